@@ -66,6 +66,7 @@ func (g *Generator) GenerateSymfony(methods []ParsedMethod, properties []ParsedM
 	}
 
 	b.WriteString("}\n\n")
+	g.writeSymfonyBags(&b, methods, properties)
 	g.writeInit(&b, "symfony", fw)
 
 	return b.String()
@@ -151,6 +152,51 @@ func (g *Generator) writeSymfonyMethodPatternInferred(b *strings.Builder, fw *Fr
 	b.WriteString("\t},\n")
 }
 
+// writeSymfonyBags writes the Request bag properties with the input they
+// carry, and the bag methods returning input, so chains such as
+// $request->query->all()['filter'] resolve to the bag's source type
+func (g *Generator) writeSymfonyBags(b *strings.Builder, methods []ParsedMethod, properties []ParsedMethod) {
+	var bags [][2]string
+	for _, p := range properties {
+		if mapping := SymfonyPropertyMappings[p.Name]; mapping != nil {
+			bags = append(bags, [2]string{fmt.Sprintf("%q:", p.Name), "common." + mapping.SourceType + ","})
+		}
+	}
+	b.WriteString("// SymfonyBagProperties maps the Request properties holding a parameter bag\n")
+	b.WriteString("// to the input the bag carries\n")
+	b.WriteString("var SymfonyBagProperties = map[string]common.SourceType{\n")
+	g.writeAligned(b, bags)
+	b.WriteString("}\n\n")
+
+	var bagMethods [][2]string
+	seen := make(map[string]bool)
+	for _, m := range methods {
+		name := strings.ToLower(m.Name)
+		if !seen[name] {
+			seen[name] = true
+			bagMethods = append(bagMethods, [2]string{fmt.Sprintf("%q:", name), "true,"})
+		}
+	}
+	b.WriteString("// SymfonyBagMethods are the ParameterBag and InputBag methods returning\n")
+	b.WriteString("// input (lowercase; PHP methods are case-insensitive)\n")
+	b.WriteString("var SymfonyBagMethods = map[string]bool{\n")
+	g.writeAligned(b, bagMethods)
+	b.WriteString("}\n\n")
+}
+
+// writeAligned writes key/value lines with the values aligned like gofmt
+func (g *Generator) writeAligned(b *strings.Builder, lines [][2]string) {
+	width := 0
+	for _, l := range lines {
+		if len(l[0]) > width {
+			width = len(l[0])
+		}
+	}
+	for _, l := range lines {
+		b.WriteString(fmt.Sprintf("\t%-*s %s\n", width, l[0], l[1]))
+	}
+}
+
 func (g *Generator) writeInit(b *strings.Builder, framework string, fw *FrameworkDefinition) {
 	b.WriteString("func init() {\n")
 	b.WriteString(fmt.Sprintf("\tRegistry.RegisterAll(%sPatterns)\n\n", framework))
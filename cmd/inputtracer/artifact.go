@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+)
+
+const artifactUsage = `usage: inputtracer artifact [flags] <image.tar|app.tar.gz>
+
+Scans a container image saved with "docker save" or a deployment tarball
+when the source repository is not available. Operating system layers are
+skipped and sources are reported at their paths inside the artifact.
+
+flags:
+`
+
+// runArtifact runs the artifact command
+func runArtifact(args []string) error {
+	fs := flag.NewFlagSet("artifact", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the full trace result as JSON")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, artifactUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	result, err := semantic.New(nil).TraceArtifact(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		out, err := result.ToJSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+		return nil
+	}
+	for _, src := range result.Sources {
+		fmt.Printf("%s:%d\t%s\t%s\n", src.FilePath, src.Line, src.SourceType, src.Snippet)
+	}
+	fmt.Fprintf(os.Stderr, "%d sources in %d files (%s, %d layers skipped)\n",
+		len(result.Sources), len(result.Files), result.Artifact.Kind, len(result.Artifact.SkippedLayers))
+	return nil
+}
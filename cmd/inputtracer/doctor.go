@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/doctor"
+)
+
+const doctorUsage = `usage: inputtracer doctor [flags]
+
+Runs the full pipeline over small embedded programs, one per supported
+language, and prints which stages pass: the grammar parses the program, the
+analyzer finds its sources, a source flows into a variable, and (PHP) the
+symbolic engine traces a carrier to its superglobal. Exits with status 1 when
+a check fails.
+
+flags:
+`
+
+// runDoctor runs the doctor command
+func runDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the checks as JSON")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, doctorUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	report, err := doctor.Run()
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Grammars: %s\n\n", report.GrammarVersion)
+		if err := report.WriteMatrix(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if !report.Passed() {
+		return errors.New("self-test failed")
+	}
+	return nil
+}
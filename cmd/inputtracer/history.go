@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/history"
+)
+
+const historyUsage = `usage: inputtracer history <subcommand> [flags] <dir> [fingerprint]
+
+subcommands:
+  record    scan <dir> and store its sources
+  sources   list the sources of <dir> with first and last appearance
+  source    show when the flow path of one source changed
+  trend     chart the flow count of each recorded scan
+`
+
+// runHistory runs the history subcommands
+func runHistory(args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, historyUsage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("history "+args[0], flag.ExitOnError)
+	dbPath := fs.String("db", ".inputtracer-history.db", "History database file")
+	label := fs.String("label", "", "Label stored with a recorded scan (e.g. a commit)")
+	asJSON := fs.Bool("json", false, "Print results as JSON")
+	fs.Parse(args[1:])
+	if fs.NArg() < 1 {
+		fmt.Fprint(os.Stderr, historyUsage)
+		os.Exit(2)
+	}
+	root := fs.Arg(0)
+
+	db, err := history.Open(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "record":
+		result, err := semantic.New(nil).TraceDirectory(root)
+		if err != nil {
+			return err
+		}
+		scan, err := db.RecordScan(root, *label, result, time.Now())
+		if err != nil {
+			return err
+		}
+		return printResult(*asJSON, scan, func() {
+			fmt.Printf("Recorded scan %d: %d sources, %d flows\n", scan.ID, scan.Sources, scan.Flows)
+		})
+
+	case "sources":
+		sources, err := db.Sources(root)
+		if err != nil {
+			return err
+		}
+		return printResult(*asJSON, sources, func() {
+			for _, src := range sources {
+				name := src.Name
+				if src.SourceKey != "" {
+					name = fmt.Sprintf("%s['%s']", name, src.SourceKey)
+				}
+				fmt.Printf("%s  %-12s %s in %s (first %s, last %s, %d scans)\n", src.Fingerprint, src.SourceType, name, src.FilePath,
+					src.FirstSeen.ScannedAt.Format(time.DateTime), src.LastSeen.ScannedAt.Format(time.DateTime), src.Scans)
+			}
+		})
+
+	case "source":
+		if fs.NArg() < 2 {
+			return fmt.Errorf("history source needs a fingerprint")
+		}
+		changes, err := db.PathChanges(root, fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		return printResult(*asJSON, changes, func() {
+			for _, change := range changes {
+				fmt.Printf("%s scan %d (line %d)\n  %s\n", change.Scan.ScannedAt.Format(time.DateTime), change.Scan.ID,
+					change.Line, strings.Join(change.Path, "\n  -> "))
+			}
+		})
+
+	case "trend":
+		scans, err := db.Scans(root)
+		if err != nil {
+			return err
+		}
+		return printResult(*asJSON, scans, func() {
+			history.WriteTrendChart(os.Stdout, scans, 50)
+		})
+
+	default:
+		return fmt.Errorf("unknown history subcommand: %s", args[0])
+	}
+}
+
+// printResult prints v as JSON or with the text printer
+func printResult(asJSON bool, v interface{}, text func()) error {
+	if !asJSON {
+		text()
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
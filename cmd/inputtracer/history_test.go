@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/history"
+)
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	runErr := fn()
+	os.Stdout = stdout
+	w.Close()
+	out := <-done
+	if runErr != nil {
+		t.Fatal(runErr)
+	}
+	return out
+}
+
+func TestRunHistory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.php"), []byte("<?php\n$id = $_GET['id'];\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dbPath := filepath.Join(t.TempDir(), "history.db")
+	run := func(args ...string) string {
+		return captureStdout(t, func() error { return runHistory(args) })
+	}
+
+	if out := run("record", "-db", dbPath, "-label", "abc123", dir); !strings.HasPrefix(out, "Recorded scan 1: 1 sources") {
+		t.Errorf("record printed %q", out)
+	}
+	run("record", "-db", dbPath, dir)
+
+	var sources []history.Source
+	if err := json.Unmarshal([]byte(run("sources", "-db", dbPath, "-json", dir)), &sources); err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || sources[0].SourceKey != "id" || sources[0].Scans != 2 || sources[0].FirstSeen.Label != "abc123" {
+		t.Fatalf("sources = %+v, want $_GET['id'] seen in 2 scans", sources)
+	}
+	if out := run("sources", "-db", dbPath, dir); !strings.Contains(out, "$_GET['id'] in index.php") || !strings.Contains(out, "2 scans") {
+		t.Errorf("sources printed %q", out)
+	}
+
+	// Unchanged path: one change, the first sighting
+	if out := run("source", "-db", dbPath, dir, sources[0].Fingerprint); strings.Count(out, "scan ") != 1 || !strings.Contains(out, "(line 2)") {
+		t.Errorf("source printed %q", out)
+	}
+	if out := run("trend", "-db", dbPath, dir); strings.Count(out, "\n") != 2 || !strings.Contains(out, "abc123") {
+		t.Errorf("trend printed %q", out)
+	}
+
+	tests := []struct {
+		args []string
+		err  string
+	}{
+		{[]string{"source", "-db", dbPath, dir}, "needs a fingerprint"},
+		{[]string{"source", "-db", dbPath, dir, "0123456789abcdef-0"}, history.ErrNotFound.Error()},
+		{[]string{"prune", "-db", dbPath, dir}, "unknown history subcommand: prune"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.args[0], func(t *testing.T) {
+			if err := runHistory(tt.args); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("runHistory(%q) = %v, want %q", tt.args, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+)
+
+const indexUsage = `usage: inputtracer index [flags] <dir>
+
+Parses <dir> once and writes an index file. Backward queries load it through
+Config.IndexFile instead of parsing the codebase again.
+
+flags:
+`
+
+// runIndex runs the index command
+func runIndex(args []string) error {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	out := fs.String("o", ".inputtracer-index", "Index file to write")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, indexUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	start := time.Now()
+	if err := semantic.New(nil).BuildIndex(fs.Arg(0), *out); err != nil {
+		return err
+	}
+	stat, err := os.Stat(*out)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Indexed %s into %s (%d KB) in %v\n", fs.Arg(0), *out, stat.Size()/1024, time.Since(start).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,48 @@
+// Package main - inputtracer traces input sources from the command line
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: inputtracer <command> [arguments]
+
+commands:
+  artifact  scan a container image or deployment tarball
+  doctor    verify grammars, analyzers and rules on embedded programs
+  history   record scans and query source history across runs
+  index     write an index answering backward queries without parsing
+  repro     extract a finding into a minimal standalone reproduction
+  rules     export the effective detection rules as a rule pack
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "artifact":
+		err = runArtifact(os.Args[2:])
+	case "doctor":
+		err = runDoctor(os.Args[2:])
+	case "history":
+		err = runHistory(os.Args[2:])
+	case "index":
+		err = runIndex(os.Args[2:])
+	case "repro":
+		err = runRepro(os.Args[2:])
+	case "rules":
+		err = runRules(os.Args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+)
+
+const reproUsage = `usage: inputtracer repro [flags] <dir>
+
+Scans <dir> and extracts the flow of one source into a standalone directory:
+the files along the flow, trimmed to the code it passes through, and a README
+of the trace. Without -source, lists the source IDs to choose from.
+
+flags:
+`
+
+// runRepro runs the repro command
+func runRepro(args []string) error {
+	fs := flag.NewFlagSet("repro", flag.ExitOnError)
+	sourceID := fs.String("source", "", "ID of the source to extract")
+	out := fs.String("o", "repro", "Directory to write the reproduction to")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, reproUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	result, err := semantic.New(nil).TraceDirectory(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *sourceID == "" {
+		for _, src := range result.Sources {
+			fmt.Printf("%s\t%s\t%s\n", src.ID, src.SourceType, src.Snippet)
+		}
+		return nil
+	}
+	for _, src := range result.Sources {
+		if src.ID != *sourceID {
+			continue
+		}
+		repro, err := result.ExtractReproduction(src, *out)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Extracted %d files into %s\n", len(repro.Files), *out)
+		return nil
+	}
+	return fmt.Errorf("no source with ID %s", *sourceID)
+}
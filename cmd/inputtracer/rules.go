@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+)
+
+const rulesUsage = `usage: inputtracer rules [flags]
+
+Writes the effective detection rules (framework patterns and decoding,
+sanitizing and validating functions) as a rule pack, listing the built-in
+input sources and generated framework methods for reference. Packs given
+with -pack are applied first, so the output is the merged rule set. Edited
+packs are loaded at runtime through Config.RulePackFiles.
+
+flags:
+`
+
+// runRules runs the rules command
+func runRules(args []string) error {
+	fs := flag.NewFlagSet("rules", flag.ExitOnError)
+	out := fs.String("o", "", "Rule pack file to write (default: stdout)")
+	name := fs.String("name", "builtin", "Name recorded in the pack")
+	version := fs.String("version", "", "Version recorded in the pack")
+	packs := fs.String("pack", "", "Comma-separated rule packs to apply before exporting")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, rulesUsage)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	rules := sources.NewRuleSet()
+	if *packs != "" {
+		for _, path := range strings.Split(*packs, ",") {
+			pack, err := sources.LoadRulePack(path)
+			if err != nil {
+				return err
+			}
+			if err := rules.Apply(pack); err != nil {
+				return fmt.Errorf("rule pack %s: %w", path, err)
+			}
+		}
+	}
+
+	pack := rules.Export(*name, *version)
+	if *out == "" {
+		data, err := pack.MarshalIndent()
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := pack.WriteFile(*out); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d framework patterns to %s\n", len(pack.FrameworkPatterns), *out)
+	return nil
+}
@@ -0,0 +1,234 @@
+// Package inputtracer is the stable public API of the input tracer. It traces
+// where user input enters a codebase and how it flows through it.
+//
+// The types and functions in this package follow semantic versioning: they
+// are not removed or changed incompatibly within a major version. The
+// packages under pkg/ remain importable but expose implementation details,
+// including legacy members kept for backward compatibility, and may change
+// between minor versions.
+//
+// Typical use:
+//
+//	result, err := inputtracer.Scan("./app", nil)
+//	if err != nil {
+//		return err
+//	}
+//	out, err := inputtracer.Export(result, inputtracer.FormatJSON)
+package inputtracer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+)
+
+// Config controls a scan. A nil Config means DefaultConfig().
+type Config = semantic.Config
+
+// Rules holds custom source and wrapper rules loaded from a rules file
+type Rules = semantic.Rules
+
+// RuntimeHints is a runtime log resolving dynamic includes, services and keys
+type RuntimeHints = semantic.RuntimeHints
+
+// Result is the outcome of a scan
+type Result = semantic.TraceResult
+
+// Source is an input source or a node input flows to
+type Source = types.FlowNode
+
+// SourceType classifies an input source (http_get, http_post, cli_arg, ...)
+type SourceType = types.SourceType
+
+// Finding is one input source with its traced flow, yielded by ScanStream
+type Finding = semantic.Finding
+
+// BackwardResult is the outcome of TraceBackward
+type BackwardResult = types.BackwardTraceResult
+
+// TerminationReason tells why a backward trace stopped, so that a result
+// without sources can be told apart as proven clean or incomplete
+type TerminationReason = types.TerminationReason
+
+// Termination reasons of BackwardResult and its paths
+const (
+	TerminationSource          = types.TerminationSource
+	TerminationClean           = types.TerminationClean
+	TerminationResourceLimit   = types.TerminationResourceLimit
+	TerminationDepthLimit      = types.TerminationDepthLimit
+	TerminationClassNotFound   = types.TerminationClassNotFound
+	TerminationDynamicDispatch = types.TerminationDynamicDispatch
+	TerminationUnresolved      = types.TerminationUnresolved
+)
+
+// Explanation reports whether the expression at a position carries input,
+// from which sources and through which path
+type Explanation = semantic.TaintStatus
+
+// Warning records a place where analysis could not follow the code
+type Warning = types.AnalysisWarning
+
+// WriteOptions controls what exports embed beyond the trace (code context, ...)
+type WriteOptions = semantic.WriteOptions
+
+// Reproduction is a finding extracted into a standalone directory of trimmed
+// files and a README of its trace
+type Reproduction = semantic.Reproduction
+
+// NodeIDParts are the fields of a node ID (file, line, column, kind, hash)
+type NodeIDParts = types.NodeIDParts
+
+// ScanMetrics summarizes a scan (counts, duration, cache hit rate, peak heap)
+type ScanMetrics = semantic.ScanMetrics
+
+// MetricsCollector aggregates scan metrics and serves them to Prometheus
+type MetricsCollector = semantic.MetricsCollector
+
+// NewMetricsCollector creates a collector; mount it at /metrics and pass it
+// every scan result with Record
+func NewMetricsCollector() *MetricsCollector {
+	return semantic.NewMetricsCollector()
+}
+
+// Profile is a preset of analysis limits set with Config.Profile
+type Profile = semantic.Profile
+
+// Analysis profiles, from cheapest to most thorough
+const (
+	ProfileQuick    = semantic.ProfileQuick
+	ProfileStandard = semantic.ProfileStandard
+	ProfileDeep     = semantic.ProfileDeep
+)
+
+// Errors returned by scans, matchable with errors.Is
+var (
+	ErrParse         = types.ErrParse
+	ErrMemoryLimit   = types.ErrMemoryLimit
+	ErrTimeLimit     = types.ErrTimeLimit
+	ErrSandboxEscape = types.ErrSandboxEscape
+)
+
+// DefaultConfig returns the default scan configuration
+func DefaultConfig() *Config {
+	return semantic.DefaultConfig()
+}
+
+// ParseNodeID splits a node ID into its fields; IDs in the previous
+// file:line:column formats are accepted too
+func ParseNodeID(id string) (NodeIDParts, bool) {
+	return types.ParseNodeID(id)
+}
+
+// LoadRules reads a JSON rules file for Config.Rules
+func LoadRules(path string) (*Rules, error) {
+	return semantic.LoadRules(path)
+}
+
+// RulePack is the detection rule set as data, set with Config.RulePacks
+type RulePack = sources.RulePack
+
+// ExportRulePack returns the built-in detection rules as a rule pack
+func ExportRulePack(name, version string) *RulePack {
+	return sources.ExportRulePack(name, version)
+}
+
+// LoadRulePack reads a rule pack file for Config.RulePacks
+func LoadRulePack(path string) (*RulePack, error) {
+	return sources.LoadRulePack(path)
+}
+
+// Scan traces all input sources in a file or directory
+func Scan(path string, config *Config) (*Result, error) {
+	return semantic.New(config).TraceDirectory(path)
+}
+
+// ScanStream traces a directory and yields findings while tracing runs.
+// Cancelling ctx stops the scan. The error channel yields at most one error
+// and is closed after the findings channel.
+func ScanStream(ctx context.Context, path string, config *Config) (<-chan Finding, <-chan error) {
+	return semantic.New(config).StreamDirectory(ctx, path)
+}
+
+// BuildIndex parses a codebase once and writes an index file; setting
+// Config.IndexFile to it answers TraceBackward and Explain without parsing
+func BuildIndex(codebasePath, indexPath string, config *Config) error {
+	return semantic.New(config).BuildIndex(codebasePath, indexPath)
+}
+
+// TraceBackward finds the input sources reaching a target expression
+// (e.g. "$user" or "$mybb->input['uid']") in a codebase
+func TraceBackward(codebasePath, target string, config *Config) (*BackwardResult, error) {
+	return semantic.New(config).TraceBackward(target, codebasePath)
+}
+
+// Explain reports whether the expression at file:line:col carries input and
+// how it gets there. line is 1-based and col is 0-based. With
+// Config.IndexFile set, file must be the absolute path the index records.
+func Explain(codebasePath, file string, line, col int, config *Config) (*Explanation, error) {
+	tracer := semantic.New(config)
+	if config != nil && config.IndexFile != "" {
+		if err := tracer.LoadIndex(config.IndexFile); err != nil {
+			return nil, err
+		}
+	} else if _, err := tracer.ParseOnly(codebasePath); err != nil {
+		return nil, fmt.Errorf("failed to parse codebase: %w", err)
+	}
+	return tracer.TaintStatusAt(file, line, col)
+}
+
+// ExtractReproduction writes the flow from source into dir as a minimal
+// reproduction: the files along the flow trimmed to the code it passes
+// through, plus a README of the trace, shareable without the whole codebase
+func ExtractReproduction(result *Result, source *Source, dir string) (*Reproduction, error) {
+	return result.ExtractReproduction(source, dir)
+}
+
+// Format names an export format
+type Format string
+
+const (
+	FormatJSON         Format = "json"
+	FormatDOT          Format = "dot"
+	FormatMermaid      Format = "mermaid"
+	FormatHTML         Format = "html"
+	FormatDefectDojo   Format = "defectdojo"
+	FormatCheckmarxXML Format = "checkmarx"
+	FormatInventory    Format = "inventory"
+	FormatMetrics      Format = "metrics"
+	FormatBadge        Format = "badge"
+)
+
+// Export renders a scan result in the given format
+func Export(result *Result, format Format) (string, error) {
+	return ExportWithOptions(result, format, WriteOptions{})
+}
+
+// ExportWithOptions renders a scan result in the given format, embedding what
+// opts asks for where the format supports it (currently JSON)
+func ExportWithOptions(result *Result, format Format, opts WriteOptions) (string, error) {
+	switch format {
+	case FormatJSON:
+		return result.ToJSONWithOptions(opts)
+	case FormatDOT:
+		return result.ToDOT(), nil
+	case FormatMermaid:
+		return result.ToMermaid(), nil
+	case FormatHTML:
+		return result.ToHTML(), nil
+	case FormatDefectDojo:
+		return result.ToDefectDojo()
+	case FormatCheckmarxXML:
+		return result.ToCheckmarxXML()
+	case FormatInventory:
+		return semantic.ToAttackSurfaceInventory(result)
+	case FormatMetrics:
+		return result.ToMetricsJSON()
+	case FormatBadge:
+		return result.ToBadgeSVG(), nil
+	default:
+		return "", fmt.Errorf("unknown export format: %s", format)
+	}
+}
@@ -0,0 +1,140 @@
+package inputtracer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeApp writes a small PHP codebase and returns its directory
+func writeApp(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.php": "<?php\n$id = $_GET['id'];\n$user = $id;\necho $user;\n",
+		"form.php":  "<?php\n$name = $_POST['name'];\n",
+	}
+	for name, code := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestScan(t *testing.T) {
+	dir := writeApp(t)
+	result, err := Scan(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sources) != 2 {
+		t.Fatalf("found %d sources, want 2", len(result.Sources))
+	}
+
+	findings, errc := ScanStream(context.Background(), dir, nil)
+	streamed := 0
+	for range findings {
+		streamed++
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if streamed != len(result.Sources) {
+		t.Errorf("streamed %d findings, want %d", streamed, len(result.Sources))
+	}
+
+	for _, src := range result.Sources {
+		parts, ok := ParseNodeID(src.ID)
+		if !ok || parts.FilePath != src.FilePath || parts.Line != src.Line {
+			t.Errorf("ParseNodeID(%q) = %+v, %v", src.ID, parts, ok)
+		}
+	}
+}
+
+func TestTraceBackward(t *testing.T) {
+	dir := writeApp(t)
+	result, err := TraceBackward(dir, "$user", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sources) != 1 || result.Sources[0].Expression != "$_GET['id']" {
+		t.Errorf("sources = %+v, want [$_GET['id']]", result.Sources)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	dir := writeApp(t)
+	file := filepath.Join(dir, "index.php")
+	indexPath := filepath.Join(t.TempDir(), "index")
+	if err := BuildIndex(dir, indexPath, nil); err != nil {
+		t.Fatal(err)
+	}
+	indexed := DefaultConfig()
+	indexed.IndexFile = indexPath
+
+	tests := []struct {
+		name    string
+		config  *Config
+		line    int
+		col     int
+		tainted bool
+	}{
+		{"parsed", nil, 4, 5, true},
+		{"indexed", indexed, 4, 5, true},
+		{"untainted literal", nil, 1, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := Explain(dir, file, tt.line, tt.col, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Tainted != tt.tainted {
+				t.Errorf("tainted = %v, want %v (%+v)", status.Tainted, tt.tainted, status)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	result, err := Scan(writeApp(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		format Format
+		want   string // Substring of the output
+	}{
+		{FormatJSON, `"sources"`},
+		{FormatDOT, "digraph"},
+		{FormatMermaid, "flowchart"},
+		{FormatHTML, "<html"},
+		{FormatDefectDojo, `"findings"`},
+		{FormatCheckmarxXML, "<?xml"},
+		{FormatInventory, "$_GET"},
+		{FormatMetrics, "{"},
+		{FormatBadge, "<svg"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			out, err := Export(result, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output does not contain %q", tt.want)
+			}
+			if strings.HasPrefix(out, "{") && !json.Valid([]byte(out)) {
+				t.Error("output is not valid JSON")
+			}
+		})
+	}
+
+	if _, err := Export(result, "yaml"); err == nil {
+		t.Error("unknown format succeeded")
+	}
+}
@@ -85,4 +85,18 @@ func RegisterAll(r *Registry) {
 		[]string{"call_expression", "method_call_expression"},
 		[]string{"identifier"},
 	))
+
+	// Kotlin
+	r.Register(NewBaseExtractor("kotlin",
+		[]string{"assignment", "property_declaration"},
+		[]string{"call_expression"},
+		[]string{"simple_identifier"},
+	))
+
+	// Swift
+	r.Register(NewBaseExtractor("swift",
+		[]string{"assignment", "property_declaration"},
+		[]string{"call_expression"},
+		[]string{"simple_identifier"},
+	))
 }
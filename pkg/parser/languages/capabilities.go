@@ -0,0 +1,136 @@
+package languages
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// grammarModule is the module bundling all tree-sitter grammars
+const grammarModule = "github.com/smacker/go-tree-sitter"
+
+// ModernConstruct is a canary snippet of recent language syntax. A grammar
+// that produces ERROR nodes for it cannot parse code using the construct.
+type ModernConstruct struct {
+	Name    string
+	Since   string // Language version that introduced the construct
+	Snippet string
+}
+
+// ModernConstructs lists canary snippets per language
+var ModernConstructs = map[string][]ModernConstruct{
+	"php": {
+		{"match expression", "8.0", "<?php $x = match($a) { 1 => 'a', default => 'b' };"},
+		{"nullsafe operator", "8.0", "<?php $x = $a?->b;"},
+		{"named arguments", "8.0", "<?php f(name: 1);"},
+		{"attributes", "8.0", "<?php #[Route('/')] function f() {}"},
+		{"enums", "8.1", "<?php enum Suit: string { case Hearts = 'H'; }"},
+		{"readonly properties", "8.1", "<?php class A { public readonly int $x; }"},
+		{"first-class callable syntax", "8.1", "<?php $f = strlen(...);"},
+		{"readonly classes", "8.2", "<?php readonly class A {}"},
+		{"DNF types", "8.2", "<?php function f((A&B)|null $x) {}"},
+		{"typed class constants", "8.3", "<?php class A { const string X = 'x'; }"},
+		{"property hooks", "8.4", "<?php class A { public string $x { get => 'a'; } }"},
+		{"asymmetric visibility", "8.4", "<?php class A { public private(set) int $x; }"},
+	},
+	"javascript": {
+		{"optional chaining", "ES2020", "a?.b?.();"},
+		{"nullish coalescing assignment", "ES2021", "a ??= b;"},
+		{"private class fields", "ES2022", "class A { #x = 1; m() { return this.#x; } }"},
+		{"class static blocks", "ES2022", "class A { static { init(); } }"},
+		{"import attributes", "ES2025", "import data from './d.json' with { type: 'json' };"},
+	},
+	"typescript": {
+		{"satisfies operator", "4.9", "const x = {} satisfies T;"},
+		{"const type parameters", "5.0", "function f<const T>(x: T) {}"},
+		{"using declarations", "5.2", "{ using r = open(); }"},
+	},
+	"python": {
+		{"walrus operator", "3.8", "if (n := len(a)) > 1: pass\n"},
+		{"match statement", "3.10", "match x:\n    case 1:\n        pass\n"},
+		{"exception groups", "3.11", "try:\n    pass\nexcept* ValueError:\n    pass\n"},
+		{"type aliases", "3.12", "type Point = tuple[float, float]\n"},
+		{"generic type parameters", "3.12", "def f[T](x: T) -> T:\n    return x\n"},
+	},
+	"go": {
+		{"generics", "1.18", "package p\nfunc F[T any](x T) T { return x }\n"},
+		{"range over int", "1.22", "package p\nfunc F() { for i := range 10 { _ = i } }\n"},
+	},
+	"java": {
+		{"records", "16", "record Point(int x, int y) {}"},
+		{"switch expressions", "14", "class A { int f(int x) { return switch (x) { case 1 -> 2; default -> 0; }; } }"},
+		{"text blocks", "15", "class A { String s = \"\"\"\n  hi\n  \"\"\"; }"},
+		{"sealed classes", "17", "sealed class A permits B {}"},
+	},
+	"c_sharp": {
+		{"file-scoped namespaces", "10", "namespace A;\nclass B {}"},
+		{"records", "9", "record Point(int X, int Y);"},
+		{"raw string literals", "11", "class A { string s = \"\"\"raw\"\"\"; }"},
+	},
+	"ruby": {
+		{"pattern matching", "3.0", "case x\nin {a: Integer}\n  1\nend\n"},
+		{"endless methods", "3.0", "def sq(x) = x * x\n"},
+	},
+	"rust": {
+		{"let-else", "1.65", "fn f() { let Some(x) = g() else { return }; }"},
+		{"let chains", "2024", "fn f() { if let Some(x) = a && x > 1 {} }"},
+	},
+}
+
+// GrammarCapabilities reports which modern constructs a bundled grammar parses
+type GrammarCapabilities struct {
+	Language    string   `json:"language"`
+	Version     string   `json:"version"`               // Version of the grammar module
+	Unsupported []string `json:"unsupported,omitempty"` // Modern constructs producing ERROR nodes
+}
+
+var (
+	capabilities     = make(map[string]*GrammarCapabilities)
+	capabilitiesLock sync.Mutex
+)
+
+// GrammarVersion returns the version of the module bundling the grammars, or
+// "unknown" when build information is unavailable
+func GrammarVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == grammarModule {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
+
+// CheckCapabilities parses the canary snippets of a language and reports the
+// constructs its grammar cannot parse. Results are cached per language.
+func CheckCapabilities(name string, lang *sitter.Language) *GrammarCapabilities {
+	capabilitiesLock.Lock()
+	defer capabilitiesLock.Unlock()
+	if c, ok := capabilities[name]; ok {
+		return c
+	}
+
+	c := &GrammarCapabilities{Language: name, Version: GrammarVersion()}
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(lang)
+	for _, construct := range ModernConstructs[name] {
+		tree, err := parser.ParseCtx(context.Background(), nil, []byte(construct.Snippet))
+		if err != nil || tree.RootNode().HasError() {
+			c.Unsupported = append(c.Unsupported, construct.Name+" ("+construct.Since+")")
+		}
+		if tree != nil {
+			tree.Close()
+		}
+	}
+	capabilities[name] = c
+	return c
+}
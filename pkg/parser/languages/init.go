@@ -10,10 +10,12 @@ import (
 	"github.com/smacker/go-tree-sitter/golang"
 	"github.com/smacker/go-tree-sitter/java"
 	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/kotlin"
 	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
 	"github.com/smacker/go-tree-sitter/ruby"
 	"github.com/smacker/go-tree-sitter/rust"
+	"github.com/smacker/go-tree-sitter/swift"
 	"github.com/smacker/go-tree-sitter/typescript/tsx"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
 )
@@ -133,6 +135,16 @@ func GetAllLanguages() []LanguageInfo {
 			Language:   rust.GetLanguage(),
 			Extensions: []string{".rs"},
 		},
+		{
+			Name:       "kotlin",
+			Language:   kotlin.GetLanguage(),
+			Extensions: []string{".kt", ".kts"},
+		},
+		{
+			Name:       "swift",
+			Language:   swift.GetLanguage(),
+			Extensions: []string{".swift"},
+		},
 	}
 }
 
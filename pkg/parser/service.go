@@ -160,6 +160,15 @@ func (s *Service) ParseFile(filePath string) (*ParseResult, error) {
 	}, nil
 }
 
+// Content returns a file's source from the cache, or reads it from disk
+// without parsing or caching it
+func (s *Service) Content(filePath string) ([]byte, error) {
+	if cached := s.cache.Get(filePath); cached != nil {
+		return cached.Source, nil
+	}
+	return os.ReadFile(filePath)
+}
+
 // ParseWithTree parses source code and returns both tree and root node
 // MEMORY FIX: Now returns the tree so it can be closed later
 func (s *Service) ParseWithTree(source []byte, language string) (*sitter.Tree, *sitter.Node, error) {
@@ -247,6 +256,11 @@ func (s *Service) ClearCache() {
 	s.cache.Clear()
 }
 
+// Invalidate drops the cached parse of a file, so the next ParseFile reads it again
+func (s *Service) Invalidate(filePath string) {
+	s.cache.Remove(filePath)
+}
+
 // CacheStats returns cache statistics
 func (s *Service) CacheStats() (hits, misses int64) {
 	return s.cache.Stats()
@@ -10,6 +10,7 @@ import (
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 	goPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/golang"
 	sitter "github.com/smacker/go-tree-sitter"
 )
@@ -34,8 +35,22 @@ func NewGoAnalyzer() *GoAnalyzer {
 	return a
 }
 
+// WithFrameworkPatterns returns a copy of the analyzer matching patterns
+// instead of the ones registered in pkg/sources/golang
+func (a *GoAnalyzer) WithFrameworkPatterns(patterns []*common.FrameworkPattern) analyzer.LanguageAnalyzer {
+	c := *a
+	c.BaseAnalyzer = a.WithoutFrameworkPatterns()
+	c.addFrameworkPatterns(patterns)
+	return &c
+}
+
 func (a *GoAnalyzer) registerFrameworkPatterns() {
-	for _, p := range goPatterns.GetAllPatterns() {
+	a.addFrameworkPatterns(goPatterns.GetAllPatterns())
+}
+
+// addFrameworkPatterns converts framework patterns from pkg/sources and adds them
+func (a *GoAnalyzer) addFrameworkPatterns(patterns []*common.FrameworkPattern) {
+	for _, p := range patterns {
 		fp := &types.FrameworkPattern{
 			ID:              p.ID,
 			Framework:       p.Framework,
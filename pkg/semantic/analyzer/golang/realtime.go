@@ -0,0 +1,87 @@
+package golang
+
+import (
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	goPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/golang"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// FindRealtimeHandlers returns the functions reading WebSocket messages, one
+// handler per function. Variables filled by reference (ReadJSON(&v)) are the
+// payloads; calls returning the message are sources through the input mappings.
+func (a *GoAnalyzer) FindRealtimeHandlers(root *sitter.Node, source []byte) []*types.RealtimeHandler {
+	var handlers []*types.RealtimeHandler
+	byFunction := make(map[*sitter.Node]*types.RealtimeHandler)
+
+	for _, call := range analyzer.FindNodesOfType(root, "call_expression") {
+		fn := analyzer.FindChildByFieldName(call, "function")
+		if fn == nil || fn.Type() != "selector_expression" {
+			continue
+		}
+
+		var library string
+		var payload *sitter.Node
+		args := analyzer.FindChildByFieldName(call, "arguments")
+		if receive, ok := goPatterns.RealtimeReceiveCalls[analyzer.GetNodeText(fn, source)]; ok {
+			library = receive.Library
+			payload = argumentAt(args, receive.PayloadArg)
+		} else if lib, ok := goPatterns.RealtimeReadMethods[analyzer.GetNodeText(analyzer.FindChildByFieldName(fn, "field"), source)]; ok {
+			library = lib
+			payload = argumentAt(args, 0) // ReadJSON(&v)
+		} else {
+			continue
+		}
+
+		enclosing := enclosingFunction(call)
+		if enclosing == nil {
+			continue
+		}
+		handler := byFunction[enclosing]
+		if handler == nil {
+			handler = &types.RealtimeHandler{
+				Protocol:  types.ProtocolWebSocket,
+				Framework: library,
+				Function:  analyzer.GetNodeText(analyzer.FindChildByFieldName(enclosing, "name"), source),
+				Line:      int(enclosing.StartPoint().Row) + 1,
+				EndLine:   int(enclosing.EndPoint().Row) + 1,
+			}
+			byFunction[enclosing] = handler
+			handlers = append(handlers, handler)
+		}
+		if payload != nil && payload.Type() == "identifier" && analyzer.GetNodeText(payload, source) != "_" {
+			handler.Payloads = append(handler.Payloads, types.RealtimePayload{
+				Name:   analyzer.GetNodeText(payload, source),
+				Line:   int(payload.StartPoint().Row) + 1,
+				Column: int(payload.StartPoint().Column),
+			})
+		}
+	}
+	return handlers
+}
+
+// argumentAt returns the argument at index, unwrapping &v to v
+func argumentAt(args *sitter.Node, index int) *sitter.Node {
+	if args == nil || index >= int(args.NamedChildCount()) {
+		return nil
+	}
+	arg := args.NamedChild(index)
+	if arg.Type() == "unary_expression" {
+		if operand := analyzer.FindChildByFieldName(arg, "operand"); operand != nil {
+			return operand
+		}
+	}
+	return arg
+}
+
+// enclosingFunction returns the function declaration, method or function
+// literal containing node
+func enclosingFunction(node *sitter.Node) *sitter.Node {
+	for n := node.Parent(); n != nil; n = n.Parent() {
+		switch n.Type() {
+		case "function_declaration", "method_declaration", "func_literal":
+			return n
+		}
+	}
+	return nil
+}
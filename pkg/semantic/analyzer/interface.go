@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 	sitter "github.com/smacker/go-tree-sitter"
 )
 
@@ -124,6 +125,14 @@ func (b *BaseAnalyzer) AddFrameworkPattern(pattern *types.FrameworkPattern) {
 	b.frameworkPatterns = append(b.frameworkPatterns, pattern)
 }
 
+// WithoutFrameworkPatterns returns a copy of the base analyzer without
+// framework patterns
+func (b *BaseAnalyzer) WithoutFrameworkPatterns() *BaseAnalyzer {
+	c := *b
+	c.frameworkPatterns = make([]*types.FrameworkPattern, 0)
+	return &c
+}
+
 // ============================================================================
 // AST Helper Functions
 // ============================================================================
@@ -354,5 +363,26 @@ func (r *Registry) Languages() []string {
 	return languages
 }
 
+// FrameworkPatternLoader is implemented by analyzers whose framework patterns
+// come from pkg/sources, so that a scan can match its own rule set
+type FrameworkPatternLoader interface {
+	// WithFrameworkPatterns returns a copy of the analyzer matching patterns
+	// instead of the built-in framework patterns
+	WithFrameworkPatterns(patterns []*common.FrameworkPattern) LanguageAnalyzer
+}
+
+// WithFrameworkPatterns returns a registry whose analyzers match the
+// framework patterns patternsOf returns for their language; r is not changed
+func (r *Registry) WithFrameworkPatterns(patternsOf func(language string) []*common.FrameworkPattern) *Registry {
+	c := NewRegistry()
+	for language, analyzer := range r.analyzers {
+		if loader, ok := analyzer.(FrameworkPatternLoader); ok {
+			analyzer = loader.WithFrameworkPatterns(patternsOf(language))
+		}
+		c.analyzers[language] = analyzer
+	}
+	return c
+}
+
 // DefaultRegistry is the global analyzer registry
 var DefaultRegistry = NewRegistry()
@@ -9,6 +9,7 @@ import (
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 	javaPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/java"
 	sitter "github.com/smacker/go-tree-sitter"
 )
@@ -31,8 +32,22 @@ func NewJavaAnalyzer() *JavaAnalyzer {
 	return a
 }
 
+// WithFrameworkPatterns returns a copy of the analyzer matching patterns
+// instead of the ones registered in pkg/sources/java
+func (a *JavaAnalyzer) WithFrameworkPatterns(patterns []*common.FrameworkPattern) analyzer.LanguageAnalyzer {
+	c := *a
+	c.BaseAnalyzer = a.WithoutFrameworkPatterns()
+	c.addFrameworkPatterns(patterns)
+	return &c
+}
+
 func (a *JavaAnalyzer) registerFrameworkPatterns() {
-	for _, p := range javaPatterns.GetAllPatterns() {
+	a.addFrameworkPatterns(javaPatterns.GetAllPatterns())
+}
+
+// addFrameworkPatterns converts framework patterns from pkg/sources and adds them
+func (a *JavaAnalyzer) addFrameworkPatterns(patterns []*common.FrameworkPattern) {
+	for _, p := range patterns {
 		fp := &types.FrameworkPattern{
 			ID:              p.ID,
 			Framework:       p.Framework,
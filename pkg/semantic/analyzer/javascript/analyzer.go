@@ -10,6 +10,7 @@ import (
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 	jsPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/javascript"
 	sitter "github.com/smacker/go-tree-sitter"
 )
@@ -38,11 +39,25 @@ func NewJSAnalyzer() *JSAnalyzer {
 	return a
 }
 
+// WithFrameworkPatterns returns a copy of the analyzer matching patterns
+// instead of the ones registered in pkg/sources/javascript
+func (a *JSAnalyzer) WithFrameworkPatterns(patterns []*common.FrameworkPattern) analyzer.LanguageAnalyzer {
+	c := *a
+	c.BaseAnalyzer = a.WithoutFrameworkPatterns()
+	c.addFrameworkPatterns(patterns)
+	return &c
+}
+
 // registerFrameworkPatterns loads JavaScript framework patterns from pkg/sources/javascript
 // This centralizes all framework patterns in one place
 func (a *JSAnalyzer) registerFrameworkPatterns() {
 	// Load all patterns from pkg/sources/javascript registry
-	for _, p := range jsPatterns.GetAllPatterns() {
+	a.addFrameworkPatterns(jsPatterns.GetAllPatterns())
+}
+
+// addFrameworkPatterns converts framework patterns from pkg/sources and adds them
+func (a *JSAnalyzer) addFrameworkPatterns(patterns []*common.FrameworkPattern) {
+	for _, p := range patterns {
 		// Convert common.FrameworkPattern to types.FrameworkPattern
 		fp := &types.FrameworkPattern{
 			ID:              p.ID,
@@ -326,17 +341,23 @@ func (a *JSAnalyzer) parseFieldDefinition(node *sitter.Node, source []byte) *typ
 
 	// Get name
 	nameNode := analyzer.FindChildByType(node, "property_identifier")
+	if nameNode == nil {
+		nameNode = analyzer.FindChildByType(node, "private_property_identifier")
+	}
 	if nameNode == nil {
 		nameNode = analyzer.FindChildByType(node, "identifier")
 	}
 	if nameNode != nil {
 		prop.Name = analyzer.GetNodeText(nameNode, source)
+		if strings.HasPrefix(prop.Name, "#") {
+			prop.Visibility = "private"
+		}
 	}
 
 	// Get initial value
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
-		if child.Type() != "property_identifier" && child.Type() != "identifier" && child.Type() != "=" {
+		if child.Type() != "property_identifier" && child.Type() != "private_property_identifier" && child.Type() != "identifier" && child.Type() != "=" {
 			prop.InitialValue = analyzer.GetNodeText(child, source)
 			break
 		}
@@ -496,8 +517,8 @@ func (a *JSAnalyzer) parseFunctionDeclaration(node *sitter.Node, source []byte)
 func (a *JSAnalyzer) ExtractAssignments(root *sitter.Node, source []byte, scope string) ([]*types.Assignment, error) {
 	var assignments []*types.Assignment
 
-	// Assignment expressions
-	assignNodes := analyzer.FindNodesOfType(root, "assignment_expression")
+	// Assignment expressions, including compound ones (+=, ??=, ...)
+	assignNodes := analyzer.FindNodesOfTypes(root, []string{"assignment_expression", "augmented_assignment_expression"})
 	for _, node := range assignNodes {
 		assignment := a.parseAssignment(node, source, scope)
 		if assignment != nil {
@@ -505,6 +526,19 @@ func (a *JSAnalyzer) ExtractAssignments(root *sitter.Node, source []byte, scope
 		}
 	}
 
+	// Class field initializers: token = req.query.token, #token = ...
+	fieldNodes := analyzer.FindNodesOfTypes(root, []string{"field_definition", "public_field_definition"})
+	for _, node := range fieldNodes {
+		fieldScope := scope
+		if fieldScope == "" {
+			fieldScope = analyzer.ScopeIDForNode(node, source, "javascript")
+		}
+		if assignment := FieldAssignment(node, source, fieldScope); assignment != nil {
+			assignment.IsTainted, assignment.TaintSource = a.isExpressionTainted(node.ChildByFieldName("value"), source)
+			assignments = append(assignments, assignment)
+		}
+	}
+
 	// Variable declarations with initializers
 	varDecls := analyzer.FindNodesOfType(root, "variable_declarator")
 	for _, node := range varDecls {
@@ -525,12 +559,16 @@ func (a *JSAnalyzer) ExtractAssignments(root *sitter.Node, source []byte, scope
 		}
 
 		if valueNode != nil {
+			declScope := scope
+			if declScope == "" {
+				declScope = analyzer.ScopeIDForNode(node, source, "javascript")
+			}
 			assignment := &types.Assignment{
 				Target:     analyzer.GetNodeText(nameNode, source),
 				Source:     analyzer.GetNodeText(valueNode, source),
 				Line:       int(node.StartPoint().Row) + 1,
 				Column:     int(node.StartPoint().Column),
-				Scope:      scope,
+				Scope:      declScope,
 				TargetType: "variable",
 				Operator:   "=",
 			}
@@ -559,6 +597,12 @@ func (a *JSAnalyzer) parseAssignment(node *sitter.Node, source []byte, scope str
 		return nil
 	}
 
+	// Qualify the assignment with its enclosing function/method scope so
+	// same-named locals in different functions are never confused
+	if scope == "" {
+		scope = analyzer.ScopeIDForNode(node, source, "javascript")
+	}
+
 	assignment := &types.Assignment{
 		Target:   analyzer.GetNodeText(leftNode, source),
 		Source:   analyzer.GetNodeText(rightNode, source),
@@ -571,15 +615,8 @@ func (a *JSAnalyzer) parseAssignment(node *sitter.Node, source []byte, scope str
 		assignment.Operator = analyzer.GetNodeText(opNode, source)
 	}
 
-	// Determine target type
-	switch leftNode.Type() {
-	case "identifier":
-		assignment.TargetType = "variable"
-	case "member_expression":
-		assignment.TargetType = "property"
-	case "subscript_expression":
-		assignment.TargetType = "array_element"
-	}
+	// Determine target type and access path: obj[key], this.#token, ...
+	assignment.TargetType, assignment.Keys = AssignmentTarget(leftNode, source)
 
 	// Check if source is tainted
 	assignment.IsTainted, assignment.TaintSource = a.isExpressionTainted(rightNode, source)
@@ -0,0 +1,134 @@
+package javascript
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	jsPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/javascript"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// FindRealtimeHandlers returns the WebSocket and EventSource message handlers
+// of a file
+func (a *JSAnalyzer) FindRealtimeHandlers(root *sitter.Node, source []byte) []*types.RealtimeHandler {
+	return FindRealtimeHandlers(root, source)
+}
+
+// FindRealtimeHandlers finds socket.on('event', fn), ws.on('message', fn),
+// x.addEventListener('message', fn) and x.onmessage = fn handlers on
+// receivers that look like WebSocket or EventSource connections. It is shared
+// with the TypeScript analyzer, whose grammar extends the JavaScript one.
+func FindRealtimeHandlers(root *sitter.Node, source []byte) []*types.RealtimeHandler {
+	var handlers []*types.RealtimeHandler
+
+	for _, call := range analyzer.FindNodesOfType(root, "call_expression") {
+		fn := analyzer.FindChildByFieldName(call, "function")
+		if fn == nil || fn.Type() != "member_expression" {
+			continue
+		}
+		method := analyzer.GetNodeText(analyzer.FindChildByFieldName(fn, "property"), source)
+		if method != "on" && method != jsPatterns.RealtimeListenerMethod {
+			continue
+		}
+		args := analyzer.FindChildByFieldName(call, "arguments")
+		if args == nil || args.NamedChildCount() < 2 {
+			continue
+		}
+		eventNode, callback := args.NamedChild(0), args.NamedChild(1)
+		if eventNode.Type() != "string" || !isFunction(callback) {
+			continue
+		}
+		event := strings.Trim(analyzer.GetNodeText(eventNode, source), "'\"`")
+		if jsPatterns.RealtimeLifecycleEvents[event] {
+			continue
+		}
+		if method == jsPatterns.RealtimeListenerMethod && event != jsPatterns.RealtimeMessageEvent {
+			continue
+		}
+		receiver := receiverName(analyzer.FindChildByFieldName(fn, "object"), source)
+		framework, protocol := jsPatterns.RealtimeFramework(receiver, event)
+		if framework == "" {
+			continue
+		}
+		handlers = append(handlers, newRealtimeHandler(callback, source, protocol, framework, event, receiver+"."+method))
+	}
+
+	for _, assign := range analyzer.FindNodesOfType(root, "assignment_expression") {
+		left := analyzer.FindChildByFieldName(assign, "left")
+		right := analyzer.FindChildByFieldName(assign, "right")
+		if left == nil || right == nil || left.Type() != "member_expression" || !isFunction(right) {
+			continue
+		}
+		if analyzer.GetNodeText(analyzer.FindChildByFieldName(left, "property"), source) != jsPatterns.RealtimeMessageProperty {
+			continue
+		}
+		receiver := receiverName(analyzer.FindChildByFieldName(left, "object"), source)
+		framework, protocol := jsPatterns.RealtimeFramework(receiver, jsPatterns.RealtimeMessageEvent)
+		if framework == "" {
+			continue
+		}
+		handlers = append(handlers, newRealtimeHandler(right, source, protocol, framework, jsPatterns.RealtimeMessageEvent, analyzer.GetNodeText(left, source)))
+	}
+
+	return handlers
+}
+
+// receiverName returns the last identifier of a receiver expression
+// (this.socket -> socket)
+func receiverName(node *sitter.Node, source []byte) string {
+	if node != nil && node.Type() == "member_expression" {
+		return analyzer.GetNodeText(analyzer.FindChildByFieldName(node, "property"), source)
+	}
+	return analyzer.GetNodeText(node, source)
+}
+
+// isFunction reports whether node is a function or arrow function expression
+func isFunction(node *sitter.Node) bool {
+	switch node.Type() {
+	case "arrow_function", "function_expression", "function":
+		return true
+	}
+	return false
+}
+
+// newRealtimeHandler builds the handler of a callback whose parameters
+// receive the message, except trailing acknowledgement callbacks
+func newRealtimeHandler(fn *sitter.Node, source []byte, protocol, framework, event, name string) *types.RealtimeHandler {
+	handler := &types.RealtimeHandler{
+		Protocol:  protocol,
+		Framework: framework,
+		Event:     event,
+		Function:  name,
+		Line:      int(fn.StartPoint().Row) + 1,
+		EndLine:   int(fn.EndPoint().Row) + 1,
+	}
+
+	var params []*sitter.Node
+	if single := analyzer.FindChildByFieldName(fn, "parameter"); single != nil {
+		params = append(params, single)
+	} else if list := analyzer.FindChildByFieldName(fn, "parameters"); list != nil {
+		for i := 0; i < int(list.NamedChildCount()); i++ {
+			params = append(params, list.NamedChild(i))
+		}
+	}
+	for _, param := range params {
+		// TypeScript wraps parameters: (required_parameter pattern: (identifier) type: ...)
+		if pattern := analyzer.FindChildByFieldName(param, "pattern"); pattern != nil {
+			param = pattern
+		}
+		if param.Type() != "identifier" {
+			continue
+		}
+		paramName := analyzer.GetNodeText(param, source)
+		if jsPatterns.RealtimeAckParams[paramName] {
+			continue
+		}
+		handler.Payloads = append(handler.Payloads, types.RealtimePayload{
+			Name:   paramName,
+			Line:   int(param.StartPoint().Row) + 1,
+			Column: int(param.StartPoint().Column),
+		})
+	}
+	return handler
+}
@@ -0,0 +1,87 @@
+package javascript
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// AssignmentTarget classifies the target of an assignment and returns its
+// access path: "property" and ["this", "#token"] for this.#token,
+// "array_element" and ["obj", "k", "0"] for obj['k'][0]. A computed key
+// (obj[key]) is "*", as it may be any key. The TypeScript analyzer shares it,
+// the two grammars having the same member and subscript expressions.
+func AssignmentTarget(node *sitter.Node, source []byte) (targetType string, keys []string) {
+	switch node.Type() {
+	case "identifier":
+		return "variable", nil
+	case "member_expression":
+		return "property", accessPath(node, source)
+	case "subscript_expression":
+		return "array_element", accessPath(node, source)
+	}
+	return "", nil
+}
+
+// accessPath returns the base and the keys of a member or subscript chain
+func accessPath(node *sitter.Node, source []byte) []string {
+	switch node.Type() {
+	case "member_expression":
+		object := node.ChildByFieldName("object")
+		property := node.ChildByFieldName("property")
+		if object == nil || property == nil {
+			break
+		}
+		return append(accessPath(object, source), analyzer.GetNodeText(property, source))
+	case "subscript_expression":
+		object := node.ChildByFieldName("object")
+		index := node.ChildByFieldName("index")
+		if object == nil || index == nil {
+			break
+		}
+		return append(accessPath(object, source), subscriptKey(index, source))
+	case "parenthesized_expression", "non_null_expression":
+		if inner := node.NamedChild(0); inner != nil {
+			return accessPath(inner, source)
+		}
+	}
+	return []string{analyzer.GetNodeText(node, source)}
+}
+
+// subscriptKey returns the key of a literal subscript, or "*" for a computed one
+func subscriptKey(index *sitter.Node, source []byte) string {
+	switch index.Type() {
+	case "string":
+		return strings.Trim(analyzer.GetNodeText(index, source), "\"'`")
+	case "number":
+		return analyzer.GetNodeText(index, source)
+	}
+	return "*"
+}
+
+// FieldAssignment returns a class field initializer (token = req.query.t,
+// #token = ...) as an assignment to this.<field>, or nil for a field
+// without one. The initializer runs when the class is constructed.
+func FieldAssignment(node *sitter.Node, source []byte, scope string) *types.Assignment {
+	nameNode := node.ChildByFieldName("property")
+	if nameNode == nil {
+		nameNode = node.ChildByFieldName("name")
+	}
+	valueNode := node.ChildByFieldName("value")
+	if nameNode == nil || valueNode == nil {
+		return nil
+	}
+	name := analyzer.GetNodeText(nameNode, source)
+	return &types.Assignment{
+		Target:     "this." + name,
+		TargetType: "property",
+		Source:     analyzer.GetNodeText(valueNode, source),
+		Line:       int(node.StartPoint().Row) + 1,
+		Column:     int(node.StartPoint().Column),
+		Scope:      scope,
+		Operator:   "=",
+		Keys:       []string{"this", name},
+	}
+}
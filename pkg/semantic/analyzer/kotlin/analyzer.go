@@ -0,0 +1,546 @@
+// Package kotlin implements the Kotlin language analyzer for semantic input tracing
+package kotlin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/parser/languages"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	kotlinPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/kotlin"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// KotlinAnalyzer implements the LanguageAnalyzer interface for Kotlin
+type KotlinAnalyzer struct {
+	*analyzer.BaseAnalyzer
+	inputSources map[string]types.SourceType
+}
+
+// NewKotlinAnalyzer creates a new Kotlin analyzer
+func NewKotlinAnalyzer() *KotlinAnalyzer {
+	m := sources.GetMappings("kotlin")
+	a := &KotlinAnalyzer{
+		BaseAnalyzer: analyzer.NewBaseAnalyzer("kotlin", languages.GetExtensionsForLanguage("kotlin")),
+		inputSources: m.GetInputSourcesMap(),
+	}
+
+	return a
+}
+
+func (a *KotlinAnalyzer) BuildSymbolTable(filePath string, source []byte, root *sitter.Node) (*types.SymbolTable, error) {
+	st := types.NewSymbolTable(filePath, "kotlin")
+	st.Imports = a.extractImports(root, source)
+
+	if header := analyzer.FindChildByType(root, "package_header"); header != nil {
+		if ident := analyzer.FindChildByType(header, "identifier"); ident != nil {
+			st.Namespace = analyzer.GetNodeText(ident, source)
+		}
+	}
+
+	classes, _ := a.ExtractClasses(root, source)
+	for _, class := range classes {
+		class.FilePath = filePath
+		st.Classes[class.Name] = class
+	}
+
+	functions, _ := a.ExtractFunctions(root, source)
+	for _, fn := range functions {
+		fn.FilePath = filePath
+		st.Functions[fn.Name] = fn
+	}
+
+	return st, nil
+}
+
+func (a *KotlinAnalyzer) extractImports(root *sitter.Node, source []byte) []types.ImportInfo {
+	var imports []types.ImportInfo
+
+	for _, node := range analyzer.FindNodesOfType(root, "import_header") {
+		ident := analyzer.FindChildByType(node, "identifier")
+		if ident == nil {
+			continue
+		}
+		imports = append(imports, types.ImportInfo{
+			Path: analyzer.GetNodeText(ident, source),
+			Line: int(node.StartPoint().Row) + 1,
+			Type: "import",
+		})
+	}
+
+	return imports
+}
+
+func (a *KotlinAnalyzer) ResolveImports(symbolTable *types.SymbolTable, basePath string) ([]string, error) {
+	return nil, nil
+}
+
+func (a *KotlinAnalyzer) ExtractClasses(root *sitter.Node, source []byte) ([]*types.ClassDef, error) {
+	var classes []*types.ClassDef
+
+	// Classes, interfaces and enum classes share class_declaration; objects
+	// (including companion objects) are singletons with methods
+	classNodes := analyzer.FindNodesOfTypes(root, []string{"class_declaration", "object_declaration"})
+	for _, classNode := range classNodes {
+		nameNode := analyzer.FindChildByType(classNode, "type_identifier")
+		if nameNode == nil {
+			continue
+		}
+
+		class := types.NewClassDef(analyzer.GetNodeText(nameNode, source), "", int(classNode.StartPoint().Row)+1)
+		class.EndLine = int(classNode.EndPoint().Row) + 1
+
+		if delegation := analyzer.FindChildByType(classNode, "delegation_specifier"); delegation != nil {
+			class.Extends = strings.TrimSuffix(analyzer.GetNodeText(delegation, source), "()")
+		}
+
+		body := analyzer.FindChildByType(classNode, "class_body")
+		if body == nil {
+			body = analyzer.FindChildByType(classNode, "enum_class_body")
+		}
+		if body != nil {
+			for _, fnNode := range analyzer.FindChildrenByType(body, "function_declaration") {
+				method := a.extractMethod(fnNode, source)
+				if method != nil {
+					class.Methods[method.Name] = method
+				}
+			}
+			for _, propNode := range analyzer.FindChildrenByType(body, "property_declaration") {
+				if name := a.declaredName(propNode, source); name != "" {
+					class.Properties[name] = &types.PropertyDef{
+						Name: name,
+						Line: int(propNode.StartPoint().Row) + 1,
+					}
+				}
+			}
+		}
+
+		classes = append(classes, class)
+	}
+
+	return classes, nil
+}
+
+// extractMethod builds a method definition from a function_declaration
+func (a *KotlinAnalyzer) extractMethod(fnNode *sitter.Node, source []byte) *types.MethodDef {
+	fn := a.extractFunction(fnNode, source)
+	if fn == nil {
+		return nil
+	}
+	return &types.MethodDef{
+		Name:       fn.Name,
+		Parameters: fn.Parameters,
+		ReturnType: fn.ReturnType,
+		Line:       fn.Line,
+		EndLine:    fn.EndLine,
+		BodyStart:  fn.BodyStart,
+		BodyEnd:    fn.BodyEnd,
+		BodySource: fn.BodySource,
+	}
+}
+
+func (a *KotlinAnalyzer) ExtractFunctions(root *sitter.Node, source []byte) ([]*types.FunctionDef, error) {
+	var functions []*types.FunctionDef
+
+	for _, fnNode := range analyzer.FindNodesOfType(root, "function_declaration") {
+		// Skip methods of classes and objects
+		if analyzer.GetAncestorOfType(fnNode, "class_body") != nil {
+			continue
+		}
+		if fn := a.extractFunction(fnNode, source); fn != nil {
+			functions = append(functions, fn)
+		}
+	}
+
+	return functions, nil
+}
+
+// extractFunction builds a function definition from a function_declaration.
+// Extension functions (fun Application.module()) are named after the function.
+func (a *KotlinAnalyzer) extractFunction(fnNode *sitter.Node, source []byte) *types.FunctionDef {
+	nameNode := analyzer.FindChildByType(fnNode, "simple_identifier")
+	if nameNode == nil {
+		return nil
+	}
+
+	fn := &types.FunctionDef{
+		Name:    analyzer.GetNodeText(nameNode, source),
+		Line:    int(fnNode.StartPoint().Row) + 1,
+		EndLine: int(fnNode.EndPoint().Row) + 1,
+	}
+
+	if paramsNode := analyzer.FindChildByType(fnNode, "function_value_parameters"); paramsNode != nil {
+		fn.Parameters = a.parseParameters(paramsNode, source)
+	}
+
+	// The return type follows the ':' after the parameters
+	afterParams := false
+	for i := 0; i < int(fnNode.ChildCount()); i++ {
+		child := fnNode.Child(i)
+		switch {
+		case child.Type() == "function_value_parameters":
+			afterParams = true
+		case afterParams && (child.Type() == "user_type" || child.Type() == "nullable_type"):
+			fn.ReturnType = analyzer.GetNodeText(child, source)
+		}
+	}
+
+	if bodyNode := analyzer.FindChildByType(fnNode, "function_body"); bodyNode != nil {
+		fn.BodyStart = int(bodyNode.StartPoint().Row) + 1
+		fn.BodyEnd = int(bodyNode.EndPoint().Row) + 1
+		fn.BodySource = analyzer.GetNodeText(bodyNode, source)
+	}
+
+	return fn
+}
+
+func (a *KotlinAnalyzer) parseParameters(node *sitter.Node, source []byte) []types.ParameterDef {
+	var params []types.ParameterDef
+
+	for _, paramNode := range analyzer.FindChildrenByType(node, "parameter") {
+		nameNode := analyzer.FindChildByType(paramNode, "simple_identifier")
+		if nameNode == nil {
+			continue
+		}
+		param := types.ParameterDef{
+			Name:  analyzer.GetNodeText(nameNode, source),
+			Index: len(params),
+		}
+		for i := 0; i < int(paramNode.ChildCount()); i++ {
+			if child := paramNode.Child(i); child.Type() == "user_type" || child.Type() == "nullable_type" {
+				param.Type = analyzer.GetNodeText(child, source)
+			}
+		}
+		if modifiers := parameterModifiers(paramNode); modifiers != nil && strings.Contains(analyzer.GetNodeText(modifiers, source), "vararg") {
+			param.IsVariadic = true
+		}
+		params = append(params, param)
+	}
+
+	return params
+}
+
+// parameterModifiers returns the modifiers (annotations, vararg) of a
+// parameter. The grammar places them before the parameter, as a sibling.
+func parameterModifiers(paramNode *sitter.Node) *sitter.Node {
+	if modifiers := analyzer.FindChildByType(paramNode, "parameter_modifiers"); modifiers != nil {
+		return modifiers
+	}
+	if prev := paramNode.PrevSibling(); prev != nil && prev.Type() == "parameter_modifiers" {
+		return prev
+	}
+	return nil
+}
+
+// declaredName returns the variable declared by a property_declaration
+func (a *KotlinAnalyzer) declaredName(node *sitter.Node, source []byte) string {
+	if decl := analyzer.FindChildByType(node, "variable_declaration"); decl != nil {
+		if ident := analyzer.FindChildByType(decl, "simple_identifier"); ident != nil {
+			return analyzer.GetNodeText(ident, source)
+		}
+	}
+	if decl := analyzer.FindChildByType(node, "multi_variable_declaration"); decl != nil {
+		return analyzer.GetNodeText(decl, source)
+	}
+	return ""
+}
+
+// valueAfter returns the first named child following the '=' of a declaration
+// or assignment
+func valueAfter(node *sitter.Node, operator string) *sitter.Node {
+	seen := false
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if seen && child.IsNamed() {
+			return child
+		}
+		if child.Type() == operator || child.Type() == "assignment_and_operator" {
+			seen = true
+		}
+	}
+	return nil
+}
+
+// inputParameters returns the controller parameters bound to request input by
+// a Spring annotation (@RequestParam, @PathVariable, ...)
+func (a *KotlinAnalyzer) inputParameters(root *sitter.Node, source []byte) map[string]*sitter.Node {
+	params := make(map[string]*sitter.Node)
+	for _, paramNode := range analyzer.FindNodesOfType(root, "parameter") {
+		if _, ok := a.parameterAnnotation(paramNode, source); !ok {
+			continue
+		}
+		if nameNode := analyzer.FindChildByType(paramNode, "simple_identifier"); nameNode != nil {
+			params[analyzer.GetNodeText(nameNode, source)] = paramNode
+		}
+	}
+	return params
+}
+
+// parameterAnnotation returns the input annotation on a parameter
+func (a *KotlinAnalyzer) parameterAnnotation(paramNode *sitter.Node, source []byte) (string, bool) {
+	modifiers := parameterModifiers(paramNode)
+	if modifiers == nil {
+		return "", false
+	}
+	for _, annotation := range analyzer.FindNodesOfType(modifiers, "annotation") {
+		typeNode := analyzer.FindNodesOfType(annotation, "type_identifier")
+		if len(typeNode) == 0 {
+			continue
+		}
+		name := analyzer.GetNodeText(typeNode[0], source)
+		if _, ok := kotlinPatterns.InputAnnotations[name]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (a *KotlinAnalyzer) ExtractAssignments(root *sitter.Node, source []byte, scope string) ([]*types.Assignment, error) {
+	var assignments []*types.Assignment
+	inputParams := a.inputParameters(root, source)
+
+	// val/var declarations
+	for _, node := range analyzer.FindNodesOfType(root, "property_declaration") {
+		name := a.declaredName(node, source)
+		valueNode := valueAfter(node, "=")
+		if name == "" || valueNode == nil {
+			continue
+		}
+		assignment := &types.Assignment{
+			Target: name,
+			Source: analyzer.GetNodeText(valueNode, source),
+			Line:   int(node.StartPoint().Row) + 1,
+			Column: int(node.StartPoint().Column),
+			Scope:  scope,
+		}
+		assignment.IsTainted, assignment.TaintSource = a.isExpressionTainted(valueNode, source, inputParams)
+		assignments = append(assignments, assignment)
+	}
+
+	// Reassignments (x = ..., x += ...)
+	for _, node := range analyzer.FindNodesOfType(root, "assignment") {
+		targetNode := analyzer.FindChildByType(node, "directly_assignable_expression")
+		valueNode := valueAfter(node, "=")
+		if targetNode == nil || valueNode == nil {
+			continue
+		}
+		assignment := &types.Assignment{
+			Target: analyzer.GetNodeText(targetNode, source),
+			Source: analyzer.GetNodeText(valueNode, source),
+			Line:   int(node.StartPoint().Row) + 1,
+			Column: int(node.StartPoint().Column),
+			Scope:  scope,
+		}
+		assignment.IsTainted, assignment.TaintSource = a.isExpressionTainted(valueNode, source, inputParams)
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, nil
+}
+
+// isExpressionTainted reports whether an expression reads an input source or
+// an annotated input parameter
+func (a *KotlinAnalyzer) isExpressionTainted(node *sitter.Node, source []byte, inputParams map[string]*sitter.Node) (bool, string) {
+	if node == nil {
+		return false, ""
+	}
+
+	text := analyzer.GetNodeText(node, source)
+	for pattern := range a.inputSources {
+		if strings.Contains(text, pattern) {
+			return true, pattern
+		}
+	}
+
+	tainted, taintSource := false, ""
+	analyzer.TraverseTree(node, func(n *sitter.Node) bool {
+		if n.Type() == "simple_identifier" {
+			if name := analyzer.GetNodeText(n, source); inputParams[name] != nil {
+				tainted, taintSource = true, name
+			}
+		}
+		return !tainted
+	})
+	return tainted, taintSource
+}
+
+func (a *KotlinAnalyzer) ExtractCalls(root *sitter.Node, source []byte, scope string) ([]*types.CallSite, error) {
+	var calls []*types.CallSite
+	inputParams := a.inputParameters(root, source)
+
+	for _, node := range analyzer.FindNodesOfType(root, "call_expression") {
+		if node.ChildCount() < 2 {
+			continue
+		}
+		calleeNode := node.Child(0)
+
+		call := &types.CallSite{
+			FunctionName: analyzer.GetNodeText(calleeNode, source),
+			Line:         int(node.StartPoint().Row) + 1,
+			Column:       int(node.StartPoint().Column),
+			Scope:        scope,
+			Arguments:    make([]types.CallArg, 0),
+		}
+
+		// Method call: receiver.method(...)
+		if calleeNode.Type() == "navigation_expression" {
+			if suffix := analyzer.FindChildByType(calleeNode, "navigation_suffix"); suffix != nil {
+				if method := analyzer.FindChildByType(suffix, "simple_identifier"); method != nil {
+					call.ClassName = analyzer.GetNodeText(calleeNode.Child(0), source)
+					call.MethodName = analyzer.GetNodeText(method, source)
+				}
+			}
+		}
+
+		if suffix := analyzer.FindChildByType(node, "call_suffix"); suffix != nil {
+			if argsNode := analyzer.FindChildByType(suffix, "value_arguments"); argsNode != nil {
+				call.Arguments = a.parseCallArguments(argsNode, source, inputParams)
+			}
+		}
+
+		for i, arg := range call.Arguments {
+			if arg.IsTainted {
+				call.HasTaintedArgs = true
+				call.TaintedArgIndices = append(call.TaintedArgIndices, i)
+			}
+		}
+
+		calls = append(calls, call)
+	}
+
+	return calls, nil
+}
+
+func (a *KotlinAnalyzer) parseCallArguments(node *sitter.Node, source []byte, inputParams map[string]*sitter.Node) []types.CallArg {
+	var args []types.CallArg
+
+	for _, argNode := range analyzer.FindChildrenByType(node, "value_argument") {
+		arg := types.CallArg{
+			Index: len(args),
+			Value: analyzer.GetNodeText(argNode, source),
+		}
+		arg.IsTainted, arg.TaintSource = a.isExpressionTainted(argNode, source, inputParams)
+		args = append(args, arg)
+	}
+
+	return args
+}
+
+func (a *KotlinAnalyzer) FindInputSources(root *sitter.Node, source []byte) ([]*types.FlowNode, error) {
+	var sources []*types.FlowNode
+
+	// Ktor call members (call.parameters, call.receive<T>(), ...) and
+	// standard library reads (System.getenv, readLine)
+	analyzer.TraverseTree(root, func(node *sitter.Node) bool {
+		var name string
+		switch node.Type() {
+		case "navigation_expression":
+			name = analyzer.GetNodeText(node, source)
+		case "call_expression":
+			if callee := node.Child(0); callee != nil && callee.Type() == "simple_identifier" {
+				name = analyzer.GetNodeText(callee, source)
+			}
+		}
+		sourceType, ok := a.inputSources[name]
+		if !ok {
+			return true
+		}
+
+		// Report the whole read: the call or subscript of the member
+		reportNode := node
+		if parent := node.Parent(); parent != nil && node.Type() == "navigation_expression" &&
+			(parent.Type() == "call_expression" || parent.Type() == "indexing_expression") && parent.Child(0) == node {
+			reportNode = parent
+		}
+		sources = append(sources, &types.FlowNode{
+			ID:         analyzer.GenerateNodeID("", reportNode),
+			Type:       types.NodeSource,
+			Language:   "kotlin",
+			Line:       int(reportNode.StartPoint().Row) + 1,
+			Column:     int(reportNode.StartPoint().Column),
+			Name:       name,
+			Snippet:    analyzer.GetNodeText(reportNode, source),
+			SourceType: sourceType,
+		})
+		return false
+	})
+
+	// Spring controller parameters (@RequestParam name: String, ...)
+	for name, paramNode := range a.inputParameters(root, source) {
+		annotation, _ := a.parameterAnnotation(paramNode, source)
+		sources = append(sources, &types.FlowNode{
+			ID:         analyzer.GenerateNodeID("", paramNode),
+			Type:       types.NodeSource,
+			Language:   "kotlin",
+			Line:       int(paramNode.StartPoint().Row) + 1,
+			Column:     int(paramNode.StartPoint().Column),
+			Name:       name,
+			Snippet:    "@" + annotation + " " + analyzer.GetNodeText(paramNode, source),
+			SourceType: types.SourceType(kotlinPatterns.InputAnnotations[annotation]),
+		})
+	}
+
+	return sources, nil
+}
+
+func (a *KotlinAnalyzer) DetectFrameworks(symbolTable *types.SymbolTable, source []byte) ([]string, error) {
+	var frameworks []string
+
+	seen := make(map[string]bool)
+	for _, imp := range symbolTable.Imports {
+		var framework string
+		switch {
+		case strings.HasPrefix(imp.Path, "io.ktor"):
+			framework = "Ktor"
+		case strings.HasPrefix(imp.Path, "org.springframework"):
+			framework = "Spring"
+		}
+		if framework != "" && !seen[framework] {
+			seen[framework] = true
+			frameworks = append(frameworks, framework)
+		}
+	}
+
+	return frameworks, nil
+}
+
+func (a *KotlinAnalyzer) AnalyzeMethodBody(method *types.MethodDef, source []byte, state *types.AnalysisState) (*analyzer.MethodFlowAnalysis, error) {
+	return &analyzer.MethodFlowAnalysis{
+		ParamsToReturn:     make([]int, 0),
+		ParamsToProperties: make(map[int][]string),
+		ParamsToCallArgs:   make(map[int][]*types.CallSite),
+		TaintedVariables:   make(map[string]*types.TaintInfo),
+		Assignments:        make([]*types.Assignment, 0),
+		Calls:              make([]*types.CallSite, 0),
+		Returns:            make([]analyzer.ReturnInfo, 0),
+	}, nil
+}
+
+func (a *KotlinAnalyzer) TraceExpression(target types.FlowTarget, state *types.AnalysisState) (*types.FlowMap, error) {
+	flowMap := types.NewFlowMap()
+	flowMap.Target = target
+
+	expr := target.Expression
+
+	for fn, sourceType := range a.inputSources {
+		if strings.Contains(expr, fn) {
+			sourceNode := types.FlowNode{
+				ID:         fmt.Sprintf("source-%s", fn),
+				Type:       types.NodeSource,
+				Language:   "kotlin",
+				Name:       fn,
+				Snippet:    expr,
+				SourceType: sourceType,
+			}
+			flowMap.AddSource(sourceNode)
+		}
+	}
+
+	return flowMap, nil
+}
+
+func init() {
+	analyzer.DefaultRegistry.Register(NewKotlinAnalyzer())
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
 	sitter "github.com/smacker/go-tree-sitter"
 )
@@ -43,11 +44,25 @@ func NewPHPAnalyzer() *PHPAnalyzer {
 // Note: Universal pattern detection now uses centralized patterns from phpPatterns package
 // See pkg/sources/php/patterns.go for InputMethodPattern, InputPropertyPattern, etc.
 
+// WithFrameworkPatterns returns a copy of the analyzer matching patterns
+// instead of the ones registered in pkg/sources/php
+func (a *PHPAnalyzer) WithFrameworkPatterns(patterns []*common.FrameworkPattern) analyzer.LanguageAnalyzer {
+	c := *a
+	c.BaseAnalyzer = a.WithoutFrameworkPatterns()
+	c.addFrameworkPatterns(patterns)
+	return &c
+}
+
 // registerFrameworkPatterns loads PHP framework patterns from pkg/sources/php
 // This centralizes all framework patterns in one place
 func (a *PHPAnalyzer) registerFrameworkPatterns() {
 	// Load all patterns from pkg/sources/php registry
-	for _, p := range phpPatterns.GetAllPatterns() {
+	a.addFrameworkPatterns(phpPatterns.GetAllPatterns())
+}
+
+// addFrameworkPatterns converts framework patterns from pkg/sources and adds them
+func (a *PHPAnalyzer) addFrameworkPatterns(patterns []*common.FrameworkPattern) {
+	for _, p := range patterns {
 		// Convert common.FrameworkPattern to types.FrameworkPattern
 		fp := &types.FrameworkPattern{
 			ID:              p.ID,
@@ -98,6 +113,9 @@ func (a *PHPAnalyzer) BuildSymbolTable(filePath string, source []byte, root *sit
 		st.Functions[fn.Name] = fn
 	}
 
+	// Extract constants (const, define() and class constants) for key folding
+	st.Constants = a.extractConstants(root, source)
+
 	// Detect frameworks
 	frameworks, _ := a.DetectFrameworks(st, source)
 	if len(frameworks) > 0 {
@@ -139,6 +157,14 @@ func (a *PHPAnalyzer) extractImports(root *sitter.Node, source []byte) []types.I
 						Type:       strings.ReplaceAll(incType, "_expression", ""),
 						IsRelative: !strings.HasPrefix(path, "/") && !strings.Contains(path, "://"),
 					})
+				} else if path, ok := phpPatterns.FileRelativeIncludePath(analyzer.GetNodeText(child, source)); ok && child.IsNamed() {
+					// __DIR__ . '/lib.php' is relative to the including file
+					imports = append(imports, types.ImportInfo{
+						Path:       path,
+						Line:       int(node.StartPoint().Row) + 1,
+						Type:       strings.ReplaceAll(incType, "_expression", ""),
+						IsRelative: true,
+					})
 				}
 			}
 		}
@@ -623,6 +649,12 @@ func (a *PHPAnalyzer) parseAssignment(node *sitter.Node, source []byte, scope st
 		return nil
 	}
 
+	// Qualify the assignment with its enclosing function/method scope so
+	// same-named locals in different functions are never confused
+	if scope == "" {
+		scope = analyzer.ScopeIDForNode(node, source, "php")
+	}
+
 	assignment := &types.Assignment{
 		Target:   analyzer.GetNodeText(leftNode, source),
 		Source:   analyzer.GetNodeText(rightNode, source),
@@ -844,7 +876,7 @@ func (a *PHPAnalyzer) parseStaticCall(node *sitter.Node, source []byte, scope st
 	}
 
 	// Get class name
-	scopeNode := analyzer.FindChildByType(node, "scope_resolution_qualifier")
+	scopeNode := analyzer.FindChildByFieldName(node, "scope")
 	if scopeNode == nil {
 		scopeNode = node.Child(0)
 	}
@@ -852,8 +884,8 @@ func (a *PHPAnalyzer) parseStaticCall(node *sitter.Node, source []byte, scope st
 		call.ClassName = analyzer.GetNodeText(scopeNode, source)
 	}
 
-	// Get method name
-	nameNode := analyzer.FindChildByType(node, "name")
+	// Get method name (the scope is a name node too)
+	nameNode := analyzer.FindChildByFieldName(node, "name")
 	if nameNode != nil {
 		call.MethodName = analyzer.GetNodeText(nameNode, source)
 		call.FunctionName = call.ClassName + "::" + call.MethodName
@@ -918,15 +950,30 @@ func (a *PHPAnalyzer) parseCallArguments(node *sitter.Node, source []byte) []typ
 	return args
 }
 
+// serverKeySourceType returns the source type of a request key of $_SERVER
+func serverKeySourceType(key string) (types.SourceType, bool) {
+	st := sources.GetServerKeySourceType(key)
+	return st, st != sources.SourceUnknown
+}
+
 // FindInputSources finds all user input sources in the AST
 func (a *PHPAnalyzer) FindInputSources(root *sitter.Node, source []byte) ([]*types.FlowNode, error) {
 	var sources []*types.FlowNode
 
+	// Constants of this file, used to fold keys like $_GET[PARAM_NAME]
+	constants := a.extractConstants(root, source)
+
 	// Find superglobal accesses
 	varNodes := analyzer.FindNodesOfType(root, "variable_name")
 	for _, node := range varNodes {
 		text := analyzer.GetNodeText(node, source)
-		if sourceType, ok := a.superglobals[text]; ok {
+		sourceType, ok := a.superglobals[text]
+		if !ok {
+			// $argv and $argc only where they are the script's globals
+			sourceType, ok = phpPatterns.CLIArgVariables[text]
+			ok = ok && isScriptGlobal(node, source)
+		}
+		if ok {
 			flowNode := &types.FlowNode{
 				ID:         analyzer.GenerateNodeID("", node),
 				Type:       types.NodeSource,
@@ -944,13 +991,21 @@ func (a *PHPAnalyzer) FindInputSources(root *sitter.Node, source []byte) ([]*typ
 				// Get the full expression
 				flowNode.Snippet = analyzer.GetNodeText(parent, source)
 
-				// Extract the key
-				for i := 0; i < int(parent.ChildCount()); i++ {
-					child := parent.Child(i)
-					if child.Type() == "string" || child.Type() == "encapsed_string" {
-						key := analyzer.GetNodeText(child, source)
-						flowNode.SourceKey = strings.Trim(key, "\"'")
-						break
+				// Extract the key (literal or folded constant expression)
+				setSourceKey(flowNode, parent, source, constants)
+
+				// $_SERVER['argv'][1] is a command line argument
+				if text == "$_SERVER" && phpPatterns.ServerCLIKeys[flowNode.SourceKey] {
+					flowNode.SourceType = types.SourceCLIArg
+					flowNode.SourceKey = ""
+					if outer := parent.Parent(); outer != nil && outer.Type() == "subscript_expression" && outer.NamedChild(0) == parent {
+						flowNode.Snippet = analyzer.GetNodeText(outer, source)
+						setSourceKey(flowNode, outer, source, constants)
+					}
+				} else if text == "$_SERVER" {
+					// REQUEST_URI is the request path, QUERY_STRING the query, HTTP_COOKIE cookies
+					if st, ok := serverKeySourceType(flowNode.SourceKey); ok {
+						flowNode.SourceType = st
 					}
 				}
 			}
@@ -998,6 +1053,13 @@ func (a *PHPAnalyzer) FindInputSources(root *sitter.Node, source []byte) ([]*typ
 			sources = append(sources, flowNode)
 		}
 
+		// array_shift($argv) and friends read positional command line arguments
+		if position, ok := phpPatterns.ArgvHelperFunctions[strings.ToLower(funcName)]; ok {
+			if flowNode := a.argvHelperSource(node, strings.ToLower(funcName), position, source); flowNode != nil {
+				sources = append(sources, flowNode)
+			}
+		}
+
 		// Check for database fetch functions
 		if a.dbFetchFunctions[funcName] {
 			flowNode := &types.FlowNode{
@@ -1038,6 +1100,12 @@ func (a *PHPAnalyzer) FindInputSources(root *sitter.Node, source []byte) ([]*typ
 			sources = append(sources, flowNode)
 		}
 
+		// Symfony bag methods take their source type from the bag ($request->query->get())
+		if flowNode := a.symfonyBagSource(node, source, constants); flowNode != nil {
+			sources = append(sources, flowNode)
+			continue
+		}
+
 		// Universal pattern-based method detection (uses centralized patterns from phpPatterns)
 		// This detects ANY method that looks like an input getter, not just specific frameworks
 		isInputMethod := phpPatterns.IsInputMethod(methodName)
@@ -1123,15 +1191,8 @@ func (a *PHPAnalyzer) FindInputSources(root *sitter.Node, source []byte) ([]*typ
 				SourceType: sourceType,
 			}
 
-			// Extract the key from the subscript
-			for i := 0; i < int(node.ChildCount()); i++ {
-				child := node.Child(i)
-				if child.Type() == "string" || child.Type() == "encapsed_string" {
-					key := analyzer.GetNodeText(child, source)
-					flowNode.SourceKey = strings.Trim(key, "\"'")
-					break
-				}
-			}
+			// Extract the key from the subscript (literal or folded constant expression)
+			setSourceKey(flowNode, node, source, constants)
 
 			sources = append(sources, flowNode)
 		}
@@ -0,0 +1,143 @@
+package php
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// argvHelperSource returns a source for array_shift($argv), end($argv),
+// array_slice($_SERVER['argv'], 1), ... with the positional index as key,
+// or nil if the call does not read a command line argument array
+func (a *PHPAnalyzer) argvHelperSource(call *sitter.Node, funcName string, position phpPatterns.ArgvPosition, source []byte) *types.FlowNode {
+	args := analyzer.FindChildrenByType(analyzer.FindChildByFieldName(call, "arguments"), "argument")
+	if len(args) == 0 || !a.isCLIArgArray(args[0].NamedChild(0), source) {
+		return nil
+	}
+	array := analyzer.GetNodeText(args[0], source)
+
+	var key string
+	switch position {
+	case phpPatterns.ArgvShift:
+		key = strconv.Itoa(countPrecedingCalls(call, funcName, array, source))
+	case phpPatterns.ArgvFirst:
+		key = "0"
+	case phpPatterns.ArgvLast:
+		if funcName == "array_pop" {
+			key = strconv.Itoa(-1 - countPrecedingCalls(call, funcName, array, source))
+		} else {
+			key = "-1"
+		}
+	case phpPatterns.ArgvSlice:
+		if len(args) > 1 {
+			if offset := args[1].NamedChild(0); offset != nil && offset.Type() == "integer" {
+				key = analyzer.GetNodeText(offset, source) + ":"
+			}
+		}
+	}
+
+	return &types.FlowNode{
+		ID:         analyzer.GenerateNodeID("", call),
+		Type:       types.NodeSource,
+		Language:   "php",
+		Line:       int(call.StartPoint().Row) + 1,
+		Column:     int(call.StartPoint().Column),
+		Name:       funcName,
+		Snippet:    analyzer.GetNodeText(call, source),
+		SourceType: types.SourceCLIArg,
+		SourceKey:  key,
+	}
+}
+
+// isCLIArgArray reports whether node is the script's $argv or $_SERVER['argv']
+func (a *PHPAnalyzer) isCLIArgArray(node *sitter.Node, source []byte) bool {
+	if node == nil {
+		return false
+	}
+	switch node.Type() {
+	case "variable_name":
+		_, ok := phpPatterns.CLIArgVariables[analyzer.GetNodeText(node, source)]
+		return ok && isScriptGlobal(node, source)
+	case "subscript_expression":
+		if node.NamedChildCount() < 2 || analyzer.GetNodeText(node.NamedChild(0), source) != "$_SERVER" {
+			return false
+		}
+		key, ok := stringLiteral(node.NamedChild(1), source)
+		return ok && phpPatterns.ServerCLIKeys[key]
+	}
+	return false
+}
+
+// isScriptGlobal reports whether a variable read refers to the script's global
+// of that name: it is at file scope, in a function declaring it `global`, or
+// captured from one of those by an arrow function or a closure's use clause.
+// The declarations themselves are not reads.
+func isScriptGlobal(node *sitter.Node, source []byte) bool {
+	if parent := node.Parent(); parent != nil &&
+		(parent.Type() == "global_declaration" || parent.Type() == "anonymous_function_use_clause") {
+		return false
+	}
+	name := analyzer.GetNodeText(node, source)
+	for scope := enclosingScope(node); ; scope = enclosingScope(scope.Parent()) {
+		switch scope.Type() {
+		case "arrow_function":
+			continue // Captures the enclosing scope by value
+		case "anonymous_function_creation_expression":
+			if declaresVariable(analyzer.FindChildByType(scope, "anonymous_function_use_clause"), name, node, source) {
+				continue
+			}
+			return declaresGlobal(scope, name, node, source)
+		case "function_definition", "method_declaration":
+			return declaresGlobal(scope, name, node, source)
+		default:
+			return true // File scope
+		}
+	}
+}
+
+// declaresGlobal reports whether a function declares name `global` before use
+func declaresGlobal(scope *sitter.Node, name string, use *sitter.Node, source []byte) bool {
+	for _, decl := range analyzer.FindNodesOfType(scope, "global_declaration") {
+		if enclosingScope(decl) == scope && declaresVariable(decl, name, use, source) {
+			return true
+		}
+	}
+	return false
+}
+
+// declaresVariable reports whether a declaration lists name before use
+func declaresVariable(decl *sitter.Node, name string, use *sitter.Node, source []byte) bool {
+	if decl == nil || decl.StartByte() > use.StartByte() {
+		return false
+	}
+	for _, v := range analyzer.FindChildrenByType(decl, "variable_name") {
+		if analyzer.GetNodeText(v, source) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// countPrecedingCalls counts calls to funcName on the same array earlier in
+// the enclosing scope, e.g. the number of array_shift($argv) before this one
+func countPrecedingCalls(call *sitter.Node, funcName, array string, source []byte) int {
+	count := 0
+	for _, other := range analyzer.FindNodesOfType(enclosingScope(call), "function_call_expression") {
+		if other.StartByte() >= call.StartByte() {
+			continue
+		}
+		nameNode := analyzer.FindChildByFieldName(other, "function")
+		if nameNode == nil || !strings.EqualFold(analyzer.GetNodeText(nameNode, source), funcName) {
+			continue
+		}
+		args := analyzer.FindChildrenByType(analyzer.FindChildByFieldName(other, "arguments"), "argument")
+		if len(args) > 0 && analyzer.GetNodeText(args[0], source) == array {
+			count++
+		}
+	}
+	return count
+}
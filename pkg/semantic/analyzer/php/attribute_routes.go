@@ -0,0 +1,151 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// FindAttributeRoutes returns the routes declared by #[Route] attributes on
+// the methods of a file's classes. A #[Route] on the class prefixes its
+// methods' paths and names, and routes an invokable class to __invoke.
+func (a *PHPAnalyzer) FindAttributeRoutes(root *sitter.Node, source []byte) []*types.AttributeRoute {
+	var routes []*types.AttributeRoute
+
+	for _, class := range analyzer.FindNodesOfType(root, "class_declaration") {
+		body := analyzer.FindChildByFieldName(class, "body")
+		if body == nil {
+			continue
+		}
+		className := analyzer.GetNodeText(analyzer.FindChildByFieldName(class, "name"), source)
+		classRoutes := routeAttributes(class, source)
+		prefix := &routeAttribute{}
+		if len(classRoutes) > 0 {
+			prefix = classRoutes[0]
+		}
+
+		invokable := false
+		for _, method := range analyzer.FindChildrenByType(body, "method_declaration") {
+			methodName := analyzer.GetNodeText(analyzer.FindChildByFieldName(method, "name"), source)
+			invokable = invokable || strings.EqualFold(methodName, "__invoke")
+			for _, attr := range routeAttributes(method, source) {
+				attr.name = prefix.name + attr.name
+				routes = append(routes, attr.routes(prefix.paths, className+"::"+methodName, int(method.StartPoint().Row)+1)...)
+			}
+		}
+		if invokable {
+			for _, attr := range classRoutes {
+				routes = append(routes, attr.routes(nil, className+"::__invoke", int(class.StartPoint().Row)+1)...)
+			}
+		}
+	}
+	return routes
+}
+
+// routeAttribute holds the arguments of one #[Route] attribute
+type routeAttribute struct {
+	framework string
+	paths     []string // Several for localized routes
+	methods   []string
+	name      string
+}
+
+// routes returns a route per path of the attribute, under the first of the
+// class-level prefixes
+func (r *routeAttribute) routes(prefixes []string, function string, line int) []*types.AttributeRoute {
+	prefix := ""
+	if len(prefixes) > 0 {
+		prefix = prefixes[0]
+	}
+	paths := r.paths
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+	var routes []*types.AttributeRoute
+	for _, p := range paths {
+		routes = append(routes, &types.AttributeRoute{
+			Framework: r.framework,
+			Path:      phpPatterns.JoinRoutePaths(prefix, p),
+			Methods:   r.methods,
+			Name:      r.name,
+			Function:  function,
+			Line:      line,
+		})
+	}
+	return routes
+}
+
+// routeAttributes returns the #[Route] attributes of a class or method
+// declaration, reading the path from the first positional argument or path:
+func routeAttributes(decl *sitter.Node, source []byte) []*routeAttribute {
+	list := analyzer.FindChildByFieldName(decl, "attributes")
+	if list == nil {
+		return nil
+	}
+	var attrs []*routeAttribute
+	for _, attr := range analyzer.FindNodesOfType(list, "attribute") {
+		nameNode := analyzer.FindChildByType(attr, "qualified_name")
+		if nameNode == nil {
+			nameNode = analyzer.FindChildByType(attr, "name")
+		}
+		framework := phpPatterns.RouteAttributeFramework(analyzer.GetNodeText(nameNode, source))
+		if framework == "" {
+			continue
+		}
+		route := &routeAttribute{framework: framework}
+		if args := analyzer.FindChildByFieldName(attr, "parameters"); args != nil {
+			positional := 0
+			for _, arg := range analyzer.FindChildrenByType(args, "argument") {
+				if arg.NamedChildCount() == 0 {
+					continue
+				}
+				value := arg.NamedChild(int(arg.NamedChildCount()) - 1)
+				param := ""
+				if name := analyzer.FindChildByFieldName(arg, "name"); name != nil {
+					param = strings.ToLower(analyzer.GetNodeText(name, source))
+				} else {
+					positional++
+					if positional == 1 {
+						param = "path"
+					}
+				}
+				switch param {
+				case "path":
+					route.paths = literalStrings(value, source)
+				case "methods":
+					for _, m := range literalStrings(value, source) {
+						route.methods = append(route.methods, strings.ToUpper(m))
+					}
+				case "name":
+					route.name, _ = stringLiteral(value, source)
+				}
+			}
+		}
+		attrs = append(attrs, route)
+	}
+	return attrs
+}
+
+// literalStrings returns a string literal, or the string values of an array
+// literal such as a list of methods or a map of localized paths
+func literalStrings(node *sitter.Node, source []byte) []string {
+	if s, ok := stringLiteral(node, source); ok {
+		return []string{s}
+	}
+	if node.Type() != "array_creation_expression" {
+		return nil
+	}
+	var values []string
+	for _, element := range analyzer.FindChildrenByType(node, "array_element_initializer") {
+		if element.NamedChildCount() == 0 {
+			continue
+		}
+		if s, ok := stringLiteral(element.NamedChild(int(element.NamedChildCount())-1), source); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
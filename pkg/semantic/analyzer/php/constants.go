@@ -0,0 +1,135 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// extractConstants collects `const NAME = ...;`, `define('NAME', ...)` and class
+// constants (keyed "Class::NAME") with their unevaluated value expressions
+func (a *PHPAnalyzer) extractConstants(root *sitter.Node, source []byte) map[string]*types.ConstantDef {
+	constants := make(map[string]*types.ConstantDef)
+
+	for _, elem := range analyzer.FindNodesOfType(root, "const_element") {
+		if elem.NamedChildCount() < 2 {
+			continue
+		}
+		name := analyzer.GetNodeText(elem.NamedChild(0), source)
+		if className := enclosingClassName(elem, source); className != "" {
+			name = className + "::" + name
+		}
+		constants[name] = &types.ConstantDef{
+			Name:  name,
+			Value: analyzer.GetNodeText(elem.NamedChild(int(elem.NamedChildCount())-1), source),
+			Line:  int(elem.StartPoint().Row) + 1,
+		}
+	}
+
+	for _, call := range analyzer.FindNodesOfType(root, "function_call_expression") {
+		nameNode := analyzer.FindChildByFieldName(call, "function")
+		if nameNode == nil || !strings.EqualFold(analyzer.GetNodeText(nameNode, source), "define") {
+			continue
+		}
+		argsNode := analyzer.FindChildByType(call, "arguments")
+		if argsNode == nil {
+			continue
+		}
+		args := analyzer.FindChildrenByType(argsNode, "argument")
+		if len(args) < 2 {
+			continue
+		}
+		name, ok := stringLiteral(args[0].NamedChild(0), source)
+		if !ok || name == "" {
+			continue
+		}
+		constants[name] = &types.ConstantDef{
+			Name:  name,
+			Value: analyzer.GetNodeText(args[1], source),
+			Line:  int(call.StartPoint().Row) + 1,
+		}
+	}
+
+	return constants
+}
+
+// enclosingClassName returns the name of the class-like declaration containing node
+func enclosingClassName(node *sitter.Node, source []byte) string {
+	for cur := node.Parent(); cur != nil; cur = cur.Parent() {
+		switch cur.Type() {
+		case "class_declaration", "interface_declaration", "trait_declaration", "enum_declaration":
+			if nameNode := analyzer.FindChildByFieldName(cur, "name"); nameNode != nil {
+				return analyzer.GetNodeText(nameNode, source)
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// ConstantLookup returns a lookup over a constant table for
+// phpPatterns.FoldConstantExpression; self:: and static:: resolve against className
+func ConstantLookup(constants map[string]*types.ConstantDef, className string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		if m := phpPatterns.ClassConstantPattern.FindStringSubmatch(name); m != nil {
+			class := m[1]
+			if strings.EqualFold(class, "self") || strings.EqualFold(class, "static") {
+				class = className
+			}
+			// Drop the namespace: class constants are keyed by short class name
+			if i := strings.LastIndex(class, "\\"); i >= 0 {
+				class = class[i+1:]
+			}
+			name = class + "::" + m[2]
+		}
+		if def, ok := constants[name]; ok {
+			return def.Value, true
+		}
+		return "", false
+	}
+}
+
+// subscriptKey resolves the key of a subscript expression: a literal directly,
+// or a constant expression folded against the file's constants. When folding
+// needs constants from other files, the key expression is returned instead.
+func subscriptKey(subscript *sitter.Node, source []byte, constants map[string]*types.ConstantDef) (key, keyExpr string) {
+	if subscript.NamedChildCount() < 2 {
+		return "", ""
+	}
+	index := subscript.NamedChild(1)
+	text := analyzer.GetNodeText(index, source)
+	if value, ok := stringLiteral(index, source); ok {
+		return value, ""
+	}
+	if index.Type() == "integer" {
+		return text, "" // Positional index ($_GET[0], $argv[1])
+	}
+	if phpPatterns.IsLiteralKey(text) {
+		return "", "" // Variable index
+	}
+	if value, ok := phpPatterns.FoldConstantExpression(text, ConstantLookup(constants, enclosingClassName(subscript, source))); ok {
+		return value, ""
+	}
+	return "", text
+}
+
+// setSourceKey sets the key of a subscript source. Keys that depend on constants
+// defined in other files are kept in Metadata["key_expr"] (with the enclosing
+// class in Metadata["key_class"]) for the tracer to fold once all files are parsed.
+func setSourceKey(flowNode *types.FlowNode, subscript *sitter.Node, source []byte, constants map[string]*types.ConstantDef) {
+	key, keyExpr := subscriptKey(subscript, source, constants)
+	if key != "" || keyExpr == "" {
+		flowNode.SourceKey = key
+		return
+	}
+	if flowNode.Metadata == nil {
+		flowNode.Metadata = make(map[string]interface{})
+	}
+	flowNode.Metadata["key_expr"] = keyExpr
+	if className := enclosingClassName(subscript, source); className != "" {
+		flowNode.Metadata["key_class"] = className
+	}
+}
@@ -0,0 +1,142 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// statementListTypes are the nodes whose children run in sequence
+var statementListTypes = []string{"program", "compound_statement", "colon_block", "case_statement", "default_statement"}
+
+// hoistedTypes are declarations that take effect even when written after an exit
+var hoistedTypes = map[string]bool{
+	"function_definition":   true,
+	"class_declaration":     true,
+	"interface_declaration": true,
+	"trait_declaration":     true,
+	"enum_declaration":      true,
+	"comment":               true,
+}
+
+// FindDeadRanges finds the statements that follow one which always leaves
+// its block: exit, die(), return, throw, break, continue, a call to a
+// function that ends the request (wp_die(), ...) or an if/else whose every
+// branch does. Function and class declarations after it are not dead, since
+// PHP declares them before running the file.
+func (a *PHPAnalyzer) FindDeadRanges(root *sitter.Node, source []byte) []types.DeadRange {
+	var ranges []types.DeadRange
+
+	for _, block := range analyzer.FindNodesOfTypes(root, statementListTypes) {
+		var terminator *sitter.Node
+		var kind string
+		for i := 0; i < int(block.NamedChildCount()); i++ {
+			stmt := block.NamedChild(i)
+			if terminator == nil {
+				kind = terminates(stmt, source)
+				if kind != "" {
+					terminator = stmt
+				}
+				continue
+			}
+			if hoistedTypes[stmt.Type()] {
+				continue
+			}
+
+			start, end := int(stmt.StartPoint().Row)+1, int(stmt.EndPoint().Row)+1
+			termLine := int(terminator.StartPoint().Row) + 1
+			if start <= int(terminator.EndPoint().Row)+1 {
+				start = int(terminator.EndPoint().Row) + 2 // Code sharing the terminator's line is kept
+			}
+			if start > end {
+				continue
+			}
+			if n := len(ranges); n > 0 && ranges[n-1].TerminatorLine == termLine && ranges[n-1].EndLine >= start-1 {
+				ranges[n-1].EndLine = end
+				continue
+			}
+			ranges = append(ranges, types.DeadRange{
+				StartLine:      start,
+				EndLine:        end,
+				Terminator:     kind,
+				TerminatorLine: termLine,
+			})
+		}
+	}
+
+	return ranges
+}
+
+// terminates returns what makes a statement always leave its block (exit,
+// die(), return, ...), or "" if it may fall through
+func terminates(stmt *sitter.Node, source []byte) string {
+	if stmt == nil {
+		return ""
+	}
+	switch stmt.Type() {
+	case "exit_statement":
+		return "exit"
+	case "return_statement":
+		return "return"
+	case "break_statement":
+		return "break"
+	case "continue_statement":
+		return "continue"
+	case "expression_statement":
+		expr := stmt.NamedChild(0)
+		if expr == nil {
+			return ""
+		}
+		switch expr.Type() {
+		case "throw_expression":
+			return "throw"
+		case "name": // die; without parentheses
+			if name := strings.ToLower(analyzer.GetNodeText(expr, source)); phpPatterns.RejectFunctions[name] {
+				return name
+			}
+		case "function_call_expression":
+			if nameNode := analyzer.FindChildByFieldName(expr, "function"); nameNode != nil {
+				name := strings.ToLower(strings.TrimPrefix(analyzer.GetNodeText(nameNode, source), "\\"))
+				if phpPatterns.RejectFunctions[name] {
+					return name + "()"
+				}
+			}
+		}
+	case "compound_statement", "colon_block":
+		for i := 0; i < int(stmt.NamedChildCount()); i++ {
+			if kind := terminates(stmt.NamedChild(i), source); kind != "" {
+				return kind
+			}
+		}
+	case "if_statement":
+		return ifTerminates(stmt, source)
+	}
+	return ""
+}
+
+// ifTerminates returns what makes every branch of an if statement leave its
+// block, or "" if a branch may fall through or there is no else
+func ifTerminates(stmt *sitter.Node, source []byte) string {
+	kind := terminates(analyzer.FindChildByFieldName(stmt, "body"), source)
+	if kind == "" {
+		return ""
+	}
+	hasElse := false
+	for i := 0; i < int(stmt.NamedChildCount()); i++ {
+		clause := stmt.NamedChild(i)
+		switch clause.Type() {
+		case "else_if_clause", "else_clause":
+			if terminates(analyzer.FindChildByFieldName(clause, "body"), source) == "" {
+				return ""
+			}
+			hasElse = hasElse || clause.Type() == "else_clause"
+		}
+	}
+	if !hasElse {
+		return ""
+	}
+	return kind
+}
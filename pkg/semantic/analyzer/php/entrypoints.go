@@ -0,0 +1,53 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// declarationNodeTypes are top-level statements that only declare symbols
+var declarationNodeTypes = map[string]bool{
+	"php_tag":                   true,
+	"comment":                   true,
+	"namespace_use_declaration": true,
+	"class_declaration":         true,
+	"interface_declaration":     true,
+	"trait_declaration":         true,
+	"enum_declaration":          true,
+	"function_definition":       true,
+	"const_declaration":         true,
+	"declare_statement":         true,
+}
+
+// HasTopLevelCode reports whether a file executes code when requested directly,
+// i.e. it is more than class/function declarations (inline HTML counts)
+func (a *PHPAnalyzer) HasTopLevelCode(root *sitter.Node, source []byte) bool {
+	return hasExecutableStatement(root, source)
+}
+
+// hasExecutableStatement checks the statements of a program or namespace body
+func hasExecutableStatement(node *sitter.Node, source []byte) bool {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch {
+		case declarationNodeTypes[child.Type()]:
+			continue
+		case child.Type() == "namespace_definition":
+			if body := analyzer.FindChildByFieldName(child, "body"); body != nil && hasExecutableStatement(body, source) {
+				return true
+			}
+		case child.Type() == "text_interpolation":
+			// "?>" followed by inline HTML; whitespace alone is not output worth routing
+			for _, text := range analyzer.FindChildrenByType(child, "text") {
+				if strings.TrimSpace(analyzer.GetNodeText(text, source)) != "" {
+					return true
+				}
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
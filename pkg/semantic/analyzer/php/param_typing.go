@@ -0,0 +1,489 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// InferSourceConstraints sets FlowNode.Constraint on each source by inspecting
+// how the value is used: casts and typing functions (int/float/bool), comparisons,
+// in_array(), switch and match against literals (enum) and preg_match (pattern).
+// Uses of a variable the source is assigned to in the same scope count too,
+// as long as every read of it is converted or dominated by a check (see
+// variableConstraint).
+// Framework getters are argument-sensitive: getInt('page', 1) or
+// get_input('page', MyBB::INPUT_INT) are ints whatever their uses, and a
+// getter's default value is recorded.
+// Sources without any constraining use are free strings.
+func (a *PHPAnalyzer) InferSourceConstraints(root *sitter.Node, source []byte, sources []*types.FlowNode) {
+	for _, src := range sources {
+		point := sitter.Point{Row: uint32(src.Line - 1), Column: uint32(src.Column)}
+		// Widen to the full source expression ($_GET['id'], getenv('X'))
+		node := root.NamedDescendantForPointRange(point, point)
+		for node != nil && analyzer.GetNodeText(node, source) != src.Snippet {
+			node = node.Parent()
+		}
+		if node == nil {
+			continue
+		}
+
+		// A typed getter or type flag fixes the type; otherwise the uses decide
+		var constraint *types.ParamConstraint
+		getter := getterConstraint(node, source)
+		if getter != nil {
+			node = getter.call
+			if getter.Type != "" && getter.Type != types.ParamFreeString {
+				constraint = &getter.ParamConstraint
+			}
+		}
+		if constraint == nil {
+			constraint = a.usageConstraint(node, source)
+		}
+		if constraint == nil {
+			if target, scopeNode := assignedVariable(node, source); target != "" {
+				constraint = a.variableConstraint(target, scopeNode, int(node.EndByte()), source)
+			}
+		}
+		if constraint == nil {
+			constraint = &types.ParamConstraint{Type: types.ParamFreeString}
+		}
+		if getter != nil {
+			constraint.Default = getter.Default
+		}
+		src.Constraint = constraint
+	}
+}
+
+// getterCall is an input getter call with the constraint its arguments impose.
+// Type is empty when the getter leaves the type to the value's uses.
+type getterCall struct {
+	types.ParamConstraint
+	call *sitter.Node
+}
+
+// getterConstraint returns the typed-getter call a source is read through:
+// the source itself ($mybb->get_input('x', MyBB::INPUT_INT)) or the call on a
+// source bag ($request->query->getInt('x', 1)); nil for other sources
+func getterConstraint(node *sitter.Node, source []byte) *getterCall {
+	candidates := []*sitter.Node{node}
+	if parent := node.Parent(); parent != nil && analyzer.FindChildByFieldName(parent, "object") == node {
+		candidates = append(candidates, parent)
+	}
+	var call *sitter.Node
+	var getter phpPatterns.TypedGetter
+	for _, candidate := range candidates {
+		if candidate.Type() != "member_call_expression" {
+			continue
+		}
+		nameNode := analyzer.FindChildByFieldName(candidate, "name")
+		if nameNode == nil {
+			continue
+		}
+		if g, ok := phpPatterns.LookupTypedGetter(analyzer.GetNodeText(nameNode, source)); ok {
+			call, getter = candidate, g
+			break
+		}
+	}
+	if call == nil {
+		return nil
+	}
+
+	g := &getterCall{
+		ParamConstraint: types.ParamConstraint{Type: getter.ParamType, Pattern: getter.Pattern},
+		call:            call,
+	}
+	args := analyzer.FindChildrenByType(analyzer.FindChildByFieldName(call, "arguments"), "argument")
+	if getter.TypeArg >= 0 && getter.TypeArg < len(args) {
+		if paramType, ok := phpPatterns.ParamTypeForGetterFlag(analyzer.GetNodeText(args[getter.TypeArg], source)); ok {
+			g.Type = paramType
+		}
+	}
+	// A default reading variables is not a value: in get('a', $request->query->get('a'))
+	// it is a fallback source of its own
+	if getter.DefaultArg >= 0 && getter.DefaultArg < len(args) && len(analyzer.FindNodesOfType(args[getter.DefaultArg], "variable_name")) == 0 {
+		g.Default = analyzer.GetNodeText(args[getter.DefaultArg], source)
+	}
+	if g.Type != "" {
+		g.Evidence = analyzer.GetNodeText(call, source)
+		g.Line = int(call.StartPoint().Row) + 1
+	}
+	return g
+}
+
+// usageConstraint returns the constraint a single use of node imposes, or nil
+func (a *PHPAnalyzer) usageConstraint(node *sitter.Node, source []byte) *types.ParamConstraint {
+	child := node
+	parent := node.Parent()
+	for parent != nil && (parent.Type() == "parenthesized_expression" || parent.Type() == "argument") {
+		child, parent = parent, parent.Parent()
+	}
+	if parent == nil {
+		return nil
+	}
+
+	evidence := func(n *sitter.Node) (string, int) {
+		return analyzer.GetNodeText(n, source), int(n.StartPoint().Row) + 1
+	}
+
+	switch parent.Type() {
+	case "cast_expression":
+		castNode := analyzer.FindChildByFieldName(parent, "type")
+		if castNode == nil {
+			return nil
+		}
+		if paramType, ok := phpPatterns.ParamTypeForCast(analyzer.GetNodeText(castNode, source)); ok {
+			text, line := evidence(parent)
+			return &types.ParamConstraint{Type: paramType, Evidence: text, Line: line}
+		}
+
+	case "arguments":
+		call := parent.Parent()
+		if call == nil || call.Type() != "function_call_expression" {
+			return nil
+		}
+		nameNode := analyzer.FindChildByFieldName(call, "function")
+		if nameNode == nil {
+			return nil
+		}
+		funcName := strings.ToLower(strings.TrimPrefix(analyzer.GetNodeText(nameNode, source), "\\"))
+		args := analyzer.FindChildrenByType(parent, "argument")
+		argIndex := -1
+		for i, arg := range args {
+			if arg == child {
+				argIndex = i
+			}
+		}
+		text, line := evidence(call)
+
+		if paramType, ok := phpPatterns.ParamTypeForFunction(funcName); ok && argIndex == 0 {
+			return &types.ParamConstraint{Type: paramType, Evidence: text, Line: line}
+		}
+		if phpPatterns.EnumCheckFunctions[funcName] && argIndex == 0 && len(args) > 1 {
+			if values := literalValues(args[1].NamedChild(0), source); len(values) > 0 {
+				return &types.ParamConstraint{Type: types.ParamEnum, Values: values, Evidence: text, Line: line}
+			}
+		}
+		if phpPatterns.PatternCheckFunctions[funcName] && argIndex == 1 {
+			if pattern, ok := stringLiteral(args[0].NamedChild(0), source); ok {
+				return &types.ParamConstraint{Type: types.ParamPattern, Pattern: pattern, Evidence: text, Line: line}
+			}
+		}
+
+	case "binary_expression":
+		op := analyzer.FindChildByFieldName(parent, "operator")
+		if op == nil {
+			return nil
+		}
+		switch analyzer.GetNodeText(op, source) {
+		case "==", "===":
+		default:
+			return nil
+		}
+		other := analyzer.FindChildByFieldName(parent, "right")
+		if other == child {
+			other = analyzer.FindChildByFieldName(parent, "left")
+		}
+		if value, ok := scalarLiteral(other, source); ok {
+			text, line := evidence(parent)
+			return &types.ParamConstraint{Type: types.ParamEnum, Values: []string{value}, Evidence: text, Line: line}
+		}
+
+	case "switch_statement", "match_expression":
+		if analyzer.FindChildByFieldName(parent, "condition") != child {
+			return nil
+		}
+		var values []string
+		for _, caseNode := range analyzer.FindNodesOfTypes(parent, []string{"case_statement", "match_condition_list"}) {
+			if caseNode.Type() == "case_statement" {
+				if value, ok := scalarLiteral(analyzer.FindChildByFieldName(caseNode, "value"), source); ok {
+					values = append(values, value)
+				}
+				continue
+			}
+			for i := 0; i < int(caseNode.NamedChildCount()); i++ {
+				if value, ok := scalarLiteral(caseNode.NamedChild(i), source); ok {
+					values = append(values, value)
+				}
+			}
+		}
+		if len(values) > 0 {
+			keyword := strings.SplitN(parent.Type(), "_", 2)[0] // "switch" or "match"
+			_, line := evidence(parent)
+			text := keyword + " " + analyzer.GetNodeText(child, source)
+			return &types.ParamConstraint{Type: types.ParamEnum, Values: values, Evidence: text, Line: line}
+		}
+	}
+
+	return nil
+}
+
+// functionScopeNodeTypes own their local variables
+var functionScopeNodeTypes = map[string]bool{
+	"function_definition":                    true,
+	"method_declaration":                     true,
+	"anonymous_function_creation_expression": true,
+	"arrow_function":                         true,
+}
+
+// assignedVariable returns the variable a value is directly assigned to and the
+// scope node its later uses are searched in
+func assignedVariable(node *sitter.Node, source []byte) (string, *sitter.Node) {
+	parent := node.Parent()
+	for parent != nil && parent.Type() == "parenthesized_expression" {
+		node, parent = parent, parent.Parent()
+	}
+	if parent == nil || parent.Type() != "assignment_expression" ||
+		analyzer.FindChildByFieldName(parent, "right") != node {
+		return "", nil
+	}
+	left := analyzer.FindChildByFieldName(parent, "left")
+	if left == nil || left.Type() != "variable_name" {
+		return "", nil
+	}
+
+	// Uses are searched in the enclosing function (or the whole file)
+	scopeNode := parent
+	for scopeNode.Parent() != nil && !functionScopeNodeTypes[scopeNode.Type()] {
+		scopeNode = scopeNode.Parent()
+	}
+	return analyzer.GetNodeText(left, source), scopeNode
+}
+
+// variableConstraint returns the constraint all uses of a variable after
+// offset are subject to. Checks only count where they dominate the uses: a
+// guard leaving the block when the check fails (`if (!in_array($x, [...]))
+// return;`), a branch or switch case entered only when it passes. A value
+// also read outside such a check, before or after a type conversion, is a
+// free string (nil). A type conversion wins over enum values, which win over
+// a pattern.
+func (a *PHPAnalyzer) variableConstraint(varName string, scopeNode *sitter.Node, offset int, source []byte) *types.ParamConstraint {
+	var constraint *types.ParamConstraint
+	var checked [][2]uint32 // Byte ranges a passed check dominates
+	for _, use := range analyzer.FindNodesOfType(scopeNode, "variable_name") {
+		if int(use.StartByte()) < offset || analyzer.GetNodeText(use, source) != varName {
+			continue
+		}
+		// A reassignment ends the value's lifetime; $x = (int)$x converts it
+		if parent := use.Parent(); parent != nil && parent.Type() == "assignment_expression" &&
+			analyzer.FindChildByFieldName(parent, "left") == use {
+			for _, operand := range analyzer.FindNodesOfType(analyzer.FindChildByFieldName(parent, "right"), "variable_name") {
+				if c := a.usageConstraint(operand, source); c != nil && analyzer.GetNodeText(operand, source) == varName && convertsType(c) {
+					constraint = strongerConstraint(constraint, c)
+				}
+			}
+			break
+		}
+		if withinRanges(use, checked) || presenceCheck(use, source) {
+			continue
+		}
+		c := a.usageConstraint(use, source)
+		if c == nil {
+			return nil // Read unchecked
+		}
+		if !convertsType(c) {
+			ranges := dominatedRanges(checkNode(use), varName, source)
+			if len(ranges) == 0 {
+				continue // The check guards nothing
+			}
+			checked = append(checked, ranges...)
+		}
+		if constraint != nil && c.Type == types.ParamEnum && constraint.Type == types.ParamEnum {
+			constraint.Values = append(constraint.Values, c.Values...)
+			continue
+		}
+		constraint = strongerConstraint(constraint, c)
+	}
+	return constraint
+}
+
+// convertsType reports whether a constraint comes from a type conversion
+// (cast or typing function) rather than a check of the value
+func convertsType(c *types.ParamConstraint) bool {
+	return c.Type != types.ParamEnum && c.Type != types.ParamPattern
+}
+
+// presenceCheck reports whether a use only tests whether the variable is
+// set: isset($x) or empty($x)
+func presenceCheck(use *sitter.Node, source []byte) bool {
+	call := checkNode(use)
+	if call == nil || call.Type() != "function_call_expression" {
+		return false
+	}
+	nameNode := analyzer.FindChildByFieldName(call, "function")
+	if nameNode == nil {
+		return false
+	}
+	name := strings.ToLower(analyzer.GetNodeText(nameNode, source))
+	return name == "isset" || name == "empty"
+}
+
+// withinRanges reports whether a node lies in one of the byte ranges
+func withinRanges(node *sitter.Node, ranges [][2]uint32) bool {
+	for _, r := range ranges {
+		if node.StartByte() >= r[0] && node.EndByte() <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNode returns the comparison, call, switch or match checking a use
+func checkNode(use *sitter.Node) *sitter.Node {
+	node := use.Parent()
+	for node != nil && (node.Type() == "parenthesized_expression" || node.Type() == "argument" || node.Type() == "arguments") {
+		node = node.Parent()
+	}
+	return node
+}
+
+// dominatedRanges returns the byte ranges of the code only reached when a
+// check passes: the non-default cases of a switch or match, the body of an if
+// whose condition requires the check, or the rest of the block after an if
+// leaving it when the check fails
+func dominatedRanges(check *sitter.Node, varName string, source []byte) [][2]uint32 {
+	if check == nil {
+		return nil
+	}
+	var ranges [][2]uint32
+	switch check.Type() {
+	case "switch_statement", "match_expression":
+		for _, arm := range analyzer.FindNodesOfTypes(check, []string{"case_statement", "match_conditional_expression"}) {
+			ranges = append(ranges, [2]uint32{arm.StartByte(), arm.EndByte()})
+		}
+		return ranges
+	}
+
+	negated := false
+	for node := check; node.Parent() != nil; node = node.Parent() {
+		parent := node.Parent()
+		switch parent.Type() {
+		case "parenthesized_expression":
+		case "unary_op_expression":
+			if negated || !strings.HasPrefix(analyzer.GetNodeText(parent, source), "!") {
+				return nil
+			}
+			negated = true
+		case "binary_expression":
+			op := analyzer.FindChildByFieldName(parent, "operator")
+			if op == nil {
+				return nil
+			}
+			switch strings.ToLower(analyzer.GetNodeText(op, source)) {
+			case "&&", "and":
+				// Both operands hold in the body: `if (in_array(...) && $y)`
+				if negated {
+					return nil
+				}
+			case "||", "or":
+				// Both operands hold past the guard: `if (!in_array(...) || $y) exit;`,
+				// and one of the checks of the value in the body: `if ($x == 'a' || $x == 'b')`
+				if !negated && !checksValue(otherOperand(parent, node), varName, source) {
+					return nil
+				}
+			case "===", "==":
+				// `preg_match(...) === false` fails the check
+				if negated || failedCheck(parent, source) != node {
+					return nil
+				}
+				negated = true
+			default:
+				return nil
+			}
+		case "if_statement":
+			body := analyzer.FindChildByFieldName(parent, "body")
+			if analyzer.FindChildByFieldName(parent, "condition") != node || body == nil {
+				return nil
+			}
+			if !negated {
+				return [][2]uint32{{body.StartByte(), body.EndByte()}}
+			}
+			if block := parent.Parent(); block != nil && (rejects(body, source) || terminates(body, source) != "") {
+				return [][2]uint32{{parent.EndByte(), block.EndByte()}}
+			}
+			return nil
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// otherOperand returns the operand of a binary expression that is not node
+func otherOperand(binary, node *sitter.Node) *sitter.Node {
+	if other := analyzer.FindChildByFieldName(binary, "left"); other != node {
+		return other
+	}
+	return analyzer.FindChildByFieldName(binary, "right")
+}
+
+// checksValue reports whether an expression is a comparison or call reading
+// the variable directly, or a disjunction of them
+func checksValue(node *sitter.Node, varName string, source []byte) bool {
+	for node != nil && node.Type() == "parenthesized_expression" {
+		node = node.NamedChild(0)
+	}
+	if node == nil {
+		return false
+	}
+	if node.Type() == "binary_expression" {
+		if op := analyzer.FindChildByFieldName(node, "operator"); op != nil {
+			switch strings.ToLower(analyzer.GetNodeText(op, source)) {
+			case "||", "or":
+				return checksValue(analyzer.FindChildByFieldName(node, "left"), varName, source) &&
+					checksValue(analyzer.FindChildByFieldName(node, "right"), varName, source)
+			}
+		}
+	}
+	for _, use := range analyzer.FindNodesOfType(node, "variable_name") {
+		if analyzer.GetNodeText(use, source) == varName && checkNode(use) == node {
+			return true
+		}
+	}
+	return false
+}
+
+// literalValues returns the values of an array literal of scalars
+func literalValues(node *sitter.Node, source []byte) []string {
+	if node == nil || node.Type() != "array_creation_expression" {
+		return nil
+	}
+	var values []string
+	for _, elem := range analyzer.FindChildrenByType(node, "array_element_initializer") {
+		if elem.NamedChildCount() != 1 {
+			return nil // Keyed arrays are not value sets
+		}
+		value, ok := scalarLiteral(elem.NamedChild(0), source)
+		if !ok {
+			return nil
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// scalarLiteral returns the value of a string or integer literal
+func scalarLiteral(node *sitter.Node, source []byte) (string, bool) {
+	if node == nil {
+		return "", false
+	}
+	if node.Type() == "integer" {
+		return analyzer.GetNodeText(node, source), true
+	}
+	return stringLiteral(node, source)
+}
+
+// stringLiteral returns the content of a quoted string literal without interpolation
+func stringLiteral(node *sitter.Node, source []byte) (string, bool) {
+	if node == nil || (node.Type() != "string" && node.Type() != "encapsed_string") {
+		return "", false
+	}
+	text := analyzer.GetNodeText(node, source)
+	if len(text) < 2 || (node.Type() == "encapsed_string" && strings.Contains(text, "$")) {
+		return "", false
+	}
+	return text[1 : len(text)-1], true
+}
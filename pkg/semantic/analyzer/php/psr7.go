@@ -0,0 +1,325 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// maxDerivationDepth bounds how many assignments are followed back when
+// resolving a request variable or an attribute value
+const maxDerivationDepth = 8
+
+// ExtractRequestAttributes finds withAttribute() calls with a literal name on
+// a PSR-7 request and records where each value comes from: one of the file's
+// input sources in the value (directly or through local assignments), or
+// other attributes it reads. The withX() calls the request was derived
+// through are recorded as well:
+//
+//	$user = $request->getHeader('X-User');
+//	$request = $request->withQueryParams($q);
+//	return $handler->handle($request->withAttribute('user', $user));
+func (a *PHPAnalyzer) ExtractRequestAttributes(root *sitter.Node, source []byte, filePath string, sources []*types.FlowNode) []*types.RequestAttribute {
+	var setters []*sitter.Node
+	for _, call := range analyzer.FindNodesOfType(root, "member_call_expression") {
+		if nameNode := analyzer.FindChildByFieldName(call, "name"); nameNode != nil &&
+			phpPatterns.IsPSR7AttributeSetter(analyzer.GetNodeText(nameNode, source)) {
+			setters = append(setters, call)
+		}
+	}
+	if len(setters) == 0 {
+		return nil
+	}
+
+	// Input sources of the file by position, so values are checked with the
+	// same detection as the file's own sources
+	origins := make(map[sitter.Point]types.SourceType, len(sources))
+	for _, src := range sources {
+		origins[sitter.Point{Row: uint32(src.Line - 1), Column: uint32(src.Column)}] = src.SourceType
+	}
+	receivers := newRequestReceivers(root, source)
+
+	var attrs []*types.RequestAttribute
+	for _, call := range setters {
+		scopeNode := enclosingScope(call)
+		if !receivers.isRequest(analyzer.FindChildByFieldName(call, "object"), scopeNode, 0) {
+			continue
+		}
+		args := analyzer.FindChildrenByType(analyzer.FindChildByFieldName(call, "arguments"), "argument")
+		if len(args) < 2 {
+			continue
+		}
+		name, ok := stringLiteral(args[0].NamedChild(0), source)
+		if !ok {
+			continue
+		}
+		value := args[1].NamedChild(0)
+		if value == nil {
+			continue
+		}
+
+		attr := &types.RequestAttribute{
+			Name:       name,
+			Value:      analyzer.GetNodeText(value, source),
+			Derivation: requestDerivation(call, source, scopeNode, 0),
+			FilePath:   filePath,
+			Line:       int(call.StartPoint().Row) + 1,
+		}
+		attr.SourceType, attr.DependsOn = valueOrigin(value, source, scopeNode, origins, receivers, 0)
+		attrs = append(attrs, attr)
+	}
+
+	return attrs
+}
+
+// FindRequestAttributeSources returns a source node for every getAttribute()
+// read of a tainted attribute on a PSR-7 request. attrs is keyed by attribute name.
+func (a *PHPAnalyzer) FindRequestAttributeSources(root *sitter.Node, source []byte, attrs map[string]*types.RequestAttribute) []*types.FlowNode {
+	var sources []*types.FlowNode
+	receivers := newRequestReceivers(root, source)
+
+	for _, call := range analyzer.FindNodesOfType(root, "member_call_expression") {
+		name, ok := attributeRead(call, source)
+		if !ok {
+			continue
+		}
+		attr, ok := attrs[name]
+		if !ok || attr.SourceType == "" {
+			continue
+		}
+		if !receivers.isRequest(analyzer.FindChildByFieldName(call, "object"), enclosingScope(call), 0) {
+			continue
+		}
+
+		sources = append(sources, &types.FlowNode{
+			ID:         analyzer.GenerateNodeID("", call),
+			Type:       types.NodeSource,
+			Language:   "php",
+			Line:       int(call.StartPoint().Row) + 1,
+			Column:     int(call.StartPoint().Column),
+			Name:       "->getAttribute()",
+			Snippet:    analyzer.GetNodeText(call, source),
+			SourceType: attr.SourceType,
+			SourceKey:  name,
+			Metadata: map[string]interface{}{
+				"request_attribute": name,
+				"attribute_file":    attr.FilePath,
+				"attribute_line":    attr.Line,
+				"derivation":        strings.Join(attr.Derivation, " -> "),
+			},
+		})
+	}
+
+	return sources
+}
+
+// attributeRead returns the literal name a getAttribute() call reads
+func attributeRead(call *sitter.Node, source []byte) (string, bool) {
+	nameNode := analyzer.FindChildByFieldName(call, "name")
+	if nameNode == nil || !phpPatterns.IsPSR7AttributeGetter(analyzer.GetNodeText(nameNode, source)) {
+		return "", false
+	}
+	args := analyzer.FindChildrenByType(analyzer.FindChildByFieldName(call, "arguments"), "argument")
+	if len(args) == 0 {
+		return "", false
+	}
+	return stringLiteral(args[0].NamedChild(0), source)
+}
+
+// requestReceivers decides which receivers of a file hold a PSR-7 request,
+// resolving class names through the file's use statements
+type requestReceivers struct {
+	source  []byte
+	imports map[string]string // lowercase alias -> fully qualified name
+}
+
+// newRequestReceivers indexes the use statements of a file
+func newRequestReceivers(root *sitter.Node, source []byte) *requestReceivers {
+	r := &requestReceivers{source: source, imports: make(map[string]string)}
+	for _, decl := range analyzer.FindNodesOfType(root, "namespace_use_declaration") {
+		prefix := ""
+		if ns := analyzer.FindChildByType(decl, "namespace_name"); ns != nil {
+			prefix = analyzer.GetNodeText(ns, source) + "\\" // use A\B\{C, D as E}
+		}
+		for _, clause := range analyzer.FindNodesOfTypes(decl, []string{"namespace_use_clause", "namespace_use_group_clause"}) {
+			nameNode := analyzer.FindChildByType(clause, "qualified_name")
+			if nameNode == nil {
+				nameNode = analyzer.FindChildByType(clause, "namespace_name")
+			}
+			if nameNode == nil {
+				nameNode = analyzer.FindChildByType(clause, "name")
+			}
+			if nameNode == nil {
+				continue
+			}
+			name := strings.TrimPrefix(analyzer.GetNodeText(nameNode, source), "\\")
+			if clause.Type() == "namespace_use_group_clause" {
+				name = prefix + name
+			}
+			alias := name[strings.LastIndex(name, "\\")+1:]
+			if aliasNode := analyzer.FindChildByType(clause, "namespace_aliasing_clause"); aliasNode != nil {
+				if n := analyzer.FindChildByType(aliasNode, "name"); n != nil {
+					alias = analyzer.GetNodeText(n, source)
+				}
+			}
+			r.imports[strings.ToLower(alias)] = name
+		}
+	}
+	return r
+}
+
+// isRequest reports whether object is a PSR-7 request: a parameter declared
+// with a request type, a variable assigned one, or a withX() call on one.
+// A nil receivers knows no requests.
+func (r *requestReceivers) isRequest(object *sitter.Node, scopeNode *sitter.Node, depth int) bool {
+	if r == nil || object == nil || depth >= maxDerivationDepth {
+		return false
+	}
+	switch object.Type() {
+	case "parenthesized_expression":
+		return r.isRequest(object.NamedChild(0), scopeNode, depth+1)
+	case "member_call_expression":
+		return isDerivationCall(object, r.source) &&
+			r.isRequest(analyzer.FindChildByFieldName(object, "object"), scopeNode, depth+1)
+	case "variable_name":
+		varName := analyzer.GetNodeText(object, r.source)
+		if assigned := precedingAssignment(varName, scopeNode, object.StartByte(), r.source); assigned != nil {
+			return r.isRequest(assigned, scopeNode, depth+1)
+		}
+		return r.isRequestParameter(varName, scopeNode)
+	}
+	return false
+}
+
+// isRequestParameter reports whether scopeNode declares varName as a
+// parameter typed with a PSR-7 request (also nullable or in a union)
+func (r *requestReceivers) isRequestParameter(varName string, scopeNode *sitter.Node) bool {
+	params := analyzer.FindChildByFieldName(scopeNode, "parameters")
+	if params == nil {
+		return false
+	}
+	for _, param := range analyzer.FindChildrenByType(params, "simple_parameter") {
+		nameNode := analyzer.FindChildByFieldName(param, "name")
+		typeNode := analyzer.FindChildByFieldName(param, "type")
+		if nameNode == nil || typeNode == nil || analyzer.GetNodeText(nameNode, r.source) != varName {
+			continue
+		}
+		for _, named := range analyzer.FindNodesOfType(typeNode, "named_type") {
+			if phpPatterns.IsPSR7RequestType(r.resolveClass(analyzer.GetNodeText(named, r.source))) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveClass returns the fully qualified name of a class name as written
+func (r *requestReceivers) resolveClass(name string) string {
+	if strings.HasPrefix(name, "\\") {
+		return name
+	}
+	first, rest, _ := strings.Cut(name, "\\")
+	if imported, ok := r.imports[strings.ToLower(first)]; ok {
+		if rest == "" {
+			return imported
+		}
+		return imported + "\\" + rest
+	}
+	return name
+}
+
+// enclosingScope returns the function owning node's local variables (or the root)
+func enclosingScope(node *sitter.Node) *sitter.Node {
+	for node.Parent() != nil && !functionScopeNodeTypes[node.Type()] {
+		node = node.Parent()
+	}
+	return node
+}
+
+// precedingAssignment returns the value last assigned to varName in scopeNode
+// before offset, or nil
+func precedingAssignment(varName string, scopeNode *sitter.Node, offset uint32, source []byte) *sitter.Node {
+	var value *sitter.Node
+	for _, assign := range analyzer.FindNodesOfType(scopeNode, "assignment_expression") {
+		if assign.EndByte() > offset {
+			continue
+		}
+		left := analyzer.FindChildByFieldName(assign, "left")
+		if left != nil && analyzer.GetNodeText(left, source) == varName {
+			value = analyzer.FindChildByFieldName(assign, "right")
+		}
+	}
+	return value
+}
+
+// valueOrigin returns the source type of the first input source found in a
+// value (following local variables back to their assignments) and the
+// request attributes the value reads
+func valueOrigin(value *sitter.Node, source []byte, scopeNode *sitter.Node, origins map[sitter.Point]types.SourceType, receivers *requestReceivers, depth int) (types.SourceType, []string) {
+	var sourceType types.SourceType
+	var dependsOn []string
+
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if sourceType == "" {
+			sourceType = origins[n.StartPoint()]
+		}
+		switch n.Type() {
+		case "member_call_expression":
+			if name, ok := attributeRead(n, source); ok &&
+				receivers.isRequest(analyzer.FindChildByFieldName(n, "object"), scopeNode, 0) {
+				dependsOn = append(dependsOn, name)
+			}
+		case "variable_name":
+			varName := analyzer.GetNodeText(n, source)
+			if varName == "$this" || depth >= maxDerivationDepth {
+				return
+			}
+			if assigned := precedingAssignment(varName, scopeNode, n.StartByte(), source); assigned != nil {
+				st, deps := valueOrigin(assigned, source, scopeNode, origins, receivers, depth+1)
+				if sourceType == "" {
+					sourceType = st
+				}
+				dependsOn = append(dependsOn, deps...)
+			}
+			return
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			walk(n.NamedChild(i))
+		}
+	}
+	walk(value)
+
+	return sourceType, dependsOn
+}
+
+// requestDerivation returns the withX() calls a request was derived through,
+// ending with call itself, following chained calls and local assignments
+func requestDerivation(call *sitter.Node, source []byte, scopeNode *sitter.Node, depth int) []string {
+	var chain []string
+	if depth < maxDerivationDepth {
+		object := analyzer.FindChildByFieldName(call, "object")
+		if object != nil && object.Type() == "variable_name" {
+			object = precedingAssignment(analyzer.GetNodeText(object, source), scopeNode, call.StartByte(), source)
+		}
+		if isDerivationCall(object, source) {
+			chain = requestDerivation(object, source, scopeNode, depth+1)
+		}
+	}
+
+	nameNode := analyzer.FindChildByFieldName(call, "name")
+	argsNode := analyzer.FindChildByFieldName(call, "arguments")
+	return append(chain, analyzer.GetNodeText(nameNode, source)+analyzer.GetNodeText(argsNode, source))
+}
+
+// isDerivationCall reports whether node is a PSR-7 withX() call
+func isDerivationCall(node *sitter.Node, source []byte) bool {
+	if node == nil || node.Type() != "member_call_expression" {
+		return false
+	}
+	nameNode := analyzer.FindChildByFieldName(node, "name")
+	return nameNode != nil && phpPatterns.IsPSR7DerivationMethod(analyzer.GetNodeText(nameNode, source))
+}
@@ -0,0 +1,125 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// FindRealtimeHandlers returns the WebSocket message handlers of a file:
+// message methods of Ratchet components, Workerman onMessage closures and
+// Swoole ->on('message') closures
+func (a *PHPAnalyzer) FindRealtimeHandlers(root *sitter.Node, source []byte) []*types.RealtimeHandler {
+	var handlers []*types.RealtimeHandler
+
+	for _, class := range analyzer.FindNodesOfType(root, "class_declaration") {
+		framework := realtimeClassFramework(class, source)
+		body := analyzer.FindChildByFieldName(class, "body")
+		if framework == "" || body == nil {
+			continue
+		}
+		className := analyzer.GetNodeText(analyzer.FindChildByFieldName(class, "name"), source)
+		for _, method := range analyzer.FindChildrenByType(body, "method_declaration") {
+			methodName := analyzer.GetNodeText(analyzer.FindChildByFieldName(method, "name"), source)
+			callback, ok := phpPatterns.RealtimeHandlerMethods[strings.ToLower(methodName)]
+			if !ok {
+				continue
+			}
+			handlers = append(handlers, newRealtimeHandler(method, source, framework, "", className+"::"+methodName, callback.PayloadParam))
+		}
+	}
+
+	for _, assign := range analyzer.FindNodesOfType(root, "assignment_expression") {
+		left := analyzer.FindChildByFieldName(assign, "left")
+		right := analyzer.FindChildByFieldName(assign, "right")
+		if left == nil || right == nil || left.Type() != "member_access_expression" || !isClosure(right) {
+			continue
+		}
+		property := analyzer.GetNodeText(analyzer.FindChildByFieldName(left, "name"), source)
+		callback, ok := phpPatterns.RealtimeCallbackProperties[strings.ToLower(property)]
+		if !ok {
+			continue
+		}
+		handlers = append(handlers, newRealtimeHandler(right, source, callback.Framework, "", analyzer.GetNodeText(left, source), callback.PayloadParam))
+	}
+
+	for _, call := range analyzer.FindNodesOfType(root, "member_call_expression") {
+		if !strings.EqualFold(analyzer.GetNodeText(analyzer.FindChildByFieldName(call, "name"), source), "on") {
+			continue
+		}
+		args := analyzer.FindChildByFieldName(call, "arguments")
+		if args == nil {
+			continue
+		}
+		argNodes := analyzer.FindChildrenByType(args, "argument")
+		if len(argNodes) < 2 || argNodes[1].NamedChildCount() == 0 || !isClosure(argNodes[1].NamedChild(0)) {
+			continue
+		}
+		event := strings.Trim(analyzer.GetNodeText(argNodes[0], source), `'"`)
+		callback, ok := phpPatterns.RealtimeEvents[strings.ToLower(event)]
+		if !ok {
+			continue
+		}
+		handlers = append(handlers, newRealtimeHandler(argNodes[1].NamedChild(0), source, callback.Framework, event, "", callback.PayloadParam))
+	}
+
+	return handlers
+}
+
+// realtimeClassFramework returns the framework of the message-receiving
+// interface a class implements, or ""
+func realtimeClassFramework(class *sitter.Node, source []byte) string {
+	clause := analyzer.FindChildByType(class, "class_interface_clause")
+	if clause == nil {
+		return ""
+	}
+	for i := 0; i < int(clause.NamedChildCount()); i++ {
+		if framework := phpPatterns.RealtimeInterfaceFramework(analyzer.GetNodeText(clause.NamedChild(i), source)); framework != "" {
+			return framework
+		}
+	}
+	return ""
+}
+
+// isClosure reports whether node is an anonymous or arrow function
+func isClosure(node *sitter.Node) bool {
+	switch node.Type() {
+	case "anonymous_function_creation_expression", "anonymous_function", "arrow_function":
+		return true
+	}
+	return false
+}
+
+// newRealtimeHandler builds the handler of a method or closure whose
+// parameter at payloadParam receives the message
+func newRealtimeHandler(fn *sitter.Node, source []byte, framework, event, name string, payloadParam int) *types.RealtimeHandler {
+	handler := &types.RealtimeHandler{
+		Protocol:  types.ProtocolWebSocket,
+		Framework: framework,
+		Event:     event,
+		Function:  name,
+		Line:      int(fn.StartPoint().Row) + 1,
+		EndLine:   int(fn.EndPoint().Row) + 1,
+	}
+	if params := analyzer.FindChildByFieldName(fn, "parameters"); params != nil {
+		var paramNodes []*sitter.Node
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			if p := params.NamedChild(i); p.Type() == "simple_parameter" || p.Type() == "variadic_parameter" {
+				paramNodes = append(paramNodes, p)
+			}
+		}
+		if payloadParam < len(paramNodes) {
+			if nameNode := analyzer.FindChildByFieldName(paramNodes[payloadParam], "name"); nameNode != nil {
+				handler.Payloads = append(handler.Payloads, types.RealtimePayload{
+					Name:   analyzer.GetNodeText(nameNode, source),
+					Line:   int(nameNode.StartPoint().Row) + 1,
+					Column: int(nameNode.StartPoint().Column),
+				})
+			}
+		}
+	}
+	return handler
+}
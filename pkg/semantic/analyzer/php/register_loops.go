@@ -0,0 +1,256 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// fileScopeBoundaries are the nodes whose variables are not file-scope variables
+var fileScopeBoundaries = map[string]bool{
+	"function_definition":                    true,
+	"method_declaration":                     true,
+	"class_declaration":                      true,
+	"interface_declaration":                  true,
+	"trait_declaration":                      true,
+	"enum_declaration":                       true,
+	"anonymous_function_creation_expression": true,
+	"arrow_function":                         true,
+	"object_creation_expression":             true, // Anonymous classes
+}
+
+// FindRegisterLoops finds the file-scope idioms copying a superglobal into
+// variables named after its keys:
+//
+//	foreach ($_REQUEST as $k => $v) $$k = $v;
+//	foreach ($_POST as $k => $v) { $GLOBALS[$k] = addslashes($v); }
+//	foreach (array('_GET', '_POST') as $sg) foreach ($$sg as $k => $v) ${$k} = $v;
+//	extract($_GET);
+//
+// Each loop lists the file-scope variables read after it before any
+// assignment of their own, which the loop may define.
+func (a *PHPAnalyzer) FindRegisterLoops(root *sitter.Node, source []byte) []*types.RegisterLoop {
+	var loops []*types.RegisterLoop
+	walkFileScope(root, func(node *sitter.Node) {
+		var superglobals []string
+		switch node.Type() {
+		case "foreach_statement":
+			superglobals = registeredByLoop(node, source)
+		case "function_call_expression":
+			superglobals = registeredByExtract(node, source)
+		}
+		if len(superglobals) == 0 {
+			return
+		}
+		code := analyzer.GetNodeText(node, source)
+		if i := strings.IndexByte(code, '\n'); i >= 0 {
+			code = strings.TrimSpace(code[:i])
+		}
+		loops = append(loops, &types.RegisterLoop{
+			Superglobals: superglobals,
+			Code:         code,
+			Line:         int(node.StartPoint().Row) + 1,
+			EndLine:      int(node.EndPoint().Row) + 1,
+		})
+	})
+	if len(loops) == 0 {
+		return nil
+	}
+
+	// Outer loops of nested ones are found too; keep the innermost
+	loops = dropEnclosingLoops(loops)
+	for _, loop := range loops {
+		loop.Variables = undeclaredReads(root, source, loop.EndLine)
+	}
+	return loops
+}
+
+// walkFileScope calls fn on every node of root outside functions and classes
+func walkFileScope(node *sitter.Node, fn func(*sitter.Node)) {
+	fn(node)
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); !fileScopeBoundaries[child.Type()] {
+			walkFileScope(child, fn)
+		}
+	}
+}
+
+// registeredByLoop returns the superglobals a foreach copies into variables
+// named after their keys, or nil
+func registeredByLoop(loop *sitter.Node, source []byte) []string {
+	subject, pair := loop.NamedChild(0), loop.NamedChild(1)
+	if subject == nil || pair == nil || pair.Type() != "pair" || pair.NamedChild(0) == nil {
+		return nil
+	}
+	key := analyzer.GetNodeText(pair.NamedChild(0), source)
+
+	var superglobals []string
+	switch subject.Type() {
+	case "variable_name":
+		if name := analyzer.GetNodeText(subject, source); sources.IsSuperglobal(name) {
+			superglobals = []string{name}
+		}
+	case "dynamic_variable_name":
+		// $$sg, iterating the superglobals named by an enclosing foreach
+		superglobals = namedSuperglobals(loop, dynamicVariable(subject, source), source)
+	}
+	if len(superglobals) == 0 {
+		return nil
+	}
+
+	body := loop.ChildByFieldName("body")
+	if body == nil {
+		return nil
+	}
+	for _, assign := range analyzer.FindNodesOfTypes(body, []string{"assignment_expression", "reference_assignment_expression"}) {
+		left := assign.ChildByFieldName("left")
+		if left == nil {
+			continue
+		}
+		switch left.Type() {
+		case "dynamic_variable_name": // $$k = $v, ${$k} = $v
+			if dynamicVariable(left, source) == key {
+				return superglobals
+			}
+		case "subscript_expression": // $GLOBALS[$k] = $v
+			base, index := left.NamedChild(0), left.NamedChild(1)
+			if base != nil && index != nil && analyzer.GetNodeText(base, source) == "$GLOBALS" && analyzer.GetNodeText(index, source) == key {
+				return superglobals
+			}
+		}
+	}
+	return nil
+}
+
+// registeredByExtract returns the superglobal an extract() call copies into
+// variables, or nil
+func registeredByExtract(call *sitter.Node, source []byte) []string {
+	nameNode := call.Child(0)
+	if nameNode == nil || !strings.EqualFold(analyzer.GetNodeText(nameNode, source), "extract") {
+		return nil
+	}
+	arg, _ := firstCallArgument(call, source)
+	if sources.IsSuperglobal(arg) {
+		return []string{arg}
+	}
+	return nil
+}
+
+// dynamicVariable returns the variable naming a dynamic variable: $k for $$k and ${$k}
+func dynamicVariable(node *sitter.Node, source []byte) string {
+	if inner := analyzer.FindChildByType(node, "variable_name"); inner != nil {
+		return analyzer.GetNodeText(inner, source)
+	}
+	return ""
+}
+
+// namedSuperglobals returns the superglobals an enclosing
+// foreach (array('_GET', '_POST') as $name) iterates the names of
+func namedSuperglobals(loop *sitter.Node, name string, source []byte) []string {
+	for outer := loop.Parent(); outer != nil; outer = outer.Parent() {
+		if outer.Type() != "foreach_statement" {
+			continue
+		}
+		subject, value := outer.NamedChild(0), outer.NamedChild(1)
+		if subject == nil || value == nil || analyzer.GetNodeText(value, source) != name {
+			continue
+		}
+		var superglobals []string
+		for _, str := range analyzer.FindNodesOfTypes(subject, []string{"string", "encapsed_string"}) {
+			sg := "$" + strings.Trim(analyzer.GetNodeText(str, source), `"'`)
+			if sources.IsSuperglobal(sg) {
+				superglobals = append(superglobals, sg)
+			}
+		}
+		return superglobals
+	}
+	return nil
+}
+
+// dropEnclosingLoops removes the loops that contain another loop
+func dropEnclosingLoops(loops []*types.RegisterLoop) []*types.RegisterLoop {
+	var kept []*types.RegisterLoop
+	for _, loop := range loops {
+		enclosing := false
+		for _, other := range loops {
+			if other != loop && other.Line >= loop.Line && other.EndLine <= loop.EndLine && (other.Line > loop.Line || other.EndLine < loop.EndLine) {
+				enclosing = true
+				break
+			}
+		}
+		if !enclosing {
+			kept = append(kept, loop)
+		}
+	}
+	return kept
+}
+
+// undeclaredReads returns the first read of every file-scope variable after
+// line that no file-scope assignment, foreach or global statement declared
+// before it. Superglobals and $this are never undeclared.
+func undeclaredReads(root *sitter.Node, source []byte, line int) []types.RegisteredVariable {
+	// Byte offset after which each variable is declared
+	declared := make(map[string]uint32)
+	declare := func(node *sitter.Node, at uint32) {
+		if node == nil {
+			return
+		}
+		for _, v := range append([]*sitter.Node{node}, analyzer.FindNodesOfType(node, "variable_name")...) {
+			if v.Type() != "variable_name" {
+				continue
+			}
+			name := analyzer.GetNodeText(v, source)
+			if prev, ok := declared[name]; !ok || at < prev {
+				declared[name] = at
+			}
+		}
+	}
+	walkFileScope(root, func(node *sitter.Node) {
+		switch node.Type() {
+		case "assignment_expression", "reference_assignment_expression":
+			declare(node.ChildByFieldName("left"), node.EndByte())
+		case "foreach_statement":
+			// The key and value variables bind where they are written
+			body := node.ChildByFieldName("body")
+			for i := 1; i < int(node.NamedChildCount()); i++ {
+				if child := node.NamedChild(i); body == nil || child.StartByte() < body.StartByte() {
+					declare(child, child.StartByte())
+				}
+			}
+		case "global_declaration", "static_variable_declaration", "catch_clause":
+			declare(node, node.StartByte())
+		}
+	})
+
+	var reads []types.RegisteredVariable
+	seen := make(map[string]bool)
+	walkFileScope(root, func(node *sitter.Node) {
+		if node.Type() != "variable_name" || int(node.StartPoint().Row)+1 <= line {
+			return
+		}
+		name := analyzer.GetNodeText(node, source)
+		if seen[name] || name == "$this" || name == "$GLOBALS" || sources.IsSuperglobal(name) {
+			return
+		}
+		if parent := node.Parent(); parent != nil && parent.Type() == "dynamic_variable_name" {
+			return
+		}
+		if at, ok := declared[name]; ok && at <= node.StartByte() {
+			return
+		}
+		// The target of an assignment is written, not read
+		if parent := node.Parent(); parent != nil && (parent.Type() == "assignment_expression" || parent.Type() == "reference_assignment_expression") && parent.ChildByFieldName("left") == node {
+			return
+		}
+		seen[name] = true
+		reads = append(reads, types.RegisteredVariable{
+			Name:   name,
+			Line:   int(node.StartPoint().Row) + 1,
+			Column: int(node.StartPoint().Column),
+		})
+	})
+	return reads
+}
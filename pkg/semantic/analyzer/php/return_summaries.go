@@ -0,0 +1,183 @@
+package php
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// ExtractReturnSummaries finds global functions whose every return is an array
+// literal with literal keys, and records which keys hold input (directly or
+// through local assignments):
+//
+//	function get_user() { return ['id' => $_GET['id'], 'name' => 'static']; }
+//
+// Functions without a tainted key are not summarized.
+func (a *PHPAnalyzer) ExtractReturnSummaries(root *sitter.Node, source []byte, filePath string) []*types.ReturnSummary {
+	functions := analyzer.FindNodesOfType(root, "function_definition")
+	if len(functions) == 0 {
+		return nil
+	}
+
+	// Input sources of the file by position
+	sources, _ := a.FindInputSources(root, source)
+	origins := make(map[sitter.Point]types.SourceType, len(sources))
+	for _, src := range sources {
+		origins[sitter.Point{Row: uint32(src.Line - 1), Column: uint32(src.Column)}] = src.SourceType
+	}
+
+	var summaries []*types.ReturnSummary
+	for _, fnNode := range functions {
+		nameNode := analyzer.FindChildByFieldName(fnNode, "name")
+		bodyNode := analyzer.FindChildByFieldName(fnNode, "body")
+		if nameNode == nil || bodyNode == nil {
+			continue
+		}
+
+		tainted := make(map[string]types.SourceType)
+		summarized := false
+		for _, ret := range analyzer.FindNodesOfType(bodyNode, "return_statement") {
+			if enclosingScope(ret) != fnNode {
+				continue // Return of a nested closure
+			}
+			summarized = ret.NamedChildCount() > 0 && a.summarizeArrayReturn(ret.NamedChild(0), source, fnNode, origins, tainted)
+			if !summarized {
+				break
+			}
+		}
+		if !summarized || len(tainted) == 0 {
+			continue
+		}
+
+		summaries = append(summaries, &types.ReturnSummary{
+			FunctionName: analyzer.GetNodeText(nameNode, source),
+			TaintedKeys:  tainted,
+			FilePath:     filePath,
+			Line:         int(fnNode.StartPoint().Row) + 1,
+		})
+	}
+
+	return summaries
+}
+
+// summarizeArrayReturn adds the tainted keys of a returned array literal to
+// tainted. It returns false when the value is not an array literal or has a
+// key that is not a literal (a dynamic key or a spread).
+func (a *PHPAnalyzer) summarizeArrayReturn(value *sitter.Node, source []byte, scopeNode *sitter.Node, origins map[sitter.Point]types.SourceType, tainted map[string]types.SourceType) bool {
+	for value != nil && value.Type() == "parenthesized_expression" {
+		value = value.NamedChild(0)
+	}
+	if value == nil || value.Type() != "array_creation_expression" {
+		return false
+	}
+
+	next := 0 // Key PHP gives the next element without one
+	for _, elem := range analyzer.FindChildrenByType(value, "array_element_initializer") {
+		var key string
+		var elemValue *sitter.Node
+		switch elem.NamedChildCount() {
+		case 1:
+			elemValue = elem.NamedChild(0)
+			if elemValue.Type() == "variadic_unpacking" {
+				return false
+			}
+			key = strconv.Itoa(next)
+			next++
+		case 2:
+			k, ok := arrayKey(elem.NamedChild(0), source)
+			if !ok {
+				return false
+			}
+			if n, err := strconv.Atoi(k); err == nil && n >= next {
+				next = n + 1
+			}
+			key, elemValue = k, elem.NamedChild(1)
+		default:
+			return false
+		}
+		if st, _ := valueOrigin(elemValue, source, scopeNode, origins, nil, 0); st != "" {
+			tainted[key] = st
+		}
+	}
+	return true
+}
+
+// arrayKey returns the value of a string or integer literal array key
+func arrayKey(node *sitter.Node, source []byte) (string, bool) {
+	if node != nil && node.Type() == "integer" {
+		return analyzer.GetNodeText(node, source), true
+	}
+	return stringLiteral(node, source)
+}
+
+// FindReturnSummarySources returns a source node for every read of a tainted
+// key from the result of a summarized function, either directly
+// (`get_user()['id']`) or through the variable the result was last assigned
+// to in the same scope (`$res = get_user(); ... $res['id']`). summaries is
+// keyed by lowercase function name.
+func (a *PHPAnalyzer) FindReturnSummarySources(root *sitter.Node, source []byte, summaries map[string]*types.ReturnSummary) []*types.FlowNode {
+	var sources []*types.FlowNode
+
+	for _, sub := range analyzer.FindNodesOfType(root, "subscript_expression") {
+		if sub.NamedChildCount() < 2 || isAssignmentTarget(sub) {
+			continue
+		}
+		key, ok := arrayKey(sub.NamedChild(1), source)
+		if !ok {
+			continue
+		}
+
+		call := sub.NamedChild(0)
+		if call.Type() == "variable_name" {
+			call = precedingAssignment(analyzer.GetNodeText(call, source), enclosingScope(sub), sub.StartByte(), source)
+		}
+		if call == nil || call.Type() != "function_call_expression" {
+			continue
+		}
+		nameNode := analyzer.FindChildByFieldName(call, "function")
+		if nameNode == nil {
+			continue
+		}
+		funcName := strings.TrimPrefix(analyzer.GetNodeText(nameNode, source), "\\")
+		summary, ok := summaries[strings.ToLower(funcName)]
+		if !ok {
+			continue
+		}
+		sourceType, ok := summary.TaintedKeys[key]
+		if !ok {
+			continue
+		}
+
+		sources = append(sources, &types.FlowNode{
+			ID:         analyzer.GenerateNodeID("", sub),
+			Type:       types.NodeSource,
+			Language:   "php",
+			Line:       int(sub.StartPoint().Row) + 1,
+			Column:     int(sub.StartPoint().Column),
+			Name:       analyzer.GetNodeText(sub, source),
+			Snippet:    analyzer.GetNodeText(sub, source),
+			SourceType: sourceType,
+			SourceKey:  key,
+			Metadata: map[string]interface{}{
+				"return_summary": summary.FunctionName,
+				"summary_file":   summary.FilePath,
+				"summary_line":   summary.Line,
+			},
+		})
+	}
+
+	return sources
+}
+
+// isAssignmentTarget reports whether node is the left side of an assignment
+func isAssignmentTarget(node *sitter.Node) bool {
+	parent := node.Parent()
+	if parent == nil || parent.Type() != "assignment_expression" {
+		return false
+	}
+	left := analyzer.FindChildByFieldName(parent, "left")
+	return left != nil && left.StartByte() == node.StartByte() && left.EndByte() == node.EndByte()
+}
@@ -0,0 +1,175 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// FindRouteDispatches returns the custom routers of a file: switch statements
+// whose string cases call functions, and arrays mapping actions to function
+// names that are indexed and called directly, through call_user_func() or
+// through a variable holding the selected entry. Whether the selecting value
+// is input is left to the tracer.
+func (a *PHPAnalyzer) FindRouteDispatches(root *sitter.Node, source []byte) []*types.RouteDispatch {
+	var dispatches []*types.RouteDispatch
+
+	for _, sw := range analyzer.FindNodesOfType(root, "switch_statement") {
+		if d := switchDispatch(sw, source); d != nil {
+			dispatches = append(dispatches, d)
+		}
+	}
+
+	// Action maps: $routes = ['edit' => 'edit_item', ...]
+	maps := make(map[string][]types.RouteHandler)
+	// Variables holding a selected entry: $handler = $routes[$action]
+	selected := make(map[string]*sitter.Node)
+	for _, assign := range analyzer.FindNodesOfType(root, "assignment_expression") {
+		left := analyzer.FindChildByFieldName(assign, "left")
+		right := analyzer.FindChildByFieldName(assign, "right")
+		if left == nil || right == nil || left.Type() != "variable_name" {
+			continue
+		}
+		name := analyzer.GetNodeText(left, source)
+		if handlers := actionMap(right, source); len(handlers) > 0 {
+			maps[name] = handlers
+		} else if right.Type() == "subscript_expression" {
+			selected[name] = right
+		}
+	}
+	if len(maps) == 0 {
+		return dispatches
+	}
+
+	// mapLookup returns the handlers and key of a $routes[$key] expression
+	mapLookup := func(node *sitter.Node) ([]types.RouteHandler, string) {
+		if node != nil && node.Type() == "variable_name" && selected[analyzer.GetNodeText(node, source)] != nil {
+			node = selected[analyzer.GetNodeText(node, source)]
+		}
+		if node == nil || node.Type() != "subscript_expression" || node.NamedChildCount() < 2 {
+			return nil, ""
+		}
+		return maps[analyzer.GetNodeText(node.NamedChild(0), source)], analyzer.GetNodeText(node.NamedChild(1), source)
+	}
+
+	for _, call := range analyzer.FindNodesOfType(root, "function_call_expression") {
+		fn := analyzer.FindChildByFieldName(call, "function")
+		if fn == nil {
+			continue
+		}
+		handlers, key := mapLookup(fn)
+		offset := 0
+		if handlers == nil {
+			invokerOffset, ok := phpPatterns.CallableInvoker(analyzer.GetNodeText(fn, source))
+			args := analyzer.FindChildByFieldName(call, "arguments")
+			if !ok || args == nil {
+				continue
+			}
+			argNodes := analyzer.FindChildrenByType(args, "argument")
+			if len(argNodes) == 0 || argNodes[0].NamedChildCount() == 0 {
+				continue
+			}
+			handlers, key = mapLookup(argNodes[0].NamedChild(0))
+			offset = invokerOffset
+		}
+		if handlers == nil {
+			continue
+		}
+		dispatches = append(dispatches, &types.RouteDispatch{
+			Key:        key,
+			Line:       int(call.StartPoint().Row) + 1,
+			Handlers:   handlers,
+			CallLine:   int(call.StartPoint().Row) + 1,
+			CallColumn: int(call.StartPoint().Column),
+			ArgOffset:  offset,
+		})
+	}
+
+	return dispatches
+}
+
+// switchDispatch returns the dispatch of a switch statement calling functions
+// in its string cases, or nil
+func switchDispatch(sw *sitter.Node, source []byte) *types.RouteDispatch {
+	condition := analyzer.FindChildByFieldName(sw, "condition")
+	body := analyzer.FindChildByFieldName(sw, "body")
+	if condition == nil || body == nil || condition.NamedChildCount() == 0 {
+		return nil
+	}
+
+	dispatch := &types.RouteDispatch{
+		Key:       analyzer.GetNodeText(condition.NamedChild(0), source),
+		Line:      int(sw.StartPoint().Row) + 1,
+		ArgOffset: -1,
+	}
+	for _, c := range analyzer.FindChildrenByType(body, "case_statement") {
+		action, ok := stringLiteral(analyzer.FindChildByFieldName(c, "value"), source)
+		if !ok {
+			continue
+		}
+		for _, function := range caseCalls(c, source) {
+			dispatch.Handlers = append(dispatch.Handlers, types.RouteHandler{
+				Action:   action,
+				Function: function,
+				Line:     int(c.StartPoint().Row) + 1,
+			})
+		}
+	}
+	if len(dispatch.Handlers) == 0 {
+		return nil
+	}
+	return dispatch
+}
+
+// caseCalls returns the functions and static methods called by name in a
+// case, outside closures; the tracer keeps those defined in the scanned code
+func caseCalls(c *sitter.Node, source []byte) []string {
+	var functions []string
+	analyzer.TraverseTree(c, func(node *sitter.Node) bool {
+		if isClosure(node) {
+			return false
+		}
+		switch node.Type() {
+		case "function_call_expression":
+			if fn := analyzer.FindChildByFieldName(node, "function"); fn != nil && (fn.Type() == "name" || fn.Type() == "qualified_name") {
+				functions = append(functions, analyzer.GetNodeText(fn, source))
+			}
+		case "scoped_call_expression":
+			scope := analyzer.FindChildByFieldName(node, "scope")
+			name := analyzer.FindChildByFieldName(node, "name")
+			if scope != nil && name != nil && scope.Type() != "relative_scope" {
+				functions = append(functions, analyzer.GetNodeText(scope, source)+"::"+analyzer.GetNodeText(name, source))
+			}
+		}
+		return true
+	})
+	return functions
+}
+
+// actionMap returns the handlers of an array literal mapping string actions
+// to function names, or nil if any entry is something else
+func actionMap(node *sitter.Node, source []byte) []types.RouteHandler {
+	if node.Type() != "array_creation_expression" {
+		return nil
+	}
+	var handlers []types.RouteHandler
+	for _, element := range analyzer.FindChildrenByType(node, "array_element_initializer") {
+		if element.NamedChildCount() != 2 {
+			return nil
+		}
+		action, ok := stringLiteral(element.NamedChild(0), source)
+		function, isName := stringLiteral(element.NamedChild(1), source)
+		if !ok || !isName || function == "" {
+			return nil
+		}
+		handlers = append(handlers, types.RouteHandler{
+			Action:   action,
+			Function: strings.TrimPrefix(function, "\\"),
+			Line:     int(element.StartPoint().Row) + 1,
+		})
+	}
+	return handlers
+}
@@ -0,0 +1,109 @@
+package php
+
+import (
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// symfonyBagSource returns the source read by a Symfony bag method called on
+// a Request bag property, typed by the bag rather than the method name:
+//
+//	$request->query->get('id')                // http_get "id"
+//	$request->query->all()['filter']['id']    // http_get "filter"
+//	$request->request->get('a', $request->query->get('a'))
+//
+// In the last form the default argument is itself input: the inner call is
+// a source of its own, flagged as the fallback of the outer one. Returns nil
+// for other method calls.
+func (a *PHPAnalyzer) symfonyBagSource(call *sitter.Node, source []byte, constants map[string]*types.ConstantDef) *types.FlowNode {
+	nameNode := analyzer.FindChildByFieldName(call, "name")
+	bag := analyzer.FindChildByFieldName(call, "object")
+	if nameNode == nil || bag == nil || bag.Type() != "member_access_expression" {
+		return nil
+	}
+	methodName := analyzer.GetNodeText(nameNode, source)
+	if !phpPatterns.IsSymfonyBagMethod(methodName) {
+		return nil
+	}
+	propNode := analyzer.FindChildByFieldName(bag, "name")
+	request := analyzer.FindChildByFieldName(bag, "object")
+	if propNode == nil || request == nil || !phpPatterns.IsInputObject(analyzer.GetNodeText(request, source)) {
+		return nil
+	}
+	property := analyzer.GetNodeText(propNode, source)
+	sourceType, ok := phpPatterns.LookupSymfonyBag(property)
+	if !ok {
+		return nil
+	}
+
+	// all() returns the whole bag; subscripts on its result select the key
+	expr := call
+	var firstSubscript *sitter.Node
+	for parent := expr.Parent(); parent != nil && parent.Type() == "subscript_expression" && parent.NamedChild(0) == expr; parent = expr.Parent() {
+		if firstSubscript == nil {
+			firstSubscript = parent
+		}
+		expr = parent
+	}
+
+	flowNode := &types.FlowNode{
+		ID:         analyzer.GenerateNodeID("", call),
+		Type:       types.NodeSource,
+		Language:   "php",
+		Line:       int(call.StartPoint().Row) + 1,
+		Column:     int(call.StartPoint().Column),
+		Name:       "->" + property + "->" + methodName + "()",
+		Snippet:    analyzer.GetNodeText(expr, source),
+		SourceType: types.SourceType(sourceType),
+	}
+	if args := analyzer.FindChildrenByType(analyzer.FindChildByFieldName(call, "arguments"), "argument"); len(args) > 0 {
+		flowNode.SourceKey, _ = stringLiteral(args[0].NamedChild(0), source)
+	} else if firstSubscript != nil {
+		setSourceKey(flowNode, firstSubscript, source, constants)
+	}
+	if property == "server" {
+		// The server bag holds $_SERVER: REQUEST_URI is the path, QUERY_STRING the query
+		if st, ok := serverKeySourceType(flowNode.SourceKey); ok {
+			flowNode.SourceType = st
+		}
+	}
+
+	if outer := bagDefaultOf(call, source); outer != nil {
+		flowNode.Metadata = map[string]interface{}{
+			"fallback_of": analyzer.GetNodeText(outer, source),
+		}
+	}
+	return flowNode
+}
+
+// bagDefaultOf returns the bag getter call whose default argument is node
+// ($request->request->get('a', <node>)), or nil
+func bagDefaultOf(node *sitter.Node, source []byte) *sitter.Node {
+	arg := node.Parent()
+	if arg == nil || arg.Type() != "argument" {
+		return nil
+	}
+	args := arg.Parent()
+	if args == nil || args.Type() != "arguments" {
+		return nil
+	}
+	outer := args.Parent()
+	if outer == nil || outer.Type() != "member_call_expression" {
+		return nil
+	}
+	nameNode := analyzer.FindChildByFieldName(outer, "name")
+	if nameNode == nil {
+		return nil
+	}
+	getter, ok := phpPatterns.LookupTypedGetter(analyzer.GetNodeText(nameNode, source))
+	if !ok || getter.DefaultArg < 0 {
+		return nil
+	}
+	list := analyzer.FindChildrenByType(args, "argument")
+	if getter.DefaultArg >= len(list) || list[getter.DefaultArg] != arg {
+		return nil
+	}
+	return outer
+}
@@ -0,0 +1,190 @@
+package php
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// includeNodeTypes are the PHP include/require expression node types
+var includeNodeTypes = []string{"include_expression", "include_once_expression", "require_expression", "require_once_expression"}
+
+// scopedNode is an AST node together with its enclosing scope and line
+type scopedNode struct {
+	node  *sitter.Node
+	scope string
+	line  int
+}
+
+// ExtractTemplateBindings finds the template idiom `extract($vars); include $file;`
+// and returns one binding per include that follows an extract() in the same scope.
+// Variables read in the included file resolve to keys of the extracted array.
+func (a *PHPAnalyzer) ExtractTemplateBindings(root *sitter.Node, source []byte, filePath string) []*types.TemplateBinding {
+	var extracts []scopedNode
+	for _, call := range analyzer.FindNodesOfType(root, "function_call_expression") {
+		nameNode := call.Child(0)
+		if nameNode == nil || !strings.EqualFold(analyzer.GetNodeText(nameNode, source), "extract") {
+			continue
+		}
+		extracts = append(extracts, scopedNode{
+			node:  call,
+			scope: analyzer.ScopeIDForNode(call, source, "php"),
+			line:  int(call.StartPoint().Row) + 1,
+		})
+	}
+	if len(extracts) == 0 {
+		return nil
+	}
+	sort.Slice(extracts, func(i, j int) bool { return extracts[i].line < extracts[j].line })
+
+	var bindings []*types.TemplateBinding
+	for _, incNode := range analyzer.FindNodesOfTypes(root, includeNodeTypes) {
+		pathNode := incNode.NamedChild(0)
+		if pathNode == nil {
+			continue
+		}
+		scope := analyzer.ScopeIDForNode(incNode, source, "php")
+		line := int(incNode.StartPoint().Row) + 1
+
+		// The most recent extract() before the include in the same scope
+		var ext *scopedNode
+		for i := range extracts {
+			if extracts[i].scope == scope && extracts[i].line <= line {
+				ext = &extracts[i]
+			}
+		}
+		if ext == nil {
+			continue
+		}
+
+		argExpr, argNode := firstCallArgument(ext.node, source)
+		if argExpr == "" {
+			continue
+		}
+
+		includeExpr := analyzer.GetNodeText(pathNode, source)
+		bindings = append(bindings, &types.TemplateBinding{
+			ExtractedExpr: argExpr,
+			Values:        extractedValues(argNode, source),
+			IncludeExpr:   includeExpr,
+			IncludePath:   a.resolveTemplatePath(root, source, filePath, includeExpr, scope, line),
+			FilePath:      filePath,
+			ExtractLine:   ext.line,
+			IncludeLine:   line,
+			Scope:         scope,
+		})
+	}
+
+	return bindings
+}
+
+// firstCallArgument returns the text and expression node of a call's first argument
+func firstCallArgument(call *sitter.Node, source []byte) (string, *sitter.Node) {
+	argsNode := analyzer.FindChildByType(call, "arguments")
+	if argsNode == nil {
+		return "", nil
+	}
+	argNode := analyzer.FindChildByType(argsNode, "argument")
+	if argNode == nil {
+		return "", nil
+	}
+	if argNode.NamedChildCount() == 0 {
+		return analyzer.GetNodeText(argNode, source), nil
+	}
+	expr := argNode.NamedChild(int(argNode.NamedChildCount()) - 1)
+	if expr == nil {
+		return analyzer.GetNodeText(argNode, source), nil
+	}
+	return analyzer.GetNodeText(expr, source), expr
+}
+
+// extractedValues returns the statically known key → value expressions of an
+// extract() argument written as an array literal or a compact() call
+func extractedValues(node *sitter.Node, source []byte) map[string]string {
+	if node == nil {
+		return nil
+	}
+	values := make(map[string]string)
+
+	switch node.Type() {
+	case "array_creation_expression":
+		for _, elem := range analyzer.FindChildrenByType(node, "array_element_initializer") {
+			if elem.NamedChildCount() < 2 {
+				continue
+			}
+			key := strings.Trim(analyzer.GetNodeText(elem.NamedChild(0), source), "\"'")
+			values[key] = analyzer.GetNodeText(elem.NamedChild(1), source)
+		}
+	case "function_call_expression":
+		nameNode := node.Child(0)
+		if nameNode == nil || !strings.EqualFold(analyzer.GetNodeText(nameNode, source), "compact") {
+			return nil
+		}
+		for _, arg := range analyzer.FindNodesOfType(node, "argument") {
+			name := strings.Trim(analyzer.GetNodeText(arg, source), "\"'")
+			if name != "" && !strings.ContainsAny(name, "$( ") {
+				values[name] = "$" + name
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// resolveTemplatePath statically resolves an include path expression. Besides
+// literals and __DIR__ concatenations it follows one literal assignment to a
+// variable in the same scope, e.g. `$tpl = 'views/page.php'; include $tpl;`.
+func (a *PHPAnalyzer) resolveTemplatePath(root *sitter.Node, source []byte, filePath, expr, scope string, line int) string {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	if strings.HasPrefix(expr, "$") && !strings.ContainsAny(expr, ". ") {
+		resolved := ""
+		for _, assign := range analyzer.FindNodesOfType(root, "assignment_expression") {
+			assignLine := int(assign.StartPoint().Row) + 1
+			if assignLine > line || assign.ChildCount() < 3 {
+				continue
+			}
+			if analyzer.GetNodeText(assign.Child(0), source) != expr ||
+				analyzer.ScopeIDForNode(assign, source, "php") != scope {
+				continue
+			}
+			resolved = analyzer.GetNodeText(assign.Child(2), source)
+		}
+		if resolved == "" {
+			return ""
+		}
+		expr = resolved
+	}
+
+	dir := filepath.Dir(filePath)
+	var path strings.Builder
+	for _, part := range phpPatterns.SplitConcatenation(expr) {
+		switch {
+		case part == "__DIR__" || part == "dirname(__FILE__)":
+			path.WriteString(dir)
+		case len(part) >= 2 && (part[0] == '\'' || part[0] == '"') && part[len(part)-1] == part[0]:
+			path.WriteString(part[1 : len(part)-1])
+		default:
+			return ""
+		}
+	}
+
+	resolved := path.String()
+	if resolved == "" || strings.Contains(resolved, "$") {
+		return ""
+	}
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(dir, resolved)
+	}
+	return filepath.Clean(resolved)
+}
@@ -0,0 +1,244 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// ExtractValidators finds functions that reject invalid input: a top-level
+// guard of the body checks a parameter and throws, exits or dies when the
+// check fails, e.g. `if (!ctype_digit($x)) { throw ...; }` or
+// `if (preg_match('/^\w+$/', $x) === 0) exit;`. The check decides the
+// constraint, like a direct use of the value would (see usageConstraint).
+func (a *PHPAnalyzer) ExtractValidators(root *sitter.Node, source []byte, filePath string) []*types.Validator {
+	var validators []*types.Validator
+
+	for _, fnNode := range analyzer.FindNodesOfType(root, "function_definition") {
+		nameNode := analyzer.FindChildByFieldName(fnNode, "name")
+		bodyNode := analyzer.FindChildByFieldName(fnNode, "body")
+		paramsNode := analyzer.FindChildByFieldName(fnNode, "parameters")
+		if nameNode == nil || bodyNode == nil || paramsNode == nil {
+			continue
+		}
+		params := a.parseParameters(paramsNode, source)
+
+		found := make(map[int]*types.ParamConstraint)
+		for i := 0; i < int(bodyNode.NamedChildCount()); i++ {
+			guard := bodyNode.NamedChild(i)
+			if guard.Type() != "if_statement" || !rejects(analyzer.FindChildByFieldName(guard, "body"), source) {
+				continue
+			}
+			check := failedCheck(analyzer.FindChildByFieldName(guard, "condition"), source)
+			if check == nil {
+				continue
+			}
+			for _, param := range params {
+				if param.IsVariadic {
+					continue
+				}
+				for _, use := range analyzer.FindNodesOfType(check, "variable_name") {
+					if analyzer.GetNodeText(use, source) != "$"+param.Name {
+						continue
+					}
+					if c := a.usageConstraint(use, source); c != nil {
+						found[param.Index] = strongerConstraint(found[param.Index], c)
+					}
+				}
+			}
+		}
+
+		for _, param := range params {
+			c := found[param.Index]
+			if c == nil {
+				continue
+			}
+			validators = append(validators, &types.Validator{
+				FunctionName: analyzer.GetNodeText(nameNode, source),
+				ParamIndex:   param.Index,
+				Constraint:   *c,
+				FilePath:     filePath,
+				Line:         int(fnNode.StartPoint().Row) + 1,
+			})
+		}
+	}
+
+	return validators
+}
+
+// rejects reports whether a guard body throws, exits or dies
+func rejects(body *sitter.Node, source []byte) bool {
+	if body == nil {
+		return false
+	}
+	if len(analyzer.FindNodesOfTypes(body, []string{"throw_expression", "exit_statement"})) > 0 {
+		return true
+	}
+	for _, call := range analyzer.FindNodesOfType(body, "function_call_expression") {
+		if nameNode := analyzer.FindChildByFieldName(call, "function"); nameNode != nil &&
+			phpPatterns.RejectFunctions[strings.ToLower(analyzer.GetNodeText(nameNode, source))] {
+			return true
+		}
+	}
+	return false
+}
+
+// failedCheck returns the check a guard condition negates: X in `!X`,
+// `X == false` or `X == 0`. A strict comparison must name the value X returns
+// on failure: `X === 0` for counting checks such as preg_match, whose false
+// means an error rather than a mismatch, and `X === false` for the others.
+// nil for other conditions.
+func failedCheck(cond *sitter.Node, source []byte) *sitter.Node {
+	for cond != nil && cond.Type() == "parenthesized_expression" {
+		cond = cond.NamedChild(0)
+	}
+	if cond == nil {
+		return nil
+	}
+	switch cond.Type() {
+	case "unary_op_expression":
+		if op := analyzer.FindChildByFieldName(cond, "operator"); op != nil && analyzer.GetNodeText(op, source) != "!" {
+			return nil
+		}
+		if !strings.HasPrefix(analyzer.GetNodeText(cond, source), "!") {
+			return nil
+		}
+		return analyzer.FindChildByFieldName(cond, "argument")
+	case "binary_expression":
+		op := analyzer.FindChildByFieldName(cond, "operator")
+		if op == nil {
+			return nil
+		}
+		strict := false
+		switch analyzer.GetNodeText(op, source) {
+		case "===":
+			strict = true
+		case "==":
+		default:
+			return nil
+		}
+		check, value := analyzer.FindChildByFieldName(cond, "left"), analyzer.FindChildByFieldName(cond, "right")
+		if check == nil || value == nil {
+			return nil
+		}
+		if isFailureValue(check, source) {
+			check, value = value, check
+		}
+		if !isFailureValue(value, source) {
+			return nil
+		}
+		if !strict {
+			return check
+		}
+		counting := countingCheck(check, source)
+		if literal := strings.ToLower(analyzer.GetNodeText(value, source)); (literal == "0") == counting {
+			return check
+		}
+	}
+	return nil
+}
+
+// isFailureValue reports whether node is false or 0
+func isFailureValue(node *sitter.Node, source []byte) bool {
+	text := strings.ToLower(analyzer.GetNodeText(node, source))
+	return text == "false" || text == "0"
+}
+
+// countingCheck reports whether check calls a function returning a match
+// count (see phpPatterns.CountingCheckFunctions)
+func countingCheck(check *sitter.Node, source []byte) bool {
+	if check.Type() != "function_call_expression" {
+		return false
+	}
+	name := analyzer.FindChildByFieldName(check, "function")
+	return name != nil && phpPatterns.CountingCheckFunctions[strings.ToLower(strings.TrimPrefix(analyzer.GetNodeText(name, source), "\\"))]
+}
+
+// strongerConstraint returns the more restrictive of two constraints: a type
+// conversion wins over enum values, which win over a pattern
+func strongerConstraint(current, next *types.ParamConstraint) *types.ParamConstraint {
+	rank := func(c *types.ParamConstraint) int {
+		switch {
+		case c == nil:
+			return 0
+		case c.Type == types.ParamPattern:
+			return 1
+		case c.Type == types.ParamEnum:
+			return 2
+		default:
+			return 3
+		}
+	}
+	if rank(next) > rank(current) {
+		return next
+	}
+	return current
+}
+
+// callNodeTypes are the expressions call sites are extracted from
+var callNodeTypes = map[string]bool{
+	"function_call_expression":        true,
+	"member_call_expression":          true,
+	"nullsafe_member_call_expression": true,
+	"scoped_call_expression":          true,
+}
+
+// CallDominates reports whether the call starting at call runs whenever the
+// code at from runs: both belong to the same function, and no branch, loop,
+// try block or short-circuit operand between the call and the innermost node
+// containing both can skip the call
+func (a *PHPAnalyzer) CallDominates(root *sitter.Node, from, call sitter.Point) bool {
+	fromNode := root.NamedDescendantForPointRange(from, from)
+	callNode := root.NamedDescendantForPointRange(call, call)
+	for callNode != nil && !callNodeTypes[callNode.Type()] {
+		callNode = callNode.Parent()
+	}
+	if fromNode == nil || callNode == nil || !sameNode(enclosingScope(fromNode), enclosingScope(callNode)) {
+		return false
+	}
+
+	for node := callNode; !encloses(node, fromNode); node = node.Parent() {
+		parent := node.Parent()
+		if parent == nil {
+			return false
+		}
+		if maySkip(parent, node) {
+			return false
+		}
+	}
+	return true
+}
+
+// maySkip reports whether parent can complete without evaluating child
+func maySkip(parent, child *sitter.Node) bool {
+	switch parent.Type() {
+	case "if_statement", "while_statement", "switch_statement", "match_expression", "conditional_expression":
+		return !sameNode(child, analyzer.FindChildByFieldName(parent, "condition"))
+	case "binary_expression", "augmented_assignment_expression":
+		operator := analyzer.FindChildByFieldName(parent, "operator")
+		if operator == nil {
+			return false
+		}
+		switch strings.ToLower(operator.Type()) {
+		case "&&", "||", "??", "and", "or", "??=":
+			return sameNode(child, analyzer.FindChildByFieldName(parent, "right"))
+		}
+		return false
+	case "for_statement", "foreach_statement", "try_statement":
+		return true
+	}
+	return false
+}
+
+// sameNode reports whether a and b span the same source range with the same type
+func sameNode(a, b *sitter.Node) bool {
+	return a != nil && b != nil && a.Type() == b.Type() && a.StartByte() == b.StartByte() && a.EndByte() == b.EndByte()
+}
+
+// encloses reports whether inner lies within outer
+func encloses(outer, inner *sitter.Node) bool {
+	return inner.StartByte() >= outer.StartByte() && inner.EndByte() <= outer.EndByte()
+}
@@ -0,0 +1,172 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// ExtractSourceWrappers finds thin global functions whose body is a single return
+// of a superglobal indexed by a parameter, optionally guarded by `??` or isset():
+//
+//	function get_param($k) { return $_REQUEST[$k] ?? null; }
+//	function get_query($k, $d = '') { return isset($_GET[$k]) ? $_GET[$k] : $d; }
+func (a *PHPAnalyzer) ExtractSourceWrappers(root *sitter.Node, source []byte, filePath string) []*types.SourceWrapper {
+	var wrappers []*types.SourceWrapper
+
+	for _, fnNode := range analyzer.FindNodesOfType(root, "function_definition") {
+		nameNode := analyzer.FindChildByFieldName(fnNode, "name")
+		bodyNode := analyzer.FindChildByFieldName(fnNode, "body")
+		paramsNode := analyzer.FindChildByFieldName(fnNode, "parameters")
+		if nameNode == nil || bodyNode == nil || paramsNode == nil {
+			continue
+		}
+
+		returned := singleReturnExpression(bodyNode)
+		if returned == nil {
+			continue
+		}
+		access := a.superglobalAccess(returned, source)
+		if access == nil || access.NamedChildCount() < 2 {
+			continue
+		}
+
+		superglobal := analyzer.GetNodeText(access.NamedChild(0), source)
+		keyVar := strings.TrimPrefix(analyzer.GetNodeText(access.NamedChild(1), source), "$")
+		for _, param := range a.parseParameters(paramsNode, source) {
+			if param.Name != keyVar || param.IsVariadic {
+				continue
+			}
+			wrappers = append(wrappers, &types.SourceWrapper{
+				FunctionName: analyzer.GetNodeText(nameNode, source),
+				ParamIndex:   param.Index,
+				ParamName:    param.Name,
+				Superglobal:  superglobal,
+				SourceType:   a.superglobals[superglobal],
+				FilePath:     filePath,
+				Line:         int(fnNode.StartPoint().Row) + 1,
+			})
+			break
+		}
+	}
+
+	return wrappers
+}
+
+// singleReturnExpression returns the returned expression of a body consisting of
+// exactly one return statement (comments aside), or nil
+func singleReturnExpression(body *sitter.Node) *sitter.Node {
+	var ret *sitter.Node
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		child := body.NamedChild(i)
+		if child.Type() == "comment" {
+			continue
+		}
+		if child.Type() != "return_statement" || ret != nil {
+			return nil
+		}
+		ret = child
+	}
+	if ret == nil || ret.NamedChildCount() == 0 {
+		return nil
+	}
+	return ret.NamedChild(0)
+}
+
+// superglobalAccess unwraps `X ?? default`, `isset(X) ? X : default` and
+// parentheses, returning X if it is a superglobal subscripted by a variable
+func (a *PHPAnalyzer) superglobalAccess(node *sitter.Node, source []byte) *sitter.Node {
+	for node != nil {
+		switch node.Type() {
+		case "parenthesized_expression":
+			node = node.NamedChild(0)
+		case "binary_expression":
+			op := analyzer.FindChildByFieldName(node, "operator")
+			if op == nil || analyzer.GetNodeText(op, source) != "??" {
+				return nil
+			}
+			node = analyzer.FindChildByFieldName(node, "left")
+		case "conditional_expression":
+			node = analyzer.FindChildByFieldName(node, "body")
+		case "subscript_expression":
+			if node.NamedChildCount() < 2 {
+				return nil
+			}
+			base, index := node.NamedChild(0), node.NamedChild(1)
+			if base.Type() != "variable_name" || index.Type() != "variable_name" {
+				return nil
+			}
+			if _, ok := a.superglobals[analyzer.GetNodeText(base, source)]; !ok {
+				return nil
+			}
+			return node
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// FindCalledNames returns the lowercase names of the functions and methods
+// called in a file, as written without a leading backslash. Promotions of
+// derived sources re-parse only the files calling a promoted name.
+func (a *PHPAnalyzer) FindCalledNames(root *sitter.Node, source []byte) map[string]bool {
+	names := make(map[string]bool)
+	calls := analyzer.FindNodesOfTypes(root, []string{"function_call_expression", "member_call_expression", "nullsafe_member_call_expression", "scoped_call_expression"})
+	for _, call := range calls {
+		field := "name"
+		if call.Type() == "function_call_expression" {
+			field = "function"
+		}
+		if nameNode := analyzer.FindChildByFieldName(call, field); nameNode != nil {
+			names[strings.ToLower(strings.TrimPrefix(analyzer.GetNodeText(nameNode, source), "\\"))] = true
+		}
+	}
+	return names
+}
+
+// FindWrapperSources returns a source node for every call to a known source
+// wrapper. wrappers is keyed by lowercase function name (PHP function names
+// are case-insensitive); the key is taken from the wrapped parameter's argument.
+func (a *PHPAnalyzer) FindWrapperSources(root *sitter.Node, source []byte, wrappers map[string]*types.SourceWrapper) []*types.FlowNode {
+	var sources []*types.FlowNode
+
+	for _, call := range analyzer.FindNodesOfType(root, "function_call_expression") {
+		nameNode := analyzer.FindChildByFieldName(call, "function")
+		if nameNode == nil {
+			continue
+		}
+		funcName := analyzer.GetNodeText(nameNode, source)
+		wrapper, ok := wrappers[strings.ToLower(strings.TrimPrefix(funcName, "\\"))]
+		if !ok {
+			continue
+		}
+
+		flowNode := &types.FlowNode{
+			ID:         analyzer.GenerateNodeID("", call),
+			Type:       types.NodeSource,
+			Language:   "php",
+			Line:       int(call.StartPoint().Row) + 1,
+			Column:     int(call.StartPoint().Column),
+			Name:       funcName,
+			Snippet:    analyzer.GetNodeText(call, source),
+			SourceType: wrapper.SourceType,
+		}
+
+		if argsNode := analyzer.FindChildByType(call, "arguments"); argsNode != nil {
+			args := analyzer.FindChildrenByType(argsNode, "argument")
+			if wrapper.ParamIndex < len(args) {
+				arg := analyzer.GetNodeText(args[wrapper.ParamIndex], source)
+				if len(arg) >= 2 && (arg[0] == '\'' || arg[0] == '"') && arg[len(arg)-1] == arg[0] {
+					flowNode.SourceKey = arg[1 : len(arg)-1]
+				}
+			}
+		}
+
+		sources = append(sources, flowNode)
+	}
+
+	return sources
+}
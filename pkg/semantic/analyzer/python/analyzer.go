@@ -9,6 +9,7 @@ import (
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 	pythonPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/python"
 	sitter "github.com/smacker/go-tree-sitter"
 )
@@ -35,9 +36,23 @@ func NewPythonAnalyzer() *PythonAnalyzer {
 	return a
 }
 
+// WithFrameworkPatterns returns a copy of the analyzer matching patterns
+// instead of the ones registered in pkg/sources/python
+func (a *PythonAnalyzer) WithFrameworkPatterns(patterns []*common.FrameworkPattern) analyzer.LanguageAnalyzer {
+	c := *a
+	c.BaseAnalyzer = a.WithoutFrameworkPatterns()
+	c.addFrameworkPatterns(patterns)
+	return &c
+}
+
 // registerFrameworkPatterns loads Python framework patterns from pkg/sources/python
 func (a *PythonAnalyzer) registerFrameworkPatterns() {
-	for _, p := range pythonPatterns.GetAllPatterns() {
+	a.addFrameworkPatterns(pythonPatterns.GetAllPatterns())
+}
+
+// addFrameworkPatterns converts framework patterns from pkg/sources and adds them
+func (a *PythonAnalyzer) addFrameworkPatterns(patterns []*common.FrameworkPattern) {
+	for _, p := range patterns {
 		fp := &types.FrameworkPattern{
 			ID:              p.ID,
 			Framework:       p.Framework,
@@ -0,0 +1,126 @@
+package analyzer
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// classScopeNodeTypes are node types that open a class-like scope
+var classScopeNodeTypes = map[string]bool{
+	"class_declaration":     true,
+	"class_definition":      true,
+	"class_specifier":       true,
+	"interface_declaration": true,
+	"trait_declaration":     true,
+	"enum_declaration":      true,
+}
+
+// extraFunctionScopeNodeTypes complements sources.IsFunctionNodeForLanguage
+// with function-like nodes that are not listed there
+var extraFunctionScopeNodeTypes = map[string]bool{
+	"anonymous_function_creation_expression": true,
+	"constructor_declaration":                true,
+}
+
+// blockScopeNodeTypes are statement blocks that open a block scope
+var blockScopeNodeTypes = map[string]bool{
+	"compound_statement": true,
+	"statement_block":    true,
+	"block":              true,
+}
+
+// scopeTypeOf classifies an AST node as a scope, returning false if it opens none
+func scopeTypeOf(node *sitter.Node, language string) (types.ScopeType, bool) {
+	nodeType := node.Type()
+	if classScopeNodeTypes[nodeType] {
+		return types.ScopeClass, true
+	}
+	if extraFunctionScopeNodeTypes[nodeType] || sources.IsFunctionNodeForLanguage(nodeType, language) {
+		return types.ScopeFunction, true
+	}
+	if blockScopeNodeTypes[nodeType] {
+		// A function or class body is part of its owner, not a nested block
+		if parent := node.Parent(); parent != nil {
+			if _, owned := scopeTypeOf(parent, language); owned {
+				return "", false
+			}
+			if strings.HasSuffix(parent.Type(), "_body") || parent.Type() == "declaration_list" {
+				return "", false
+			}
+		}
+		return types.ScopeBlock, true
+	}
+	return "", false
+}
+
+// scopeName returns the declared name of a scope node ("" for anonymous scopes)
+func scopeName(node *sitter.Node, scopeType types.ScopeType, source []byte) string {
+	if scopeType == types.ScopeBlock {
+		return ""
+	}
+	if nameNode := FindChildByFieldName(node, "name"); nameNode != nil {
+		return GetNodeText(nameNode, source)
+	}
+	return ""
+}
+
+// BuildScopeTree builds the file → class → method → block scope tree for a file
+func BuildScopeTree(root *sitter.Node, source []byte, filePath, language string) *types.Scope {
+	if root == nil {
+		return nil
+	}
+	fileScope := types.NewFileScope(filePath, int(root.EndPoint().Row)+1)
+
+	var walk func(node *sitter.Node, parent *types.Scope)
+	walk = func(node *sitter.Node, parent *types.Scope) {
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			if child == nil {
+				continue
+			}
+			if child.Type() == "global_declaration" {
+				owner := parent.VariableScope()
+				for _, v := range FindChildrenByType(child, "variable_name") {
+					owner.Globals = append(owner.Globals, strings.TrimPrefix(GetNodeText(v, source), "$"))
+				}
+			}
+			next := parent
+			if scopeType, ok := scopeTypeOf(child, language); ok {
+				next = parent.AddChild(scopeType, scopeName(child, scopeType, source),
+					int(child.StartPoint().Row)+1, int(child.EndPoint().Row)+1)
+			}
+			walk(child, next)
+		}
+	}
+	walk(root, fileScope)
+
+	return fileScope
+}
+
+// ScopeIDForNode returns the ID of the variable-owning scope enclosing node,
+// matching the IDs produced by BuildScopeTree ("" for file-level code)
+func ScopeIDForNode(node *sitter.Node, source []byte, language string) string {
+	var chain []*sitter.Node
+	for cur := node.Parent(); cur != nil; cur = cur.Parent() {
+		if _, ok := scopeTypeOf(cur, language); ok {
+			chain = append(chain, cur)
+		}
+	}
+
+	var scope *types.Scope
+	varScopeID := ""
+	for i := len(chain) - 1; i >= 0; i-- {
+		scopeType, _ := scopeTypeOf(chain[i], language)
+		id := types.QualifyScopeID(scope, scopeType, scopeName(chain[i], scopeType, source),
+			int(chain[i].StartPoint().Row)+1)
+		scope = &types.Scope{ID: id, Type: scopeType, Parent: scope}
+		if scopeType == types.ScopeFunction {
+			varScopeID = id
+		}
+	}
+
+	return varScopeID
+}
@@ -0,0 +1,414 @@
+// Package swift implements the Swift language analyzer for semantic input tracing
+package swift
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/parser/languages"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// SwiftAnalyzer implements the LanguageAnalyzer interface for Swift
+type SwiftAnalyzer struct {
+	*analyzer.BaseAnalyzer
+	inputSources map[string]types.SourceType
+}
+
+// NewSwiftAnalyzer creates a new Swift analyzer
+func NewSwiftAnalyzer() *SwiftAnalyzer {
+	m := sources.GetMappings("swift")
+	a := &SwiftAnalyzer{
+		BaseAnalyzer: analyzer.NewBaseAnalyzer("swift", languages.GetExtensionsForLanguage("swift")),
+		inputSources: m.GetInputSourcesMap(),
+	}
+
+	return a
+}
+
+func (a *SwiftAnalyzer) BuildSymbolTable(filePath string, source []byte, root *sitter.Node) (*types.SymbolTable, error) {
+	st := types.NewSymbolTable(filePath, "swift")
+	st.Imports = a.extractImports(root, source)
+
+	classes, _ := a.ExtractClasses(root, source)
+	for _, class := range classes {
+		class.FilePath = filePath
+		st.Classes[class.Name] = class
+	}
+
+	functions, _ := a.ExtractFunctions(root, source)
+	for _, fn := range functions {
+		fn.FilePath = filePath
+		st.Functions[fn.Name] = fn
+	}
+
+	return st, nil
+}
+
+func (a *SwiftAnalyzer) extractImports(root *sitter.Node, source []byte) []types.ImportInfo {
+	var imports []types.ImportInfo
+
+	for _, node := range analyzer.FindNodesOfType(root, "import_declaration") {
+		ident := analyzer.FindChildByType(node, "identifier")
+		if ident == nil {
+			continue
+		}
+		imports = append(imports, types.ImportInfo{
+			Path: analyzer.GetNodeText(ident, source),
+			Line: int(node.StartPoint().Row) + 1,
+			Type: "import",
+		})
+	}
+
+	return imports
+}
+
+func (a *SwiftAnalyzer) ResolveImports(symbolTable *types.SymbolTable, basePath string) ([]string, error) {
+	return nil, nil
+}
+
+func (a *SwiftAnalyzer) ExtractClasses(root *sitter.Node, source []byte) ([]*types.ClassDef, error) {
+	var classes []*types.ClassDef
+
+	// Classes, structs, enums and extensions share class_declaration
+	for _, classNode := range analyzer.FindNodesOfType(root, "class_declaration") {
+		nameNode := analyzer.FindChildByFieldName(classNode, "name")
+		if nameNode == nil {
+			continue
+		}
+
+		class := types.NewClassDef(analyzer.GetNodeText(nameNode, source), "", int(classNode.StartPoint().Row)+1)
+		class.EndLine = int(classNode.EndPoint().Row) + 1
+
+		if inheritance := analyzer.FindChildByType(classNode, "inheritance_specifier"); inheritance != nil {
+			class.Extends = analyzer.GetNodeText(inheritance, source)
+		}
+
+		if body := analyzer.FindChildByFieldName(classNode, "body"); body != nil {
+			for _, fnNode := range analyzer.FindChildrenByType(body, "function_declaration") {
+				fn := a.extractFunction(fnNode, source)
+				if fn == nil {
+					continue
+				}
+				class.Methods[fn.Name] = &types.MethodDef{
+					Name:       fn.Name,
+					Parameters: fn.Parameters,
+					ReturnType: fn.ReturnType,
+					Line:       fn.Line,
+					EndLine:    fn.EndLine,
+					BodyStart:  fn.BodyStart,
+					BodyEnd:    fn.BodyEnd,
+					BodySource: fn.BodySource,
+				}
+			}
+			for _, propNode := range analyzer.FindChildrenByType(body, "property_declaration") {
+				if name := a.declaredName(propNode, source); name != "" {
+					class.Properties[name] = &types.PropertyDef{
+						Name: name,
+						Line: int(propNode.StartPoint().Row) + 1,
+					}
+				}
+			}
+		}
+
+		classes = append(classes, class)
+	}
+
+	return classes, nil
+}
+
+func (a *SwiftAnalyzer) ExtractFunctions(root *sitter.Node, source []byte) ([]*types.FunctionDef, error) {
+	var functions []*types.FunctionDef
+
+	for _, fnNode := range analyzer.FindNodesOfType(root, "function_declaration") {
+		// Skip methods of types and extensions
+		if analyzer.GetAncestorOfType(fnNode, "class_body") != nil {
+			continue
+		}
+		if fn := a.extractFunction(fnNode, source); fn != nil {
+			functions = append(functions, fn)
+		}
+	}
+
+	return functions, nil
+}
+
+// extractFunction builds a function definition from a function_declaration
+func (a *SwiftAnalyzer) extractFunction(fnNode *sitter.Node, source []byte) *types.FunctionDef {
+	nameNode := analyzer.FindChildByFieldName(fnNode, "name")
+	if nameNode == nil {
+		return nil
+	}
+
+	fn := &types.FunctionDef{
+		Name:       analyzer.GetNodeText(nameNode, source),
+		Line:       int(fnNode.StartPoint().Row) + 1,
+		EndLine:    int(fnNode.EndPoint().Row) + 1,
+		Parameters: a.parseParameters(fnNode, source),
+	}
+
+	if returnType := analyzer.FindChildByFieldName(fnNode, "return_type"); returnType != nil {
+		fn.ReturnType = analyzer.GetNodeText(returnType, source)
+	}
+
+	if bodyNode := analyzer.FindChildByFieldName(fnNode, "body"); bodyNode != nil {
+		fn.BodyStart = int(bodyNode.StartPoint().Row) + 1
+		fn.BodyEnd = int(bodyNode.EndPoint().Row) + 1
+		fn.BodySource = analyzer.GetNodeText(bodyNode, source)
+	}
+
+	return fn
+}
+
+// parseParameters reads the parameters of a function_declaration, which the
+// grammar places directly under the declaration
+func (a *SwiftAnalyzer) parseParameters(fnNode *sitter.Node, source []byte) []types.ParameterDef {
+	var params []types.ParameterDef
+
+	for _, paramNode := range analyzer.FindChildrenByType(fnNode, "parameter") {
+		nameNode := analyzer.FindChildByFieldName(paramNode, "name")
+		if nameNode == nil {
+			continue
+		}
+		param := types.ParameterDef{
+			Name:  analyzer.GetNodeText(nameNode, source),
+			Index: len(params),
+		}
+		if typeNode := analyzer.FindChildByType(paramNode, "user_type"); typeNode != nil {
+			param.Type = analyzer.GetNodeText(typeNode, source)
+		}
+		param.IsVariadic = strings.Contains(analyzer.GetNodeText(paramNode, source), "...")
+		params = append(params, param)
+	}
+
+	return params
+}
+
+// declaredName returns the variable declared by a property_declaration
+func (a *SwiftAnalyzer) declaredName(node *sitter.Node, source []byte) string {
+	pattern := analyzer.FindChildByFieldName(node, "name")
+	if pattern == nil {
+		return ""
+	}
+	if ident := analyzer.FindChildByType(pattern, "bound_identifier"); ident != nil {
+		return analyzer.GetNodeText(ident, source)
+	}
+	return analyzer.GetNodeText(pattern, source)
+}
+
+func (a *SwiftAnalyzer) ExtractAssignments(root *sitter.Node, source []byte, scope string) ([]*types.Assignment, error) {
+	var assignments []*types.Assignment
+
+	// let/var declarations
+	for _, node := range analyzer.FindNodesOfType(root, "property_declaration") {
+		name := a.declaredName(node, source)
+		valueNode := analyzer.FindChildByFieldName(node, "value")
+		if name == "" || valueNode == nil {
+			continue
+		}
+		assignment := &types.Assignment{
+			Target: name,
+			Source: analyzer.GetNodeText(valueNode, source),
+			Line:   int(node.StartPoint().Row) + 1,
+			Column: int(node.StartPoint().Column),
+			Scope:  scope,
+		}
+		assignment.IsTainted, assignment.TaintSource = a.isExpressionTainted(valueNode, source)
+		assignments = append(assignments, assignment)
+	}
+
+	// Reassignments (x = ..., x += ...)
+	for _, node := range analyzer.FindNodesOfType(root, "assignment") {
+		targetNode := analyzer.FindChildByFieldName(node, "target")
+		valueNode := analyzer.FindChildByFieldName(node, "result")
+		if targetNode == nil || valueNode == nil {
+			continue
+		}
+		assignment := &types.Assignment{
+			Target: analyzer.GetNodeText(targetNode, source),
+			Source: analyzer.GetNodeText(valueNode, source),
+			Line:   int(node.StartPoint().Row) + 1,
+			Column: int(node.StartPoint().Column),
+			Scope:  scope,
+		}
+		assignment.IsTainted, assignment.TaintSource = a.isExpressionTainted(valueNode, source)
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, nil
+}
+
+func (a *SwiftAnalyzer) isExpressionTainted(node *sitter.Node, source []byte) (bool, string) {
+	if node == nil {
+		return false, ""
+	}
+
+	text := analyzer.GetNodeText(node, source)
+	for pattern := range a.inputSources {
+		if strings.Contains(text, pattern) {
+			return true, pattern
+		}
+	}
+
+	return false, ""
+}
+
+func (a *SwiftAnalyzer) ExtractCalls(root *sitter.Node, source []byte, scope string) ([]*types.CallSite, error) {
+	var calls []*types.CallSite
+
+	for _, node := range analyzer.FindNodesOfType(root, "call_expression") {
+		suffix := analyzer.FindChildByType(node, "call_suffix")
+		if suffix == nil || node.ChildCount() < 2 {
+			continue
+		}
+		argsNode := analyzer.FindChildByType(suffix, "value_arguments")
+		// Subscripts (req.query["name"]) share call_expression but are reads
+		if argsNode != nil && argsNode.ChildCount() > 0 && argsNode.Child(0).Type() == "[" {
+			continue
+		}
+		calleeNode := node.Child(0)
+
+		call := &types.CallSite{
+			FunctionName: analyzer.GetNodeText(calleeNode, source),
+			Line:         int(node.StartPoint().Row) + 1,
+			Column:       int(node.StartPoint().Column),
+			Scope:        scope,
+			Arguments:    make([]types.CallArg, 0),
+		}
+
+		// Method call: receiver.method(...)
+		if calleeNode.Type() == "navigation_expression" {
+			target := analyzer.FindChildByFieldName(calleeNode, "target")
+			navSuffix := analyzer.FindChildByFieldName(calleeNode, "suffix")
+			if target != nil && navSuffix != nil {
+				if method := analyzer.FindChildByFieldName(navSuffix, "suffix"); method != nil {
+					call.ClassName = analyzer.GetNodeText(target, source)
+					call.MethodName = analyzer.GetNodeText(method, source)
+				}
+			}
+		}
+
+		if argsNode != nil {
+			for _, argNode := range analyzer.FindChildrenByType(argsNode, "value_argument") {
+				valueNode := analyzer.FindChildByFieldName(argNode, "value")
+				if valueNode == nil {
+					valueNode = argNode
+				}
+				arg := types.CallArg{
+					Index: len(call.Arguments),
+					Value: analyzer.GetNodeText(valueNode, source),
+				}
+				arg.IsTainted, arg.TaintSource = a.isExpressionTainted(valueNode, source)
+				call.Arguments = append(call.Arguments, arg)
+			}
+		}
+
+		for i, arg := range call.Arguments {
+			if arg.IsTainted {
+				call.HasTaintedArgs = true
+				call.TaintedArgIndices = append(call.TaintedArgIndices, i)
+			}
+		}
+
+		calls = append(calls, call)
+	}
+
+	return calls, nil
+}
+
+func (a *SwiftAnalyzer) FindInputSources(root *sitter.Node, source []byte) ([]*types.FlowNode, error) {
+	var sources []*types.FlowNode
+
+	// Vapor request members (req.query, req.content, ...) and standard
+	// library reads (CommandLine.arguments, readLine)
+	analyzer.TraverseTree(root, func(node *sitter.Node) bool {
+		var name string
+		switch node.Type() {
+		case "navigation_expression":
+			name = analyzer.GetNodeText(node, source)
+		case "call_expression":
+			if callee := node.Child(0); callee != nil && callee.Type() == "simple_identifier" {
+				name = analyzer.GetNodeText(callee, source)
+			}
+		}
+		sourceType, ok := a.inputSources[name]
+		if !ok {
+			return true
+		}
+
+		// Report the whole read: the call or subscript of the member
+		reportNode := node
+		if parent := node.Parent(); parent != nil && node.Type() == "navigation_expression" &&
+			parent.Type() == "call_expression" && parent.Child(0) == node {
+			reportNode = parent
+		}
+		sources = append(sources, &types.FlowNode{
+			ID:         analyzer.GenerateNodeID("", reportNode),
+			Type:       types.NodeSource,
+			Language:   "swift",
+			Line:       int(reportNode.StartPoint().Row) + 1,
+			Column:     int(reportNode.StartPoint().Column),
+			Name:       name,
+			Snippet:    analyzer.GetNodeText(reportNode, source),
+			SourceType: sourceType,
+		})
+		return false
+	})
+
+	return sources, nil
+}
+
+func (a *SwiftAnalyzer) DetectFrameworks(symbolTable *types.SymbolTable, source []byte) ([]string, error) {
+	var frameworks []string
+
+	for _, imp := range symbolTable.Imports {
+		if imp.Path == "Vapor" {
+			frameworks = append(frameworks, "Vapor")
+			break
+		}
+	}
+
+	return frameworks, nil
+}
+
+func (a *SwiftAnalyzer) AnalyzeMethodBody(method *types.MethodDef, source []byte, state *types.AnalysisState) (*analyzer.MethodFlowAnalysis, error) {
+	return &analyzer.MethodFlowAnalysis{
+		ParamsToReturn:     make([]int, 0),
+		ParamsToProperties: make(map[int][]string),
+		ParamsToCallArgs:   make(map[int][]*types.CallSite),
+		TaintedVariables:   make(map[string]*types.TaintInfo),
+		Assignments:        make([]*types.Assignment, 0),
+		Calls:              make([]*types.CallSite, 0),
+		Returns:            make([]analyzer.ReturnInfo, 0),
+	}, nil
+}
+
+func (a *SwiftAnalyzer) TraceExpression(target types.FlowTarget, state *types.AnalysisState) (*types.FlowMap, error) {
+	flowMap := types.NewFlowMap()
+	flowMap.Target = target
+
+	expr := target.Expression
+
+	for fn, sourceType := range a.inputSources {
+		if strings.Contains(expr, fn) {
+			sourceNode := types.FlowNode{
+				ID:         fmt.Sprintf("source-%s", fn),
+				Type:       types.NodeSource,
+				Language:   "swift",
+				Name:       fn,
+				Snippet:    expr,
+				SourceType: sourceType,
+			}
+			flowMap.AddSource(sourceNode)
+		}
+	}
+
+	return flowMap, nil
+}
+
+func init() {
+	analyzer.DefaultRegistry.Register(NewSwiftAnalyzer())
+}
@@ -7,8 +7,10 @@ import (
 
 	"github.com/hatlesswizard/inputtracer/pkg/parser/languages"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	jsAnalyzer "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/javascript"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 	jsPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/javascript"
 	tspatterns "github.com/hatlesswizard/inputtracer/pkg/sources/typescript"
 	sitter "github.com/smacker/go-tree-sitter"
@@ -37,9 +39,23 @@ func NewTypeScriptAnalyzer() *TypeScriptAnalyzer {
 	return a
 }
 
+// WithFrameworkPatterns returns a copy of the analyzer matching patterns
+// instead of the ones registered in pkg/sources/javascript
+func (a *TypeScriptAnalyzer) WithFrameworkPatterns(patterns []*common.FrameworkPattern) analyzer.LanguageAnalyzer {
+	c := *a
+	c.BaseAnalyzer = a.WithoutFrameworkPatterns()
+	c.addFrameworkPatterns(patterns)
+	return &c
+}
+
 func (a *TypeScriptAnalyzer) registerFrameworkPatterns() {
 	// TypeScript uses JavaScript patterns (Express, NestJS, etc.)
-	for _, p := range jsPatterns.GetAllPatterns() {
+	a.addFrameworkPatterns(jsPatterns.GetAllPatterns())
+}
+
+// addFrameworkPatterns converts framework patterns from pkg/sources and adds them
+func (a *TypeScriptAnalyzer) addFrameworkPatterns(patterns []*common.FrameworkPattern) {
+	for _, p := range patterns {
 		fp := &types.FrameworkPattern{
 			ID:              p.ID,
 			Framework:       p.Framework,
@@ -203,6 +219,10 @@ func (a *TypeScriptAnalyzer) parseClassBody(class *types.ClassDef, bodyNode *sit
 			if method != nil {
 				if method.Name == "constructor" {
 					class.Constructor = method
+					for _, param := range a.parameterProperties(child, source) {
+						prop := a.parsePropertyDefinition(param, source)
+						class.Properties[prop.Name] = prop
+					}
 				}
 				class.Methods[method.Name] = method
 			}
@@ -212,6 +232,13 @@ func (a *TypeScriptAnalyzer) parseClassBody(class *types.ClassDef, bodyNode *sit
 
 func (a *TypeScriptAnalyzer) parsePropertyDefinition(node *sitter.Node, source []byte) *types.PropertyDef {
 	nameNode := analyzer.FindChildByType(node, "property_identifier")
+	if nameNode == nil {
+		nameNode = analyzer.FindChildByType(node, "private_property_identifier")
+	}
+	if nameNode == nil {
+		// Parameter property: constructor(private x: string)
+		nameNode = node.ChildByFieldName("pattern")
+	}
 	if nameNode == nil {
 		return nil
 	}
@@ -221,6 +248,9 @@ func (a *TypeScriptAnalyzer) parsePropertyDefinition(node *sitter.Node, source [
 		Visibility: "public",
 		Line:       int(node.StartPoint().Row) + 1,
 	}
+	if strings.HasPrefix(prop.Name, "#") {
+		prop.Visibility = "private"
+	}
 
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
@@ -398,7 +428,7 @@ func (a *TypeScriptAnalyzer) parseFunctionDeclaration(node *sitter.Node, source
 func (a *TypeScriptAnalyzer) ExtractAssignments(root *sitter.Node, source []byte, scope string) ([]*types.Assignment, error) {
 	var assignments []*types.Assignment
 
-	assignNodes := analyzer.FindNodesOfType(root, "assignment_expression")
+	assignNodes := analyzer.FindNodesOfTypes(root, []string{"assignment_expression", "augmented_assignment_expression"})
 	for _, node := range assignNodes {
 		leftNode := node.ChildByFieldName("left")
 		rightNode := node.ChildByFieldName("right")
@@ -408,16 +438,88 @@ func (a *TypeScriptAnalyzer) ExtractAssignments(root *sitter.Node, source []byte
 				Source:   analyzer.GetNodeText(rightNode, source),
 				Line:     int(node.StartPoint().Row) + 1,
 				Column:   int(node.StartPoint().Column),
-				Scope:    scope,
+				Scope:    a.scopeFor(node, source, scope),
 			}
+			if opNode := node.ChildByFieldName("operator"); opNode != nil {
+				assignment.Operator = analyzer.GetNodeText(opNode, source)
+			} else {
+				assignment.Operator = "="
+			}
+			assignment.TargetType, assignment.Keys = jsAnalyzer.AssignmentTarget(leftNode, source)
 			assignment.IsTainted, assignment.TaintSource = a.isExpressionTainted(rightNode, source)
 			assignments = append(assignments, assignment)
 		}
 	}
 
+	// Class field initializers: token = req.query.token, #token = ...
+	fieldNodes := analyzer.FindNodesOfTypes(root, []string{"public_field_definition", "field_definition"})
+	for _, node := range fieldNodes {
+		if assignment := jsAnalyzer.FieldAssignment(node, source, a.scopeFor(node, source, scope)); assignment != nil {
+			assignment.IsTainted, assignment.TaintSource = a.isExpressionTainted(node.ChildByFieldName("value"), source)
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	// Parameter properties: constructor(private x: string) assigns this.x = x
+	for _, param := range a.parameterProperties(root, source) {
+		nameNode := param.ChildByFieldName("pattern")
+		name := analyzer.GetNodeText(nameNode, source)
+		assignment := &types.Assignment{
+			Target:     "this." + name,
+			TargetType: "property",
+			Source:     name,
+			Line:       int(param.StartPoint().Row) + 1,
+			Column:     int(param.StartPoint().Column),
+			Scope:      a.scopeFor(param, source, scope),
+			Operator:   "=",
+			Keys:       []string{"this", name},
+		}
+		// A default value is what the property gets when the argument is omitted
+		assignment.IsTainted, assignment.TaintSource = a.isExpressionTainted(param.ChildByFieldName("value"), source)
+		assignments = append(assignments, assignment)
+	}
+
 	return assignments, nil
 }
 
+// scopeFor returns scope, or the scope enclosing node when scope is ""
+func (a *TypeScriptAnalyzer) scopeFor(node *sitter.Node, source []byte, scope string) string {
+	if scope != "" {
+		return scope
+	}
+	return analyzer.ScopeIDForNode(node, source, "typescript")
+}
+
+// parameterProperties returns the constructor parameters declaring a
+// property: the ones with an accessibility modifier or readonly
+func (a *TypeScriptAnalyzer) parameterProperties(root *sitter.Node, source []byte) []*sitter.Node {
+	var params []*sitter.Node
+	for _, method := range analyzer.FindNodesOfType(root, "method_definition") {
+		name := method.ChildByFieldName("name")
+		paramsNode := method.ChildByFieldName("parameters")
+		if name == nil || paramsNode == nil || analyzer.GetNodeText(name, source) != "constructor" {
+			continue
+		}
+		for i := 0; i < int(paramsNode.NamedChildCount()); i++ {
+			param := paramsNode.NamedChild(i)
+			if param.Type() != "required_parameter" && param.Type() != "optional_parameter" {
+				continue
+			}
+			pattern := param.ChildByFieldName("pattern")
+			if pattern == nil || pattern.Type() != "identifier" {
+				continue
+			}
+			for j := 0; j < int(param.ChildCount()); j++ {
+				if kind := param.Child(j).Type(); kind == "accessibility_modifier" || kind == "readonly" {
+					params = append(params, param)
+					break
+				}
+			}
+		}
+	}
+	return params
+}
+
 func (a *TypeScriptAnalyzer) isExpressionTainted(node *sitter.Node, source []byte) (bool, string) {
 	if node == nil {
 		return false, ""
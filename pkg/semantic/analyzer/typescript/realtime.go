@@ -0,0 +1,13 @@
+package typescript
+
+import (
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/javascript"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// FindRealtimeHandlers returns the WebSocket and EventSource message handlers
+// of a file (see javascript.FindRealtimeHandlers)
+func (a *TypeScriptAnalyzer) FindRealtimeHandlers(root *sitter.Node, source []byte) []*types.RealtimeHandler {
+	return javascript.FindRealtimeHandlers(root, source)
+}
@@ -0,0 +1,70 @@
+package semantic
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestCLIArgSources(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "cli.php", `<?php
+$first = $argv[1];
+$count = $argc;
+$rest = array_slice($_SERVER['argv'], 2);
+function parse($argv) { return $argv[0]; }
+function main() { global $argv; return $argv[2]; }
+function local() { $argv = ['a']; return array_shift($argv); }
+$arrow = fn() => $argv[3];
+$closure = function () use ($argv) { return $argv[4]; };
+class Command { function run() { return $argv[5]; } }
+`)
+
+	tracer := New(nil)
+	result, err := tracer.TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, src := range result.Sources {
+		if src.SourceType == types.SourceCLIArg {
+			got = append(got, src.Snippet)
+		}
+	}
+	want := []string{
+		"$argv[1]",
+		"$argc",
+		"$_SERVER['argv']",
+		"$argv[2]", // Declared global
+		"$argv[3]", // Captured by an arrow function
+		"$argv[4]", // Captured by a closure
+		"array_slice($_SERVER['argv'], 2)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CLI sources = %q, want %q", got, want)
+	}
+
+	path := filepath.Join(dir, "cli.php")
+	tests := []struct {
+		expr string
+		line int
+		cli  bool
+	}{
+		{"$argv[1]", 2, true},
+		{"$_SERVER['argv'][1]", 5, true},
+		{"$argv[0]", 5, false}, // Parameter of parse()
+		{"array_shift($argv)", 7, false},
+		{"$argv[5]", 10, false}, // Local of a method
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			info := tracer.identifySource(tt.expr, path, tt.line)
+			if cli := info != nil && info.Type == types.SourceCLIArg; cli != tt.cli {
+				t.Errorf("identifySource(%q, line %d) = %+v, want CLI argument %v", tt.expr, tt.line, info, tt.cli)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package semantic
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+// closureParamsPattern matches the parameter list of an inline closure or arrow function
+var closureParamsPattern = regexp.MustCompile(`^(?:static\s+)?(?:function|fn)\s*&?\s*\(([^)]*)\)`)
+
+// paramVarPattern matches a parameter variable in a parameter list
+var paramVarPattern = regexp.MustCompile(`\$\w+`)
+
+// arrayBuiltinEdge returns how name reaches the variable an assignment
+// targets. It is false when the assigned value is a call to a modeled array
+// builtin that name only reaches through arguments not carried into the
+// result, like the column of array_column($rows, $col). A string offset or
+// substring of name still flows, truncated.
+func arrayBuiltinEdge(assign *types.Assignment, calls []*types.CallSite, name string) (string, bool) {
+	call := assignedBuiltinCall(assign, calls)
+	if call == nil {
+		if operand, truncation := phpPatterns.PartialRead(assign.Source); len(truncation) > 0 && containsSourceName(operand, name) {
+			return fmt.Sprintf("truncated (%s) and assigned to", strings.Join(truncation, ", ")), true
+		}
+		return "assigned to", true
+	}
+	builtin, _ := phpPatterns.LookupArrayBuiltin(call.FunctionName)
+
+	var keys, values bool
+	for i, arg := range call.Arguments {
+		if containsSourceName(arg.Value, name) {
+			k, v := builtin.ResultParts(i)
+			keys, values = keys || k, values || v
+		}
+	}
+	switch {
+	case keys && values:
+		return fmt.Sprintf("keys and values of %s()", call.FunctionName), true
+	case keys:
+		return fmt.Sprintf("keys of %s()", call.FunctionName), true
+	case values:
+		return fmt.Sprintf("values of %s()", call.FunctionName), true
+	}
+	return "", false
+}
+
+// assignedBuiltinCall returns the call to a modeled array builtin an
+// assignment's whole value consists of, or nil
+func assignedBuiltinCall(assign *types.Assignment, calls []*types.CallSite) *types.CallSite {
+	m := wrapperCallPattern.FindStringSubmatch(strings.TrimSpace(assign.Source))
+	if m == nil || !strings.HasSuffix(strings.TrimSpace(assign.Source), ")") {
+		return nil
+	}
+	if _, ok := phpPatterns.LookupArrayBuiltin(m[1]); !ok {
+		return nil
+	}
+	for _, call := range calls {
+		if call.Line == assign.Line && strings.EqualFold(strings.TrimPrefix(call.FunctionName, "\\"), m[1]) {
+			return call
+		}
+	}
+	return nil
+}
+
+// builtinTarget is a variable a modeled array builtin writes tainted data into
+type builtinTarget struct {
+	name        string
+	description string
+}
+
+// arrayBuiltinTargets returns the variables a call to a modeled array builtin
+// writes name into: the array it modifies by reference (array_splice) and the
+// parameters of an inline comparison callback (usort and friends)
+func arrayBuiltinTargets(call *types.CallSite, name string) []builtinTarget {
+	builtin, ok := phpPatterns.LookupArrayBuiltin(call.FunctionName)
+	if !ok {
+		return nil
+	}
+
+	var targets []builtinTarget
+	for i, arg := range call.Arguments {
+		if !containsSourceName(arg.Value, name) {
+			continue
+		}
+		if builtin.WritesRef(i) && builtin.RefArg < len(call.Arguments) {
+			if ref := strings.TrimSpace(call.Arguments[builtin.RefArg].Value); strings.HasPrefix(ref, "$") {
+				targets = append(targets, builtinTarget{ref, fmt.Sprintf("written by %s()", call.FunctionName)})
+			}
+		}
+		if i == builtin.CallbackFrom && builtin.Callback >= 0 && builtin.Callback < len(call.Arguments) {
+			m := closureParamsPattern.FindStringSubmatch(strings.TrimSpace(call.Arguments[builtin.Callback].Value))
+			if m == nil {
+				continue // Named callbacks are not followed
+			}
+			part := "elements"
+			if builtin.CallbackKeys {
+				part = "keys"
+			}
+			for _, param := range paramVarPattern.FindAllString(m[1], -1) {
+				targets = append(targets, builtinTarget{param, fmt.Sprintf("%s passed to %s() callback", part, call.FunctionName)})
+			}
+		}
+	}
+	return targets
+}
+
+// traceArrayBuiltin adds a node for each variable a modeled array builtin
+// writes from's value into and continues tracing it with next
+func (t *Tracer) traceArrayBuiltin(from *types.FlowNode, call *types.CallSite, flowMap *types.FlowMap, next func(node *types.FlowNode)) {
+	for _, target := range arrayBuiltinTargets(call, from.Name) {
+		snippet := fmt.Sprintf("%s(%s)", call.FunctionName, joinArgs(call))
+		node := types.FlowNode{
+			ID:         types.NodeID(from.FilePath, call.Line, call.Column, string(types.NodeVariable), target.name+" = "+snippet),
+			Type:       types.NodeVariable,
+			Language:   from.Language,
+			FilePath:   from.FilePath,
+			Line:       call.Line,
+			Column:     call.Column,
+			Name:       target.name,
+			Snippet:    snippet,
+			SourceType: from.SourceType,
+		}
+		if !flowMap.AddNode(node) {
+			continue
+		}
+		flowMap.AddEdge(types.FlowEdge{
+			From:        from.ID,
+			To:          node.ID,
+			Type:        types.EdgeAssignment,
+			Description: target.description,
+		})
+		t.stats.FlowsTraced++
+		t.descend(func() { next(&node) })
+	}
+}
+
+// joinArgs returns the argument list of a call as written
+func joinArgs(call *types.CallSite) string {
+	args := make([]string, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		args[i] = arg.Value
+	}
+	return strings.Join(args, ", ")
+}
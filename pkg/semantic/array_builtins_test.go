@@ -0,0 +1,60 @@
+package semantic
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestArrayBuiltinFlows(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+$m = array_column($rows, $_GET['col']);
+$c = array_combine($_GET['k'], $vals);
+$arr = [1, 2, 3];
+array_splice($arr, 0, 1, $_POST['x']);
+usort($_COOKIE['list'], function ($a, $b) {
+    return strcmp($a, $b);
+});
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]string)
+	for _, node := range result.FlowMap.AllNodes {
+		if node.FilePath == filepath.Join(dir, "index.php") {
+			names[node.ID] = node.Name
+		}
+	}
+	descriptions := make(map[string]string)
+	for _, edge := range result.FlowMap.AllEdges {
+		if name, ok := names[edge.To]; ok {
+			descriptions[name] = edge.Description
+		}
+	}
+
+	tests := []struct {
+		name        string
+		description string
+		flows       bool
+	}{
+		{"$m", "", false},
+		{"$c", "keys of array_combine()", true},
+		{"$arr", "written by array_splice()", true},
+		{"$a", "elements passed to usort() callback", true},
+		{"$b", "elements passed to usort() callback", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := descriptions[tt.name]
+			if ok != tt.flows {
+				t.Fatalf("flow to %s = %v, want %v", tt.name, ok, tt.flows)
+			}
+			if got != tt.description {
+				t.Errorf("edge description = %q, want %q", got, tt.description)
+			}
+		})
+	}
+}
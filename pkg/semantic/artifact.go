@@ -0,0 +1,405 @@
+package semantic
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// Artifact describes a container image or deployment tarball scanned by
+// TraceArtifact
+type Artifact struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"` // "image" or "tarball"
+
+	// Image layers extracted and skipped as operating system layers
+	Layers        []string `json:"layers,omitempty"`
+	SkippedLayers []string `json:"skipped_layers,omitempty"`
+}
+
+// Artifact kinds
+const (
+	ArtifactImage   = "image"
+	ArtifactTarball = "tarball"
+)
+
+// Files only an operating system layer writes: package manager databases.
+// Layers holding one are the base image or system package installs.
+var osLayerMarkers = []string{
+	"var/lib/dpkg/status",
+	"lib/apk/db/installed",
+	"var/lib/rpm/",
+	"usr/lib/sysimage/rpm/",
+	"var/lib/pacman/local/",
+}
+
+// maxExtractedBytes caps the bytes TraceArtifact writes extracting one
+// artifact, so a compressed archive cannot fill the disk
+const maxExtractedBytes = 4 << 30
+
+// errArtifactTooLarge reports an artifact extracting to more than
+// maxExtractedBytes
+var errArtifactTooLarge = fmt.Errorf("artifact extracts to more than %d bytes", int64(maxExtractedBytes))
+
+// imageManifest is one image of a `docker save` manifest.json
+type imageManifest struct {
+	Layers []string `json:"Layers"`
+}
+
+// TraceArtifact scans a container image saved with `docker save` (or an OCI
+// layout tarball with a manifest.json) or a deployment tarball (.tar,
+// .tar.gz, .tgz) when the source repository is not available. The code is
+// extracted to a temporary directory, removed afterwards: for images the
+// layers in order with their whiteouts applied, skipping layers holding an
+// operating system package database. Paths in the result (files, sources,
+// flow nodes, entry points, warnings) are the paths inside the artifact,
+// e.g. /var/www/html/index.php; query the result rather than the tracer,
+// whose state refers to the removed extraction. Artifacts extracting to more
+// than 4 GiB are refused.
+func (t *Tracer) TraceArtifact(artifactPath string) (*TraceResult, error) {
+	dir, err := os.MkdirTemp("", "inputtracer-artifact-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	artifact, err := extractArtifact(artifactPath, dir)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Join(dir, "root")
+	result, err := t.TraceDirectory(root)
+	if err != nil {
+		return nil, err
+	}
+	result.Artifact = artifact
+	result.relocatePaths(root, "")
+	// The extracted files are gone: nothing to re-trace or read context from
+	result.tracer = nil
+	result.parserService = nil
+	return result, nil
+}
+
+// extractArtifact extracts the application code of a container image or
+// deployment tarball (see TraceArtifact) into dir/root
+func extractArtifact(artifactPath, dir string) (*Artifact, error) {
+	staging := filepath.Join(dir, "staging")
+	root := filepath.Join(dir, "root")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	budget := int64(maxExtractedBytes)
+	manifestData, err := readTarFile(artifactPath, "manifest.json")
+	if err != nil {
+		return nil, fmt.Errorf("reading artifact %s: %w", artifactPath, err)
+	}
+	// A deployment tarball may ship its own manifest.json (a web app
+	// manifest), so only a list of images with layers marks an image
+	var manifests []imageManifest
+	if manifestData == nil || json.Unmarshal(manifestData, &manifests) != nil || len(manifests) == 0 || len(manifests[0].Layers) == 0 {
+		if err := extractTar(artifactPath, root, false, &budget); err != nil {
+			return nil, fmt.Errorf("extracting %s: %w", artifactPath, err)
+		}
+		return &Artifact{Path: artifactPath, Kind: ArtifactTarball}, nil
+	}
+	if err := extractTar(artifactPath, staging, false, &budget); err != nil {
+		return nil, fmt.Errorf("extracting %s: %w", artifactPath, err)
+	}
+	artifact := &Artifact{Path: artifactPath, Kind: ArtifactImage}
+	for _, layer := range manifests[0].Layers {
+		layerPath := filepath.Join(staging, filepath.FromSlash(path.Clean("/"+layer)))
+		isOS, err := isOSLayer(layerPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading layer %s: %w", layer, err)
+		}
+		if isOS {
+			artifact.SkippedLayers = append(artifact.SkippedLayers, layer)
+			continue
+		}
+		if err := extractTar(layerPath, root, true, &budget); err != nil {
+			return nil, fmt.Errorf("extracting layer %s: %w", layer, err)
+		}
+		artifact.Layers = append(artifact.Layers, layer)
+	}
+	return artifact, nil
+}
+
+// openTar opens a tar archive, gzip-compressed or not
+func openTar(archivePath string) (*tar.Reader, io.Closer, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	br := bufio.NewReader(f)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return tar.NewReader(zr), f, nil
+	}
+	return tar.NewReader(br), f, nil
+}
+
+// readTarFile returns the content of a top-level file of an archive, or nil
+func readTarFile(archivePath, name string) ([]byte, error) {
+	tr, closer, err := openTar(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Clean(hdr.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// isOSLayer reports whether an image layer holds an operating system
+// package database
+func isOSLayer(layerPath string) (bool, error) {
+	tr, closer, err := openTar(layerPath)
+	if err != nil {
+		return false, err
+	}
+	defer closer.Close()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		for _, marker := range osLayerMarkers {
+			if name == strings.TrimSuffix(marker, "/") || strings.HasPrefix(name, marker) {
+				return true, nil
+			}
+		}
+	}
+}
+
+// extractTar extracts the directories and regular files of an archive into
+// dir, applying the overlay whiteouts of an image layer (.wh.name deletes
+// name, .wh..wh..opq empties the directory). Links, devices and entries
+// escaping dir are skipped. budget is the bytes left to write; extraction
+// fails with errArtifactTooLarge past it.
+func extractTar(archivePath, dir string, layer bool, budget *int64) error {
+	tr, closer, err := openTar(archivePath)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Cleaning a rooted name drops any ../ escaping dir
+		name := strings.TrimPrefix(path.Clean("/"+hdr.Name), "/")
+		if name == "" {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		parent, base := filepath.Split(target)
+
+		switch {
+		case layer && base == ".wh..wh..opq":
+			entries, _ := os.ReadDir(parent)
+			for _, entry := range entries {
+				os.RemoveAll(filepath.Join(parent, entry.Name()))
+			}
+		case layer && strings.HasPrefix(base, ".wh."):
+			os.RemoveAll(filepath.Join(parent, strings.TrimPrefix(base, ".wh.")))
+		case hdr.Typeflag == tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case hdr.Typeflag == tar.TypeReg:
+			if err := writeTarFile(tr, target, budget); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeTarFile writes the current archive entry to target, replacing it,
+// within the bytes left in budget
+func writeTarFile(r io.Reader, target string, budget *int64) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	os.RemoveAll(target)
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, io.LimitReader(r, *budget+1))
+	*budget -= n
+	if err == nil && *budget < 0 {
+		err = errArtifactTooLarge
+	}
+	return errors.Join(err, f.Close())
+}
+
+// relocatePaths replaces the from prefix of the file paths and node IDs of
+// the result with to, e.g. an extraction directory with the artifact root
+func (r *TraceResult) relocatePaths(from, to string) {
+	move := func(s string) string {
+		if strings.HasPrefix(s, from) {
+			return to + s[len(from):]
+		}
+		return s
+	}
+	moveNode := func(node *types.FlowNode) {
+		node.ID = move(node.ID)
+		node.FilePath = move(node.FilePath)
+	}
+	moveNodes := func(nodes []types.FlowNode) {
+		for i := range nodes {
+			moveNode(&nodes[i])
+		}
+	}
+	moveSymbols := func(st *types.SymbolTable) {
+		if st == nil {
+			return
+		}
+		st.FilePath = move(st.FilePath)
+		for _, class := range st.Classes {
+			class.FilePath = move(class.FilePath)
+		}
+		for _, fn := range st.Functions {
+			fn.FilePath = move(fn.FilePath)
+		}
+	}
+
+	for _, src := range r.Sources {
+		moveNode(src)
+	}
+	for _, src := range r.SkippedSources {
+		moveNode(src)
+	}
+	for _, ep := range r.EntryPoints {
+		ep.FilePath = move(ep.FilePath)
+		for i := range ep.ReachableFiles {
+			ep.ReachableFiles[i] = move(ep.ReachableFiles[i])
+		}
+	}
+	for i := range r.Warnings {
+		r.Warnings[i].FilePath = move(r.Warnings[i].FilePath)
+	}
+	if r.Frameworks != nil {
+		for _, fw := range r.Frameworks.Frameworks {
+			for i := range fw.Files {
+				fw.Files[i] = move(fw.Files[i])
+			}
+		}
+	}
+
+	files := make(map[string]*FileInfo, len(r.Files))
+	for filePath, fileInfo := range r.Files {
+		fileInfo.Path = move(fileInfo.Path)
+		for _, src := range fileInfo.Sources {
+			moveNode(src)
+		}
+		for _, assign := range fileInfo.Assignments {
+			assign.FilePath = move(assign.FilePath)
+		}
+		for _, call := range fileInfo.Calls {
+			call.FilePath = move(call.FilePath)
+		}
+		for _, b := range fileInfo.TemplateBindings {
+			b.FilePath, b.IncludePath = move(b.FilePath), move(b.IncludePath)
+		}
+		for _, w := range fileInfo.SourceWrappers {
+			w.FilePath = move(w.FilePath)
+		}
+		for _, s := range fileInfo.ReturnSummaries {
+			s.FilePath = move(s.FilePath)
+		}
+		for _, v := range fileInfo.Validators {
+			v.FilePath = move(v.FilePath)
+		}
+		for _, a := range fileInfo.RequestAttributes {
+			a.FilePath = move(a.FilePath)
+		}
+		for _, h := range fileInfo.RealtimeHandlers {
+			h.FilePath = move(h.FilePath)
+		}
+		for _, l := range fileInfo.RegisterLoops {
+			l.FilePath = move(l.FilePath)
+		}
+		for _, d := range fileInfo.RouteDispatches {
+			d.FilePath = move(d.FilePath)
+		}
+		for _, route := range fileInfo.AttributeRoutes {
+			route.FilePath = move(route.FilePath)
+		}
+		files[move(filePath)] = fileInfo
+	}
+	r.Files = files
+
+	// Symbol tables share definitions; moving a path twice leaves it as is
+	symbolTables := make(map[string]*types.SymbolTable, len(r.SymbolTable))
+	for filePath, st := range r.SymbolTable {
+		moveSymbols(st)
+		symbolTables[move(filePath)] = st
+	}
+	r.SymbolTable = symbolTables
+	for _, fileInfo := range r.Files {
+		moveSymbols(fileInfo.SymbolTable)
+	}
+	moveSymbols(r.GlobalSymbolTable)
+
+	if r.FlowMap == nil {
+		return
+	}
+	r.FlowMap.Target.FilePath = move(r.FlowMap.Target.FilePath)
+	moveNodes(r.FlowMap.Sources)
+	moveNodes(r.FlowMap.Carriers)
+	moveNodes(r.FlowMap.AllNodes)
+	moveNodes(r.FlowMap.Usages)
+	for i := range r.FlowMap.AllEdges {
+		r.FlowMap.AllEdges[i].From = move(r.FlowMap.AllEdges[i].From)
+		r.FlowMap.AllEdges[i].To = move(r.FlowMap.AllEdges[i].To)
+		r.FlowMap.AllEdges[i].FilePath = move(r.FlowMap.AllEdges[i].FilePath)
+	}
+	for _, p := range r.FlowMap.Paths {
+		for i := range p.Steps {
+			moveNode(&p.Steps[i].Node)
+			if edge := p.Steps[i].Edge; edge != nil {
+				edge.From, edge.To = move(edge.From), move(edge.To)
+			}
+		}
+		if p.Source != nil {
+			moveNode(p.Source)
+		}
+		if p.Target != nil {
+			moveNode(p.Target)
+		}
+	}
+	r.FlowMap.Reindex()
+}
@@ -0,0 +1,188 @@
+package semantic
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// tarBytes returns a tar archive of the given files, gzip-compressed if asked
+func tarBytes(t *testing.T, files map[string]string, compress bool) []byte {
+	t.Helper()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !compress {
+		return buf.Bytes()
+	}
+	var zbuf bytes.Buffer
+	zw := gzip.NewWriter(&zbuf)
+	zw.Write(buf.Bytes())
+	zw.Close()
+	return zbuf.Bytes()
+}
+
+func TestTraceArtifact(t *testing.T) {
+	dir := t.TempDir()
+	osLayer := tarBytes(t, map[string]string{
+		"var/lib/dpkg/status":         "Package: libc6\n",
+		"usr/share/php/PEAR/util.php": "<?php $x = $_GET['pear'];\n",
+	}, false)
+	appLayer := tarBytes(t, map[string]string{
+		"var/www/html/index.php": "<?php\n$id = $_GET['id'];\n",
+		"var/www/html/old.php":   "<?php\n$q = $_POST['q'];\n",
+	}, true)
+	cleanupLayer := tarBytes(t, map[string]string{
+		"var/www/html/.wh.old.php": "",
+	}, false)
+	image := filepath.Join(dir, "image.tar")
+	if err := os.WriteFile(image, tarBytes(t, map[string]string{
+		"manifest.json":  `[{"Config":"config.json","Layers":["base/layer.tar","app/layer.tar","fix/layer.tar"]}]`,
+		"config.json":    `{}`,
+		"base/layer.tar": string(osLayer),
+		"app/layer.tar":  string(appLayer),
+		"fix/layer.tar":  string(cleanupLayer),
+	}, false), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tarball := filepath.Join(dir, "app.tgz")
+	if err := os.WriteFile(tarball, tarBytes(t, map[string]string{
+		"manifest.json":   `{"name": "web app"}`,
+		"srv/app/run.php": "<?php\n$home = getenv('HOME');\n",
+		"../escape.php":   "<?php\n$e = $_COOKIE['e'];\n",
+	}, true), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		artifact string
+		kind     string
+		skipped  []string
+		sources  []string
+	}{
+		{image, ArtifactImage, []string{"base/layer.tar"}, []string{"/var/www/html/index.php:2"}},
+		{tarball, ArtifactTarball, nil, []string{"/escape.php:2", "/srv/app/run.php:2"}},
+	}
+	for _, tt := range tests {
+		result, err := New(nil).TraceArtifact(tt.artifact)
+		if err != nil {
+			t.Fatalf("TraceArtifact(%s): %v", tt.artifact, err)
+		}
+		if result.Artifact.Kind != tt.kind || !reflect.DeepEqual(result.Artifact.SkippedLayers, tt.skipped) {
+			t.Errorf("%s: artifact = %+v, want kind %s skipping %v", tt.artifact, result.Artifact, tt.kind, tt.skipped)
+		}
+		var sources []string
+		for _, src := range result.Sources {
+			sources = append(sources, fmt.Sprintf("%s:%d", src.FilePath, src.Line))
+			if result.Files[src.FilePath] == nil {
+				t.Errorf("%s: no file info under %s", tt.artifact, src.FilePath)
+			}
+		}
+		sort.Strings(sources)
+		if !reflect.DeepEqual(sources, tt.sources) {
+			t.Errorf("%s: sources = %v, want %v", tt.artifact, sources, tt.sources)
+		}
+	}
+}
+
+func TestExtractTarLimits(t *testing.T) {
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "app.tar")
+	// The whiteout entry sorts after run.php; only image layers apply it
+	if err := os.WriteFile(archive, tarBytes(t, map[string]string{
+		"app/run.php":           "<?php\n$home = getenv('HOME');\n",
+		"app/zz/../.wh.run.php": "",
+	}, false), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		layer    bool
+		budget   int64
+		kept     bool
+		tooLarge bool
+	}{
+		{false, maxExtractedBytes, true, false},
+		{true, maxExtractedBytes, false, false},
+		{false, 10, false, true},
+	}
+	for i, tt := range tests {
+		root := filepath.Join(dir, fmt.Sprint(i))
+		budget := tt.budget
+		err := extractTar(archive, root, tt.layer, &budget)
+		if errors.Is(err, errArtifactTooLarge) != tt.tooLarge {
+			t.Errorf("layer=%v budget=%d: err = %v", tt.layer, tt.budget, err)
+			continue
+		}
+		if tt.tooLarge {
+			continue
+		}
+		_, statErr := os.Stat(filepath.Join(root, "app", "run.php"))
+		if kept := statErr == nil; kept != tt.kept {
+			t.Errorf("layer=%v: run.php kept = %v, want %v", tt.layer, kept, tt.kept)
+		}
+	}
+}
+
+func TestRelocatePaths(t *testing.T) {
+	from := "/tmp/extract/root"
+	file := from + "/var/www/index.php"
+	node := types.FlowNode{ID: file + ":2:0:source", FilePath: file, Line: 2}
+	fn := &types.FunctionDef{Name: "run", FilePath: file}
+	st := &types.SymbolTable{FilePath: file, Functions: map[string]*types.FunctionDef{"run": fn}}
+	flowMap := types.NewFlowMap()
+	flowMap.AddSource(node)
+	result := &TraceResult{
+		Sources:     []*types.FlowNode{&node},
+		FlowMap:     flowMap,
+		Files:       map[string]*FileInfo{file: {Path: file, SymbolTable: st, Calls: []*types.CallSite{{FilePath: file}}}},
+		SymbolTable: map[string]*types.SymbolTable{file: st},
+		Frameworks:  &FrameworkReport{Frameworks: []*DetectedFramework{{Name: "laravel", Files: []string{file}}}},
+	}
+	result.relocatePaths(from, "")
+
+	want := "/var/www/index.php"
+	got := []string{
+		result.Sources[0].FilePath,
+		result.Files[want].Path,
+		result.Files[want].Calls[0].FilePath,
+		result.SymbolTable[want].FilePath,
+		fn.FilePath,
+		result.Frameworks.Frameworks[0].Files[0],
+		result.FlowMap.AllNodes[0].FilePath,
+	}
+	for i, path := range got {
+		if path != want {
+			t.Errorf("path %d = %q, want %q", i, path, want)
+		}
+	}
+	if !result.FlowMap.HasNode(want+":2:0:source") || result.FlowMap.HasNode(file+":2:0:source") {
+		t.Error("flow map node index not relocated")
+	}
+}
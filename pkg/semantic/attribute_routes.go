@@ -0,0 +1,80 @@
+package semantic
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// attributeRouteFinder is implemented by analyzers that read routes declared
+// by attributes on controller methods (Symfony #[Route])
+type attributeRouteFinder interface {
+	FindAttributeRoutes(root *sitter.Node, source []byte) []*types.AttributeRoute
+}
+
+// folioRoute returns the Laravel Folio route of a PHP page under rootPath,
+// or nil when the file is not a page
+func folioRoute(rootPath string, fileInfo *FileInfo) *types.AttributeRoute {
+	if fileInfo.Language != "php" {
+		return nil
+	}
+	rel, err := filepath.Rel(rootPath, fileInfo.Path)
+	if err != nil {
+		return nil
+	}
+	route, ok := phpPatterns.FolioRoute(filepath.ToSlash(rel))
+	if !ok {
+		return nil
+	}
+	return &types.AttributeRoute{
+		Framework: "folio",
+		Path:      route,
+		Methods:   phpPatterns.FolioMethods,
+		FilePath:  fileInfo.Path,
+		Line:      1,
+	}
+}
+
+// attributeRouteEntryPoints returns an entry point for every route declared
+// by an attribute whose handler is known, and for every Folio page; callers
+// hold t.mu
+func (t *Tracer) attributeRouteEntryPoints(rootPath string, filePaths []string) []*EntryPoint {
+	var eps []*EntryPoint
+	for _, filePath := range filePaths {
+		fileInfo := t.files[filePath]
+		routes := fileInfo.AttributeRoutes
+		if page := folioRoute(rootPath, fileInfo); page != nil {
+			routes = append([]*types.AttributeRoute{page}, routes...)
+		}
+		for _, r := range routes {
+			if r.Function != "" && t.lookupFunction(r.Function) == nil {
+				continue
+			}
+			ep := &EntryPoint{
+				Kind:     EntryPointFramework,
+				FilePath: filePath,
+				Function: r.Function,
+				Line:     r.Line,
+				Route:    attributeRoute(r),
+				Methods:  r.Methods,
+			}
+			if strings.Contains(r.Path, "{") {
+				ep.Inputs = []types.SourceType{types.SourceHTTPPath}
+			}
+			eps = append(eps, ep)
+		}
+	}
+	return eps
+}
+
+// attributeRoute names a route by its methods and path, e.g.
+// "GET|HEAD /blog/{slug}", or by its path alone when any method matches
+func attributeRoute(r *types.AttributeRoute) string {
+	if len(r.Methods) == 0 {
+		return r.Path
+	}
+	return strings.Join(r.Methods, "|") + " " + r.Path
+}
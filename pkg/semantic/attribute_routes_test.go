@@ -0,0 +1,106 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestAttributeRoutes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "resources", "views", "pages", "users"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "BlogController.php", `<?php
+namespace App\Controller;
+
+use Symfony\Component\Routing\Attribute\Route;
+
+#[Route('/blog', name: 'blog_')]
+class BlogController {
+    #[Route('/{slug}', name: 'show', methods: ['GET', 'HEAD'])]
+    public function show(string $slug) {
+        echo $slug;
+    }
+
+    #[Route(path: '/new', methods: 'post')]
+    public function create() {
+        echo $_POST['title'];
+    }
+
+    public function helper() {}
+}
+`)
+	writeFile(t, dir, "HealthAction.php", `<?php
+namespace App\Controller;
+
+#[\Symfony\Component\Routing\Annotation\Route('/health')]
+class HealthAction {
+    public function __invoke() { return 'ok'; }
+}
+`)
+	writeFile(t, dir, "resources/views/pages/users/[User].blade.php", `<?php echo $user->name; ?>
+<div>{{ $user->email }}</div>
+`)
+	writeFile(t, dir, "resources/views/pages/index.blade.php", `<h1>Welcome</h1>
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var routes []string
+	byRoute := make(map[string]*EntryPoint)
+	for _, ep := range result.EntryPoints {
+		if strings.Contains(ep.FilePath, filepath.Join("resources", "views", "pages")) && ep.Kind != EntryPointFramework {
+			t.Errorf("Folio page %s is a %s entry point routed %s", filepath.Base(ep.FilePath), ep.Kind, ep.Route)
+		}
+		if ep.Kind != EntryPointFramework {
+			continue
+		}
+		routes = append(routes, ep.Route)
+		byRoute[ep.Route] = ep
+	}
+	sort.Strings(routes)
+	want := []string{
+		"/health",
+		"GET|HEAD /",
+		"GET|HEAD /blog/{slug}",
+		"GET|HEAD /users/{user}",
+		"POST /blog/new",
+	}
+	if strings.Join(routes, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("framework routes = %v, want %v", routes, want)
+	}
+
+	show := byRoute["GET|HEAD /blog/{slug}"]
+	if show.Function != "BlogController::show" || show.Line != 8 || strings.Join(show.Methods, ",") != "GET,HEAD" {
+		t.Errorf("show route = %+v", show)
+	}
+	if !hasSourceType(show.Inputs, types.SourceHTTPPath) {
+		t.Errorf("show route inputs = %v, want the path parameter", show.Inputs)
+	}
+	if !hasSourceType(byRoute["POST /blog/new"].Inputs, types.SourceHTTPPost) {
+		t.Errorf("create route inputs = %v, want POST input", byRoute["POST /blog/new"].Inputs)
+	}
+	if fn := byRoute["/health"].Function; fn != "HealthAction::__invoke" {
+		t.Errorf("invokable route handler = %s, want HealthAction::__invoke", fn)
+	}
+	if page := byRoute["GET|HEAD /users/{user}"]; page.Function != "" || filepath.Base(page.FilePath) != "[User].blade.php" {
+		t.Errorf("Folio page route = %+v", page)
+	}
+}
+
+func hasSourceType(inputs []types.SourceType, st types.SourceType) bool {
+	for _, in := range inputs {
+		if in == st {
+			return true
+		}
+	}
+	return false
+}
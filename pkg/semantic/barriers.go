@@ -0,0 +1,219 @@
+package semantic
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// Trust levels of a barrier rule
+const (
+	BarrierTrusted   = "trusted"
+	BarrierUntrusted = "untrusted"
+)
+
+// BarrierRule declares a trust boundary the flow engine enforces with a
+// barrier node. Input stops at a trusted boundary: what a trusted function
+// returns carries no input, and input passed into a trusted function or into
+// trusted files goes no further. Input crossing an untrusted boundary keeps
+// flowing through the barrier node, which records the crossing; an untrusted
+// boundary wins over a trusted one on the same crossing.
+type BarrierRule struct {
+	Name     string   `json:"name,omitempty"`     // Shown on barrier nodes; defaults to the function or files
+	Function string   `json:"function,omitempty"` // Function or Class::method; instance calls match on the method
+	Files    []string `json:"files,omitempty"`    // File globs relative to the scanned directory (** allowed)
+	Trust    string   `json:"trust"`              // trusted or untrusted
+}
+
+// validate checks that the rule has criteria and a known trust level
+func (r BarrierRule) validate() error {
+	if r.Function == "" && len(r.Files) == 0 {
+		return fmt.Errorf("function or files is required")
+	}
+	if r.Trust != BarrierTrusted && r.Trust != BarrierUntrusted {
+		return fmt.Errorf("unknown trust %q", r.Trust)
+	}
+	for _, pattern := range r.Files {
+		if _, err := doubleStarMatch(pattern, ""); err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// label names the boundary on barrier nodes
+func (r BarrierRule) label() string {
+	switch {
+	case r.Name != "":
+		return r.Name
+	case r.Function != "":
+		return r.Function + "()"
+	}
+	return strings.Join(r.Files, ", ")
+}
+
+// matchesFunction reports whether the rule names the function called by call
+func (r BarrierRule) matchesFunction(call *types.CallSite) bool {
+	if r.Function == "" {
+		return false
+	}
+	class, method, isMethod := strings.Cut(r.Function, "::")
+	if !isMethod {
+		return strings.EqualFold(strings.TrimPrefix(call.FunctionName, "\\"), r.Function) ||
+			(call.ClassName != "" && strings.EqualFold(call.MethodName, r.Function))
+	}
+	if !strings.EqualFold(call.MethodName, method) {
+		return false
+	}
+	// The class of an instance call's receiver is not resolved
+	return !call.IsStatic || strings.EqualFold(strings.TrimPrefix(call.ClassName, "\\"), class)
+}
+
+// matchesFile reports whether the file rel (relative to the scanned
+// directory) is within the rule's files
+func (r BarrierRule) matchesFile(rel string) bool {
+	for _, pattern := range r.Files {
+		if ok, _ := doubleStarMatch(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// trustedFunction reports whether a trusted barrier names the function, so
+// that its return value is not promoted to a source
+func (t *Tracer) trustedFunction(name string) bool {
+	if t.rules == nil {
+		return false
+	}
+	for _, rule := range t.rules.Barriers {
+		if rule.Trust != BarrierTrusted || rule.Function == "" {
+			continue
+		}
+		_, method, _ := strings.Cut(rule.Function, "::")
+		if strings.EqualFold(name, rule.Function) || strings.EqualFold(name, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// returnBarrier returns the trusted barrier whose function call is the whole
+// value of assign, or nil
+func (t *Tracer) returnBarrier(assign *types.Assignment, calls []*types.CallSite) *BarrierRule {
+	if t.rules == nil || len(t.rules.Barriers) == 0 {
+		return nil
+	}
+	callee := outermostCallee(assign.Source)
+	if callee == "" {
+		return nil
+	}
+	for _, call := range calls {
+		if call.Line != assign.Line || !strings.EqualFold(strings.TrimPrefix(call.FunctionName, "\\"), callee) {
+			continue
+		}
+		for i := range t.rules.Barriers {
+			rule := &t.rules.Barriers[i]
+			if rule.Trust == BarrierTrusted && rule.matchesFunction(call) {
+				return rule
+			}
+		}
+	}
+	return nil
+}
+
+// outermostCallee returns the callee of the call that is the whole of value,
+// e.g. "AuthService::currentUser" for "AuthService::currentUser($_GET['a'])",
+// or "" when value is not a call. A leading namespace separator is dropped.
+func outermostCallee(value string) string {
+	value = strings.TrimSpace(value)
+	depth, open := 0, -1
+	var quote byte
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			if depth == 0 {
+				open = i
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 && i == len(value)-1 && open > 0 {
+				return strings.TrimPrefix(strings.TrimSpace(value[:open]), "\\")
+			}
+		}
+	}
+	return ""
+}
+
+// callBarrier returns the barrier input passed by call into funcFile crosses,
+// or nil: a rule naming the called function, or one whose files contain
+// funcFile but not the calling file. Untrusted rules win over trusted ones.
+func (t *Tracer) callBarrier(call *types.CallSite, callerFile, funcFile, rootPath string) *BarrierRule {
+	if t.rules == nil {
+		return nil
+	}
+	callerRel, funcRel := relPath(rootPath, callerFile), relPath(rootPath, funcFile)
+	var crossed *BarrierRule
+	for i := range t.rules.Barriers {
+		rule := &t.rules.Barriers[i]
+		if !rule.matchesFunction(call) && !(rule.matchesFile(funcRel) && !rule.matchesFile(callerRel)) {
+			continue
+		}
+		if rule.Trust == BarrierUntrusted {
+			return rule
+		}
+		if crossed == nil {
+			crossed = rule
+		}
+	}
+	return crossed
+}
+
+// addBarrier records that the flow from `from` crosses the boundary of rule
+// at line:column of filePath, and returns the barrier node
+func (t *Tracer) addBarrier(from *types.FlowNode, rule *BarrierRule, filePath string, line, column int, flowMap *types.FlowMap) *types.FlowNode {
+	node := types.FlowNode{
+		ID:         types.NodeID(filePath, line, column, string(types.NodeBarrier), rule.label()),
+		Type:       types.NodeBarrier,
+		Language:   from.Language,
+		FilePath:   filePath,
+		Line:       line,
+		Column:     column,
+		Name:       rule.label(),
+		Snippet:    fmt.Sprintf("%s boundary %s", rule.Trust, rule.label()),
+		SourceType: from.SourceType,
+		Metadata:   map[string]interface{}{"trust": rule.Trust},
+	}
+	flowMap.AddNode(node)
+
+	description := "stopped at trusted boundary"
+	if rule.Trust == BarrierUntrusted {
+		description = "crosses untrusted boundary"
+	}
+	if flowMap.AddEdge(types.FlowEdge{From: from.ID, To: node.ID, Type: types.EdgeDataFlow, Description: description}) {
+		t.stats.FlowsTraced++
+	}
+	return &node
+}
+
+// relPath returns filePath relative to rootPath with forward slashes, or
+// filePath itself when it is outside rootPath
+func relPath(rootPath, filePath string) string {
+	rel, err := filepath.Rel(rootPath, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filePath
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,149 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestBarriers(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"admin", "lib"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, dir, "index.php", `<?php
+require 'auth.php';
+require 'admin/panel.php';
+require 'lib/audit.php';
+$user = currentUser($_COOKIE['sid']);
+$name = $_GET['name'];
+show($name);
+audit($name);
+$who = currentUser('x');
+`)
+	writeFile(t, dir, "auth.php", `<?php
+function currentUser($sid) {
+    return $_COOKIE['user'];
+}
+`)
+	writeFile(t, dir, filepath.Join("admin", "panel.php"), `<?php
+function show($v) {
+    echo $v;
+}
+`)
+	writeFile(t, dir, filepath.Join("lib", "audit.php"), `<?php
+function audit($v) {
+    echo $v;
+}
+`)
+
+	config := DefaultConfig()
+	config.Rules = &Rules{Barriers: []BarrierRule{
+		{Function: "currentUser", Trust: BarrierTrusted},
+		{Name: "admin module", Files: []string{"admin/**"}, Trust: BarrierUntrusted},
+		{Files: []string{"lib/**"}, Trust: BarrierTrusted},
+	}}
+	result, err := New(config).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A trusted function returning input is not a source wrapper
+	for _, src := range result.Sources {
+		if src.Line == 9 && filepath.Base(src.FilePath) == "index.php" {
+			t.Errorf("call to trusted function is a source: %s", src.Snippet)
+		}
+	}
+
+	nodes := make(map[string]types.FlowNode)
+	for _, node := range result.FlowMap.AllNodes {
+		nodes[node.ID] = node
+	}
+	barriers := make(map[string]bool) // Names of the barrier nodes reached
+	var crossed, stopped bool
+	for _, edge := range result.FlowMap.AllEdges {
+		from, to := nodes[edge.From], nodes[edge.To]
+		if to.Type == types.NodeBarrier {
+			barriers[to.Name] = true
+		}
+		if from.Type == types.NodeBarrier {
+			switch from.Name {
+			case "admin module":
+				crossed = crossed || filepath.Base(to.FilePath) == "panel.php"
+			default:
+				stopped = true
+			}
+		}
+	}
+	for _, name := range []string{"currentUser()", "admin module", "lib/**"} {
+		if !barriers[name] {
+			t.Errorf("no barrier node for %s in %v", name, barriers)
+		}
+	}
+	if !crossed {
+		t.Error("input stopped at the untrusted admin boundary")
+	}
+	if stopped {
+		t.Error("input flows past a trusted boundary")
+	}
+}
+
+func TestBarrierRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule BarrierRule
+		ok   bool
+	}{
+		{BarrierRule{Function: "AuthService::currentUser", Trust: BarrierTrusted}, true},
+		{BarrierRule{Files: []string{"admin/**"}, Trust: BarrierUntrusted}, true},
+		{BarrierRule{Trust: BarrierTrusted}, false},
+		{BarrierRule{Function: "currentUser", Trust: "safe"}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.rule.validate(); (err == nil) != tt.ok {
+			t.Errorf("validate(%+v) = %v, want ok %v", tt.rule, err, tt.ok)
+		}
+	}
+}
+
+func TestReturnBarriers(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		value   string
+		barrier bool
+	}{
+		{"function", "currentUser", "currentUser($_GET['a'])", true},
+		{"static method", "AuthService::currentUser", "AuthService::currentUser($_GET['a'])", true},
+		{"fully qualified static method", "AuthService::currentUser", "\\AuthService::currentUser($_GET['a'])", true},
+		{"instance method", "AuthService::currentUser", "$auth->currentUser($_GET['a'])", true},
+		{"chained instance method", "AuthService::currentUser", "$app->auth()->currentUser($_GET['a'])", true},
+		{"static method of another class", "AuthService::currentUser", "Session::currentUser($_GET['a'])", false},
+		{"argument with parentheses", "currentUser", "currentUser($_GET['a'] . ')')", true},
+		{"barrier nested in another call", "currentUser", "trim(currentUser($_GET['a']))", false},
+		{"barrier call not the whole value", "currentUser", "currentUser(1) . $_GET['a']", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "index.php", "<?php\n$user = "+tt.value+";\necho $user;\n")
+			config := DefaultConfig()
+			config.Rules = &Rules{Barriers: []BarrierRule{{Function: tt.rule, Trust: BarrierTrusted}}}
+			result, err := New(config).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var barrier, assigned bool
+			for _, node := range result.FlowMap.AllNodes {
+				barrier = barrier || node.Type == types.NodeBarrier
+				assigned = assigned || (node.Type == types.NodeVariable && node.Name == "$user")
+			}
+			if barrier != tt.barrier || assigned == tt.barrier {
+				t.Errorf("barrier = %v, $user assigned input = %v; want barrier %v", barrier, assigned, tt.barrier)
+			}
+		})
+	}
+}
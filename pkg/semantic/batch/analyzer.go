@@ -9,6 +9,7 @@ import (
 	"github.com/hatlesswizard/inputtracer/pkg/semantic"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/extractor"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/symbolic"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 )
 
 // SnippetInput represents a code snippet to analyze
@@ -31,6 +32,12 @@ type ExpressionResult struct {
 	InputTypes   []string `json:"input_types,omitempty"`
 	TraceSteps   []string `json:"trace_steps,omitempty"`
 	TraceError   string   `json:"trace_error,omitempty"`
+
+	// Classes declaring the member read, when the object's instantiation was not found
+	CandidateClasses []string `json:"candidate_classes,omitempty"`
+
+	// Analysis gaps hit while tracing, including the trace error if any
+	Warnings []types.AnalysisWarning `json:"warnings,omitempty"`
 }
 
 // SnippetResult represents the analysis result for a single snippet
@@ -85,16 +92,19 @@ func (a *BatchAnalyzer) Initialize() error {
 
 	// Create execution engine
 	a.engine = symbolic.NewExecutionEngine()
+	a.engine.SetCompactSnippets(true) // Batches keep many flows
 
 	// Add symbol tables
 	for filePath, st := range result.SymbolTable {
 		a.engine.AddSymbolTable(filePath, st)
 	}
 
-	// Add parsed files
+	// Register parsed files; the engine loads them on demand
 	for filePath, fileInfo := range result.Files {
 		if fileInfo.Root != nil && fileInfo.Content != nil {
-			a.engine.AddParsedFile(filePath, fileInfo.Root, fileInfo.Content)
+			if err := a.engine.AddFile(filePath, nil); err != nil {
+				return fmt.Errorf("failed to register %s: %w", filePath, err)
+			}
 		}
 	}
 
@@ -181,8 +191,15 @@ func (a *BatchAnalyzer) traceExpression(expression, contextFile string) Expressi
 	flow, err := a.engine.TracePropertyAccess(expression, contextFile)
 	if err != nil {
 		result.TraceError = err.Error()
+		result.Warnings = append(result.Warnings, types.WarningFromError(err))
+	}
+	if flow == nil {
 		return result
 	}
+	result.Warnings = append(result.Warnings, flow.Warnings...)
+	for _, c := range flow.Candidates {
+		result.CandidateClasses = append(result.CandidateClasses, c.ClassName)
+	}
 
 	// Check if any sources are user input
 	for _, source := range flow.Sources {
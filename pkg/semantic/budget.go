@@ -0,0 +1,35 @@
+package semantic
+
+import (
+	"errors"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// beyondMaxDepth reports whether a hop at depth goes past Config.MaxDepth,
+// recording the depth reached in the scan's budget otherwise
+func (t *Tracer) beyondMaxDepth(depth int) bool {
+	if depth > t.config.MaxDepth {
+		return true
+	}
+	t.budget.ReachDepth(depth)
+	return false
+}
+
+// budgetReport returns the budget of the running scan, with the limits behind
+// its warnings, its early stop and a full flow map (nil when none was built)
+func (t *Tracer) budgetReport(flowMap *types.FlowMap) *types.BudgetReport {
+	for category := range t.warnings.Counts() {
+		t.budget.HitLimitOf(category)
+	}
+	switch {
+	case errors.Is(t.incomplete, types.ErrMemoryLimit):
+		t.budget.HitLimit(types.BudgetLimitMemory)
+	case errors.Is(t.incomplete, types.ErrTimeLimit):
+		t.budget.HitLimit(types.BudgetLimitTime)
+	}
+	if flowMap != nil && flowMap.Full() {
+		t.budget.HitLimit(types.BudgetLimitNodes)
+	}
+	return t.budget.Report()
+}
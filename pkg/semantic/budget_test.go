@@ -0,0 +1,58 @@
+package semantic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestBudgetReports(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "chain.php", `<?php
+$a = $_GET['q'];
+$b = $a;
+$c = $b;
+$d = $c;
+$e = trim($d);
+`)
+	writeFile(t, dir, "other.php", "<?php\necho 'static';\n")
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget := result.Budget
+	if budget == nil || budget.FilesTouched != 2 || budget.Parses < 2 || budget.WallTime <= 0 {
+		t.Fatalf("scan budget = %+v, want 2 files touched and parsed", budget)
+	}
+	if budget.LimitHit() {
+		t.Errorf("scan budget limits = %v, want none", budget.LimitsHit)
+	}
+
+	backward, err := New(nil).TraceBackward("$d", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget = backward.Budget
+	if budget == nil || budget.DepthReached < 2 || budget.RegexEvaluations == 0 || budget.CacheHits == 0 || budget.LimitHit() {
+		t.Errorf("backward budget = %+v, want hops, pattern matches and cache hits without limits", budget)
+	}
+
+	config := DefaultConfig()
+	config.MaxDepth = 1
+	shallow, err := New(config).TraceBackward("$d", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shallow.Budget.LimitsHit) != 1 || shallow.Budget.LimitsHit[0] != types.BudgetLimitDepth || shallow.Budget.DepthReached != 1 {
+		t.Errorf("shallow backward budget = %+v, want the depth limit hit at depth 1", shallow.Budget)
+	}
+
+	batch, err := New(nil).TraceBackwardBatch([]string{"$d", "$e"}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, e := batch.PerVariable["$d"].Budget, batch.PerVariable["$e"].Budget; d == nil || d != e {
+		t.Errorf("batch budgets = %+v, %+v, want one shared report", d, e)
+	}
+}
@@ -0,0 +1,67 @@
+package semantic
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+)
+
+func TestCanonicalSourceName(t *testing.T) {
+	tests := []struct {
+		sourceType types.SourceType
+		key        string
+		want       string
+	}{
+		{types.SourceHTTPGet, "id", "http_get:id"},
+		{types.SourceHTTPGet, " id ", "http_get:id"},
+		{types.SourceHTTPPost, "", "http_post"},
+		{types.SourceHTTPHeader, "HTTP_USER_AGENT", "http_header:user-agent"},
+		{types.SourceHTTPHeader, "User-Agent", "http_header:user-agent"},
+		{types.SourceHTTPCookie, "Session", "http_cookie:Session"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := sources.CanonicalSourceName(tt.sourceType, tt.key); got != tt.want {
+				t.Errorf("CanonicalSourceName(%s, %q) = %q, want %q", tt.sourceType, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupSourcesByCanonical(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", "<?php\n$id = $_GET['id'];\n$ua = $_SERVER['HTTP_USER_AGENT'];\n")
+	writeFile(t, dir, "app.js", "app.get('/', (req, res) => {\n  const id = req.query.id;\n});\n")
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		canonical string
+		want      []string // Languages reading the input
+	}{
+		{"http_get:id", []string{"javascript", "php"}},
+		{"http_header:user-agent", []string{"php"}},
+		{"http_get:missing", nil},
+	}
+	groups := result.GroupSourcesByCanonical()
+	for _, tt := range tests {
+		t.Run(tt.canonical, func(t *testing.T) {
+			var langs []string
+			for _, src := range result.GetSourcesByCanonical(tt.canonical) {
+				langs = append(langs, src.Language)
+			}
+			sort.Strings(langs)
+			if !reflect.DeepEqual(langs, tt.want) {
+				t.Errorf("languages = %v, want %v", langs, tt.want)
+			}
+			if len(groups[tt.canonical]) != len(tt.want) {
+				t.Errorf("group has %d sources, want %d", len(groups[tt.canonical]), len(tt.want))
+			}
+		})
+	}
+}
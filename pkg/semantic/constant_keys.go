@@ -0,0 +1,33 @@
+package semantic
+
+import (
+	phpAnalyzer "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/php"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+// resolveConstantKeys folds source keys that reference constants defined in
+// other files (Metadata["key_expr"]) using the merged global constant table
+func (t *Tracer) resolveConstantKeys() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.symbolTable.Constants) == 0 {
+		return
+	}
+	for _, fileInfo := range t.files {
+		if fileInfo.Language != "php" {
+			continue
+		}
+		for _, src := range fileInfo.Sources {
+			keyExpr, ok := src.Metadata["key_expr"].(string)
+			if !ok || src.SourceKey != "" {
+				continue
+			}
+			className, _ := src.Metadata["key_class"].(string)
+			lookup := phpAnalyzer.ConstantLookup(t.symbolTable.Constants, className)
+			if key, ok := phpPatterns.FoldConstantExpression(keyExpr, lookup); ok {
+				src.SourceKey = key
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package semantic
+
+import (
+	"bytes"
+
+	"github.com/hatlesswizard/inputtracer/pkg/parser"
+)
+
+// WriteOptions controls what exported results embed beyond the trace itself
+type WriteOptions struct {
+	// ContextLines embeds this many lines of code before and after each source
+	// and node (0 = none). Redacted results never embed code context.
+	ContextLines int
+}
+
+// ContextLine is one line of code around a source or node
+type ContextLine struct {
+	Line int    `json:"line"`
+	Code string `json:"code"`
+}
+
+// contextReader reads the code around locations from the parser service's
+// cached sources, falling back to disk without parsing, so that only the files
+// in its LRU cache are held, not every scanned file. Line
+// offsets are kept for the last file read, as exports visit nodes file by file.
+type contextReader struct {
+	service *parser.Service
+	radius  int
+
+	file    string
+	source  []byte
+	offsets []int // Start offset of each line of source
+}
+
+// newContextReader creates a reader for the result, or nil when opts embeds
+// no context. Results not produced by TraceDirectory get their own service.
+func newContextReader(r *TraceResult, opts WriteOptions) *contextReader {
+	if opts.ContextLines <= 0 || r.redacted {
+		return nil
+	}
+	return &contextReader{service: r.codeParser(), radius: opts.ContextLines}
+}
+
+// around returns the lines within the radius of line in filePath, or nil when
+// the file cannot be read
+func (c *contextReader) around(filePath string, line int) []ContextLine {
+	if c == nil || line <= 0 || !c.load(filePath) {
+		return nil
+	}
+	first, last := line-c.radius, line+c.radius
+	if first < 1 {
+		first = 1
+	}
+	if last > len(c.offsets) {
+		last = len(c.offsets)
+	}
+	var lines []ContextLine
+	for n := first; n <= last; n++ {
+		end := len(c.source)
+		if n < len(c.offsets) {
+			end = c.offsets[n] - 1
+		}
+		code := bytes.TrimRight(c.source[c.offsets[n-1]:end], "\r\n")
+		lines = append(lines, ContextLine{Line: n, Code: string(code)})
+	}
+	return lines
+}
+
+// load makes filePath the current file
+func (c *contextReader) load(filePath string) bool {
+	if filePath == c.file {
+		return c.source != nil
+	}
+	c.file, c.source, c.offsets = filePath, nil, nil
+	source, err := c.service.Content(filePath)
+	if err != nil || source == nil {
+		return false
+	}
+	c.source = source
+	c.offsets = []int{0}
+	for i, b := range c.source {
+		if b == '\n' && i+1 < len(c.source) {
+			c.offsets = append(c.offsets, i+1)
+		}
+	}
+	return true
+}
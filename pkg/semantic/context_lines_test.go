@@ -0,0 +1,93 @@
+package semantic
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestContextReaderAround(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "index.php")
+	if err := os.WriteFile(file, []byte("<?php\r\n$a = 1;\r\n$id = $_GET['id'];\r\necho $id;\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reader := newContextReader(&TraceResult{}, WriteOptions{ContextLines: 1})
+
+	tests := []struct {
+		name string
+		line int
+		want []ContextLine
+	}{
+		{"middle", 3, []ContextLine{{2, "$a = 1;"}, {3, "$id = $_GET['id'];"}, {4, "echo $id;"}}},
+		{"first line", 1, []ContextLine{{1, "<?php"}, {2, "$a = 1;"}}},
+		{"last line", 4, []ContextLine{{3, "$id = $_GET['id'];"}, {4, "echo $id;"}}},
+		{"no line", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := reader.around(file, tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("around(%d) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+	if got := reader.around(filepath.Join(filepath.Dir(file), "missing.php"), 1); got != nil {
+		t.Errorf("missing file context = %v, want nil", got)
+	}
+
+	// Text is read without parsing, so files of no grammar still get context
+	tpl := filepath.Join(filepath.Dir(file), "view.tpl")
+	if err := os.WriteFile(tpl, []byte("{$a}\n{$b}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reader.around(tpl, 2), []ContextLine{{1, "{$a}"}, {2, "{$b}"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("template context = %v, want %v", got, want)
+	}
+}
+
+func TestToJSONWithOptionsContext(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.php"), []byte("<?php\n// lookup\n$id = $_GET['id'];\necho $id;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sourceContext := func(out string) []ContextLine {
+		var doc struct {
+			Sources []struct {
+				Context []ContextLine `json:"context"`
+			} `json:"sources"`
+		}
+		if err := json.Unmarshal([]byte(out), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Sources) == 0 {
+			t.Fatal("no sources in output")
+		}
+		return doc.Sources[0].Context
+	}
+
+	out, err := result.ToJSONWithOptions(WriteOptions{ContextLines: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ContextLine{{2, "// lookup"}, {3, "$id = $_GET['id'];"}, {4, "echo $id;"}}
+	if got := sourceContext(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("source context = %v, want %v", got, want)
+	}
+
+	out, _ = result.ToJSON()
+	if got := sourceContext(out); got != nil {
+		t.Errorf("ToJSON embedded context %v", got)
+	}
+
+	result.RedactSnippets()
+	out, _ = result.ToJSONWithOptions(WriteOptions{ContextLines: 1})
+	if got := sourceContext(out); got != nil {
+		t.Errorf("redacted result embedded context %v", got)
+	}
+}
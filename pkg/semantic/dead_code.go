@@ -0,0 +1,95 @@
+package semantic
+
+import (
+	"fmt"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// deadCodeFinder is implemented by analyzers that find statements no
+// execution reaches because they follow an exit, die, return or throw
+// (currently PHP)
+type deadCodeFinder interface {
+	FindDeadRanges(root *sitter.Node, source []byte) []types.DeadRange
+}
+
+// findDeadRanges returns the unreachable code of a parsed file
+func findDeadRanges(langAnalyzer analyzer.LanguageAnalyzer, root *sitter.Node, content []byte) []types.DeadRange {
+	if finder, ok := langAnalyzer.(deadCodeFinder); ok {
+		return finder.FindDeadRanges(root, content)
+	}
+	return nil
+}
+
+// deadRangeAt returns the dead range containing line, or nil
+func deadRangeAt(ranges []types.DeadRange, line int) *types.DeadRange {
+	for i := range ranges {
+		if line >= ranges[i].StartLine && line <= ranges[i].EndLine {
+			return &ranges[i]
+		}
+	}
+	return nil
+}
+
+// pruneDeadAssignments drops the assignments in dead ranges: they never run,
+// so taint does not propagate through them
+func pruneDeadAssignments(ranges []types.DeadRange, assignments []*types.Assignment) []*types.Assignment {
+	if len(ranges) == 0 {
+		return assignments
+	}
+	kept := assignments[:0:0]
+	for _, assign := range assignments {
+		if deadRangeAt(ranges, assign.Line) == nil {
+			kept = append(kept, assign)
+		}
+	}
+	return kept
+}
+
+// pruneDeadCalls drops the calls in dead ranges
+func pruneDeadCalls(ranges []types.DeadRange, calls []*types.CallSite) []*types.CallSite {
+	if len(ranges) == 0 {
+		return calls
+	}
+	kept := calls[:0:0]
+	for _, call := range calls {
+		if deadRangeAt(ranges, call.Line) == nil {
+			kept = append(kept, call)
+		}
+	}
+	return kept
+}
+
+// flagDeadSources marks the sources in dead ranges with
+// Metadata["unreachable"], naming the statement that makes them unreachable,
+// and returns how many it marked. They stay reported but, with the code
+// around them pruned, flow nowhere.
+func flagDeadSources(ranges []types.DeadRange, sources []*types.FlowNode) int {
+	flagged := 0
+	for _, src := range sources {
+		dead := deadRangeAt(ranges, src.Line)
+		if dead == nil {
+			continue
+		}
+		if src.Metadata == nil {
+			src.Metadata = make(map[string]interface{})
+		}
+		src.Metadata["unreachable"] = fmt.Sprintf("after %s at line %d", dead.Terminator, dead.TerminatorLine)
+		flagged++
+	}
+	return flagged
+}
+
+// GetUnreachableSources returns the sources located in code no execution
+// reaches, such as after an exit()
+func (r *TraceResult) GetUnreachableSources() []*types.FlowNode {
+	var result []*types.FlowNode
+	for _, src := range r.Sources {
+		if _, ok := src.Metadata["unreachable"]; ok {
+			result = append(result, src)
+		}
+	}
+	return result
+}
@@ -0,0 +1,55 @@
+package semantic
+
+import "testing"
+
+func TestDeadCodeStopsPropagation(t *testing.T) {
+	dir := writeFile(t, t.TempDir(), "index.php", `<?php
+if (!$user) {
+    header('Location: /login');
+    exit;
+    $lost = $_GET['a'];
+}
+$id = $_GET['id'];
+if ($id == '') {
+    wp_die('missing');
+} else {
+    return;
+}
+$copy = $id;
+$late = $_POST['late'];
+function helper() { return $_GET['h']; }
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, node := range result.FlowMap.AllNodes {
+		if node.Name == "$lost" || node.Name == "$copy" || node.Name == "$late" {
+			t.Errorf("input flows to %s at line %d in unreachable code", node.Name, node.Line)
+		}
+	}
+
+	want := map[string]string{
+		"a":    "after exit at line 4",
+		"late": "after wp_die() at line 8",
+	}
+	for _, src := range result.Sources {
+		got, _ := src.Metadata["unreachable"].(string)
+		if got != want[src.SourceKey] {
+			t.Errorf("source %s unreachable = %q, want %q", src.Snippet, got, want[src.SourceKey])
+		}
+	}
+	if got := len(result.GetUnreachableSources()); got != 2 || result.Stats.SourcesDead != 2 {
+		t.Errorf("unreachable sources = %d (stats %d), want 2", got, result.Stats.SourcesDead)
+	}
+
+	backward, err := New(nil).TraceBackward("$copy", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backward.Sources) != 0 {
+		t.Errorf("backward sources of unreachable $copy = %v, want none", backward.Sources)
+	}
+}
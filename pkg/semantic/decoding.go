@@ -0,0 +1,50 @@
+package semantic
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// noteDecoding records the decoding function of every flow node that decodes
+// or normalizes input (urldecode, base64_decode, stripslashes, ...) in the
+// node's Metadata["decoded"]
+func (t *Tracer) noteDecoding(flowMap *types.FlowMap) {
+	if flowMap == nil {
+		return
+	}
+	for i := range flowMap.AllNodes {
+		node := &flowMap.AllNodes[i]
+		if decoder := t.decodingStep(node); decoder != "" {
+			if node.Metadata == nil {
+				node.Metadata = make(map[string]interface{})
+			}
+			node.Metadata["decoded"] = decoder
+		}
+	}
+}
+
+// decodingStep returns the decoding function a flow node applies, or ""
+func (t *Tracer) decodingStep(node *types.FlowNode) string {
+	if node.Language != "php" {
+		return ""
+	}
+	rules := t.rulesFor(node.FilePath)
+	switch node.Type {
+	case types.NodeVariable:
+		return rules.DecodingCall(assignedValue(node.Snippet))
+	case types.NodeFunction:
+		if rules.IsDecodingFunction(node.Name) {
+			return strings.ToLower(strings.TrimPrefix(node.Name, "\\"))
+		}
+	}
+	return ""
+}
+
+// assignedValue returns the right-hand side of an assignment snippet
+func assignedValue(snippet string) string {
+	if i := strings.Index(snippet, " = "); i >= 0 {
+		return snippet[i+3:]
+	}
+	return snippet
+}
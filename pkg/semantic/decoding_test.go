@@ -0,0 +1,43 @@
+package semantic
+
+import "testing"
+
+func TestNoteDecoding(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+$raw = $_GET['q'];
+$safe = htmlspecialchars($raw);
+$decoded = urldecode($safe);
+$id = base64_decode($_GET['id']);
+$w = rawurldecode(strip_tags($_POST['w']));
+$plain = trim($_GET['p']);
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]interface{})
+	for _, node := range result.FlowMap.AllNodes {
+		if decoder, ok := node.Metadata["decoded"]; ok {
+			got[node.Name] = decoder
+		}
+	}
+	want := map[string]string{
+		"$decoded":      "urldecode",
+		"$id":           "base64_decode",
+		"$w":            "rawurldecode",
+		"urldecode":     "urldecode", // The call nodes
+		"base64_decode": "base64_decode",
+		"rawurldecode":  "rawurldecode",
+	}
+	if len(got) != len(want) {
+		t.Errorf("decoded nodes = %v, want %v", got, want)
+	}
+	for name, decoder := range want {
+		if got[name] != decoder {
+			t.Errorf("%s decoded by %v, want %s", name, got[name], decoder)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package semantic
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// DeepenPath re-traces a single finding with extraDepth more than
+// Config.MaxDepth, for a flow cut off at the depth limit, instead of
+// re-running the whole scan at a higher depth. pathID is the ID of a source
+// (one finding per source, as in InputFlowRecords) or of a path of
+// FlowMap.Paths. Only that source is traced again, on the caches of the
+// tracer that produced the result; what the deeper trace reaches is merged
+// into the flow map, the depth cutoffs it resolved are dropped from the
+// warnings, and a path of FlowMap.Paths is extended to the furthest node its
+// source now reaches. The refreshed finding is returned.
+//
+// The result must come from TraceDirectory on a tracer that has not traced
+// anything else since; Config.MaxDepth is restored afterwards.
+func (r *TraceResult) DeepenPath(pathID string, extraDepth int) (*Finding, error) {
+	t := r.tracer
+	if t == nil || r.FlowMap == nil {
+		return nil, errors.New("result cannot be deepened: it was not traced by TraceDirectory")
+	}
+	if extraDepth <= 0 {
+		return nil, fmt.Errorf("extra depth must be positive, got %d", extraDepth)
+	}
+	source, pathIndex := r.deepenTarget(pathID)
+	if source == nil {
+		return nil, fmt.Errorf("no source or path with ID %s", pathID)
+	}
+	before := newFinding(source, r.FlowMap)
+
+	// Trace into a map of its own so that nodes already reached are followed
+	// again, with warnings of their own
+	maxDepth, warnings := t.config.MaxDepth, t.warnings
+	t.config.MaxDepth += extraDepth
+	t.warnings = newWarningCollector(t.config)
+	deeper := types.NewFlowMapWithLimits(t.config.MaxFlowNodes, t.config.MaxFlowEdges)
+	deeper.AddNode(*source)
+	t.traceLevelOrder(func() { t.traceSource(source, deeper, t.queryRoot) })
+	rerun := t.warnings.Warnings()
+	t.config.MaxDepth, t.warnings = maxDepth, warnings
+
+	if r.redacted {
+		redactNodes(deeper.AllNodes)
+	}
+	for _, node := range deeper.AllNodes {
+		r.FlowMap.AddNode(node)
+	}
+	for _, edge := range deeper.AllEdges {
+		r.FlowMap.AddEdge(edge)
+	}
+	propagateLabels(r.FlowMap)
+	t.tagLayers(r.Sources, r.FlowMap, t.queryRoot)
+	r.replaceDepthCutoffs(source, before, rerun)
+
+	if pathIndex >= 0 {
+		record := InputFlowRecords(&TraceResult{Sources: []*types.FlowNode{source}, FlowMap: r.FlowMap})[0]
+		if path, ok := r.FlowMap.ShortestPath(source.ID, record.Endpoint.ID); ok {
+			path.ID = pathID
+			r.FlowMap.Paths[pathIndex] = *path
+		}
+	}
+
+	finding := newFinding(source, r.FlowMap)
+	return &finding, nil
+}
+
+// deepenTarget returns the source a DeepenPath ID designates and the index
+// of the designated path in FlowMap.Paths, or -1 for a source ID
+func (r *TraceResult) deepenTarget(pathID string) (*types.FlowNode, int) {
+	for _, src := range r.Sources {
+		if src.ID == pathID {
+			return src, -1
+		}
+	}
+	for i, path := range r.FlowMap.Paths {
+		if path.ID != pathID || path.Source == nil {
+			continue
+		}
+		for _, src := range r.Sources {
+			if src.ID == path.Source.ID {
+				return src, i
+			}
+		}
+	}
+	return nil, -1
+}
+
+// replaceDepthCutoffs drops the depth cutoffs recorded at the source or a
+// node of its finding before, which the re-trace either resolved or hit
+// again, and adds the depth cutoffs of the re-trace
+func (r *TraceResult) replaceDepthCutoffs(source *types.FlowNode, before Finding, rerun []types.AnalysisWarning) {
+	type location struct {
+		file string
+		line int
+	}
+	traced := map[location]bool{{source.FilePath, source.Line}: true}
+	for _, node := range before.Nodes {
+		traced[location{node.FilePath, node.Line}] = true
+	}
+
+	if r.WarningCounts == nil {
+		r.WarningCounts = make(map[types.WarningCategory]int)
+	}
+	kept := r.Warnings[:0]
+	for _, w := range r.Warnings {
+		if w.Category == types.WarningDepthCutoff && traced[location{w.FilePath, w.Line}] {
+			r.WarningCounts[w.Category]--
+			continue
+		}
+		kept = append(kept, w)
+	}
+	r.Warnings = kept
+	for _, w := range rerun {
+		if w.Category == types.WarningDepthCutoff {
+			r.Warnings = append(r.Warnings, w)
+			r.WarningCounts[w.Category]++
+		}
+	}
+	if r.WarningCounts[types.WarningDepthCutoff] <= 0 {
+		delete(r.WarningCounts, types.WarningDepthCutoff)
+	}
+}
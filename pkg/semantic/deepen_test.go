@@ -0,0 +1,82 @@
+package semantic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestDeepenPath(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+$a = $_GET['q'];
+$b = $a;
+$c = $b;
+$d = $c;
+$e = $d;
+echo $e;
+`)
+
+	config := DefaultConfig()
+	config.MaxDepth = 1
+	tracer := New(config)
+	result, err := tracer.TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sources) != 1 {
+		t.Fatalf("sources = %d, want 1", len(result.Sources))
+	}
+	if result.WarningCounts[types.WarningDepthCutoff] == 0 {
+		t.Fatal("expected a depth cutoff at max depth 1")
+	}
+	reaches := func(f *Finding, name string) bool {
+		for _, node := range f.Nodes {
+			if node.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	source := result.Sources[0]
+	if before := newFinding(source, result.FlowMap); reaches(&before, "$e") {
+		t.Fatal("$e reached at max depth 1")
+	}
+
+	finding, err := result.DeepenPath(source.ID, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reaches(finding, "$e") {
+		t.Errorf("deepened finding does not reach $e: %+v", finding.Nodes)
+	}
+	if n := result.WarningCounts[types.WarningDepthCutoff]; n != 0 {
+		t.Errorf("depth cutoffs after deepening = %d, want 0: %+v", n, result.Warnings)
+	}
+	if config.MaxDepth != 1 {
+		t.Errorf("MaxDepth = %d after deepening, want 1", config.MaxDepth)
+	}
+	if _, err := result.DeepenPath("missing", 5); err == nil {
+		t.Error("expected an error for an unknown path ID")
+	}
+
+	// A path of the flow map is extended to the furthest node reached
+	result, err = New(config).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source = result.Sources[0]
+	path, ok := result.FlowMap.ShortestPath(source.ID, source.ID)
+	if !ok {
+		t.Fatal("no path from the source to itself")
+	}
+	path.ID = "finding-1"
+	result.FlowMap.Paths = append(result.FlowMap.Paths, *path)
+	if _, err := result.DeepenPath("finding-1", 5); err != nil {
+		t.Fatal(err)
+	}
+	deepened := result.FlowMap.Paths[0]
+	if deepened.ID != "finding-1" || deepened.Target == nil || deepened.Target.Name != "$e" {
+		t.Errorf("deepened path = %+v, want finding-1 ending at $e", deepened)
+	}
+}
@@ -0,0 +1,299 @@
+// Package doctor is a self-test of an installation. It runs the full pipeline
+// over small fixture programs embedded in the binary, one per supported
+// language, and reports which grammars, analyzers and traces work: a quick
+// check of a build or container image before a large scan.
+package doctor
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	sitter "github.com/smacker/go-tree-sitter"
+
+	"github.com/hatlesswizard/inputtracer/pkg/parser/languages"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/pipeline"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// Fixture programs, one directory per language. A .txt suffix is dropped
+// when they are written out; it keeps the Go fixture out of the build.
+//
+//go:embed fixtures
+var fixtures embed.FS
+
+// Stages of the pipeline a self-test checks
+const (
+	StageGrammar  = "grammar"  // The fixture parses without syntax errors
+	StageSources  = "sources"  // The analyzer finds the fixture's sources
+	StageFlow     = "flow"     // A source flows into the expected variable
+	StageSymbolic = "symbolic" // The symbolic engine traces a carrier to its superglobal
+)
+
+// Stages lists the stages in pipeline order, the columns of the matrix
+var Stages = []string{StageGrammar, StageSources, StageFlow, StageSymbolic}
+
+// fixture is what the pipeline must find in the fixture of a language
+type fixture struct {
+	language string   // Language, also the directory under fixtures
+	sources  []string // Snippets of the sources the analyzer must find
+	flowsTo  string   // Variable a source must flow into; "" when the analyzer traces no flows
+
+	// Carrier expression the symbolic engine must resolve to carrierSource
+	carrier       string
+	carrierSource string
+}
+
+var languageFixtures = []fixture{
+	{language: "php", sources: []string{"$_GET['id']"}, flowsTo: "$id", carrier: "$app->input['name']", carrierSource: "$_GET"},
+	{language: "javascript", sources: []string{"req.query.id"}, flowsTo: "id"},
+	{language: "typescript", sources: []string{"req.query.id"}, flowsTo: "id"},
+	{language: "python", sources: []string{"request.args.get"}, flowsTo: "uid"},
+	{language: "go", sources: []string{"r.URL.Query().Get"}, flowsTo: "id"},
+	{language: "java", sources: []string{`request.getParameter("id")`}, flowsTo: "id"},
+	{language: "c_sharp", sources: []string{`Request.Query["id"]`}},
+	{language: "ruby", sources: []string{"params['name']"}},
+	{language: "rust", sources: []string{`env::var("NAME")`}, flowsTo: "name"},
+	{language: "c", sources: []string{`getenv("HOME")`}, flowsTo: "home"},
+	{language: "cpp", sources: []string{`std::getenv("HOME")`, "std::cin >> name"}, flowsTo: "home"},
+	{language: "kotlin", sources: []string{"readLine()"}, flowsTo: "name"},
+	{language: "swift", sources: []string{"readLine()"}, flowsTo: "name"},
+}
+
+// Check is the outcome of one stage for one language. A stage the language
+// does not support is skipped.
+type Check struct {
+	Language string `json:"language"`
+	Stage    string `json:"stage"`
+	Passed   bool   `json:"passed"`
+	Skipped  bool   `json:"skipped,omitempty"`
+	Detail   string `json:"detail,omitempty"` // Why the check failed
+}
+
+// Report is the result of a self-test
+type Report struct {
+	GrammarVersion string  `json:"grammar_version"` // Version of the bundled grammars
+	Checks         []Check `json:"checks"`
+}
+
+// Passed reports whether no check failed
+func (r *Report) Passed() bool {
+	for _, c := range r.Checks {
+		if !c.Passed && !c.Skipped {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteMatrix writes the checks as a language by stage matrix of
+// pass/FAIL/- cells, followed by the details of the failures
+func (r *Report) WriteMatrix(w io.Writer) error {
+	cells := make(map[string]map[string]Check)
+	var order []string
+	for _, c := range r.Checks {
+		if cells[c.Language] == nil {
+			cells[c.Language] = make(map[string]Check)
+			order = append(order, c.Language)
+		}
+		cells[c.Language][c.Stage] = c
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "language\t%s\n", strings.Join(Stages, "\t"))
+	for _, language := range order {
+		fmt.Fprint(tw, language)
+		for _, stage := range Stages {
+			cell := "-"
+			if c, ok := cells[language][stage]; ok && !c.Skipped {
+				cell = "pass"
+				if !c.Passed {
+					cell = "FAIL"
+				}
+			}
+			fmt.Fprintf(tw, "\t%s", cell)
+		}
+		fmt.Fprintln(tw)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, c := range r.Checks {
+		if !c.Passed && !c.Skipped {
+			fmt.Fprintf(w, "%s %s: %s\n", c.Language, c.Stage, c.Detail)
+		}
+	}
+	return nil
+}
+
+// Run writes the fixtures to a temporary directory and runs the pipeline
+// over the fixture of each language. An error means the self-test could not
+// run; failed checks are reported in the Report.
+func Run() (*Report, error) {
+	dir, err := os.MkdirTemp("", "inputtracer-doctor-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := writeFixtures(dir); err != nil {
+		return nil, err
+	}
+
+	report := &Report{GrammarVersion: languages.GrammarVersion()}
+	for _, f := range languageFixtures {
+		report.Checks = append(report.Checks, runFixture(f, filepath.Join(dir, f.language))...)
+	}
+	return report, nil
+}
+
+// writeFixtures writes the embedded fixtures under dir
+func writeFixtures(dir string) error {
+	return fs.WalkDir(fixtures, "fixtures", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := fixtures.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(strings.TrimSuffix(strings.TrimPrefix(name, "fixtures/"), ".txt")))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0o644)
+	})
+}
+
+// runFixture checks every stage on the fixture of a language written to dir
+func runFixture(f fixture, dir string) []Check {
+	checks := []Check{checkGrammar(f, dir)}
+	report, err := pipeline.Run(dir, nil, pipeline.Options{})
+	if err != nil {
+		for _, stage := range Stages[1:] {
+			checks = append(checks, Check{Language: f.language, Stage: stage, Detail: err.Error()})
+		}
+		return checks
+	}
+	return append(checks,
+		checkSources(f, report.Scan),
+		checkFlow(f, report.Scan),
+		checkSymbolic(f, report))
+}
+
+// checkGrammar parses the fixture files with the grammar of their extension
+func checkGrammar(f fixture, dir string) Check {
+	check := Check{Language: f.language, Stage: StageGrammar}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	parser := sitter.NewParser()
+	defer parser.Close()
+	for _, entry := range entries {
+		name := languages.GetLanguageByExtension(path.Ext(entry.Name()))
+		grammar := grammarOf(name)
+		if grammar == nil {
+			check.Detail = fmt.Sprintf("no grammar for %s", entry.Name())
+			return check
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			check.Detail = err.Error()
+			return check
+		}
+		parser.SetLanguage(grammar)
+		tree, err := parser.ParseCtx(context.Background(), nil, content)
+		if err != nil {
+			check.Detail = fmt.Sprintf("%s: %v", entry.Name(), err)
+			return check
+		}
+		hasError := tree.RootNode().HasError()
+		tree.Close()
+		if hasError {
+			check.Detail = fmt.Sprintf("syntax errors in %s", entry.Name())
+			return check
+		}
+	}
+	check.Passed = true
+	return check
+}
+
+// grammarOf returns the bundled grammar of a language, or nil
+func grammarOf(name string) *sitter.Language {
+	for _, lang := range languages.GetAllLanguages() {
+		if lang.Name == name {
+			return lang.Language
+		}
+	}
+	return nil
+}
+
+// checkSources checks that the scan found every expected source
+func checkSources(f fixture, result *semantic.TraceResult) Check {
+	check := Check{Language: f.language, Stage: StageSources}
+	found := make(map[string]bool)
+	for _, src := range result.Sources {
+		found[src.Snippet] = true
+	}
+	var missing []string
+	for _, want := range f.sources {
+		if !found[want] {
+			missing = append(missing, want)
+		}
+	}
+	if len(missing) > 0 {
+		check.Detail = fmt.Sprintf("sources not found: %s (found %d)", strings.Join(missing, ", "), len(result.Sources))
+		return check
+	}
+	check.Passed = true
+	return check
+}
+
+// checkFlow checks that a source flows into the expected variable
+func checkFlow(f fixture, result *semantic.TraceResult) Check {
+	check := Check{Language: f.language, Stage: StageFlow, Skipped: f.flowsTo == ""}
+	if check.Skipped {
+		return check
+	}
+	for _, node := range result.FlowMap.AllNodes {
+		if node.Type == types.NodeVariable && node.Name == f.flowsTo {
+			check.Passed = true
+			return check
+		}
+	}
+	check.Detail = fmt.Sprintf("no flow into %s (%d nodes)", f.flowsTo, len(result.FlowMap.AllNodes))
+	return check
+}
+
+// checkSymbolic checks that the deep-dive resolved the carrier
+func checkSymbolic(f fixture, report *pipeline.Report) Check {
+	check := Check{Language: f.language, Stage: StageSymbolic, Skipped: f.carrier == ""}
+	if check.Skipped {
+		return check
+	}
+	for _, trace := range report.DeepTraces {
+		if trace.Expression != f.carrier {
+			continue
+		}
+		for _, src := range trace.Sources {
+			if src.Expression == f.carrierSource {
+				check.Passed = true
+				return check
+			}
+		}
+		check.Detail = fmt.Sprintf("%s not traced to %s: %s", f.carrier, f.carrierSource, trace.Error)
+		return check
+	}
+	check.Detail = fmt.Sprintf("%s not deep-traced", f.carrier)
+	return check
+}
@@ -0,0 +1,44 @@
+package doctor
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	report, err := Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Checks) != len(languageFixtures)*len(Stages) {
+		t.Errorf("checks = %d, want %d", len(report.Checks), len(languageFixtures)*len(Stages))
+	}
+	var matrix strings.Builder
+	if err := report.WriteMatrix(&matrix); err != nil {
+		t.Fatal(err)
+	}
+	if !report.Passed() {
+		t.Errorf("self-test failed:\n%s", matrix.String())
+	}
+	if !strings.Contains(matrix.String(), "php") || strings.Contains(matrix.String(), "FAIL") {
+		t.Errorf("matrix:\n%s", matrix.String())
+	}
+}
+
+func TestReportFailures(t *testing.T) {
+	report := &Report{Checks: []Check{
+		{Language: "php", Stage: StageGrammar, Passed: true},
+		{Language: "php", Stage: StageFlow, Skipped: true},
+		{Language: "php", Stage: StageSources, Detail: "sources not found: $_GET['id']"},
+	}}
+	if report.Passed() {
+		t.Error("Passed() = true with a failed check")
+	}
+	var matrix strings.Builder
+	report.WriteMatrix(&matrix)
+	for _, want := range []string{"pass", "FAIL", "-", "php sources: sources not found"} {
+		if !strings.Contains(matrix.String(), want) {
+			t.Errorf("matrix does not contain %q:\n%s", want, matrix.String())
+		}
+	}
+}
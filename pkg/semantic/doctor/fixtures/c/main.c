@@ -0,0 +1,11 @@
+#include <stdio.h>
+#include <stdlib.h>
+
+int main(void) {
+    char buf[64];
+    fgets(buf, sizeof(buf), stdin);
+    char *home;
+    home = getenv("HOME");
+    printf("%s %s\n", buf, home);
+    return 0;
+}
@@ -0,0 +1,8 @@
+using Microsoft.AspNetCore.Mvc;
+
+public class UserController : Controller {
+    public IActionResult Show() {
+        string id = Request.Query["id"];
+        return Content(id);
+    }
+}
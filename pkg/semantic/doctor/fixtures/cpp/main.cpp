@@ -0,0 +1,11 @@
+#include <iostream>
+#include <string>
+
+int main() {
+    std::string name;
+    std::cin >> name;
+    const char *home;
+    home = std::getenv("HOME");
+    std::cout << name << home << std::endl;
+    return 0;
+}
@@ -0,0 +1,8 @@
+import javax.servlet.http.*;
+
+public class UserServlet extends HttpServlet {
+    protected void doGet(HttpServletRequest request, HttpServletResponse response) {
+        String id = request.getParameter("id");
+        System.out.println(id);
+    }
+}
@@ -0,0 +1,6 @@
+const express = require('express');
+const app = express();
+app.get('/user', (req, res) => {
+  const id = req.query.id;
+  res.send(id);
+});
@@ -0,0 +1,4 @@
+fun main(args: Array<String>) {
+    val name = readLine()
+    println(name)
+}
@@ -0,0 +1,6 @@
+<?php
+class Core {
+    public $input = array();
+    function __construct() { $this->input = $_GET; }
+}
+$app = new Core();
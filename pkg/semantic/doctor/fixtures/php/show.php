@@ -0,0 +1,5 @@
+<?php
+require 'init.php';
+$id = $_GET['id'];
+$name = $app->input['name'];
+echo htmlspecialchars($id . $name);
@@ -0,0 +1,7 @@
+from flask import Flask, request
+app = Flask(__name__)
+
+@app.route('/user')
+def user():
+    uid = request.args.get('id')
+    return uid
@@ -0,0 +1,6 @@
+require 'sinatra'
+
+get '/user' do
+  name = params['name']
+  puts name
+end
@@ -0,0 +1,6 @@
+use std::env;
+
+fn main() {
+    let name = env::var("NAME").unwrap();
+    println!("{}", name);
+}
@@ -0,0 +1,4 @@
+import Foundation
+
+let name = readLine()
+print(name ?? "")
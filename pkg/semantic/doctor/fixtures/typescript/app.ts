@@ -0,0 +1,7 @@
+import express from 'express';
+const app = express();
+app.get('/user', (req, res) => {
+  let id: string;
+  id = req.query.id;
+  res.send(id);
+});
@@ -0,0 +1,245 @@
+package semantic
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// scriptEntryDetector is implemented by analyzers for languages where a file
+// requested directly is an entry point (currently PHP)
+type scriptEntryDetector interface {
+	HasTopLevelCode(root *sitter.Node, source []byte) bool
+}
+
+// EntryPointKind tells how an entry point was found
+type EntryPointKind string
+
+const (
+	// EntryPointScript is a file with top-level code that no other file includes
+	EntryPointScript EntryPointKind = "script"
+	// EntryPointDeclared is declared in the rules file
+	EntryPointDeclared EntryPointKind = "declared"
+	// EntryPointRealtime is a WebSocket or Server-Sent Events message handler
+	EntryPointRealtime EntryPointKind = "realtime"
+	// EntryPointRoute is a handler a custom router selects from a request value
+	EntryPointRoute EntryPointKind = "route"
+	// EntryPointFramework is a route a framework declares by attribute or
+	// filesystem convention (Symfony #[Route], Laravel Folio pages)
+	EntryPointFramework EntryPointKind = "framework_route"
+)
+
+// EntryPoint is a place where execution starts: a directly requested script,
+// a realtime message handler, a handler of a custom router, a framework route
+// or a user-declared entry (cron script, custom router target). Realtime
+// handlers are routed as protocol:event, router handlers as the dispatching
+// file and action, framework routes as their methods and path.
+type EntryPoint struct {
+	Kind           EntryPointKind     `json:"kind"`
+	FilePath       string             `json:"file_path"`
+	Function       string             `json:"function,omitempty"`
+	Line           int                `json:"line,omitempty"`
+	Route          string             `json:"route"`
+	Methods        []string           `json:"methods,omitempty"` // HTTP methods of a framework route
+	Inputs         []types.SourceType `json:"inputs,omitempty"`  // Declared inputs and source types read in reachable files
+	ReachableFiles []string           `json:"reachable_files"`   // Entry file and the files it includes, transitively
+}
+
+// Reaches reports whether filePath is reachable from the entry point
+func (ep *EntryPoint) Reaches(filePath string) bool {
+	i := sort.SearchStrings(ep.ReachableFiles, filePath)
+	return i < len(ep.ReachableFiles) && ep.ReachableFiles[i] == filePath
+}
+
+// EntryPointsForFile returns the entry points from which filePath is reachable
+func (r *TraceResult) EntryPointsForFile(filePath string) []*EntryPoint {
+	var eps []*EntryPoint
+	for _, ep := range r.EntryPoints {
+		if ep.Reaches(filePath) {
+			eps = append(eps, ep)
+		}
+	}
+	return eps
+}
+
+// EntryPointsForSource returns the entry points from which a source is reachable
+func (r *TraceResult) EntryPointsForSource(src *types.FlowNode) []*EntryPoint {
+	return r.EntryPointsForFile(src.FilePath)
+}
+
+// buildEntryPoints discovers script entry points and adds the entry points
+// declared in the rules file; both get routes, reachable files and inputs
+func (t *Tracer) buildEntryPoints(rootPath string) []*EntryPoint {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	includes := t.includes
+	included := make(map[string]bool)
+	for _, targets := range includes {
+		for _, target := range targets {
+			included[target] = true
+		}
+	}
+
+	var eps []*EntryPoint
+	filePaths := make([]string, 0, len(t.files))
+	for filePath := range t.files {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+	for _, filePath := range filePaths {
+		// Folio pages are served at their framework route, not their path
+		if t.files[filePath].TopLevelCode && !included[filePath] && folioRoute(rootPath, t.files[filePath]) == nil {
+			eps = append(eps, &EntryPoint{
+				Kind:     EntryPointScript,
+				FilePath: filePath,
+				Route:    fileRoute(rootPath, filePath),
+			})
+		}
+	}
+
+	eps = append(eps, t.realtimeEntryPoints(filePaths)...)
+	eps = append(eps, t.routeEntryPoints(rootPath, filePaths)...)
+	eps = append(eps, t.attributeRouteEntryPoints(rootPath, filePaths)...)
+
+	if t.rules != nil {
+		for _, rule := range t.rules.EntryPoints {
+			ep := t.declaredEntryPoint(rootPath, rule)
+			if ep == nil {
+				if t.config.Verbose {
+					fmt.Printf("  Declared entry point %s not found in the scanned files\n", rule.File)
+				}
+				continue
+			}
+			eps = append(eps, ep)
+		}
+	}
+
+	for _, ep := range eps {
+		ep.ReachableFiles = reachableFiles(ep.FilePath, includes)
+		ep.Inputs = t.entryPointInputs(ep)
+	}
+	return eps
+}
+
+// declaredEntryPoint builds the entry point for a rules-file declaration, or
+// nil if its file was not scanned
+func (t *Tracer) declaredEntryPoint(rootPath string, rule EntryPointRule) *EntryPoint {
+	filePath := rule.File
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(rootPath, filePath)
+	}
+	filePath = filepath.Clean(filePath)
+	if t.files[filePath] == nil {
+		return nil
+	}
+
+	ep := &EntryPoint{
+		Kind:     EntryPointDeclared,
+		FilePath: filePath,
+		Function: rule.Function,
+		Route:    rule.Route,
+	}
+	if ep.Route == "" {
+		ep.Route = fileRoute(rootPath, filePath)
+	}
+	if fn := t.symbolTable.Functions[filePath+"::"+rule.Function]; fn != nil {
+		ep.Line = fn.Line
+	}
+	for _, input := range rule.Inputs {
+		if st, ok := common.ParseSourceType(input); ok {
+			ep.Inputs = append(ep.Inputs, st)
+		}
+	}
+	return ep
+}
+
+// resolveIncludes maps each file to the scanned files it statically includes.
+// Include paths are tried relative to the including file, then to the root.
+func (t *Tracer) resolveIncludes(rootPath string) map[string][]string {
+	includes := make(map[string][]string)
+	for filePath, fileInfo := range t.files {
+		for _, inc := range fileInfo.Includes {
+			candidates := []string{inc}
+			if !filepath.IsAbs(inc) {
+				candidates = []string{filepath.Join(filepath.Dir(filePath), inc), filepath.Join(rootPath, inc)}
+			}
+			resolved := false
+			for _, candidate := range candidates {
+				candidate = filepath.Clean(candidate)
+				if !t.inSandbox(candidate, filePath, 0) {
+					resolved = true // Reported as a sandbox escape
+					break
+				}
+				if t.files[candidate] != nil {
+					includes[filePath] = append(includes[filePath], candidate)
+					resolved = true
+					break
+				}
+			}
+			if !resolved {
+				t.warn(types.WarningUnresolvedInclude, filePath, 0, inc, fmt.Sprintf("included file %s not found in the scanned files", inc))
+			}
+		}
+	}
+	return includes
+}
+
+// reachableFiles returns the sorted files reachable from start through includes
+func reachableFiles(start string, includes map[string][]string) []string {
+	seen := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) > 0 {
+		filePath := queue[0]
+		queue = queue[1:]
+		for _, next := range includes[filePath] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	files := make([]string, 0, len(seen))
+	for filePath := range seen {
+		files = append(files, filePath)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// entryPointInputs merges declared inputs with the source types read in the
+// entry point's reachable files
+func (t *Tracer) entryPointInputs(ep *EntryPoint) []types.SourceType {
+	seen := make(map[types.SourceType]bool)
+	var inputs []types.SourceType
+	add := func(st types.SourceType) {
+		if st != "" && !seen[st] {
+			seen[st] = true
+			inputs = append(inputs, st)
+		}
+	}
+	for _, st := range ep.Inputs {
+		add(st)
+	}
+	for _, filePath := range ep.ReachableFiles {
+		for _, src := range t.files[filePath].Sources {
+			add(src.SourceType)
+		}
+	}
+	return inputs
+}
+
+// fileRoute returns the route a directly requested file is served under
+func fileRoute(rootPath, filePath string) string {
+	rel, err := filepath.Rel(rootPath, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = filepath.Base(filePath)
+	}
+	return "/" + filepath.ToSlash(rel)
+}
@@ -0,0 +1,136 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestScriptEntryPoints(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "index.php", `<?php
+require 'lib/session.php';
+$id = $_GET['id'];
+`)
+	writeFile(t, dir, "lib/session.php", `<?php
+$token = $_COOKIE['token'];
+`)
+	writeFile(t, dir, "lib/Model.php", `<?php
+namespace App;
+use App\Db;
+class Model { function find($id) {} }
+`)
+	writeFile(t, dir, "page.php", `<?php function title() { return 'Hi'; } ?>
+<h1><?= title() ?></h1>
+`)
+	writeFile(t, dir, "blank.php", `<?php
+function helper() {}
+?>
+`)
+	writeFile(t, dir, "cron.php", `<?php
+function main() { global $argv; return $argv[1]; }
+`)
+
+	config := DefaultConfig()
+	config.Rules = &Rules{EntryPoints: []EntryPointRule{
+		{File: "cron.php", Function: "main", Route: "cron:nightly", Inputs: []string{"env"}},
+		{File: "missing.php"},
+	}}
+	result, err := New(config).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type entry struct {
+		kind      EntryPointKind
+		route     string
+		line      int
+		reachable []string
+		inputs    []types.SourceType
+	}
+	got := make(map[string]entry)
+	for _, ep := range result.EntryPoints {
+		var reachable []string
+		for _, f := range ep.ReachableFiles {
+			rel, _ := filepath.Rel(dir, f)
+			reachable = append(reachable, filepath.ToSlash(rel))
+		}
+		rel, _ := filepath.Rel(dir, ep.FilePath)
+		got[filepath.ToSlash(rel)] = entry{ep.Kind, ep.Route, ep.Line, reachable, ep.Inputs}
+	}
+
+	tests := []struct {
+		file string
+		want *entry
+	}{
+		{"index.php", &entry{EntryPointScript, "/index.php", 0, []string{"index.php", "lib/session.php"},
+			[]types.SourceType{types.SourceHTTPGet, types.SourceHTTPCookie}}},
+		{"page.php", &entry{EntryPointScript, "/page.php", 0, []string{"page.php"}, nil}}, // Inline HTML
+		{"cron.php", &entry{EntryPointDeclared, "cron:nightly", 2, []string{"cron.php"},
+			[]types.SourceType{types.SourceEnvVar, types.SourceCLIArg}}},
+		{"lib/session.php", nil}, // Included by index.php
+		{"lib/Model.php", nil},   // Declarations only
+		{"blank.php", nil},       // Whitespace after ?>
+		{"missing.php", nil},     // Declared but not scanned
+	}
+	if len(got) != 3 {
+		t.Errorf("entry points = %+v, want 3", got)
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			ep, ok := got[tt.file]
+			if tt.want == nil {
+				if ok {
+					t.Errorf("unexpected entry point %+v", ep)
+				}
+				return
+			}
+			if !reflect.DeepEqual(ep, *tt.want) {
+				t.Errorf("entry point = %+v, want %+v", ep, *tt.want)
+			}
+		})
+	}
+
+	session := filepath.Join(dir, "lib", "session.php")
+	if eps := result.EntryPointsForFile(session); len(eps) != 1 || eps[0].Route != "/index.php" {
+		t.Errorf("EntryPointsForFile(session.php) = %+v, want /index.php", eps)
+	}
+}
+
+func TestRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules Rules
+		err   string
+	}{
+		{"valid", Rules{EntryPoints: []EntryPointRule{{File: "cron.php", Inputs: []string{"argv", "GET"}}}}, ""},
+		{"missing file", Rules{EntryPoints: []EntryPointRule{{Function: "main"}}}, "entrypoints[0]: file is required"},
+		{"unknown input", Rules{EntryPoints: []EntryPointRule{{File: "a.php"}, {File: "b.php", Inputs: []string{"keyboard"}}}},
+			`entrypoints[1]: unknown input "keyboard"`},
+		{"malformed file glob", Rules{Layers: []LayerRule{{Layer: "model", Files: []string{"src/[Domain/**"}}}},
+			`layers[0]: invalid file pattern "src/[Domain/**": syntax error in pattern`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rules.Validate()
+			if tt.err == "" && err != nil || tt.err != "" && (err == nil || err.Error() != tt.err) {
+				t.Errorf("Validate() = %v, want %q", err, tt.err)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(`{"entrypoints": [{"file": "cron.php", "inputs": ["stdin?"]}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRules(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("LoadRules() = %v, want an error naming the file", err)
+	}
+}
@@ -0,0 +1,34 @@
+package semantic
+
+import "github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+
+// Re-export the structured errors from pkg/semantic/types so callers can use
+// errors.Is/errors.As on tracer results without importing types
+var (
+	ErrClassNotFound         = types.ErrClassNotFound
+	ErrInstantiationNotFound = types.ErrInstantiationNotFound
+	ErrMethodNotFound        = types.ErrMethodNotFound
+	ErrPropertyNotFound      = types.ErrPropertyNotFound
+	ErrParse                 = types.ErrParse
+	ErrMemoryLimit           = types.ErrMemoryLimit
+	ErrTimeLimit             = types.ErrTimeLimit
+	ErrUnsupportedExpression = types.ErrUnsupportedExpression
+)
+
+// Structured error types (see pkg/semantic/types)
+type (
+	TraceError       = types.TraceError
+	ParseError       = types.ParseError
+	MemoryLimitError = types.MemoryLimitError
+	TimeLimitError   = types.TimeLimitError
+)
+
+// recordIncomplete records why analysis stopped early; the first reason wins
+func (t *Tracer) recordIncomplete(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.incomplete == nil {
+		t.incomplete = err
+	}
+	t.warnings.Add(types.WarningFromError(err))
+}
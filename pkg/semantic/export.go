@@ -0,0 +1,281 @@
+package semantic
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// Exports for vulnerability-management platforms. inputtracer does not judge
+// whether a flow is exploitable, so every exported record is an informational
+// "input flow": it starts at an input source and ends at the furthest node the
+// input reaches. Triage teams decide what the flow means.
+
+// InputFlowRecord is one exported input flow: a source and the path to the
+// furthest node it reaches in the flow map
+type InputFlowRecord struct {
+	Source   *types.FlowNode
+	Steps    []types.FlowNode // Path from the source (first) to the endpoint (last)
+	Endpoint types.FlowNode   // Furthest node reached (the source itself if it flows nowhere)
+}
+
+// InputFlowRecords builds one record per input source of the trace result
+func InputFlowRecords(r *TraceResult) []InputFlowRecord {
+	nodes := make(map[string]types.FlowNode)
+	adjacency := make(map[string][]string)
+	if r.FlowMap != nil {
+		for _, node := range r.FlowMap.AllNodes {
+			nodes[node.ID] = node
+		}
+		for _, edge := range r.FlowMap.AllEdges {
+			adjacency[edge.From] = append(adjacency[edge.From], edge.To)
+		}
+	}
+
+	records := make([]InputFlowRecord, 0, len(r.Sources))
+	for _, src := range r.Sources {
+		// BFS keeps the first (shortest) parent of each node; the last node
+		// dequeued is one of the furthest reached
+		parent := map[string]string{src.ID: ""}
+		queue := []string{src.ID}
+		last := src.ID
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			last = id
+			for _, next := range adjacency[id] {
+				if _, seen := parent[next]; seen {
+					continue
+				}
+				node, ok := nodes[next]
+				if id == src.ID && (!ok || !ownsFlowStep(src, node)) {
+					continue
+				}
+				if ok && node.Type == types.NodeSource {
+					continue // Another finding
+				}
+				parent[next] = id
+				queue = append(queue, next)
+			}
+		}
+
+		var path []types.FlowNode
+		for id := last; id != src.ID; id = parent[id] {
+			if node, ok := nodes[id]; ok {
+				path = append([]types.FlowNode{node}, path...)
+			}
+		}
+		path = append([]types.FlowNode{*src}, path...)
+
+		records = append(records, InputFlowRecord{
+			Source:   src,
+			Steps:    path,
+			Endpoint: path[len(path)-1],
+		})
+	}
+	return records
+}
+
+// ownsFlowStep reports whether a node the source has an edge to reads the
+// source itself. Tracing links a source to every assignment or call naming
+// its superglobal, so the sources of $_GET['a'] and $_GET['b'] in one file
+// share their first steps; a finding starts only from its own expression.
+func ownsFlowStep(src *types.FlowNode, node types.FlowNode) bool {
+	if node.FilePath == src.FilePath && node.Line == src.Line {
+		return true
+	}
+	return src.Snippet != "" && containsSourceName(node.Snippet, src.Snippet)
+}
+
+// flowTitle names an input flow record
+func flowTitle(rec InputFlowRecord) string {
+	name := rec.Source.Name
+	if rec.Source.SourceKey != "" {
+		name = fmt.Sprintf("%s['%s']", name, rec.Source.SourceKey)
+	}
+	return fmt.Sprintf("Input flow: %s (%s)", name, rec.Source.SourceType)
+}
+
+// flowDescription renders the path steps as a markdown list
+func flowDescription(rec InputFlowRecord) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Input source `%s` (%s) at %s:%d.\n\n",
+		rec.Source.Snippet, rec.Source.SourceType, rec.Source.FilePath, rec.Source.Line))
+	sb.WriteString("Path:\n")
+	for i, step := range rec.Steps {
+		sb.WriteString(fmt.Sprintf("%d. [%s] `%s` at %s:%d\n", i+1, step.Type, step.Snippet, step.FilePath, step.Line))
+	}
+	return sb.String()
+}
+
+// DefectDojoEndpoint is a location endpoint in a DefectDojo generic finding
+type DefectDojoEndpoint struct {
+	Protocol string `json:"protocol"`
+	Path     string `json:"path"`
+	Fragment string `json:"fragment,omitempty"`
+}
+
+// DefectDojoFinding is one entry of the DefectDojo "Generic Findings Import" JSON format
+type DefectDojoFinding struct {
+	Title            string               `json:"title"`
+	Description      string               `json:"description"`
+	Severity         string               `json:"severity"`
+	FilePath         string               `json:"file_path"`
+	Line             int                  `json:"line"`
+	UniqueIDFromTool string               `json:"unique_id_from_tool"`
+	VulnIDFromTool   string               `json:"vuln_id_from_tool"`
+	StaticFinding    bool                 `json:"static_finding"`
+	DynamicFinding   bool                 `json:"dynamic_finding"`
+	Endpoints        []DefectDojoEndpoint `json:"endpoints"`
+}
+
+// ToDefectDojo exports input flows in DefectDojo generic-findings JSON.
+// The source and the flow endpoint become the finding's endpoints.
+func ToDefectDojo(r *TraceResult) (string, error) {
+	output := struct {
+		Findings []DefectDojoFinding `json:"findings"`
+	}{Findings: make([]DefectDojoFinding, 0, len(r.Sources))}
+
+	for _, rec := range InputFlowRecords(r) {
+		endpoints := []DefectDojoEndpoint{{
+			Protocol: "file",
+			Path:     rec.Source.FilePath,
+			Fragment: fmt.Sprintf("L%d", rec.Source.Line),
+		}}
+		if rec.Endpoint.ID != rec.Source.ID {
+			endpoints = append(endpoints, DefectDojoEndpoint{
+				Protocol: "file",
+				Path:     rec.Endpoint.FilePath,
+				Fragment: fmt.Sprintf("L%d", rec.Endpoint.Line),
+			})
+		}
+
+		output.Findings = append(output.Findings, DefectDojoFinding{
+			Title:            flowTitle(rec),
+			Description:      flowDescription(rec),
+			Severity:         "Info",
+			FilePath:         rec.Source.FilePath,
+			Line:             rec.Source.Line,
+			UniqueIDFromTool: rec.Source.ID,
+			VulnIDFromTool:   "inputtracer-" + string(rec.Source.SourceType),
+			StaticFinding:    true,
+			DynamicFinding:   false,
+			Endpoints:        endpoints,
+		})
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// cxResults is the root of the Checkmarx-style XML report
+type cxResults struct {
+	XMLName      xml.Name  `xml:"CxXMLResults"`
+	ScanStart    string    `xml:"ScanStart,attr,omitempty"`
+	Files        int       `xml:"FilesScanned,attr"`
+	SourceFolder string    `xml:"SourceFolder,attr,omitempty"`
+	Queries      []cxQuery `xml:"Query"`
+}
+
+type cxQuery struct {
+	ID       int        `xml:"id,attr"`
+	Name     string     `xml:"name,attr"`
+	Group    string     `xml:"group,attr"`
+	Severity string     `xml:"Severity,attr"`
+	Results  []cxResult `xml:"Result"`
+}
+
+type cxResult struct {
+	NodeID   string `xml:"NodeId,attr"`
+	FileName string `xml:"FileName,attr"`
+	Line     int    `xml:"Line,attr"`
+	Column   int    `xml:"Column,attr"`
+	Severity string `xml:"Severity,attr"`
+	Path     cxPath `xml:"Path"`
+}
+
+type cxPath struct {
+	ResultID  string       `xml:"ResultId,attr"`
+	PathID    int          `xml:"PathId,attr"`
+	PathNodes []cxPathNode `xml:"PathNode"`
+}
+
+type cxPathNode struct {
+	FileName string    `xml:"FileName"`
+	Line     int       `xml:"Line"`
+	Column   int       `xml:"Column"`
+	NodeID   int       `xml:"NodeId"`
+	Name     string    `xml:"Name"`
+	Type     string    `xml:"Type"`
+	Length   int       `xml:"Length"`
+	Snippet  cxSnippet `xml:"Snippet"`
+}
+
+type cxSnippet struct {
+	Line cxSnippetLine `xml:"Line"`
+}
+
+type cxSnippetLine struct {
+	Number int    `xml:"Number"`
+	Code   string `xml:"Code"`
+}
+
+// ToCheckmarxXML exports input flows in the Checkmarx-like CxXMLResults format
+// many vulnerability-management platforms accept. Flows are grouped into one
+// query per source type; each path runs from the source to the flow endpoint.
+func ToCheckmarxXML(r *TraceResult) (string, error) {
+	report := cxResults{}
+	if r.Stats != nil {
+		report.Files = r.Stats.FilesScanned
+	}
+
+	queryIndex := make(map[types.SourceType]int)
+	for i, rec := range InputFlowRecords(r) {
+		idx, ok := queryIndex[rec.Source.SourceType]
+		if !ok {
+			idx = len(report.Queries)
+			queryIndex[rec.Source.SourceType] = idx
+			report.Queries = append(report.Queries, cxQuery{
+				ID:       idx + 1,
+				Name:     "Input_Flow_" + string(rec.Source.SourceType),
+				Group:    "InputTracer",
+				Severity: "Information",
+			})
+		}
+
+		path := cxPath{ResultID: rec.Source.ID, PathID: i + 1}
+		for n, step := range rec.Steps {
+			path.PathNodes = append(path.PathNodes, cxPathNode{
+				FileName: step.FilePath,
+				Line:     step.Line,
+				Column:   step.Column,
+				NodeID:   n + 1,
+				Name:     step.Name,
+				Type:     string(step.Type),
+				Length:   len(step.Name),
+				Snippet:  cxSnippet{Line: cxSnippetLine{Number: step.Line, Code: step.Snippet}},
+			})
+		}
+
+		report.Queries[idx].Results = append(report.Queries[idx].Results, cxResult{
+			NodeID:   rec.Source.ID,
+			FileName: rec.Source.FilePath,
+			Line:     rec.Source.Line,
+			Column:   rec.Source.Column,
+			Severity: "Information",
+			Path:     path,
+		})
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(data), nil
+}
@@ -0,0 +1,101 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// exportResult has two $_GET sources on consecutive lines; tracing links
+// each of them to both assignments, as it matches by superglobal name
+func exportResult() *TraceResult {
+	id := &types.FlowNode{ID: "src-id", Type: types.NodeSource, FilePath: "app/page.php", Line: 2, Column: 6,
+		Name: "$_GET", Snippet: "$_GET['id']", SourceType: types.SourceHTTPGet, SourceKey: "id"}
+	name := &types.FlowNode{ID: "src-name", Type: types.NodeSource, FilePath: "app/page.php", Line: 3, Column: 8,
+		Name: "$_GET", Snippet: "$_GET['name']", SourceType: types.SourceHTTPGet, SourceKey: "name"}
+	cookie := &types.FlowNode{ID: "src-token", Type: types.NodeSource, FilePath: "app/auth.php", Line: 5, Column: 9,
+		Name: "$_COOKIE", Snippet: "$_COOKIE['token']", SourceType: types.SourceHTTPCookie, SourceKey: "token"}
+
+	flowMap := types.NewFlowMap()
+	for _, node := range []types.FlowNode{
+		*id, *name, *cookie,
+		{ID: "var-id", Type: types.NodeVariable, FilePath: "app/page.php", Line: 2, Name: "$id", Snippet: "$id = $_GET['id']"},
+		{ID: "var-name", Type: types.NodeVariable, FilePath: "app/page.php", Line: 3, Name: "$name", Snippet: "$name = $_GET['name']"},
+		{ID: "var-title", Type: types.NodeVariable, FilePath: "app/page.php", Line: 4, Column: 2, Name: "$title", Snippet: "$title = 'Hi ' . $name"},
+	} {
+		flowMap.AddNode(node)
+	}
+	for _, edge := range [][2]string{
+		{"src-id", "var-id"}, {"src-id", "var-name"},
+		{"src-name", "var-id"}, {"src-name", "var-name"},
+		{"var-name", "var-title"},
+	} {
+		flowMap.AddEdge(types.FlowEdge{From: edge[0], To: edge[1], Type: types.EdgeAssignment})
+	}
+
+	return &TraceResult{
+		Sources: []*types.FlowNode{id, name, cookie},
+		FlowMap: flowMap,
+		Stats:   &TraceStats{FilesScanned: 2},
+	}
+}
+
+func TestInputFlowRecords(t *testing.T) {
+	tests := []struct {
+		source string
+		steps  []string
+	}{
+		{"src-id", []string{"src-id", "var-id"}}, // Not through $name
+		{"src-name", []string{"src-name", "var-name", "var-title"}},
+		{"src-token", []string{"src-token"}},
+	}
+	records := InputFlowRecords(exportResult())
+	if len(records) != len(tests) {
+		t.Fatalf("got %d records, want %d", len(records), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			rec := records[i]
+			var steps []string
+			for _, step := range rec.Steps {
+				steps = append(steps, step.ID)
+			}
+			if rec.Source.ID != tt.source || !reflect.DeepEqual(steps, tt.steps) {
+				t.Errorf("record of %s = %v, want %v", rec.Source.ID, steps, tt.steps)
+			}
+			if rec.Endpoint.ID != tt.steps[len(tt.steps)-1] {
+				t.Errorf("endpoint = %s, want %s", rec.Endpoint.ID, tt.steps[len(tt.steps)-1])
+			}
+		})
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	result := exportResult()
+	tests := []struct {
+		name   string
+		export func(*TraceResult) (string, error)
+		golden string
+	}{
+		{"defectdojo", ToDefectDojo, "export_defectdojo.golden.json"},
+		{"checkmarx", ToCheckmarxXML, "export_checkmarx.golden.xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.export(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join("testdata", tt.golden))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from testdata/%s:\n%s", tt.golden, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package semantic
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+func TestVersionSatisfies(t *testing.T) {
+	tests := []struct {
+		version, constraint string
+		want                bool
+	}{
+		{"10.48.2", ">=9", true},
+		{"8.83.27", ">=9", false},
+		{"v6.3.0", ">=6.3", true},
+		{"6.2.14", ">=6.3", false},
+		{"5.4.36", "<5.5", true},
+		{"5.5.0", "<5.5", false},
+		{"6.4.1", ">=5.5 <7", true},
+		{"7.0.0", ">=5.5, <7", false},
+		{"5.8.0", "<6 || >=8", true},
+		{"6.1.0", "<6 || >=8", false},
+		{"6.4.1", "6.x", true},
+		{"6.4.1", "6.4", true},
+		{"7.0.0", "6", false},
+		{"dev-master", "<5.5", true}, // Unknown version
+		{"10.0.0", "", true},
+	}
+	for _, tt := range tests {
+		if got := common.VersionSatisfies(tt.version, tt.constraint); got != tt.want {
+			t.Errorf("VersionSatisfies(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+		}
+	}
+}
+
+func TestFrameworkVersionsFromLockFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "composer.json", `{"require": {"laravel/framework": "^10.0"}}`)
+	writeFile(t, dir, "composer.lock", `{
+  "packages": [
+    {"name": "laravel/framework", "version": "v10.48.2"},
+    {"name": "symfony/http-foundation", "version": "v6.4.4"}
+  ],
+  "packages-dev": []
+}`)
+	writeFile(t, dir, "app.php", `<?php
+$name = $request->string('name');
+echo $name;
+`)
+
+	tracer := New(DefaultConfig())
+	result, err := tracer.TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	laravel := result.Frameworks.Get("laravel")
+	if laravel == nil {
+		t.Fatalf("laravel not detected: %+v", result.Frameworks)
+	}
+	if laravel.Installed != "10.48.2" {
+		t.Errorf("installed laravel = %q, want 10.48.2", laravel.Installed)
+	}
+	if !tracer.ruleSet.IsInputMethodCall("$request->string('name')") {
+		t.Error("->string() (Laravel 9+) not matched on Laravel 10")
+	}
+	if tracer.ruleSet.IsInputMethodCall("$request->intersect(['a'])") {
+		t.Error("->intersect() (removed in Laravel 5.5) matched on Laravel 10")
+	}
+
+	// Config.FrameworkVersions overrides the lock file
+	config := DefaultConfig()
+	config.FrameworkVersions = map[string]string{"laravel": "5.4"}
+	tracer = New(config)
+	if _, err := tracer.TraceDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	if tracer.ruleSet.IsInputMethodCall("$request->string('name')") {
+		t.Error("->string() (Laravel 9+) matched on Laravel 5.4")
+	}
+	if !tracer.ruleSet.IsInputMethodCall("$request->intersect(['a'])") {
+		t.Error("->intersect() not matched on Laravel 5.4")
+	}
+
+	// Without a lock file every version's patterns apply
+	tracer = New(DefaultConfig())
+	if _, err := tracer.TraceDirectory(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if !tracer.ruleSet.IsInputMethodCall("$request->string('name')") || !tracer.ruleSet.IsInputMethodCall("$request->intersect(['a'])") {
+		t.Error("version-conditional patterns not matched without a known version")
+	}
+	if !phpPatterns.IsInputMethodCall("$request->string('name')") || !phpPatterns.IsInputMethodCall("$request->intersect(['a'])") {
+		t.Error("framework versions of a scan changed the built-in patterns")
+	}
+}
+
+func TestFrameworkVersionsPerTracer(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "app.php", `<?php
+$a = $request->string('name');
+$b = $request->intersect(['a']);
+`)
+
+	tests := []struct {
+		version           string
+		string, intersect bool
+	}{
+		{"5.4", false, true},
+		{"10.48.2", true, false},
+	}
+	tracers := make([]*Tracer, len(tests))
+	var wg sync.WaitGroup
+	for i, tt := range tests {
+		config := DefaultConfig()
+		config.FrameworkVersions = map[string]string{"laravel": tt.version}
+		tracers[i] = New(config)
+		wg.Add(1)
+		go func(tracer *Tracer) {
+			defer wg.Done()
+			if _, err := tracer.TraceDirectory(dir); err != nil {
+				t.Error(err)
+			}
+		}(tracers[i])
+	}
+	wg.Wait()
+
+	for i, tt := range tests {
+		if got := tracers[i].ruleSet.IsInputMethodCall("$request->string('name')"); got != tt.string {
+			t.Errorf("Laravel %s: ->string() matched = %v, want %v", tt.version, got, tt.string)
+		}
+		if got := tracers[i].ruleSet.IsInputMethodCall("$request->intersect(['a'])"); got != tt.intersect {
+			t.Errorf("Laravel %s: ->intersect() matched = %v, want %v", tt.version, got, tt.intersect)
+		}
+	}
+}
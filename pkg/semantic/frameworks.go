@@ -0,0 +1,152 @@
+package semantic
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/frameworks"
+)
+
+// maxEvidenceFiles caps the files listed as evidence per framework
+const maxEvidenceFiles = 20
+
+// FrameworkReport aggregates framework detection across the whole codebase
+// so users can verify which framework pattern packs were activated
+type FrameworkReport struct {
+	Frameworks []*DetectedFramework `json:"frameworks"`
+	Warnings   []string             `json:"warnings,omitempty"`
+}
+
+// DetectedFramework describes one framework detected in the codebase
+type DetectedFramework struct {
+	Name       string   `json:"name"`
+	Language   string   `json:"language"`
+	Confidence float64  `json:"confidence"`           // 0.0-1.0
+	FileCount  int      `json:"file_count"`           // Files whose imports/source triggered detection
+	Files      []string `json:"files,omitempty"`      // Sample of those files (capped)
+	Indicators []string `json:"indicators,omitempty"` // Project files that indicate the framework (e.g., artisan)
+	Manifest   string   `json:"manifest,omitempty"`   // Manifest declaring the framework (composer.json/package.json)
+	Version    string   `json:"version,omitempty"`    // Declared version constraint when derivable
+	Installed  string   `json:"installed,omitempty"`  // Version patterns are restricted to (lock file or Config.FrameworkVersions)
+	Patterns   int      `json:"patterns"`             // Registered input patterns for this framework
+}
+
+// Get returns the detected framework with the given name, or nil
+func (r *FrameworkReport) Get(name string) *DetectedFramework {
+	if r == nil {
+		return nil
+	}
+	for _, fw := range r.Frameworks {
+		if fw.Name == name {
+			return fw
+		}
+	}
+	return nil
+}
+
+// buildFrameworkReport aggregates per-file framework detection with project
+// indicators and manifest declarations into a codebase-level report
+func (t *Tracer) buildFrameworkReport(rootPath string) *FrameworkReport {
+	report := &FrameworkReport{}
+	byName := make(map[string]*DetectedFramework)
+
+	get := func(name, language string) *DetectedFramework {
+		fw, ok := byName[name]
+		if !ok {
+			fw = &DetectedFramework{Name: name, Language: language}
+			byName[name] = fw
+		}
+		return fw
+	}
+
+	// Evidence from per-file detection (imports and source markers)
+	t.mu.RLock()
+	filePaths := make([]string, 0, len(t.files))
+	for filePath := range t.files {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+	for _, filePath := range filePaths {
+		fileInfo := t.files[filePath]
+		if fileInfo.SymbolTable == nil || fileInfo.SymbolTable.Framework == "" {
+			continue
+		}
+		fw := get(fileInfo.SymbolTable.Framework, fileInfo.Language)
+		fw.FileCount++
+		if len(fw.Files) < maxEvidenceFiles {
+			fw.Files = append(fw.Files, filePath)
+		}
+	}
+	t.mu.RUnlock()
+
+	// Evidence from dependency manifests
+	for _, decl := range frameworks.DetectDeclaredFrameworks(rootPath) {
+		fw := get(decl.Framework, decl.Language)
+		fw.Manifest = decl.Manifest
+		fw.Version = decl.Version
+	}
+
+	for _, fw := range byName {
+		fw.Installed = t.frameworkVersions[fw.Name]
+		// Project indicators only corroborate; on their own they are too generic
+		fw.Indicators = frameworks.FindFrameworkIndicators(rootPath, fw.Name)
+		fw.Confidence = frameworkConfidence(fw)
+		fw.Patterns = sources.FrameworkPatternCount(fw.Name)
+		report.Frameworks = append(report.Frameworks, fw)
+
+		if fw.Patterns == 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"framework %q detected but no pattern pack is registered; its input APIs will not be recognized", fw.Name))
+		}
+	}
+
+	sort.Slice(report.Frameworks, func(i, j int) bool {
+		if report.Frameworks[i].Confidence != report.Frameworks[j].Confidence {
+			return report.Frameworks[i].Confidence > report.Frameworks[j].Confidence
+		}
+		return report.Frameworks[i].Name < report.Frameworks[j].Name
+	})
+	sort.Strings(report.Warnings)
+
+	return report
+}
+
+// frameworkConfidence scores detection evidence: source usage is the strongest
+// signal, a manifest declaration and project indicators corroborate it
+func frameworkConfidence(fw *DetectedFramework) float64 {
+	confidence := 0.0
+	if fw.FileCount > 0 {
+		confidence = 0.5 + 0.05*float64(fw.FileCount-1)
+		if confidence > 0.7 {
+			confidence = 0.7
+		}
+	}
+	if fw.Manifest != "" {
+		confidence += 0.4
+	}
+	if len(fw.Indicators) > 0 {
+		confidence += 0.1
+	}
+	if confidence > 1.0 {
+		confidence = 1.0
+	}
+	return confidence
+}
+
+// applyFrameworkVersions restricts framework patterns to the framework
+// versions installed in the codebase, so that APIs removed in those versions
+// are not matched and APIs added later only on versions that have them
+func (t *Tracer) applyFrameworkVersions(rootPath string) {
+	versions := make(map[string]string)
+	for _, installed := range frameworks.DetectInstalledFrameworks(rootPath) {
+		versions[installed.Framework] = installed.Version
+	}
+	for framework, version := range t.config.FrameworkVersions {
+		versions[framework] = version
+	}
+	t.frameworkVersions = versions
+	t.ruleSet.SetFrameworkVersions(versions)
+	t.analyzers = analyzersFor(t.ruleSet)
+	t.buildSubtreeRules()
+}
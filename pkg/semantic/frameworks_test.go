@@ -0,0 +1,117 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFrameworkReport(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"app", "src"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, dir, "composer.json", `{"require": {"laravel/framework": "^10.0"}}`)
+	writeFile(t, dir, "package.json", `{"dependencies": {"nuxt": "^3.8.0"}}`)
+	writeFile(t, dir, "artisan", "#!/usr/bin/env php\n")
+	writeFile(t, dir, "app/Show.php", `<?php
+use Illuminate\Http\Request;
+class Show { function __invoke(Request $r) { return $r->input('id'); } }
+`)
+	writeFile(t, dir, "app/Search.php", `<?php
+use Illuminate\Support\Facades\Input;
+$q = Input::get('q');
+`)
+	writeFile(t, dir, "src/Legacy.php", `<?php
+use Symfony\Component\HttpFoundation\Request;
+$q = Request::createFromGlobals()->query->get('q');
+`)
+
+	result, err := New(DefaultConfig()).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := result.Frameworks
+
+	var order []string
+	for _, fw := range report.Frameworks {
+		order = append(order, fw.Name)
+	}
+	if want := []string{"laravel", "symfony", "nuxt"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("frameworks = %v, want %v (by confidence)", order, want)
+	}
+
+	tests := []struct {
+		name       string
+		confidence float64
+		files      []string
+		indicators []string
+		manifest   string
+		version    string
+		patterns   bool
+	}{
+		{"laravel", 1.0, []string{"app/Search.php", "app/Show.php"}, []string{"artisan"}, "composer.json", "^10.0", true},
+		{"symfony", 0.5, []string{"src/Legacy.php"}, nil, "", "", true}, // Imports only
+		{"nuxt", 0.4, nil, nil, "package.json", "^3.8.0", false},        // Declared, no pattern pack
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fw := report.Get(tt.name)
+			if fw == nil {
+				t.Fatalf("%s not in report", tt.name)
+			}
+			var files []string
+			for _, f := range fw.Files {
+				rel, _ := filepath.Rel(dir, f)
+				files = append(files, filepath.ToSlash(rel))
+			}
+			if fw.Confidence != tt.confidence {
+				t.Errorf("confidence = %v, want %v", fw.Confidence, tt.confidence)
+			}
+			if fw.FileCount != len(tt.files) || !reflect.DeepEqual(files, tt.files) {
+				t.Errorf("files = %d %v, want %v", fw.FileCount, files, tt.files)
+			}
+			if !reflect.DeepEqual(fw.Indicators, tt.indicators) {
+				t.Errorf("indicators = %v, want %v", fw.Indicators, tt.indicators)
+			}
+			if fw.Manifest != tt.manifest || fw.Version != tt.version {
+				t.Errorf("manifest = %q %q, want %q %q", fw.Manifest, fw.Version, tt.manifest, tt.version)
+			}
+			if (fw.Patterns > 0) != tt.patterns {
+				t.Errorf("patterns = %d, want registered %v", fw.Patterns, tt.patterns)
+			}
+		})
+	}
+
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], `"nuxt"`) {
+		t.Errorf("warnings = %q, want one for nuxt", report.Warnings)
+	}
+	if report.Get("express") != nil {
+		t.Error("express reported without evidence")
+	}
+}
+
+func TestFrameworkConfidence(t *testing.T) {
+	tests := []struct {
+		name string
+		fw   DetectedFramework
+		want float64
+	}{
+		{"one file", DetectedFramework{FileCount: 1}, 0.5},
+		{"many files", DetectedFramework{FileCount: 30}, 0.7},
+		{"manifest only", DetectedFramework{Manifest: "composer.json"}, 0.4},
+		{"indicator only", DetectedFramework{Indicators: []string{"artisan"}}, 0.1},
+		{"all evidence", DetectedFramework{FileCount: 3, Manifest: "composer.json", Indicators: []string{"artisan"}}, 1.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := frameworkConfidence(&tt.fw); got < tt.want-1e-9 || got > tt.want+1e-9 {
+				t.Errorf("frameworkConfidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package semantic
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// fuzzLocations maps the HTTP source types to where a fuzzer places the
+// parameter in a request; other source types are not fuzzable over HTTP
+var fuzzLocations = map[types.SourceType]string{
+	types.SourceHTTPGet:     "query",
+	types.SourceHTTPPost:    "body",
+	types.SourceHTTPBody:    "body",
+	types.SourceHTTPJSON:    "json",
+	types.SourceHTTPHeader:  "header",
+	types.SourceHTTPCookie:  "cookie",
+	types.SourceHTTPPath:    "path",
+	types.SourceHTTPFile:    "file",
+	types.SourceHTTPRequest: "query_or_body",
+}
+
+// FuzzParam is a request parameter read by the code behind a route, with
+// what the code reveals about the values it expects
+type FuzzParam struct {
+	Name     string          `json:"name"`
+	In       string          `json:"in"` // query, body, json, header, cookie, path, file or query_or_body
+	Type     types.ParamType `json:"type,omitempty"`
+	Pattern  string          `json:"pattern,omitempty"`
+	Examples []string        `json:"examples,omitempty"` // Compared literals and getter defaults
+}
+
+// FuzzRoute is the fuzz dictionary of one entry point. Parameters read in
+// files no entry point reaches are listed per file, without a route.
+type FuzzRoute struct {
+	Route  string      `json:"route,omitempty"`
+	File   string      `json:"file"`
+	Params []FuzzParam `json:"params"`
+}
+
+// FuzzDictionary builds a fuzz dictionary per entry point from the named
+// HTTP input sources reachable from it, sorted by route and parameter
+func FuzzDictionary(r *TraceResult) []FuzzRoute {
+	byRoute := make(map[string]*FuzzRoute)
+	params := make(map[string]map[string]*FuzzParam) // Route key -> location:name -> param
+	add := func(key string, route FuzzRoute, src *types.FlowNode, in string) {
+		if byRoute[key] == nil {
+			byRoute[key] = &route
+			params[key] = make(map[string]*FuzzParam)
+		}
+		name := fuzzParamName(src)
+		p := params[key][in+":"+name]
+		if p == nil {
+			p = &FuzzParam{Name: name, In: in}
+			params[key][in+":"+name] = p
+		}
+		if c := src.Constraint; c != nil {
+			if p.Type == "" || p.Type == types.ParamFreeString {
+				p.Type = c.Type
+			}
+			if p.Pattern == "" {
+				p.Pattern = c.Pattern
+			}
+			p.Examples = appendUnique(p.Examples, c.Values...)
+			if c.Default != "" {
+				p.Examples = appendUnique(p.Examples, c.Default)
+			}
+		}
+	}
+
+	for _, src := range r.Sources {
+		in, ok := fuzzLocations[src.SourceType]
+		if !ok || src.SourceKey == "" {
+			continue
+		}
+		if _, dead := src.Metadata["unreachable"]; dead {
+			continue
+		}
+		eps := r.EntryPointsForSource(src)
+		if len(eps) == 0 {
+			add("file:"+src.FilePath, FuzzRoute{File: src.FilePath}, src, in)
+		}
+		for _, ep := range eps {
+			add("route:"+ep.Route, FuzzRoute{Route: ep.Route, File: ep.FilePath}, src, in)
+		}
+	}
+
+	routes := make([]FuzzRoute, 0, len(byRoute))
+	for key, route := range byRoute {
+		for _, p := range params[key] {
+			route.Params = append(route.Params, *p)
+		}
+		sort.Slice(route.Params, func(i, j int) bool {
+			if route.Params[i].In != route.Params[j].In {
+				return route.Params[i].In < route.Params[j].In
+			}
+			return route.Params[i].Name < route.Params[j].Name
+		})
+		routes = append(routes, *route)
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Route != routes[j].Route {
+			return routes[i].Route < routes[j].Route
+		}
+		return routes[i].File < routes[j].File
+	})
+	return routes
+}
+
+// fuzzParamName returns the name a request uses for a source's parameter:
+// HTTP_X_API_KEY read from $_SERVER is sent as the X-Api-Key header
+func fuzzParamName(src *types.FlowNode) string {
+	key := src.SourceKey
+	if src.SourceType != types.SourceHTTPHeader || !strings.HasPrefix(key, "HTTP_") {
+		return key
+	}
+	words := strings.Split(strings.ToLower(strings.TrimPrefix(key, "HTTP_")), "_")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, "-")
+}
+
+// appendUnique appends the values not already in list
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// ToFuzzDictionary exports the fuzz dictionary of every route as JSON
+func ToFuzzDictionary(r *TraceResult) (string, error) {
+	output := struct {
+		Routes []FuzzRoute `json:"routes"`
+	}{Routes: FuzzDictionary(r)}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ToFuzzWordlist exports the parameter names of a route ("" for all routes)
+// as a wordlist, one name per line, for ffuf (-w names.txt:FUZZ) or a Burp
+// Intruder payload list
+func ToFuzzWordlist(r *TraceResult, route string) string {
+	return fuzzWordlist(r, route, func(p FuzzParam) []string { return []string{p.Name} })
+}
+
+// ToFuzzValueWordlist exports the example values of a route's parameters
+// ("" for all routes) as a wordlist, one value per line
+func ToFuzzValueWordlist(r *TraceResult, route string) string {
+	return fuzzWordlist(r, route, func(p FuzzParam) []string { return p.Examples })
+}
+
+// fuzzWordlist returns the sorted, deduplicated words of the matching routes' parameters
+func fuzzWordlist(r *TraceResult, route string, words func(FuzzParam) []string) string {
+	seen := make(map[string]bool)
+	var list []string
+	for _, fr := range FuzzDictionary(r) {
+		if route != "" && fr.Route != route {
+			continue
+		}
+		for _, p := range fr.Params {
+			for _, w := range words(p) {
+				// A word with a line break would split into two payloads
+				if w != "" && !seen[w] && !strings.ContainsAny(w, "\r\n") {
+					seen[w] = true
+					list = append(list, w)
+				}
+			}
+		}
+	}
+	sort.Strings(list)
+	if len(list) == 0 {
+		return ""
+	}
+	return strings.Join(list, "\n") + "\n"
+}
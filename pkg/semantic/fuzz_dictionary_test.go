@@ -0,0 +1,51 @@
+package semantic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestFuzzDictionary(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "search.php", `<?php
+require 'lib.php';
+$page = $_GET['page'];
+if ($page == 'home' || $page == 'about') {
+    show($page);
+}
+$n = (int)$_POST['n'];
+$key = $_SERVER['HTTP_X_API_KEY'];
+$argv1 = $argv[1];
+`)
+	writeFile(t, dir, "lib.php", `<?php
+function token() { return $_COOKIE['token']; }
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := FuzzDictionary(result)
+	if len(routes) != 1 || routes[0].Route != "/search.php" {
+		t.Fatalf("routes = %+v, want /search.php only", routes)
+	}
+	want := []FuzzParam{
+		{Name: "n", In: "body", Type: types.ParamInt},
+		{Name: "token", In: "cookie", Type: types.ParamFreeString},
+		{Name: "X-Api-Key", In: "header", Type: types.ParamFreeString},
+		{Name: "page", In: "query", Type: types.ParamEnum, Examples: []string{"home", "about"}},
+	}
+	if !reflect.DeepEqual(routes[0].Params, want) {
+		t.Errorf("params = %+v, want %+v", routes[0].Params, want)
+	}
+
+	if got, want := ToFuzzWordlist(result, ""), "X-Api-Key\nn\npage\ntoken\n"; got != want {
+		t.Errorf("name wordlist = %q, want %q", got, want)
+	}
+	if got, want := ToFuzzValueWordlist(result, "/search.php"), "about\nhome\n"; got != want {
+		t.Errorf("value wordlist = %q, want %q", got, want)
+	}
+}
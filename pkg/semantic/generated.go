@@ -0,0 +1,102 @@
+package semantic
+
+import (
+	"bytes"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+)
+
+// GeneratedKind classifies a file as hand-written, minified or generated
+type GeneratedKind string
+
+const (
+	GeneratedNone     GeneratedKind = ""
+	GeneratedMinified GeneratedKind = "minified"
+	GeneratedCode     GeneratedKind = "generated"
+)
+
+// GeneratedFileMode controls how minified and generated files are analyzed
+type GeneratedFileMode string
+
+const (
+	// GeneratedFlag analyzes minified/generated files fully but reports them
+	// and marks their sources (default)
+	GeneratedFlag GeneratedFileMode = "flag"
+	// GeneratedSkip skips minified/generated files entirely
+	GeneratedSkip GeneratedFileMode = "skip"
+	// GeneratedLight only collects input sources; no symbol table, assignments or calls
+	GeneratedLight GeneratedFileMode = "light"
+	// GeneratedFull analyzes minified/generated files like any other file
+	GeneratedFull GeneratedFileMode = "full"
+)
+
+// Minified-file heuristics
+const (
+	minifiedMinBytes      = 2048 // Small files are never classified as minified
+	minifiedAvgLineLength = 300  // Average characters per line
+	minifiedLongLine      = 1000 // A line at least this long counts as "long"
+	minifiedLongLineRatio = 0.5  // Share of content that sits on long lines
+	generatedHeaderBytes  = 1024 // Only the file header is checked for markers
+)
+
+// ClassifyGeneratedFile classifies a file from its path and content: generated
+// locations (compiled views, caches, proxies, bundles) and `@generated`-style
+// header markers mark generated code; long average lines or content packed on
+// a few very long lines mark minified code
+func ClassifyGeneratedFile(path string, content []byte) GeneratedKind {
+	header := content
+	if len(header) > generatedHeaderBytes {
+		header = header[:generatedHeaderBytes]
+	}
+	if sources.HasGeneratedMarker(string(header)) {
+		return GeneratedCode
+	}
+
+	if len(content) >= minifiedMinBytes {
+		lines := bytes.Count(content, []byte("\n")) + 1
+		if len(content)/lines >= minifiedAvgLineLength {
+			return GeneratedMinified
+		}
+		longBytes := 0
+		for _, line := range bytes.Split(content, []byte("\n")) {
+			if len(line) >= minifiedLongLine {
+				longBytes += len(line)
+			}
+		}
+		if float64(longBytes)/float64(len(content)) >= minifiedLongLineRatio {
+			return GeneratedMinified
+		}
+	}
+
+	if sources.IsGeneratedPath(path) {
+		return GeneratedCode
+	}
+	return GeneratedNone
+}
+
+// generatedFileMode returns the configured mode, defaulting to GeneratedFlag
+func (t *Tracer) generatedFileMode() GeneratedFileMode {
+	if t.config.GeneratedFiles == "" {
+		return GeneratedFlag
+	}
+	return t.config.GeneratedFiles
+}
+
+// analyzesGeneratedFully reports whether minified/generated files are analyzed
+// like hand-written ones
+func (t *Tracer) analyzesGeneratedFully() bool {
+	mode := t.generatedFileMode()
+	return mode == GeneratedFlag || mode == GeneratedFull
+}
+
+// flagGeneratedSources marks sources found in a minified/generated file with
+// the file's kind, so findings from such code can be told apart
+func flagGeneratedSources(kind GeneratedKind, sources []*types.FlowNode) {
+	for _, src := range sources {
+		if src.Metadata == nil {
+			src.Metadata = make(map[string]interface{})
+		}
+		src.Metadata["generated"] = string(kind)
+	}
+}
@@ -0,0 +1,100 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestClassifyGeneratedFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    GeneratedKind
+	}{
+		{"hand-written", "app/Controller.php", "<?php\necho $_GET['q'];\n", GeneratedNone},
+		{"generated directory", "app/generated/Report.php", "<?php\nclass Report {}\n", GeneratedNone},
+		{"compiled view", "storage/framework/views/3f2a.php", "<?php echo e($name); ?>\n", GeneratedCode},
+		{"protobuf output", "api/user.pb.go", "package api\n", GeneratedCode},
+		{"@generated", "src/Model.php", "<?php\n// @generated by schema-tool\n", GeneratedCode},
+		{"Go marker", "mock.go", "// Code generated by mockgen. DO NOT EDIT.\npackage mock\n", GeneratedCode},
+		{"docblock", "Client.php", "<?php\n/**\n * This file was auto-generated by the OpenAPI generator.\n */\n", GeneratedCode},
+		{"do not edit notice", "config.php", "<?php\n# Do not edit: values are read at startup\n", GeneratedNone},
+		{"string literal", "admin.php", "<?php\n$note = 'this file was generated, do not edit';\n", GeneratedNone},
+		{"marker past header", "big.php", "<?php\n" + strings.Repeat("$a = 1;\n", 200) + "// @generated\n", GeneratedNone},
+		{"minified", "app.js", strings.Repeat("var a=1;", 400), GeneratedMinified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyGeneratedFile(tt.path, []byte(tt.content)); got != tt.want {
+				t.Errorf("ClassifyGeneratedFile(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratedFileModes(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "bootstrap", "cache"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "bootstrap/cache/routes.php", `<?php
+$route = $_GET['route'];
+`)
+	writeFile(t, dir, "index.php", `<?php
+$id = $_GET['id'];
+`)
+
+	tests := []struct {
+		mode      GeneratedFileMode
+		keys      string
+		flagged   bool
+		skipped   int
+		generated int
+	}{
+		{"", "id,route", true, 0, 1}, // Flagged by default
+		{GeneratedSkip, "id", false, 1, 0},
+		{GeneratedLight, "id,route", false, 0, 0},
+		{GeneratedFull, "id,route", false, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			config := DefaultConfig()
+			config.GeneratedFiles = tt.mode
+			result, err := New(config).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var keys []string
+			flagged := false
+			for _, src := range result.Sources {
+				keys = append(keys, src.SourceKey)
+				if kind, ok := src.Metadata["generated"]; ok {
+					if src.SourceKey != "route" || kind != string(GeneratedCode) {
+						t.Errorf("source %s flagged as %v", src.SourceKey, kind)
+					}
+					flagged = true
+				}
+			}
+			sort.Strings(keys)
+			if got := strings.Join(keys, ","); got != tt.keys {
+				t.Errorf("source keys = %s, want %s", got, tt.keys)
+			}
+			if flagged != tt.flagged {
+				t.Errorf("route flagged = %v, want %v", flagged, tt.flagged)
+			}
+			if got := result.WarningCounts[types.WarningFileSkipped]; got != tt.skipped {
+				t.Errorf("file_skipped warnings = %d, want %d", got, tt.skipped)
+			}
+			if got := result.WarningCounts[types.WarningGeneratedFile]; got != tt.generated {
+				t.Errorf("generated_file warnings = %d, want %d", got, tt.generated)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package semantic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/parser/languages"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// reportGrammarGaps warns about modern constructs the grammars of the parsed
+// languages cannot parse (such code shows up as silent ERROR nodes) and about
+// a bundled grammar version differing from Config.GrammarVersion
+func (t *Tracer) reportGrammarGaps() {
+	bundled := languages.GrammarVersion()
+	if t.config.GrammarVersion != "" && t.config.GrammarVersion != bundled {
+		t.warn(types.WarningGrammarMismatch, "", 0, "",
+			fmt.Sprintf("bundled grammar version %s differs from pinned version %s", bundled, t.config.GrammarVersion))
+	}
+
+	langs := make([]string, 0, len(t.stats.ByLanguage))
+	for lang, stats := range t.stats.ByLanguage {
+		if stats.Files > 0 {
+			langs = append(langs, lang)
+		}
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		caps := t.stats.Grammars[lang]
+		if caps == nil || len(caps.Unsupported) == 0 {
+			continue
+		}
+		message := fmt.Sprintf("%s grammar %s cannot parse: %s", lang, caps.Version, strings.Join(caps.Unsupported, ", "))
+		t.warn(types.WarningGrammarUnsupported, "", 0, "", message)
+		if t.config.Verbose {
+			fmt.Printf("  Warning: %s\n", message)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package semantic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/parser/languages"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestGrammarCapabilities(t *testing.T) {
+	result, err := New(nil).ParseOnly(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	php := result.Stats.Grammars["php"]
+	if php == nil {
+		t.Fatal("no php grammar capabilities")
+	}
+	if php.Version != languages.GrammarVersion() {
+		t.Errorf("version = %q, want %q", php.Version, languages.GrammarVersion())
+	}
+	for _, unsupported := range php.Unsupported {
+		if strings.HasPrefix(unsupported, "match expression") || strings.HasPrefix(unsupported, "enums") {
+			t.Errorf("php grammar reported as unable to parse %s", unsupported)
+		}
+	}
+}
+
+func TestGrammarWarnings(t *testing.T) {
+	bundled := languages.GrammarVersion()
+	empty, err := New(nil).ParseOnly(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	phpGaps := 0
+	if len(empty.Stats.Grammars["php"].Unsupported) > 0 {
+		phpGaps = 1
+	}
+	tests := []struct {
+		name            string
+		file            string
+		pinned          string
+		wantUnsupported int
+		wantMismatch    int
+	}{
+		{"php file", "index.php", "", phpGaps, 0},
+		{"no php file", "app.js", "", 0, 0},
+		{"pinned bundled version", "app.js", bundled, 0, 0},
+		{"pinned other version", "app.js", "v0.0.0-pinned", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			code := "let x = 1;\n"
+			if strings.HasSuffix(tt.file, ".php") {
+				code = "<?php\n$x = 1;\n"
+			}
+			writeFile(t, dir, tt.file, code)
+			config := DefaultConfig()
+			config.GrammarVersion = tt.pinned
+			result, err := New(config).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := result.WarningCounts[types.WarningGrammarUnsupported]; got != tt.wantUnsupported {
+				t.Errorf("grammar_unsupported = %d, want %d", got, tt.wantUnsupported)
+			}
+			if got := result.WarningCounts[types.WarningGrammarMismatch]; got != tt.wantMismatch {
+				t.Errorf("grammar_mismatch = %d, want %d", got, tt.wantMismatch)
+			}
+		})
+	}
+}
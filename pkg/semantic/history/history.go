@@ -0,0 +1,352 @@
+// Package history persists the input sources of each scan in an embedded
+// SQLite database and answers questions across runs: when a source first
+// appeared, when its flow path changed, and how flow counts trend over time.
+//
+// Sources are keyed by a fingerprint that does not include line numbers, so a
+// source keeps its identity when unrelated code above it is edited.
+package history
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// ErrNotFound means a fingerprint has never been recorded for the codebase
+var ErrNotFound = errors.New("source not found in history")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS scans (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	root             TEXT    NOT NULL,
+	label            TEXT    NOT NULL DEFAULT '',
+	scanned_at       INTEGER NOT NULL,
+	sources          INTEGER NOT NULL,
+	flows            INTEGER NOT NULL,
+	cross_file_flows INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS scans_root ON scans (root, scanned_at);
+CREATE TABLE IF NOT EXISTS sources (
+	root        TEXT NOT NULL,
+	fingerprint TEXT NOT NULL,
+	file_path   TEXT NOT NULL,
+	source_type TEXT NOT NULL,
+	source_key  TEXT NOT NULL,
+	name        TEXT NOT NULL,
+	PRIMARY KEY (root, fingerprint)
+);
+CREATE TABLE IF NOT EXISTS sightings (
+	scan_id     INTEGER NOT NULL REFERENCES scans (id),
+	fingerprint TEXT    NOT NULL,
+	line        INTEGER NOT NULL,
+	path_hash   TEXT    NOT NULL,
+	path        TEXT    NOT NULL,
+	PRIMARY KEY (scan_id, fingerprint)
+);
+CREATE INDEX IF NOT EXISTS sightings_fingerprint ON sightings (fingerprint, scan_id);
+`
+
+// DB is a scan history database
+type DB struct {
+	db *sql.DB
+}
+
+// Scan is one recorded scan of a codebase
+type Scan struct {
+	ID             int64     `json:"id"`
+	Root           string    `json:"root"`
+	Label          string    `json:"label,omitempty"`
+	ScannedAt      time.Time `json:"scanned_at"`
+	Sources        int       `json:"sources"`
+	Flows          int       `json:"flows"`
+	CrossFileFlows int       `json:"cross_file_flows"`
+}
+
+// Source is a fingerprinted input source with the scans it was seen in
+type Source struct {
+	Fingerprint string           `json:"fingerprint"`
+	FilePath    string           `json:"file_path"` // Relative to the codebase root
+	SourceType  types.SourceType `json:"source_type"`
+	SourceKey   string           `json:"source_key,omitempty"`
+	Name        string           `json:"name"`
+	FirstSeen   Scan             `json:"first_seen"`
+	LastSeen    Scan             `json:"last_seen"`
+	Scans       int              `json:"scans"` // Number of scans the source was seen in
+}
+
+// PathChange records a scan in which a source's flow path differed from the
+// previous scan it was seen in (the first sighting counts as a change)
+type PathChange struct {
+	Scan Scan     `json:"scan"`
+	Line int      `json:"line"`
+	Path []string `json:"path"` // Steps from the source to the flow endpoint
+}
+
+// Open opens (creating if needed) the history database at path
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("open history database: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create history schema: %w", err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close closes the database
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// RecordScan stores the sources of a trace result of the codebase at root,
+// each with its current flow path
+func (d *DB) RecordScan(root, label string, r *semantic.TraceResult, scannedAt time.Time) (*Scan, error) {
+	root = cleanRoot(root)
+	scan := &Scan{Root: root, Label: label, ScannedAt: scannedAt.UTC(), Sources: len(r.Sources)}
+	if r.Stats != nil {
+		scan.Flows = r.Stats.FlowsTraced
+		scan.CrossFileFlows = r.Stats.CrossFileFlows
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("record scan: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO scans (root, label, scanned_at, sources, flows, cross_file_flows) VALUES (?, ?, ?, ?, ?, ?)`,
+		root, label, scan.ScannedAt.UnixNano(), scan.Sources, scan.Flows, scan.CrossFileFlows)
+	if err != nil {
+		return nil, fmt.Errorf("record scan: %w", err)
+	}
+	if scan.ID, err = res.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("record scan: %w", err)
+	}
+
+	occurrences := make(map[string]int)
+	for _, rec := range semantic.InputFlowRecords(r) {
+		src := rec.Source
+		rel := relPath(root, src.FilePath)
+		base := Fingerprint(rel, src)
+		fingerprint := fmt.Sprintf("%s-%d", base, occurrences[base])
+		occurrences[base]++
+
+		path := make([]string, 0, len(rec.Steps))
+		for _, step := range rec.Steps {
+			path = append(path, pathStep(root, step))
+		}
+		pathJSON, err := json.Marshal(path)
+		if err != nil {
+			return nil, fmt.Errorf("record scan: %w", err)
+		}
+
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO sources (root, fingerprint, file_path, source_type, source_key, name) VALUES (?, ?, ?, ?, ?, ?)`,
+			root, fingerprint, rel, string(src.SourceType), src.SourceKey, src.Name); err != nil {
+			return nil, fmt.Errorf("record source %s: %w", fingerprint, err)
+		}
+		if _, err := tx.Exec(`INSERT INTO sightings (scan_id, fingerprint, line, path_hash, path) VALUES (?, ?, ?, ?, ?)`,
+			scan.ID, fingerprint, src.Line, hashString(string(pathJSON)), string(pathJSON)); err != nil {
+			return nil, fmt.Errorf("record source %s: %w", fingerprint, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("record scan: %w", err)
+	}
+	return scan, nil
+}
+
+// Scans returns the recorded scans of the codebase at root, oldest first.
+// The per-scan source and flow counts form the trend over time.
+func (d *DB) Scans(root string) ([]Scan, error) {
+	rows, err := d.db.Query(`SELECT id, root, label, scanned_at, sources, flows, cross_file_flows FROM scans WHERE root = ? ORDER BY scanned_at, id`, cleanRoot(root))
+	if err != nil {
+		return nil, fmt.Errorf("query scans: %w", err)
+	}
+	defer rows.Close()
+
+	var scans []Scan
+	for rows.Next() {
+		scan, err := scanRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("query scans: %w", err)
+		}
+		scans = append(scans, scan)
+	}
+	return scans, rows.Err()
+}
+
+// Sources returns every source recorded for the codebase at root with the
+// scans it was first and last seen in, ordered by first appearance
+func (d *DB) Sources(root string) ([]Source, error) {
+	root = cleanRoot(root)
+	scans, err := d.scansByID(root)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.Query(`
+		SELECT s.fingerprint, s.file_path, s.source_type, s.source_key, s.name,
+		       MIN(g.scan_id), MAX(g.scan_id), COUNT(*)
+		FROM sources s JOIN sightings g ON g.fingerprint = s.fingerprint
+		JOIN scans c ON c.id = g.scan_id AND c.root = s.root
+		WHERE s.root = ?
+		GROUP BY s.fingerprint
+		ORDER BY MIN(g.scan_id), s.file_path, s.fingerprint`, root)
+	if err != nil {
+		return nil, fmt.Errorf("query sources: %w", err)
+	}
+	defer rows.Close()
+
+	var sources []Source
+	for rows.Next() {
+		var src Source
+		var sourceType string
+		var first, last int64
+		if err := rows.Scan(&src.Fingerprint, &src.FilePath, &sourceType, &src.SourceKey, &src.Name, &first, &last, &src.Scans); err != nil {
+			return nil, fmt.Errorf("query sources: %w", err)
+		}
+		src.SourceType = types.SourceType(sourceType)
+		src.FirstSeen, src.LastSeen = scans[first], scans[last]
+		sources = append(sources, src)
+	}
+	return sources, rows.Err()
+}
+
+// Source returns one source of the codebase at root by fingerprint
+func (d *DB) Source(root, fingerprint string) (*Source, error) {
+	sources, err := d.Sources(root)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sources {
+		if sources[i].Fingerprint == fingerprint {
+			return &sources[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", fingerprint, ErrNotFound)
+}
+
+// PathChanges returns the scans in which the flow path of a source changed,
+// oldest first
+func (d *DB) PathChanges(root, fingerprint string) ([]PathChange, error) {
+	root = cleanRoot(root)
+	scans, err := d.scansByID(root)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.db.Query(`
+		SELECT g.scan_id, g.line, g.path_hash, g.path
+		FROM sightings g JOIN scans c ON c.id = g.scan_id
+		WHERE c.root = ? AND g.fingerprint = ?
+		ORDER BY c.scanned_at, c.id`, root, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("query path changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []PathChange
+	lastHash := ""
+	for rows.Next() {
+		var scanID int64
+		var line int
+		var hash, pathJSON string
+		if err := rows.Scan(&scanID, &line, &hash, &pathJSON); err != nil {
+			return nil, fmt.Errorf("query path changes: %w", err)
+		}
+		if hash == lastHash {
+			continue
+		}
+		lastHash = hash
+		change := PathChange{Scan: scans[scanID], Line: line}
+		if err := json.Unmarshal([]byte(pathJSON), &change.Path); err != nil {
+			return nil, fmt.Errorf("decode path of %s: %w", fingerprint, err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%s: %w", fingerprint, ErrNotFound)
+	}
+	return changes, nil
+}
+
+// Fingerprint identifies a source independently of its line: the file path
+// relative to the codebase root, source type, key, name and snippet.
+// RecordScan appends an occurrence index for identical sources in one file.
+func Fingerprint(relPath string, src *types.FlowNode) string {
+	return hashString(strings.Join([]string{
+		filepath.ToSlash(relPath),
+		string(src.SourceType),
+		src.SourceKey,
+		src.Name,
+		strings.Join(strings.Fields(src.Snippet), " "),
+	}, "\x00"))[:16]
+}
+
+// scansByID returns the scans of root keyed by ID
+func (d *DB) scansByID(root string) (map[int64]Scan, error) {
+	scans, err := d.Scans(root)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]Scan, len(scans))
+	for _, scan := range scans {
+		byID[scan.ID] = scan
+	}
+	return byID, nil
+}
+
+// scanRow reads a scans row
+func scanRow(rows *sql.Rows) (Scan, error) {
+	var scan Scan
+	var scannedAt int64
+	err := rows.Scan(&scan.ID, &scan.Root, &scan.Label, &scannedAt, &scan.Sources, &scan.Flows, &scan.CrossFileFlows)
+	scan.ScannedAt = time.Unix(0, scannedAt).UTC()
+	return scan, err
+}
+
+// pathStep describes a flow step without its line, so that moving code does
+// not count as a path change
+func pathStep(root string, node types.FlowNode) string {
+	return fmt.Sprintf("%s %s %s", relPath(root, node.FilePath), node.Type, node.Name)
+}
+
+// relPath returns filePath relative to root, or filePath if it is outside root
+func relPath(root, filePath string) string {
+	rel, err := filepath.Rel(root, filePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(filePath)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// cleanRoot normalizes the codebase root used as the history key
+func cleanRoot(root string) string {
+	if abs, err := filepath.Abs(root); err == nil {
+		return abs
+	}
+	return filepath.Clean(root)
+}
+
+// hashString returns the hex SHA-256 of s
+func hashString(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
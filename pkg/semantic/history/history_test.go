@@ -0,0 +1,151 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+)
+
+// recordScans scans dir once per version of index.php and records each scan
+func recordScans(t *testing.T, db *DB, dir string, versions []string) []*Scan {
+	t.Helper()
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	var scans []*Scan
+	for i, code := range versions {
+		if err := os.WriteFile(filepath.Join(dir, "index.php"), []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		result, err := semantic.New(nil).TraceDirectory(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scan, err := db.RecordScan(dir, fmt.Sprintf("v%d", i+1), result, start.Add(time.Duration(i)*24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scans = append(scans, scan)
+	}
+	return scans
+}
+
+func TestHistory(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	dir := t.TempDir()
+	scans := recordScans(t, db, dir, []string{
+		`<?php
+$id = $_GET['id'];
+`,
+		// Lines shift, $id flows further and a POST source appears
+		`<?php
+// Header
+$name = $_POST['name'];
+$id = $_GET['id'];
+$copy = $id;
+`,
+		`<?php
+$id = $_GET['id'];
+$copy = $id;
+$again = $_GET['id'];
+`,
+	})
+
+	recorded, err := db.Scans(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != 3 {
+		t.Fatalf("scans = %+v, want 3", recorded)
+	}
+	for i, scan := range recorded {
+		if scan.ID != scans[i].ID || scan.Label != scans[i].Label || !scan.ScannedAt.Equal(scans[i].ScannedAt) || scan.Sources != scans[i].Sources {
+			t.Errorf("scan %d = %+v, want %+v", i, scan, *scans[i])
+		}
+	}
+
+	sources, err := db.Sources(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		first, last int64
+		count       int
+	}{
+		{"$_GET['id']", scans[0].ID, scans[2].ID, 3}, // Same fingerprint across line shifts
+		{"$_POST['name']", scans[1].ID, scans[1].ID, 1},
+		{"$_GET['id'] (second)", scans[2].ID, scans[2].ID, 1}, // Identical source further down
+	}
+	if len(sources) != len(tests) {
+		t.Fatalf("sources = %+v, want %d", sources, len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := sources[i]
+			if src.FilePath != "index.php" || src.FirstSeen.ID != tt.first || src.LastSeen.ID != tt.last || src.Scans != tt.count {
+				t.Errorf("source = %+v, want first %d, last %d, %d scans", src, tt.first, tt.last, tt.count)
+			}
+		})
+	}
+	id := sources[0]
+	if !strings.HasSuffix(id.Fingerprint, "-0") || sources[2].Fingerprint != strings.TrimSuffix(id.Fingerprint, "-0")+"-1" {
+		t.Errorf("fingerprints = %s, %s, want occurrences -0 and -1 of one base", id.Fingerprint, sources[2].Fingerprint)
+	}
+	if got, err := db.Source(dir, id.Fingerprint); err != nil || got.Fingerprint != id.Fingerprint {
+		t.Errorf("Source(%s) = %+v, %v", id.Fingerprint, got, err)
+	}
+
+	// The path of $_GET['id'] changed in the second scan only; line moves do not count
+	changes, err := db.PathChanges(dir, id.Fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Scan.ID != scans[0].ID || changes[1].Scan.ID != scans[1].ID {
+		t.Fatalf("path changes = %+v, want scans %d and %d", changes, scans[0].ID, scans[1].ID)
+	}
+	if changes[0].Line != 2 || changes[1].Line != 4 {
+		t.Errorf("lines = %d, %d, want 2, 4", changes[0].Line, changes[1].Line)
+	}
+	if len(changes[1].Path) <= len(changes[0].Path) {
+		t.Errorf("paths = %q then %q, want the second one longer", changes[0].Path, changes[1].Path)
+	}
+
+	if _, err := db.PathChanges(dir, "0123456789abcdef-0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PathChanges(unknown) = %v, want ErrNotFound", err)
+	}
+	if _, err := db.Source(dir, "0123456789abcdef-0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Source(unknown) = %v, want ErrNotFound", err)
+	}
+	if other, err := db.Sources(t.TempDir()); err != nil || len(other) != 0 {
+		t.Errorf("sources of another root = %+v, %v, want none", other, err)
+	}
+}
+
+func TestWriteTrendChart(t *testing.T) {
+	day := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	scans := []Scan{
+		{ScannedAt: day, Label: "v1", Flows: 2, Sources: 1},
+		{ScannedAt: day.Add(24 * time.Hour), Flows: 4, Sources: 3},
+		{ScannedAt: day.Add(48 * time.Hour), Flows: 0, Sources: 0},
+	}
+	var sb strings.Builder
+	if err := WriteTrendChart(&sb, scans, 4); err != nil {
+		t.Fatal(err)
+	}
+	want := "2026-03-01 09:00 v1            ##   2 flows, 1 sources\n" +
+		"2026-03-02 09:00               #### 4 flows, 3 sources\n" +
+		"2026-03-03 09:00                    0 flows, 0 sources\n"
+	if sb.String() != want {
+		t.Errorf("chart:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
@@ -0,0 +1,37 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteTrendChart writes a text bar chart of the flow count of each scan,
+// with bars scaled to at most width characters
+func WriteTrendChart(w io.Writer, scans []Scan, width int) error {
+	if width <= 0 {
+		width = 50
+	}
+	maxFlows := 0
+	for _, scan := range scans {
+		if scan.Flows > maxFlows {
+			maxFlows = scan.Flows
+		}
+	}
+
+	for _, scan := range scans {
+		bar := 0
+		if maxFlows > 0 {
+			bar = scan.Flows * width / maxFlows
+		}
+		label := scan.ScannedAt.Format("2006-01-02 15:04")
+		if scan.Label != "" {
+			label += " " + scan.Label
+		}
+		if _, err := fmt.Fprintf(w, "%-30s %-*s %d flows, %d sources\n",
+			label, width, strings.Repeat("#", bar), scan.Flows, scan.Sources); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package semantic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+func TestKeywordMatcher(t *testing.T) {
+	keywords := []string{"hers", "he", "she", "his", "->get(", "->get_body_params(", "$_GET"}
+	matcher := common.NewKeywordMatcher(keywords)
+
+	inputs := []string{
+		"", "h", "ushers", "this", "ahishers", "$req->get_body_params()", "$req->get('a')",
+		"$req->getX()", "$_GE", "$_GET['id']", "no match here", "sshe", "hhers",
+	}
+	for _, s := range inputs {
+		wantFirst := -1
+		var wantAll []string
+		for i, kw := range keywords {
+			if n := strings.Count(s, kw); n > 0 {
+				if wantFirst < 0 {
+					wantFirst = i
+				}
+				for ; n > 0; n-- {
+					wantAll = append(wantAll, kw)
+				}
+			}
+		}
+		if got := matcher.Contains(s); got != (wantFirst >= 0) {
+			t.Errorf("Contains(%q) = %v", s, got)
+		}
+		if got := matcher.First(s); got != wantFirst {
+			t.Errorf("First(%q) = %d, want %d", s, got, wantFirst)
+		}
+		var gotAll []string
+		matcher.Find(s, func(i int) bool {
+			gotAll = append(gotAll, matcher.Keyword(i))
+			return true
+		})
+		if len(gotAll) != len(wantAll) {
+			t.Errorf("Find(%q) = %v, want occurrences of %v", s, gotAll, wantAll)
+		}
+	}
+}
+
+func TestIdentifySourceMatching(t *testing.T) {
+	tests := []struct {
+		expr string
+		want types.SourceType
+	}{
+		{"$_GET['id']", types.SourceHTTPGet},
+		{"$_COOKIE['a'] . $_GET['b']", types.SourceHTTPCookie},
+		{"$_SERVER['SCRIPT_NAME'] . $_POST['b']", types.SourceHTTPPost},
+		{"curl_exec($ch)", types.SourceNetwork},
+		{"json_decode(file_get_contents('php://input'))", types.SourceNetwork},
+		{"unserialize(base64_decode($data))", types.SourceUserInput},
+		{"getenv('HOME')", types.SourceEnvVar},
+		{"$this->request->get_body_params()", types.SourceUserInput},
+		{"strlen($name)", ""},
+	}
+	tracer := New(nil)
+	for _, tt := range tests {
+		var got types.SourceType
+		if info := tracer.identifySource(tt.expr, "index.php", 1); info != nil {
+			got = info.Type
+		}
+		if got != tt.want {
+			t.Errorf("identifySource(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+// identifySourceExprs are assignment right-hand sides as found in real code:
+// most are not sources, so every check runs to the end
+var identifySourceExprs = []string{
+	"$this->db->query(\"SELECT * FROM users WHERE id = \" . (int)$id)",
+	"array_merge($defaults, $options)",
+	"$_GET['page']",
+	"htmlspecialchars($row['title'], ENT_QUOTES, 'UTF-8')",
+	"new DateTime('now', new DateTimeZone($config->get('timezone')))",
+	"$request->input('email')",
+	"sprintf('%s/%s', rtrim($base, '/'), ltrim($path, '/'))",
+	"json_decode($response->getBody(), true)",
+	"$this->cache->remember('settings', 3600, function () { return Setting::all(); })",
+	"count($items) > 0 ? $items[0] : null",
+}
+
+func BenchmarkIdentifySource(b *testing.B) {
+	tracer := New(nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, expr := range identifySourceExprs {
+			tracer.identifySource(expr, "index.php", 1)
+		}
+	}
+}
+
+func BenchmarkInputMethodCall(b *testing.B) {
+	patterns := phpPatterns.GetInputMethodPatterns()
+	b.Run("contains", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, expr := range identifySourceExprs {
+				for _, pattern := range patterns {
+					if strings.Contains(expr, pattern) {
+						break
+					}
+				}
+			}
+		}
+	})
+	b.Run("matcher", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, expr := range identifySourceExprs {
+				phpPatterns.IsInputMethodCall(expr)
+			}
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// SinkImpact lists the input sources whose values can reach a code location,
+// such as a query call another tool flagged as a sink
+type SinkImpact struct {
+	FilePath string               `json:"file_path"`
+	Line     int                  `json:"line"`
+	Sources  []types.SourceInfo   `json:"sources,omitempty"` // Distinct sources reaching the line
+	Paths    []types.BackwardPath `json:"paths,omitempty"`   // Shortest path from each source, ending at the line
+}
+
+// SourcesReaching returns the input sources that can reach file:line (line is
+// 1-based): sources read on the line, sources whose flows of the last
+// TraceDirectory reach a node on it, and sources the variables read on the
+// line trace back to through the cached assignments. The codebase must have
+// been parsed first (ParseOnly or TraceDirectory) or its index loaded
+// (LoadIndex); only TraceDirectory records flows.
+func (t *Tracer) SourcesReaching(file string, line int) (*SinkImpact, error) {
+	t.reparseStale()
+	t.mu.RLock()
+	fileInfo := t.files[file]
+	flowMap := t.flowMap
+	t.mu.RUnlock()
+	if fileInfo == nil {
+		return nil, fmt.Errorf("file %s has not been parsed: call ParseOnly, TraceDirectory or LoadIndex first", file)
+	}
+	if fileInfo.Error != nil {
+		return nil, fileInfo.Error
+	}
+
+	impact := &SinkImpact{FilePath: file, Line: line}
+	shortest := make(map[string]int)
+	add := func(path types.BackwardPath) {
+		key := fmt.Sprintf("%s:%d:%s", path.Source.FilePath, path.Source.Line, path.Source.Expression)
+		if i, ok := shortest[key]; ok {
+			if len(path.Steps) < len(impact.Paths[i].Steps) {
+				impact.Paths[i] = path
+			}
+			return
+		}
+		shortest[key] = len(impact.Paths)
+		impact.Sources = append(impact.Sources, path.Source)
+		impact.Paths = append(impact.Paths, path)
+	}
+
+	for _, src := range fileInfo.Sources {
+		if src.Line == line {
+			add(sourcePath(src))
+		}
+	}
+	// Flows keep to a variable's name; one of another function's scope does
+	// not reach the line
+	var scopes *types.Scope
+	if fileInfo.SymbolTable != nil {
+		scopes = fileInfo.SymbolTable.Scopes
+	}
+	reachesLine := func(from *types.FlowNode) bool {
+		return from.Type != types.NodeVariable || from.FilePath != file ||
+			scopes.VariableScopeOf(from.Line, from.Name) == scopes.VariableScopeOf(line, from.Name)
+	}
+	for _, path := range flowPathsReaching(flowMap, file, line, reachesLine) {
+		add(path)
+	}
+
+	vars, err := t.variablesOnLine(file, fileInfo.Language, line)
+	if err != nil {
+		return nil, err
+	}
+	ctx := t.traceContext()
+	defer ctx.Close()
+	for _, varName := range vars {
+		// Only assignments in the scope owning the variable at line can reach it
+		var inScope func(string) bool
+		if scopes != nil {
+			scope := scopes.VariableScopeOf(line, varName)
+			inScope = func(s string) bool { return s == scope }
+		}
+		paths, _, _ := t.traceBackwardInScope(ctx, file, varName, inScope)
+		for _, path := range paths {
+			if len(path.Steps) == 0 {
+				continue
+			}
+			// Only assignments that precede the line can reach it
+			if last := path.Steps[len(path.Steps)-1]; last.FilePath == file && last.Line > line {
+				continue
+			}
+			add(path)
+		}
+	}
+
+	sort.SliceStable(impact.Paths, func(i, j int) bool { return len(impact.Paths[i].Steps) < len(impact.Paths[j].Steps) })
+	return impact, nil
+}
+
+// sourcePath is the one-step path of a source read on the queried line
+func sourcePath(src *types.FlowNode) types.BackwardPath {
+	info := types.SourceInfo{Type: src.SourceType, Expression: src.Snippet, FilePath: src.FilePath, Line: src.Line}
+	return types.BackwardPath{
+		Source: info,
+		Steps: []types.BackwardStep{{
+			StepNumber:  1,
+			Expression:  src.Snippet,
+			FilePath:    src.FilePath,
+			Line:        src.Line,
+			StepType:    "source",
+			Description: fmt.Sprintf("Input source: %s (%s)", src.Snippet, src.SourceType),
+		}},
+		Termination: types.TerminationSource,
+	}
+}
+
+// flowPathsReaching walks a flow map backward from its nodes on file:line,
+// entering them only from nodes reachesLine accepts, and returns, for every
+// source reached, the shortest path to one of them
+func flowPathsReaching(flowMap *types.FlowMap, file string, line int, reachesLine func(from *types.FlowNode) bool) []types.BackwardPath {
+	if flowMap == nil {
+		return nil
+	}
+	nodes := make(map[string]*types.FlowNode, len(flowMap.AllNodes))
+	into := make(map[string][]*types.FlowEdge)
+	for i := range flowMap.AllNodes {
+		nodes[flowMap.AllNodes[i].ID] = &flowMap.AllNodes[i]
+	}
+	for i := range flowMap.AllEdges {
+		edge := &flowMap.AllEdges[i]
+		into[edge.To] = append(into[edge.To], edge)
+	}
+
+	// next records the edge leading from each node toward the line
+	next := make(map[string]*types.FlowEdge)
+	var queue []string
+	for id, node := range nodes {
+		if node.FilePath == file && node.Line == line {
+			next[id] = nil
+			queue = append(queue, id)
+		}
+	}
+	sort.Strings(queue)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, edge := range into[id] {
+			if from := nodes[edge.From]; next[id] == nil && from != nil && !reachesLine(from) {
+				continue
+			}
+			if _, visited := next[edge.From]; !visited {
+				next[edge.From] = edge
+				queue = append(queue, edge.From)
+			}
+		}
+	}
+
+	var paths []types.BackwardPath
+	for id := range next {
+		src := nodes[id]
+		if src == nil || src.Type != types.NodeSource {
+			continue
+		}
+		path := types.BackwardPath{
+			Source:      types.SourceInfo{Type: src.SourceType, Expression: src.Snippet, FilePath: src.FilePath, Line: src.Line},
+			Termination: types.TerminationSource,
+		}
+		description := fmt.Sprintf("Input source: %s (%s)", src.Snippet, src.SourceType)
+		for node := src; node != nil; {
+			expr := node.Snippet
+			if expr == "" {
+				expr = node.Name
+			}
+			path.Steps = append(path.Steps, types.BackwardStep{
+				StepNumber:  len(path.Steps) + 1,
+				Expression:  expr,
+				FilePath:    node.FilePath,
+				Line:        node.Line,
+				StepType:    string(node.Type),
+				Description: description,
+			})
+			path.CrossFile = path.CrossFile || node.FilePath != src.FilePath
+			edge := next[node.ID]
+			if edge == nil {
+				break
+			}
+			node, description = nodes[edge.To], edge.Description
+		}
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		a, b := paths[i].Source, paths[j].Source
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Line < b.Line
+	})
+	return paths
+}
+
+// variablesOnLine returns the names (without $) of the plain variables read
+// on a line, in order of appearance
+func (t *Tracer) variablesOnLine(file, language string, line int) ([]string, error) {
+	content, err := t.readFile(file)
+	if err != nil {
+		return nil, &types.ParseError{FilePath: file, Err: err}
+	}
+	parser := createParser(language)
+	if parser == nil {
+		return nil, nil
+	}
+	defer parser.Close()
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, &types.ParseError{FilePath: file, Err: err}
+	}
+	defer tree.Close()
+
+	nodeType := "identifier"
+	if language == "php" {
+		nodeType = "variable_name"
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, node := range analyzer.FindNodesOfType(tree.RootNode(), nodeType) {
+		if int(node.StartPoint().Row)+1 != line {
+			continue
+		}
+		name := strings.TrimPrefix(analyzer.GetNodeText(node, content), "$")
+		if name != "" && name != "this" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
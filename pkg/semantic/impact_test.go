@@ -0,0 +1,85 @@
+package semantic
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourcesReaching(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "lib.php", `<?php
+$id = $_GET['id'];
+$sql = "SELECT * FROM t WHERE id = " . $id;
+mysql_query($sql);
+function run($q) {
+    return mysql_query($q);
+}
+run($sql);
+echo "static";
+mysql_query("SELECT " . $_POST['col']);
+`)
+	writeFile(t, dir, "page.php", `<?php
+$name = $_COOKIE['name'];
+echo "Hello " . $name;
+`)
+	writeFile(t, dir, "scopes.php", `<?php
+function a() {
+    $q = $_GET['x'];
+    mysql_query($q);
+}
+function b() {
+    $q = 'SELECT 1';
+    mysql_query($q);
+}
+`)
+	lib, page, scopes := filepath.Join(dir, "lib.php"), filepath.Join(dir, "page.php"), filepath.Join(dir, "scopes.php")
+
+	tracer := New(nil)
+	if _, err := tracer.TraceDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file   string
+		line   int
+		source string
+		steps  int
+	}{
+		{lib, 4, "$_GET['id']", 4},       // $_GET -> $id -> $sql -> mysql_query()
+		{lib, 6, "$_GET['id']", 0},       // Through run()'s parameter
+		{lib, 10, "$_POST['col']", 1},    // Read on the line itself
+		{page, 3, "$_COOKIE['name']", 0}, // Traced backward from $name
+		{lib, 9, "", 0},
+		{scopes, 4, "$_GET['x']", 0},
+		{scopes, 8, "", 0}, // $q of a() is another variable
+	}
+	for _, tt := range tests {
+		impact, err := tracer.SourcesReaching(tt.file, tt.line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		where := fmt.Sprintf("%s:%d", filepath.Base(tt.file), tt.line)
+		if tt.source == "" {
+			if len(impact.Sources) != 0 {
+				t.Errorf("%s sources = %+v, want none", where, impact.Sources)
+			}
+			continue
+		}
+		if len(impact.Sources) != 1 || impact.Sources[0].Expression != tt.source {
+			t.Errorf("%s sources = %+v, want %s", where, impact.Sources, tt.source)
+			continue
+		}
+		path := impact.Paths[0]
+		if last := path.Steps[len(path.Steps)-1]; last.FilePath != tt.file || last.Line > tt.line {
+			t.Errorf("%s path ends at %s:%d", where, last.FilePath, last.Line)
+		}
+		if tt.steps > 0 && len(path.Steps) != tt.steps {
+			t.Errorf("%s path has %d steps, want %d: %+v", where, len(path.Steps), tt.steps, path.Steps)
+		}
+	}
+
+	if _, err := New(nil).SourcesReaching(lib, 4); err == nil {
+		t.Error("expected an error before the codebase is parsed")
+	}
+}
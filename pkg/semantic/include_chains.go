@@ -0,0 +1,132 @@
+package semantic
+
+import (
+	"sort"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// buildIncludeGraph resolves the static includes between the parsed files.
+// The graph is built once per trace, before flow tracing, and read-only after.
+func (t *Tracer) buildIncludeGraph(rootPath string) {
+	t.mu.RLock()
+	includes := t.resolveIncludes(rootPath)
+	t.mu.RUnlock()
+	observed := t.runtimeIncludes(rootPath)
+
+	// Includes observed at runtime complete the dynamic ones
+	runtimeOnly := make(map[string]map[string]bool)
+	for filePath, targets := range observed {
+		static := make(map[string]bool, len(includes[filePath]))
+		for _, target := range includes[filePath] {
+			static[target] = true
+		}
+		sorted := make([]string, 0, len(targets))
+		for target := range targets {
+			sorted = append(sorted, target)
+		}
+		sort.Strings(sorted)
+		for _, target := range sorted {
+			if static[target] {
+				continue
+			}
+			includes[filePath] = append(includes[filePath], target)
+			if runtimeOnly[filePath] == nil {
+				runtimeOnly[filePath] = make(map[string]bool)
+			}
+			runtimeOnly[filePath][target] = true
+		}
+	}
+
+	includedBy := make(map[string][]string)
+	for filePath, targets := range includes {
+		for _, target := range targets {
+			includedBy[target] = append(includedBy[target], filePath)
+		}
+	}
+
+	t.mu.Lock()
+	t.includes, t.includedBy = includes, includedBy
+	t.runtimeOnlyIncludes = runtimeOnly
+	t.mu.Unlock()
+}
+
+// includeChain returns the include chain that makes to reachable from from:
+// the shortest chain from from itself or, failing that, from the nearest file
+// including from. Without one the hop is a global symbol of unproven reachability.
+func (t *Tracer) includeChain(from, to string) types.IncludeChain {
+	chain := types.IncludeChain{From: from, To: to, Reachability: types.ReachabilityUnproven}
+	for _, entry := range includeAncestors(from, t.includedBy) {
+		if files := includePath(entry, to, t.includes); files != nil {
+			chain.Reachability = types.ReachabilityIncluded
+			if t.runtimeAssistedChain(files) {
+				chain.Reachability = types.ReachabilityRuntime
+			}
+			chain.Files = files
+			break
+		}
+	}
+	return chain
+}
+
+// annotateIncludeChains records the include chain of every file boundary a
+// backward path crosses
+func (t *Tracer) annotateIncludeChains(paths []types.BackwardPath) {
+	for i := range paths {
+		path := &paths[i]
+		if !path.CrossFile {
+			continue
+		}
+		prev := path.Source.FilePath
+		for _, step := range path.Steps {
+			if step.FilePath == "" || step.FilePath == prev {
+				continue
+			}
+			if prev != "" {
+				path.IncludeChains = append(path.IncludeChains, t.includeChain(prev, step.FilePath))
+			}
+			prev = step.FilePath
+		}
+	}
+}
+
+// includeAncestors returns filePath followed by the files including it,
+// directly or transitively, nearest first
+func includeAncestors(filePath string, includedBy map[string][]string) []string {
+	seen := map[string]bool{filePath: true}
+	ancestors := []string{filePath}
+	for i := 0; i < len(ancestors); i++ {
+		for _, parent := range includedBy[ancestors[i]] {
+			if !seen[parent] {
+				seen[parent] = true
+				ancestors = append(ancestors, parent)
+			}
+		}
+	}
+	return ancestors
+}
+
+// includePath returns the shortest include chain from start to target
+// (both included), or nil if target is not reachable from start
+func includePath(start, target string, includes map[string][]string) []string {
+	parent := map[string]string{start: ""}
+	queue := []string{start}
+	for len(queue) > 0 {
+		filePath := queue[0]
+		queue = queue[1:]
+		if filePath == target {
+			var files []string
+			for f := target; f != ""; f = parent[f] {
+				files = append([]string{f}, files...)
+			}
+			return files
+		}
+		for _, next := range includes[filePath] {
+			if _, seen := parent[next]; !seen {
+				parent[next] = filePath
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestIncludeChains(t *testing.T) {
+	const caller = "<?php\n$id = $_GET['id'];\nhandle($id);\n"
+	const helper = "<?php\nfunction handle($v) {\n    $w = $v;\n}\n"
+	tests := []struct {
+		name      string
+		files     map[string]string
+		want      types.Reachability
+		wantFiles []string
+	}{
+		{"direct include", map[string]string{
+			"index.php":       "<?php\nrequire_once __DIR__ . '/lib/helpers.php';\n$id = $_GET['id'];\nhandle($id);\n",
+			"lib/helpers.php": helper,
+		}, types.ReachabilityIncluded, []string{"index.php", "lib/helpers.php"}},
+		{"nested include", map[string]string{
+			"index.php":       "<?php\ninclude 'lib/boot.php';\n$id = $_GET['id'];\nhandle($id);\n",
+			"lib/boot.php":    "<?php\nrequire 'lib/helpers.php';\n",
+			"lib/helpers.php": helper,
+		}, types.ReachabilityIncluded, []string{"index.php", "lib/boot.php", "lib/helpers.php"}},
+		{"included by the caller's includer", map[string]string{
+			"index.php":   "<?php\nrequire 'helpers.php';\nrequire 'page.php';\n",
+			"page.php":    caller,
+			"helpers.php": helper,
+		}, types.ReachabilityIncluded, []string{"index.php", "helpers.php"}},
+		{"no include", map[string]string{
+			"index.php":   caller,
+			"helpers.php": helper,
+		}, types.ReachabilityUnproven, nil},
+		{"same file", map[string]string{
+			"index.php": caller + "function handle($v) {\n    $w = $v;\n}\n",
+		}, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, code := range tt.files {
+				if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, dir, name, code)
+			}
+			result, err := New(nil).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var chain *types.IncludeChain
+			for _, edge := range result.FlowMap.AllEdges {
+				if edge.Type == types.EdgeCall && edge.IncludeChain != nil {
+					chain = edge.IncludeChain
+				}
+			}
+			if chain == nil {
+				if tt.want != "" {
+					t.Fatal("no include chain on the call edge")
+				}
+				return
+			}
+			if tt.want == "" {
+				t.Fatalf("unexpected include chain %+v", *chain)
+			}
+			var files []string
+			for _, f := range chain.Files {
+				rel, _ := filepath.Rel(dir, f)
+				files = append(files, filepath.ToSlash(rel))
+			}
+			if chain.Reachability != tt.want || !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("chain = %s %v, want %s %v", chain.Reachability, files, tt.want, tt.wantFiles)
+			}
+		})
+	}
+}
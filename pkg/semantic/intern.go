@@ -0,0 +1,177 @@
+package semantic
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// Most retained memory of a large trace is the same strings repeated across
+// cached assignments, calls, flow nodes and path steps: file paths, source
+// expressions ($_GET['id'] in every file) and snippets copied into every path
+// a node appears on. The interner stores each distinct string once; every
+// structure then references the shared copy instead of its own allocation.
+
+// stringInterner deduplicates strings. A nil interner returns strings
+// unchanged. It is safe for concurrent use.
+type stringInterner struct {
+	mu      sync.Mutex
+	strings map[string]string
+}
+
+// newStringInterner creates an empty interner
+func newStringInterner() *stringInterner {
+	return &stringInterner{strings: make(map[string]string, 1024)}
+}
+
+// intern returns the shared copy of s. New strings are cloned so that a
+// substring does not keep its (larger) backing array alive.
+func (in *stringInterner) intern(s string) string {
+	if in == nil || s == "" {
+		return s
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if shared, ok := in.strings[s]; ok {
+		return shared
+	}
+	if in.strings == nil {
+		in.strings = make(map[string]string, 1024)
+	}
+	s = strings.Clone(s)
+	in.strings[s] = s
+	return s
+}
+
+// len returns the number of distinct strings stored
+func (in *stringInterner) len() int {
+	if in == nil {
+		return 0
+	}
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	return len(in.strings)
+}
+
+// release drops the lookup table once a parse or trace is complete, so it
+// does not grow for the tracer's lifetime. Interned strings stay shared by the
+// structures referencing them; the next intern starts a new table.
+func (in *stringInterner) release() {
+	if in == nil {
+		return
+	}
+	in.mu.Lock()
+	in.strings = nil
+	in.mu.Unlock()
+}
+
+// internNode interns the strings of a flow node in place
+func (in *stringInterner) internNode(node *types.FlowNode) {
+	if in == nil {
+		return
+	}
+	node.ID = in.intern(node.ID)
+	node.Language = in.intern(node.Language)
+	node.FilePath = in.intern(node.FilePath)
+	node.Name = in.intern(node.Name)
+	node.ClassName = in.intern(node.ClassName)
+	node.MethodName = in.intern(node.MethodName)
+	node.Scope = in.intern(node.Scope)
+	node.SourceKey = in.intern(node.SourceKey)
+	node.Canonical = in.intern(node.Canonical)
+	node.CarrierType = in.intern(node.CarrierType)
+	node.Snippet = in.intern(node.Snippet)
+}
+
+// internNodes interns the strings of a slice of flow nodes in place
+func (in *stringInterner) internNodes(nodes []types.FlowNode) {
+	for i := range nodes {
+		in.internNode(&nodes[i])
+	}
+}
+
+// internEdge interns the strings of a flow edge in place
+func (in *stringInterner) internEdge(edge *types.FlowEdge) {
+	edge.ID = in.intern(edge.ID)
+	edge.From = in.intern(edge.From)
+	edge.To = in.intern(edge.To)
+	edge.FilePath = in.intern(edge.FilePath)
+	edge.Description = in.intern(edge.Description)
+	edge.Code = in.intern(edge.Code)
+}
+
+// internAssignments interns the strings of cached assignments in place
+func (in *stringInterner) internAssignments(assignments []*types.Assignment) {
+	if in == nil {
+		return
+	}
+	for _, assign := range assignments {
+		assign.Target = in.intern(assign.Target)
+		assign.TargetType = in.intern(assign.TargetType)
+		assign.Source = in.intern(assign.Source)
+		assign.SourceType = in.intern(assign.SourceType)
+		assign.FilePath = in.intern(assign.FilePath)
+		assign.Scope = in.intern(assign.Scope)
+		assign.TaintSource = in.intern(assign.TaintSource)
+		assign.Operator = in.intern(assign.Operator)
+		for i, key := range assign.Keys {
+			assign.Keys[i] = in.intern(key)
+		}
+	}
+}
+
+// internCalls interns the strings of cached calls in place
+func (in *stringInterner) internCalls(calls []*types.CallSite) {
+	if in == nil {
+		return
+	}
+	for _, call := range calls {
+		call.FunctionName = in.intern(call.FunctionName)
+		call.ClassName = in.intern(call.ClassName)
+		call.MethodName = in.intern(call.MethodName)
+		call.FilePath = in.intern(call.FilePath)
+		call.Scope = in.intern(call.Scope)
+		call.ResultVar = in.intern(call.ResultVar)
+		for i := range call.Arguments {
+			arg := &call.Arguments[i]
+			arg.Value = in.intern(arg.Value)
+			arg.Type = in.intern(arg.Type)
+			arg.TaintSource = in.intern(arg.TaintSource)
+		}
+	}
+}
+
+// internFlowMap interns the strings of every node, edge and path step of a
+// flow map in place. Path steps copy their node, so identical snippets
+// repeated across paths end up stored once.
+func (in *stringInterner) internFlowMap(flowMap *types.FlowMap) {
+	if in == nil || flowMap == nil {
+		return
+	}
+	in.internNodes(flowMap.Sources)
+	in.internNodes(flowMap.Carriers)
+	in.internNodes(flowMap.AllNodes)
+	in.internNodes(flowMap.Usages)
+	for i := range flowMap.AllEdges {
+		in.internEdge(&flowMap.AllEdges[i])
+	}
+	for i := range flowMap.Paths {
+		path := &flowMap.Paths[i]
+		path.Description = in.intern(path.Description)
+		for j := range path.Steps {
+			step := &path.Steps[j]
+			in.internNode(&step.Node)
+			step.Description = in.intern(step.Description)
+			if step.Edge != nil {
+				in.internEdge(step.Edge)
+			}
+		}
+		if path.Source != nil {
+			in.internNode(path.Source)
+		}
+		if path.Target != nil {
+			in.internNode(path.Target)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package semantic
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+	"unsafe"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestStringInterner(t *testing.T) {
+	in := newStringInterner()
+	a := in.intern(string([]byte("$_GET['id']")))
+	b := in.intern(string([]byte("$_GET['id']")))
+	if a != b || unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Error("equal strings do not share storage")
+	}
+	if in.intern("") != "" || in.len() != 1 {
+		t.Errorf("len = %d, want 1 (empty strings are not stored)", in.len())
+	}
+
+	in.release()
+	if got := in.intern("$x"); got != "$x" || in.len() != 1 {
+		t.Errorf("intern after release = %q (len %d)", got, in.len())
+	}
+
+	var none *stringInterner
+	if got := none.intern("$x"); got != "$x" {
+		t.Errorf("nil interner returned %q", got)
+	}
+}
+
+func TestInternFlowMapSharesSnippets(t *testing.T) {
+	snippet := func() string { return string([]byte("$id = $_GET['id']")) }
+	node := types.FlowNode{ID: "a.php:3:0", FilePath: "a.php", Snippet: snippet()}
+	flowMap := &types.FlowMap{
+		AllNodes: []types.FlowNode{node},
+		Paths: []types.FlowPath{
+			{Steps: []types.FlowStep{{Node: types.FlowNode{ID: "a.php:3:0", Snippet: snippet()}}}},
+			{Steps: []types.FlowStep{{Node: types.FlowNode{ID: "a.php:3:0", Snippet: snippet()}}}},
+		},
+	}
+
+	newStringInterner().internFlowMap(flowMap)
+
+	want := unsafe.StringData(flowMap.AllNodes[0].Snippet)
+	for i, path := range flowMap.Paths {
+		if unsafe.StringData(path.Steps[0].Node.Snippet) != want {
+			t.Errorf("path %d snippet not shared with the node", i)
+		}
+	}
+}
+
+// retainedHeap returns the live heap bytes and objects after a full collection
+func retainedHeap() (bytes, objects uint64) {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc, m.HeapObjects
+}
+
+// BenchmarkTraceRetained compares the heap retained by a trace result with
+// and without string interning
+func BenchmarkTraceRetained(b *testing.B) {
+	dir := writeBenchmarkCodebase(b, 500)
+	for _, bm := range []struct {
+		name   string
+		intern bool
+	}{{"plain", false}, {"interned", true}} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			var retained, objects uint64
+			for i := 0; i < b.N; i++ {
+				beforeBytes, beforeObjects := retainedHeap()
+				config := DefaultConfig()
+				config.MaxTracedSources = -1
+				tracer := New(config)
+				if !bm.intern {
+					tracer.interner = nil
+				}
+				result, err := tracer.TraceDirectory(dir)
+				if err != nil {
+					b.Fatal(err)
+				}
+				afterBytes, afterObjects := retainedHeap()
+				if afterBytes > beforeBytes {
+					retained += afterBytes - beforeBytes
+				}
+				if afterObjects > beforeObjects {
+					objects += afterObjects - beforeObjects
+				}
+				runtime.KeepAlive(result)
+			}
+			b.ReportMetric(float64(retained)/float64(b.N)/(1<<20), "retained-MB")
+			b.ReportMetric(float64(objects)/float64(b.N), "retained-objects")
+		})
+	}
+}
+
+func TestInternerReleasedAfterEachParse(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", "<?php\n$id = $_GET['id'];\necho $id;\n")
+	file := filepath.Join(dir, "index.php")
+
+	tracer := New(nil)
+	if _, err := tracer.ParseOnly(dir); err != nil {
+		t.Fatal(err)
+	}
+	if n := tracer.interner.len(); n != 0 {
+		t.Errorf("ParseOnly kept %d interned strings", n)
+	}
+
+	tracer.InvalidateFiles([]string{file})
+	if _, err := tracer.SourcesReaching(file, 3); err != nil {
+		t.Fatal(err)
+	}
+	if n := tracer.interner.len(); n != 0 {
+		t.Errorf("re-parsing invalidated files kept %d interned strings", n)
+	}
+}
@@ -0,0 +1,195 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// InvalidateFiles drops what the tracer holds about files changed, added or
+// deleted since they were parsed: their file information with its cached
+// assignments and calls, their cached parse, the assignments an index loaded
+// for them, and the global symbol table keys they define. A short name one of
+// them defined falls back to another file defining it. The files are parsed
+// again on the next query (TraceBackward, TraceBackwardBatch, TaintStatusAt
+// or SourcesReaching) instead of requiring a new Tracer; deleted files and
+// added files outside the include patterns are dropped. Paths are as the
+// tracer records them (FileInfo.Path). The flows of the last TraceDirectory
+// may run through them, so they are dropped too.
+func (t *Tracer) InvalidateFiles(paths []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(paths) > 0 {
+		t.flowMap = nil
+	}
+
+	if t.staleFiles == nil {
+		t.staleFiles = make(map[string]bool)
+	}
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		if fileInfo := t.files[path]; fileInfo != nil {
+			t.removeFileSymbols(path, fileInfo)
+			delete(t.files, path)
+		}
+		delete(t.indexedAssignments, path)
+		if t.parserService != nil {
+			t.parserService.Invalidate(path)
+		}
+		t.staleFiles[path] = true
+	}
+}
+
+// removeFileSymbols removes the global symbol table keys of a file: its
+// "file::name" keys and the short names pointing at its definitions. Callers
+// must hold t.mu.
+func (t *Tracer) removeFileSymbols(filePath string, fileInfo *FileInfo) {
+	prefix := filePath + "::"
+
+	classes := make(map[string]*types.ClassDef)
+	for key, class := range t.symbolTable.Classes {
+		if strings.HasPrefix(key, prefix) {
+			classes[key[len(prefix):]] = class
+			delete(t.symbolTable.Classes, key)
+		}
+	}
+	for name, class := range classes {
+		if t.symbolTable.Classes[name] != class {
+			continue
+		}
+		delete(t.symbolTable.Classes, name)
+		// Short names fall back to the definition of another parsed file
+		if key := t.definingKey(name, filePath, func(key string) bool { return t.symbolTable.Classes[key] != nil }); key != "" {
+			t.symbolTable.Classes[name] = t.symbolTable.Classes[key]
+		}
+	}
+
+	functions := make(map[string]*types.FunctionDef)
+	for key, fn := range t.symbolTable.Functions {
+		if strings.HasPrefix(key, prefix) {
+			functions[key[len(prefix):]] = fn
+			delete(t.symbolTable.Functions, key)
+		}
+	}
+	for name, fn := range functions {
+		if t.symbolTable.Functions[name] != fn {
+			continue
+		}
+		delete(t.symbolTable.Functions, name)
+		if key := t.definingKey(name, filePath, func(key string) bool { return t.symbolTable.Functions[key] != nil }); key != "" {
+			t.symbolTable.Functions[name] = t.symbolTable.Functions[key]
+		}
+	}
+
+	if fileInfo.SymbolTable == nil {
+		return
+	}
+	for name, c := range fileInfo.SymbolTable.Constants {
+		if t.symbolTable.Constants[name] != c {
+			continue
+		}
+		delete(t.symbolTable.Constants, name)
+		for path, other := range t.files {
+			if path != filePath && other.SymbolTable != nil && other.SymbolTable.Constants[name] != nil {
+				t.symbolTable.Constants[name] = other.SymbolTable.Constants[name]
+				break
+			}
+		}
+	}
+}
+
+// definingKey returns the "file::name" key of another parsed file defining
+// name, the first in path order, or ""
+func (t *Tracer) definingKey(name, exclude string, defined func(key string) bool) string {
+	paths := make([]string, 0, len(t.files))
+	for path := range t.files {
+		if path != exclude {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if key := path + "::" + name; defined(key) {
+			return key
+		}
+	}
+	return ""
+}
+
+// reparseStale parses the files invalidated since the last query again and
+// merges them back into the global symbol table, the include graph and the
+// functions promoted from them
+func (t *Tracer) reparseStale() {
+	t.mu.Lock()
+	stale := make([]string, 0, len(t.staleFiles))
+	for path := range t.staleFiles {
+		if _, err := os.Stat(path); err == nil && t.discoverable(t.queryRoot, path) {
+			stale = append(stale, path)
+		}
+	}
+	t.staleFiles = nil
+	t.mu.Unlock()
+	if len(stale) == 0 {
+		return
+	}
+	sort.Strings(stale)
+	defer t.interner.release()
+
+	parsers := make(map[string]*sitter.Parser)
+	for _, path := range stale {
+		lang := detectLanguage(path)
+		parser, ok := parsers[lang]
+		if !ok {
+			parser = createParser(lang)
+			if parser == nil {
+				continue
+			}
+			parsers[lang] = parser
+		}
+		t.parseFileWithParser(path, lang, parser)
+		t.yielder.Tick()
+	}
+
+	t.mu.Lock()
+	for _, path := range stale {
+		if fileInfo := t.files[path]; fileInfo != nil {
+			t.mergeFileSymbols(path, fileInfo)
+			if fileInfo.SymbolTable != nil {
+				fileInfo.SymbolTable.ReleaseBodySources()
+			}
+		}
+	}
+	t.indexPromotions()
+	t.mu.Unlock()
+
+	t.buildIncludeGraph(t.queryRoot)
+	t.promoteValidators()
+}
+
+// indexPromotions registers the source wrappers, return summaries and request
+// attributes of the parsed files, without deriving the sources they promote.
+// Callers must hold t.mu.
+func (t *Tracer) indexPromotions() {
+	t.sourceWrappers = make(map[string]*types.SourceWrapper)
+	t.returnSummaries = make(map[string]*types.ReturnSummary)
+	var attrs []*types.RequestAttribute
+	for _, fileInfo := range t.files {
+		for _, w := range fileInfo.SourceWrappers {
+			if !t.trustedFunction(w.FunctionName) {
+				t.sourceWrappers[strings.ToLower(w.FunctionName)] = w
+			}
+		}
+		for _, rs := range fileInfo.ReturnSummaries {
+			if !t.trustedFunction(rs.FunctionName) {
+				t.returnSummaries[strings.ToLower(rs.FunctionName)] = rs
+			}
+		}
+		attrs = append(attrs, fileInfo.RequestAttributes...)
+	}
+	t.requestAttributes = resolveRequestAttributes(attrs)
+}
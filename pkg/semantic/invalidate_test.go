@@ -0,0 +1,67 @@
+package semantic
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestInvalidateFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "lib.php", `<?php
+function helper() { return 1; }
+`)
+	writeFile(t, dir, "index.php", `<?php
+$id = $_GET['id'];
+$out = $id;
+`)
+
+	tracer := New(nil)
+	if _, err := tracer.ParseOnly(dir); err != nil {
+		t.Fatal(err)
+	}
+	lib, index, added := filepath.Join(dir, "lib.php"), filepath.Join(dir, "index.php"), filepath.Join(dir, "added.php")
+	if tracer.symbolTable.Functions["helper"] == nil {
+		t.Fatal("helper() not in the global symbol table")
+	}
+
+	writeFile(t, dir, "lib.php", `<?php
+function renamed() { return 1; }
+`)
+	writeFile(t, dir, "index.php", `<?php
+$id = $_POST['id'];
+$out = $id;
+`)
+	writeFile(t, dir, "added.php", `<?php
+function added() { return 2; }
+`)
+	tracer.InvalidateFiles([]string{lib, index, added})
+
+	if tracer.symbolTable.Functions["helper"] != nil || tracer.symbolTable.Functions[lib+"::helper"] != nil {
+		t.Error("invalidated helper() still in the global symbol table")
+	}
+	if tracer.files[index] != nil {
+		t.Error("invalidated file still cached")
+	}
+
+	result, err := tracer.TraceBackward("$out", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []types.SourceType
+	for _, src := range result.Sources {
+		got = append(got, src.Type)
+	}
+	if len(got) != 1 || got[0] != types.SourceHTTPPost {
+		t.Errorf("sources of $out = %v, want [http_post]", got)
+	}
+	for _, name := range []string{"renamed", "added"} {
+		if tracer.symbolTable.Functions[name] == nil {
+			t.Errorf("%s() not in the global symbol table after reparsing", name)
+		}
+	}
+	if tracer.symbolTable.Functions["helper"] != nil {
+		t.Error("helper() back in the global symbol table")
+	}
+}
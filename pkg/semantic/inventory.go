@@ -0,0 +1,132 @@
+package semantic
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+)
+
+// InventoryRead is one place the application reads an external input
+type InventoryRead struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Snippet  string `json:"snippet,omitempty"`
+
+	// Unreachable is set for reads in code that never runs (see GetUnreachableSources)
+	Unreachable bool `json:"unreachable,omitempty"`
+}
+
+// InventoryInput is one external input, identified by its channel and key,
+// with every place it is read
+type InventoryInput struct {
+	Type      types.SourceType `json:"type"`
+	Key       string           `json:"key,omitempty"`
+	Canonical string           `json:"canonical"`
+	Reads     []InventoryRead  `json:"reads"`
+}
+
+// InventoryEntry lists the external inputs read by the code behind one entry
+// point. Inputs read in files no entry point reaches are listed per file,
+// without a route.
+type InventoryEntry struct {
+	Route  string           `json:"route,omitempty"`
+	Kind   EntryPointKind   `json:"kind,omitempty"`
+	File   string           `json:"file"`
+	Inputs []InventoryInput `json:"inputs"`
+}
+
+// Inventory is the attack surface of a codebase: every external read it
+// performs, whether or not input flows anywhere from it
+type Inventory struct {
+	EntryPoints []InventoryEntry         `json:"entry_points"`
+	ByType      map[types.SourceType]int `json:"by_type"` // Distinct inputs per channel
+}
+
+// AttackSurfaceInventory lists every input source of a scan (HTTP parameters,
+// environment variables, file, socket and stdin reads, CLI arguments, ...)
+// keyed by the entry points reaching it, sorted by route, channel and key.
+// Sources skipped by the source cap are included; their flows are not needed.
+func AttackSurfaceInventory(r *TraceResult) *Inventory {
+	byEntry := make(map[string]*InventoryEntry)
+	inputs := make(map[string]map[string]*InventoryInput) // Entry key -> canonical -> input
+	distinct := make(map[string]types.SourceType)
+	add := func(key string, entry InventoryEntry, src *types.FlowNode) {
+		if byEntry[key] == nil {
+			byEntry[key] = &entry
+			inputs[key] = make(map[string]*InventoryInput)
+		}
+		canonical := inventoryCanonical(src)
+		in := inputs[key][canonical]
+		if in == nil {
+			in = &InventoryInput{Type: src.SourceType, Key: src.SourceKey, Canonical: canonical}
+			inputs[key][canonical] = in
+		}
+		_, dead := src.Metadata["unreachable"]
+		in.Reads = append(in.Reads, InventoryRead{FilePath: src.FilePath, Line: src.Line, Snippet: src.Snippet, Unreachable: dead})
+		distinct[canonical] = src.SourceType
+	}
+
+	for _, src := range r.Sources {
+		eps := r.EntryPointsForSource(src)
+		if len(eps) == 0 {
+			add("file:"+src.FilePath, InventoryEntry{File: src.FilePath}, src)
+		}
+		for _, ep := range eps {
+			add("route:"+ep.Route, InventoryEntry{Route: ep.Route, Kind: ep.Kind, File: ep.FilePath}, src)
+		}
+	}
+
+	inv := &Inventory{
+		EntryPoints: make([]InventoryEntry, 0, len(byEntry)),
+		ByType:      make(map[types.SourceType]int),
+	}
+	for _, st := range distinct {
+		inv.ByType[st]++
+	}
+	for key, entry := range byEntry {
+		for _, in := range inputs[key] {
+			sort.Slice(in.Reads, func(i, j int) bool {
+				if in.Reads[i].FilePath != in.Reads[j].FilePath {
+					return in.Reads[i].FilePath < in.Reads[j].FilePath
+				}
+				return in.Reads[i].Line < in.Reads[j].Line
+			})
+			entry.Inputs = append(entry.Inputs, *in)
+		}
+		sort.Slice(entry.Inputs, func(i, j int) bool {
+			if entry.Inputs[i].Type != entry.Inputs[j].Type {
+				return entry.Inputs[i].Type < entry.Inputs[j].Type
+			}
+			return entry.Inputs[i].Canonical < entry.Inputs[j].Canonical
+		})
+		inv.EntryPoints = append(inv.EntryPoints, *entry)
+	}
+	sort.Slice(inv.EntryPoints, func(i, j int) bool {
+		if inv.EntryPoints[i].Route != inv.EntryPoints[j].Route {
+			return inv.EntryPoints[i].Route < inv.EntryPoints[j].Route
+		}
+		return inv.EntryPoints[i].File < inv.EntryPoints[j].File
+	})
+	return inv
+}
+
+// inventoryCanonical names the input a source reads; keyless reads (a file,
+// stdin) are named by their channel alone, so they are listed as one input
+// with each read
+func inventoryCanonical(src *types.FlowNode) string {
+	if src.Canonical != "" {
+		return src.Canonical
+	}
+	return sources.CanonicalSourceName(src.SourceType, src.SourceKey)
+}
+
+// ToAttackSurfaceInventory exports the attack surface inventory as JSON
+func ToAttackSurfaceInventory(r *TraceResult) (string, error) {
+	data, err := json.MarshalIndent(AttackSurfaceInventory(r), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,67 @@
+package semantic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestAttackSurfaceInventory(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+require 'lib.php';
+$id = $_GET['id'];
+echo $_GET['id'];
+$mode = getenv('APP_MODE');
+exit;
+$late = $_POST['late'];
+`)
+	writeFile(t, dir, "lib.php", `<?php
+function config() { return file_get_contents('/etc/app.ini'); }
+`)
+	writeFile(t, dir, "cron.php", `<?php
+$job = $argv[1];
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := AttackSurfaceInventory(result)
+
+	type read struct {
+		canonical string
+		line      int
+		dead      bool
+	}
+	got := make(map[string][]read)
+	for _, entry := range inv.EntryPoints {
+		for _, in := range entry.Inputs {
+			for _, r := range in.Reads {
+				got[entry.Route] = append(got[entry.Route], read{in.Canonical, r.Line, r.Unreachable})
+			}
+		}
+	}
+	want := map[string][]read{
+		"/index.php": {
+			{"env_var:APP_MODE", 5, false},
+			{"file", 2, false},
+			{"http_get:id", 3, false},
+			{"http_get:id", 4, false},
+			{"http_post:late", 7, true},
+		},
+		"/cron.php": {{"cli_arg:1", 2, false}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("inventory = %+v, want %+v", got, want)
+	}
+
+	wantTypes := map[types.SourceType]int{
+		types.SourceHTTPGet: 1, types.SourceHTTPPost: 1, types.SourceEnvVar: 1,
+		types.SourceFile: 1, types.SourceCLIArg: 1,
+	}
+	if !reflect.DeepEqual(inv.ByType, wantTypes) {
+		t.Errorf("by type = %v, want %v", inv.ByType, wantTypes)
+	}
+}
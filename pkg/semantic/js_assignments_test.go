@@ -0,0 +1,79 @@
+package semantic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestJSAssignmentTargets(t *testing.T) {
+	tests := []struct {
+		language string
+		code     string
+		want     []types.Assignment // Target, TargetType, Source and Keys compared
+	}{
+		{"javascript", `class Form {
+  #token = req.query.token;
+  set(input) {
+    this.#priv = input;
+    obj[key] = req.body[key];
+    obj['k'].x[0] = v;
+    opts.q ??= req.query.q;
+  }
+}
+`, []types.Assignment{
+			{Target: "this.#priv", TargetType: "property", Source: "input", Keys: []string{"this", "#priv"}},
+			{Target: "obj[key]", TargetType: "array_element", Source: "req.body[key]", Keys: []string{"obj", "*"}},
+			{Target: "obj['k'].x[0]", TargetType: "array_element", Source: "v", Keys: []string{"obj", "k", "x", "0"}},
+			{Target: "opts.q", TargetType: "property", Source: "req.query.q", Keys: []string{"opts", "q"}},
+			{Target: "this.#token", TargetType: "property", Source: "req.query.token", Keys: []string{"this", "#token"}},
+		}},
+		{"typescript", `class Service {
+  constructor(private readonly name: string, public limit?: number, plain: string) {
+    this.cache[name] = plain;
+  }
+}
+`, []types.Assignment{
+			{Target: "this.cache[name]", TargetType: "array_element", Source: "plain", Keys: []string{"this", "cache", "*"}},
+			{Target: "this.name", TargetType: "property", Source: "name", Keys: []string{"this", "name"}},
+			{Target: "this.limit", TargetType: "property", Source: "limit", Keys: []string{"this", "limit"}},
+		}},
+	}
+
+	service := (&TraceResult{}).codeParser()
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			root, err := service.Parse([]byte(tt.code), tt.language)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assignments, err := analyzer.DefaultRegistry.Get(tt.language).ExtractAssignments(root, []byte(tt.code), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []types.Assignment
+			for _, a := range assignments {
+				got = append(got, types.Assignment{Target: a.Target, TargetType: a.TargetType, Source: a.Source, Keys: a.Keys})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("assignments =\n%+v\nwant\n%+v", got, tt.want)
+			}
+			if tt.language == "javascript" && !assignments[len(assignments)-1].IsTainted {
+				t.Error("field initializer from req.query not tainted")
+			}
+		})
+	}
+
+	// Parameter properties are class properties too
+	code := "class Service { constructor(private readonly name: string) {} }"
+	root, err := service.Parse([]byte(code), "typescript")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classes, _ := analyzer.DefaultRegistry.Get("typescript").ExtractClasses(root, []byte(code))
+	if len(classes) != 1 || classes[0].Properties["name"] == nil || classes[0].Properties["name"].Visibility != "private" {
+		t.Errorf("parameter property not declared on the class: %+v", classes)
+	}
+}
@@ -0,0 +1,262 @@
+package semantic
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
+// LabelRule attaches a taint label (pii, auth_token, attacker_controlled, ...)
+// to the sources matching all of its non-empty criteria. A rule without
+// criteria labels every source.
+type LabelRule struct {
+	Label  string   `json:"label"`
+	Inputs []string `json:"inputs,omitempty"` // Source types or aliases (get, post, cookie, ...)
+	Keys   []string `json:"keys,omitempty"`   // Case-insensitive key globs, e.g. "email", "card_*"
+	Files  []string `json:"files,omitempty"`  // File globs relative to the scanned directory (** allowed)
+}
+
+// validate checks that the rule is named and uses known inputs and valid globs
+func (r LabelRule) validate() error {
+	if r.Label == "" {
+		return fmt.Errorf("label is required")
+	}
+	for _, input := range r.Inputs {
+		if _, ok := common.ParseSourceType(input); !ok {
+			return fmt.Errorf("unknown input %q", input)
+		}
+	}
+	for _, key := range r.Keys {
+		if _, err := path.Match(strings.ToLower(key), ""); err != nil {
+			return fmt.Errorf("invalid key pattern %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// matches reports whether a source found under rootPath matches the rule
+func (r LabelRule) matches(src *types.FlowNode, rootPath string) bool {
+	if len(r.Inputs) > 0 {
+		matched := false
+		for _, input := range r.Inputs {
+			if st, _ := common.ParseSourceType(input); st == src.SourceType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.Keys) > 0 {
+		key := strings.ToLower(src.SourceKey)
+		matched := false
+		for _, pattern := range r.Keys {
+			if ok, _ := path.Match(strings.ToLower(pattern), key); ok && key != "" {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(r.Files) > 0 {
+		rel, err := filepath.Rel(rootPath, src.FilePath)
+		if err != nil {
+			rel = src.FilePath
+		}
+		rel = filepath.ToSlash(rel)
+		matched := false
+		for _, pattern := range r.Files {
+			if ok, _ := doubleStarMatch(pattern, rel); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// labelSources attaches the labels of the matching label rules to sources
+func (t *Tracer) labelSources(sources []*types.FlowNode, rootPath string) {
+	if t.rules == nil || len(t.rules.Labels) == 0 {
+		return
+	}
+	for _, src := range sources {
+		for _, rule := range t.rules.Labels {
+			if rule.matches(src, rootPath) {
+				src.Labels = mergeLabels(src.Labels, []string{rule.Label})
+			}
+		}
+	}
+}
+
+// mergeLabels returns the sorted union of two label sets
+func mergeLabels(a, b []string) []string {
+	if len(b) == 0 {
+		return a
+	}
+	seen := make(map[string]bool, len(a)+len(b))
+	var merged []string
+	for _, set := range [][]string{a, b} {
+		for _, label := range set {
+			if !seen[label] {
+				seen[label] = true
+				merged = append(merged, label)
+			}
+		}
+	}
+	if len(merged) == len(a) {
+		return a
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// hasLabel reports whether labels contains any of wanted
+func hasLabel(labels []string, wanted map[string]bool) bool {
+	for _, label := range labels {
+		if wanted[label] {
+			return true
+		}
+	}
+	return false
+}
+
+// propagateLabels carries source labels along the edges of a flow map. A
+// node reached from several sources gets the union of their labels.
+func propagateLabels(flowMap *types.FlowMap) {
+	if flowMap == nil {
+		return
+	}
+	labels := make(map[string][]string)
+	var queue []string
+	for _, node := range flowMap.AllNodes {
+		if len(node.Labels) > 0 {
+			labels[node.ID] = node.Labels
+			queue = append(queue, node.ID)
+		}
+	}
+	if len(queue) == 0 {
+		return
+	}
+
+	adjacency := make(map[string][]string)
+	for _, edge := range flowMap.AllEdges {
+		adjacency[edge.From] = append(adjacency[edge.From], edge.To)
+	}
+	// Re-queue a node whenever its label set grows; sets only grow, so this ends
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[id] {
+			merged := mergeLabels(labels[next], labels[id])
+			if len(merged) != len(labels[next]) {
+				labels[next] = merged
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	apply := func(nodes []types.FlowNode) {
+		for i := range nodes {
+			nodes[i].Labels = mergeLabels(nodes[i].Labels, labels[nodes[i].ID])
+		}
+	}
+	apply(flowMap.Sources)
+	apply(flowMap.Carriers)
+	apply(flowMap.AllNodes)
+	apply(flowMap.Usages)
+	for i := range flowMap.Paths {
+		p := &flowMap.Paths[i]
+		for j := range p.Steps {
+			p.Steps[j].Node.Labels = mergeLabels(p.Steps[j].Node.Labels, labels[p.Steps[j].Node.ID])
+		}
+		if p.Source != nil {
+			p.Source.Labels = mergeLabels(p.Source.Labels, labels[p.Source.ID])
+		}
+		if p.Target != nil {
+			p.Target.Labels = mergeLabels(p.Target.Labels, labels[p.Target.ID])
+		}
+	}
+}
+
+// GetSourcesByLabel returns the sources carrying a label
+func (r *TraceResult) GetSourcesByLabel(label string) []*types.FlowNode {
+	wanted := map[string]bool{label: true}
+	var result []*types.FlowNode
+	for _, src := range r.Sources {
+		if hasLabel(src.Labels, wanted) {
+			result = append(result, src)
+		}
+	}
+	return result
+}
+
+// FilterByLabels returns a copy of the result restricted to the sources,
+// nodes, edges and paths carrying at least one of the given labels, so
+// exporters only emit those flows. The receiver is not modified.
+func (r *TraceResult) FilterByLabels(labels ...string) *TraceResult {
+	wanted := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		wanted[label] = true
+	}
+	keepNodes := func(nodes []types.FlowNode) []types.FlowNode {
+		var kept []types.FlowNode
+		for _, node := range nodes {
+			if hasLabel(node.Labels, wanted) {
+				kept = append(kept, node)
+			}
+		}
+		return kept
+	}
+	keepSources := func(sources []*types.FlowNode) []*types.FlowNode {
+		var kept []*types.FlowNode
+		for _, src := range sources {
+			if hasLabel(src.Labels, wanted) {
+				kept = append(kept, src)
+			}
+		}
+		return kept
+	}
+
+	filtered := *r
+	filtered.Sources = keepSources(r.Sources)
+	filtered.SkippedSources = keepSources(r.SkippedSources)
+	if r.FlowMap == nil {
+		return &filtered
+	}
+
+	flowMap := *r.FlowMap
+	flowMap.Sources = keepNodes(r.FlowMap.Sources)
+	flowMap.Carriers = keepNodes(r.FlowMap.Carriers)
+	flowMap.AllNodes = keepNodes(r.FlowMap.AllNodes)
+	flowMap.Usages = keepNodes(r.FlowMap.Usages)
+	kept := make(map[string]bool, len(flowMap.AllNodes))
+	for _, node := range flowMap.AllNodes {
+		kept[node.ID] = true
+	}
+	flowMap.AllEdges = nil
+	for _, edge := range r.FlowMap.AllEdges {
+		if kept[edge.From] && kept[edge.To] {
+			flowMap.AllEdges = append(flowMap.AllEdges, edge)
+		}
+	}
+	flowMap.Paths = nil
+	for _, p := range r.FlowMap.Paths {
+		if p.Source != nil && hasLabel(p.Source.Labels, wanted) {
+			flowMap.Paths = append(flowMap.Paths, p)
+		}
+	}
+	filtered.FlowMap = &flowMap
+	return &filtered
+}
@@ -0,0 +1,58 @@
+package semantic
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestPropagateLabels(t *testing.T) {
+	flowMap := types.NewFlowMap()
+	flowMap.AddNode(types.FlowNode{ID: "email", Labels: []string{"pii"}})
+	flowMap.AddNode(types.FlowNode{ID: "token", Labels: []string{"auth_token"}})
+	flowMap.AddNode(types.FlowNode{ID: "joined"})
+	flowMap.AddNode(types.FlowNode{ID: "copy"})
+	flowMap.AddNode(types.FlowNode{ID: "unrelated"})
+	flowMap.AddEdge(types.FlowEdge{From: "email", To: "joined"})
+	flowMap.AddEdge(types.FlowEdge{From: "token", To: "joined"})
+	flowMap.AddEdge(types.FlowEdge{From: "joined", To: "copy"})
+
+	propagateLabels(flowMap)
+
+	want := map[string][]string{
+		"email":     {"pii"},
+		"token":     {"auth_token"},
+		"joined":    {"auth_token", "pii"},
+		"copy":      {"auth_token", "pii"},
+		"unrelated": nil,
+	}
+	for _, node := range flowMap.AllNodes {
+		if !reflect.DeepEqual(node.Labels, want[node.ID]) {
+			t.Errorf("%s labels = %v, want %v", node.ID, node.Labels, want[node.ID])
+		}
+	}
+}
+
+func TestLabelRuleMatches(t *testing.T) {
+	src := &types.FlowNode{SourceType: types.SourceHTTPPost, SourceKey: "Email", FilePath: "/app/user/profile.php"}
+	tests := []struct {
+		name string
+		rule LabelRule
+		want bool
+	}{
+		{"no criteria", LabelRule{Label: "attacker_controlled"}, true},
+		{"input alias", LabelRule{Label: "pii", Inputs: []string{"post"}}, true},
+		{"other input", LabelRule{Label: "pii", Inputs: []string{"get"}}, false},
+		{"key glob ignores case", LabelRule{Label: "pii", Keys: []string{"e*"}}, true},
+		{"file glob", LabelRule{Label: "pii", Files: []string{"user/**"}}, true},
+		{"all criteria must match", LabelRule{Label: "pii", Inputs: []string{"post"}, Files: []string{"admin/**"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.matches(src, "/app"); got != tt.want {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package semantic
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+)
+
+// LayerRule assigns an architectural layer (controller, model, view, ...) to
+// the code in matching files or classes. Rules are checked in order before
+// the default conventions of sources.DefaultLayerConventions.
+type LayerRule struct {
+	Layer   string   `json:"layer"`
+	Files   []string `json:"files,omitempty"`   // File globs relative to the scanned directory (** allowed)
+	Classes []string `json:"classes,omitempty"` // Case-insensitive class name globs, e.g. "*Service"
+}
+
+// validate checks that the rule is named, has criteria and uses valid globs
+func (r LayerRule) validate() error {
+	if r.Layer == "" {
+		return fmt.Errorf("layer is required")
+	}
+	if len(r.Files) == 0 && len(r.Classes) == 0 {
+		return fmt.Errorf("files or classes is required")
+	}
+	for _, file := range r.Files {
+		if _, err := path.Match(file, ""); err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", file, err)
+		}
+	}
+	for _, class := range r.Classes {
+		if _, err := path.Match(strings.ToLower(class), ""); err != nil {
+			return fmt.Errorf("invalid class pattern %q: %w", class, err)
+		}
+	}
+	return nil
+}
+
+// matches reports whether code in class className of the file rel (relative
+// to the scanned directory) matches the rule
+func (r LayerRule) matches(rel, className string) bool {
+	if className != "" {
+		for _, pattern := range r.Classes {
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(className)); ok {
+				return true
+			}
+		}
+	}
+	for _, pattern := range r.Files {
+		if ok, _ := doubleStarMatch(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LayerStats holds per-layer statistics
+type LayerStats struct {
+	Sources int // Input sources in the layer
+	Nodes   int // Flow nodes in the layer that input reaches
+}
+
+// layerOf returns the layer of code in class className of filePath
+func (t *Tracer) layerOf(filePath, className, rootPath string) string {
+	rel := relPath(rootPath, filePath)
+	if t.rules != nil {
+		for _, rule := range t.rules.Layers {
+			if rule.matches(rel, className) {
+				return rule.Layer
+			}
+		}
+	}
+	return sources.ClassifyLayer(rel, className)
+}
+
+// tagLayers sets the layer of every source and flow node and counts them
+// per layer in the stats
+func (t *Tracer) tagLayers(srcs []*types.FlowNode, flowMap *types.FlowMap, rootPath string) {
+	layers := make(map[string]string) // Node ID -> layer
+	layer := func(node *types.FlowNode) string {
+		if l, ok := layers[node.ID]; ok {
+			return l
+		}
+		l := t.layerOf(node.FilePath, node.ClassName, rootPath)
+		layers[node.ID] = l
+		return l
+	}
+	stats := func(l string) *LayerStats {
+		if t.stats.ByLayer[l] == nil {
+			t.stats.ByLayer[l] = &LayerStats{}
+		}
+		return t.stats.ByLayer[l]
+	}
+
+	t.stats.ByLayer = make(map[string]*LayerStats)
+	for _, src := range srcs {
+		src.Layer = layer(src)
+		if src.Layer != "" {
+			stats(src.Layer).Sources++
+		}
+	}
+	if flowMap == nil {
+		return
+	}
+	apply := func(nodes []types.FlowNode) {
+		for i := range nodes {
+			nodes[i].Layer = layer(&nodes[i])
+		}
+	}
+	apply(flowMap.Sources)
+	apply(flowMap.Carriers)
+	apply(flowMap.AllNodes)
+	apply(flowMap.Usages)
+	for i := range flowMap.Paths {
+		p := &flowMap.Paths[i]
+		for j := range p.Steps {
+			p.Steps[j].Node.Layer = layer(&p.Steps[j].Node)
+		}
+		if p.Source != nil {
+			p.Source.Layer = layer(p.Source)
+		}
+		if p.Target != nil {
+			p.Target.Layer = layer(p.Target)
+		}
+	}
+	for _, node := range flowMap.AllNodes {
+		if node.Layer != "" && node.Type != types.NodeSource {
+			stats(node.Layer).Nodes++
+		}
+	}
+}
+
+// SourcesReachingLayer returns the sources whose input flows to a node in a
+// layer, e.g. "model" for taint reaching the model layer. A source located
+// in the layer reaches it trivially.
+func (r *TraceResult) SourcesReachingLayer(layer string) []*types.FlowNode {
+	if r.FlowMap == nil {
+		return nil
+	}
+	reverse := make(map[string][]string)
+	for _, edge := range r.FlowMap.AllEdges {
+		reverse[edge.To] = append(reverse[edge.To], edge.From)
+	}
+	reached := make(map[string]bool)
+	var queue []string
+	for _, node := range r.FlowMap.AllNodes {
+		if node.Layer == layer && !reached[node.ID] {
+			reached[node.ID] = true
+			queue = append(queue, node.ID)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, from := range reverse[id] {
+			if !reached[from] {
+				reached[from] = true
+				queue = append(queue, from)
+			}
+		}
+	}
+
+	var result []*types.FlowNode
+	for _, src := range r.Sources {
+		if reached[src.ID] || src.Layer == layer {
+			result = append(result, src)
+		}
+	}
+	return result
+}
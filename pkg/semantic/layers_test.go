@@ -0,0 +1,69 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLayerOf(t *testing.T) {
+	tracer := New(&Config{Rules: &Rules{Layers: []LayerRule{
+		{Layer: "service", Classes: []string{"*service"}},
+		{Layer: "model", Files: []string{"src/Domain/**"}},
+	}}})
+	if err := tracer.loadRules(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file      string
+		className string
+		want      string
+	}{
+		{"app/Http/Controllers/UserController.php", "", "controller"},
+		{"lib/user.php", "UserController", "controller"},
+		{"app/Models/User.php", "User", "model"},
+		{"resources/views/profile.blade.php", "", "view"},
+		{"database/migrations/2024_create_users.php", "", "migration"},
+		{"app/Console/Commands/Import.php", "", "cli"},
+		{"src/Domain/Order.php", "", "model"},
+		{"app/Http/Controllers/Mail.php", "MailService", "service"},
+		{"index.php", "", ""},
+	}
+	for _, tt := range tests {
+		if got := tracer.layerOf(filepath.Join("/app", tt.file), tt.className, "/app"); got != tt.want {
+			t.Errorf("layerOf(%s, %q) = %q, want %q", tt.file, tt.className, got, tt.want)
+		}
+	}
+}
+
+func TestTagLayers(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "controllers"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "controllers/user.php", "<?php\n$id = $_GET['id'];\n$copy = $id;\n")
+	writeFile(t, dir, "index.php", "<?php\n$q = $_GET['q'];\n")
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, node := range result.FlowMap.AllNodes {
+		want := ""
+		if filepath.Base(node.FilePath) == "user.php" {
+			want = "controller"
+		}
+		if node.Layer != want {
+			t.Errorf("%s in %s has layer %q, want %q", node.Name, node.FilePath, node.Layer, want)
+		}
+	}
+	if stats := result.Stats.ByLayer["controller"]; stats == nil || stats.Sources != 1 || stats.Nodes == 0 {
+		t.Errorf("controller stats = %+v, want 1 source and reached nodes", stats)
+	}
+	reaching := result.SourcesReachingLayer("controller")
+	if len(reaching) != 1 || reaching[0].SourceKey != "id" {
+		t.Errorf("sources reaching controller = %v, want $_GET['id']", reaching)
+	}
+}
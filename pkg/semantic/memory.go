@@ -0,0 +1,145 @@
+package semantic
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Memory pacing: instead of forcing runtime.GC() every N files, heap checks
+// are spaced adaptively: the pacer measures the allocation per processed item
+// and checks again roughly when half of the remaining headroom would be used
+// up. A GC is only forced to confirm a suspected limit overrun. With
+// Config.SetGCMemoryLimit the tracer also sets the runtime's soft memory limit
+// (debug.SetMemoryLimit) from Config.MaxMemoryMB so the background GC works
+// harder as the heap approaches it; that limit is process-wide.
+
+// Adaptive check interval bounds (items between heap checks)
+const (
+	minMemoryCheckInterval     = 5
+	maxMemoryCheckInterval     = 500
+	initialMemoryCheckInterval = 25
+	allocPerItemSmoothing      = 0.3 // EWMA weight of the latest measurement
+)
+
+// Soft memory limit shared by the traces running with Config.SetGCMemoryLimit:
+// the limit before the first of them started is restored when the last ends
+var gcLimit struct {
+	sync.Mutex
+	users    int
+	previous int64
+}
+
+// applyMemoryLimit sets the process-wide soft memory limit for the duration
+// of a trace when Config.SetGCMemoryLimit is set, and returns a function
+// restoring the previous limit
+func (t *Tracer) applyMemoryLimit() func() {
+	if !t.config.SetGCMemoryLimit || t.config.MaxMemoryMB <= 0 {
+		return func() {}
+	}
+	gcLimit.Lock()
+	defer gcLimit.Unlock()
+	previous := debug.SetMemoryLimit(int64(t.config.MaxMemoryMB) << 20)
+	if gcLimit.users == 0 {
+		gcLimit.previous = previous
+	}
+	gcLimit.users++
+	return func() {
+		gcLimit.Lock()
+		defer gcLimit.Unlock()
+		if gcLimit.users--; gcLimit.users == 0 {
+			debug.SetMemoryLimit(gcLimit.previous)
+		}
+	}
+}
+
+// recordHeap samples the heap in use into Stats.PeakHeapMB; it is called
+// between phases, where the heap of the finished phase is still live
+func (t *Tracer) recordHeap() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	if mb := m.HeapAlloc >> 20; mb > t.stats.PeakHeapMB {
+		t.stats.PeakHeapMB = mb
+	}
+}
+
+// memoryPacer decides when to check heap usage against the memory limit.
+// It is not safe for concurrent use.
+type memoryPacer struct {
+	limitBytes   uint64
+	interval     int     // Items between checks
+	nextCheck    int     // Item count at which the next check is due
+	lastCount    int     // Item count at the previous check
+	lastTotal    uint64  // TotalAlloc at the previous check
+	allocPerItem float64 // Smoothed bytes allocated per item
+}
+
+// newMemoryPacer creates a pacer for a limit in MB (0 disables checks)
+func newMemoryPacer(limitMB int) *memoryPacer {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return &memoryPacer{
+		limitBytes: uint64(limitMB) << 20,
+		interval:   initialMemoryCheckInterval,
+		nextCheck:  initialMemoryCheckInterval,
+		lastTotal:  m.TotalAlloc,
+	}
+}
+
+// due reports whether a heap check is due after count items
+func (p *memoryPacer) due(count int) bool {
+	return p.limitBytes > 0 && count >= p.nextCheck
+}
+
+// check reads heap usage after count items and reports whether the limit is
+// exceeded. The next check is scheduled from the observed allocation rate.
+func (p *memoryPacer) check(count int) (usedMB uint64, exceeded bool) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	if items := count - p.lastCount; items > 0 {
+		perItem := float64(m.TotalAlloc-p.lastTotal) / float64(items)
+		if p.allocPerItem == 0 {
+			p.allocPerItem = perItem
+		} else {
+			p.allocPerItem += allocPerItemSmoothing * (perItem - p.allocPerItem)
+		}
+	}
+	p.lastCount, p.lastTotal = count, m.TotalAlloc
+
+	heap := m.HeapAlloc
+	if heap > p.limitBytes {
+		// HeapAlloc includes garbage not yet collected: confirm before stopping
+		runtime.GC()
+		runtime.ReadMemStats(&m)
+		heap = m.HeapAlloc
+		p.lastTotal = m.TotalAlloc
+	}
+	exceeded = heap > p.limitBytes
+
+	var headroom uint64
+	if !exceeded {
+		headroom = p.limitBytes - heap
+	}
+	p.interval = nextCheckInterval(headroom, p.allocPerItem)
+	p.nextCheck = count + p.interval
+
+	return heap >> 20, exceeded
+}
+
+// nextCheckInterval returns how many items may be processed before half of
+// the headroom is expected to be allocated, clamped to the interval bounds
+func nextCheckInterval(headroom uint64, allocPerItem float64) int {
+	if allocPerItem <= 0 {
+		return maxMemoryCheckInterval
+	}
+	items := float64(headroom) / 2 / allocPerItem
+	if math.IsNaN(items) || items < minMemoryCheckInterval {
+		return minMemoryCheckInterval
+	}
+	if items > maxMemoryCheckInterval {
+		return maxMemoryCheckInterval
+	}
+	return int(items)
+}
@@ -0,0 +1,187 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNextCheckInterval(t *testing.T) {
+	tests := []struct {
+		name         string
+		headroom     uint64
+		allocPerItem float64
+		want         int
+	}{
+		{"no allocation measured", 100 << 20, 0, maxMemoryCheckInterval},
+		{"plenty of headroom", 100 << 20, 1 << 10, maxMemoryCheckInterval},
+		{"half headroom per check", 100 << 20, 1 << 20, 50},
+		{"little headroom", 4 << 20, 1 << 20, minMemoryCheckInterval},
+		{"limit exceeded", 0, 1 << 20, minMemoryCheckInterval},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextCheckInterval(tt.headroom, tt.allocPerItem); got != tt.want {
+				t.Errorf("nextCheckInterval(%d, %v) = %d, want %d", tt.headroom, tt.allocPerItem, got, tt.want)
+			}
+		})
+	}
+}
+
+// memoryTestBallast keeps heap memory live across a forced GC
+var memoryTestBallast []byte
+
+func TestMemoryPacer(t *testing.T) {
+	t.Run("disabled without limit", func(t *testing.T) {
+		p := newMemoryPacer(0)
+		if p.due(1000) {
+			t.Error("pacer without limit should never be due")
+		}
+	})
+
+	t.Run("schedules next check", func(t *testing.T) {
+		p := newMemoryPacer(1 << 20) // 1 TB: never exceeded
+		if p.due(initialMemoryCheckInterval - 1) {
+			t.Error("check due before the initial interval")
+		}
+		if !p.due(initialMemoryCheckInterval) {
+			t.Fatal("check not due at the initial interval")
+		}
+		if _, exceeded := p.check(initialMemoryCheckInterval); exceeded {
+			t.Error("1 TB limit reported as exceeded")
+		}
+		if p.nextCheck <= initialMemoryCheckInterval {
+			t.Errorf("next check %d not after current count", p.nextCheck)
+		}
+	})
+
+	t.Run("reports exceeded limit", func(t *testing.T) {
+		p := newMemoryPacer(1)
+		memoryTestBallast = make([]byte, 4<<20)
+		defer func() { memoryTestBallast = nil }()
+		_, exceeded := p.check(1)
+		if !exceeded {
+			t.Error("1 MB limit not reported as exceeded with 4 MB live")
+		}
+		if p.interval != minMemoryCheckInterval {
+			t.Errorf("interval = %d, want %d when over the limit", p.interval, minMemoryCheckInterval)
+		}
+	})
+}
+
+func TestApplyMemoryLimit(t *testing.T) {
+	initial := debug.SetMemoryLimit(-1)
+
+	config := DefaultConfig()
+	config.MaxMemoryMB = 64
+	restore := New(config).applyMemoryLimit()
+	if got := debug.SetMemoryLimit(-1); got != initial {
+		t.Errorf("limit = %d without SetGCMemoryLimit, want %d unchanged", got, initial)
+	}
+	restore()
+
+	config.SetGCMemoryLimit = true
+	restoreA := New(config).applyMemoryLimit()
+	other := DefaultConfig()
+	other.MaxMemoryMB, other.SetGCMemoryLimit = 32, true
+	restoreB := New(other).applyMemoryLimit()
+	if got := debug.SetMemoryLimit(-1); got != 32<<20 {
+		t.Errorf("limit = %d, want 32 MB", got)
+	}
+	// The first trace to end leaves the limit to the one still running
+	restoreA()
+	if got := debug.SetMemoryLimit(-1); got == initial {
+		t.Error("limit restored while a trace is still running")
+	}
+	restoreB()
+	if got := debug.SetMemoryLimit(-1); got != initial {
+		t.Errorf("limit = %d after both traces, want %d restored", got, initial)
+	}
+}
+
+// writeBenchmarkCodebase writes a synthetic PHP codebase of n files
+func writeBenchmarkCodebase(b *testing.B, n int) string {
+	b.Helper()
+	dir := b.TempDir()
+	for i := 0; i < n; i++ {
+		content := fmt.Sprintf(`<?php
+class Controller%[1]d {
+    private $input;
+    public function __construct() { $this->input = $_POST; }
+    public function show() {
+        $id = $_GET['id%[1]d'];
+        $name = $this->input['name'];
+        $data = array('id' => $id, 'name' => $name);
+        return render%[1]d($data, $_COOKIE['session']);
+    }
+}
+function render%[1]d($data, $session) {
+    $out = '';
+    foreach ($data as $k => $v) { $out .= $k . '=' . $v; }
+    return $out . $session . getenv('APP_%[1]d');
+}
+`, i)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%d.php", i)), []byte(content), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// peakHeapSampler samples live heap bytes until stopped and returns the peak
+func peakHeapSampler() (stop func() uint64) {
+	samples := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	var peak uint64
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			metrics.Read(samples)
+			if v := samples[0].Value.Uint64(); v > peak {
+				peak = v
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() uint64 {
+		close(done)
+		wg.Wait()
+		return peak
+	}
+}
+
+func benchmarkTraceDirectory(b *testing.B, files int) {
+	dir := writeBenchmarkCodebase(b, files)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		stop := peakHeapSampler()
+		if _, err := New(nil).TraceDirectory(dir); err != nil {
+			b.Fatal(err)
+		}
+		if p := stop(); p > peak {
+			peak = p
+		}
+	}
+	b.ReportMetric(float64(peak)/(1<<20), "peak-heap-MB")
+}
+
+func BenchmarkTraceDirectory100(b *testing.B)  { benchmarkTraceDirectory(b, 100) }
+func BenchmarkTraceDirectory500(b *testing.B)  { benchmarkTraceDirectory(b, 500) }
+func BenchmarkTraceDirectory1000(b *testing.B) { benchmarkTraceDirectory(b, 1000) }
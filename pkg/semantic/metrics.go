@@ -0,0 +1,234 @@
+package semantic
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// ScanMetrics summarizes a scan for dashboards and badges
+type ScanMetrics struct {
+	Files            int                      `json:"files"`
+	ParseErrors      int                      `json:"parse_errors"`
+	Sources          int                      `json:"sources"`
+	UserInputSources int                      `json:"user_input_sources"` // Sources of HTTP request data
+	Flows            int                      `json:"flows"`
+	CrossFileFlows   int                      `json:"cross_file_flows"`
+	EntryPoints      int                      `json:"entry_points"`
+	Warnings         int                      `json:"warnings"`
+	Incomplete       bool                     `json:"incomplete"`
+	DurationMs       float64                  `json:"duration_ms"`
+	CacheHitRate     float64                  `json:"cache_hit_rate"`
+	PeakHeapMB       uint64                   `json:"peak_heap_mb"`
+	BySourceType     map[types.SourceType]int `json:"by_source_type"`
+}
+
+// Metrics returns the metrics of a scan
+func (r *TraceResult) Metrics() *ScanMetrics {
+	m := &ScanMetrics{
+		Sources:      len(r.Sources),
+		EntryPoints:  len(r.EntryPoints),
+		Incomplete:   r.Incomplete != nil,
+		BySourceType: make(map[types.SourceType]int),
+	}
+	for _, src := range r.Sources {
+		m.BySourceType[src.SourceType]++
+		if isRequestInput(src.SourceType) {
+			m.UserInputSources++
+		}
+	}
+	for _, n := range r.WarningCounts {
+		m.Warnings += n
+	}
+	if s := r.Stats; s != nil {
+		m.Files = s.FilesParsed
+		m.ParseErrors = s.ParseErrors
+		m.Flows = s.FlowsTraced
+		m.CrossFileFlows = s.CrossFileFlows
+		m.DurationMs = s.TotalDuration.Seconds() * 1000
+		m.PeakHeapMB = s.PeakHeapMB
+		if lookups := s.CacheHits + s.CacheMisses; lookups > 0 {
+			m.CacheHitRate = float64(s.CacheHits) / float64(lookups)
+		}
+	}
+	return m
+}
+
+// isRequestInput reports whether a source type is data of the HTTP request
+// (see sources.PHPSuperglobals for what counts as user input)
+func isRequestInput(st types.SourceType) bool {
+	return strings.HasPrefix(string(st), "http_") || st == types.SourceUserInput
+}
+
+// ToMetricsJSON renders the scan metrics as JSON
+func (r *TraceResult) ToMetricsJSON() (string, error) {
+	data, err := json.MarshalIndent(r.Metrics(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Badge colors: no user input read, user input read, scan incomplete
+const (
+	badgeColorClean      = "#4c1"
+	badgeColorUserInput  = "#fe7d37"
+	badgeColorIncomplete = "#9f9f9f"
+)
+
+// ToBadgeSVG renders a flat badge of the source count, e.g.
+// "input sources | 42 (7 user)", for READMEs and dashboards
+func (r *TraceResult) ToBadgeSVG() string {
+	m := r.Metrics()
+	value := fmt.Sprintf("%d", m.Sources)
+	if m.UserInputSources > 0 {
+		value += fmt.Sprintf(" (%d user)", m.UserInputSources)
+	}
+	color := badgeColorClean
+	switch {
+	case m.Incomplete:
+		value += " partial"
+		color = badgeColorIncomplete
+	case m.UserInputSources > 0:
+		color = badgeColorUserInput
+	}
+	return badgeSVG("input sources", value, color)
+}
+
+// badgeSVG draws a two-part badge. Text widths are estimated at 7px per
+// character of 11px Verdana, which is close enough for digits and lowercase.
+func badgeSVG(label, value, color string) string {
+	lw := len(label)*7 + 10
+	vw := len(value)*7 + 10
+	label, value = html.EscapeString(label), html.EscapeString(value)
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, lw+vw, label, value)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, value)
+	fmt.Fprintf(&b, `<rect width="%d" height="20" fill="#555"/>`, lw)
+	fmt.Fprintf(&b, `<rect x="%d" width="%d" height="20" fill="%s"/>`, lw, vw, color)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(&b, `<text x="%d" y="14">%s</text>`, lw/2, label)
+	fmt.Fprintf(&b, `<text x="%d" y="14">%s</text>`, lw+vw/2, value)
+	b.WriteString("</g></svg>\n")
+	return b.String()
+}
+
+// MetricsCollector aggregates metrics across the scans of a long-running
+// process and serves them in the Prometheus text format:
+//
+//	collector := semantic.NewMetricsCollector()
+//	http.Handle("/metrics", collector)
+//	...
+//	result, err := tracer.TraceDirectory(dir)
+//	collector.Record(result, err)
+//
+// It is safe for concurrent use.
+type MetricsCollector struct {
+	mu              sync.Mutex
+	scans           int
+	failedScans     int
+	incompleteScans int
+	durationSeconds float64 // Sum over scans
+	cacheHits       int64
+	cacheMisses     int64
+	peakHeapMB      uint64
+	flows           int
+	sources         map[types.SourceType]int
+	lastSources     int
+}
+
+// NewMetricsCollector creates an empty collector
+func NewMetricsCollector() *MetricsCollector {
+	return &MetricsCollector{sources: make(map[types.SourceType]int)}
+}
+
+// Record adds a scan to the metrics; err is the scan's error, if any
+func (c *MetricsCollector) Record(r *TraceResult, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scans++
+	if err != nil || r == nil {
+		c.failedScans++
+		return
+	}
+	if r.Incomplete != nil {
+		c.incompleteScans++
+	}
+	if s := r.Stats; s != nil {
+		c.durationSeconds += s.TotalDuration.Seconds()
+		c.cacheHits += s.CacheHits
+		c.cacheMisses += s.CacheMisses
+		c.flows += s.FlowsTraced
+		if s.PeakHeapMB > c.peakHeapMB {
+			c.peakHeapMB = s.PeakHeapMB
+		}
+	}
+	for _, src := range r.Sources {
+		c.sources[src.SourceType]++
+	}
+	c.lastSources = len(r.Sources)
+}
+
+// WritePrometheus writes the metrics in the Prometheus text exposition format
+func (c *MetricsCollector) WritePrometheus(w io.Writer) error {
+	_, err := io.WriteString(w, c.prometheusText())
+	return err
+}
+
+// prometheusText renders the metrics in the Prometheus text exposition format
+func (c *MetricsCollector) prometheusText() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var b strings.Builder
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	metric("inputtracer_scans_total", "counter", "Scans recorded, by outcome.")
+	fmt.Fprintf(&b, "inputtracer_scans_total{result=\"ok\"} %d\n", c.scans-c.failedScans-c.incompleteScans)
+	fmt.Fprintf(&b, "inputtracer_scans_total{result=\"incomplete\"} %d\n", c.incompleteScans)
+	fmt.Fprintf(&b, "inputtracer_scans_total{result=\"error\"} %d\n", c.failedScans)
+	metric("inputtracer_scan_duration_seconds", "summary", "Duration of scans that returned a result, complete or not.")
+	fmt.Fprintf(&b, "inputtracer_scan_duration_seconds_sum %g\n", c.durationSeconds)
+	fmt.Fprintf(&b, "inputtracer_scan_duration_seconds_count %d\n", c.scans-c.failedScans)
+	metric("inputtracer_cache_hits_total", "counter", "Assignment lookups answered from the trace cache or index.")
+	fmt.Fprintf(&b, "inputtracer_cache_hits_total %d\n", c.cacheHits)
+	metric("inputtracer_cache_misses_total", "counter", "Assignment lookups that parsed the file.")
+	fmt.Fprintf(&b, "inputtracer_cache_misses_total %d\n", c.cacheMisses)
+	metric("inputtracer_heap_high_water_bytes", "gauge", "Highest heap in use sampled during any scan.")
+	fmt.Fprintf(&b, "inputtracer_heap_high_water_bytes %d\n", c.peakHeapMB<<20)
+	metric("inputtracer_flows_total", "counter", "Flows traced.")
+	fmt.Fprintf(&b, "inputtracer_flows_total %d\n", c.flows)
+	metric("inputtracer_sources_total", "counter", "Input sources found, by source type.")
+	sourceTypes := make([]string, 0, len(c.sources))
+	for st := range c.sources {
+		sourceTypes = append(sourceTypes, string(st))
+	}
+	sort.Strings(sourceTypes)
+	for _, st := range sourceTypes {
+		fmt.Fprintf(&b, "inputtracer_sources_total{source_type=%q} %d\n", st, c.sources[types.SourceType(st)])
+	}
+	metric("inputtracer_last_scan_sources", "gauge", "Input sources found by the latest scan that returned a result.")
+	fmt.Fprintf(&b, "inputtracer_last_scan_sources %d\n", c.lastSources)
+	return b.String()
+}
+
+// ServeHTTP serves the metrics, so the collector can be mounted at /metrics.
+// The body is sent with its length, and a failed write aborts the response so
+// the server drops the connection rather than leave a truncated scrape.
+func (c *MetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	body := c.prometheusText()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if _, err := io.WriteString(w, body); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,80 @@
+package semantic
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestScanMetrics(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+$id = $_GET['id'];
+$name = $_POST['name'];
+$home = getenv('HOME');
+echo $id;
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := result.Metrics()
+	if m.Sources != 3 || m.UserInputSources != 2 {
+		t.Errorf("sources = %d (%d user), want 3 (2 user)", m.Sources, m.UserInputSources)
+	}
+	if m.BySourceType[types.SourceHTTPGet] != 1 || m.BySourceType[types.SourceEnvVar] != 1 {
+		t.Errorf("by source type = %v", m.BySourceType)
+	}
+	if m.PeakHeapMB == 0 {
+		t.Error("peak heap not sampled")
+	}
+
+	badge := result.ToBadgeSVG()
+	if !strings.HasPrefix(badge, "<svg") || !strings.Contains(badge, "3 (2 user)") || !strings.Contains(badge, badgeColorUserInput) {
+		t.Errorf("unexpected badge: %s", badge)
+	}
+
+	collector := NewMetricsCollector()
+	collector.Record(result, nil)
+	collector.Record(nil, errors.New("scan failed"))
+	incomplete := *result
+	incomplete.Incomplete = ErrTimeLimit
+	collector.Record(&incomplete, nil)
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`inputtracer_scans_total{result="ok"} 1`,
+		`inputtracer_scans_total{result="error"} 1`,
+		`inputtracer_scans_total{result="incomplete"} 1`,
+		`inputtracer_scan_duration_seconds_count 2`,
+		`inputtracer_sources_total{source_type="http_get"} 2`,
+		`inputtracer_last_scan_sources 3`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
+
+// failingWriter is a response writer whose client has gone away
+type failingWriter struct{ *httptest.ResponseRecorder }
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("connection reset") }
+
+func (failingWriter) WriteString(string) (int, error) { return 0, errors.New("connection reset") }
+
+func TestMetricsCollectorServeHTTPWriteError(t *testing.T) {
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+		}
+	}()
+	NewMetricsCollector().ServeHTTP(failingWriter{httptest.NewRecorder()}, httptest.NewRequest("GET", "/metrics", nil))
+}
@@ -0,0 +1,85 @@
+package semantic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestKotlinAndSwiftSources(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "Routes.kt", `import io.ktor.server.application.*
+
+class UserController {
+    fun list(@RequestParam name: String): String {
+        val q = name
+        return q
+    }
+}
+
+fun Application.module() {
+    routing {
+        get("/hello") {
+            val id = call.parameters["id"]
+            val body = call.receive<User>()
+        }
+    }
+}
+`)
+	writeFile(t, dir, "UserController.swift", `import Vapor
+
+struct UserController {
+    func index(req: Request) throws -> String {
+        let name = req.query["name"]
+        let user = try req.content.decode(User.self)
+        return name ?? ""
+    }
+}
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type node struct {
+		language string
+		line     int
+		nodeType types.FlowNodeType
+		name     string
+	}
+	got := make(map[node]types.SourceType)
+	for _, s := range result.Sources {
+		got[node{s.Language, s.Line, s.Type, s.Name}] = s.SourceType
+	}
+	for _, n := range result.FlowMap.AllNodes {
+		if n.Type == types.NodeVariable {
+			got[node{n.Language, n.Line, n.Type, n.Name}] = ""
+		}
+	}
+
+	tests := []struct {
+		node       node
+		sourceType types.SourceType
+	}{
+		{node{"kotlin", 4, types.NodeSource, "name"}, types.SourceHTTPGet},
+		{node{"kotlin", 5, types.NodeVariable, "q"}, ""},
+		{node{"kotlin", 13, types.NodeSource, "call.parameters"}, types.SourceHTTPRequest},
+		{node{"kotlin", 13, types.NodeVariable, "id"}, ""},
+		{node{"kotlin", 14, types.NodeSource, "call.receive"}, types.SourceHTTPBody},
+		{node{"swift", 5, types.NodeSource, "req.query"}, types.SourceHTTPGet},
+		{node{"swift", 5, types.NodeVariable, "name"}, ""},
+		{node{"swift", 6, types.NodeSource, "req.content"}, types.SourceHTTPBody},
+		{node{"swift", 6, types.NodeVariable, "user"}, ""},
+	}
+	for _, tt := range tests {
+		sourceType, ok := got[tt.node]
+		if !ok {
+			t.Errorf("missing %+v in %+v", tt.node, got)
+			continue
+		}
+		if sourceType != tt.sourceType {
+			t.Errorf("%+v: source type = %s, want %s", tt.node, sourceType, tt.sourceType)
+		}
+	}
+}
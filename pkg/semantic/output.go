@@ -11,53 +11,94 @@ import (
 
 // ToJSON converts the trace result to JSON format
 func ToJSON(r *TraceResult) (string, error) {
+	return ToJSONWithOptions(r, WriteOptions{})
+}
+
+// ToJSONWithOptions converts the trace result to JSON format, embedding the
+// code context and other extras requested by opts
+func ToJSONWithOptions(r *TraceResult, opts WriteOptions) (string, error) {
+	reader := newContextReader(r, opts)
 	output := struct {
 		Stats struct {
 			FilesScanned   int     `json:"files_scanned"`
 			FilesParsed    int     `json:"files_parsed"`
 			ParseErrors    int     `json:"parse_errors"`
 			SourcesFound   int     `json:"sources_found"`
+			SourcesSkipped int     `json:"sources_skipped,omitempty"`
+			SourcesDead    int     `json:"sources_unreachable,omitempty"`
 			FlowsTraced    int     `json:"flows_traced"`
 			CrossFileFlows int     `json:"cross_file_flows"`
 			DurationMs     float64 `json:"duration_ms"`
 		} `json:"stats"`
+		Budget  *types.BudgetReport `json:"budget,omitempty"`
 		Sources []struct {
-			ID         string `json:"id"`
-			Type       string `json:"type"`
-			Name       string `json:"name"`
-			File       string `json:"file"`
-			Line       int    `json:"line"`
-			Column     int    `json:"column"`
-			SourceType string `json:"source_type"`
-			SourceKey  string `json:"source_key,omitempty"`
-			Snippet    string `json:"snippet"`
+			ID         string                 `json:"id"`
+			Type       string                 `json:"type"`
+			Name       string                 `json:"name"`
+			File       string                 `json:"file"`
+			Line       int                    `json:"line"`
+			Column     int                    `json:"column"`
+			SourceType string                 `json:"source_type"`
+			SourceKey  string                 `json:"source_key,omitempty"`
+			Canonical  string                 `json:"canonical,omitempty"`
+			Labels     []string               `json:"labels,omitempty"`
+			Layer      string                 `json:"layer,omitempty"`
+			Snippet    string                 `json:"snippet"`
+			Constraint *types.ParamConstraint `json:"constraint,omitempty"`
+			Context    []ContextLine          `json:"context,omitempty"`
 		} `json:"sources"`
 		Nodes []struct {
-			ID       string `json:"id"`
-			Type     string `json:"type"`
-			Name     string `json:"name"`
-			File     string `json:"file"`
-			Line     int    `json:"line"`
-			Snippet  string `json:"snippet"`
-			Language string `json:"language"`
+			ID       string        `json:"id"`
+			Type     string        `json:"type"`
+			Name     string        `json:"name"`
+			File     string        `json:"file"`
+			Line     int           `json:"line"`
+			Snippet  string        `json:"snippet"`
+			Language string        `json:"language"`
+			Labels   []string      `json:"labels,omitempty"`
+			Layer    string        `json:"layer,omitempty"`
+			Context  []ContextLine `json:"context,omitempty"`
 		} `json:"nodes"`
 		Edges []struct {
-			From  string `json:"from"`
-			To    string `json:"to"`
-			Type  string `json:"type"`
-			Label string `json:"label"`
+			From         string              `json:"from"`
+			To           string              `json:"to"`
+			Type         string              `json:"type"`
+			Label        string              `json:"label"`
+			IncludeChain *types.IncludeChain `json:"include_chain,omitempty"`
 		} `json:"edges"`
 		ByLanguage map[string]struct {
 			Files   int `json:"files"`
 			Sources int `json:"sources"`
 		} `json:"by_language"`
+		ByLayer map[string]struct {
+			Sources int `json:"sources"`
+			Nodes   int `json:"nodes"`
+		} `json:"by_layer,omitempty"`
+		Frameworks  *FrameworkReport `json:"frameworks,omitempty"`
+		Artifact    *Artifact        `json:"artifact,omitempty"` // Image or tarball scanned (TraceArtifact)
+		EntryPoints   []*EntryPoint                 `json:"entry_points,omitempty"`
+		SkippedSources []string                     `json:"skipped_sources,omitempty"` // IDs of sources not traced because of the source cap
+		Warnings      []types.AnalysisWarning       `json:"warnings,omitempty"`
+		WarningCounts map[types.WarningCategory]int `json:"warning_counts,omitempty"`
 	}{}
 
+	output.Frameworks = r.Frameworks
+	output.Artifact = r.Artifact
+	output.EntryPoints = r.EntryPoints
+	output.Budget = r.Budget
+	for _, src := range r.SkippedSources {
+		output.SkippedSources = append(output.SkippedSources, src.ID)
+	}
+	output.Warnings = r.Warnings
+	output.WarningCounts = r.WarningCounts
+
 	// Stats
 	output.Stats.FilesScanned = r.Stats.FilesScanned
 	output.Stats.FilesParsed = r.Stats.FilesParsed
 	output.Stats.ParseErrors = r.Stats.ParseErrors
 	output.Stats.SourcesFound = r.Stats.SourcesFound
+	output.Stats.SourcesSkipped = r.Stats.SourcesSkipped
+	output.Stats.SourcesDead = r.Stats.SourcesDead
 	output.Stats.FlowsTraced = r.Stats.FlowsTraced
 	output.Stats.CrossFileFlows = r.Stats.CrossFileFlows
 	output.Stats.DurationMs = r.Stats.TotalDuration.Seconds() * 1000
@@ -65,15 +106,20 @@ func ToJSON(r *TraceResult) (string, error) {
 	// Sources
 	for _, src := range r.Sources {
 		output.Sources = append(output.Sources, struct {
-			ID         string `json:"id"`
-			Type       string `json:"type"`
-			Name       string `json:"name"`
-			File       string `json:"file"`
-			Line       int    `json:"line"`
-			Column     int    `json:"column"`
-			SourceType string `json:"source_type"`
-			SourceKey  string `json:"source_key,omitempty"`
-			Snippet    string `json:"snippet"`
+			ID         string                 `json:"id"`
+			Type       string                 `json:"type"`
+			Name       string                 `json:"name"`
+			File       string                 `json:"file"`
+			Line       int                    `json:"line"`
+			Column     int                    `json:"column"`
+			SourceType string                 `json:"source_type"`
+			SourceKey  string                 `json:"source_key,omitempty"`
+			Canonical  string                 `json:"canonical,omitempty"`
+			Labels     []string               `json:"labels,omitempty"`
+			Layer      string                 `json:"layer,omitempty"`
+			Snippet    string                 `json:"snippet"`
+			Constraint *types.ParamConstraint `json:"constraint,omitempty"`
+			Context    []ContextLine          `json:"context,omitempty"`
 		}{
 			ID:         src.ID,
 			Type:       string(src.Type),
@@ -83,20 +129,28 @@ func ToJSON(r *TraceResult) (string, error) {
 			Column:     src.Column,
 			SourceType: string(src.SourceType),
 			SourceKey:  src.SourceKey,
+			Canonical:  src.Canonical,
+			Labels:     src.Labels,
+			Layer:      src.Layer,
 			Snippet:    src.Snippet,
+			Constraint: src.Constraint,
+			Context:    reader.around(src.FilePath, src.Line),
 		})
 	}
 
 	// Nodes
 	for _, node := range r.FlowMap.AllNodes {
 		output.Nodes = append(output.Nodes, struct {
-			ID       string `json:"id"`
-			Type     string `json:"type"`
-			Name     string `json:"name"`
-			File     string `json:"file"`
-			Line     int    `json:"line"`
-			Snippet  string `json:"snippet"`
-			Language string `json:"language"`
+			ID       string        `json:"id"`
+			Type     string        `json:"type"`
+			Name     string        `json:"name"`
+			File     string        `json:"file"`
+			Line     int           `json:"line"`
+			Snippet  string        `json:"snippet"`
+			Language string        `json:"language"`
+			Labels   []string      `json:"labels,omitempty"`
+			Layer    string        `json:"layer,omitempty"`
+			Context  []ContextLine `json:"context,omitempty"`
 		}{
 			ID:       node.ID,
 			Type:     string(node.Type),
@@ -105,21 +159,26 @@ func ToJSON(r *TraceResult) (string, error) {
 			Line:     node.Line,
 			Snippet:  node.Snippet,
 			Language: node.Language,
+			Labels:   node.Labels,
+			Layer:    node.Layer,
+			Context:  reader.around(node.FilePath, node.Line),
 		})
 	}
 
 	// Edges
 	for _, edge := range r.FlowMap.AllEdges {
 		output.Edges = append(output.Edges, struct {
-			From  string `json:"from"`
-			To    string `json:"to"`
-			Type  string `json:"type"`
-			Label string `json:"label"`
+			From         string              `json:"from"`
+			To           string              `json:"to"`
+			Type         string              `json:"type"`
+			Label        string              `json:"label"`
+			IncludeChain *types.IncludeChain `json:"include_chain,omitempty"`
 		}{
-			From:  edge.From,
-			To:    edge.To,
-			Type:  string(edge.Type),
-			Label: edge.Description,
+			From:         edge.From,
+			To:           edge.To,
+			Type:         string(edge.Type),
+			Label:        edge.Description,
+			IncludeChain: edge.IncludeChain,
 		})
 	}
 
@@ -138,6 +197,23 @@ func ToJSON(r *TraceResult) (string, error) {
 		}
 	}
 
+	// By layer
+	for layer, stats := range r.Stats.ByLayer {
+		if output.ByLayer == nil {
+			output.ByLayer = make(map[string]struct {
+				Sources int `json:"sources"`
+				Nodes   int `json:"nodes"`
+			})
+		}
+		output.ByLayer[layer] = struct {
+			Sources int `json:"sources"`
+			Nodes   int `json:"nodes"`
+		}{
+			Sources: stats.Sources,
+			Nodes:   stats.Nodes,
+		}
+	}
+
 	data, err := json.MarshalIndent(output, "", "  ")
 	if err != nil {
 		return "", err
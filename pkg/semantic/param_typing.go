@@ -0,0 +1,24 @@
+package semantic
+
+import (
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// sourceConstraintInferrer is implemented by analyzers that infer the effective
+// type of input parameters from their uses (currently PHP)
+type sourceConstraintInferrer interface {
+	InferSourceConstraints(root *sitter.Node, source []byte, sources []*types.FlowNode)
+}
+
+// GetSourcesByParamType returns sources whose inferred parameter type matches.
+// Free-string parameters are usually the most interesting to review first.
+func (r *TraceResult) GetSourcesByParamType(paramType types.ParamType) []*types.FlowNode {
+	var result []*types.FlowNode
+	for _, source := range r.Sources {
+		if source.Constraint != nil && source.Constraint.Type == paramType {
+			result = append(result, source)
+		}
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package semantic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestInferSourceConstraints(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want types.ParamType
+	}{
+		{"cast result assigned", `$page = $_GET['v'];
+$n = (int)$page;
+echo $n;`, types.ParamInt},
+		{"raw use before cast", `$page = $_GET['v'];
+echo $page;
+$n = (int)$page;`, types.ParamFreeString},
+		{"raw use after cast", `$page = $_GET['v'];
+$n = intval($page);
+echo "Page " . $page;`, types.ParamFreeString},
+		{"reassigned through a cast", `$page = $_GET['v'];
+$page = (int)$page;
+echo $page;`, types.ParamInt},
+		{"comparison guarding nothing", `$sort = $_GET['v'];
+if ($sort == 'asc') { $dir = 1; }
+query($sort);`, types.ParamFreeString},
+		{"in_array guarding nothing", `$sort = $_GET['v'];
+$known = in_array($sort, ['asc', 'desc']);
+query($sort);`, types.ParamFreeString},
+		{"in_array guard exits", `$sort = $_GET['v'];
+if (!in_array($sort, ['asc', 'desc'])) { exit; }
+query($sort);`, types.ParamEnum},
+		{"in_array === false guard returns", `function order() {
+    $sort = $_GET['v'];
+    if (in_array($sort, ['asc', 'desc']) === false) return;
+    query($sort);
+}`, types.ParamEnum},
+		{"use inside the checked branch", `$sort = $_GET['v'];
+if (isset($sort) && in_array($sort, ['asc', 'desc'])) { query($sort); }`, types.ParamEnum},
+		{"use after the checked branch", `$sort = $_GET['v'];
+if (in_array($sort, ['asc', 'desc'])) { query($sort); }
+query($sort);`, types.ParamFreeString},
+		{"either literal in the branch", `$sort = $_GET['v'];
+if ($sort == 'asc' || $sort == 'desc') { query($sort); }`, types.ParamEnum},
+		{"disjunction with another condition", `$sort = $_GET['v'];
+if ($sort == 'asc' || $force) { query($sort); }`, types.ParamFreeString},
+		{"switch cases", `$action = $_GET['v'];
+switch ($action) { case 'view': show($action); break; case 'edit': edit(); break; }`, types.ParamEnum},
+		{"switch default reads the value", `$action = $_GET['v'];
+switch ($action) { case 'view': break; default: show($action); }`, types.ParamFreeString},
+		{"preg_match guard dies", `$slug = $_GET['v'];
+if (!preg_match('/^[a-z]+$/', $slug)) die('bad slug');
+load($slug);`, types.ParamPattern},
+		{"only compared", `$mode = $_GET['v'];
+if ($mode === 'full') { $full = true; }`, types.ParamEnum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "index.php", "<?php\n"+tt.code+"\n")
+			result, err := New(nil).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, src := range result.Sources {
+				if src.Snippet != "$_GET['v']" {
+					continue
+				}
+				if src.Constraint == nil || src.Constraint.Type != tt.want {
+					t.Errorf("constraint = %+v, want %s", src.Constraint, tt.want)
+				}
+				return
+			}
+			t.Fatal("source $_GET['v'] not found")
+		})
+	}
+}
+
+func TestTypedGetterConstraints(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		snippet     string
+		want        types.ParamType
+		wantDefault string
+		wantPattern string
+	}{
+		{"typed getter with default", `$page = $request->query->getInt('page', 1);
+echo $page;`, "$request->query->getInt('page', 1)", types.ParamInt, "1", ""},
+		{"pattern getter", `$zip = $request->query->getDigits('zip');`,
+			"$request->query->getDigits('zip')", types.ParamPattern, "", `^[0-9]*$`},
+		{"untyped getter keeps its default", `$q = $request->query->get('q', 'all');
+echo $q;`, "$request->query->get('q', 'all')", types.ParamFreeString, "'all'", ""},
+		{"untyped getter typed by its uses", `$q = $request->query->get('q');
+$n = (int)$q;
+echo $n;`, "$request->query->get('q')", types.ParamInt, "", ""},
+		{"type flag", `$pid = $mybb->get_input('pid', MyBB::INPUT_INT);
+echo "Post " . $pid;`, "$mybb->get_input('pid', MyBB::INPUT_INT)", types.ParamInt, "", ""},
+		{"integer type flag", `$pid = $mybb->get_input('pid', 1);`,
+			"$mybb->get_input('pid', 1)", types.ParamInt, "", ""},
+		{"string type flag", `$subject = $mybb->get_input('subject', MyBB::INPUT_STRING);
+$n = (int)$subject;`, "$mybb->get_input('subject', MyBB::INPUT_STRING)", types.ParamInt, "", ""},
+		{"no type flag", `$subject = $mybb->get_input('subject');
+echo $subject;`, "$mybb->get_input('subject')", types.ParamFreeString, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "index.php", "<?php\n"+tt.code+"\n")
+			result, err := New(nil).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, src := range result.Sources {
+				if src.Snippet != tt.snippet {
+					continue
+				}
+				c := src.Constraint
+				if c == nil {
+					t.Fatal("no constraint")
+				}
+				if c.Type != tt.want || c.Default != tt.wantDefault || c.Pattern != tt.wantPattern {
+					t.Errorf("constraint = %+v, want type %s default %q pattern %q", c, tt.want, tt.wantDefault, tt.wantPattern)
+				}
+				return
+			}
+			t.Fatalf("source %s not found", tt.snippet)
+		})
+	}
+}
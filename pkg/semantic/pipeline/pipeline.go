@@ -0,0 +1,270 @@
+// Package pipeline combines the semantic scan with symbolic deep-dives. After
+// TraceDirectory, the carrier property accesses whose provenance is incomplete
+// (e.g. $mybb->input['uid'], known to be input but not from which superglobal)
+// are traced through the symbolic engine, and both results are merged into
+// one report.
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/symbolic"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/php"
+)
+
+// DefaultTopN is the number of carrier expressions deep-traced by default
+const DefaultTopN = 20
+
+// Options controls the deep-dive phase
+type Options struct {
+	// TopN is the number of distinct carrier expressions deep-traced, most
+	// frequent first (0 = DefaultTopN, or all with the deep profile)
+	TopN int
+}
+
+// DeepTrace is the symbolic trace of one carrier expression
+type DeepTrace struct {
+	Expression  string                    `json:"expression"`
+	FilePath    string                    `json:"file_path"` // First occurrence, used as trace context
+	Line        int                       `json:"line"`
+	Occurrences int                       `json:"occurrences"` // Sources in the scan reading this expression
+	Steps       []symbolic.FlowStep       `json:"steps,omitempty"`
+	Sources     []symbolic.UltimateSource `json:"ultimate_sources,omitempty"`
+	Warnings    []types.AnalysisWarning   `json:"warnings,omitempty"`
+	Error       string                    `json:"error,omitempty"`
+
+	// RuntimeAssisted is true when the trace relied on the scan's runtime hints
+	RuntimeAssisted bool `json:"runtime_assisted,omitempty"`
+
+	// Candidates are the classes declaring the member read, when the
+	// object's instantiation was not found
+	Candidates []symbolic.CandidateClass `json:"candidate_classes,omitempty"`
+}
+
+// Report is the merged result of the semantic scan and the deep-dives
+type Report struct {
+	Scan       *semantic.TraceResult
+	DeepTraces []DeepTrace
+
+	// Warnings are the files the symbolic engine could not load; the deep
+	// traces ran without them
+	Warnings []types.AnalysisWarning
+}
+
+// carrier groups the scan sources reading the same carrier expression
+type carrier struct {
+	expression string
+	sources    []*types.FlowNode
+}
+
+// Run scans path, then deep-traces the top carrier expressions with the
+// symbolic engine. Resolved ultimate sources are also recorded on the scan's
+// source nodes as Metadata["ultimate_sources"].
+func Run(path string, config *semantic.Config, opts Options) (*Report, error) {
+	result, err := semantic.New(config).TraceDirectory(path)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Scan: result}
+
+	carriers := incompleteCarriers(result.Sources)
+	topN := opts.TopN
+	if topN <= 0 {
+		topN = DefaultTopN
+		if config != nil && config.Profile == semantic.ProfileDeep {
+			topN = len(carriers)
+		}
+	}
+	if len(carriers) > topN {
+		carriers = carriers[:topN]
+	}
+	if len(carriers) == 0 {
+		return report, nil
+	}
+
+	var sandboxRoots []string
+	if config != nil {
+		sandboxRoots = config.SandboxRoots
+	}
+	engine, warnings, err := newEngine(result, sandboxRoots)
+	if err != nil {
+		return nil, err
+	}
+	report.Warnings = warnings
+	if config != nil {
+		engine.SetYield(config.YieldEvery, config.YieldHook)
+	}
+	for _, c := range carriers {
+		report.DeepTraces = append(report.DeepTraces, deepTrace(engine, c))
+	}
+	return report, nil
+}
+
+// incompleteCarriers returns the property accesses and method calls known to
+// carry input without a resolved superglobal, grouped by expression, most
+// frequent first
+func incompleteCarriers(sources []*types.FlowNode) []carrier {
+	index := make(map[string]int)
+	var carriers []carrier
+	for _, src := range sources {
+		if src.SourceType != types.SourceUserInput || src.Language != "php" || !strings.Contains(src.Snippet, "->") {
+			continue
+		}
+		i, ok := index[src.Snippet]
+		if !ok {
+			i = len(carriers)
+			index[src.Snippet] = i
+			carriers = append(carriers, carrier{expression: src.Snippet})
+		}
+		carriers[i].sources = append(carriers[i].sources, src)
+	}
+	for i := range carriers {
+		sort.SliceStable(carriers[i].sources, func(a, b int) bool {
+			sa, sb := carriers[i].sources[a], carriers[i].sources[b]
+			if sa.FilePath != sb.FilePath {
+				return sa.FilePath < sb.FilePath
+			}
+			return sa.Line < sb.Line
+		})
+	}
+	sort.SliceStable(carriers, func(a, b int) bool {
+		if len(carriers[a].sources) != len(carriers[b].sources) {
+			return len(carriers[a].sources) > len(carriers[b].sources)
+		}
+		return carriers[a].expression < carriers[b].expression
+	})
+	return carriers
+}
+
+// newEngine loads the scanned PHP files into a symbolic execution engine. The
+// scan releases per-file symbol tables and method bodies, so files are
+// re-parsed and their symbol tables rebuilt; the engine reloads their ASTs on
+// demand through its file cache. Files are read only inside sandboxRoots,
+// like the scan (see semantic.Config.SandboxRoots). Files that cannot be read
+// or parsed again are skipped with a warning.
+func newEngine(result *semantic.TraceResult, sandboxRoots []string) (*symbolic.ExecutionEngine, []types.AnalysisWarning, error) {
+	engine := symbolic.NewExecutionEngine()
+	engine.SetSandbox(sandboxRoots)
+	sandbox := types.NewSandbox(sandboxRoots)
+	engine.SetServiceClasses(result.RuntimeHints.ServiceClasses())
+	phpAnalyzer := analyzer.DefaultRegistry.Get("php")
+	if phpAnalyzer == nil {
+		return nil, nil, fmt.Errorf("no php analyzer registered")
+	}
+
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(php.GetLanguage())
+	var warnings []types.AnalysisWarning
+	for _, filePath := range sortedFiles(result.Files) {
+		if err := loadFile(engine, parser, phpAnalyzer, sandbox, filePath); err != nil {
+			warnings = append(warnings, types.WarningFromError(&types.ParseError{FilePath: filePath, Err: err}))
+		}
+	}
+	return engine, warnings, nil
+}
+
+// sortedFiles returns the parsed PHP files of a scan in order
+func sortedFiles(files map[string]*semantic.FileInfo) []string {
+	var paths []string
+	for filePath, fileInfo := range files {
+		if fileInfo.Language == "php" && fileInfo.Error == nil {
+			paths = append(paths, filePath)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// loadFile parses a file again and adds it and its symbol table to the engine
+func loadFile(engine *symbolic.ExecutionEngine, parser *sitter.Parser, phpAnalyzer analyzer.LanguageAnalyzer, sandbox *types.Sandbox, filePath string) error {
+	content, err := sandbox.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return err
+	}
+	defer tree.Close()
+	st, err := phpAnalyzer.BuildSymbolTable(filePath, content, tree.RootNode())
+	if err != nil {
+		return err
+	}
+	engine.AddSymbolTable(filePath, st)
+	return engine.AddFile(filePath, nil)
+}
+
+// deepTrace traces a carrier expression from its first occurrence and
+// records the ultimate sources on every scan source reading it
+func deepTrace(engine *symbolic.ExecutionEngine, c carrier) DeepTrace {
+	first := c.sources[0]
+	trace := DeepTrace{
+		Expression:  c.expression,
+		FilePath:    first.FilePath,
+		Line:        first.Line,
+		Occurrences: len(c.sources),
+	}
+
+	flow, err := engine.TracePropertyAccessAt(c.expression, first.FilePath, first.Line)
+	if err != nil {
+		trace.Error = err.Error()
+		trace.Warnings = append(trace.Warnings, types.WarningFromError(err))
+	}
+	if flow == nil {
+		return trace
+	}
+	trace.Steps = flow.Steps
+	trace.RuntimeAssisted = flow.RuntimeAssisted
+	trace.Sources = flow.Sources
+	trace.Candidates = flow.Candidates
+	trace.Warnings = append(trace.Warnings, flow.Warnings...)
+
+	if len(flow.Sources) > 0 {
+		var ultimate []string
+		for _, us := range flow.Sources {
+			ultimate = append(ultimate, us.Expression)
+		}
+		for _, src := range c.sources {
+			if src.Metadata == nil {
+				src.Metadata = make(map[string]interface{})
+			}
+			src.Metadata["ultimate_sources"] = ultimate
+			if flow.RuntimeAssisted {
+				src.Metadata["runtime_assisted"] = true
+			}
+		}
+	}
+	return trace
+}
+
+// ToJSON renders the report as one JSON document: the scan (as
+// semantic.ToJSON renders it), the deep traces and the files they skipped
+func (r *Report) ToJSON() (string, error) {
+	scan, err := semantic.ToJSON(r.Scan)
+	if err != nil {
+		return "", err
+	}
+	output := struct {
+		Scan       json.RawMessage         `json:"scan"`
+		DeepTraces []DeepTrace             `json:"deep_traces"`
+		Warnings   []types.AnalysisWarning `json:"warnings,omitempty"`
+	}{
+		Scan:       json.RawMessage(scan),
+		DeepTraces: r.DeepTraces,
+		Warnings:   r.Warnings,
+	}
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding pipeline report: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestRunDeepTracesCarriers(t *testing.T) {
+	files := map[string]string{
+		"init.php": `<?php
+class Core {
+    public $input = array();
+    function __construct() { $this->input = $_GET; }
+}
+$mybb = new Core();
+`,
+		"show.php": `<?php
+require 'init.php';
+$name = $mybb->input['name'];
+$page = $mybb->input['page'];
+$again = $mybb->input['name'];
+`,
+	}
+	dir := t.TempDir()
+	for name, code := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := Run(dir, nil, Options{TopN: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.DeepTraces) != 1 {
+		t.Fatalf("deep traces = %d, want 1 (TopN)", len(report.DeepTraces))
+	}
+	trace := report.DeepTraces[0]
+	if trace.Expression != "$mybb->input['name']" || trace.Occurrences != 2 {
+		t.Errorf("traced %s (%d occurrences), want the most frequent carrier $mybb->input['name'] (2)", trace.Expression, trace.Occurrences)
+	}
+	if len(trace.Sources) == 0 || trace.Sources[0].Expression != "$_GET" {
+		t.Errorf("ultimate sources = %+v, want $_GET (error: %s)", trace.Sources, trace.Error)
+	}
+	if _, err := report.ToJSON(); err != nil {
+		t.Errorf("ToJSON: %v", err)
+	}
+}
+
+func TestNewEngineSkipsUnreadableFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, code := range map[string]string{
+		"a.php": "<?php\n$a = $_GET['a'];\n",
+		"b.php": "<?php\n$b = $_GET['b'];\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := semantic.New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone := filepath.Join(dir, "b.php")
+	if err := os.Remove(gone); err != nil {
+		t.Fatal(err)
+	}
+
+	engine, warnings, err := newEngine(result, nil)
+	if err != nil || engine == nil {
+		t.Fatalf("newEngine: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].FilePath != gone || warnings[0].Category != types.WarningParseError {
+		t.Errorf("warnings = %+v, want a parse_error for %s", warnings, gone)
+	}
+}
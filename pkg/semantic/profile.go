@@ -0,0 +1,124 @@
+package semantic
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// Profile is a preset of analysis limits trading precision for cost, so a
+// scan can be tuned without setting each limit
+type Profile string
+
+const (
+	// ProfileQuick only collects input sources; flows are not traced. Use it
+	// for inventories of the inputs a codebase reads and for CI checks, where
+	// a scan must finish in seconds on any codebase.
+	ProfileQuick Profile = "quick"
+	// ProfileStandard traces the flows of the 200 highest-priority sources to
+	// a call depth of 10 within 120MB. Used when no profile is set.
+	ProfileStandard Profile = "standard"
+	// ProfileDeep traces every source to a call depth of 25 with 10x the flow
+	// graph and 1GB of memory, stopping after 30 minutes, and pipeline.Run
+	// deep-traces every carrier with the symbolic engine instead of the top 20.
+	// Use it for audits of a single application; expect minutes per run.
+	ProfileDeep Profile = "deep"
+)
+
+// profileLimits are the limits a profile sets
+type profileLimits struct {
+	maxDepth         int
+	maxMemoryMB      int
+	maxTracedSources int
+	maxFlowNodes     int
+	maxFlowEdges     int
+	maxDuration      time.Duration
+}
+
+// profiles maps each profile to its limits
+var profiles = map[Profile]profileLimits{
+	ProfileQuick: {
+		maxDepth:    10,
+		maxMemoryMB: 120,
+	},
+	ProfileStandard: {
+		maxDepth:    10,
+		maxMemoryMB: 120, // 120MB internal -> ~180MB external
+	},
+	ProfileDeep: {
+		maxDepth:         25,
+		maxMemoryMB:      1024,
+		maxTracedSources: -1,
+		maxFlowNodes:     10 * types.DefaultMaxFlowNodes,
+		maxFlowEdges:     10 * types.DefaultMaxFlowEdges,
+		maxDuration:      30 * time.Minute,
+	},
+}
+
+// checkProfile fails for a profile that is neither empty nor one of the
+// Profile constants, rather than tracing it as standard
+func (c *Config) checkProfile() error {
+	if _, ok := profiles[c.Profile]; !ok && c.Profile != "" {
+		return fmt.Errorf("unknown profile %q (want %s, %s or %s)", c.Profile, ProfileQuick, ProfileStandard, ProfileDeep)
+	}
+	return nil
+}
+
+// applyProfile sets the limits of the configured profile that are left at
+// zero; limits set explicitly win. An empty profile is standard, as are the
+// limits of an unknown one, which checkProfile rejects before tracing.
+func applyProfile(config *Config) {
+	limits, ok := profiles[config.Profile]
+	if !ok {
+		limits = profiles[ProfileStandard]
+	}
+	if config.MaxDepth == 0 {
+		config.MaxDepth = limits.maxDepth
+	}
+	if config.MaxMemoryMB == 0 {
+		config.MaxMemoryMB = limits.maxMemoryMB
+	}
+	if config.MaxTracedSources == 0 {
+		config.MaxTracedSources = limits.maxTracedSources
+	}
+	if config.MaxFlowNodes == 0 {
+		config.MaxFlowNodes = limits.maxFlowNodes
+	}
+	if config.MaxFlowEdges == 0 {
+		config.MaxFlowEdges = limits.maxFlowEdges
+	}
+	if config.MaxDuration == 0 {
+		config.MaxDuration = limits.maxDuration
+	}
+}
+
+// tracesFlows reports whether the profile traces flows from the sources
+func (c *Config) tracesFlows() bool {
+	return c.Profile != ProfileQuick
+}
+
+// sourcesOnlyFlowMap is the flow map of a scan that does not trace flows:
+// the sources alone, each streamed as a finding without a flow
+func (t *Tracer) sourcesOnlyFlowMap(sources []*types.FlowNode) *types.FlowMap {
+	flowMap := types.NewFlowMapWithLimits(t.config.MaxFlowNodes, t.config.MaxFlowEdges)
+	for _, source := range sources {
+		flowMap.AddNode(*source)
+	}
+	for _, source := range sources {
+		if !t.emitFinding(source, flowMap) {
+			break
+		}
+	}
+	return flowMap
+}
+
+// timeExceeded reports whether the trace has run past Config.MaxDuration,
+// recording the time limit as the reason analysis stopped
+func (t *Tracer) timeExceeded(sourcesTraced int) bool {
+	if t.config.MaxDuration <= 0 || t.traceStart.IsZero() || time.Since(t.traceStart) < t.config.MaxDuration {
+		return false
+	}
+	t.recordIncomplete(&types.TimeLimitError{Limit: t.config.MaxDuration, SourcesTraced: sourcesTraced})
+	return true
+}
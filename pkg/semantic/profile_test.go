@@ -0,0 +1,80 @@
+package semantic
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		wantDepth   int
+		wantMemory  int
+		wantSources int
+		wantTime    time.Duration
+	}{
+		{"default", Config{}, 10, 120, 0, 0},
+		{"unknown gets standard limits", Config{Profile: "thorough"}, 10, 120, 0, 0},
+		{"deep", Config{Profile: ProfileDeep}, 25, 1024, -1, 30 * time.Minute},
+		{"explicit limits win", Config{Profile: ProfileDeep, MaxDepth: 3, MaxMemoryMB: 200}, 3, 200, -1, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.config
+			applyProfile(&c)
+			if c.MaxDepth != tt.wantDepth || c.MaxMemoryMB != tt.wantMemory || c.MaxTracedSources != tt.wantSources || c.MaxDuration != tt.wantTime {
+				t.Errorf("limits = depth %d, memory %d, sources %d, time %v; want %d, %d, %d, %v",
+					c.MaxDepth, c.MaxMemoryMB, c.MaxTracedSources, c.MaxDuration,
+					tt.wantDepth, tt.wantMemory, tt.wantSources, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir := writeFile(t, t.TempDir(), "index.php", `<?php
+$id = $_GET['id'];
+$copy = $id;
+$name = $_POST['name'];
+$other = $_COOKIE['other'];
+`)
+
+	config := DefaultConfig()
+	config.Profile = ProfileQuick
+	quick, err := New(config).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quick.Sources) != 3 || len(quick.FlowMap.AllEdges) != 0 || quick.Stats.FlowsTraced != 0 {
+		t.Errorf("quick: %d sources, %d edges, %d flows; want 3 sources and no flows",
+			len(quick.Sources), len(quick.FlowMap.AllEdges), quick.Stats.FlowsTraced)
+	}
+
+	standard, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(standard.FlowMap.AllEdges) == 0 {
+		t.Error("standard: no flows traced")
+	}
+
+	config = DefaultConfig()
+	config.Profile = "thorough"
+	if _, err := New(config).TraceDirectory(dir); err == nil {
+		t.Error("unknown profile: no error")
+	}
+
+	config = DefaultConfig()
+	config.MaxDuration = time.Nanosecond
+	timed, err := New(config).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(timed.Incomplete, ErrTimeLimit) || timed.WarningCounts[types.WarningTimeLimit] != 1 {
+		t.Errorf("incomplete = %v, warnings = %v; want one time limit", timed.Incomplete, timed.WarningCounts)
+	}
+}
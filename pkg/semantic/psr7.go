@@ -0,0 +1,166 @@
+package semantic
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// requestAttributeExtractor is implemented by analyzers that track PSR-7
+// request derivation (currently PHP)
+type requestAttributeExtractor interface {
+	ExtractRequestAttributes(root *sitter.Node, source []byte, filePath string, sources []*types.FlowNode) []*types.RequestAttribute
+	FindRequestAttributeSources(root *sitter.Node, source []byte, attrs map[string]*types.RequestAttribute) []*types.FlowNode
+}
+
+// RequestAttributes returns the PSR-7 request attributes set from tainted data, sorted by name
+func (t *Tracer) RequestAttributes() []*types.RequestAttribute {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	attrs := make([]*types.RequestAttribute, 0, len(t.requestAttributes))
+	for _, attr := range t.requestAttributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+	return attrs
+}
+
+// promoteRequestAttributes resolves which request attributes carry input and
+// adds a source node for every getAttribute() read of them. Middleware and
+// the handlers reading its attributes usually live in different files, so
+// this runs after all files are parsed.
+func (t *Tracer) promoteRequestAttributes() {
+	t.mu.Lock()
+	var all []*types.RequestAttribute
+	for _, fileInfo := range t.files {
+		all = append(all, fileInfo.RequestAttributes...)
+	}
+	t.requestAttributes = resolveRequestAttributes(all)
+	attrs := t.requestAttributes
+	t.mu.Unlock()
+
+	if len(attrs) == 0 {
+		return
+	}
+	if t.config.Verbose {
+		fmt.Printf("  Tracking %d tainted request attribute(s)\n", len(attrs))
+	}
+
+	t.addDerivedSources(
+		func(fileInfo *FileInfo) bool { return fileInfo.CalledNames["getattribute"] },
+		func(langAnalyzer analyzer.LanguageAnalyzer, root *sitter.Node, content []byte) []*types.FlowNode {
+			if extractor, ok := langAnalyzer.(requestAttributeExtractor); ok {
+				return extractor.FindRequestAttributeSources(root, content, attrs)
+			}
+			return nil
+		},
+		markRequestAttributeTaint,
+	)
+}
+
+// resolveRequestAttributes returns the tainted attributes keyed by name: those
+// set from an input source, then, until nothing changes, those set from a
+// tainted attribute (an attribute derived in one middleware from another)
+func resolveRequestAttributes(all []*types.RequestAttribute) map[string]*types.RequestAttribute {
+	tainted := make(map[string]*types.RequestAttribute)
+	for _, attr := range all {
+		if attr.SourceType != "" && tainted[attr.Name] == nil {
+			tainted[attr.Name] = attr
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, attr := range all {
+			if attr.SourceType != "" {
+				continue
+			}
+			for _, dep := range attr.DependsOn {
+				if origin, ok := tainted[dep]; ok {
+					attr.SourceType = origin.SourceType
+					if tainted[attr.Name] == nil {
+						tainted[attr.Name] = attr
+					}
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return tainted
+}
+
+// markRequestAttributeTaint marks cached assignments and call arguments that
+// contain one of the file's request attribute sources as tainted
+func markRequestAttributeTaint(fileInfo *FileInfo) {
+	reads := requestAttributeReads(fileInfo)
+	for _, assign := range fileInfo.Assignments {
+		if assign.IsTainted {
+			continue
+		}
+		if src := findRequestAttributeRead(assign.Source, reads[assign.Line]); src != nil {
+			assign.IsTainted = true
+			assign.TaintSource = src.Snippet
+		}
+	}
+	for _, call := range fileInfo.Calls {
+		for i := range call.Arguments {
+			arg := &call.Arguments[i]
+			if arg.IsTainted {
+				continue
+			}
+			if src := findRequestAttributeRead(arg.Value, reads[call.Line]); src != nil {
+				arg.IsTainted = true
+				arg.TaintSource = src.Snippet
+				call.HasTaintedArgs = true
+				call.TaintedArgIndices = append(call.TaintedArgIndices, i)
+			}
+		}
+	}
+}
+
+// requestAttributeReads returns the request attribute sources of a file by line
+func requestAttributeReads(fileInfo *FileInfo) map[int][]*types.FlowNode {
+	reads := make(map[int][]*types.FlowNode)
+	for _, src := range fileInfo.Sources {
+		if _, ok := src.Metadata["request_attribute"]; ok {
+			reads[src.Line] = append(reads[src.Line], src)
+		}
+	}
+	return reads
+}
+
+// findRequestAttributeRead returns the first of reads contained in expr
+func findRequestAttributeRead(expr string, reads []*types.FlowNode) *types.FlowNode {
+	for _, src := range reads {
+		if containsSourceName(expr, src.Snippet) {
+			return src
+		}
+	}
+	return nil
+}
+
+// identifyRequestAttributeSource recognizes a read of a tainted request
+// attribute in a backward trace. Only reads found on a PSR-7 request when the
+// attributes were promoted count, so getAttribute() of other objects does not.
+func (t *Tracer) identifyRequestAttributeSource(expr string, filePath string, line int) *types.SourceInfo {
+	t.mu.RLock()
+	var src *types.FlowNode
+	if fileInfo, ok := t.files[filePath]; ok {
+		src = findRequestAttributeRead(expr, requestAttributeReads(fileInfo)[line])
+	}
+	t.mu.RUnlock()
+	if src == nil {
+		return nil
+	}
+	return &types.SourceInfo{
+		Type:       src.SourceType,
+		Expression: expr,
+		FilePath:   filePath,
+		Line:       line,
+	}
+}
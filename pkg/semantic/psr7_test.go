@@ -0,0 +1,110 @@
+package semantic
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRequestAttributes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "auth.php", `<?php
+use Psr\Http\Message\ServerRequestInterface;
+
+class Auth {
+    public function process(ServerRequestInterface $request, $handler) {
+        $user = $request->getHeader('X-User');
+        $request = $request->withQueryParams([]);
+        return $handler->handle($request->withAttribute('user', $user));
+    }
+}
+`)
+	writeFile(t, dir, "roles.php", `<?php
+use Psr\Http\Message\ServerRequestInterface as Request;
+
+function roles(?Request $req, $next) {
+    return $next($req->withAttribute('role', $req->getAttribute('user')));
+}
+`)
+	writeFile(t, dir, "theme.php", `<?php
+$builder->withAttribute('theme', $_GET['theme']);
+`)
+	writeFile(t, dir, "show.php", `<?php
+use Psr\Http\Message\ServerRequestInterface as Request;
+
+function show(Request $request) {
+    $name = $request->getAttribute('user');
+    $theme = $request->getAttribute('theme');
+    return $name . $theme;
+}
+`)
+	writeFile(t, dir, "admin.php", `<?php
+use Psr\Http\Message\{ResponseInterface, ServerRequestInterface};
+
+$check = fn(ServerRequestInterface $r) => $r->withHeader('X', '1')->getAttribute('role');
+`)
+	writeFile(t, dir, "slim.php", `<?php
+function profile(\Slim\Psr7\Request $request) {
+    return $request->getAttribute('user');
+}
+`)
+	writeFile(t, dir, "dom.php", `<?php
+function render(DOMElement $el) {
+    return $el->getAttribute('user');
+}
+`)
+	writeFile(t, dir, "untyped.php", `<?php
+function label($obj) {
+    return $obj->getAttribute('role');
+}
+`)
+
+	tracer := New(nil)
+	result, err := tracer.TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var tainted []string
+	for _, attr := range tracer.RequestAttributes() {
+		tainted = append(tainted, attr.Name)
+	}
+	if want := []string{"role", "user"}; !reflect.DeepEqual(tainted, want) {
+		t.Fatalf("tainted attributes = %v, want %v", tainted, want)
+	}
+
+	reads := make(map[string][]string)
+	for _, src := range result.Sources {
+		if name, ok := src.Metadata["request_attribute"].(string); ok {
+			reads[filepath.Base(src.FilePath)] = append(reads[filepath.Base(src.FilePath)], name)
+		}
+	}
+	tests := []struct {
+		file  string
+		attrs []string
+	}{
+		{"roles.php", []string{"user"}},
+		{"show.php", []string{"user"}}, // theme is set on a builder, not a request
+		{"admin.php", []string{"role"}},
+		{"slim.php", []string{"user"}},
+		{"dom.php", nil},     // DOMElement::getAttribute()
+		{"untyped.php", nil}, // Receiver of unknown type
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			attrs := reads[tt.file]
+			sort.Strings(attrs)
+			if !reflect.DeepEqual(attrs, tt.attrs) {
+				t.Errorf("attribute sources = %v, want %v", attrs, tt.attrs)
+			}
+		})
+	}
+
+	if info := tracer.identifyRequestAttributeSource("$request->getAttribute('user')", filepath.Join(dir, "show.php"), 5); info == nil {
+		t.Error("read of user in show.php not identified as a source")
+	}
+	if info := tracer.identifyRequestAttributeSource("$el->getAttribute('user')", filepath.Join(dir, "dom.php"), 3); info != nil {
+		t.Errorf("DOMElement read identified as %+v", info)
+	}
+}
@@ -0,0 +1,191 @@
+package semantic
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// indexVersion is bumped whenever the index layout changes
+const indexVersion = 2
+
+// queryIndex is the on-disk index of a codebase: what backward queries read
+// from each file, so they can be answered without parsing it
+type queryIndex struct {
+	Version int
+	Root    string
+	Created time.Time
+	Files   []indexedFile
+}
+
+// indexedFile is the part of a FileInfo backward queries need, with the
+// modification time and size used to detect files changed since indexing
+type indexedFile struct {
+	Path              string
+	Language          string
+	Generated         GeneratedKind
+	ModTime           time.Time
+	Size              int64
+	Assignments       []*types.Assignment
+	TemplateBindings  []*types.TemplateBinding
+	SourceWrappers    []*types.SourceWrapper
+	ReturnSummaries   []*types.ReturnSummary
+	Validators        []*types.Validator
+	RequestAttributes []*types.RequestAttribute
+	Includes          []string
+}
+
+// BuildIndex parses a codebase and writes the index backward queries load
+// through Config.IndexFile or LoadIndex
+func (t *Tracer) BuildIndex(root, indexPath string) error {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", root, err)
+	}
+	if _, err := t.ParseOnly(root); err != nil {
+		return fmt.Errorf("failed to parse codebase: %w", err)
+	}
+
+	ctx := newTraceContext()
+	defer ctx.Close()
+
+	idx := &queryIndex{Version: indexVersion, Root: root, Created: time.Now()}
+	t.mu.RLock()
+	for path, fileInfo := range t.files {
+		if fileInfo.Error != nil {
+			continue // Queried again, and reported, when parsing without the index
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		file := indexedFile{
+			Path:              path,
+			Language:          fileInfo.Language,
+			Generated:         fileInfo.Generated,
+			ModTime:           stat.ModTime(),
+			Size:              stat.Size(),
+			TemplateBindings:  fileInfo.TemplateBindings,
+			SourceWrappers:    fileInfo.SourceWrappers,
+			ReturnSummaries:   fileInfo.ReturnSummaries,
+			Validators:        fileInfo.Validators,
+			RequestAttributes: fileInfo.RequestAttributes,
+			Includes:          fileInfo.Includes,
+		}
+		if fileInfo.Generated == GeneratedNone || t.generatedFileMode() != GeneratedSkip {
+			file.Assignments = ctx.getAssignmentsDirectly(path, fileInfo.Language)
+		}
+		idx.Files = append(idx.Files, file)
+	}
+	t.mu.RUnlock()
+
+	f, err := os.Create(indexPath)
+	if err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+	zw := gzip.NewWriter(f)
+	if err := gob.NewEncoder(zw).Encode(idx); err != nil {
+		f.Close()
+		return fmt.Errorf("encoding index %s: %w", indexPath, err)
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return fmt.Errorf("writing index %s: %w", indexPath, err)
+	}
+	return f.Close()
+}
+
+// LoadIndex loads an index written by BuildIndex in place of parsing its
+// codebase. Files changed since indexing are parsed again when queried;
+// files added since are not seen until the index is rebuilt.
+func (t *Tracer) LoadIndex(indexPath string) error {
+	if err := t.config.checkProfile(); err != nil {
+		return err
+	}
+	if err := t.loadRules(); err != nil {
+		return err
+	}
+	if err := t.loadRuntimeHints(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(indexPath)
+	if err != nil {
+		return fmt.Errorf("reading index: %w", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("reading index %s: %w", indexPath, err)
+	}
+	var idx queryIndex
+	if err := gob.NewDecoder(zr).Decode(&idx); err != nil {
+		return fmt.Errorf("decoding index %s: %w", indexPath, err)
+	}
+	if idx.Version != indexVersion {
+		return fmt.Errorf("index %s has version %d, want %d: rebuild it", indexPath, idx.Version, indexVersion)
+	}
+
+	files := make(map[string]*FileInfo, len(idx.Files))
+	assignments := make(map[string][]*types.Assignment, len(idx.Files))
+	stale := 0
+	for _, file := range idx.Files {
+		stat, err := os.Stat(file.Path)
+		if err != nil {
+			continue // Deleted since indexing
+		}
+		files[file.Path] = &FileInfo{
+			Path:              file.Path,
+			Language:          file.Language,
+			Generated:         file.Generated,
+			TemplateBindings:  file.TemplateBindings,
+			SourceWrappers:    file.SourceWrappers,
+			ReturnSummaries:   file.ReturnSummaries,
+			Validators:        file.Validators,
+			RequestAttributes: file.RequestAttributes,
+			Includes:          file.Includes,
+		}
+		if !stat.ModTime().Equal(file.ModTime) || stat.Size() != file.Size {
+			stale++
+			continue // Assignments are extracted again on first use
+		}
+		assignments[file.Path] = file.Assignments
+	}
+	if t.config.Verbose {
+		fmt.Printf("Loaded index of %s: %d files (%d changed since %s)\n", idx.Root, len(files), stale, idx.Created.Format(time.DateTime))
+	}
+
+	t.mu.Lock()
+	t.files = files
+	t.indexedAssignments = assignments
+	t.queryRoot = idx.Root
+	t.indexPromotions()
+	t.stats.FilesScanned = len(files)
+	t.mu.Unlock()
+
+	t.buildIncludeGraph(idx.Root)
+	t.promoteValidators()
+	return nil
+}
+
+// prepareQueries makes the codebase queryable: it loads Config.IndexFile, or
+// parses codebasePath, unless files were already parsed or loaded; files
+// invalidated since are parsed again
+func (t *Tracer) prepareQueries(codebasePath string) error {
+	if len(t.files) > 0 || len(t.staleFiles) > 0 {
+		t.reparseStale()
+		return nil
+	}
+	if t.config.IndexFile != "" {
+		return t.LoadIndex(t.config.IndexFile)
+	}
+	if _, err := t.ParseOnly(codebasePath); err != nil {
+		return fmt.Errorf("failed to parse codebase: %w", err)
+	}
+	return nil
+}
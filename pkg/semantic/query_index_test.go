@@ -0,0 +1,78 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQueryIndex(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.php": "<?php\n$id = $_GET['id'];\n$user = $id;\ninclude 'view.php';\n",
+		"view.php":  "<?php\n$name = input('name');\n$title = $name;\n",
+		"lib.php":   "<?php\nfunction input($k) {\n    return $_REQUEST[$k];\n}\n",
+	}
+	for name, code := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexPath := filepath.Join(t.TempDir(), "index")
+	if err := New(nil).BuildIndex(dir, indexPath); err != nil {
+		t.Fatal(err)
+	}
+
+	query := func(target string) []string {
+		config := DefaultConfig()
+		config.IndexFile = indexPath
+		tracer := New(config)
+		result, err := tracer.TraceBackward(target, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tracer.stats.FilesParsed != 0 {
+			t.Errorf("query parsed %d files, want 0", tracer.stats.FilesParsed)
+		}
+		var sources []string
+		for _, src := range result.Sources {
+			sources = append(sources, src.Expression)
+		}
+		return sources
+	}
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"$user", "$_GET['id']"},
+		{"$title", "input('name')"}, // Source wrapper defined in another file
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			if got := query(tt.target); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("sources = %v, want [%s]", got, tt.want)
+			}
+		})
+	}
+
+	// A file changed since indexing is read again
+	changed := filepath.Join(dir, "index.php")
+	if err := os.WriteFile(changed, []byte("<?php\n$user = $_COOKIE['u'];\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(changed, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := query("$user"); len(got) != 1 || got[0] != "$_COOKIE['u']" {
+		t.Errorf("sources after change = %v, want [$_COOKIE['u']]", got)
+	}
+
+	config := DefaultConfig()
+	config.IndexFile = filepath.Join(dir, "index.php")
+	if _, err := New(config).TraceBackward("$user", dir); err == nil {
+		t.Error("loading a file that is not an index succeeded")
+	}
+}
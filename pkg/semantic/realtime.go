@@ -0,0 +1,119 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// realtimeHandlerFinder is implemented by analyzers that detect WebSocket and
+// Server-Sent Events message handlers
+type realtimeHandlerFinder interface {
+	FindRealtimeHandlers(root *sitter.Node, source []byte) []*types.RealtimeHandler
+}
+
+// realtimeSources returns a network source for every payload of the handlers.
+// The handler's event is the source key.
+func realtimeSources(handlers []*types.RealtimeHandler, language string) []*types.FlowNode {
+	var sources []*types.FlowNode
+	for _, h := range handlers {
+		for _, payload := range h.Payloads {
+			sources = append(sources, &types.FlowNode{
+				Type:       types.NodeSource,
+				Language:   language,
+				Line:       payload.Line,
+				Column:     payload.Column,
+				Name:       payload.Name,
+				Snippet:    payload.Name,
+				SourceType: types.SourceNetwork,
+				SourceKey:  h.Event,
+				Metadata: map[string]interface{}{
+					"protocol":  h.Protocol,
+					"framework": h.Framework,
+					"handler":   h.Function,
+				},
+			})
+		}
+	}
+	return sources
+}
+
+// markRealtimeTaint marks the cached assignments and call arguments inside a
+// handler that read one of its payloads as tainted. Only code after the
+// payload counts, which excludes the call or assignment registering the handler.
+func markRealtimeTaint(handlers []*types.RealtimeHandler, assignments []*types.Assignment, calls []*types.CallSite) {
+	for _, h := range handlers {
+		for _, payload := range h.Payloads {
+			for _, assign := range assignments {
+				if !assign.IsTainted && afterPayload(payload, assign.Line, assign.Column) && assign.Line <= h.EndLine && mentionsIdentifier(assign.Source, payload.Name) {
+					assign.IsTainted = true
+					assign.TaintSource = payload.Name
+				}
+			}
+			for _, call := range calls {
+				if !afterPayload(payload, call.Line, call.Column) || call.Line > h.EndLine {
+					continue
+				}
+				for i := range call.Arguments {
+					arg := &call.Arguments[i]
+					if !arg.IsTainted && mentionsIdentifier(arg.Value, payload.Name) {
+						arg.IsTainted = true
+						arg.TaintSource = payload.Name
+						call.HasTaintedArgs = true
+						call.TaintedArgIndices = append(call.TaintedArgIndices, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+// afterPayload reports whether line:column comes after a payload's declaration
+func afterPayload(payload types.RealtimePayload, line, column int) bool {
+	return line > payload.Line || (line == payload.Line && column > payload.Column)
+}
+
+// mentionsIdentifier reports whether expr contains name as a whole identifier
+func mentionsIdentifier(expr, name string) bool {
+	for offset := 0; ; {
+		idx := strings.Index(expr[offset:], name)
+		if idx < 0 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(name)
+		if (start == 0 || !isIdentByte(expr[start-1])) && (end == len(expr) || !isIdentByte(expr[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// realtimeEntryPoints returns an entry point for every message handler of the
+// scanned files
+func (t *Tracer) realtimeEntryPoints(filePaths []string) []*EntryPoint {
+	var eps []*EntryPoint
+	for _, filePath := range filePaths {
+		for _, h := range t.files[filePath].RealtimeHandlers {
+			eps = append(eps, &EntryPoint{
+				Kind:     EntryPointRealtime,
+				FilePath: filePath,
+				Function: h.Function,
+				Line:     h.Line,
+				Route:    realtimeRoute(h),
+				Inputs:   []types.SourceType{types.SourceNetwork},
+			})
+		}
+	}
+	return eps
+}
+
+// realtimeRoute names a handler by protocol and event, e.g. "websocket:chat"
+func realtimeRoute(h *types.RealtimeHandler) string {
+	if h.Event == "" {
+		return h.Protocol
+	}
+	return fmt.Sprintf("%s:%s", h.Protocol, h.Event)
+}
@@ -0,0 +1,114 @@
+package semantic
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestRealtimeHandlers(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		code        string
+		wantRoutes  []string // Entry points as route function
+		wantSources []string // Network sources as key:name framework
+		wantFlow    string   // Variable a payload flows to ("" = none checked)
+	}{
+		{"ratchet", "chat.php", `<?php
+use Ratchet\MessageComponentInterface;
+class Chat implements MessageComponentInterface {
+    public function onOpen($conn) {}
+    public function onMessage($from, $msg) {
+        $data = json_decode($msg);
+    }
+}
+`, []string{"websocket Chat::onMessage"}, []string{":$msg ratchet"}, "$data"},
+		{"workerman and swoole", "worker.php", `<?php
+$worker->onMessage = function($conn, $data) {
+    $copy = $data;
+};
+$server->on('message', function($server, $frame) {
+    $body = $frame->data;
+});
+$server->on('open', function($server, $req) {});
+`, []string{"websocket $worker->onMessage", "websocket:message "}, []string{":$data workerman", "message:$frame swoole"}, "$copy"},
+		{"socket.io, ws and EventSource", "server.js", `io.on('connection', (socket) => {
+  socket.on('chat', (msg, ack) => {
+    const text = msg.text;
+  });
+  socket.on('disconnect', (reason) => {});
+});
+ws.on('message', (data) => { const m = data; });
+const es = new EventSource('/feed');
+es.onmessage = (event) => { const d = event.data; };
+`, []string{"sse:message es.onmessage", "websocket:chat socket.on", "websocket:message ws.on"},
+			[]string{"chat:msg socket.io", "message:data ws", "message:event eventsource"}, "text"},
+		{"typescript", "client.ts", `let latest: string;
+socket.on('update', (payload: string) => {
+  latest = payload;
+});
+`, []string{"websocket:update socket.on"}, []string{"update:payload socket.io"}, "latest"},
+		{"gorilla ReadJSON", "ws.go", `package main
+
+func handle(conn *websocket.Conn) {
+	var req Request
+	conn.ReadJSON(&req)
+}
+`, []string{"websocket handle"}, []string{":req gorilla/websocket"}, ""},
+		{"nhooyr wsjson", "ws.go", `package main
+
+func handle(ctx context.Context, c *websocket.Conn) {
+	var v Request
+	wsjson.Read(ctx, c, &v)
+}
+`, []string{"websocket handle"}, []string{":v nhooyr.io/websocket"}, ""},
+		{"no handlers", "plain.js", `socket.on('connect', () => {});
+button.on('message', (data) => {});
+`, nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, tt.file, tt.code)
+			result, err := New(nil).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var routes []string
+			for _, ep := range result.EntryPoints {
+				if ep.Kind == EntryPointRealtime {
+					routes = append(routes, ep.Route+" "+ep.Function)
+				}
+			}
+			var srcs []string
+			for _, src := range result.Sources {
+				if src.SourceType == types.SourceNetwork && src.Metadata["protocol"] != nil {
+					srcs = append(srcs, fmt.Sprintf("%s:%s %v", src.SourceKey, src.Name, src.Metadata["framework"]))
+				}
+			}
+			sort.Strings(routes)
+			sort.Strings(srcs)
+			if !reflect.DeepEqual(routes, tt.wantRoutes) {
+				t.Errorf("entry points = %q, want %q", routes, tt.wantRoutes)
+			}
+			if !reflect.DeepEqual(srcs, tt.wantSources) {
+				t.Errorf("sources = %q, want %q", srcs, tt.wantSources)
+			}
+
+			if tt.wantFlow == "" {
+				return
+			}
+			for _, node := range result.FlowMap.AllNodes {
+				if node.Type != types.NodeSource && node.Name == tt.wantFlow {
+					return
+				}
+			}
+			t.Errorf("no flow to %s", tt.wantFlow)
+		})
+	}
+}
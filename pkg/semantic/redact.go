@@ -0,0 +1,170 @@
+package semantic
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// redactedText replaces the contents of string literals and comments
+const redactedText = "***"
+
+// commentSyntax describes the comment and quote syntax of a language
+type commentSyntax struct {
+	line   []string // Line comment prefixes
+	block  bool     // Supports /* ... */ comments
+	quotes string   // Characters opening a string literal
+}
+
+// snippetSyntax returns the comment and quote syntax of a language; unknown
+// languages get C-style syntax
+func snippetSyntax(language string) commentSyntax {
+	switch language {
+	case "php":
+		return commentSyntax{line: []string{"//", "#"}, block: true, quotes: "'\"`"}
+	case "python":
+		return commentSyntax{line: []string{"#"}, quotes: "'\""}
+	case "ruby":
+		return commentSyntax{line: []string{"#"}, quotes: "'\"`"}
+	case "rust":
+		// ' also starts lifetimes, so only double-quoted strings are literals
+		return commentSyntax{line: []string{"//"}, block: true, quotes: "\""}
+	default:
+		return commentSyntax{line: []string{"//"}, block: true, quotes: "'\"`"}
+	}
+}
+
+// RedactSnippet replaces the contents of string literals and comments in a
+// code snippet while keeping quotes, comment delimiters and the surrounding
+// code, e.g. `$k = "secret"; // key` becomes `$k = "***"; // ***`
+func RedactSnippet(snippet, language string) string {
+	syntax := snippetSyntax(language)
+	var sb strings.Builder
+	sb.Grow(len(snippet))
+
+	for i := 0; i < len(snippet); {
+		rest := snippet[i:]
+
+		if syntax.block && strings.HasPrefix(rest, "/*") {
+			end := strings.Index(rest[2:], "*/")
+			sb.WriteString("/* " + redactedText)
+			if end < 0 {
+				break
+			}
+			sb.WriteString(" */")
+			i += end + 4
+			continue
+		}
+
+		if prefix := lineCommentPrefix(rest, syntax, language); prefix != "" {
+			end := strings.IndexByte(rest, '\n')
+			sb.WriteString(prefix + " " + redactedText)
+			if end < 0 {
+				break
+			}
+			i += end
+			continue
+		}
+
+		if quote := rest[0]; strings.IndexByte(syntax.quotes, quote) >= 0 {
+			end := closingQuote(rest, quote)
+			sb.WriteByte(quote)
+			if end > 1 || (end < 0 && len(rest) > 1) {
+				sb.WriteString(redactedText)
+			}
+			if end < 0 {
+				break
+			}
+			sb.WriteByte(quote)
+			i += end + 1
+			continue
+		}
+
+		sb.WriteByte(snippet[i])
+		i++
+	}
+	return sb.String()
+}
+
+// lineCommentPrefix returns the line comment prefix rest starts with, if any.
+// PHP attributes (#[...]) are not comments.
+func lineCommentPrefix(rest string, syntax commentSyntax, language string) string {
+	for _, prefix := range syntax.line {
+		if !strings.HasPrefix(rest, prefix) {
+			continue
+		}
+		if prefix == "#" && language == "php" && strings.HasPrefix(rest, "#[") {
+			return ""
+		}
+		return prefix
+	}
+	return ""
+}
+
+// closingQuote returns the index of the quote closing the literal s starts
+// with, skipping backslash escapes, or -1 when the literal is unterminated
+func closingQuote(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// redactNode redacts the snippet of a flow node in place
+func redactNode(node *types.FlowNode) {
+	node.Snippet = RedactSnippet(node.Snippet, node.Language)
+}
+
+// redactNodes redacts the snippets of a slice of flow nodes in place
+func redactNodes(nodes []types.FlowNode) {
+	for i := range nodes {
+		redactNode(&nodes[i])
+	}
+}
+
+// RedactSnippets replaces string literals and comments in every snippet of
+// the result (see RedactSnippet), so that all exporters emit redacted code.
+// Config.RedactSnippets applies it to the results of TraceDirectory.
+func (r *TraceResult) RedactSnippets() {
+	r.redacted = true
+	for _, src := range r.Sources {
+		redactNode(src)
+	}
+	for _, src := range r.SkippedSources {
+		redactNode(src)
+	}
+	if r.FlowMap == nil {
+		return
+	}
+	redactNodes(r.FlowMap.Sources)
+	redactNodes(r.FlowMap.Carriers)
+	redactNodes(r.FlowMap.AllNodes)
+	redactNodes(r.FlowMap.Usages)
+	for _, path := range r.FlowMap.Paths {
+		for i := range path.Steps {
+			redactNode(&path.Steps[i].Node)
+		}
+		if path.Source != nil {
+			redactNode(path.Source)
+		}
+		if path.Target != nil {
+			redactNode(path.Target)
+		}
+	}
+}
+
+// redact replaces string literals and comments in the snippets of a finding.
+// The source is copied since it is shared with the trace result.
+func (f *Finding) redact() {
+	if f.Source != nil {
+		src := *f.Source
+		redactNode(&src)
+		f.Source = &src
+	}
+	redactNodes(f.Nodes)
+}
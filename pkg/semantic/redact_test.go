@@ -0,0 +1,85 @@
+package semantic
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRedactSnippet(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		snippet  string
+		want     string
+	}{
+		{"double-quoted string", "php", `$k = "secret";`, `$k = "***";`},
+		{"single-quoted key", "php", `$_GET['token']`, `$_GET['***']`},
+		{"empty string kept", "php", `$k = '';`, `$k = '';`},
+		{"escaped quote", "php", `$k = 'it\'s';`, `$k = '***';`},
+		{"line comment", "php", "$k = 1; // api key\n$j = 2;", "$k = 1; // ***\n$j = 2;"},
+		{"hash comment", "php", "$k = 1; # key", "$k = 1; # ***"},
+		{"attribute kept", "php", "#[Route('/admin')]", "#[Route('***')]"},
+		{"block comment", "javascript", "a = /* pw */ b;", "a = /* *** */ b;"},
+		{"unterminated block comment", "javascript", "a = /* pw", "a = /* ***"},
+		{"unterminated string", "javascript", `x = "abc`, `x = "***`},
+		{"template literal", "javascript", "x = `hi ${name}`;", "x = `***`;"},
+		{"python comment", "python", "x = 'a'  # note", "x = '***'  # ***"},
+		{"python floor division kept", "python", "x = a // b", "x = a // b"},
+		{"rust lifetime kept", "rust", `fn f<'a>(s: &'a str) { g("pw") }`, `fn f<'a>(s: &'a str) { g("***") }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactSnippet(tt.snippet, tt.language); got != tt.want {
+				t.Errorf("RedactSnippet(%q) = %q, want %q", tt.snippet, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactSnippetsConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", "<?php\n$id = $_GET['secret_key'];\n$copy = $id . \"hunter2\";\n")
+	config := DefaultConfig()
+	config.RedactSnippets = true
+
+	check := func(where, snippet string) {
+		t.Helper()
+		if strings.Contains(snippet, "secret_key") || strings.Contains(snippet, "hunter2") {
+			t.Errorf("%s snippet %q is not redacted", where, snippet)
+		}
+	}
+
+	result, err := New(config).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Sources) == 0 {
+		t.Fatal("no sources found")
+	}
+	for _, src := range result.Sources {
+		check("source", src.Snippet)
+	}
+	for _, node := range result.FlowMap.AllNodes {
+		check("node", node.Snippet)
+	}
+	out, err := result.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Source keys are structured fields, not code; only snippets are redacted
+	if strings.Contains(out, "hunter2") {
+		t.Error("JSON export contains a string literal")
+	}
+
+	findings, errc := New(config).StreamDirectory(context.Background(), dir)
+	for finding := range findings {
+		check("finding source", finding.Source.Snippet)
+		for _, node := range finding.Nodes {
+			check("finding node", node.Snippet)
+		}
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,102 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// registerLoopFinder is implemented by analyzers that detect file-scope loops
+// copying a superglobal into variables named after its keys (register_globals emulation)
+type registerLoopFinder interface {
+	FindRegisterLoops(root *sitter.Node, source []byte) []*types.RegisterLoop
+}
+
+// registerLoopSources returns a source for the first read of every variable a
+// loop may define. The variable name without its $ is the source key, as in
+// $_REQUEST['name'].
+func registerLoopSources(loops []*types.RegisterLoop, language string) []*types.FlowNode {
+	var nodes []*types.FlowNode
+	for _, loop := range loops {
+		for _, v := range loop.Variables {
+			nodes = append(nodes, &types.FlowNode{
+				Type:       types.NodeSource,
+				Language:   language,
+				Line:       v.Line,
+				Column:     v.Column,
+				Name:       v.Name,
+				Snippet:    v.Name,
+				SourceType: registerLoopSourceType(loop),
+				SourceKey:  strings.TrimPrefix(v.Name, "$"),
+				Metadata: map[string]interface{}{
+					"register_loop":      loop.Code,
+					"register_loop_line": loop.Line,
+					"superglobals":       loop.Superglobals,
+				},
+			})
+		}
+	}
+	return nodes
+}
+
+// registerLoopSourceType is the source type of the variables of a loop: that
+// of its superglobal, or an HTTP request when it copies several
+func registerLoopSourceType(loop *types.RegisterLoop) types.SourceType {
+	if len(loop.Superglobals) == 1 {
+		if st, ok := sources.SuperglobalToSourceType[loop.Superglobals[0]]; ok {
+			return st
+		}
+	}
+	return types.SourceHTTPRequest
+}
+
+// markRegisterTaint marks the cached file-scope assignments and call
+// arguments after a loop that read one of its variables as tainted
+func markRegisterTaint(loops []*types.RegisterLoop, assignments []*types.Assignment, calls []*types.CallSite) {
+	for _, loop := range loops {
+		for _, v := range loop.Variables {
+			for _, assign := range assignments {
+				if !assign.IsTainted && assign.Scope == "" && assign.Line >= v.Line && mentionsIdentifier(assign.Source, v.Name) {
+					assign.IsTainted = true
+					assign.TaintSource = v.Name
+				}
+			}
+			for _, call := range calls {
+				if call.Line < v.Line {
+					continue
+				}
+				for i := range call.Arguments {
+					arg := &call.Arguments[i]
+					if !arg.IsTainted && mentionsIdentifier(arg.Value, v.Name) {
+						arg.IsTainted = true
+						arg.TaintSource = v.Name
+						call.HasTaintedArgs = true
+						call.TaintedArgIndices = append(call.TaintedArgIndices, i)
+					}
+				}
+			}
+		}
+	}
+}
+
+// registeredVariableSource returns the request input a file-scope variable
+// of a file with a register loop reads, or nil when no loop defines it
+func registeredVariableSource(fileInfo *FileInfo, varName string) *types.SourceInfo {
+	for _, loop := range fileInfo.RegisterLoops {
+		for _, v := range loop.Variables {
+			if strings.TrimPrefix(v.Name, "$") != varName {
+				continue
+			}
+			return &types.SourceInfo{
+				Type:       registerLoopSourceType(loop),
+				Expression: fmt.Sprintf("%s['%s']", loop.Superglobals[0], varName),
+				FilePath:   fileInfo.Path,
+				Line:       loop.Line,
+			}
+		}
+	}
+	return nil
+}
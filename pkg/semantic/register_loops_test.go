@@ -0,0 +1,90 @@
+package semantic
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestRegisterLoops(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "legacy.php", `<?php
+$limit = 10;
+foreach ($_REQUEST as $k => $v) $$k = $v;
+function helper($arg) {
+    echo $undeclaredInFunction;
+}
+$id = intval($id);
+$title = $name;
+mysql_query("SELECT * FROM t WHERE name = '$name' LIMIT $limit");
+foreach ($_SERVER as $key => $value) { echo $key; }
+`)
+	writeFile(t, dir, "nested.php", `<?php
+foreach (array('_GET', '_POST') as $sg) {
+    foreach ($$sg as $k => $v) {
+        ${$k} = addslashes($v);
+    }
+}
+echo $page;
+`)
+	writeFile(t, dir, "plain.php", `<?php
+echo $notRegistered;
+foreach ($_GET as $k => $v) { $clean[$k] = $v; }
+`)
+
+	tracer := New(nil)
+	result, err := tracer.TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered := make(map[string][]string)
+	for _, src := range result.Sources {
+		if src.Metadata["register_loop"] == nil {
+			continue
+		}
+		file := filepath.Base(src.FilePath)
+		registered[file] = append(registered[file], src.Snippet)
+		if src.SourceKey != strings.TrimPrefix(src.Snippet, "$") {
+			t.Errorf("%s source key = %q", src.Snippet, src.SourceKey)
+		}
+	}
+	for file := range registered {
+		sort.Strings(registered[file])
+	}
+	if got, want := strings.Join(registered["legacy.php"], " "), "$id $name"; got != want {
+		t.Errorf("legacy.php registered variables = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(registered["nested.php"], " "), "$page"; got != want {
+		t.Errorf("nested.php registered variables = %s, want %s", got, want)
+	}
+	if len(registered["plain.php"]) != 0 {
+		t.Errorf("plain.php registered variables = %v, want none", registered["plain.php"])
+	}
+
+	loops := tracer.files[filepath.Join(dir, "nested.php")].RegisterLoops
+	if len(loops) != 1 || strings.Join(loops[0].Superglobals, ",") != "$_GET,$_POST" || loops[0].Line != 3 {
+		t.Errorf("nested.php loops = %+v, want the inner loop over $_GET and $_POST", loops)
+	}
+
+	flowsToTitle := false
+	for _, node := range result.FlowMap.AllNodes {
+		if node.Type == types.NodeVariable && node.Name == "$title" {
+			flowsToTitle = true
+		}
+	}
+	if !flowsToTitle {
+		t.Error("registered $name does not flow into $title")
+	}
+
+	backward, err := New(nil).TraceBackward("$title", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backward.Sources) != 1 || backward.Sources[0].Expression != "$_REQUEST['name']" || backward.Sources[0].Line != 3 {
+		t.Errorf("backward sources of $title = %+v, want $_REQUEST['name'] from the loop on line 3", backward.Sources)
+	}
+}
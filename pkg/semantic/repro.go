@@ -0,0 +1,380 @@
+package semantic
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/parser"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Reproduction is a finding extracted into a standalone directory: the files
+// along its flow, trimmed to the code the flow passes through, and a README
+// of the trace. It can be shared without shipping the whole codebase.
+type Reproduction struct {
+	Dir    string
+	Record InputFlowRecord
+	Files  []ReproductionFile
+}
+
+// ReproductionFile is one file copied into a reproduction
+type ReproductionFile struct {
+	Path     string   // Path in the reproduction, relative to its directory
+	Original string   // Path of the file the code was copied from
+	Kept     [][2]int // Line ranges of the original file kept, 1-based and inclusive
+}
+
+// Node types kept whatever the flow: without them the kept code does not parse
+// or does not resolve its names
+var reproKeptTypes = map[string]bool{
+	"php_tag":                   true,
+	"namespace_definition":      true, // namespace Foo; (braced namespaces are trimmed as containers)
+	"namespace_use_declaration": true,
+	"declare_statement":         true,
+	"import_statement":          true,
+	"import_from_statement":     true,
+	"import_declaration":        true,
+	"package_clause":            true,
+	"package_declaration":       true,
+}
+
+// Node types holding declarations, trimmed to the members the flow passes through
+var reproContainerTypes = map[string]bool{
+	"namespace_definition":  true,
+	"class_declaration":     true,
+	"interface_declaration": true,
+	"trait_declaration":     true,
+	"enum_declaration":      true,
+	"class_definition":      true,
+	"class":                 true,
+}
+
+// Include expressions whose statements are kept when they include a file of
+// the reproduction
+var reproIncludeTypes = map[string]bool{
+	"include_expression":      true,
+	"include_once_expression": true,
+	"require_expression":      true,
+	"require_once_expression": true,
+}
+
+// ExtractReproduction writes the finding of source into dir: each file the
+// flow from source passes through, trimmed to the functions and statements on
+// the flow plus the import statements needed to parse them, and a README.md
+// describing the trace. Includes are kept only when they include another file
+// of the reproduction, so that the copied files run without the rest. Copied files keep their paths relative to
+// the deepest directory containing them all. Redacted results are refused, as
+// the reproduction holds code.
+func (r *TraceResult) ExtractReproduction(source *types.FlowNode, dir string) (*Reproduction, error) {
+	if source == nil {
+		return nil, errors.New("no source to extract")
+	}
+	if r.redacted {
+		return nil, errors.New("cannot extract a reproduction from a redacted result")
+	}
+
+	flowMap := r.FlowMap
+	if flowMap == nil {
+		flowMap = types.NewFlowMap()
+	}
+	finding := newFinding(source, flowMap)
+	lines := make(map[string][]int)
+	addLines := func(node *types.FlowNode) {
+		if node.FilePath == "" || node.Line <= 0 {
+			return
+		}
+		lines[node.FilePath] = append(lines[node.FilePath], node.Line)
+		if node.EndLine > node.Line {
+			lines[node.FilePath] = append(lines[node.FilePath], node.EndLine)
+		}
+	}
+	addLines(source)
+	for i := range finding.Nodes {
+		addLines(&finding.Nodes[i])
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("source %s has no location", source.ID)
+	}
+
+	files := make([]string, 0, len(lines))
+	for filePath := range lines {
+		files = append(files, filePath)
+	}
+	sort.Strings(files)
+	root := commonDir(files)
+	copied := make(map[string]bool, len(files))
+	for _, filePath := range files {
+		copied[filePath] = true
+	}
+
+	repro := &Reproduction{
+		Dir:    dir,
+		Record: InputFlowRecords(&TraceResult{Sources: []*types.FlowNode{source}, FlowMap: flowMap})[0],
+	}
+	service := r.codeParser()
+	for _, filePath := range files {
+		parsed, err := service.ParseFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		if parsed == nil {
+			return nil, fmt.Errorf("unsupported file type: %s", filePath)
+		}
+
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			rel = filepath.Base(filePath)
+		}
+		file := ReproductionFile{
+			Path:     filepath.ToSlash(filepath.Join("src", rel)),
+			Original: filePath,
+			Kept:     keptRanges(parsed.Root, lines[filePath], reproIncludeFilter(parsed.Source, filePath, root, copied)),
+		}
+		target := filepath.Join(dir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		code := trimToRanges(parsed.Source, file.Kept, commentPrefix(parsed.Language))
+		if err := os.WriteFile(target, code, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		repro.Files = append(repro.Files, file)
+	}
+
+	readme := repro.readme(root)
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte(readme), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write README: %w", err)
+	}
+	return repro, nil
+}
+
+// codeParser returns the parser service of the result; results not produced
+// by TraceDirectory get their own
+func (r *TraceResult) codeParser() *parser.Service {
+	if r.parserService != nil {
+		return r.parserService
+	}
+	service := parser.NewService(5)
+	for name, lang := range supportedGrammars() {
+		service.RegisterLanguage(name, lang)
+	}
+	return service
+}
+
+// reproIncludeFilter returns whether an include statement of filePath names
+// one of the copied files, resolved against its directory or root. Dynamic
+// includes resolve to nothing.
+func reproIncludeFilter(source []byte, filePath, root string, copied map[string]bool) func(*sitter.Node) bool {
+	return func(stmt *sitter.Node) bool {
+		include := stmt.NamedChild(0)
+		for i := 0; i < int(include.NamedChildCount()); i++ {
+			arg := include.NamedChild(i)
+			for arg.Type() == "parenthesized_expression" && arg.NamedChildCount() == 1 {
+				arg = arg.NamedChild(0)
+			}
+			text := arg.Content(source)
+			path, ok := "", false
+			switch arg.Type() {
+			case "string":
+				path, ok = strings.Trim(text, `'`), true
+			case "encapsed_string":
+				path, ok = strings.Trim(text, `"`), !strings.ContainsAny(text, "${")
+			default:
+				path, ok = phpPatterns.FileRelativeIncludePath(text)
+			}
+			if !ok || path == "" {
+				continue
+			}
+			if filepath.IsAbs(path) {
+				return copied[filepath.Clean(path)]
+			}
+			return copied[filepath.Join(filepath.Dir(filePath), path)] || copied[filepath.Join(root, path)]
+		}
+		return false
+	}
+}
+
+// keptRanges returns the merged line ranges of the top-level code to keep for
+// a flow through lines; keepInclude reports whether an include statement is kept
+func keptRanges(root *sitter.Node, lines []int, keepInclude func(*sitter.Node) bool) [][2]int {
+	var ranges [][2]int
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		ranges = append(ranges, keptRangesOf(root.NamedChild(i), lines, keepInclude)...)
+	}
+	return mergeRanges(ranges)
+}
+
+// keptRangesOf returns the line ranges of node to keep: imports and the
+// includes keepInclude accepts whole, containers trimmed to their members on
+// the flow, other declarations and statements whole when the flow passes
+// through them
+func keptRangesOf(node *sitter.Node, lines []int, keepInclude func(*sitter.Node) bool) [][2]int {
+	start, end := int(node.StartPoint().Row)+1, int(node.EndPoint().Row)+1
+	body := node.ChildByFieldName("body")
+
+	switch {
+	case reproContainerTypes[node.Type()] && body != nil:
+		if !spansLine(lines, start, end) {
+			return nil
+		}
+		ranges := [][2]int{{start, int(body.StartPoint().Row) + 1}}
+		namespace := node.Type() == "namespace_definition"
+		for i := 0; i < int(body.NamedChildCount()); i++ {
+			member := body.NamedChild(i)
+			memberStart, memberEnd := int(member.StartPoint().Row)+1, int(member.EndPoint().Row)+1
+			switch {
+			case namespace:
+				ranges = append(ranges, keptRangesOf(member, lines, keepInclude)...)
+			case member.Type() == "comment":
+			case isFunctionNode(member) && !spansLine(lines, memberStart, memberEnd):
+			default:
+				// Properties, constants and trait uses are kept: methods read them
+				ranges = append(ranges, [2]int{memberStart, memberEnd})
+			}
+		}
+		return append(ranges, [2]int{end, end})
+	case reproKeptTypes[node.Type()] || isIncludeStatement(node) && keepInclude(node):
+		return [][2]int{{start, end}}
+	case spansLine(lines, start, end):
+		return [][2]int{{start, end}}
+	}
+	return nil
+}
+
+// isFunctionNode reports whether node declares a function or method
+func isFunctionNode(node *sitter.Node) bool {
+	return strings.Contains(node.Type(), "function") || strings.Contains(node.Type(), "method")
+}
+
+// isIncludeStatement reports whether node is an include/require statement
+func isIncludeStatement(node *sitter.Node) bool {
+	return node.Type() == "expression_statement" && node.NamedChildCount() > 0 &&
+		reproIncludeTypes[node.NamedChild(0).Type()]
+}
+
+// spansLine reports whether one of lines is within start..end
+func spansLine(lines []int, start, end int) bool {
+	for _, line := range lines {
+		if line >= start && line <= end {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeRanges sorts ranges and merges the overlapping and adjacent ones
+func mergeRanges(ranges [][2]int) [][2]int {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	var merged [][2]int
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r[0] <= merged[n-1][1]+1 {
+			if r[1] > merged[n-1][1] {
+				merged[n-1][1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// trimToRanges returns the lines of source within ranges, with a comment
+// marking each run of omitted lines before, between and after them
+func trimToRanges(source []byte, ranges [][2]int, comment string) []byte {
+	lines := strings.Split(strings.TrimSuffix(string(source), "\n"), "\n")
+	var sb strings.Builder
+	next := 1
+	for _, r := range ranges {
+		if r[0] > next {
+			sb.WriteString(omitted(comment, next, r[0]-1))
+		}
+		for n := r[0]; n <= r[1] && n <= len(lines); n++ {
+			sb.WriteString(lines[n-1])
+			sb.WriteByte('\n')
+		}
+		next = r[1] + 1
+	}
+	if next <= len(lines) {
+		sb.WriteString(omitted(comment, next, len(lines)))
+	}
+	return []byte(sb.String())
+}
+
+// omitted marks the lines first to last as omitted in a comment
+func omitted(comment string, first, last int) string {
+	if first == last {
+		return fmt.Sprintf("%s ... line %d omitted\n", comment, first)
+	}
+	return fmt.Sprintf("%s ... lines %s omitted\n", comment, lineRange(first, last))
+}
+
+// lineRange renders the lines first to last ("4", "4-9")
+func lineRange(first, last int) string {
+	if first == last {
+		return fmt.Sprintf("%d", first)
+	}
+	return fmt.Sprintf("%d-%d", first, last)
+}
+
+// commentPrefix returns the line comment marker of a language
+func commentPrefix(language string) string {
+	switch language {
+	case "python", "ruby":
+		return "#"
+	}
+	return "//"
+}
+
+// commonDir returns the deepest directory containing all files
+func commonDir(files []string) string {
+	dir := filepath.Dir(files[0])
+	for _, file := range files[1:] {
+		for !strings.HasPrefix(file, dir+string(filepath.Separator)) && dir != filepath.Dir(dir) {
+			dir = filepath.Dir(dir)
+		}
+	}
+	return dir
+}
+
+// readme renders the README of the reproduction; root is the directory the
+// copied files were relative to
+func (p *Reproduction) readme(root string) string {
+	rel := func(filePath string) string {
+		if r, err := filepath.Rel(root, filePath); err == nil {
+			return filepath.ToSlash(filepath.Join("src", r))
+		}
+		return filePath
+	}
+
+	src := p.Record.Source
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s\n\n", flowTitle(p.Record)))
+	sb.WriteString("Minimal reproduction extracted by inputtracer. Only the code the flow passes\n")
+	sb.WriteString("through is kept, plus the import statements needed to parse it and the\n")
+	sb.WriteString("includes of the other files here; omitted lines are marked in place. Line\n")
+	sb.WriteString("numbers refer to the original files.\n\n")
+
+	sb.WriteString("## Source\n\n")
+	sb.WriteString(fmt.Sprintf("`%s` (%s) at %s:%d\n\n", src.Snippet, src.SourceType, rel(src.FilePath), src.Line))
+
+	sb.WriteString("## Path\n\n")
+	for i, step := range p.Record.Steps {
+		sb.WriteString(fmt.Sprintf("%d. [%s] `%s` at %s:%d\n", i+1, step.Type, step.Snippet, rel(step.FilePath), step.Line))
+	}
+
+	sb.WriteString("\n## Files\n\n")
+	for _, file := range p.Files {
+		kept := make([]string, 0, len(file.Kept))
+		for _, r := range file.Kept {
+			kept = append(kept, lineRange(r[0], r[1]))
+		}
+		sb.WriteString(fmt.Sprintf("- %s: lines %s\n", file.Path, strings.Join(kept, ", ")))
+	}
+	return sb.String()
+}
@@ -0,0 +1,100 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractReproduction(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+require_once 'lib.php';
+require 'config.php';
+function unrelated() {
+    return 42;
+}
+
+$id = $_GET['id'];
+render($id);
+`)
+	writeFile(t, dir, "lib.php", `<?php
+class Secret {
+    public function proprietary() {
+        return 'do not share';
+    }
+}
+
+function render($value) {
+    echo $value;
+}
+`)
+	writeFile(t, dir, "config.php", `<?php
+$password = 'hunter2';
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := result.GetSourcesByType("http_get")
+	if len(sources) != 1 {
+		t.Fatalf("sources = %d, want 1", len(sources))
+	}
+
+	out := t.TempDir()
+	repro, err := result.ExtractReproduction(sources[0], out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repro.Files) != 2 {
+		t.Fatalf("files = %+v, want index.php and lib.php", repro.Files)
+	}
+
+	tests := []struct {
+		file    string
+		want    []string
+		notWant []string
+	}{
+		{"src/index.php", []string{"require_once 'lib.php';", "$id = $_GET['id'];", "render($id);", "// ... lines 3-7 omitted"}, []string{"unrelated", "config.php"}},
+		{"src/lib.php", []string{"function render($value)", "echo $value;"}, []string{"Secret", "do not share"}},
+		{"README.md", []string{"Input flow:", "src/index.php:8", "src/lib.php: lines"}, nil},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join(out, tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("%s lacks %q:\n%s", tt.file, want, data)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(string(data), notWant) {
+				t.Errorf("%s contains %q:\n%s", tt.file, notWant, data)
+			}
+		}
+	}
+}
+
+func TestTrimToRanges(t *testing.T) {
+	source := []byte("a\nb\nc\nd\ne\n")
+	tests := []struct {
+		name   string
+		ranges [][2]int
+		want   string
+	}{
+		{"leading gap", [][2]int{{3, 5}}, "# ... lines 1-2 omitted\nc\nd\ne\n"},
+		{"inner and trailing gaps", [][2]int{{1, 1}, {3, 3}}, "a\n# ... line 2 omitted\nc\n# ... lines 4-5 omitted\n"},
+		{"everything", [][2]int{{1, 5}}, "a\nb\nc\nd\ne\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(trimToRanges(source, tt.ranges, "#")); got != tt.want {
+				t.Errorf("trimToRanges = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
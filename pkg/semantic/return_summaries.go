@@ -0,0 +1,147 @@
+package semantic
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// returnSummaryExtractor is implemented by analyzers that can summarize which
+// keys of a returned array literal carry input (currently PHP)
+type returnSummaryExtractor interface {
+	ExtractReturnSummaries(root *sitter.Node, source []byte, filePath string) []*types.ReturnSummary
+	FindReturnSummarySources(root *sitter.Node, source []byte, summaries map[string]*types.ReturnSummary) []*types.FlowNode
+}
+
+// returnKeyPattern matches the literal key read from a call's result, e.g. ['id']
+var returnKeyPattern = regexp.MustCompile(`\)\s*\[\s*(?:'([^']*)'|"([^"]*)"|(\d+))\s*\]$`)
+
+// ReturnSummaries returns the functions whose returned arrays have tainted
+// keys, sorted by name
+func (t *Tracer) ReturnSummaries() []*types.ReturnSummary {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	summaries := make([]*types.ReturnSummary, 0, len(t.returnSummaries))
+	for _, s := range t.returnSummaries {
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].FunctionName < summaries[j].FunctionName })
+	return summaries
+}
+
+// promoteReturnSummaries registers the return summaries found while parsing
+// and adds a source node for each read of a tainted key from a call's result
+func (t *Tracer) promoteReturnSummaries() {
+	t.mu.Lock()
+	t.returnSummaries = make(map[string]*types.ReturnSummary)
+	for _, fileInfo := range t.files {
+		for _, s := range fileInfo.ReturnSummaries {
+			if t.trustedFunction(s.FunctionName) {
+				continue
+			}
+			t.returnSummaries[strings.ToLower(s.FunctionName)] = s
+		}
+	}
+	summaries := t.returnSummaries
+	t.mu.Unlock()
+
+	if len(summaries) == 0 {
+		return
+	}
+	if t.config.Verbose {
+		fmt.Printf("  Summarized %d array-returning function(s)\n", len(summaries))
+	}
+
+	t.addDerivedSources(
+		func(fileInfo *FileInfo) bool { return callsReturnSummary(fileInfo, summaries) },
+		func(langAnalyzer analyzer.LanguageAnalyzer, root *sitter.Node, content []byte) []*types.FlowNode {
+			if extractor, ok := langAnalyzer.(returnSummaryExtractor); ok {
+				return extractor.FindReturnSummarySources(root, content, summaries)
+			}
+			return nil
+		},
+		markReturnSummaryTaint,
+	)
+}
+
+// callsReturnSummary reports whether a file calls any summarized function
+func callsReturnSummary(fileInfo *FileInfo, summaries map[string]*types.ReturnSummary) bool {
+	for name := range summaries {
+		if fileInfo.CalledNames[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// markReturnSummaryTaint marks cached assignments and call arguments that read
+// a tainted key on the line it was found on as tainted
+func markReturnSummaryTaint(fileInfo *FileInfo) {
+	reads := make(map[int][]*types.FlowNode)
+	for _, src := range fileInfo.Sources {
+		if _, ok := src.Metadata["return_summary"]; ok {
+			reads[src.Line] = append(reads[src.Line], src)
+		}
+	}
+	for _, assign := range fileInfo.Assignments {
+		if assign.IsTainted {
+			continue
+		}
+		for _, src := range reads[assign.Line] {
+			if containsSourceName(assign.Source, src.Snippet) {
+				assign.IsTainted = true
+				assign.TaintSource = src.Snippet
+				break
+			}
+		}
+	}
+	for _, call := range fileInfo.Calls {
+		for i := range call.Arguments {
+			arg := &call.Arguments[i]
+			if arg.IsTainted {
+				continue
+			}
+			for _, src := range reads[call.Line] {
+				if containsSourceName(arg.Value, src.Snippet) {
+					arg.IsTainted = true
+					arg.TaintSource = src.Snippet
+					call.HasTaintedArgs = true
+					call.TaintedArgIndices = append(call.TaintedArgIndices, i)
+					break
+				}
+			}
+		}
+	}
+}
+
+// identifyReturnSummarySource recognizes a tainted key read directly from a
+// summarized function's result (get_user()['id']) in a backward trace
+func (t *Tracer) identifyReturnSummarySource(expr string, filePath string, line int) *types.SourceInfo {
+	call := wrapperCallPattern.FindStringSubmatch(expr)
+	key := returnKeyPattern.FindStringSubmatch(expr)
+	if call == nil || key == nil {
+		return nil
+	}
+	t.mu.RLock()
+	summary, ok := t.returnSummaries[strings.ToLower(call[1])]
+	t.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	sourceType, ok := summary.TaintedKeys[key[1]+key[2]+key[3]]
+	if !ok {
+		return nil
+	}
+	return &types.SourceInfo{
+		Type:       sourceType,
+		Expression: expr,
+		FilePath:   filePath,
+		Line:       line,
+	}
+}
@@ -0,0 +1,103 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestReturnSummaries(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib.php": `<?php
+function get_user() {
+    $name = $_POST['name'];
+    return ['id' => $_GET['id'], 'name' => 'static', 'alias' => $name, $_COOKIE['c']];
+}
+function pick($flag) {
+    if ($flag) {
+        return ['a' => $_GET['a']];
+    }
+    return $_GET;
+}
+`,
+		"index.php": `<?php
+$res = get_user();
+$id = $res['id'];
+$name = $res['name'];
+show($res['alias']);
+echo get_user()[0];
+$p = pick(1);
+$a = $p['a'];
+`,
+	}
+	for name, code := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tracer := New(nil)
+	result, err := tracer.TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summaries := tracer.ReturnSummaries()
+	if len(summaries) != 1 || summaries[0].FunctionName != "get_user" {
+		t.Fatalf("summaries = %v, want get_user only", summaries)
+	}
+	wantKeys := map[string]types.SourceType{"id": types.SourceHTTPGet, "alias": types.SourceHTTPPost, "0": types.SourceHTTPCookie}
+	if len(summaries[0].TaintedKeys) != len(wantKeys) {
+		t.Errorf("tainted keys = %v, want %v", summaries[0].TaintedKeys, wantKeys)
+	}
+	for key, want := range wantKeys {
+		if got := summaries[0].TaintedKeys[key]; got != want {
+			t.Errorf("key %s source type = %q, want %q", key, got, want)
+		}
+	}
+
+	var derived []string
+	for _, src := range result.Sources {
+		if src.FilePath == filepath.Join(dir, "index.php") {
+			derived = append(derived, src.Snippet)
+		}
+	}
+	sort.Strings(derived)
+	if got, want := strings.Join(derived, " "), "$res['alias'] $res['id'] get_user()[0]"; got != want {
+		t.Errorf("derived sources = %s, want %s", got, want)
+	}
+
+	flowsTo := func(name string) bool {
+		for _, node := range result.FlowMap.AllNodes {
+			if node.Name == name && node.FilePath == filepath.Join(dir, "index.php") {
+				return true
+			}
+		}
+		return false
+	}
+	if !flowsTo("$id") || flowsTo("$name") {
+		t.Errorf("flow to $id = %v, to $name = %v; want true, false", flowsTo("$id"), flowsTo("$name"))
+	}
+
+	backward, err := New(nil).TraceBackward("$x", writeFile(t, dir, "direct.php", "<?php\n$x = get_user()['id'];\n$y = get_user()['name'];\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backward.Sources) != 1 || backward.Sources[0].Type != types.SourceHTTPGet {
+		t.Errorf("backward sources of $x = %v, want one http_get", backward.Sources)
+	}
+}
+
+// writeFile writes a file into dir and returns dir
+func writeFile(t *testing.T, dir, name, code string) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
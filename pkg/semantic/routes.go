@@ -0,0 +1,200 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// routeDispatchFinder is implemented by analyzers that detect custom routers
+// dispatching on a request value (currently PHP)
+type routeDispatchFinder interface {
+	FindRouteDispatches(root *sitter.Node, source []byte) []*types.RouteDispatch
+}
+
+// promoteRouteDispatches resolves the dynamic calls of action maps: every
+// handler the call may run gets a call site with the arguments passed on to
+// it, so input passed to the dispatch flows into the handler's parameters
+func (t *Tracer) promoteRouteDispatches() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, fileInfo := range t.files {
+		for _, d := range fileInfo.RouteDispatches {
+			if d.ArgOffset < 0 {
+				continue
+			}
+			var dynamic *types.CallSite
+			for _, call := range fileInfo.Calls {
+				if call.Line == d.CallLine && call.Column == d.CallColumn {
+					dynamic = call
+					break
+				}
+			}
+			if dynamic == nil {
+				continue // No sources in the file, so no calls were cached
+			}
+			for _, h := range d.Handlers {
+				fileInfo.Calls = append(fileInfo.Calls, handlerCall(dynamic, h.Function, d.ArgOffset))
+			}
+		}
+	}
+}
+
+// handlerCall returns the call of function made by a dynamic call whose
+// arguments from offset on are passed to it
+func handlerCall(dynamic *types.CallSite, function string, offset int) *types.CallSite {
+	call := &types.CallSite{
+		FunctionName: function,
+		FilePath:     dynamic.FilePath,
+		Line:         dynamic.Line,
+		Column:       dynamic.Column,
+		Scope:        dynamic.Scope,
+		Arguments:    make([]types.CallArg, 0),
+	}
+	if class, method, ok := strings.Cut(function, "::"); ok {
+		call.ClassName, call.MethodName = class, method
+	}
+	for i := offset; i < len(dynamic.Arguments); i++ {
+		arg := dynamic.Arguments[i]
+		arg.Index = len(call.Arguments)
+		if arg.IsTainted {
+			call.HasTaintedArgs = true
+			call.TaintedArgIndices = append(call.TaintedArgIndices, arg.Index)
+		}
+		call.Arguments = append(call.Arguments, arg)
+	}
+	return call
+}
+
+// routeKeySource returns the input source selecting the handler of a
+// dispatch: the key expression itself or the source last assigned to the key
+// variable before the dispatch, or nil if the key is not input
+func routeKeySource(fileInfo *FileInfo, d *types.RouteDispatch) *types.FlowNode {
+	key := strings.TrimSpace(d.Key)
+	for _, src := range fileInfo.Sources {
+		if src.Snippet == key && src.Line <= d.Line {
+			return src
+		}
+	}
+
+	var assigned *types.Assignment
+	for _, assign := range fileInfo.Assignments {
+		if assign.Target == key && assign.Line <= d.Line && (assigned == nil || assign.Line > assigned.Line) {
+			assigned = assign
+		}
+	}
+	if assigned == nil {
+		return nil
+	}
+	for _, src := range fileInfo.Sources {
+		if src.Line == assigned.Line && strings.Contains(assigned.Source, src.Snippet) {
+			return src
+		}
+	}
+	return nil
+}
+
+// lookupFunction returns the definition of a function or Class::method in
+// the global symbol table; callers hold t.mu
+func (t *Tracer) lookupFunction(name string) *types.FunctionDef {
+	if fn := t.symbolTable.Functions[name]; fn != nil {
+		return fn
+	}
+	for key, fn := range t.symbolTable.Functions {
+		if strings.HasSuffix(key, "::"+name) {
+			return fn
+		}
+	}
+	if class, method, ok := strings.Cut(name, "::"); ok {
+		if classDef := t.symbolTable.Classes[class]; classDef != nil {
+			if m := classDef.Methods[method]; m != nil {
+				return &types.FunctionDef{Name: name, Line: m.Line, FilePath: classDef.FilePath, Parameters: m.Parameters}
+			}
+		}
+	}
+	return nil
+}
+
+// routeEntryPoints returns an entry point for every handler a custom router
+// selects from input, routed as the dispatching file with the key and
+// action, e.g. "/index.php?action=edit"; callers hold t.mu
+func (t *Tracer) routeEntryPoints(rootPath string, filePaths []string) []*EntryPoint {
+	var eps []*EntryPoint
+	for _, filePath := range filePaths {
+		fileInfo := t.files[filePath]
+		for _, d := range fileInfo.RouteDispatches {
+			src := routeKeySource(fileInfo, d)
+			if src == nil {
+				continue
+			}
+			for _, h := range d.Handlers {
+				if t.lookupFunction(h.Function) == nil {
+					continue
+				}
+				eps = append(eps, &EntryPoint{
+					Kind:     EntryPointRoute,
+					FilePath: filePath,
+					Function: h.Function,
+					Line:     h.Line,
+					Route:    routeFor(fileRoute(rootPath, filePath), src, h.Action),
+					Inputs:   []types.SourceType{src.SourceType},
+				})
+			}
+		}
+	}
+	return eps
+}
+
+// routeFor appends the action to a file route: as a query parameter when
+// the key is a named GET parameter, otherwise as a fragment
+func routeFor(fileRoute string, src *types.FlowNode, action string) string {
+	if src.SourceType == types.SourceHTTPGet && src.SourceKey != "" {
+		return fmt.Sprintf("%s?%s=%s", fileRoute, src.SourceKey, action)
+	}
+	return fmt.Sprintf("%s#%s", fileRoute, action)
+}
+
+// linkRouteHandlers connects the source selecting each route to the handlers
+// it selects, so a handler's flows start at the request value choosing it
+func (t *Tracer) linkRouteHandlers(flowMap *types.FlowMap) {
+	if flowMap == nil {
+		return
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, fileInfo := range t.files {
+		for _, d := range fileInfo.RouteDispatches {
+			src := routeKeySource(fileInfo, d)
+			if src == nil {
+				continue
+			}
+			for _, h := range d.Handlers {
+				fn := t.lookupFunction(h.Function)
+				if fn == nil {
+					continue
+				}
+				funcNode := types.FlowNode{
+					ID:       types.NodeID(fn.FilePath, fn.Line, 0, types.NodeKindFunc, fn.Name),
+					Type:     types.NodeFunction,
+					Language: src.Language,
+					FilePath: fn.FilePath,
+					Line:     fn.Line,
+					Name:     fn.Name,
+					Snippet:  fn.Name + "()",
+				}
+				flowMap.AddNode(funcNode)
+				flowMap.AddEdge(types.FlowEdge{
+					From:        src.ID,
+					To:          funcNode.ID,
+					Type:        types.EdgeCall,
+					Description: fmt.Sprintf("routes %q to %s", h.Action, h.Function),
+				})
+				t.stats.FlowsTraced++
+			}
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package semantic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestCustomRouterEntryPoints(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+function edit_item($id) { echo $id; }
+class Items { static function delete() {} }
+function show_list() {}
+function save_item($req) { echo $req['name']; }
+function view_item($req) {}
+switch ($_GET['action']) {
+    case 'edit':
+        edit_item($_GET['id']);
+        break;
+    case 'delete':
+        Items::delete();
+        break;
+    default:
+        show_list();
+}
+$routes = ['save' => 'save_item', 'view' => 'view_item'];
+$act = $_REQUEST['do'];
+$routes[$act]($_POST);
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := make(map[string]string)
+	for _, ep := range result.EntryPoints {
+		if ep.Kind == EntryPointRoute {
+			routes[ep.Route] = ep.Function
+		}
+	}
+	want := map[string]string{
+		"/index.php?action=edit":   "edit_item",
+		"/index.php?action=delete": "Items::delete",
+		"/index.php#save":          "save_item",
+		"/index.php#view":          "view_item",
+	}
+	if len(routes) != len(want) {
+		t.Errorf("routes = %v, want %v", routes, want)
+	}
+	for route, function := range want {
+		if routes[route] != function {
+			t.Errorf("route %s = %q, want %q", route, routes[route], function)
+		}
+	}
+
+	var routed, paramFlow bool
+	for _, edge := range result.FlowMap.AllEdges {
+		if edge.Description == `routes "save" to save_item` {
+			routed = true
+		}
+	}
+	for _, node := range result.FlowMap.AllNodes {
+		if node.Type == types.NodeVariable && node.Name == "req" && node.Line == 5 {
+			paramFlow = true
+		}
+	}
+	if !routed {
+		t.Error("no edge from $_REQUEST['do'] to save_item")
+	}
+	if !paramFlow {
+		t.Error("$_POST not traced into save_item's parameter")
+	}
+}
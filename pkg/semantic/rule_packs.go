@@ -0,0 +1,57 @@
+package semantic
+
+import (
+	"fmt"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+)
+
+// loadRulePacks builds the rules of the run: the built-in rules with
+// Config.RulePacks and then the files named by Config.RulePackFiles applied,
+// in order. Packs change this tracer's rules only (see sources.RuleSet).
+func (t *Tracer) loadRulePacks() error {
+	rules := sources.NewRuleSet()
+	for _, pack := range t.config.RulePacks {
+		if err := rules.Apply(pack); err != nil {
+			return fmt.Errorf("rule pack %s: %w", pack.Name, err)
+		}
+	}
+	for _, path := range t.config.RulePackFiles {
+		pack, err := sources.LoadRulePack(path)
+		if err != nil {
+			return err
+		}
+		if err := rules.Apply(pack); err != nil {
+			return fmt.Errorf("rule pack %s: %w", path, err)
+		}
+	}
+	t.ruleSet = rules
+	t.analyzers = analyzersFor(rules)
+	t.frameworkVersions = nil // Set again by applyFrameworkVersions
+	return nil
+}
+
+// analyzersFor returns the language analyzers matching the framework
+// patterns of a rule set
+func analyzersFor(rules *sources.RuleSet) *analyzer.Registry {
+	return analyzer.DefaultRegistry.WithFrameworkPatterns(rules.Patterns)
+}
+
+// rulesFor returns the rules a file is analyzed with: those of its subtree
+// (see SubtreeRule), or the run's
+func (t *Tracer) rulesFor(path string) *sources.RuleSet {
+	if subtree := t.subtreeOf(path); subtree != nil {
+		return subtree.ruleSet
+	}
+	return t.ruleSet
+}
+
+// languageAnalyzer returns the analyzer of a language matching the rules a
+// file is analyzed with, or nil
+func (t *Tracer) languageAnalyzer(path, language string) analyzer.LanguageAnalyzer {
+	if subtree := t.subtreeOf(path); subtree != nil {
+		return subtree.analyzers.Get(language)
+	}
+	return t.analyzers.Get(language)
+}
@@ -0,0 +1,107 @@
+package semantic
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+func TestRulePacks(t *testing.T) {
+	pack := &sources.RulePack{
+		Format:  sources.RulePackFormat,
+		Name:    "acme",
+		Version: "1",
+		FrameworkPatterns: []*common.FrameworkPattern{{
+			ID:            "rulepack_test_acme_param",
+			Framework:     "acme",
+			Language:      "php",
+			Name:          "Acme $req->param()",
+			ClassPattern:  "^AcmeRequest$",
+			MethodPattern: "^param$",
+			SourceType:    common.SourceHTTPGet,
+		}},
+		Functions: map[string]*sources.FunctionRuleSet{
+			"php": {Decoding: []string{"Acme_Unescape"}},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "acme.json")
+	if err := pack.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	tracer := New(&Config{RulePackFiles: []string{path}})
+	if err := tracer.loadRules(); err != nil {
+		t.Fatal(err)
+	}
+
+	hasPattern := func(a analyzer.LanguageAnalyzer) bool {
+		for _, p := range a.GetFrameworkPatterns() {
+			if p.ID == "rulepack_test_acme_param" {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasPattern(tracer.languageAnalyzer("", "php")) {
+		t.Error("PHP analyzer of the tracer does not match the pack's framework pattern")
+	}
+	if !tracer.ruleSet.IsDecodingFunction("acme_unescape") || tracer.ruleSet.IsDecodingFunction("urldecode") {
+		t.Error("pack's decoding functions did not replace the built-in list")
+	}
+
+	// The built-in rules and other tracers are not changed
+	if hasPattern(analyzer.DefaultRegistry.Get("php")) || php.Registry.GetByID("rulepack_test_acme_param") != nil {
+		t.Error("pack's framework pattern leaked into the built-in rules")
+	}
+	if php.IsDecodingFunction("acme_unescape") || !php.IsDecodingFunction("urldecode") {
+		t.Error("pack's decoding functions leaked into the built-in rules")
+	}
+	other := New(nil)
+	if err := other.loadRules(); err != nil {
+		t.Fatal(err)
+	}
+	if hasPattern(other.languageAnalyzer("", "php")) || other.ruleSet.IsDecodingFunction("acme_unescape") {
+		t.Error("pack of one tracer applies to another")
+	}
+
+	exported := tracer.ruleSet.Export("merged", "2")
+	count := 0
+	for _, p := range exported.FrameworkPatterns {
+		if p.ID == "rulepack_test_acme_param" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("exported pack has the pattern %d times, want 1", count)
+	}
+	if exported.Sources["php"]["$_GET"] != common.SourceHTTPGet {
+		t.Errorf("exported php sources = %v, want $_GET", exported.Sources["php"])
+	}
+	if len(exported.GeneratedMethods["laravel"]) == 0 {
+		t.Error("exported pack lists no generated laravel methods")
+	}
+
+	// Applying the pack again overrides the pattern instead of duplicating it
+	rules := tracer.ruleSet.Clone()
+	if err := rules.Apply(pack); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, p := range rules.Patterns("php") {
+		if p.Framework == "acme" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("acme has %d patterns after re-applying, want 1", n)
+	}
+
+	bad := &sources.RulePack{Format: sources.RulePackFormat + 1}
+	if err := New(&Config{RulePacks: []*sources.RulePack{bad}}).loadRules(); err == nil {
+		t.Error("expected an error for an unsupported pack format")
+	}
+}
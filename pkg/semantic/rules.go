@@ -0,0 +1,131 @@
+package semantic
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
+// Rules holds user declarations for what cannot be auto-discovered. A rules
+// file is JSON:
+//
+//	{
+//	  "entrypoints": [
+//	    {"file": "cron/run.php", "function": "main", "inputs": ["argv"]}
+//	  ],
+//	  "labels": [
+//	    {"label": "pii", "inputs": ["post"], "keys": ["email", "phone*"]}
+//	  ],
+//	  "layers": [
+//	    {"layer": "model", "files": ["src/Domain/**"], "classes": ["*Service"]}
+//	  ],
+//	  "validators": [
+//	    {"function": "require_slug", "type": "pattern", "pattern": "^[a-z-]+$"},
+//	    {"function": "assert_present", "disabled": true}
+//	  ],
+//	  "barriers": [
+//	    {"function": "AuthService::currentUser", "trust": "trusted"},
+//	    {"name": "admin module", "files": ["admin/**"], "trust": "untrusted"}
+//	  ],
+//	  "subtrees": [
+//	    {"name": "legacy", "files": ["apps/legacy/**"], "rule_packs": ["packs/mybb.json"]},
+//	    {"name": "api", "files": ["apps/api/**"], "framework_versions": {"laravel": "11.0"}}
+//	  ]
+//	}
+type Rules struct {
+	EntryPoints []EntryPointRule `json:"entrypoints,omitempty"`
+	Labels      []LabelRule      `json:"labels,omitempty"`
+	Layers      []LayerRule      `json:"layers,omitempty"`
+	Validators  []ValidatorRule  `json:"validators,omitempty"`
+	Barriers    []BarrierRule    `json:"barriers,omitempty"`
+	Subtrees    []SubtreeRule    `json:"subtrees,omitempty"`
+}
+
+// EntryPointRule declares an entry point (cron script, custom router target)
+type EntryPointRule struct {
+	File     string   `json:"file"`               // Relative to the scanned directory, or absolute
+	Function string   `json:"function,omitempty"` // Empty: the file's top-level code
+	Route    string   `json:"route,omitempty"`    // Defaults to the file's route
+	Inputs   []string `json:"inputs,omitempty"`   // Source types or aliases (argv, env, get, ...)
+}
+
+// LoadRules reads and validates a rules file
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules file: %w", err)
+	}
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parsing rules file %s: %w", path, err)
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("rules file %s: %w", path, err)
+	}
+	return &rules, nil
+}
+
+// Validate checks that every declaration is complete and uses known inputs
+func (r *Rules) Validate() error {
+	for i, ep := range r.EntryPoints {
+		if ep.File == "" {
+			return fmt.Errorf("entrypoints[%d]: file is required", i)
+		}
+		for _, input := range ep.Inputs {
+			if _, ok := common.ParseSourceType(input); !ok {
+				return fmt.Errorf("entrypoints[%d]: unknown input %q", i, input)
+			}
+		}
+	}
+	for i, rule := range r.Labels {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("labels[%d]: %w", i, err)
+		}
+	}
+	for i, rule := range r.Layers {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("layers[%d]: %w", i, err)
+		}
+	}
+	for i, rule := range r.Validators {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("validators[%d]: %w", i, err)
+		}
+	}
+	for i, rule := range r.Barriers {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("barriers[%d]: %w", i, err)
+		}
+	}
+	for i, rule := range r.Subtrees {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("subtrees[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// loadRules resolves the rules for this run: Config.Rules if set, otherwise
+// the file named by Config.RulesFile. Rule packs are applied first; those of
+// subtrees are applied to the files of their subtree only (see rulesFor).
+func (t *Tracer) loadRules() error {
+	if err := t.loadRulePacks(); err != nil {
+		return err
+	}
+	switch {
+	case t.config.Rules != nil:
+		if err := t.config.Rules.Validate(); err != nil {
+			return fmt.Errorf("rules: %w", err)
+		}
+		t.rules = t.config.Rules
+	case t.config.RulesFile != "":
+		rules, err := LoadRules(t.config.RulesFile)
+		if err != nil {
+			return err
+		}
+		t.rules = rules
+	}
+	return t.loadSubtreePacks()
+}
@@ -0,0 +1,244 @@
+package semantic
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
+// Runtime hint kinds
+const (
+	HintRequestParam = "request_param" // A request parameter observed at runtime
+	HintService      = "service"       // The class a DI service resolved to
+	HintInclude      = "include"       // A file actually included at runtime
+)
+
+// RuntimeHint is one line of a runtime log gathered by an instrumentation shim
+// on a dev or staging instance. Paths are relative to the scanned directory
+// or absolute.
+//
+//	{"kind": "request_param", "input": "get", "key": "sort", "file": "list.php"}
+//	{"kind": "service", "service": "mailer", "class": "SmtpMailer"}
+//	{"kind": "include", "file": "index.php", "line": 12, "path": "modules/news.php"}
+type RuntimeHint struct {
+	Kind    string `json:"kind"`
+	File    string `json:"file,omitempty"`    // Requested script (request_param) or including file (include)
+	Line    int    `json:"line,omitempty"`    // Line of the include
+	Path    string `json:"path,omitempty"`    // Included file
+	Input   string `json:"input,omitempty"`   // Source type or alias (get, post, cookie, ...)
+	Key     string `json:"key,omitempty"`     // Parameter name
+	Service string `json:"service,omitempty"` // Service name passed to the container
+	Class   string `json:"class,omitempty"`   // Class the service resolved to
+}
+
+// RuntimeHints is a runtime log used to resolve constructs that static
+// analysis cannot: dynamic includes, container-resolved services and
+// parameters read through dynamic keys
+type RuntimeHints struct {
+	Hints []RuntimeHint
+}
+
+// LoadRuntimeHints reads a JSONL runtime log
+func LoadRuntimeHints(path string) (*RuntimeHints, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading runtime log: %w", err)
+	}
+	defer f.Close()
+
+	hints := &RuntimeHints{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var hint RuntimeHint
+		if err := json.Unmarshal([]byte(line), &hint); err != nil {
+			return nil, fmt.Errorf("parsing runtime log %s line %d: %w", path, n, err)
+		}
+		if err := hint.validate(); err != nil {
+			return nil, fmt.Errorf("runtime log %s line %d: %w", path, n, err)
+		}
+		hints.Hints = append(hints.Hints, hint)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading runtime log %s: %w", path, err)
+	}
+	return hints, nil
+}
+
+// Validate checks that every hint is complete and uses known inputs
+func (h *RuntimeHints) Validate() error {
+	for i, hint := range h.Hints {
+		if err := hint.validate(); err != nil {
+			return fmt.Errorf("hints[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// validate checks that the hint has the fields its kind requires
+func (h RuntimeHint) validate() error {
+	switch h.Kind {
+	case HintRequestParam:
+		if h.Input == "" || h.Key == "" {
+			return fmt.Errorf("request_param needs input and key")
+		}
+		if _, ok := common.ParseSourceType(h.Input); !ok {
+			return fmt.Errorf("unknown input %q", h.Input)
+		}
+	case HintService:
+		if h.Service == "" || h.Class == "" {
+			return fmt.Errorf("service needs service and class")
+		}
+	case HintInclude:
+		if h.File == "" || h.Path == "" {
+			return fmt.Errorf("include needs file and path")
+		}
+	default:
+		return fmt.Errorf("unknown hint kind %q", h.Kind)
+	}
+	return nil
+}
+
+// ServiceClasses returns the class each observed service resolved to
+func (h *RuntimeHints) ServiceClasses() map[string]string {
+	if h == nil {
+		return nil
+	}
+	classes := make(map[string]string)
+	for _, hint := range h.Hints {
+		if hint.Kind == HintService {
+			classes[hint.Service] = hint.Class
+		}
+	}
+	return classes
+}
+
+// loadRuntimeHints loads Config.RuntimeHints or Config.RuntimeHintsFile
+func (t *Tracer) loadRuntimeHints() error {
+	switch {
+	case t.config.RuntimeHints != nil:
+		if err := t.config.RuntimeHints.Validate(); err != nil {
+			return fmt.Errorf("runtime hints: %w", err)
+		}
+		t.runtimeHints = t.config.RuntimeHints
+	case t.config.RuntimeHintsFile != "":
+		hints, err := LoadRuntimeHints(t.config.RuntimeHintsFile)
+		if err != nil {
+			return err
+		}
+		t.runtimeHints = hints
+	}
+	return nil
+}
+
+// hintPath resolves a path from the runtime log against the scanned directory
+func hintPath(path, rootPath string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(rootPath, path)
+	}
+	return filepath.Clean(path)
+}
+
+// runtimeIncludes returns the includes observed at runtime, as the set of
+// included files by including file
+func (t *Tracer) runtimeIncludes(rootPath string) map[string]map[string]bool {
+	if t.runtimeHints == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	includes := make(map[string]map[string]bool)
+	for _, hint := range t.runtimeHints.Hints {
+		if hint.Kind != HintInclude {
+			continue
+		}
+		from, to := hintPath(hint.File, rootPath), hintPath(hint.Path, rootPath)
+		if includes[from][to] || t.files[from] == nil || !t.inSandbox(to, from, hint.Line) {
+			continue
+		}
+		if t.files[to] == nil {
+			t.warn(types.WarningUnresolvedInclude, from, hint.Line, hint.Path, fmt.Sprintf("runtime include %s not found in the scanned files", hint.Path))
+			continue
+		}
+		if includes[from] == nil {
+			includes[from] = make(map[string]bool)
+		}
+		includes[from][to] = true
+	}
+	return includes
+}
+
+// runtimeAssistedChain reports whether an include chain uses an include that
+// was only observed at runtime
+func (t *Tracer) runtimeAssistedChain(files []string) bool {
+	for i := 1; i < len(files); i++ {
+		if t.runtimeOnlyIncludes[files[i-1]][files[i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRuntimeKeys records the parameter keys observed at runtime on sources
+// read without a static key (whole arrays, dynamic keys). The keys are those
+// observed for the source's input type, for its file when the log names one.
+func (t *Tracer) applyRuntimeKeys(sources []*types.FlowNode, rootPath string) {
+	if t.runtimeHints == nil {
+		return
+	}
+	type scope struct {
+		input types.SourceType
+		file  string
+	}
+	keys := make(map[scope]map[string]bool)
+	for _, hint := range t.runtimeHints.Hints {
+		if hint.Kind != HintRequestParam {
+			continue
+		}
+		input, _ := common.ParseSourceType(hint.Input)
+		s := scope{input: input}
+		if hint.File != "" {
+			s.file = hintPath(hint.File, rootPath)
+		}
+		if keys[s] == nil {
+			keys[s] = make(map[string]bool)
+		}
+		keys[s][hint.Key] = true
+	}
+
+	for _, src := range sources {
+		if src.SourceKey != "" {
+			continue
+		}
+		anyFile, inFile := keys[scope{input: src.SourceType}], keys[scope{input: src.SourceType, file: src.FilePath}]
+		if len(anyFile) == 0 && len(inFile) == 0 {
+			continue
+		}
+		observed := make([]string, 0, len(anyFile)+len(inFile))
+		for key := range anyFile {
+			observed = append(observed, key)
+		}
+		for key := range inFile {
+			if !anyFile[key] {
+				observed = append(observed, key)
+			}
+		}
+		sort.Strings(observed)
+		if src.Metadata == nil {
+			src.Metadata = make(map[string]interface{})
+		}
+		src.Metadata["runtime_keys"] = observed
+		src.Metadata["runtime_assisted"] = true
+	}
+}
@@ -0,0 +1,116 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestLoadRuntimeHints(t *testing.T) {
+	tests := []struct {
+		name    string
+		log     string
+		wantErr string
+		want    int
+	}{
+		{"valid", `{"kind":"include","file":"index.php","path":"a.php"}` + "\n\n" + `{"kind":"service","service":"db","class":"Db"}`, "", 2},
+		{"bad json", `{"kind":"include"` + "\n", "line 1", 0},
+		{"unknown kind", `{"kind":"env"}`, "unknown hint kind", 0},
+		{"unknown input", `{"kind":"request_param","input":"shell","key":"id"}`, "unknown input", 0},
+		{"missing class", "\n" + `{"kind":"service","service":"db"}`, "line 2", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "runtime.jsonl")
+			if err := os.WriteFile(path, []byte(tt.log), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			hints, err := LoadRuntimeHints(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hints.Hints) != tt.want {
+				t.Errorf("loaded %d hints, want %d", len(hints.Hints), tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceDirectoryRuntimeHints(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.php":        "<?php\n$page = $_COOKIE['page'];\ninclude \"modules/$page.php\";\n$id = $_GET['id'];\nshow($id);\n",
+		"modules/news.php": "<?php\nfunction show($x) {\n    $y = $x;\n}\n",
+		"list.php":         "<?php\nforeach ($_GET as $k => $v) {\n    echo $v;\n}\n",
+	}
+	for name, code := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	trace := func(hints *RuntimeHints) *TraceResult {
+		config := DefaultConfig()
+		config.RuntimeHints = hints
+		result, err := New(config).TraceDirectory(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	reachability := func(r *TraceResult) types.Reachability {
+		for _, edge := range r.FlowMap.AllEdges {
+			if edge.IncludeChain != nil {
+				return edge.IncludeChain.Reachability
+			}
+		}
+		return ""
+	}
+	runtimeKeys := func(r *TraceResult) interface{} {
+		for _, src := range r.Sources {
+			if src.FilePath == filepath.Join(dir, "list.php") {
+				return src.Metadata["runtime_keys"]
+			}
+		}
+		return nil
+	}
+
+	plain := trace(nil)
+	if got := reachability(plain); got != types.ReachabilityUnproven {
+		t.Errorf("reachability without hints = %q, want %q", got, types.ReachabilityUnproven)
+	}
+	if got := runtimeKeys(plain); got != nil {
+		t.Errorf("runtime keys without hints = %v", got)
+	}
+
+	hinted := trace(&RuntimeHints{Hints: []RuntimeHint{
+		{Kind: HintInclude, File: "index.php", Line: 3, Path: "modules/news.php"},
+		{Kind: HintRequestParam, Input: "get", Key: "sort", File: "list.php"},
+		{Kind: HintRequestParam, Input: "get", Key: "page"},
+		{Kind: HintRequestParam, Input: "post", Key: "token"},
+		// Repeated observations are recorded once
+		{Kind: HintInclude, File: "index.php", Line: 3, Path: "modules/news.php"},
+		{Kind: HintRequestParam, Input: "get", Key: "page", File: "list.php"},
+		{Kind: HintRequestParam, Input: "get", Key: "sort", File: "list.php"},
+	}})
+	if got := reachability(hinted); got != types.ReachabilityRuntime {
+		t.Errorf("reachability with hints = %q, want %q", got, types.ReachabilityRuntime)
+	}
+	if got, want := runtimeKeys(hinted), []string{"page", "sort"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("runtime keys = %v, want %v", got, want)
+	}
+}
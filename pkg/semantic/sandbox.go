@@ -0,0 +1,43 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// inSandbox reports whether path may be read, recording a sandbox_escape
+// warning when it may not. from and line locate the code that resolved path.
+func (t *Tracer) inSandbox(path, from string, line int) bool {
+	if t.sandbox.Allows(path) {
+		return true
+	}
+	if from == "" {
+		from = path
+	}
+	t.warn(types.WarningSandboxEscape, from, line, path, fmt.Sprintf("%s resolves outside the sandbox roots and was not read", path))
+	return false
+}
+
+// checkSandboxRoot fails when the scanned path itself is outside the sandbox
+func (t *Tracer) checkSandboxRoot(path string) error {
+	if t.sandbox.Allows(path) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s is not inside %s", types.ErrSandboxEscape, path, strings.Join(t.config.SandboxRoots, ", "))
+}
+
+// readFile reads a file through the sandbox, recording a sandbox_escape
+// warning for a path outside it; content the parser cache holds is not read
+// again. All reads of analyzed files after discovery go through it.
+func (t *Tracer) readFile(path string) ([]byte, error) {
+	if !t.inSandbox(path, "", 0) {
+		return nil, fmt.Errorf("%w: %s", types.ErrSandboxEscape, path)
+	}
+	if t.parserService != nil {
+		return t.parserService.Content(path)
+	}
+	return os.ReadFile(path)
+}
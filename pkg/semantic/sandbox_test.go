@@ -0,0 +1,48 @@
+package semantic
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestTraceDirectorySandbox(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "app")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(dir, "config.php")
+	if err := os.WriteFile(outside, []byte("<?php $key = $_GET['key'];"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	index := "<?php include '../config.php'; $id = $_GET['id'];"
+	if err := os.WriteFile(filepath.Join(root, "index.php"), []byte(index), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "config.php")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.SandboxRoots = []string{root}
+	result, err := New(config).TraceDirectory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, src := range result.Sources {
+		if src.SourceKey == "key" {
+			t.Errorf("source read from a file outside the sandbox: %s", src.FilePath)
+		}
+	}
+	if got := result.WarningCounts[types.WarningSandboxEscape]; got != 2 {
+		t.Errorf("sandbox_escape warnings = %d, want 2 (symlink and include)", got)
+	}
+
+	if _, err := New(config).TraceDirectory(dir); !errors.Is(err, types.ErrSandboxEscape) {
+		t.Errorf("scanning outside the sandbox: err = %v, want ErrSandboxEscape", err)
+	}
+}
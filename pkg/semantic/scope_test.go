@@ -0,0 +1,44 @@
+package semantic
+
+import "testing"
+
+func TestBackwardTraceScopes(t *testing.T) {
+	dir := writeFile(t, t.TempDir(), "index.php", `<?php
+$token = $_COOKIE['token'];
+function search() { $q = $_GET['q']; $copy = $q; }
+function other() { $copy2 = $q; }
+function session() { global $token; $sess = $token; }
+function fixed() { $token = 'fixed'; $local = $token; }
+function setup() { global $cfg; $cfg = $_POST['cfg']; }
+$config = $cfg;
+`)
+
+	tests := []struct {
+		target string
+		want   string // Source expected, "" for none
+	}{
+		{"$copy", "$_GET['q']"},
+		{"$copy2", ""},                 // $q is a local of other()
+		{"$sess", "$_COOKIE['token']"}, // global $token reads the file-level variable
+		{"$local", ""},                 // A local $token shadows the file-level one
+		{"$config", "$_POST['cfg']"},   // Assigned through global $cfg in setup()
+	}
+	tracer := New(nil)
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			result, err := tracer.TraceBackward(tt.target, dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				if len(result.Sources) != 0 {
+					t.Errorf("sources = %+v, want none", result.Sources)
+				}
+				return
+			}
+			if len(result.Sources) != 1 || result.Sources[0].Expression != tt.want {
+				t.Errorf("sources = %+v, want %s", result.Sources, tt.want)
+			}
+		})
+	}
+}
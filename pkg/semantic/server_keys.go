@@ -0,0 +1,49 @@
+package semantic
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+// serverKeyTrusted reports whether a $_SERVER key holds server configuration
+// rather than request data; Config.TrustedServerKeys overrides the defaults
+func (t *Tracer) serverKeyTrusted(key string) bool {
+	key = strings.ToUpper(key)
+	for configured, trusted := range t.config.TrustedServerKeys {
+		if strings.ToUpper(configured) == key {
+			return trusted
+		}
+	}
+	return sources.IsServerKeyTrusted(key)
+}
+
+// dropTrustedServerKeys removes the $_SERVER sources reading trusted keys
+// (SERVER_ADDR, SCRIPT_FILENAME, ...): they are not input
+func (t *Tracer) dropTrustedServerKeys(srcs []*types.FlowNode) []*types.FlowNode {
+	kept := srcs[:0]
+	for _, src := range srcs {
+		if src.Name == "$_SERVER" && src.SourceKey != "" && t.serverKeyTrusted(src.SourceKey) {
+			continue
+		}
+		kept = append(kept, src)
+	}
+	return kept
+}
+
+// onlyTrustedServerKeys reports whether every $_SERVER access in an
+// expression reads a literal trusted key
+func (t *Tracer) onlyTrustedServerKeys(expr string) bool {
+	keys, allLiteral := phpPatterns.ServerKeys(expr)
+	if !allLiteral || len(keys) == 0 {
+		return false
+	}
+	for _, key := range keys {
+		if !t.serverKeyTrusted(key) {
+			return false
+		}
+	}
+	return true
+}
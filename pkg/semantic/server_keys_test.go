@@ -0,0 +1,58 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestServerKeyTrust(t *testing.T) {
+	code := `<?php
+$uri = $_SERVER['REQUEST_URI'];
+$agent = $_SERVER['HTTP_USER_AGENT'];
+$addr = $_SERVER['SERVER_ADDR'];
+$script = $_SERVER['SCRIPT_FILENAME'];
+$custom = $_SERVER['APP_TENANT'];
+`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.php"), []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string]bool
+		want      string
+	}{
+		{"defaults", nil, "APP_TENANT:http_header HTTP_USER_AGENT:http_header REQUEST_URI:http_path"},
+		{"overrides", map[string]bool{"app_tenant": true, "SERVER_ADDR": false}, "HTTP_USER_AGENT:http_header REQUEST_URI:http_path SERVER_ADDR:http_header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.TrustedServerKeys = tt.overrides
+			result, err := New(config).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, src := range result.Sources {
+				got = append(got, src.SourceKey+":"+string(src.SourceType))
+			}
+			sort.Strings(got)
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("sources = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	tracer := New(nil)
+	if tracer.identifySource("$_SERVER['SCRIPT_FILENAME']", "index.php", 1) != nil {
+		t.Error("trusted key identified as a source")
+	}
+	if tracer.identifySource("$_SERVER['SCRIPT_FILENAME'] . $_SERVER[$k]", "index.php", 1) == nil {
+		t.Error("dynamic $_SERVER key not identified as a source")
+	}
+}
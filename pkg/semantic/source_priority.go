@@ -0,0 +1,111 @@
+package semantic
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// defaultMaxTracedSources is the number of sources traced when
+// Config.MaxTracedSources is 0; each traced source costs file re-parsing
+const defaultMaxTracedSources = 200
+
+// maxTracedSources returns the source cap, or 0 when tracing is unlimited
+func (t *Tracer) maxTracedSources() int {
+	switch {
+	case t.config.MaxTracedSources < 0:
+		return 0
+	case t.config.MaxTracedSources == 0:
+		return defaultMaxTracedSources
+	default:
+		return t.config.MaxTracedSources
+	}
+}
+
+// prioritizeSources orders sources for capping: sources in entry-point files
+// first, then the first source of each distinct input key before repeats of
+// that key, then higher-confidence detections. Ties keep file and line order.
+func (t *Tracer) prioritizeSources(sources []*types.FlowNode) []*types.FlowNode {
+	entryFiles := make(map[string]bool, len(t.entryPoints))
+	for _, ep := range t.entryPoints {
+		entryFiles[ep.FilePath] = true
+	}
+
+	type rankedSource struct {
+		src        *types.FlowNode
+		entry      bool
+		confidence float64
+		duplicate  bool
+	}
+	t.mu.RLock()
+	ranked := make([]rankedSource, len(sources))
+	for i, src := range sources {
+		ranked[i] = rankedSource{
+			src:        src,
+			entry:      entryFiles[src.FilePath],
+			confidence: t.sourceConfidence(src),
+		}
+	}
+	t.mu.RUnlock()
+
+	// Rank without uniqueness first so the best occurrence of a key is the
+	// one kept ahead of its duplicates
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.entry != b.entry {
+			return a.entry
+		}
+		if a.confidence != b.confidence {
+			return a.confidence > b.confidence
+		}
+		if a.src.FilePath != b.src.FilePath {
+			return a.src.FilePath < b.src.FilePath
+		}
+		if a.src.Line != b.src.Line {
+			return a.src.Line < b.src.Line
+		}
+		return a.src.Column < b.src.Column
+	})
+	seen := make(map[string]bool, len(ranked))
+	for i := range ranked {
+		key := ranked[i].src.Canonical
+		ranked[i].duplicate = seen[key]
+		seen[key] = true
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.entry != b.entry {
+			return a.entry
+		}
+		return !a.duplicate && b.duplicate
+	})
+
+	ordered := make([]*types.FlowNode, len(ranked))
+	for i, r := range ranked {
+		ordered[i] = r.src
+	}
+	return ordered
+}
+
+// sourceConfidence scores how certain a source detection is (0.0-1.0).
+// Sources derived from wrapper functions or request attributes, sources with
+// an unknown input type and sources in generated files score lower.
+func (t *Tracer) sourceConfidence(src *types.FlowNode) float64 {
+	confidence := 1.0
+	if src.SourceType == "" || src.SourceType == types.SourceUnknown {
+		confidence -= 0.4
+	}
+	if _, derived := src.Metadata["derivation"]; derived {
+		confidence -= 0.2
+	} else if t.sourceWrappers[strings.ToLower(src.Name)] != nil {
+		confidence -= 0.2
+	}
+	if src.SourceKey == "" {
+		confidence -= 0.1
+	}
+	if fileInfo := t.files[src.FilePath]; fileInfo != nil && fileInfo.Generated != GeneratedNone {
+		confidence -= 0.2
+	}
+	return confidence
+}
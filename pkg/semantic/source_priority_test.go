@@ -0,0 +1,68 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestMaxTracedSources(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "index.php", `<?php
+require 'lib/input.php';
+$c = $_COOKIE['c'];
+`)
+	writeFile(t, dir, "lib/input.php", `<?php
+$a = $_GET['id'];
+$b = $_GET['id'];
+$x = $_POST['x'];
+`)
+
+	tests := []struct {
+		name string
+		max  int
+		want []string // Skipped sources as file:line, in priority order
+	}{
+		{"unlimited", -1, nil},
+		{"default cap", 0, nil},
+		{"entry point first", 1, []string{"lib/input.php:2", "lib/input.php:4", "lib/input.php:3"}},
+		{"distinct keys before repeats", 2, []string{"lib/input.php:4", "lib/input.php:3"}},
+		{"repeat skipped last", 3, []string{"lib/input.php:3"}},
+		{"cap above the source count", 4, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.MaxTracedSources = tt.max
+			result, err := New(config).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var skipped []string
+			for _, src := range result.SkippedSources {
+				rel, _ := filepath.Rel(dir, src.FilePath)
+				skipped = append(skipped, fmt.Sprintf("%s:%d", filepath.ToSlash(rel), src.Line))
+			}
+			if !reflect.DeepEqual(skipped, tt.want) {
+				t.Errorf("skipped = %v, want %v", skipped, tt.want)
+			}
+			if result.Stats.SourcesSkipped != len(tt.want) {
+				t.Errorf("SourcesSkipped = %d, want %d", result.Stats.SourcesSkipped, len(tt.want))
+			}
+			wantWarnings := 0
+			if len(tt.want) > 0 {
+				wantWarnings = 1
+			}
+			if got := result.WarningCounts[types.WarningSourceLimit]; got != wantWarnings {
+				t.Errorf("source_limit warnings = %d, want %d", got, wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// TraceStrategy controls the order in which flows are explored
+type TraceStrategy string
+
+const (
+	// DepthFirst follows each flow to its end before the next one (default)
+	DepthFirst TraceStrategy = "depth_first"
+	// BreadthFirst explores every flow one step at a time, so the shortest
+	// paths are found first and survive node limits and deduplication
+	BreadthFirst TraceStrategy = "breadth_first"
+)
+
+// traceStrategy returns the configured strategy, defaulting to DepthFirst
+func (t *Tracer) traceStrategy() TraceStrategy {
+	if t.config.TraceStrategy == "" {
+		return DepthFirst
+	}
+	return t.config.TraceStrategy
+}
+
+// frontier is the FIFO queue of pending forward tracing steps
+type frontier struct {
+	steps []func()
+	head  int
+}
+
+// descend runs the next forward tracing step now (depth-first) or queues it
+// behind the steps already discovered (breadth-first)
+func (t *Tracer) descend(step func()) {
+	if t.frontier == nil {
+		step()
+		return
+	}
+	t.frontier.steps = append(t.frontier.steps, step)
+}
+
+// traceLevelOrder runs trace and, breadth-first, the steps it queues until
+// none are left. Flow tracing runs on a single worker, so one frontier per
+// tracer suffices.
+func (t *Tracer) traceLevelOrder(trace func()) {
+	if t.traceStrategy() != BreadthFirst {
+		trace()
+		return
+	}
+	t.frontier = &frontier{}
+	defer func() { t.frontier = nil }()
+	trace()
+	for f := t.frontier; f.head < len(f.steps); f.head++ {
+		step := f.steps[f.head]
+		f.steps[f.head] = nil
+		step()
+	}
+}
+
+// backwardTarget is a variable to resolve backward at a given depth
+type backwardTarget struct {
+	expr  string
+	scope string
+	file  string
+	depth int
+}
+
+// traceBackwardBreadthFirst resolves a variable level by level: all variables
+// one assignment away are searched before any two away, so the source found is
+// the nearest one rather than the first reached
+func (t *Tracer) traceBackwardBreadthFirst(ctx *TraceContext, varExpr string, scope string, startFile string, visited map[string]bool, depth int) []types.SourceInfo {
+	var sources []types.SourceInfo
+	queue := []backwardTarget{{expr: varExpr, scope: scope, file: startFile, depth: depth}}
+	for len(queue) > 0 {
+		target := queue[0]
+		queue = queue[1:]
+		if t.searchVar(ctx, target, visited, &sources, &queue) {
+			return sources
+		}
+	}
+	return sources
+}
+
+// searchVar searches for the assignments of target, current file first. Inner
+// variables are traced recursively, or appended to next when it is non-nil.
+// Returns true if a source was found (for early termination).
+func (t *Tracer) searchVar(ctx *TraceContext, target backwardTarget, visited map[string]bool, sources *[]types.SourceInfo, next *[]backwardTarget) bool {
+	if target.depth > t.config.MaxDepth {
+		t.warnDepthCutoff(target.file, 0, target.expr)
+		ctx.stopAt(types.TerminationDepthLimit)
+		ctx.budget.HitLimit(types.BudgetLimitDepth)
+		return false
+	}
+	ctx.budget.ReachDepth(target.depth)
+
+	varName := strings.TrimPrefix(strings.TrimSpace(target.expr), "$")
+	target.scope = t.variableScope(target.file, target.scope, varName)
+
+	// Prevent infinite loops
+	visitKey := fmt.Sprintf("%s:%s:%s", target.file, target.scope, target.expr)
+	if visited[visitKey] {
+		return false
+	}
+	visited[visitKey] = true
+	seen := ctx.assignmentsSeen
+
+	// OPTIMIZATION 1: Search current file FIRST (most common case)
+	if t.searchFileForVar(ctx, target.file, varName, target.scope, true, visited, target.depth, sources, next) {
+		return true
+	}
+
+	// Function locals are invisible outside their own function
+	if target.scope != "" {
+		if ctx.assignmentsSeen == seen {
+			ctx.stopAt(types.TerminationUnresolved) // A parameter or a variable set by reference
+		}
+		return false
+	}
+
+	// OPTIMIZATION 2: Only search other files if not found in current
+	// Use read lock, NO map copying
+	t.mu.RLock()
+	filePaths := make([]string, 0, len(t.files))
+	for fp, fileInfo := range t.files {
+		if fileInfo.Generated != GeneratedNone && t.generatedFileMode() == GeneratedSkip {
+			continue
+		}
+		if fp != target.file { // Skip already-searched file
+			filePaths = append(filePaths, fp)
+		}
+	}
+	t.mu.RUnlock()
+
+	// OPTIMIZATION 3: Search other files with early termination
+	for _, filePath := range filePaths {
+		if t.searchFileForVar(ctx, filePath, varName, target.scope, false, visited, target.depth, sources, next) {
+			return true
+		}
+	}
+	if ctx.assignmentsSeen == seen {
+		ctx.stopAt(types.TerminationUnresolved)
+	}
+	return false
+}
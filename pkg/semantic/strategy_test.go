@@ -0,0 +1,81 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestTraceStrategyForward(t *testing.T) {
+	dir := t.TempDir()
+	code := "<?php\n$a = $_GET['x'];\n$b = $a;\n$c = $b;\n$d = $a . $c;\n"
+	if err := os.WriteFile(filepath.Join(dir, "index.php"), []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// $d is reachable directly from $a and through $b and $c; the node is
+	// created once, with an edge from whichever variable reaches it first
+	tests := []struct {
+		strategy TraceStrategy
+		wantFrom string
+	}{
+		{DepthFirst, "$c"},
+		{BreadthFirst, "$a"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			config := DefaultConfig()
+			config.TraceStrategy = tt.strategy
+			result, err := New(config).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := make(map[string]string)
+			for _, node := range result.FlowMap.AllNodes {
+				names[node.ID] = node.Name
+			}
+			var from []string
+			for _, edge := range result.FlowMap.AllEdges {
+				if names[edge.To] == "$d" && edge.Type == types.EdgeAssignment {
+					from = append(from, names[edge.From])
+				}
+			}
+			if len(from) != 1 || from[0] != tt.wantFrom {
+				t.Errorf("$d assigned from %v, want [%s]", from, tt.wantFrom)
+			}
+		})
+	}
+}
+
+func TestTraceStrategyBackward(t *testing.T) {
+	dir := t.TempDir()
+	code := "<?php\n$z = $_POST['z'];\n$y = $z;\n$x = $y;\n$x = $_GET['x'];\n$t = $x;\n"
+	if err := os.WriteFile(filepath.Join(dir, "index.php"), []byte(code), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Depth-first stops at the first source reached through $y; breadth-first
+	// finds the direct assignment from $_GET first
+	tests := []struct {
+		strategy TraceStrategy
+		want     string
+	}{
+		{DepthFirst, "$_POST['z']"},
+		{BreadthFirst, "$_GET['x']"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			config := DefaultConfig()
+			config.TraceStrategy = tt.strategy
+			result, err := New(config).TraceBackward("$t", dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.Sources) != 1 || result.Sources[0].Expression != tt.want {
+				t.Errorf("sources = %+v, want %s", result.Sources, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package semantic
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// Finding is one input source with the flow traced from it. The Stream APIs
+// yield a Finding as soon as tracing of its source completes.
+type Finding struct {
+	Source *types.FlowNode
+	Nodes  []types.FlowNode // Nodes the source flows to
+	Edges  []types.FlowEdge // Edges between the source and those nodes
+}
+
+// StreamDirectory traces a directory like TraceDirectory but yields findings
+// on the returned channel while flows are traced. Sending blocks until the
+// consumer receives, so a slow consumer slows tracing down instead of
+// buffering findings. Cancelling ctx stops tracing of the remaining sources.
+// The error channel yields at most one error and is closed after findings.
+func (t *Tracer) StreamDirectory(ctx context.Context, path string) (<-chan Finding, <-chan error) {
+	findings := make(chan Finding)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(findings)
+
+		t.mu.Lock()
+		t.onSourceTraced = func(source *types.FlowNode, flowMap *types.FlowMap) bool {
+			// select picks at random when the consumer is also ready
+			if ctx.Err() != nil {
+				return false
+			}
+			finding := newFinding(source, flowMap)
+			finding.tagLayers(t, path)
+			if t.config.RedactSnippets {
+				finding.redact()
+			}
+			select {
+			case findings <- finding:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		t.mu.Unlock()
+		defer func() {
+			t.mu.Lock()
+			t.onSourceTraced = nil
+			t.mu.Unlock()
+		}()
+
+		_, err := t.TraceDirectory(path)
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			errc <- err
+		}
+	}()
+
+	return findings, errc
+}
+
+// StreamFile is the streaming variant of TraceFile
+func (t *Tracer) StreamFile(ctx context.Context, path string) (<-chan Finding, <-chan error) {
+	return t.StreamDirectory(ctx, filepath.Dir(path))
+}
+
+// emitFinding passes a traced source to the stream consumer, if any, and
+// reports whether tracing should continue
+func (t *Tracer) emitFinding(source *types.FlowNode, flowMap *types.FlowMap) bool {
+	t.mu.RLock()
+	emit := t.onSourceTraced
+	t.mu.RUnlock()
+	if emit == nil {
+		return true
+	}
+	return emit(source, flowMap)
+}
+
+// newFinding collects the part of flowMap reachable from source
+func newFinding(source *types.FlowNode, flowMap *types.FlowMap) Finding {
+	finding := Finding{Source: source}
+
+	adjacency := make(map[string][]int)
+	for i, edge := range flowMap.AllEdges {
+		adjacency[edge.From] = append(adjacency[edge.From], i)
+	}
+	reached := map[string]bool{source.ID: true}
+	queue := []string{source.ID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, i := range adjacency[id] {
+			edge := flowMap.AllEdges[i]
+			finding.Edges = append(finding.Edges, edge)
+			if !reached[edge.To] {
+				reached[edge.To] = true
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+
+	for _, node := range flowMap.AllNodes {
+		if node.ID != source.ID && reached[node.ID] {
+			node.Labels = mergeLabels(node.Labels, source.Labels)
+			finding.Nodes = append(finding.Nodes, node)
+		}
+	}
+	return finding
+}
+
+// tagLayers sets the layer of the finding's source and nodes; TraceDirectory
+// only tags the whole flow map once tracing completes
+func (f *Finding) tagLayers(t *Tracer, rootPath string) {
+	f.Source.Layer = t.layerOf(f.Source.FilePath, f.Source.ClassName, rootPath)
+	for i := range f.Nodes {
+		f.Nodes[i].Layer = t.layerOf(f.Nodes[i].FilePath, f.Nodes[i].ClassName, rootPath)
+	}
+}
@@ -0,0 +1,105 @@
+package semantic
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestStreamDirectory(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"single source", "<?php\n$id = $_GET['id'];\n$copy = $id;\n"},
+		{"many sources", "<?php\n$a = $_GET['a'];\n$b = $_POST['b'];\n$c = $_COOKIE['c'];\n$d = $a . $b;\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "index.php", tt.code)
+			want, err := New(nil).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			findings, errc := New(nil).StreamDirectory(context.Background(), dir)
+			var got []string
+			for finding := range findings {
+				got = append(got, finding.Source.Snippet)
+				if len(finding.Nodes) == 0 || len(finding.Edges) == 0 {
+					t.Errorf("finding for %s has no flow", finding.Source.Snippet)
+				}
+				for _, edge := range finding.Edges {
+					if edge.From == finding.Source.ID {
+						continue
+					}
+					found := false
+					for _, node := range finding.Nodes {
+						found = found || node.ID == edge.From
+					}
+					if !found {
+						t.Errorf("edge %s -> %s does not start in the finding", edge.From, edge.To)
+					}
+				}
+			}
+			if err := <-errc; err != nil {
+				t.Fatal(err)
+			}
+
+			var wantSnippets []string
+			for _, src := range want.Sources {
+				wantSnippets = append(wantSnippets, src.Snippet)
+			}
+			sort.Strings(got)
+			sort.Strings(wantSnippets)
+			if len(got) != len(wantSnippets) {
+				t.Fatalf("streamed %v, want %v", got, wantSnippets)
+			}
+			for i := range got {
+				if got[i] != wantSnippets[i] {
+					t.Fatalf("streamed %v, want %v", got, wantSnippets)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamDirectoryCancel(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", "<?php\n$a = $_GET['a'];\n$b = $_POST['b'];\n$c = $_COOKIE['c'];\n$d = $_GET['d'];\n")
+
+	tests := []struct {
+		name        string
+		cancelAfter int // Findings received before cancelling (-1 = before streaming)
+		maxReceived int
+	}{
+		{"cancelled before streaming", -1, 0},
+		// The finding traced while cancelling may still be delivered
+		{"cancelled after the first finding", 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelAfter < 0 {
+				cancel()
+			}
+			findings, errc := New(nil).StreamDirectory(ctx, dir)
+			received := 0
+			for range findings {
+				received++
+				if received == tt.cancelAfter {
+					cancel()
+				}
+			}
+			if received > tt.maxReceived {
+				t.Errorf("received %d findings, want at most %d", received, tt.maxReceived)
+			}
+			if err := <-errc; !errors.Is(err, context.Canceled) {
+				t.Errorf("err = %v, want %v", err, context.Canceled)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package semantic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPartialReadsStayTainted(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+$t = $_GET['name'];
+$prefix = substr($t, 0, 4);
+$ch = $t[0];
+$first = $_GET['id'][0];
+$inner = mb_substr(substr($t, 1), 0, 2);
+$whole = $t;
+$len = substr('fixed', 0, $n);
+`)
+
+	tracer := New(DefaultConfig())
+	if _, err := tracer.ParseOnly(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target    string
+		source    string
+		truncated bool
+	}{
+		{"$prefix", "$_GET['name']", true},
+		{"$ch", "$_GET['name']", true},
+		{"$first", "$_GET['id'][0]", true},
+		{"$inner", "$_GET['name']", true},
+		{"$whole", "$_GET['name']", false},
+		{"$len", "", false},
+	}
+	for _, tt := range tests {
+		result, err := tracer.TraceBackward(tt.target, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.source == "" {
+			if len(result.Sources) != 0 {
+				t.Errorf("%s: sources = %+v, want none", tt.target, result.Sources)
+			}
+			continue
+		}
+		if len(result.Paths) != 1 || result.Paths[0].Source.Expression != tt.source {
+			t.Errorf("%s: paths = %+v, want one from %s", tt.target, result.Paths, tt.source)
+			continue
+		}
+		if result.Paths[0].Truncated != tt.truncated {
+			t.Errorf("%s: truncated = %v, want %v", tt.target, result.Paths[0].Truncated, tt.truncated)
+		}
+	}
+}
+
+func TestPartialReadEdges(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+$t = $_GET['name'];
+$prefix = substr($t, 0, 4);
+`)
+
+	result, err := New(DefaultConfig()).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, edge := range result.FlowMap.AllEdges {
+		if strings.Contains(edge.Description, "truncated (substr())") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no edge notes the substr() truncation: %+v", result.FlowMap.AllEdges)
+	}
+}
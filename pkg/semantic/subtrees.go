@@ -0,0 +1,134 @@
+package semantic
+
+import (
+	"fmt"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+)
+
+// SubtreeRule tunes source discovery for part of a monorepo: the files it
+// matches are analyzed with its rule packs applied on top of the run's rules
+// and with its framework versions, so apps on different frameworks or
+// conventions each get their own patterns and decoding, sanitizing and
+// validating functions in one scan. The first matching rule wins.
+type SubtreeRule struct {
+	Name              string              `json:"name,omitempty"`               // Shown in verbose output; defaults to the files
+	Files             []string            `json:"files"`                        // File globs relative to the scanned directory (** allowed)
+	RulePacks         []string            `json:"rule_packs,omitempty"`         // Rule pack files, as in Config.RulePackFiles
+	FrameworkVersions map[string]string   `json:"framework_versions,omitempty"` // Override detected versions, as in Config.FrameworkVersions
+	Packs             []*sources.RulePack `json:"-"`                            // Applied before RulePacks, for rules built in code
+}
+
+// validate checks that the rule has files, valid globs and something to apply
+func (r SubtreeRule) validate() error {
+	if len(r.Files) == 0 {
+		return fmt.Errorf("files is required")
+	}
+	if len(r.RulePacks) == 0 && len(r.Packs) == 0 && len(r.FrameworkVersions) == 0 {
+		return fmt.Errorf("rule_packs or framework_versions is required")
+	}
+	for _, pattern := range r.Files {
+		if _, err := doubleStarMatch(pattern, ""); err != nil {
+			return fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// label names the subtree in verbose output
+func (r SubtreeRule) label() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprint(r.Files)
+}
+
+// matchesFile reports whether the file rel (relative to the scanned
+// directory) is within the subtree
+func (r SubtreeRule) matchesFile(rel string) bool {
+	for _, pattern := range r.Files {
+		if ok, _ := doubleStarMatch(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// subtreeRules is the rules the files of one subtree are analyzed with: the
+// run's rules with the subtree's packs and framework versions applied, and the
+// analyzers matching their framework patterns
+type subtreeRules struct {
+	rule      *SubtreeRule
+	packs     []*sources.RulePack
+	ruleSet   *sources.RuleSet
+	analyzers *analyzer.Registry
+}
+
+// loadSubtreePacks reads the rule pack files of every subtree rule, so that
+// invalid packs fail the run before parsing, and builds the subtrees' rules
+func (t *Tracer) loadSubtreePacks() error {
+	t.subtrees = nil
+	if t.rules == nil {
+		return nil
+	}
+	for i := range t.rules.Subtrees {
+		rule := &t.rules.Subtrees[i]
+		packs := append([]*sources.RulePack(nil), rule.Packs...)
+		for _, pack := range rule.Packs {
+			if err := pack.Validate(); err != nil {
+				return fmt.Errorf("subtrees[%d]: rule pack %s: %w", i, pack.Name, err)
+			}
+		}
+		for _, path := range rule.RulePacks {
+			pack, err := sources.LoadRulePack(path)
+			if err != nil {
+				return fmt.Errorf("subtrees[%d]: %w", i, err)
+			}
+			packs = append(packs, pack)
+		}
+		t.subtrees = append(t.subtrees, &subtreeRules{rule: rule, packs: packs})
+		if t.config.Verbose {
+			fmt.Printf("  Subtree %s: %d rule packs\n", rule.label(), len(packs))
+		}
+	}
+	t.buildSubtreeRules()
+	return nil
+}
+
+// buildSubtreeRules applies the packs and framework versions of every
+// subtree on top of the run's rules; it runs again once the run's framework
+// versions are known (see applyFrameworkVersions)
+func (t *Tracer) buildSubtreeRules() {
+	for _, subtree := range t.subtrees {
+		rules := t.ruleSet.Clone()
+		for _, pack := range subtree.packs {
+			_ = rules.Apply(pack) // Validated when loaded
+		}
+		if len(subtree.rule.FrameworkVersions) > 0 {
+			versions := make(map[string]string, len(t.frameworkVersions)+len(subtree.rule.FrameworkVersions))
+			for framework, version := range t.frameworkVersions {
+				versions[framework] = version
+			}
+			for framework, version := range subtree.rule.FrameworkVersions {
+				versions[framework] = version
+			}
+			rules.SetFrameworkVersions(versions)
+		}
+		subtree.ruleSet, subtree.analyzers = rules, analyzersFor(rules)
+	}
+}
+
+// subtreeOf returns the rules of the subtree a file is in, or nil
+func (t *Tracer) subtreeOf(path string) *subtreeRules {
+	if len(t.subtrees) == 0 {
+		return nil
+	}
+	rel := relPath(t.queryRoot, path)
+	for _, subtree := range t.subtrees {
+		if subtree.rule.matchesFile(rel) {
+			return subtree
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+func TestSubtreeRulePacks(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"apps/legacy", "apps/api", "shared"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, dir, "apps/legacy/LegacyInput.php", `<?php
+class LegacyInput {
+    public $vars;
+    public function __construct() { $this->vars = $_REQUEST; }
+}
+`)
+	writeFile(t, dir, "apps/api/ApiInput.php", `<?php
+class ApiInput {
+    public $vars;
+    public function __construct() { $this->vars = $_REQUEST; }
+}
+`)
+	writeFile(t, dir, "shared/SharedInput.php", `<?php
+class SharedInput {
+    public $vars;
+    public function __construct() { $this->vars = $_REQUEST; }
+}
+`)
+
+	legacy := &sources.RulePack{
+		Format: sources.RulePackFormat,
+		Name:   "legacy",
+		FrameworkPatterns: []*common.FrameworkPattern{{
+			ID:              "subtree_test_input_carrier",
+			Framework:       "legacy",
+			Language:        "php",
+			Name:            "Legacy input carrier",
+			ClassPattern:    "Input$",
+			CarrierProperty: "vars",
+			SourceType:      common.SourceHTTPRequest,
+		}},
+		Functions: map[string]*sources.FunctionRuleSet{
+			"php": {Decoding: []string{"legacy_unescape"}},
+		},
+	}
+	config := DefaultConfig()
+	config.Rules = &Rules{Subtrees: []SubtreeRule{
+		{Name: "legacy", Files: []string{"apps/legacy/**"}, Packs: []*sources.RulePack{legacy}},
+		{Name: "api", Files: []string{"apps/api/**"}, FrameworkVersions: map[string]string{"laravel": "11.0"}},
+	}}
+	tracer := New(config)
+	if _, err := tracer.TraceDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for class, carrier := range map[string]bool{"LegacyInput": true, "ApiInput": false, "SharedInput": false} {
+		def := tracer.symbolTable.Classes[class]
+		if def == nil {
+			t.Fatalf("class %s not found", class)
+		}
+		if def.IsCarrier != carrier {
+			t.Errorf("%s carrier = %v, want %v", class, def.IsCarrier, carrier)
+		}
+	}
+
+	legacyFile := filepath.Join(dir, "apps/legacy/LegacyInput.php")
+	apiFile := filepath.Join(dir, "apps/api/ApiInput.php")
+	sharedFile := filepath.Join(dir, "shared/SharedInput.php")
+	if !tracer.rulesFor(legacyFile).IsDecodingFunction("legacy_unescape") {
+		t.Error("subtree pack function lists not applied to the subtree's files")
+	}
+	if tracer.rulesFor(sharedFile).IsDecodingFunction("legacy_unescape") {
+		t.Error("subtree pack function lists applied outside the subtree")
+	}
+	if v := tracer.rulesFor(apiFile).FrameworkVersion("laravel"); v != "11.0" {
+		t.Errorf("subtree laravel version = %q, want 11.0", v)
+	}
+	if tracer.rulesFor(sharedFile).FrameworkVersion("laravel") == "11.0" {
+		t.Error("subtree framework version applied outside the subtree")
+	}
+
+	if php.Registry.GetByID("subtree_test_input_carrier") != nil {
+		t.Error("subtree pack pattern changed the built-in rules")
+	}
+	if php.IsDecodingFunction("legacy_unescape") || !sources.NewRuleSet().IsInputMethodCall("$request->intersect(['a'])") {
+		t.Error("subtree rules changed the built-in rules")
+	}
+
+	bad := &Rules{Subtrees: []SubtreeRule{{Files: []string{"apps/**"}}}}
+	if err := bad.Validate(); err == nil {
+		t.Error("expected an error for a subtree without packs or versions")
+	}
+	missing := New(&Config{Rules: &Rules{Subtrees: []SubtreeRule{
+		{Files: []string{"apps/**"}, RulePacks: []string{filepath.Join(dir, "missing.json")}},
+	}}})
+	if err := missing.loadRules(); err == nil {
+		t.Error("expected an error for a missing subtree rule pack")
+	}
+}
@@ -0,0 +1,26 @@
+package symbolic
+
+import "regexp"
+
+// touchFile records a registered file read by the current trace in its
+// budget: a cache hit when the cache holds the file, else a parse when the
+// trace needs its AST (parse)
+func (e *ExecutionEngine) touchFile(filePath string, parse bool) {
+	if e.budget == nil {
+		return
+	}
+	e.budget.TouchFile(filePath)
+	switch {
+	case e.fileCache.Has(filePath):
+		e.budget.CountCacheHit()
+	case parse:
+		e.budget.CountParse()
+	}
+}
+
+// match returns re, counting one evaluation in the budget of the current
+// trace; evaluations read e.match(re).FindStringSubmatch(s)
+func (e *ExecutionEngine) match(re *regexp.Regexp) *regexp.Regexp {
+	e.budget.CountRegex(1)
+	return re
+}
@@ -0,0 +1,104 @@
+package symbolic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestPropertyFlowBudget(t *testing.T) {
+	e := NewExecutionEngine()
+	addPHPClassFile(t, e, "/app/Request.php", `<?php
+class Request {
+    public $input = array();
+    function __construct() { $this->input = $_GET; }
+}
+`)
+	addPHPFile(t, e, "/app/index.php", "<?php\n$request = new Request();\n")
+
+	flow, err := e.TracePropertyAccess("$request->input['id']", "/app/index.php")
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget := flow.Budget
+	if budget == nil || budget.FilesTouched == 0 || budget.RegexEvaluations == 0 || budget.DepthReached == 0 || budget.WallTime <= 0 {
+		t.Fatalf("budget = %+v, want files, pattern matches, depth and wall time", budget)
+	}
+	if budget.LimitHit() {
+		t.Errorf("limits = %v, want none", budget.LimitsHit)
+	}
+
+	e.maxDepth = 0
+	flow, _ = e.TracePropertyAccess("$request->input['id']", "/app/index.php")
+	if flow == nil || len(flow.Budget.LimitsHit) != 1 || flow.Budget.LimitsHit[0] != types.BudgetLimitDepth {
+		t.Errorf("budget at depth 0 = %+v, want the depth limit hit", flow)
+	}
+}
+
+func TestSetYield(t *testing.T) {
+	e := NewExecutionEngine()
+	addPHPClassFile(t, e, "/app/Request.php", `<?php
+class Request {
+    public $input = array();
+    function __construct() { $this->input = $_GET; }
+}
+`)
+	addPHPFile(t, e, "/app/index.php", "<?php\n$request = new Request();\n")
+
+	yields := 0
+	e.SetYield(1, func() { yields++ })
+	if _, err := e.TracePropertyAccess("$request->input['id']", "/app/index.php"); err != nil {
+		t.Fatal(err)
+	}
+	if yields == 0 {
+		t.Error("expected yields while tracing")
+	}
+
+	yields = 0
+	e.SetYield(0, func() { yields++ })
+	e.TracePropertyAccess("$request->input['id']", "/app/index.php")
+	if yields != 0 {
+		t.Errorf("yields = %d after SetYield(0), want none", yields)
+	}
+}
+
+func TestDepthCutoffWarnings(t *testing.T) {
+	e := NewExecutionEngine()
+	addPHPClassFile(t, e, "/app/Request.php", `<?php
+class Request {
+    public $input = array();
+    function __construct() { $this->input = $_GET; }
+}
+`)
+	addPHPFile(t, e, "/app/index.php", "<?php\n$request = new Request();\n$copy = clone $request;\n")
+
+	tests := []struct {
+		name       string
+		expression string
+		maxDepth   int
+		cutoffs    int
+	}{
+		{"method past the depth", "$request->input['id']", 0, 1},
+		{"within the depth", "$request->input['id']", 10, 0},
+		{"copy past the depth", "$copy->input['id']", 0, 1},
+		{"copy within the depth", "$copy->input['id']", 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e.maxDepth = tt.maxDepth
+			flow, _ := e.TracePropertyAccess(tt.expression, "/app/index.php")
+			if flow == nil {
+				t.Fatal("no flow")
+			}
+			cutoffs := 0
+			for _, w := range flow.Warnings {
+				if w.Category == types.WarningDepthCutoff {
+					cutoffs++
+				}
+			}
+			if cutoffs != tt.cutoffs {
+				t.Errorf("depth_cutoff warnings = %d, want %d: %+v", cutoffs, tt.cutoffs, flow.Warnings)
+			}
+		})
+	}
+}
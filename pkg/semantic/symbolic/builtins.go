@@ -0,0 +1,127 @@
+package symbolic
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/patterns"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+// builtinCallPattern matches the function name of a call expression
+var builtinCallPattern = regexp.MustCompile(`^\\?([A-Za-z_]\w*)\s*\(`)
+
+// carriedValue returns the part of an assigned value whose input reaches the
+// variable: for a call to a modeled array builtin, only the arguments carried
+// into its result (not the column of array_column, say); for a substring
+// call, the string it reads; otherwise the value
+func (e *ExecutionEngine) carriedValue(value string) string {
+	if _, arg, ok := phpPatterns.SubstringCall(value); ok {
+		return arg
+	}
+	m := e.match(builtinCallPattern).FindStringSubmatch(value)
+	if m == nil {
+		return value
+	}
+	builtin, ok := phpPatterns.LookupArrayBuiltin(m[1])
+	if !ok {
+		return value
+	}
+	args, end := e.callArguments(value, len(m[0])-1)
+	if end != len(value)-1 {
+		return value // The call is only part of the value
+	}
+
+	var carried []string
+	for i, arg := range args {
+		if keys, values := builtin.ResultParts(i); keys || values {
+			carried = append(carried, arg)
+		}
+	}
+	return strings.Join(carried, ", ")
+}
+
+// callArguments returns the arguments of the call whose "(" is at open and
+// the index of its closing ")", or -1 when it is not closed
+func (e *ExecutionEngine) callArguments(expr string, open int) ([]string, int) {
+	depth := 0
+	var quote byte
+	for i := open; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == quote && expr[i-1] != '\\' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return e.parseArguments(expr[open+1 : i]), i
+			}
+		}
+	}
+	return nil, -1
+}
+
+// findBuiltinWrites returns the values modeled array builtins write into a
+// variable passed by reference, e.g. the replacement of
+// array_splice($varName, 0, 1, $replacement), as assignments
+func (e *ExecutionEngine) findBuiltinWrites(varName string, contextFile string, scope string, scoped bool) []variableAssignment {
+	var writes []variableAssignment
+	varName = strings.TrimPrefix(varName, "$")
+	callPattern := patterns.BuildFirstArgCallPattern(varName)
+
+	for _, file := range e.files {
+		if scoped && scope != "" && file != contextFile {
+			continue
+		}
+		content, ok := e.fileContent(file)
+		if !ok {
+			continue
+		}
+		if !bytes.Contains(content, []byte("$"+varName)) {
+			continue
+		}
+		for _, stmt := range e.fileStatements(file) {
+			line := stmt.text
+			for _, loc := range e.match(callPattern).FindAllStringSubmatchIndex(line, -1) {
+				builtin, ok := phpPatterns.LookupArrayBuiltin(line[loc[2]:loc[3]])
+				if !ok || builtin.RefArg != 0 {
+					continue
+				}
+				args, end := e.callArguments(line, strings.Index(line[loc[0]:], "(")+loc[0])
+				if end < 0 {
+					continue
+				}
+				var written []string
+				for i, arg := range args {
+					if builtin.WritesRef(i) {
+						written = append(written, arg)
+					}
+				}
+				if len(written) == 0 {
+					continue
+				}
+				if scoped {
+					writeScope, _ := e.scopeAt(file, stmt.line, varName)
+					if !types.SameVariableScope(writeScope, scope, file == contextFile) {
+						continue
+					}
+				}
+				writes = append(writes, variableAssignment{
+					source: strings.Join(written, ", "),
+					file:   file,
+					line:   stmt.line,
+				})
+			}
+		}
+	}
+
+	return writes
+}
@@ -0,0 +1,32 @@
+package symbolic
+
+import "testing"
+
+func TestCarriedValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"array_column($rows, $_GET['col'])", "$rows"},
+		{"array_combine($_GET['k'], $vals)", "$_GET['k'], $vals"},
+		{"array_column($rows, 'id') . $_GET['x']", "array_column($rows, 'id') . $_GET['x']"},
+		{"trim($_GET['x'])", "trim($_GET['x'])"},
+	}
+
+	e := NewExecutionEngine()
+	for _, tt := range tests {
+		if got := e.carriedValue(tt.value); got != tt.want {
+			t.Errorf("carriedValue(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFindBuiltinWrites(t *testing.T) {
+	e := NewExecutionEngine()
+	addPHPFile(t, e, "/app/index.php", "<?php\n$arr = [1, 2];\narray_splice($arr, 0, 1, $_POST['x']);\nsort($arr);\n")
+
+	writes := e.findBuiltinWrites("$arr", "/app/index.php", "", false)
+	if len(writes) != 1 || writes[0].source != "$_POST['x']" || writes[0].line != 3 {
+		t.Errorf("writes = %+v, want $_POST['x'] at line 3", writes)
+	}
+}
@@ -0,0 +1,116 @@
+package symbolic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// CandidateClass is a class an object may be an instance of when its
+// instantiation was not found: one declaring the property or method the
+// expression reads
+type CandidateClass struct {
+	ClassName string
+	FilePath  string
+	Line      int
+	Member    string // Property ("input") or method ("get_input()") the class declares
+	NameMatch bool   // The class is named like the variable, e.g. MyBB for $mybb
+}
+
+// instantiationNotFound returns the partial flow of an expression whose
+// object was never instantiated: the parsed member, a not_found step and the
+// classes declaring that member, along with the error
+func (e *ExecutionEngine) instantiationNotFound(parsed *ParsedExpression, flow *PropertyFlow) (*PropertyFlow, error) {
+	flow.PropertyName = parsed.PropertyName
+	flow.MethodName = parsed.MethodName
+	flow.AccessKey = parsed.AccessKey
+	flow.Candidates = e.candidateClasses(parsed)
+
+	description := fmt.Sprintf("Instantiation of %s not found", parsed.VarName)
+	if len(flow.Candidates) > 0 {
+		names := make([]string, len(flow.Candidates))
+		for i, c := range flow.Candidates {
+			names[i] = c.ClassName
+		}
+		description += fmt.Sprintf("; candidate classes: %s", strings.Join(names, ", "))
+	}
+	flow.Steps = append(flow.Steps, FlowStep{
+		StepNumber:  len(flow.Steps) + 1,
+		Description: description,
+		Code:        parsed.RawExpr,
+		Type:        "not_found",
+	})
+
+	return flow, &TraceError{
+		Kind:       ErrInstantiationNotFound,
+		Expression: parsed.RawExpr,
+		Message:    fmt.Sprintf("could not find instantiation of variable %s (searched %d files)", parsed.VarName, len(e.files)),
+	}
+}
+
+// candidateClasses returns the classes of all symbol tables declaring the
+// first member the expression reads, those named like its variable first
+func (e *ExecutionEngine) candidateClasses(parsed *ParsedExpression) []CandidateClass {
+	memberType, member := parsed.Type, parsed.PropertyName
+	if parsed.Type == ExprTypeMethodCall {
+		member = parsed.MethodName
+	}
+	if parsed.IsChained && len(parsed.ChainSteps) > 0 {
+		memberType, member = parsed.ChainSteps[0].Type, parsed.ChainSteps[0].Name
+	}
+	if member == "" {
+		return nil
+	}
+
+	varName := strings.TrimPrefix(parsed.VarName, "$")
+	var candidates []CandidateClass
+	for filePath, st := range e.symbolTables {
+		for _, class := range st.Classes {
+			declared := declaresMember(class, memberType, member)
+			if declared == "" {
+				continue
+			}
+			classFile := class.FilePath
+			if classFile == "" {
+				classFile = filePath
+			}
+			candidates = append(candidates, CandidateClass{
+				ClassName: class.Name,
+				FilePath:  classFile,
+				Line:      class.Line,
+				Member:    declared,
+				NameMatch: strings.EqualFold(strings.ReplaceAll(class.Name, "_", ""), strings.ReplaceAll(varName, "_", "")),
+			})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.NameMatch != b.NameMatch {
+			return a.NameMatch
+		}
+		if a.ClassName != b.ClassName {
+			return a.ClassName < b.ClassName
+		}
+		return a.FilePath < b.FilePath
+	})
+	return candidates
+}
+
+// declaresMember returns how a class declares a property or method, or ""
+// when it does not; method names are case-insensitive
+func declaresMember(class *types.ClassDef, memberType ExpressionType, member string) string {
+	if memberType == ExprTypeMethodCall {
+		for name := range class.Methods {
+			if strings.EqualFold(name, member) {
+				return name + "()"
+			}
+		}
+		return ""
+	}
+	if _, ok := class.Properties[member]; ok {
+		return member
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package symbolic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInstantiationNotFoundPartialFlow(t *testing.T) {
+	e := NewExecutionEngine()
+	addPHPClassFile(t, e, "/app/Request.php", `<?php
+class Request {
+    public $input = array();
+    function get_input($name) { return $this->input[$name]; }
+}
+`)
+	addPHPClassFile(t, e, "/app/MyBB.php", `<?php
+class MyBB {
+    public $input = array();
+}
+`)
+	addPHPClassFile(t, e, "/app/Template.php", `<?php
+class Template {
+    public $cache = array();
+}
+`)
+	addPHPFile(t, e, "/app/index.php", "<?php\necho $mybb->input['id'];\n")
+
+	flow, err := e.TracePropertyAccess("$mybb->input['id']", "/app/index.php")
+	if !errors.Is(err, ErrInstantiationNotFound) {
+		t.Fatalf("err = %v, want ErrInstantiationNotFound", err)
+	}
+	if flow == nil {
+		t.Fatal("expected a partial flow")
+	}
+	if flow.PropertyName != "input" || flow.AccessKey != "id" {
+		t.Errorf("flow member = %s[%s], want input[id]", flow.PropertyName, flow.AccessKey)
+	}
+	if len(flow.Steps) != 1 || flow.Steps[0].Type != "not_found" {
+		t.Fatalf("steps = %+v, want one not_found step", flow.Steps)
+	}
+	if len(flow.Candidates) != 2 || flow.Candidates[0].ClassName != "MyBB" || !flow.Candidates[0].NameMatch || flow.Candidates[1].ClassName != "Request" {
+		t.Errorf("candidates = %+v, want MyBB (named like $mybb) then Request", flow.Candidates)
+	}
+	if flow.Candidates[0].FilePath != "/app/MyBB.php" || flow.Candidates[0].Member != "input" {
+		t.Errorf("MyBB candidate = %+v", flow.Candidates[0])
+	}
+
+	flow, err = e.TracePropertyAccess("$req->GET_INPUT('id')", "/app/index.php")
+	if !errors.Is(err, ErrInstantiationNotFound) || flow == nil {
+		t.Fatalf("method call: flow = %v, err = %v", flow, err)
+	}
+	if len(flow.Candidates) != 1 || flow.Candidates[0].ClassName != "Request" || flow.Candidates[0].Member != "get_input()" {
+		t.Errorf("method call candidates = %+v, want Request::get_input()", flow.Candidates)
+	}
+}
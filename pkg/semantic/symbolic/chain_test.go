@@ -0,0 +1,52 @@
+package symbolic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestTraceChainedExpression(t *testing.T) {
+	tests := []struct {
+		name          string
+		expression    string
+		maxChain      int
+		wantSource    bool
+		wantTruncated bool
+	}{
+		{"fluent call repeated", "$q->where('a')->where('b')->get()", DefaultMaxChainLength, true, false},
+		{"same method three times", "$q->where('a')->where('b')->where('c')->get()", DefaultMaxChainLength, true, false},
+		{"past the chain length limit", "$q->where('a')->where('b')->get()", 2, false, true},
+		{"unlimited chain length", "$q->where('a')->where('b')->get()", 0, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutionEngine()
+			e.SetMaxChainLength(tt.maxChain)
+			addPHPClassFile(t, e, "/app/Query.php", `<?php
+class Query {
+    public $params = array();
+    function __construct() { $this->params = $_GET; }
+    function where($column) { return $this; }
+    function get() { return $this->params; }
+}
+`)
+			addPHPFile(t, e, "/app/index.php", "<?php\n$q = new Query();\n")
+
+			flow, err := e.TracePropertyAccess(tt.expression, "/app/index.php")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(flow.Sources) > 0; got != tt.wantSource {
+				t.Errorf("sources = %+v, want found = %v", flow.Sources, tt.wantSource)
+			}
+			truncated := false
+			for _, w := range flow.Warnings {
+				truncated = truncated || w.Category == types.WarningChainTruncated
+			}
+			if truncated != tt.wantTruncated {
+				t.Errorf("chain truncated = %v, want %v (steps %+v)", truncated, tt.wantTruncated, flow.Steps)
+			}
+		})
+	}
+}
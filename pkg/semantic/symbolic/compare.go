@@ -0,0 +1,121 @@
+package symbolic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FlowDiff is the structured difference between the traces of two
+// expressions, e.g. why $mybb->input['a'] reaches a source but
+// $mybb->input['b'] does not
+type FlowDiff struct {
+	ExpressionA string
+	ExpressionB string
+
+	// Complete traces; nil when the trace failed (see ErrorA/ErrorB)
+	FlowA  *PropertyFlow
+	FlowB  *PropertyFlow
+	ErrorA error
+	ErrorB error
+
+	// SharedSteps are the leading steps both traces take. Steps are compared by
+	// type, location and code, with each trace's own access key ignored.
+	SharedSteps []FlowStep
+
+	// DivergesAt is the index of the first differing step, or -1 when both
+	// traces take the same steps
+	DivergesAt int
+
+	// Steps after the divergence point, per trace
+	OnlyStepsA []FlowStep
+	OnlyStepsB []FlowStep
+
+	// Ultimate sources reached by both traces and by only one of them
+	SharedSources []UltimateSource
+	OnlySourcesA  []UltimateSource
+	OnlySourcesB  []UltimateSource
+}
+
+// Identical reports whether both traces take the same steps to the same sources
+func (d *FlowDiff) Identical() bool {
+	return d.DivergesAt < 0 && len(d.OnlySourcesA) == 0 && len(d.OnlySourcesB) == 0
+}
+
+// CompareFlows traces two expressions in the context of the same file and
+// diffs their steps and ultimate sources. A failed trace is recorded in the
+// diff with the partial flow it returned, if any; an error is returned only
+// when both traces fail.
+func (e *ExecutionEngine) CompareFlows(exprA, exprB string, contextFile string) (*FlowDiff, error) {
+	flowA, errA := e.TracePropertyAccess(exprA, contextFile)
+	flowB, errB := e.TracePropertyAccess(exprB, contextFile)
+	if errA != nil && errB != nil {
+		return nil, fmt.Errorf("failed to trace %s and %s: %w", exprA, exprB, errors.Join(errA, errB))
+	}
+
+	diff := diffFlows(flowA, flowB)
+	diff.ExpressionA, diff.ExpressionB = exprA, exprB
+	diff.ErrorA, diff.ErrorB = errA, errB
+	return diff, nil
+}
+
+// diffFlows compares two traces; either may be nil
+func diffFlows(a, b *PropertyFlow) *FlowDiff {
+	diff := &FlowDiff{FlowA: a, FlowB: b, DivergesAt: -1}
+	var stepsA, stepsB []FlowStep
+	var sourcesA, sourcesB []UltimateSource
+	if a != nil {
+		stepsA, sourcesA = a.Steps, a.Sources
+	}
+	if b != nil {
+		stepsB, sourcesB = b.Steps, b.Sources
+	}
+
+	shared := 0
+	for shared < len(stepsA) && shared < len(stepsB) &&
+		stepKey(stepsA[shared], a) == stepKey(stepsB[shared], b) {
+		shared++
+	}
+	diff.SharedSteps = stepsA[:shared]
+	if shared < len(stepsA) || shared < len(stepsB) {
+		diff.DivergesAt = shared
+		diff.OnlyStepsA = stepsA[shared:]
+		diff.OnlyStepsB = stepsB[shared:]
+	}
+
+	inB := make(map[string]bool, len(sourcesB))
+	for _, src := range sourcesB {
+		inB[sourceKey(src)] = true
+	}
+	inA := make(map[string]bool, len(sourcesA))
+	for _, src := range sourcesA {
+		inA[sourceKey(src)] = true
+		if inB[sourceKey(src)] {
+			diff.SharedSources = append(diff.SharedSources, src)
+		} else {
+			diff.OnlySourcesA = append(diff.OnlySourcesA, src)
+		}
+	}
+	for _, src := range sourcesB {
+		if !inA[sourceKey(src)] {
+			diff.OnlySourcesB = append(diff.OnlySourcesB, src)
+		}
+	}
+	return diff
+}
+
+// stepKey identifies a step for comparison. Quoted occurrences of the traced
+// expression's access key are masked so that input['a'] and input['b'] share
+// the steps they take through the same code.
+func stepKey(step FlowStep, flow *PropertyFlow) string {
+	code := step.CodeText()
+	if flow != nil && flow.AccessKey != "" {
+		code = strings.NewReplacer("'"+flow.AccessKey+"'", "'*'", `"`+flow.AccessKey+`"`, "'*'").Replace(code)
+	}
+	return fmt.Sprintf("%s|%s|%d|%s|%s", step.Type, step.FilePath, step.Line, step.Subclass, code)
+}
+
+// sourceKey identifies an ultimate source for comparison
+func sourceKey(src UltimateSource) string {
+	return fmt.Sprintf("%s|%s|%s|%d", src.Type, src.Expression, src.FilePath, src.Line)
+}
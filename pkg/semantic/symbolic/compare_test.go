@@ -0,0 +1,84 @@
+package symbolic
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiffFlows(t *testing.T) {
+	initStep := FlowStep{Type: "property_init", FilePath: "initStep.php", Line: 3, Code: "$this->input = $_GET"}
+	readA := FlowStep{Type: "property_access", FilePath: "app.php", Line: 9, Code: "$mybb->input['a']"}
+	readB := FlowStep{Type: "property_access", FilePath: "app.php", Line: 9, Code: "$mybb->input['b']"}
+	filter := FlowStep{Type: "method_call", FilePath: "initStep.php", Line: 12, Code: "$this->clean_input()"}
+	srcA := UltimateSource{Type: "http_get", Expression: "$_GET['a']", FilePath: "initStep.php", Line: 3}
+	srcB := UltimateSource{Type: "http_get", Expression: "$_GET['b']", FilePath: "initStep.php", Line: 3}
+	cookie := UltimateSource{Type: "http_cookie", Expression: "$_COOKIE", FilePath: "initStep.php", Line: 4}
+
+	tests := []struct {
+		name          string
+		a, b          *PropertyFlow
+		divergesAt    int
+		shared        int
+		onlyA, onlyB  int
+		sharedSources int
+		srcOnlyA      int
+		srcOnlyB      int
+	}{
+		{
+			name:          "same path, different keys",
+			a:             &PropertyFlow{AccessKey: "a", Steps: []FlowStep{initStep, readA}, Sources: []UltimateSource{srcA, cookie}},
+			b:             &PropertyFlow{AccessKey: "b", Steps: []FlowStep{initStep, readB}, Sources: []UltimateSource{srcB, cookie}},
+			divergesAt:    -1,
+			shared:        2,
+			sharedSources: 1,
+			srcOnlyA:      1,
+			srcOnlyB:      1,
+		},
+		{
+			name:       "b takes an extra step",
+			a:          &PropertyFlow{AccessKey: "a", Steps: []FlowStep{initStep, readA}, Sources: []UltimateSource{srcA}},
+			b:          &PropertyFlow{AccessKey: "b", Steps: []FlowStep{initStep, filter, readB}},
+			divergesAt: 1,
+			shared:     1,
+			onlyA:      1,
+			onlyB:      2,
+			srcOnlyA:   1,
+		},
+		{
+			name:       "b failed to trace",
+			a:          &PropertyFlow{AccessKey: "a", Steps: []FlowStep{initStep}, Sources: []UltimateSource{srcA}},
+			divergesAt: 0,
+			onlyA:      1,
+			srcOnlyA:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := diffFlows(tt.a, tt.b)
+			if d.DivergesAt != tt.divergesAt {
+				t.Errorf("DivergesAt = %d, want %d", d.DivergesAt, tt.divergesAt)
+			}
+			if len(d.SharedSteps) != tt.shared || len(d.OnlyStepsA) != tt.onlyA || len(d.OnlyStepsB) != tt.onlyB {
+				t.Errorf("steps shared/onlyA/onlyB = %d/%d/%d, want %d/%d/%d",
+					len(d.SharedSteps), len(d.OnlyStepsA), len(d.OnlyStepsB), tt.shared, tt.onlyA, tt.onlyB)
+			}
+			if len(d.SharedSources) != tt.sharedSources || len(d.OnlySourcesA) != tt.srcOnlyA || len(d.OnlySourcesB) != tt.srcOnlyB {
+				t.Errorf("sources shared/onlyA/onlyB = %d/%d/%d, want %d/%d/%d",
+					len(d.SharedSources), len(d.OnlySourcesA), len(d.OnlySourcesB), tt.sharedSources, tt.srcOnlyA, tt.srcOnlyB)
+			}
+		})
+	}
+}
+
+func TestCompareFlows_BothFail(t *testing.T) {
+	e := NewExecutionEngine()
+	_, err := e.CompareFlows("$a->input['x']", "$b->input['y']", "missing.php")
+	if err == nil {
+		t.Fatal("expected an error when neither expression can be traced")
+	}
+	joined, ok := errors.Unwrap(err).(interface{ Unwrap() []error })
+	if !ok || len(joined.Unwrap()) != 2 {
+		t.Errorf("error %v does not wrap both trace errors", err)
+	}
+}
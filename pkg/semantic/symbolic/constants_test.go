@@ -0,0 +1,51 @@
+package symbolic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestFoldConstantKeys(t *testing.T) {
+	e := NewExecutionEngine()
+	addPHPClassFile(t, e, "/app/a.php", `<?php
+define('PARAM', 'id');
+const PREFIX = 'p_';
+class MyBB { const KEY = 'uid'; }
+`)
+	addPHPClassFile(t, e, "/app/b.php", `<?php
+define('PARAM', 'other');
+`)
+
+	tests := []struct {
+		name, expr, want string
+	}{
+		{"global constant", "$_GET[PARAM]", "$_GET['id']"},
+		{"class constant", "$mybb->input[MyBB::KEY]", "$mybb->input['uid']"},
+		{"concatenation", "$_POST[PREFIX . 'name']", "$_POST['p_name']"},
+		{"nested subscripts", "$_GET[PARAM][MyBB::KEY]", "$_GET['id']['uid']"},
+		{"literal key", "$_GET['id']", "$_GET['id']"},
+		{"unknown constant", "$_GET[UNKNOWN]", "$_GET[UNKNOWN]"},
+		{"no subscript", "$request", "$request"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.foldConstantKeys(tt.expr); got != tt.want {
+				t.Errorf("foldConstantKeys(%s) = %s, want %s", tt.expr, got, tt.want)
+			}
+		})
+	}
+
+	// The merged constants are reused until a symbol table is added
+	e.constantIndex()["CACHED"] = &types.ConstantDef{Name: "CACHED", Value: "'cached'"}
+	if got := e.foldConstantKeys("$_GET[CACHED]"); got != "$_GET['cached']" {
+		t.Errorf("constant index rebuilt without a symbol table change: %s", got)
+	}
+	addPHPClassFile(t, e, "/app/c.php", "<?php\ndefine('LATE', 'late');\n")
+	if got := e.foldConstantKeys("$_GET[LATE]"); got != "$_GET['late']" {
+		t.Errorf("after adding a symbol table: %s, want $_GET['late']", got)
+	}
+	if got := e.foldConstantKeys("$_GET[CACHED]"); got != "$_GET[CACHED]" {
+		t.Errorf("stale constant index after adding a symbol table: %s", got)
+	}
+}
@@ -0,0 +1,237 @@
+package symbolic
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+// objectCopy is an object variable assigned a copy of another object:
+// $copy = clone $request, or $copy = new Request($request) when the
+// constructor copies the traced property from its argument
+type objectCopy struct {
+	original string // Variable copied from, e.g. "$request"
+	member   string // Property of the original the traced property is copied from ("" for clone)
+	code     string // The copying assignment
+	file     string
+	line     int
+}
+
+// findCopy finds where the object of an expression is copied from another
+// object, searching contextFile first. A copy constructor only counts when it
+// copies the accessed property: $this->input = $other->input.
+func (e *ExecutionEngine) findCopy(parsed *ParsedExpression, contextFile string) *objectCopy {
+	if root, content, ok := e.parsedFile(contextFile); ok {
+		if cp := e.findCopyInAST(root, content, parsed); cp != nil {
+			cp.file = contextFile
+			return cp
+		}
+		// An object created in the context file is not a copy made elsewhere
+		if className, _ := e.findInstantiationInAST(root, content, parsed.VarName); className != "" {
+			return nil
+		}
+	}
+	name := []byte(strings.TrimPrefix(parsed.VarName, "$"))
+	for _, file := range e.files {
+		if file == contextFile {
+			continue // Already checked
+		}
+		if content, ok := e.fileContent(file); !ok || !bytes.Contains(content, name) {
+			continue
+		}
+		if root, content, ok := e.parsedFile(file); ok {
+			if cp := e.findCopyInAST(root, content, parsed); cp != nil {
+				cp.file = file
+				return cp
+			}
+		}
+	}
+	return nil
+}
+
+// findCopyInAST searches an AST for an assignment copying another object
+// into the variable of parsed
+func (e *ExecutionEngine) findCopyInAST(root *sitter.Node, source []byte, parsed *ParsedExpression) *objectCopy {
+	for _, assign := range findNodesOfType(root, "assignment_expression") {
+		if assign.ChildCount() < 3 {
+			continue
+		}
+		left, right := assign.Child(0), assign.Child(2)
+		if left == nil || right == nil || getNodeText(left, source) != parsed.VarName {
+			continue
+		}
+		cp := &objectCopy{
+			code: getNodeText(assign, source) + ";",
+			line: int(assign.StartPoint().Row) + 1,
+		}
+		switch right.Type() {
+		case "clone_expression":
+			operand := findChildByType(right, "variable_name")
+			if operand != nil && getNodeText(operand, source) != parsed.VarName {
+				cp.original = getNodeText(operand, source)
+				return cp
+			}
+		case "object_creation_expression":
+			if parsed.Type != ExprTypePropertyAccess || parsed.IsChained {
+				continue
+			}
+			nameNode := findChildByType(right, "name")
+			if nameNode == nil {
+				nameNode = findChildByType(right, "qualified_name")
+			}
+			args := findChildByType(right, "arguments")
+			if nameNode == nil || args == nil {
+				continue
+			}
+			cp.original, cp.member = e.copiedMember(getNodeText(nameNode, source), args, source, parsed.PropertyName)
+			if cp.original != "" {
+				return cp
+			}
+		}
+	}
+	return nil
+}
+
+// copiedMember returns the argument of a constructor call the constructor
+// copies property from, and the property of the argument it copies
+func (e *ExecutionEngine) copiedMember(className string, args *sitter.Node, source []byte, property string) (string, string) {
+	classDef, _ := e.findClassDefinition(className)
+	if classDef == nil || classDef.Constructor == nil {
+		return "", ""
+	}
+	constructor := classDef.Constructor
+	var assigned []string
+	for _, m := range e.match(phpPatterns.BuildDirectAssignPattern(property)).FindAllStringSubmatch(constructor.BodySource, -1) {
+		assigned = append(assigned, strings.TrimSpace(m[1]))
+	}
+	var position int
+	for i := 0; i < int(args.NamedChildCount()); i++ {
+		arg := args.NamedChild(i)
+		if arg.Type() != "argument" {
+			continue
+		}
+		index := position
+		position++
+		variable := arg.NamedChild(0)
+		if index >= len(constructor.Parameters) || variable == nil || variable.Type() != "variable_name" {
+			continue
+		}
+		param := regexp.MustCompile(`^\$` + regexp.QuoteMeta(constructor.Parameters[index].Name) + `->(\w+)$`)
+		for _, value := range assigned {
+			if m := e.match(param).FindStringSubmatch(value); m != nil {
+				return getNodeText(variable, source), m[1]
+			}
+		}
+	}
+	return "", ""
+}
+
+// traceCopy traces an expression on a copied object through the object it
+// was copied from. A __clone() that reassigns the accessed property replaces
+// the original's value, so the trace follows __clone() instead.
+func (e *ExecutionEngine) traceCopy(parsed *ParsedExpression, cp *objectCopy, flow *PropertyFlow) (*PropertyFlow, error) {
+	flow.PropertyName = parsed.PropertyName
+	flow.MethodName = parsed.MethodName
+	flow.AccessKey = parsed.AccessKey
+
+	description := fmt.Sprintf("%s is a clone of %s and starts with its properties", parsed.VarName, cp.original)
+	stepType := "clone"
+	rewritten := strings.Replace(flow.Expression, parsed.VarName+"->", cp.original+"->", 1)
+	if cp.member != "" {
+		description = fmt.Sprintf("%s is constructed from %s, copying %s->%s into $%s", parsed.VarName, cp.original, cp.original, cp.member, parsed.PropertyName)
+		stepType = "copy_constructor"
+		rewritten = strings.Replace(flow.Expression, parsed.VarName+"->"+parsed.PropertyName, cp.original+"->"+cp.member, 1)
+	}
+	flow.Steps = append(flow.Steps, FlowStep{
+		StepNumber:  len(flow.Steps) + 1,
+		Description: description,
+		Code:        cp.code,
+		FilePath:    cp.file,
+		Line:        cp.line,
+		Type:        stepType,
+	})
+
+	if cp.member == "" && parsed.PropertyName != "" {
+		if steps := e.traceCloneMethod(cp, parsed.PropertyName, parsed.AccessKey, flow); len(steps) > 0 {
+			for i := range steps {
+				steps[i].StepNumber = len(flow.Steps) + i + 1
+			}
+			flow.Steps = append(flow.Steps, steps...)
+			flow.Sources = append(flow.Sources, e.extractSources(steps)...)
+			return flow, nil
+		}
+	}
+
+	// Copies of copies are followed as deep as method calls
+	e.copyDepth++
+	defer func() { e.copyDepth-- }()
+	if e.copyDepth > e.maxDepth {
+		flow.Warnings = append(flow.Warnings, types.AnalysisWarning{
+			Category: types.WarningDepthCutoff,
+			Message:  fmt.Sprintf("tracing stopped at max depth %d following copies of %s", e.maxDepth, parsed.VarName),
+			FilePath: cp.file,
+			Line:     cp.line,
+		})
+		return flow, nil
+	}
+	original, err := e.tracePropertyAccessAt(rewritten, cp.file, cp.line)
+	if original == nil {
+		return nil, err
+	}
+	if original.ClassName != "" {
+		flow.ClassName = original.ClassName
+	}
+	for _, step := range original.Steps {
+		step.StepNumber = len(flow.Steps) + 1
+		flow.Steps = append(flow.Steps, step)
+	}
+	flow.Sources = append(flow.Sources, original.Sources...)
+	flow.Warnings = append(flow.Warnings, original.Warnings...)
+	flow.RuntimeAssisted = flow.RuntimeAssisted || original.RuntimeAssisted
+	return flow, err
+}
+
+// traceCloneMethod traces the __clone() of the cloned object's class when it
+// reassigns property. A deep copy ($this->p = clone $this->p) keeps the
+// original's value and is not a reassignment.
+func (e *ExecutionEngine) traceCloneMethod(cp *objectCopy, property string, accessKey string, flow *PropertyFlow) []FlowStep {
+	className, _, _ := e.findInstantiation(cp.original, cp.file)
+	if className == "" {
+		return nil
+	}
+	classDef, classFile := e.findClassDefinition(className)
+	if classDef == nil {
+		return nil
+	}
+	method := classDef.Methods["__clone"]
+	if method == nil {
+		return nil
+	}
+	deepCopy := regexp.MustCompile(`^clone\s+\$this->` + regexp.QuoteMeta(property) + `$`)
+	reassigned := false
+	for _, m := range e.match(phpPatterns.BuildDirectAssignPattern(property)).FindAllStringSubmatch(method.BodySource, -1) {
+		if !e.match(deepCopy).MatchString(strings.TrimSpace(m[1])) {
+			reassigned = true
+		}
+	}
+	if !reassigned {
+		return nil
+	}
+	savedDepth := e.currentDepth
+	e.currentDepth = 0
+	steps := e.traceMethod(classDef, method, classFile, property, accessKey, "", flow)
+	e.currentDepth = savedDepth
+	return append([]FlowStep{{
+		Description: fmt.Sprintf("%s::__clone() reassigns $%s on the copy", classDef.Name, property),
+		Code:        "function __clone() { ... }",
+		FilePath:    classFile,
+		Line:        method.Line,
+		Type:        "method_call",
+	}}, steps...)
+}
@@ -0,0 +1,94 @@
+package symbolic
+
+import (
+	"context"
+	"testing"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/php"
+
+	phpAnalyzer "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/php"
+)
+
+// addPHPClassFile registers PHP source with the engine along with its
+// symbol table, so the classes it declares can be traced through
+func addPHPClassFile(t *testing.T, e *ExecutionEngine, path, src string) {
+	t.Helper()
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(php.GetLanguage())
+	tree, err := parser.ParseCtx(context.Background(), nil, []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+	st, err := phpAnalyzer.NewPHPAnalyzer().BuildSymbolTable(path, []byte(src), tree.RootNode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.AddSymbolTable(path, st)
+	addPHPFile(t, e, path, src)
+}
+
+func TestTracePropertyAccess_Copies(t *testing.T) {
+	const classes = `<?php
+class Request {
+    public $input = array();
+    public $cookies = array();
+    function __construct($other = null) {
+        $this->input = $_GET;
+        if ($other) { $this->cookies = $other->cookies; }
+    }
+    function parse_cookies() {
+        foreach ($_COOKIE as $key => $val) { $this->cookies[$key] = $val; }
+    }
+    function __clone() {
+        $this->input = clone $this->input;
+        $this->cookies = array();
+    }
+}
+`
+	tests := []struct {
+		name       string
+		expression string
+		stepType   string
+		source     string // Expected ultimate source, "" for none
+	}{
+		{"clone keeps the original's property", "$copy->input['id']", "clone", "$_GET"},
+		{"__clone reassigns the property", "$copy->cookies['sid']", "clone", ""},
+		{"copy constructor copies the property", "$built->cookies['sid']", "copy_constructor", "$_COOKIE"},
+	}
+
+	e := NewExecutionEngine()
+	addPHPClassFile(t, e, "/app/Request.php", classes)
+	addPHPFile(t, e, "/app/index.php", `<?php
+$request = new Request();
+$request->parse_cookies();
+$copy = clone $request;
+$built = new Request($request);
+`)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow, err := e.TracePropertyAccess(tt.expression, "/app/index.php")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(flow.Steps) == 0 || flow.Steps[0].Type != tt.stepType {
+				t.Fatalf("steps = %+v, want a %s step first", flow.Steps, tt.stepType)
+			}
+			if flow.ClassName != "Request" && tt.source != "" {
+				t.Errorf("class = %q, want Request", flow.ClassName)
+			}
+			var found bool
+			for _, src := range flow.Sources {
+				found = found || src.Expression == tt.source
+			}
+			if tt.source == "" && len(flow.Sources) != 0 {
+				t.Errorf("sources = %+v, want none", flow.Sources)
+			}
+			if tt.source != "" && !found {
+				t.Errorf("sources = %+v, want %s", flow.Sources, tt.source)
+			}
+		})
+	}
+}
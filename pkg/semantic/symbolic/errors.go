@@ -0,0 +1,18 @@
+package symbolic
+
+import "github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+
+// Re-export the structured errors from pkg/semantic/types so callers of the
+// symbolic engine can use errors.Is/errors.As without importing types
+var (
+	ErrClassNotFound         = types.ErrClassNotFound
+	ErrInstantiationNotFound = types.ErrInstantiationNotFound
+	ErrMethodNotFound        = types.ErrMethodNotFound
+	ErrPropertyNotFound      = types.ErrPropertyNotFound
+	ErrParse                 = types.ErrParse
+	ErrMemoryLimit           = types.ErrMemoryLimit
+	ErrUnsupportedExpression = types.ErrUnsupportedExpression
+)
+
+// TraceError is a structured tracing failure (see types.TraceError)
+type TraceError = types.TraceError
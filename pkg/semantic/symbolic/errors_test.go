@@ -0,0 +1,111 @@
+package symbolic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// addPHPFile registers PHP source with the engine
+func addPHPFile(t *testing.T, e *ExecutionEngine, path, src string) {
+	t.Helper()
+	if err := e.AddFile(path, []byte(src)); err != nil {
+		t.Fatalf("failed to parse %s: %v", path, err)
+	}
+}
+
+func TestTracePropertyAccess_StructuredErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		setup      func(t *testing.T, e *ExecutionEngine)
+		want       error
+		className  string
+	}{
+		{
+			name:       "unparseable expression",
+			expression: "not an expression!",
+			want:       ErrUnsupportedExpression,
+		},
+		{
+			name:       "object never instantiated",
+			expression: "$request->input['id']",
+			want:       ErrInstantiationNotFound,
+		},
+		{
+			name:       "instantiated class without definition",
+			expression: "$request->input['id']",
+			setup: func(t *testing.T, e *ExecutionEngine) {
+				addPHPFile(t, e, "/app/index.php", "<?php\n$request = new MissingRequest();\n")
+			},
+			want:      ErrClassNotFound,
+			className: "MissingRequest",
+		},
+		{
+			name:       "container service resolved by runtime hint",
+			expression: "$mailer->input['to']",
+			setup: func(t *testing.T, e *ExecutionEngine) {
+				addPHPFile(t, e, "/app/index.php", "<?php\n$mailer = $container->get('mailer');\n")
+				e.SetServiceClasses(map[string]string{"mailer": "MissingMailer"})
+			},
+			want:      ErrClassNotFound,
+			className: "MissingMailer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutionEngine()
+			if tt.setup != nil {
+				tt.setup(t, e)
+			}
+
+			_, err := e.TracePropertyAccess(tt.expression, "/app/index.php")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+
+			var traceErr *TraceError
+			if !errors.As(err, &traceErr) {
+				t.Fatalf("expected *TraceError, got %T", err)
+			}
+			if traceErr.Expression != tt.expression {
+				t.Errorf("expected expression %q, got %q", tt.expression, traceErr.Expression)
+			}
+			if tt.className != "" && traceErr.ClassName != tt.className {
+				t.Errorf("expected class %q, got %q", tt.className, traceErr.ClassName)
+			}
+		})
+	}
+}
+
+func TestParseError_Unwrap(t *testing.T) {
+	cause := errors.New("read failed")
+	err := error(&types.ParseError{FilePath: "/app/index.php", Err: cause})
+
+	if !errors.Is(err, ErrParse) {
+		t.Error("ParseError should match ErrParse")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("ParseError should unwrap to its cause")
+	}
+	if errors.Is(err, ErrClassNotFound) {
+		t.Error("ParseError should not match ErrClassNotFound")
+	}
+}
+
+func TestMemoryLimitError_Is(t *testing.T) {
+	err := error(&types.MemoryLimitError{LimitMB: 100, UsedMB: 150, FilesProcessed: 25})
+
+	if !errors.Is(err, ErrMemoryLimit) {
+		t.Error("MemoryLimitError should match ErrMemoryLimit")
+	}
+	var memErr *types.MemoryLimitError
+	if !errors.As(err, &memErr) || memErr.UsedMB != 150 {
+		t.Errorf("errors.As should expose MemoryLimitError details, got %+v", memErr)
+	}
+}
@@ -4,11 +4,15 @@
 package symbolic
 
 import (
+	"bytes"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	phpAnalyzer "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/php"
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 	pkgSources "github.com/hatlesswizard/inputtracer/pkg/sources"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
@@ -57,6 +61,7 @@ type ParsedExpression struct {
 	Arguments       []string // method arguments
 	SuperglobalName string   // $_GET, $_POST, etc. (for ExprTypeSuperglobal)
 	IsSuperglobal   bool     // true if this is a superglobal access
+	Truncation      []string // Partial reads around the parsed value, outermost first: "substr()", "[0]"
 
 	// Chained expression support
 	IsChained       bool           // true if this is a chained expression
@@ -92,19 +97,54 @@ type ExecutionEngine struct {
 	// Maximum call depth to prevent infinite recursion
 	maxDepth int
 
+	// Maximum number of steps followed in a chained expression (0 = unlimited)
+	maxChainLength int
+
 	// Current analysis depth
 	currentDepth int
 
+	// Budget of the current trace, attached to its PropertyFlow
+	budget *types.BudgetMeter
+
+	// Yield points of file scans and method traces (see SetYield)
+	yielder *types.Yielder
+
+	// Object copies followed by the current trace (see traceCopy)
+	copyDepth int
+
 	// MEMORY OPTIMIZATION: LRU file cache instead of unbounded maps
 	// Keeps only recently-used files in memory, evicts LRU entries
 	fileCache *LRUFileCache
 
-	// Legacy maps for backward compatibility (deprecated - use fileCache)
-	parsedFiles  map[string]*sitter.Node
-	fileContents map[string][]byte
+	// Files known to the engine, in registration order; their content and
+	// ASTs live only in fileCache
+	files     []string
+	fileIndex map[string]bool
+
+	// Deprecated API use, reported on every traced flow (see AddParsedFile)
+	deprecations []types.AnalysisWarning
 
 	// Method return analysis cache: "ClassName.methodName" -> what it returns
 	methodReturns map[string]*MethodReturnInfo
+
+	// Scope trees per file for scope-qualified variable resolution
+	scopeTrees map[string]*types.Scope
+	scopeMu    sync.RWMutex
+
+	// Logical statements per file, split lazily (see fileStatements)
+	statements map[string][]statement
+
+	// Class hierarchy index, built lazily (see subclassIndex)
+	subclasses map[string][]classRef
+
+	// Constants of all symbol tables, merged lazily (see constantIndex)
+	constants map[string]*types.ConstantDef
+
+	// Classes DI services resolved to at runtime (see SetServiceClasses)
+	serviceClasses map[string]string
+
+	// Store step code as file references (see SetCompactSnippets)
+	compactSnippets bool
 }
 
 // MethodReturnInfo captures what a method returns
@@ -185,16 +225,44 @@ type PropertyFlow struct {
 
 	// Ultimate sources
 	Sources []UltimateSource
+
+	// Analysis gaps hit while tracing (class not found, chain truncated, ...)
+	Warnings []types.AnalysisWarning
+
+	// Why the trace stopped: source when it reached input, else the most
+	// severe gap in Warnings, or clean when there was none
+	Termination types.TerminationReason
+
+	// RuntimeAssisted is true when the trace relies on runtime hints, e.g. a
+	// container service resolved through SetServiceClasses
+	RuntimeAssisted bool
+
+	// Truncated is true when the expression reads only part of the traced
+	// value: a string offset, a nested element or a substring
+	Truncated bool
+
+	// Budget is what the trace consumed: files, parses, cache hits, pattern
+	// matches, depth and wall time
+	Budget *types.BudgetReport
+
+	// Candidates are the classes declaring the member read when the object's
+	// instantiation was not found (the trace then also returns an error)
+	Candidates []CandidateClass
+
+	truncation []string // ParsedExpression.Truncation of the expression
 }
 
 // FlowStep represents one step in the flow trace
 type FlowStep struct {
 	StepNumber  int
 	Description string
-	Code        string
+	Code        string // Empty when stored as a file reference; read it with CodeText
 	FilePath    string
 	Line        int
 	Type        string // "property_init", "constructor_call", "method_call", "assignment", "loop", "return"
+	Subclass    string // Concrete subclass traced when dispatching over an abstract class or interface
+
+	ref *snippetRef // Location of Code in FilePath (see SetCompactSnippets)
 }
 
 // UltimateSource represents the original user input source
@@ -203,25 +271,58 @@ type UltimateSource struct {
 	Expression string // e.g., "$_GET['thumbnail']"
 	FilePath   string
 	Line       int
+	Subclass   string // Concrete subclass the source was found through, if dispatched
 }
 
 // NewExecutionEngine creates a new symbolic execution engine
 // Uses an LRU file cache to limit memory usage
 func NewExecutionEngine() *ExecutionEngine {
 	return &ExecutionEngine{
-		symbolTables:  make(map[string]*types.SymbolTable),
-		instances:     make(map[string]*ObjectInstance),
-		properties:    make(map[string]*PropertyState),
-		callStack:     make([]MethodCall, 0, 32), // Pre-allocate reasonable capacity
-		flows:         make([]*PropertyFlow, 0, 64),
-		maxDepth:      10,
-		fileCache:     NewLRUFileCache(100), // Keep max 100 files in memory
-		parsedFiles:   make(map[string]*sitter.Node),
-		fileContents:  make(map[string][]byte),
-		methodReturns: make(map[string]*MethodReturnInfo),
+		symbolTables:   make(map[string]*types.SymbolTable),
+		instances:      make(map[string]*ObjectInstance),
+		properties:     make(map[string]*PropertyState),
+		callStack:      make([]MethodCall, 0, 32), // Pre-allocate reasonable capacity
+		flows:          make([]*PropertyFlow, 0, 64),
+		maxDepth:       10,
+		maxChainLength: DefaultMaxChainLength,
+		fileCache:      NewLRUFileCache(100), // Keep max 100 files in memory
+		fileIndex:      make(map[string]bool),
+		methodReturns:  make(map[string]*MethodReturnInfo),
+		scopeTrees:     make(map[string]*types.Scope),
+		statements:     make(map[string][]statement),
 	}
 }
 
+// DefaultMaxChainLength is the default cap on steps followed in a chained expression
+const DefaultMaxChainLength = 16
+
+// SetMaxChainLength sets the maximum number of chain steps followed in a single
+// chained trace; longer chains end with a "chain_truncated" step (0 = unlimited)
+func (e *ExecutionEngine) SetMaxChainLength(n int) {
+	e.maxChainLength = n
+}
+
+// SetServiceClasses sets the classes container services resolved to at
+// runtime, used for $var = $container->get('service') without a type hint
+func (e *ExecutionEngine) SetServiceClasses(classes map[string]string) {
+	e.serviceClasses = classes
+}
+
+// SetYield makes traces call hook (runtime.Gosched when nil) every n files
+// read or methods traced, so that an embedding application stays responsive
+// during long traces (n <= 0 = never yield)
+func (e *ExecutionEngine) SetYield(n int, hook func()) {
+	e.yielder = types.NewYielder(n, hook)
+}
+
+// SetSandbox restricts the files traces load from disk to the given root
+// directories; a file resolving outside them (e.g. through a symlink) fails
+// with types.ErrSandboxEscape and is not searched. Without roots any file is
+// loaded.
+func (e *ExecutionEngine) SetSandbox(roots []string) {
+	e.fileCache.sandbox = types.NewSandbox(roots)
+}
+
 // NewExecutionEngineWithCacheSize creates an engine with custom cache size
 func NewExecutionEngineWithCacheSize(cacheSize int) *ExecutionEngine {
 	e := NewExecutionEngine()
@@ -232,50 +333,89 @@ func NewExecutionEngineWithCacheSize(cacheSize int) *ExecutionEngine {
 // AddSymbolTable adds a symbol table from a parsed file
 func (e *ExecutionEngine) AddSymbolTable(filePath string, st *types.SymbolTable) {
 	e.symbolTables[filePath] = st
+	e.subclasses = nil
+	e.constants = nil
+}
+
+// AddFile registers a PHP file searched by traces. Its content and AST are
+// loaded from disk on demand through the LRU cache; content, if non-nil, is
+// the file's current state and is reparsed from memory after eviction
+func (e *ExecutionEngine) AddFile(filePath string, content []byte) error {
+	if content != nil {
+		if err := e.fileCache.Put(filePath, content); err != nil {
+			return &types.ParseError{FilePath: filePath, Err: err}
+		}
+	}
+	if !e.fileIndex[filePath] {
+		e.fileIndex[filePath] = true
+		e.files = append(e.files, filePath)
+	}
+	delete(e.statements, filePath)
+	e.scopeMu.Lock()
+	delete(e.scopeTrees, filePath)
+	e.scopeMu.Unlock()
+	return nil
 }
 
-// AddParsedFile adds a parsed file AST
-// DEPRECATED: Use SetFilePath and let the LRU cache handle loading
+// AddParsedFile adds a parsed file AST.
+//
+// Deprecated: Use AddFile. The AST is not kept: the file is re-parsed into
+// the LRU cache, and traces report a deprecated_api warning.
 func (e *ExecutionEngine) AddParsedFile(filePath string, root *sitter.Node, content []byte) {
-	// Store in legacy maps for backward compatibility
-	e.parsedFiles[filePath] = root
-	e.fileContents[filePath] = content
+	if len(e.deprecations) == 0 {
+		e.deprecations = append(e.deprecations, types.AnalysisWarning{
+			Category: types.WarningDeprecatedAPI,
+			Message:  "ExecutionEngine.AddParsedFile is deprecated, use AddFile",
+		})
+	}
+	_ = e.AddFile(filePath, content)
 }
 
 // GetFileContent retrieves file content using LRU cache (lazy loading)
 func (e *ExecutionEngine) GetFileContent(filePath string) ([]byte, error) {
-	// Try LRU cache first
-	if e.fileCache != nil {
-		return e.fileCache.GetContent(filePath)
-	}
-	// Fall back to legacy map
-	if content, ok := e.fileContents[filePath]; ok {
-		return content, nil
+	if e.fileCache == nil {
+		return nil, nil
 	}
-	return nil, nil
+	return e.fileCache.GetContent(filePath)
 }
 
 // GetParsedFile retrieves parsed AST using LRU cache (lazy loading)
 func (e *ExecutionEngine) GetParsedFile(filePath string) (*sitter.Node, error) {
-	// Try LRU cache first
-	if e.fileCache != nil {
-		return e.fileCache.GetParsedFile(filePath)
+	if e.fileCache == nil {
+		return nil, nil
 	}
-	// Fall back to legacy map
-	if root, ok := e.parsedFiles[filePath]; ok {
-		return root, nil
+	return e.fileCache.GetParsedFile(filePath)
+}
+
+// fileContent returns the content of a registered file without parsing it
+func (e *ExecutionEngine) fileContent(filePath string) ([]byte, bool) {
+	if !e.fileIndex[filePath] {
+		return nil, false
 	}
-	return nil, nil
+	e.yielder.Tick()
+	e.touchFile(filePath, false)
+	content, err := e.fileCache.Content(filePath)
+	return content, err == nil
 }
 
-// ClearFileCache releases all cached files to free memory
+// parsedFile returns the AST and content of a registered file
+func (e *ExecutionEngine) parsedFile(filePath string) (*sitter.Node, []byte, bool) {
+	if !e.fileIndex[filePath] {
+		return nil, nil, false
+	}
+	e.yielder.Tick()
+	e.touchFile(filePath, true)
+	root, content, err := e.fileCache.Get(filePath)
+	return root, content, err == nil
+}
+
+// ClearFileCache releases all cached files to free memory. Registered files
+// stay known and are reloaded from disk (or from the content passed to
+// AddFile) when traced again.
 func (e *ExecutionEngine) ClearFileCache() {
 	if e.fileCache != nil {
 		e.fileCache.Clear()
 	}
-	// Also clear legacy maps
-	e.parsedFiles = make(map[string]*sitter.Node)
-	e.fileContents = make(map[string][]byte)
 }
 
 // FileCacheStats returns cache statistics for monitoring
@@ -287,18 +427,74 @@ func (e *ExecutionEngine) FileCacheStats() (hits, misses, memUsage int64) {
 }
 
 // TracePropertyAccess traces any expression - property access OR method call
-// This is the main entry point for symbolic tracing
+// This is the main entry point for symbolic tracing. When the object's
+// instantiation is not found it returns a partial flow with candidate
+// classes along with the ErrInstantiationNotFound error.
 func (e *ExecutionEngine) TracePropertyAccess(expression string, contextFile string) (*PropertyFlow, error) {
+	return e.TracePropertyAccessAt(expression, contextFile, 0)
+}
+
+// TracePropertyAccessAt traces an expression read at a specific line of contextFile.
+// The line selects the enclosing scope, so local variables only resolve against
+// assignments in the same function. A line of 0 means the scope is unknown.
+func (e *ExecutionEngine) TracePropertyAccessAt(expression string, contextFile string, line int) (*PropertyFlow, error) {
+	e.budget = types.NewBudgetMeter()
+	defer func() { e.budget = nil }()
+	flow, err := e.tracePropertyAccessAt(expression, contextFile, line)
+	if flow != nil {
+		e.noteTruncation(flow)
+		flow.Termination = types.TerminationSource
+		if len(flow.Sources) == 0 {
+			flow.Termination = types.TerminationFromWarnings(flow.Warnings)
+			if err != nil {
+				flow.Termination = types.CombineTermination(flow.Termination, types.TerminationFromWarning(types.WarningFromError(err)))
+			}
+		}
+		// Not an analysis gap, so it does not affect the termination
+		flow.Warnings = append(flow.Warnings, e.deprecations...)
+		if e.compactSnippets {
+			e.compactSteps(flow)
+		}
+		for _, w := range flow.Warnings {
+			e.budget.HitLimitOf(w.Category)
+		}
+		flow.Budget = e.budget.Report()
+	}
+	return flow, err
+}
+
+// noteTruncation ends the trace of a partial read with a step saying which
+// part of the traced value the expression reads
+func (e *ExecutionEngine) noteTruncation(flow *PropertyFlow) {
+	if len(flow.truncation) == 0 {
+		return
+	}
+	flow.Truncated = true
+	flow.Steps = append(flow.Steps, FlowStep{
+		StepNumber:  len(flow.Steps) + 1,
+		Description: fmt.Sprintf("Value read partially (%s), still tainted but truncated", strings.Join(flow.truncation, ", ")),
+		Code:        flow.Expression,
+		Type:        "truncation",
+	})
+}
+
+// tracePropertyAccessAt dispatches on the parsed expression type
+func (e *ExecutionEngine) tracePropertyAccessAt(expression string, contextFile string, line int) (*PropertyFlow, error) {
 	// Parse the expression to determine its type
 	parsed := e.parseExpression(expression)
 	if parsed.Type == ExprTypeUnknown {
-		return nil, fmt.Errorf("could not parse expression: %s", expression)
+		return nil, &TraceError{
+			Kind:       ErrUnsupportedExpression,
+			Expression: expression,
+			Message:    fmt.Sprintf("could not parse expression: %s", expression),
+		}
 	}
 
 	flow := &PropertyFlow{
 		Expression: expression,
 		Steps:      make([]FlowStep, 0),
 		Sources:    make([]UltimateSource, 0),
+		truncation: parsed.Truncation,
 	}
 
 	// GAP #1 FIX: Handle direct superglobal access
@@ -308,7 +504,7 @@ func (e *ExecutionEngine) TracePropertyAccess(expression string, contextFile str
 
 	// GAP #2 FIX: Handle local variable tracing
 	if parsed.Type == ExprTypeLocalVariable {
-		return e.traceLocalVariable(parsed, contextFile, flow)
+		return e.traceLocalVariable(parsed, contextFile, line, flow)
 	}
 
 	// GAP #3 FIX: Handle static method/property calls
@@ -319,10 +515,30 @@ func (e *ExecutionEngine) TracePropertyAccess(expression string, contextFile str
 		return e.traceStaticProperty(parsed, flow)
 	}
 
+	// Clones and copy-constructed objects carry the original's properties
+	if cp := e.findCopy(parsed, contextFile); cp != nil {
+		return e.traceCopy(parsed, cp, flow)
+	}
+
 	// For object-based expressions, find instantiation
 	className, instantiationFile, instantiationLine := e.findInstantiation(parsed.VarName, contextFile)
 	if className == "" {
-		return nil, fmt.Errorf("could not find instantiation of variable %s (searched %d files)", parsed.VarName, len(e.parsedFiles))
+		return e.instantiationNotFound(parsed, flow)
+	}
+
+	if service, ok := diService(className); ok {
+		if class := e.serviceClasses[service]; class != "" {
+			flow.Steps = append(flow.Steps, FlowStep{
+				StepNumber:  len(flow.Steps) + 1,
+				Description: fmt.Sprintf("Service '%s' resolved to %s at runtime", service, class),
+				Code:        fmt.Sprintf("%s = ...->get('%s')", parsed.VarName, service),
+				FilePath:    instantiationFile,
+				Line:        instantiationLine,
+				Type:        "runtime_hint",
+			})
+			flow.RuntimeAssisted = true
+			className = class
+		}
 	}
 
 	parsed.ClassName = className
@@ -331,7 +547,12 @@ func (e *ExecutionEngine) TracePropertyAccess(expression string, contextFile str
 	// Find the class definition
 	classDef, classFile := e.findClassDefinition(className)
 	if classDef == nil {
-		return nil, fmt.Errorf("could not find class definition for %s", className)
+		return nil, &TraceError{
+			Kind:       ErrClassNotFound,
+			Expression: expression,
+			ClassName:  className,
+			Message:    fmt.Sprintf("could not find class definition for %s", className),
+		}
 	}
 
 	// GAP #4 FIX: Handle chained expressions like $obj->method()->property
@@ -345,7 +566,11 @@ func (e *ExecutionEngine) TracePropertyAccess(expression string, contextFile str
 	case ExprTypePropertyAccess:
 		return e.tracePropertyAccessExpr(parsed, classDef, classFile, instantiationFile, instantiationLine, flow)
 	default:
-		return nil, fmt.Errorf("unsupported expression type: %v", parsed.Type)
+		return nil, &TraceError{
+			Kind:       ErrUnsupportedExpression,
+			Expression: expression,
+			Message:    fmt.Sprintf("unsupported expression type: %v", parsed.Type),
+		}
 	}
 }
 
@@ -378,7 +603,7 @@ func (e *ExecutionEngine) traceSuperglobal(parsed *ParsedExpression, flow *Prope
 }
 
 // traceLocalVariable traces a local variable to find its source
-func (e *ExecutionEngine) traceLocalVariable(parsed *ParsedExpression, contextFile string, flow *PropertyFlow) (*PropertyFlow, error) {
+func (e *ExecutionEngine) traceLocalVariable(parsed *ParsedExpression, contextFile string, line int, flow *PropertyFlow) (*PropertyFlow, error) {
 	varName := parsed.VarName
 
 	flow.Steps = append(flow.Steps, FlowStep{
@@ -390,8 +615,9 @@ func (e *ExecutionEngine) traceLocalVariable(parsed *ParsedExpression, contextFi
 		Type:        "local_var",
 	})
 
-	// Search all files for assignments to this variable from superglobals
-	foundAssignments := e.findVariableAssignments(varName)
+	// Search for assignments to this variable, restricted to the reading scope when known
+	scope, scoped := e.scopeAt(contextFile, line, varName)
+	foundAssignments := e.findVariableAssignments(varName, contextFile, scope, scoped)
 
 	for i, assignment := range foundAssignments {
 		flow.Steps = append(flow.Steps, FlowStep{
@@ -404,8 +630,9 @@ func (e *ExecutionEngine) traceLocalVariable(parsed *ParsedExpression, contextFi
 		})
 
 		// Check if source is a superglobal
+		carried := e.carriedValue(assignment.source)
 		for sg, sgType := range pkgSources.SuperglobalToSourceType {
-			if strings.Contains(assignment.source, sg) {
+			if strings.Contains(carried, sg) {
 				flow.Sources = append(flow.Sources, UltimateSource{
 					Type:       string(sgType),
 					Expression: assignment.source,
@@ -425,6 +652,7 @@ func (e *ExecutionEngine) traceLocalVariable(parsed *ParsedExpression, contextFi
 			Line:        0,
 			Type:        "not_found",
 		})
+		flow.addWarning(types.WarningUnresolvedVariable, "", 0, fmt.Sprintf("No assignments found for %s in parsed files", varName))
 	}
 
 	return flow, nil
@@ -437,30 +665,84 @@ type variableAssignment struct {
 	line   int
 }
 
+// scopeTree returns the scope tree of a file, building it from the parsed AST if needed
+func (e *ExecutionEngine) scopeTree(filePath string) *types.Scope {
+	e.scopeMu.RLock()
+	tree, ok := e.scopeTrees[filePath]
+	e.scopeMu.RUnlock()
+	if ok {
+		return tree
+	}
+	if st := e.symbolTables[filePath]; st != nil && st.Scopes != nil {
+		tree = st.Scopes
+	} else if root, content, ok := e.parsedFile(filePath); ok {
+		tree = analyzer.BuildScopeTree(root, content, filePath, "php")
+	}
+	e.scopeMu.Lock()
+	e.scopeTrees[filePath] = tree
+	e.scopeMu.Unlock()
+	return tree
+}
+
+// scopeAt returns the scope owning a variable used at a line and whether it
+// could be determined; a variable the function declares global is file-level
+func (e *ExecutionEngine) scopeAt(filePath string, line int, varName string) (string, bool) {
+	if filePath == "" || line <= 0 {
+		return "", false
+	}
+	tree := e.scopeTree(filePath)
+	if tree == nil {
+		return "", false
+	}
+	return tree.VariableScopeOf(line, varName), true
+}
+
 // findVariableAssignments searches for assignments to a variable
-func (e *ExecutionEngine) findVariableAssignments(varName string) []variableAssignment {
+// When scoped is true only assignments visible from scope in contextFile are returned
+func (e *ExecutionEngine) findVariableAssignments(varName string, contextFile string, scope string, scoped bool) []variableAssignment {
 	var assignments []variableAssignment
 
 	// Remove $ prefix for matching
 	varNameClean := strings.TrimPrefix(varName, "$")
 
 	// Search all file contents for assignments
-	for file, content := range e.fileContents {
-		// Pattern: $varname = something
+	for _, file := range e.files {
+		// Function locals never leak into other files
+		if scoped && scope != "" && file != contextFile {
+			continue
+		}
+		content, ok := e.fileContent(file)
+		if !ok {
+			continue
+		}
+
+		// Pattern: $varname = something, matched per statement so multi-line
+		// statements and alternative-syntax bodies are seen whole
 		assignPattern := patterns.BuildVariableAssignPattern(varNameClean)
-		lines := strings.Split(string(content), "\n")
+		if !bytes.Contains(content, []byte("$"+varNameClean)) {
+			continue
+		}
 
-		for lineNum, line := range lines {
-			if matches := assignPattern.FindStringSubmatch(line); len(matches) >= 2 {
+		for _, stmt := range e.fileStatements(file) {
+			if matches := e.match(assignPattern).FindStringSubmatch(stmt.text); len(matches) >= 2 {
+				if scoped {
+					assignScope, _ := e.scopeAt(file, stmt.line, varNameClean)
+					if !types.SameVariableScope(assignScope, scope, file == contextFile) {
+						continue
+					}
+				}
 				assignments = append(assignments, variableAssignment{
 					source: strings.TrimSpace(matches[1]),
 					file:   file,
-					line:   lineNum + 1,
+					line:   stmt.line,
 				})
 			}
 		}
 	}
 
+	// Array builtins writing into the variable by reference
+	assignments = append(assignments, e.findBuiltinWrites(varName, contextFile, scope, scoped)...)
+
 	return assignments
 }
 
@@ -473,7 +755,11 @@ func (e *ExecutionEngine) findExternalPropertyAssignments(varName string, proper
 	varNameWithoutDollar := strings.TrimPrefix(varName, "$")
 
 	// Search all file contents for external property assignments
-	for file, content := range e.fileContents {
+	for _, file := range e.files {
+		content, ok := e.fileContent(file)
+		if !ok {
+			continue
+		}
 		// Pattern: $varname->property = something
 		// or $varname->property['key'] = something
 		assignPatterns := []*regexp.Regexp{
@@ -483,15 +769,17 @@ func (e *ExecutionEngine) findExternalPropertyAssignments(varName string, proper
 			patterns.BuildPropertyArrayExternalAssignPattern(varNameWithoutDollar, propertyName),
 		}
 
-		lines := strings.Split(string(content), "\n")
-		for lineNum, line := range lines {
+		if !bytes.Contains(content, []byte("->"+propertyName)) {
+			continue
+		}
+		for _, stmt := range e.fileStatements(file) {
 			for _, pattern := range assignPatterns {
-				if matches := pattern.FindStringSubmatch(line); len(matches) >= 2 {
+				if matches := e.match(pattern).FindStringSubmatch(stmt.text); len(matches) >= 2 {
 					assignments = append(assignments, ExternalAssignment{
 						PropertyName: propertyName,
 						Source:       strings.TrimSpace(matches[1]),
 						FilePath:     file,
-						Line:         lineNum + 1,
+						Line:         stmt.line,
 					})
 				}
 			}
@@ -541,7 +829,7 @@ func (e *ExecutionEngine) traceExternalPropertyAssignment(parsed *ParsedExpressi
 		}
 
 		// If source is a function call, trace into that function
-		if matches := patterns.FunctionCallPattern.FindStringSubmatch(assign.Source); len(matches) >= 2 {
+		if matches := e.match(patterns.FunctionCallPattern).FindStringSubmatch(assign.Source); len(matches) >= 2 {
 			funcName := matches[1]
 			funcArgs := ""
 			if len(matches) >= 3 {
@@ -624,7 +912,7 @@ func (e *ExecutionEngine) checkMagicPropertyPattern(classDef *types.ClassDef, cl
 	if method, ok := classDef.Methods["__get"]; ok {
 		info.HasMagicGet = true
 		// Look for return $this->property[$name] pattern
-		if matches := patterns.BackingPropertyPattern.FindStringSubmatch(method.BodySource); len(matches) >= 2 {
+		if matches := e.match(patterns.BackingPropertyPattern).FindStringSubmatch(method.BodySource); len(matches) >= 2 {
 			info.BackingProperty = matches[1]
 		}
 		return info
@@ -634,7 +922,7 @@ func (e *ExecutionEngine) checkMagicPropertyPattern(classDef *types.ClassDef, cl
 	// This is used in classes like MyLanguage that load properties dynamically
 	for methodName, method := range classDef.Methods {
 		if method.BodySource != "" {
-			if patterns.DynamicPropertyAssignPattern.MatchString(method.BodySource) {
+			if e.match(patterns.DynamicPropertyAssignPattern).MatchString(method.BodySource) {
 				info.HasDynamicAssign = true
 				info.AssignMethodName = methodName
 
@@ -644,7 +932,7 @@ func (e *ExecutionEngine) checkMagicPropertyPattern(classDef *types.ClassDef, cl
 				}
 
 				// Check for foreach pattern: foreach($array as $key => $val) { $this->$key = $val; }
-				if matches := patterns.ForeachWithKVPattern.FindStringSubmatch(method.BodySource); len(matches) >= 2 {
+				if matches := e.match(patterns.ForeachWithKVPattern).FindStringSubmatch(method.BodySource); len(matches) >= 2 {
 					info.BackingProperty = matches[1]
 				}
 
@@ -752,6 +1040,7 @@ func (e *ExecutionEngine) traceStaticCall(parsed *ParsedExpression, flow *Proper
 			Line:        0,
 			Type:        "not_found",
 		})
+		flow.addWarning(types.WarningClassNotFound, "", 0, fmt.Sprintf("Class %s not found", parsed.ClassName))
 		return flow, nil
 	}
 
@@ -766,6 +1055,7 @@ func (e *ExecutionEngine) traceStaticCall(parsed *ParsedExpression, flow *Proper
 			Line:        0,
 			Type:        "not_found",
 		})
+		flow.addWarning(types.WarningMethodNotFound, "", 0, fmt.Sprintf("Static method %s not found in class %s", parsed.MethodName, parsed.ClassName))
 		return flow, nil
 	}
 
@@ -821,6 +1111,7 @@ func (e *ExecutionEngine) traceStaticProperty(parsed *ParsedExpression, flow *Pr
 			Line:        0,
 			Type:        "not_found",
 		})
+		flow.addWarning(types.WarningClassNotFound, "", 0, fmt.Sprintf("Class %s not found", parsed.ClassName))
 		return flow, nil
 	}
 
@@ -873,6 +1164,12 @@ func (e *ExecutionEngine) traceChainedExpression(parsed *ParsedExpression, class
 	for i, step := range parsed.ChainSteps {
 		isLastStep := i == len(parsed.ChainSteps)-1
 
+		if e.maxChainLength > 0 && i >= e.maxChainLength {
+			flow.truncateChain(stepNum,
+				fmt.Sprintf("Chain length limit (%d) reached - %d remaining step(s) not traced", e.maxChainLength, len(parsed.ChainSteps)-i))
+			break
+		}
+
 		if step.Type == ExprTypeMethodCall {
 			// Find the method
 			methodDef, ok := currentClass.Methods[step.Name]
@@ -885,6 +1182,7 @@ func (e *ExecutionEngine) traceChainedExpression(parsed *ParsedExpression, class
 					Line:        0,
 					Type:        "method_not_found",
 				})
+				flow.addWarning(types.WarningMethodNotFound, currentClassFile, 0, fmt.Sprintf("Method %s() not found in class %s", step.Name, currentClass.Name))
 				break
 			}
 
@@ -909,7 +1207,7 @@ func (e *ExecutionEngine) traceChainedExpression(parsed *ParsedExpression, class
 					flow.MethodName = step.Name
 
 					// Trace sources from that property
-					propSteps := e.traceConstructor(currentClass, currentClassFile, returnInfo.PropertyName, step.AccessKey)
+					propSteps := e.traceConstructor(currentClass, currentClassFile, returnInfo.PropertyName, step.AccessKey, flow)
 					for _, ps := range propSteps {
 						ps.StepNumber = stepNum
 						flow.Steps = append(flow.Steps, ps)
@@ -947,6 +1245,7 @@ func (e *ExecutionEngine) traceChainedExpression(parsed *ParsedExpression, class
 				Line:        0,
 				Type:        "return_type_unknown",
 			})
+			flow.addWarning(types.WarningReturnTypeUnknown, "", 0, fmt.Sprintf("Cannot determine return type of %s() - chain tracing stopped", step.Name))
 			break
 
 		} else if step.Type == ExprTypePropertyAccess {
@@ -962,6 +1261,7 @@ func (e *ExecutionEngine) traceChainedExpression(parsed *ParsedExpression, class
 					Line:        0,
 					Type:        "property_not_found",
 				})
+				flow.addWarning(types.WarningPropertyNotFound, currentClassFile, 0, fmt.Sprintf("Property %s not found in class %s", step.Name, currentClass.Name))
 				break
 			}
 
@@ -978,7 +1278,7 @@ func (e *ExecutionEngine) traceChainedExpression(parsed *ParsedExpression, class
 
 			if isLastStep {
 				// Trace the property sources
-				propSteps := e.traceConstructor(currentClass, currentClassFile, step.Name, step.AccessKey)
+				propSteps := e.traceConstructor(currentClass, currentClassFile, step.Name, step.AccessKey, flow)
 				for _, ps := range propSteps {
 					ps.StepNumber = stepNum
 					flow.Steps = append(flow.Steps, ps)
@@ -993,6 +1293,27 @@ func (e *ExecutionEngine) traceChainedExpression(parsed *ParsedExpression, class
 	return flow, nil
 }
 
+// truncateChain records the step and warning for chained tracing stopping at a limit
+func (f *PropertyFlow) truncateChain(stepNum int, reason string) {
+	f.Steps = append(f.Steps, FlowStep{
+		StepNumber:  stepNum,
+		Description: reason,
+		Type:        "chain_truncated",
+	})
+	f.addWarning(types.WarningChainTruncated, "", 0, reason)
+}
+
+// addWarning records an analysis gap hit while tracing the flow's expression
+func (f *PropertyFlow) addWarning(category types.WarningCategory, filePath string, line int, message string) {
+	f.Warnings = append(f.Warnings, types.AnalysisWarning{
+		Category:   category,
+		Message:    message,
+		FilePath:   filePath,
+		Line:       line,
+		Expression: f.Expression,
+	})
+}
+
 // inferMethodReturnType tries to determine what class a method returns
 func (e *ExecutionEngine) inferMethodReturnType(classDef *types.ClassDef, methodDef *types.MethodDef, classFile string) string {
 	// Check for explicit return type annotation first
@@ -1008,12 +1329,12 @@ func (e *ExecutionEngine) inferMethodReturnType(classDef *types.ClassDef, method
 	}
 
 	// Check for return new ClassName()
-	if matches := patterns.ReturnNewPattern.FindStringSubmatch(body); len(matches) >= 2 {
+	if matches := e.match(patterns.ReturnNewPattern).FindStringSubmatch(body); len(matches) >= 2 {
 		return matches[1]
 	}
 
 	// Check for @return PHPDoc annotation
-	if matches := patterns.PHPDocReturnPattern.FindStringSubmatch(body); len(matches) >= 2 {
+	if matches := e.match(patterns.PHPDocReturnPattern).FindStringSubmatch(body); len(matches) >= 2 {
 		returnType := matches[1]
 		if returnType != "void" && returnType != "self" && returnType != "static" && returnType != "mixed" {
 			return returnType
@@ -1023,6 +1344,93 @@ func (e *ExecutionEngine) inferMethodReturnType(classDef *types.ClassDef, method
 	return ""
 }
 
+// foldConstantKeys replaces subscript keys built from constants and literal
+// concatenations ($_GET[PARAM_NAME], $x->input[MyBB::KEY]) with their folded
+// string value so the access patterns below see a literal key
+func (e *ExecutionEngine) foldConstantKeys(expr string) string {
+	if !strings.Contains(expr, "[") {
+		return expr
+	}
+
+	constants := e.constantIndex()
+	if len(constants) == 0 {
+		return expr
+	}
+	lookup := phpAnalyzer.ConstantLookup(constants, "")
+
+	var sb strings.Builder
+	for {
+		open := strings.IndexByte(expr, '[')
+		if open < 0 {
+			break
+		}
+		close := matchingBracket(expr, open)
+		if close < 0 {
+			break
+		}
+		sb.WriteString(expr[:open+1])
+		keyExpr := expr[open+1 : close]
+		if value, ok := phpPatterns.FoldConstantExpression(keyExpr, lookup); ok && !phpPatterns.IsLiteralKey(keyExpr) {
+			sb.WriteString("'" + value + "'")
+		} else {
+			sb.WriteString(keyExpr)
+		}
+		sb.WriteByte(']')
+		expr = expr[close+1:]
+	}
+	sb.WriteString(expr)
+	return sb.String()
+}
+
+// constantIndex returns the constants defined across all symbol tables; a
+// name defined in several files keeps the definition of the first file in
+// path order. The index is built on first use and dropped when a symbol
+// table is added.
+func (e *ExecutionEngine) constantIndex() map[string]*types.ConstantDef {
+	if e.constants != nil {
+		return e.constants
+	}
+	files := make([]string, 0, len(e.symbolTables))
+	for filePath := range e.symbolTables {
+		files = append(files, filePath)
+	}
+	sort.Strings(files)
+	e.constants = make(map[string]*types.ConstantDef)
+	for _, filePath := range files {
+		for name, c := range e.symbolTables[filePath].Constants {
+			if e.constants[name] == nil {
+				e.constants[name] = c
+			}
+		}
+	}
+	return e.constants
+}
+
+// matchingBracket returns the index of the "]" closing the "[" at open, or -1
+func matchingBracket(expr string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == quote && expr[i-1] != '\\' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
 // parseExpression parses any expression and determines its type
 func (e *ExecutionEngine) parseExpression(expr string) *ParsedExpression {
 	parsed := &ParsedExpression{
@@ -1030,7 +1438,7 @@ func (e *ExecutionEngine) parseExpression(expr string) *ParsedExpression {
 		RawExpr: expr,
 	}
 
-	expr = strings.TrimSpace(expr)
+	expr = e.foldConstantKeys(strings.TrimSpace(expr))
 
 	// GAP #4 FIX: Try chained expression parsing first
 	// This handles expressions like: $obj->method()->property or $obj->method1()->method2('arg')
@@ -1042,7 +1450,7 @@ func (e *ExecutionEngine) parseExpression(expr string) *ParsedExpression {
 
 	// GAP #1 FIX: Try superglobal pattern first: $_GET['key'], $_POST['key'], etc.
 	// Pattern: $_SUPERGLOBAL['key'] or $_SUPERGLOBAL["key"]
-	if matches := patterns.SuperglobalAccessPattern.FindStringSubmatch(expr); len(matches) >= 3 {
+	if matches := e.match(patterns.SuperglobalAccessPattern).FindStringSubmatch(expr); len(matches) >= 3 {
 		parsed.Type = ExprTypeSuperglobal
 		parsed.SuperglobalName = "$_" + matches[1]
 		parsed.AccessKey = matches[2]
@@ -1066,7 +1474,7 @@ func (e *ExecutionEngine) parseExpression(expr string) *ParsedExpression {
 	}
 
 	// GAP #3 FIX: Try static property/constant pattern: Class::$property or Class::CONSTANT
-	if matches := patterns.StaticPropertyPattern.FindStringSubmatch(expr); len(matches) >= 3 {
+	if matches := e.match(patterns.StaticPropertyPattern).FindStringSubmatch(expr); len(matches) >= 3 {
 		parsed.Type = ExprTypeStaticProperty
 		parsed.ClassName = matches[1]
 		parsed.PropertyName = matches[2]
@@ -1089,7 +1497,7 @@ func (e *ExecutionEngine) parseExpression(expr string) *ParsedExpression {
 	}
 
 	// Try property access pattern: $var->property or $var->property['key']
-	if matches := patterns.PropertyAccessPattern.FindStringSubmatch(expr); len(matches) >= 3 {
+	if matches := e.match(patterns.PropertyAccessPattern).FindStringSubmatch(expr); len(matches) >= 3 {
 		parsed.Type = ExprTypePropertyAccess
 		parsed.VarName = "$" + matches[1]
 		parsed.PropertyName = matches[2]
@@ -1101,12 +1509,39 @@ func (e *ExecutionEngine) parseExpression(expr string) *ParsedExpression {
 
 	// GAP #2 FIX: Try simple local variable pattern: $varname
 	// This must come LAST as it's the most generic pattern
-	if matches := patterns.LocalVariablePattern.FindStringSubmatch(expr); len(matches) >= 2 {
+	if matches := e.match(patterns.LocalVariablePattern).FindStringSubmatch(expr); len(matches) >= 2 {
 		parsed.Type = ExprTypeLocalVariable
 		parsed.VarName = "$" + matches[1]
 		return parsed
 	}
 
+	return e.parsePartialRead(expr, parsed)
+}
+
+// parsePartialRead parses what a substring call or trailing subscript the
+// patterns do not cover reads from: $_GET['id'][0] parses as $_GET['id'],
+// substr($t, 0, 8) as $t. The input is still tainted, only truncated.
+func (e *ExecutionEngine) parsePartialRead(expr string, unknown *ParsedExpression) *ParsedExpression {
+	var inner, how string
+	if fn, arg, ok := phpPatterns.SubstringCall(expr); ok {
+		inner, how = arg, fn+"()"
+	} else if _, subscripts := phpPatterns.TrailingSubscripts(expr, 0); len(subscripts) > 0 {
+		// Drop the last subscript only, so that $_GET['a']['b'][0] keeps its key
+		inner, subscripts = phpPatterns.TrailingSubscripts(expr, len(subscripts)-1)
+		how = subscripts[0]
+	} else {
+		return unknown
+	}
+	if inner == "" {
+		return unknown
+	}
+
+	parsed := e.parseExpression(inner)
+	if parsed.Type == ExprTypeUnknown {
+		return unknown
+	}
+	parsed.RawExpr = unknown.RawExpr
+	parsed.Truncation = append([]string{how}, parsed.Truncation...)
 	return parsed
 }
 
@@ -1127,7 +1562,7 @@ func (e *ExecutionEngine) parseChainedExpression(expr string) *ParsedExpression
 	}
 
 	basePart := expr[1:varNameEnd] // Remove $ prefix
-	if !patterns.WordPattern.MatchString(basePart) {
+	if !e.match(patterns.WordPattern).MatchString(basePart) {
 		return nil
 	}
 	varName := "$" + basePart
@@ -1161,7 +1596,7 @@ func (e *ExecutionEngine) parseChainedExpression(expr string) *ParsedExpression
 
 		// Try property with key: ->property['key']
 		if !matched {
-			if matches := propWithKeyPattern.FindStringSubmatch(remainder); len(matches) >= 3 {
+			if matches := e.match(propWithKeyPattern).FindStringSubmatch(remainder); len(matches) >= 3 {
 				step.Type = ExprTypePropertyAccess
 				step.Name = matches[1]
 				step.AccessKey = matches[2]
@@ -1173,7 +1608,7 @@ func (e *ExecutionEngine) parseChainedExpression(expr string) *ParsedExpression
 
 		// Try simple property: ->property
 		if !matched {
-			if matches := propPattern.FindStringSubmatch(remainder); len(matches) >= 2 {
+			if matches := e.match(propPattern).FindStringSubmatch(remainder); len(matches) >= 2 {
 				step.Type = ExprTypePropertyAccess
 				step.Name = matches[1]
 				steps = append(steps, step)
@@ -1307,7 +1742,7 @@ func (e *ExecutionEngine) extractMethodCall(expr string) (string, string, string
 
 	varName := expr[:arrowIdx]
 	// Validate variable name: $word
-	if !patterns.DollarVariablePattern.MatchString(varName) {
+	if !e.match(patterns.DollarVariablePattern).MatchString(varName) {
 		return "", "", "", false
 	}
 
@@ -1320,7 +1755,7 @@ func (e *ExecutionEngine) extractMethodCall(expr string) (string, string, string
 
 	methodName := remainder[:parenIdx]
 	// Validate method name: word characters only
-	if !patterns.WordPattern.MatchString(methodName) {
+	if !e.match(patterns.WordPattern).MatchString(methodName) {
 		return "", "", "", false
 	}
 
@@ -1360,7 +1795,7 @@ func (e *ExecutionEngine) extractStaticMethodCall(expr string) (string, string,
 	}
 
 	className := expr[:colonIdx]
-	if !patterns.WordPattern.MatchString(className) {
+	if !e.match(patterns.WordPattern).MatchString(className) {
 		return "", "", "", false
 	}
 
@@ -1373,7 +1808,7 @@ func (e *ExecutionEngine) extractStaticMethodCall(expr string) (string, string,
 	}
 
 	methodName := remainder[:parenIdx]
-	if !patterns.WordPattern.MatchString(methodName) {
+	if !e.match(patterns.WordPattern).MatchString(methodName) {
 		return "", "", "", false
 	}
 
@@ -1413,7 +1848,7 @@ func (e *ExecutionEngine) extractChainMethodCall(s string) (string, string, int,
 	}
 
 	methodName := remainder[:parenIdx]
-	if !patterns.WordPattern.MatchString(methodName) {
+	if !e.match(patterns.WordPattern).MatchString(methodName) {
 		return "", "", 0, false
 	}
 
@@ -1478,13 +1913,30 @@ func findMatchingParen(s string) (int, bool) {
 
 // traceMethodCall traces a method call expression like $mybb->get_input('timezone')
 func (e *ExecutionEngine) traceMethodCall(parsed *ParsedExpression, classDef *types.ClassDef, classFile string, instFile string, instLine int, flow *PropertyFlow) (*PropertyFlow, error) {
+	// Calls on abstract classes and interfaces run a subclass implementation
+	if base := dispatchBase(parsed, classDef); base != "" {
+		if impls := e.concreteImplementations(base, parsed.MethodName); len(impls) > 0 {
+			return e.traceSubclassMethodCalls(parsed, base, impls, instFile, instLine, flow)
+		}
+	}
+	return e.traceResolvedMethodCall(parsed, classDef, classFile, instFile, instLine, flow)
+}
+
+// traceResolvedMethodCall traces a method call on the class declaring the method
+func (e *ExecutionEngine) traceResolvedMethodCall(parsed *ParsedExpression, classDef *types.ClassDef, classFile string, instFile string, instLine int, flow *PropertyFlow) (*PropertyFlow, error) {
 	flow.MethodName = parsed.MethodName
 	flow.AccessKey = parsed.AccessKey
 
 	// Find the method definition
 	methodDef, ok := classDef.Methods[parsed.MethodName]
 	if !ok {
-		return nil, fmt.Errorf("method %s not found in class %s", parsed.MethodName, parsed.ClassName)
+		return nil, &TraceError{
+			Kind:       ErrMethodNotFound,
+			Expression: flow.Expression,
+			ClassName:  parsed.ClassName,
+			Member:     parsed.MethodName,
+			Message:    fmt.Sprintf("method %s not found in class %s", parsed.MethodName, parsed.ClassName),
+		}
 	}
 
 	// Step 1: Show instantiation
@@ -1562,7 +2014,7 @@ func (e *ExecutionEngine) traceMethodCall(parsed *ParsedExpression, classDef *ty
 			// Trace constructor to see how property is populated
 			if classDef.Constructor != nil {
 				e.currentDepth = 0
-				constructorFlows := e.traceConstructor(classDef, classFile, propName, parsed.AccessKey)
+				constructorFlows := e.traceConstructor(classDef, classFile, propName, parsed.AccessKey, flow)
 
 				// Renumber steps
 				for i := range constructorFlows {
@@ -1624,7 +2076,13 @@ func (e *ExecutionEngine) tracePropertyAccessExpr(parsed *ParsedExpression, clas
 		if magicInfo != nil {
 			return e.traceMagicProperty(parsed, classDef, classFile, magicInfo, flow)
 		}
-		return nil, fmt.Errorf("property %s not found in class %s", parsed.PropertyName, parsed.ClassName)
+		return nil, &TraceError{
+			Kind:       ErrPropertyNotFound,
+			Expression: flow.Expression,
+			ClassName:  parsed.ClassName,
+			Member:     parsed.PropertyName,
+			Message:    fmt.Sprintf("property %s not found in class %s", parsed.PropertyName, parsed.ClassName),
+		}
 	}
 
 	// Add step for property initialization
@@ -1652,7 +2110,7 @@ func (e *ExecutionEngine) tracePropertyAccessExpr(parsed *ParsedExpression, clas
 	// Analyze the constructor
 	if classDef.Constructor != nil {
 		e.currentDepth = 0
-		constructorFlows := e.traceConstructor(classDef, classFile, parsed.PropertyName, parsed.AccessKey)
+		constructorFlows := e.traceConstructor(classDef, classFile, parsed.PropertyName, parsed.AccessKey, flow)
 		flow.Steps = append(flow.Steps, constructorFlows...)
 
 		// Extract ultimate sources from constructor
@@ -1662,7 +2120,7 @@ func (e *ExecutionEngine) tracePropertyAccessExpr(parsed *ParsedExpression, clas
 	// PHASE 1.2: Trace EXTERNAL method calls made after instantiation
 	// This handles cases like: $mybb->parse_cookies() called in init.php:210
 	if instFile != "" {
-		externalFlows := e.traceExternalCalls(parsed.VarName, instFile, instLine, parsed.PropertyName, parsed.AccessKey, classDef, classFile)
+		externalFlows := e.traceExternalCalls(parsed.VarName, instFile, instLine, parsed.PropertyName, parsed.AccessKey, classDef, classFile, flow)
 		if len(externalFlows) > 0 {
 			// Renumber steps
 			for i := range externalFlows {
@@ -1678,17 +2136,11 @@ func (e *ExecutionEngine) tracePropertyAccessExpr(parsed *ParsedExpression, clas
 
 // traceExternalCalls finds and traces method calls made on a variable AFTER its instantiation
 // This is critical for cases like: $mybb = new MyBB(); ... $mybb->parse_cookies();
-func (e *ExecutionEngine) traceExternalCalls(varName string, instFile string, instLine int, targetProperty string, accessKey string, classDef *types.ClassDef, classFile string) []FlowStep {
+func (e *ExecutionEngine) traceExternalCalls(varName string, instFile string, instLine int, targetProperty string, accessKey string, classDef *types.ClassDef, classFile string, flow *PropertyFlow) []FlowStep {
 	var steps []FlowStep
 
-	// Get the instantiation file content
-	content, ok := e.fileContents[instFile]
-	if !ok {
-		return steps
-	}
-
-	// Parse the file to find method calls on this variable
-	root, ok := e.parsedFiles[instFile]
+	// Get the instantiation file's AST to find method calls on this variable
+	root, content, ok := e.parsedFile(instFile)
 	if !ok {
 		return steps
 	}
@@ -1716,7 +2168,7 @@ func (e *ExecutionEngine) traceExternalCalls(varName string, instFile string, in
 
 			// Trace into this method
 			e.currentDepth = 0
-			methodSteps := e.traceMethod(classDef, methodDef, classFile, targetProperty, accessKey, mc.args)
+			methodSteps := e.traceMethod(classDef, methodDef, classFile, targetProperty, accessKey, mc.args, flow)
 			steps = append(steps, methodSteps...)
 		}
 	}
@@ -1819,7 +2271,7 @@ func (e *ExecutionEngine) analyzeMethodReturns(classDef *types.ClassDef, method
 	body := method.BodySource
 
 	// Find all return statements
-	returnMatches := patterns.ReturnStatementPattern.FindAllStringSubmatch(body, -1)
+	returnMatches := e.match(patterns.ReturnStatementPattern).FindAllStringSubmatch(body, -1)
 
 	for _, match := range returnMatches {
 		if len(match) >= 2 {
@@ -1833,7 +2285,7 @@ func (e *ExecutionEngine) analyzeMethodReturns(classDef *types.ClassDef, method
 
 			// PHASE 2.1: Check if it returns TYPE-CASTED $this->property[$param]
 			// Pattern: (int)$this->property[$paramName] or (float)$this->... etc.
-			if propMatch := patterns.TypeCastPropertyReturnPattern.FindStringSubmatch(returnExpr); len(propMatch) >= 4 {
+			if propMatch := e.match(patterns.TypeCastPropertyReturnPattern).FindStringSubmatch(returnExpr); len(propMatch) >= 4 {
 				info.ReturnsProperty = true
 				info.PropertyName = propMatch[2] // property name
 				paramName := propMatch[3]        // param used as key
@@ -1851,7 +2303,7 @@ func (e *ExecutionEngine) analyzeMethodReturns(classDef *types.ClassDef, method
 			// Check if it returns $this->property[$param] (without type cast)
 			// Pattern: $this->property[$paramName]
 			if !info.ReturnsProperty {
-				if propMatch := patterns.PropertyWithParamKeyPattern.FindStringSubmatch(returnExpr); len(propMatch) >= 3 {
+				if propMatch := e.match(patterns.PropertyWithParamKeyPattern).FindStringSubmatch(returnExpr); len(propMatch) >= 3 {
 					info.ReturnsProperty = true
 					info.PropertyName = propMatch[1]
 					paramName := propMatch[2]
@@ -1870,7 +2322,7 @@ func (e *ExecutionEngine) analyzeMethodReturns(classDef *types.ClassDef, method
 			// PHASE 2.2: Check for null coalescing pattern
 			// Pattern: $this->property[$param] ?? $default
 			if !info.ReturnsProperty {
-				if propMatch := patterns.NullCoalescePropertyPattern.FindStringSubmatch(returnExpr); len(propMatch) >= 3 {
+				if propMatch := e.match(patterns.NullCoalescePropertyPattern).FindStringSubmatch(returnExpr); len(propMatch) >= 3 {
 					info.ReturnsProperty = true
 					info.PropertyName = propMatch[1]
 					paramName := propMatch[2]
@@ -1888,7 +2340,7 @@ func (e *ExecutionEngine) analyzeMethodReturns(classDef *types.ClassDef, method
 			// PHASE 2.2: Check for ternary isset pattern
 			// Pattern: isset($this->property[$param]) ? $this->property[$param] : default
 			if !info.ReturnsProperty {
-				if propMatch := patterns.TernaryIssetPattern.FindStringSubmatch(returnExpr); len(propMatch) >= 5 {
+				if propMatch := e.match(patterns.TernaryIssetPattern).FindStringSubmatch(returnExpr); len(propMatch) >= 5 {
 					// Verify both property refs match
 					if propMatch[1] == propMatch[3] && propMatch[2] == propMatch[4] {
 						info.ReturnsProperty = true
@@ -1908,7 +2360,7 @@ func (e *ExecutionEngine) analyzeMethodReturns(classDef *types.ClassDef, method
 
 			// Check if it returns $this->property directly
 			if !info.ReturnsProperty {
-				if propMatch := patterns.DirectPropertyReturnPattern.FindStringSubmatch(returnExpr); len(propMatch) >= 2 {
+				if propMatch := e.match(patterns.DirectPropertyReturnPattern).FindStringSubmatch(returnExpr); len(propMatch) >= 2 {
 					info.ReturnsProperty = true
 					info.PropertyName = propMatch[1]
 				}
@@ -1949,21 +2401,24 @@ func (e *ExecutionEngine) formatParams(params []types.ParameterDef) string {
 // This is fully universal - no framework-specific hints or assumptions
 func (e *ExecutionEngine) findInstantiation(varName string, contextFile string) (className, filePath string, line int) {
 	// First check the context file (most likely location)
-	if root, ok := e.parsedFiles[contextFile]; ok {
-		if content, ok := e.fileContents[contextFile]; ok {
-			className, line = e.findInstantiationInAST(root, content, varName)
-			if className != "" {
-				return className, contextFile, line
-			}
+	if root, content, ok := e.parsedFile(contextFile); ok {
+		className, line = e.findInstantiationInAST(root, content, varName)
+		if className != "" {
+			return className, contextFile, line
 		}
 	}
 
-	// Search ALL files for the instantiation - no assumptions about file names
-	for file, root := range e.parsedFiles {
+	// Search ALL files for the instantiation - no assumptions about file names.
+	// Only files mentioning the variable are parsed.
+	name := []byte(strings.TrimPrefix(varName, "$"))
+	for _, file := range e.files {
 		if file == contextFile {
 			continue // Already checked
 		}
-		if content, ok := e.fileContents[file]; ok {
+		if content, ok := e.fileContent(file); !ok || !bytes.Contains(content, name) {
+			continue
+		}
+		if root, content, ok := e.parsedFile(file); ok {
 			className, line = e.findInstantiationInAST(root, content, varName)
 			if className != "" {
 				return className, file, line
@@ -2007,7 +2462,7 @@ func (e *ExecutionEngine) findInstantiationInAST(root *sitter.Node, source []byt
 		globalsMatch := false
 		if !directMatch {
 			// Uses centralized pattern from phpPatterns
-			if matches := phpPatterns.GlobalsPattern.FindStringSubmatch(leftText); len(matches) >= 2 {
+			if matches := e.match(phpPatterns.GlobalsPattern).FindStringSubmatch(leftText); len(matches) >= 2 {
 				if matches[1] == varNameWithoutDollar {
 					globalsMatch = true
 				}
@@ -2032,7 +2487,7 @@ func (e *ExecutionEngine) findInstantiationInAST(root *sitter.Node, source []byt
 		// Check for DI container pattern: $var = $container->get('service')
 		// Uses centralized pattern from phpPatterns
 		rightText := getNodeText(right, source)
-		if phpPatterns.DIContainerPattern.MatchString(rightText) {
+		if e.match(phpPatterns.DIContainerPattern).MatchString(rightText) {
 			// Found DI container pattern - look for type hint above
 			assignLine := int(assign.StartPoint().Row)
 			typeHintClass := e.findTypeHintAboveLine(source, assignLine, varNameWithoutDollar)
@@ -2040,7 +2495,7 @@ func (e *ExecutionEngine) findInstantiationInAST(root *sitter.Node, source []byt
 				return typeHintClass, assignLine + 1
 			}
 			// If no type hint, return the service name as a hint
-			if matches := phpPatterns.DIContainerPattern.FindStringSubmatch(rightText); len(matches) >= 2 {
+			if matches := e.match(phpPatterns.DIContainerPattern).FindStringSubmatch(rightText); len(matches) >= 2 {
 				serviceName := matches[1]
 				return fmt.Sprintf("[DI:%s]", serviceName), assignLine + 1
 			}
@@ -2050,6 +2505,14 @@ func (e *ExecutionEngine) findInstantiationInAST(root *sitter.Node, source []byt
 	return "", 0
 }
 
+// diService returns the service name of a "[DI:service]" instantiation
+func diService(className string) (string, bool) {
+	if strings.HasPrefix(className, "[DI:") && strings.HasSuffix(className, "]") {
+		return className[len("[DI:") : len(className)-1], true
+	}
+	return "", false
+}
+
 // findTypeHintAboveLine searches for PHPDoc @var type hints above a line
 // Pattern: /* @var $varname \namespace\classname */ or /** @var \class $var */
 func (e *ExecutionEngine) findTypeHintAboveLine(source []byte, targetLine int, varName string) string {
@@ -2072,7 +2535,7 @@ func (e *ExecutionEngine) findTypeHintAboveLine(source []byte, targetLine int, v
 	for i := targetLine - 1; i >= startLine; i-- {
 		line := lines[i]
 		for _, pattern := range typeHintPatterns {
-			if matches := pattern.FindStringSubmatch(line); len(matches) >= 2 {
+			if matches := e.match(pattern).FindStringSubmatch(line); len(matches) >= 2 {
 				// Extract class name from fully qualified name
 				fqn := matches[1]
 				parts := strings.Split(fqn, "\\")
@@ -2132,7 +2595,7 @@ func (e *ExecutionEngine) findClassDefinition(className string) (*types.ClassDef
 }
 
 // traceConstructor traces through a constructor to find property population
-func (e *ExecutionEngine) traceConstructor(classDef *types.ClassDef, classFile string, targetProperty string, accessKey string) []FlowStep {
+func (e *ExecutionEngine) traceConstructor(classDef *types.ClassDef, classFile string, targetProperty string, accessKey string, flow *PropertyFlow) []FlowStep {
 	var steps []FlowStep
 
 	if classDef.Constructor == nil {
@@ -2157,7 +2620,7 @@ func (e *ExecutionEngine) traceConstructor(classDef *types.ClassDef, classFile s
 
 	// Look for method calls that might populate the property
 	// Parse: $this->methodName($arg) - uses centralized pattern
-	methodCalls := phpPatterns.ThisMethodCallPattern.FindAllStringSubmatch(constructor.BodySource, -1)
+	methodCalls := e.match(phpPatterns.ThisMethodCallPattern).FindAllStringSubmatch(constructor.BodySource, -1)
 
 	for _, call := range methodCalls {
 		methodName := call[1]
@@ -2166,7 +2629,7 @@ func (e *ExecutionEngine) traceConstructor(classDef *types.ClassDef, classFile s
 		// Check if this method populates our target property
 		if methodDef, ok := classDef.Methods[methodName]; ok {
 			// Trace into the method FIRST to see if it affects target property
-			methodSteps := e.traceMethod(classDef, methodDef, classFile, targetProperty, accessKey, methodArgs)
+			methodSteps := e.traceMethod(classDef, methodDef, classFile, targetProperty, accessKey, methodArgs, flow)
 
 			// Only add method call step if method actually affects the target property
 			if len(methodSteps) > 0 {
@@ -2195,19 +2658,28 @@ func (e *ExecutionEngine) traceConstructor(classDef *types.ClassDef, classFile s
 	// This is not a recursive call, just analyzing the current body
 	savedDepth := e.currentDepth
 	e.currentDepth = 0
-	directSteps := e.traceMethod(classDef, constructorAsMethod, classFile, targetProperty, accessKey, "")
+	directSteps := e.traceMethod(classDef, constructorAsMethod, classFile, targetProperty, accessKey, "", flow)
 	e.currentDepth = savedDepth
 	steps = append(steps, directSteps...)
 
 	return steps
 }
 
-// traceMethod traces through a method to find property assignments
-func (e *ExecutionEngine) traceMethod(classDef *types.ClassDef, method *types.MethodDef, classFile string, targetProperty string, accessKey string, callArgs string) []FlowStep {
+// traceMethod traces through a method to find property assignments. A depth
+// cutoff is reported on flow, the flow of the trace in progress.
+func (e *ExecutionEngine) traceMethod(classDef *types.ClassDef, method *types.MethodDef, classFile string, targetProperty string, accessKey string, callArgs string, flow *PropertyFlow) []FlowStep {
 	var steps []FlowStep
 
+	e.yielder.Tick()
 	e.currentDepth++
+	e.budget.ReachDepth(min(e.currentDepth, e.maxDepth))
 	if e.currentDepth > e.maxDepth {
+		flow.Warnings = append(flow.Warnings, types.AnalysisWarning{
+			Category: types.WarningDepthCutoff,
+			Message:  fmt.Sprintf("tracing stopped at max depth %d in %s::%s()", e.maxDepth, classDef.Name, method.Name),
+			FilePath: classFile,
+			Line:     method.Line,
+		})
 		return steps
 	}
 
@@ -2221,7 +2693,7 @@ func (e *ExecutionEngine) traceMethod(classDef *types.ClassDef, method *types.Me
 	// Pattern: foreach($_SUPERGLOBAL as $key => $val)
 	// This handles methods like parse_cookies() that don't take parameters
 	// Uses centralized pattern from common
-	superglobalMatches := common.SuperglobalForeachPattern.FindAllStringSubmatch(body, -1)
+	superglobalMatches := e.match(common.SuperglobalForeachPattern).FindAllStringSubmatch(body, -1)
 
 	for _, match := range superglobalMatches {
 		superglobalName := match[1] // e.g., "$_COOKIE"
@@ -2241,7 +2713,7 @@ func (e *ExecutionEngine) traceMethod(classDef *types.ClassDef, method *types.Me
 			// Look for property assignment inside the loop FIRST
 			// Pattern: $this->property[$key] = $val
 			propAssignPattern := patterns.BuildPropertyAssignInLoopPattern(keyVar, valVar)
-			propMatches := propAssignPattern.FindAllStringSubmatch(body, -1)
+			propMatches := e.match(propAssignPattern).FindAllStringSubmatch(body, -1)
 
 			for _, propMatch := range propMatches {
 				assignedProperty := propMatch[1]
@@ -2282,7 +2754,7 @@ func (e *ExecutionEngine) traceMethod(classDef *types.ClassDef, method *types.Me
 
 	// Look for foreach loops iterating over the method parameter
 	// Pattern: foreach($array as $key => $val) - uses centralized pattern
-	foreachMatches := phpPatterns.ForeachPattern.FindAllStringSubmatch(body, -1)
+	foreachMatches := e.match(phpPatterns.ForeachPattern).FindAllStringSubmatch(body, -1)
 
 	for _, match := range foreachMatches {
 		arrayVar := match[1]
@@ -2299,7 +2771,7 @@ func (e *ExecutionEngine) traceMethod(classDef *types.ClassDef, method *types.Me
 			// Look for property assignment inside the loop FIRST
 			// Pattern: $this->property[$key] = $val
 			propAssignPattern := patterns.BuildPropertyAssignInLoopPattern(keyVar, valVar)
-			propMatches := propAssignPattern.FindAllStringSubmatch(body, -1)
+			propMatches := e.match(propAssignPattern).FindAllStringSubmatch(body, -1)
 
 			for _, propMatch := range propMatches {
 				assignedProperty := propMatch[1]
@@ -2342,7 +2814,7 @@ func (e *ExecutionEngine) traceMethod(classDef *types.ClassDef, method *types.Me
 	// Pattern: $this->property = $something
 	// Uses centralized pattern builder from phpPatterns
 	directAssignPattern := phpPatterns.BuildDirectAssignPattern(targetProperty)
-	directMatches := directAssignPattern.FindAllStringSubmatch(body, -1)
+	directMatches := e.match(directAssignPattern).FindAllStringSubmatch(body, -1)
 
 	for _, match := range directMatches {
 		source := strings.TrimSpace(match[1])
@@ -2364,7 +2836,7 @@ func (e *ExecutionEngine) traceMethod(classDef *types.ClassDef, method *types.Me
 			// Check if superglobal is used in a condition and property is assigned nearby
 			// Pattern: if($_SUPERGLOBAL[anything]) - uses centralized pattern builder
 			condPattern := phpPatterns.BuildConditionalPattern(sg)
-			if condMatches := condPattern.FindStringSubmatch(body); len(condMatches) >= 2 {
+			if condMatches := e.match(condPattern).FindStringSubmatch(body); len(condMatches) >= 2 {
 				superglobalKey := condMatches[1]
 				steps = append(steps, FlowStep{
 					StepNumber:  len(steps) + 10,
@@ -2441,7 +2913,7 @@ func (flow *PropertyFlow) GenerateFlowReport() string {
 
 	for _, step := range flow.Steps {
 		sb.WriteString(fmt.Sprintf("Step %d: %s\n", step.StepNumber, step.Description))
-		sb.WriteString(fmt.Sprintf("   Code: %s\n", step.Code))
+		sb.WriteString(fmt.Sprintf("   Code: %s\n", step.CodeText()))
 		if step.FilePath != "" && step.Line > 0 {
 			sb.WriteString(fmt.Sprintf("   Location: %s:%d\n", step.FilePath, step.Line))
 		}
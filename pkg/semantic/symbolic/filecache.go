@@ -4,9 +4,9 @@ package symbolic
 import (
 	"container/list"
 	"context"
-	"os"
 	"sync"
 
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/php"
 )
@@ -20,8 +20,10 @@ type LRUFileCache struct {
 
 	entries   map[string]*list.Element
 	evictList *list.List
+	virtual   map[string][]byte // Content of files added with Put, kept across eviction
 	mu        sync.RWMutex
 	parser    *sitter.Parser
+	sandbox   *types.Sandbox // Directories files are loaded from (nil = any)
 
 	// Stats
 	hits   int64
@@ -50,6 +52,7 @@ func NewLRUFileCache(maxEntries int) *LRUFileCache {
 		maxMemory:  64 * 1024 * 1024, // 64MB default max memory (reduced from 512MB for multi-thread)
 		entries:    make(map[string]*list.Element, maxEntries),
 		evictList:  list.New(),
+		virtual:    make(map[string][]byte),
 		parser:     parser,
 	}
 }
@@ -79,8 +82,8 @@ func (c *LRUFileCache) Get(filePath string) (*sitter.Node, []byte, error) {
 
 	c.misses++
 
-	// Lazy load from disk
-	content, err := os.ReadFile(filePath)
+	// Lazy load from disk, or reparse a file added with Put
+	content, err := c.load(filePath)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -112,6 +115,65 @@ func (c *LRUFileCache) Get(filePath string) (*sitter.Node, []byte, error) {
 	return entry.root, entry.content, nil
 }
 
+// Put parses content and caches it as the file's current state, replacing a
+// cached entry; used for files whose content is not (yet) on disk. The
+// content is kept after the AST is evicted and reparsed on the next Get.
+func (c *LRUFileCache) Put(filePath string, content []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tree, err := c.parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return err
+	}
+	c.virtual[filePath] = content
+	if elem, ok := c.entries[filePath]; ok {
+		c.removeElement(elem)
+	}
+
+	memUsage := estimateFileMemory(content)
+	for len(c.entries) >= c.maxEntries || (c.currentMem+memUsage > c.maxMemory && c.evictList.Len() > 0) {
+		c.evictOldest()
+	}
+
+	entry := &fileCacheEntry{
+		key:     filePath,
+		root:    tree.RootNode(),
+		tree:    tree,
+		content: content,
+		memory:  memUsage,
+	}
+	c.entries[filePath] = c.evictList.PushFront(entry)
+	c.currentMem += memUsage
+	return nil
+}
+
+// Content returns a file's content from the cache, or reads it from disk
+// without parsing or caching it. Scans over every file use it so that
+// files they only search through do not evict the ASTs in use.
+func (c *LRUFileCache) Content(filePath string) ([]byte, error) {
+	c.mu.RLock()
+	elem, cached := c.entries[filePath]
+	content, virtual := c.virtual[filePath]
+	c.mu.RUnlock()
+	switch {
+	case cached:
+		return elem.Value.(*fileCacheEntry).content, nil
+	case virtual:
+		return content, nil
+	}
+	return c.sandbox.ReadFile(filePath)
+}
+
+// load returns the content of a file added with Put, or reads it from disk.
+// The caller holds c.mu.
+func (c *LRUFileCache) load(filePath string) ([]byte, error) {
+	if content, ok := c.virtual[filePath]; ok {
+		return content, nil
+	}
+	return c.sandbox.ReadFile(filePath)
+}
+
 // GetContent retrieves file content with lazy loading
 func (c *LRUFileCache) GetContent(filePath string) ([]byte, error) {
 	_, content, err := c.Get(filePath)
@@ -138,35 +200,34 @@ func (c *LRUFileCache) evictOldest() {
 	if elem == nil {
 		return
 	}
-	entry := elem.Value.(*fileCacheEntry)
+	c.removeElement(elem)
+}
 
-	// Close the tree to free AST memory
+// removeElement drops an entry and closes its tree to free AST memory
+func (c *LRUFileCache) removeElement(elem *list.Element) {
+	entry := elem.Value.(*fileCacheEntry)
 	if entry.tree != nil {
 		entry.tree.Close()
 	}
-
 	c.evictList.Remove(elem)
 	delete(c.entries, entry.key)
 	c.currentMem -= entry.memory
 }
 
-// Remove removes a specific file from the cache - O(1)
+// Remove removes a specific file from the cache, including content added
+// with Put - O(1)
 func (c *LRUFileCache) Remove(filePath string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	delete(c.virtual, filePath)
+
 	if elem, exists := c.entries[filePath]; exists {
-		entry := elem.Value.(*fileCacheEntry)
-		if entry.tree != nil {
-			entry.tree.Close()
-		}
-		c.evictList.Remove(elem)
-		delete(c.entries, filePath)
-		c.currentMem -= entry.memory
+		c.removeElement(elem)
 	}
 }
 
-// Clear removes all entries from the cache
+// Clear removes all entries from the cache; content added with Put is kept
 func (c *LRUFileCache) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
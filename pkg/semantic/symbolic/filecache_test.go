@@ -0,0 +1,198 @@
+package symbolic
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// writeCodebase writes count PHP files padded to roughly 20KB each: the first
+// assigns $target, the last instantiates $req
+func writeCodebase(t *testing.T, count int) []string {
+	t.Helper()
+	dir := t.TempDir()
+	padding := strings.Repeat("$filler = 'padding padding padding padding';\n", 450)
+	var paths []string
+	for i := 0; i < count; i++ {
+		code := "<?php\n" + padding
+		switch i {
+		case 0:
+			code += "$target = $_GET['t'];\n"
+		case count - 1:
+			code += "class Request { public $input; function __construct() { $this->input = $_GET; } }\n$req = new Request();\n"
+		}
+		path := filepath.Join(dir, fmt.Sprintf("file%02d.php", i))
+		if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestFileMemoryBoundedByCache(t *testing.T) {
+	const files, cacheSize = 40, 4
+	paths := writeCodebase(t, files)
+	content, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	maxMem := int64(cacheSize+1) * estimateFileMemory(content)
+
+	tests := []struct {
+		name   string
+		add    func(e *ExecutionEngine, path string)
+		legacy bool
+	}{
+		{
+			name: "AddFile",
+			add: func(e *ExecutionEngine, path string) {
+				if err := e.AddFile(path, nil); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "AddParsedFile shim",
+			add: func(e *ExecutionEngine, path string) {
+				content, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				e.AddParsedFile(path, nil, content)
+			},
+			legacy: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutionEngineWithCacheSize(cacheSize)
+			for _, path := range paths {
+				tt.add(e, path)
+			}
+
+			if class, file, _ := e.findInstantiation("$req", paths[0]); class != "Request" || file != paths[files-1] {
+				t.Errorf("instantiation = %s in %s, want Request in %s", class, file, paths[files-1])
+			}
+			flow, err := e.TracePropertyAccess("$target", paths[files-1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(flow.Sources) != 1 || flow.Sources[0].Expression != "$_GET['t']" {
+				t.Errorf("sources = %+v, want $_GET['t']", flow.Sources)
+			}
+
+			if size := e.fileCache.Size(); size > cacheSize {
+				t.Errorf("cached files = %d, want at most %d", size, cacheSize)
+			}
+			if _, _, mem := e.FileCacheStats(); mem > maxMem {
+				t.Errorf("cache memory = %d, want at most %d", mem, maxMem)
+			}
+
+			var deprecated bool
+			for _, w := range flow.Warnings {
+				deprecated = deprecated || w.Category == types.WarningDeprecatedAPI
+			}
+			if deprecated != tt.legacy {
+				t.Errorf("deprecated_api warning = %v, want %v", deprecated, tt.legacy)
+			}
+		})
+	}
+}
+
+func TestSetSandbox(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "app")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	outside := filepath.Join(dir, "config.php")
+	if err := os.WriteFile(outside, []byte("<?php\n$target = $_GET['t'];\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	index := filepath.Join(root, "index.php")
+	if err := os.WriteFile(index, []byte("<?php\necho $target;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "config.php")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		roots      []string
+		wantSource bool
+	}{
+		{"no sandbox", nil, true},
+		{"symlink outside the root", []string{root}, false},
+		{"root containing the target", []string{dir}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutionEngine()
+			e.SetSandbox(tt.roots)
+			for _, path := range []string{index, link} {
+				if err := e.AddFile(path, nil); err != nil {
+					t.Fatal(err)
+				}
+			}
+			flow, err := e.TracePropertyAccess("$target", index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(flow.Sources) > 0; got != tt.wantSource {
+				t.Errorf("sources = %+v, want found = %v", flow.Sources, tt.wantSource)
+			}
+			_, err = e.GetFileContent(link)
+			if escaped := errors.Is(err, types.ErrSandboxEscape); escaped == tt.wantSource {
+				t.Errorf("GetFileContent(%s) err = %v, want sandbox escape = %v", link, err, !tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestAddedFilesSurviveEviction(t *testing.T) {
+	files := []struct{ path, code string }{
+		{"/virtual/config.php", "<?php\n$target = $_GET['t'];\n"},
+		{"/virtual/lib.php", "<?php\n$other = 1;\n"},
+		{"/virtual/more.php", "<?php\n$more = 2;\n"},
+		{"/virtual/index.php", "<?php\necho $target;\n"},
+	}
+	tests := []struct {
+		name      string
+		cacheSize int
+		clear     bool
+	}{
+		{"all cached", len(files), false},
+		{"evicted", 2, false},
+		{"cache cleared", len(files), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutionEngineWithCacheSize(tt.cacheSize)
+			for _, f := range files {
+				addPHPFile(t, e, f.path, f.code)
+			}
+			if tt.clear {
+				e.ClearFileCache()
+			}
+			flow, err := e.TracePropertyAccess("$target", "/virtual/index.php")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(flow.Sources) != 1 || flow.Sources[0].Expression != "$_GET['t']" {
+				t.Errorf("sources = %+v, want $_GET['t']", flow.Sources)
+			}
+			if size := e.fileCache.Size(); size > tt.cacheSize {
+				t.Errorf("cached files = %d, want at most %d", size, tt.cacheSize)
+			}
+		})
+	}
+}
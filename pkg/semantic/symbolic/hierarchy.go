@@ -0,0 +1,173 @@
+package symbolic
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// classRef is a class definition and the file declaring it
+type classRef struct {
+	def  *types.ClassDef
+	file string
+}
+
+// methodImpl is the concrete implementation of a method for one subclass.
+// owner declares the method body: the subclass itself or an ancestor.
+type methodImpl struct {
+	subclass classRef
+	owner    classRef
+	method   *types.MethodDef
+}
+
+// subclassIndex returns the class hierarchy index: lowercase parent class or
+// interface name -> classes directly extending or implementing it. The index
+// is built on first use and dropped when a symbol table is added.
+func (e *ExecutionEngine) subclassIndex() map[string][]classRef {
+	if e.subclasses != nil {
+		return e.subclasses
+	}
+	e.subclasses = make(map[string][]classRef)
+	for filePath, st := range e.symbolTables {
+		for _, classDef := range st.Classes {
+			ref := classRef{def: classDef, file: filePath}
+			if classDef.Extends != "" {
+				parent := strings.ToLower(classDef.Extends)
+				e.subclasses[parent] = append(e.subclasses[parent], ref)
+			}
+			for _, iface := range classDef.Implements {
+				iface = strings.ToLower(iface)
+				e.subclasses[iface] = append(e.subclasses[iface], ref)
+			}
+		}
+	}
+	// Map iteration order is random; keep dispatch results stable
+	for _, refs := range e.subclasses {
+		sort.Slice(refs, func(i, j int) bool { return refs[i].def.Name < refs[j].def.Name })
+	}
+	return e.subclasses
+}
+
+// concreteImplementations returns, for every concrete class below baseName
+// (an abstract class or interface), the implementation of methodName it
+// runs: its own or the nearest one inherited from an intermediate class
+func (e *ExecutionEngine) concreteImplementations(baseName string, methodName string) []methodImpl {
+	index := e.subclassIndex()
+	var impls []methodImpl
+	visited := map[string]bool{strings.ToLower(baseName): true}
+	queue := []string{strings.ToLower(baseName)}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, sub := range index[name] {
+			subName := strings.ToLower(sub.def.Name)
+			if visited[subName] {
+				continue
+			}
+			visited[subName] = true
+			queue = append(queue, subName)
+
+			if sub.def.IsAbstract {
+				continue
+			}
+			if owner, method := e.resolveMethod(sub, methodName); method != nil {
+				impls = append(impls, methodImpl{subclass: sub, owner: owner, method: method})
+			}
+		}
+	}
+	return impls
+}
+
+// resolveMethod finds the concrete method a class runs for methodName by
+// walking up its extends chain
+func (e *ExecutionEngine) resolveMethod(class classRef, methodName string) (classRef, *types.MethodDef) {
+	seen := make(map[string]bool)
+	for class.def != nil && !seen[strings.ToLower(class.def.Name)] {
+		seen[strings.ToLower(class.def.Name)] = true
+		if method, ok := class.def.Methods[methodName]; ok && !method.IsAbstract {
+			return class, method
+		}
+		if class.def.Extends == "" {
+			break
+		}
+		parent, parentFile := e.findClassDefinition(class.def.Extends)
+		class = classRef{def: parent, file: parentFile}
+	}
+	return classRef{}, nil
+}
+
+// dispatchBase returns the abstract type a method call must be dispatched
+// over, or "" when classDef's own method can be traced. Calls on an interface
+// (resolved by findClassDefinition to one implementation) and calls of
+// methods that are abstract or missing in an abstract class are dispatched.
+func dispatchBase(parsed *ParsedExpression, classDef *types.ClassDef) string {
+	if !strings.EqualFold(classDef.Name, parsed.ClassName) {
+		for _, iface := range classDef.Implements {
+			if strings.EqualFold(iface, parsed.ClassName) {
+				return parsed.ClassName
+			}
+		}
+	}
+	method, ok := classDef.Methods[parsed.MethodName]
+	if classDef.IsAbstract && (!ok || method.IsAbstract) {
+		return classDef.Name
+	}
+	return ""
+}
+
+// traceSubclassMethodCalls traces a method call on an abstract class or
+// interface in every concrete subclass and merges the results. Steps and
+// sources record the subclass they were traced in.
+func (e *ExecutionEngine) traceSubclassMethodCalls(parsed *ParsedExpression, baseName string, impls []methodImpl, instFile string, instLine int, flow *PropertyFlow) (*PropertyFlow, error) {
+	flow.MethodName = parsed.MethodName
+	flow.AccessKey = parsed.AccessKey
+
+	names := make([]string, len(impls))
+	for i, impl := range impls {
+		names[i] = impl.subclass.def.Name
+	}
+	flow.Steps = append(flow.Steps, FlowStep{
+		StepNumber:  len(flow.Steps) + 1,
+		Description: fmt.Sprintf("%s is abstract: %s() traced in %d concrete subclass(es): %s", baseName, parsed.MethodName, len(impls), strings.Join(names, ", ")),
+		Code:        fmt.Sprintf("%s->%s()", parsed.VarName, parsed.MethodName),
+		FilePath:    instFile,
+		Line:        instLine,
+		Type:        "dispatch",
+	})
+
+	var firstErr error
+	traced := 0
+	for _, impl := range impls {
+		subParsed := *parsed
+		subParsed.ClassName = impl.subclass.def.Name
+		sub := &PropertyFlow{Expression: flow.Expression}
+		if _, err := e.traceResolvedMethodCall(&subParsed, impl.owner.def, impl.owner.file, instFile, instLine, sub); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		traced++
+
+		subclass := impl.subclass.def.Name
+		for _, step := range sub.Steps {
+			step.StepNumber = len(flow.Steps) + 1
+			step.Subclass = subclass
+			flow.Steps = append(flow.Steps, step)
+		}
+		for _, src := range sub.Sources {
+			src.Subclass = subclass
+			flow.Sources = append(flow.Sources, src)
+		}
+		flow.Warnings = append(flow.Warnings, sub.Warnings...)
+		if flow.PropertyName == "" {
+			flow.PropertyName = sub.PropertyName
+		}
+	}
+	if traced == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return flow, nil
+}
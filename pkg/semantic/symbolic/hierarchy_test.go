@@ -0,0 +1,126 @@
+package symbolic
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestAbstractDispatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		classes  string
+		receiver string   // Class the receiver is declared as
+		want     []string // Subclass: source expression, sorted
+		dispatch bool     // A dispatch step is recorded
+	}{
+		{
+			name: "abstract method",
+			classes: `<?php
+abstract class InputSource {
+    abstract function read();
+}
+class QuerySource extends InputSource {
+    function read() { return $_GET['q']; }
+}
+class FormSource extends InputSource {
+    function read() { return $_POST['q']; }
+}
+`,
+			receiver: "InputSource",
+			want:     []string{"FormSource: $_POST", "QuerySource: $_GET"},
+			dispatch: true,
+		},
+		{
+			name: "interface",
+			classes: `<?php
+interface InputSource {
+    function read();
+}
+class QuerySource implements InputSource {
+    function read() { return $_GET['q']; }
+}
+class CookieSource implements InputSource {
+    function read() { return $_COOKIE['q']; }
+}
+`,
+			receiver: "InputSource",
+			want:     []string{"CookieSource: $_COOKIE", "QuerySource: $_GET"},
+			dispatch: true,
+		},
+		{
+			name: "implementation inherited from an intermediate class",
+			classes: `<?php
+abstract class InputSource {
+    abstract function read();
+}
+abstract class QueryBase extends InputSource {
+    function read() { return $_GET['q']; }
+}
+class PageQuery extends QueryBase {
+}
+abstract class Unimplemented extends InputSource {
+}
+`,
+			receiver: "InputSource",
+			want:     []string{"PageQuery: $_GET"},
+			dispatch: true,
+		},
+		{
+			name: "concrete method of an abstract class",
+			classes: `<?php
+abstract class InputSource {
+    function read() { return $_GET['q']; }
+}
+class FormSource extends InputSource {
+    function read() { return $_POST['q']; }
+}
+`,
+			receiver: "InputSource",
+			want:     []string{": $_GET"},
+		},
+		{
+			name: "concrete receiver",
+			classes: `<?php
+abstract class InputSource {
+    abstract function read();
+}
+class QuerySource extends InputSource {
+    function read() { return $_GET['q']; }
+}
+class FormSource extends InputSource {
+    function read() { return $_POST['q']; }
+}
+`,
+			receiver: "QuerySource",
+			want:     []string{": $_GET"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExecutionEngine()
+			addPHPClassFile(t, e, "/app/sources.php", tt.classes)
+			addPHPFile(t, e, "/app/index.php", "<?php\n/** @var "+tt.receiver+" $src */\n$src = $container->get('input');\n")
+
+			flow, err := e.TracePropertyAccess("$src->read()", "/app/index.php")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, src := range flow.Sources {
+				got = append(got, src.Subclass+": "+src.Expression)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sources = %v, want %v", got, tt.want)
+			}
+			dispatched := false
+			for _, step := range flow.Steps {
+				dispatched = dispatched || step.Type == "dispatch"
+			}
+			if dispatched != tt.dispatch {
+				t.Errorf("dispatch step = %v, want %v (steps %+v)", dispatched, tt.dispatch, flow.Steps)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package symbolic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePartialRead(t *testing.T) {
+	tests := []struct {
+		expr       string
+		typ        ExpressionType
+		name       string
+		key        string
+		truncation []string
+	}{
+		{"$_GET['id'][0]", ExprTypeSuperglobal, "$_GET", "id", []string{"[0]"}},
+		{"$_GET['filter']['name'][0]", ExprTypeSuperglobal, "$_GET", "filter", []string{"[0]", "['name']"}},
+		{"substr($_POST['token'], 0, 8)", ExprTypeSuperglobal, "$_POST", "token", []string{"substr()"}},
+		{"$t[0]", ExprTypeLocalVariable, "$t", "", []string{"[0]"}},
+		{"mb_substr($t{1}, 2)", ExprTypeLocalVariable, "$t", "", []string{"mb_substr()", "{1}"}},
+		{"$_GET['id']", ExprTypeSuperglobal, "$_GET", "id", nil},
+	}
+
+	e := NewExecutionEngine()
+	for _, tt := range tests {
+		parsed := e.parseExpression(tt.expr)
+		if parsed.Type != tt.typ || parsed.VarName != tt.name || parsed.AccessKey != tt.key {
+			t.Errorf("parseExpression(%q) = %v %s[%s], want %v %s[%s]", tt.expr, parsed.Type, parsed.VarName, parsed.AccessKey, tt.typ, tt.name, tt.key)
+		}
+		if !reflect.DeepEqual(parsed.Truncation, tt.truncation) {
+			t.Errorf("parseExpression(%q).Truncation = %q, want %q", tt.expr, parsed.Truncation, tt.truncation)
+		}
+	}
+
+	flow, err := e.TracePropertyAccess("$_GET['id'][0]", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !flow.Truncated || len(flow.Sources) != 1 || flow.Steps[len(flow.Steps)-1].Type != "truncation" {
+		t.Errorf("flow = %+v, want a truncated $_GET source", flow)
+	}
+}
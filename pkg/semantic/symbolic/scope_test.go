@@ -0,0 +1,53 @@
+package symbolic
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLocalVariableScopes(t *testing.T) {
+	e := NewExecutionEngine()
+	addPHPFile(t, e, "/app/index.php", `<?php
+$token = $_COOKIE['token'];
+function session() {
+    global $token;
+    return $token;
+}
+function fixed() {
+    $token = 'fixed';
+    return $token;
+}
+`)
+
+	tests := []struct {
+		name       string
+		line       int
+		wantSource bool
+	}{
+		{"file level", 2, true},
+		{"declared global", 5, true},
+		{"local shadowing the global", 9, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow, err := e.TracePropertyAccessAt("$token", "/app/index.php", tt.line)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(flow.Sources) > 0; got != tt.wantSource {
+				t.Errorf("sources = %+v, want found = %v", flow.Sources, tt.wantSource)
+			}
+		})
+	}
+
+	// Scope trees are built lazily; concurrent lookups must not race
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.scopeAt("/app/index.php", 5, "$token")
+		}()
+	}
+	wg.Wait()
+}
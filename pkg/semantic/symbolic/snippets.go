@@ -0,0 +1,109 @@
+package symbolic
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/crc32"
+)
+
+// minCompactSnippet is the shortest step code stored as a file reference;
+// shorter code costs less than the reference
+const minCompactSnippet = 48
+
+// snippetRef locates the code of a step in its file: bytes [start, end) of
+// the file as the engine's cache holds it
+type snippetRef struct {
+	cache      *LRUFileCache
+	file       string
+	start, end int
+	sum        uint32 // Checksum of the code, to detect a file changed since
+}
+
+// SetCompactSnippets makes traces store step code found verbatim in the
+// traced files as (file, byte range) references instead of strings, read
+// back through the LRU cache when rendered (FlowStep.CodeText). This bounds
+// the memory of app-wide sweeps keeping many flows; flows used after the
+// engine or its files are gone must be materialized first (PropertyFlow.Materialize).
+func (e *ExecutionEngine) SetCompactSnippets(compact bool) {
+	e.compactSnippets = compact
+}
+
+// CodeText returns the code of the step, reading it from its file when the
+// step stores a reference. It is empty when the file changed or is gone.
+func (s FlowStep) CodeText() string {
+	if s.ref == nil {
+		return s.Code
+	}
+	content, err := s.ref.cache.Content(s.ref.file)
+	if err != nil || s.ref.end > len(content) {
+		return ""
+	}
+	code := content[s.ref.start:s.ref.end]
+	if crc32.ChecksumIEEE(code) != s.ref.sum {
+		return ""
+	}
+	return string(code)
+}
+
+// MarshalJSON encodes the step with its code read back from its reference
+func (s FlowStep) MarshalJSON() ([]byte, error) {
+	type plain FlowStep
+	p := plain(s)
+	p.Code = s.CodeText()
+	return json.Marshal(p)
+}
+
+// Materialize replaces the code references of the steps with their code, so
+// that the flow no longer depends on the engine's cache or the files
+func (f *PropertyFlow) Materialize() {
+	for i := range f.Steps {
+		if f.Steps[i].ref != nil {
+			f.Steps[i].Code = f.Steps[i].CodeText()
+			f.Steps[i].ref = nil
+		}
+	}
+}
+
+// compactSteps replaces the code of the steps of flow that occurs verbatim
+// in the step's file by a reference, searching from the step's line first
+func (e *ExecutionEngine) compactSteps(flow *PropertyFlow) {
+	for i := range flow.Steps {
+		step := &flow.Steps[i]
+		if step.ref != nil || len(step.Code) < minCompactSnippet || step.FilePath == "" {
+			continue
+		}
+		content, ok := e.fileContent(step.FilePath)
+		if !ok {
+			continue
+		}
+		code := []byte(step.Code)
+		from := lineOffset(content, step.Line)
+		start := bytes.Index(content[from:], code)
+		if start >= 0 {
+			start += from
+		} else if start = bytes.Index(content, code); start < 0 {
+			continue
+		}
+		step.ref = &snippetRef{
+			cache: e.fileCache,
+			file:  step.FilePath,
+			start: start,
+			end:   start + len(code),
+			sum:   crc32.ChecksumIEEE(code),
+		}
+		step.Code = ""
+	}
+}
+
+// lineOffset returns the byte offset of a 1-based line of content, or 0
+func lineOffset(content []byte, line int) int {
+	offset := 0
+	for n := 1; n < line; n++ {
+		next := bytes.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			return 0
+		}
+		offset += next + 1
+	}
+	return offset
+}
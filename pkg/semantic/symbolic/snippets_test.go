@@ -0,0 +1,55 @@
+package symbolic
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCompactSnippets(t *testing.T) {
+	const index = `<?php
+$incomingRequest = new Request();
+$incomingRequestCopyForTheTemplates = clone $incomingRequest;
+`
+	const code = "$incomingRequestCopyForTheTemplates = clone $incomingRequest;"
+
+	e := NewExecutionEngine()
+	e.SetCompactSnippets(true)
+	addPHPClassFile(t, e, "/app/Request.php", `<?php
+class Request {
+    public $input = array();
+    function __construct() { $this->input = $_GET; }
+}
+`)
+	addPHPFile(t, e, "/app/index.php", index)
+
+	flow, err := e.TracePropertyAccess("$incomingRequestCopyForTheTemplates->input['id']", "/app/index.php")
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := flow.Steps[0]
+	if step.Type != "clone" || step.Code != "" {
+		t.Fatalf("first step = %+v, want a clone step stored as a reference", step)
+	}
+	if got := step.CodeText(); got != code {
+		t.Errorf("CodeText() = %q, want %q", got, code)
+	}
+	if data, err := json.Marshal(step); err != nil || !strings.Contains(string(data), `"Code":"`+code+`"`) {
+		t.Errorf("JSON = %s (%v), want the code", data, err)
+	}
+	if !strings.Contains(flow.GenerateFlowReport(), "Code: "+code) {
+		t.Errorf("GenerateFlowReport does not render the code:\n%s", flow.GenerateFlowReport())
+	}
+
+	// A materialized flow outlives changes to the file; a reference does not
+	materialized := *flow
+	materialized.Steps = append([]FlowStep(nil), flow.Steps...)
+	materialized.Materialize()
+	addPHPFile(t, e, "/app/index.php", strings.Replace(index, "incomingRequest;", "otherRequest;", 1))
+	if got := materialized.Steps[0].CodeText(); got != code {
+		t.Errorf("materialized CodeText() = %q, want %q", got, code)
+	}
+	if got := flow.Steps[0].CodeText(); got != "" {
+		t.Errorf("CodeText() of a changed file = %q, want empty", got)
+	}
+}
@@ -0,0 +1,319 @@
+package symbolic
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources/patterns"
+)
+
+// statement is one logical PHP statement: its text joined onto a single line
+// and ending in ";", and the line it starts on. Control structure headers
+// are not statements: `if ($a): $x = $_GET['x']; endif;` and
+// `if ($a) $x = $_GET['x'];` both yield the statement `$x = $_GET['x'];`.
+type statement struct {
+	text string
+	line int
+}
+
+// fileStatements returns the statements of a file, splitting it once
+func (e *ExecutionEngine) fileStatements(filePath string) []statement {
+	if stmts, ok := e.statements[filePath]; ok {
+		return stmts
+	}
+	content, _ := e.fileContent(filePath)
+	stmts := splitStatements(content)
+	e.statements[filePath] = stmts
+	return stmts
+}
+
+// statementBuilder accumulates the text of a statement with the line of each byte
+type statementBuilder struct {
+	text  []byte
+	lines []int
+}
+
+// write appends a byte of code read on line; runs of whitespace, including
+// line breaks, are written as one space
+func (b *statementBuilder) write(c byte, line int) {
+	if c == '\n' || c == '\r' || c == '\t' {
+		c = ' '
+	}
+	if c == ' ' && (len(b.text) == 0 || b.text[len(b.text)-1] == ' ') {
+		return
+	}
+	b.writeRaw(c, line)
+}
+
+// writeRaw appends a byte of a string literal as is
+func (b *statementBuilder) writeRaw(c byte, line int) {
+	b.text = append(b.text, c)
+	b.lines = append(b.lines, line)
+}
+
+// trimmed returns the text without trailing spaces
+func (b *statementBuilder) trimmed() string {
+	return strings.TrimRight(string(b.text), " ")
+}
+
+// reset starts a new statement
+func (b *statementBuilder) reset() {
+	b.text = b.text[:0]
+	b.lines = b.lines[:0]
+}
+
+// splitStatements splits PHP source into logical statements. Statements end
+// at ";" or "?>" outside strings, parentheses and expression braces (closure
+// bodies, match arms), so statements spanning several lines are joined.
+// Inline HTML, comments, block braces, alternative-syntax headers (`if (...):`,
+// `else:`, `case 1:`) and their `endif;`-style terminators are dropped.
+func splitStatements(content []byte) []statement {
+	var stmts []statement
+	var cur statementBuilder
+	var braces []bool // Open braces: true for expression braces, false for blocks
+	depth := 0        // Parentheses, brackets and expression braces
+	line := 1
+	inPHP := false
+
+	flush := func() {
+		stmts = appendStatement(stmts, &cur)
+		cur.reset()
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if c == '\n' {
+			line++
+		}
+
+		if !inPHP {
+			if bytes.HasPrefix(content[i:], []byte("<?")) {
+				inPHP = true
+				switch {
+				case len(content) >= i+5 && strings.EqualFold(string(content[i:i+5]), "<?php"):
+					i += 4
+				case bytes.HasPrefix(content[i:], []byte("<?=")):
+					i += 2
+					for _, e := range []byte("echo ") {
+						cur.write(e, line)
+					}
+				default:
+					i++
+				}
+			}
+			continue
+		}
+
+		switch {
+		case c == '?' && i+1 < len(content) && content[i+1] == '>':
+			flush() // A closing tag ends the statement; blocks continue in the next tag
+			depth = 0
+			inPHP = false
+			i++
+
+		case c == '\'' || c == '"' || c == '`':
+			end := stringEnd(content, i)
+			for j := i; j <= end && j < len(content); j++ {
+				if j > i && content[j] == '\n' {
+					line++
+				}
+				cur.writeRaw(content[j], line)
+			}
+			i = end
+
+		case c == '#' || (c == '/' && i+1 < len(content) && content[i+1] == '/'):
+			// Line comment, ended by a line break or a closing tag
+			for i+1 < len(content) && content[i+1] != '\n' && !bytes.HasPrefix(content[i+1:], []byte("?>")) {
+				i++
+			}
+
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(content) - i - 2
+			}
+			line += bytes.Count(content[i+2:i+2+end], []byte("\n"))
+			i += end + 3
+
+		case c == '<' && bytes.HasPrefix(content[i:], []byte("<<<")):
+			// Heredoc/nowdoc: kept as an empty string
+			end, lines := heredocEnd(content, i)
+			cur.write('\'', line)
+			cur.write('\'', line)
+			line += lines
+			i = end
+
+		case c == '(' || c == '[':
+			depth++
+			cur.write(c, line)
+
+		case c == ')' || c == ']':
+			if depth > 0 {
+				depth--
+			}
+			cur.write(c, line)
+
+		case c == '{':
+			if depth > 0 || !opensBlock(cur.trimmed()) {
+				braces = append(braces, true)
+				depth++
+				cur.write(c, line)
+				continue
+			}
+			braces = append(braces, false)
+			cur.reset() // The header of the block is not a statement
+
+		case c == '}':
+			if n := len(braces); n > 0 && braces[n-1] {
+				braces = braces[:n-1]
+				if depth > 0 {
+					depth--
+				}
+				cur.write(c, line)
+				continue
+			}
+			if n := len(braces); n > 0 {
+				braces = braces[:n-1]
+			}
+			flush()
+
+		case c == ';' && depth == 0:
+			flush()
+
+		case c == ':' && depth == 0 && !isDoubleColon(content, i) && isAlternativeHeader(cur.trimmed()):
+			cur.reset()
+
+		default:
+			cur.write(c, line)
+		}
+	}
+	flush()
+
+	return stmts
+}
+
+// appendStatement appends the accumulated statement, without the headers of
+// brace-less control structures before it and with a closing ";"
+func appendStatement(stmts []statement, cur *statementBuilder) []statement {
+	text := cur.trimmed()
+	start := 0
+	for {
+		end := controlHeaderEnd(text[start:])
+		if end < 0 {
+			break
+		}
+		start += end
+		for start < len(text) && text[start] == ' ' {
+			start++
+		}
+	}
+	if start >= len(text) || patterns.AlternativeEndPattern.MatchString(text[start:]) {
+		return stmts
+	}
+	return append(stmts, statement{text: text[start:] + ";", line: cur.lines[start]})
+}
+
+// controlHeaderEnd returns the length of the control structure header text
+// starts with (`if ($a)`, `else`, ...), or -1
+func controlHeaderEnd(text string) int {
+	if loc := patterns.ControlConditionPattern.FindStringIndex(text); loc != nil {
+		if end := closingParen(text, loc[1]-1); end >= 0 {
+			return end + 1
+		}
+		return -1
+	}
+	if loc := patterns.ControlBarePattern.FindStringIndex(text); loc != nil {
+		return loc[1]
+	}
+	return -1
+}
+
+// isAlternativeHeader reports whether text is a control structure header or
+// switch label that a ":" opens a block after
+func isAlternativeHeader(text string) bool {
+	if patterns.CaseLabelPattern.MatchString(text) {
+		return true
+	}
+	end := controlHeaderEnd(text)
+	return end > 0 && end == len(text)
+}
+
+// opensBlock reports whether a "{" after text opens a block (a control
+// structure, function or class body) rather than an expression brace
+func opensBlock(text string) bool {
+	return text == "" || isAlternativeHeader(text) || patterns.DeclarationHeaderPattern.MatchString(text)
+}
+
+// closingParen returns the index of the ")" closing the "(" at open, or -1
+func closingParen(text string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isDoubleColon reports whether the ":" at i is part of a "::"
+func isDoubleColon(content []byte, i int) bool {
+	return (i+1 < len(content) && content[i+1] == ':') || (i > 0 && content[i-1] == ':')
+}
+
+// stringEnd returns the index of the quote closing the string opened at start
+func stringEnd(content []byte, start int) int {
+	quote := content[start]
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return len(content) - 1
+}
+
+// heredocEnd returns the index of the last byte of the heredoc or nowdoc
+// starting at start, and the number of line breaks it spans
+func heredocEnd(content []byte, start int) (int, int) {
+	lineEnd := bytes.IndexByte(content[start:], '\n')
+	if lineEnd < 0 {
+		return len(content) - 1, 0
+	}
+	label := strings.Trim(strings.TrimSpace(string(content[start+3:start+lineEnd])), `'"`)
+	if label == "" {
+		return start + lineEnd - 1, 0
+	}
+
+	lines := 1
+	pos := start + lineEnd + 1
+	for pos < len(content) {
+		next := bytes.IndexByte(content[pos:], '\n')
+		if next < 0 {
+			next = len(content) - pos
+		}
+		if strings.HasPrefix(strings.TrimSpace(string(content[pos:pos+next])), label) {
+			return pos + strings.Index(string(content[pos:pos+next]), label) + len(label) - 1, lines
+		}
+		lines++
+		pos += next + 1
+	}
+	return len(content) - 1, lines - 1
+}
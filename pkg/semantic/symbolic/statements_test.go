@@ -0,0 +1,62 @@
+package symbolic
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []statement
+	}{
+		{
+			name: "statement spanning lines",
+			src:  "<?php\n$q = 'a' .\n    $_GET['q'];\n",
+			want: []statement{{"$q = 'a' . $_GET['q'];", 2}},
+		},
+		{
+			name: "alternative syntax",
+			src:  "<?php if ($a): $x = $_GET['x']; elseif ($b == 1): $x = 2; else: ?>\n<p>hi</p>\n<?php $x = 3 ?>\n<?php endif; ?>",
+			want: []statement{{"$x = $_GET['x'];", 1}, {"$x = 2;", 1}, {"$x = 3;", 3}},
+		},
+		{
+			name: "single-statement bodies without braces",
+			src:  "<?php\nif ($id == 1)\n    $x = $_POST['x'];\nelse $x = 0;\nforeach ($rows as $k => $v) $y = $v;\n",
+			want: []statement{{"$x = $_POST['x'];", 3}, {"$x = 0;", 4}, {"$y = $v;", 5}},
+		},
+		{
+			name: "blocks, switch labels and closures",
+			src:  "<?php\nfunction f() {\n  switch ($a) {\n    case 'x': $y = 1; break;\n  }\n  $cb = function () { return $_GET['c']; };\n}\n",
+			want: []statement{{"$y = 1;", 4}, {"break;", 4}, {"$cb = function () { return $_GET['c']; };", 6}},
+		},
+		{
+			name: "comments, strings and static calls",
+			src:  "<?php\n// $x = 1;\n/* $x = 2;\n */ $s = \"a;b\"; # note\n$v = Foo::bar();\n",
+			want: []statement{{"$s = \"a;b\";", 4}, {"$v = Foo::bar();", 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements([]byte(tt.src)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindVariableAssignments_AlternativeSyntax(t *testing.T) {
+	e := NewExecutionEngine()
+	addPHPFile(t, e, "/app/template.php", `<?php if ($mybb->user['uid']): ?>
+<div><?php $name = htmlspecialchars(
+    $_GET['name']) ?></div>
+<?php endif; ?>
+<?php if ($name == 'admin') $role = 1; ?>
+`)
+
+	got := e.findVariableAssignments("$name", "/app/template.php", "", false)
+	if len(got) != 1 || got[0].source != "htmlspecialchars( $_GET['name'])" || got[0].line != 2 {
+		t.Errorf("assignments = %+v, want htmlspecialchars( $_GET['name']) at line 2", got)
+	}
+}
@@ -0,0 +1,53 @@
+package semantic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestSymfonyBagSources(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "controller.php", `<?php
+class ItemController {
+    public function show(Request $request) {
+        $id = $request->query->get('id');
+        $filter = $request->query->all()['filter']['id'];
+        $a = $request->request->get('a', $request->query->get('a'));
+        $token = $request->headers->get('X-Token');
+        $slug = $request->attributes->get('slug');
+    }
+}
+`)
+
+	result, err := New(nil).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type source struct {
+		line       int
+		sourceType types.SourceType
+		key        string
+		fallbackOf string
+	}
+	got := make(map[source]bool)
+	for _, s := range result.Sources {
+		fallbackOf, _ := s.Metadata["fallback_of"].(string)
+		got[source{s.Line, s.SourceType, s.SourceKey, fallbackOf}] = true
+	}
+
+	tests := []source{
+		{4, types.SourceHTTPGet, "id", ""},
+		{5, types.SourceHTTPGet, "filter", ""},
+		{6, types.SourceHTTPPost, "a", ""},
+		{6, types.SourceHTTPGet, "a", "$request->request->get('a', $request->query->get('a'))"},
+		{7, types.SourceHTTPHeader, "X-Token", ""},
+		{8, types.SourceHTTPPath, "slug", ""},
+	}
+	for _, tt := range tests {
+		if !got[tt] {
+			t.Errorf("missing source %+v in %+v", tt, got)
+		}
+	}
+}
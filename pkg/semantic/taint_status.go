@@ -0,0 +1,173 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// TaintStatus reports whether the expression at a file position carries input
+type TaintStatus struct {
+	FilePath     string              `json:"file_path"`
+	Line         int                 `json:"line"`
+	Column       int                 `json:"column"`
+	Expression   string              `json:"expression"` // Expression found at the position
+	Tainted      bool                `json:"tainted"`
+	Sources      []types.SourceInfo  `json:"sources,omitempty"`       // Distinct sources reaching the expression
+	ShortestPath *types.BackwardPath `json:"shortest_path,omitempty"` // Fewest-step path from a source
+	Confidence   float64             `json:"confidence"`              // 0.0-1.0, highest over all paths
+}
+
+// TaintStatusAt reports whether the expression at file:line:col is tainted, by
+// which sources, through which shortest path and with what confidence.
+// line is 1-based and col is 0-based (matching FlowNode.Line/Column).
+// The codebase must have been parsed first (ParseOnly or TraceDirectory) or
+// its index loaded (LoadIndex); files invalidated since are parsed again.
+func (t *Tracer) TaintStatusAt(file string, line, col int) (*TaintStatus, error) {
+	t.reparseStale()
+	t.mu.RLock()
+	fileInfo := t.files[file]
+	t.mu.RUnlock()
+	if fileInfo == nil {
+		return nil, fmt.Errorf("file %s has not been parsed: call ParseOnly, TraceDirectory or LoadIndex first", file)
+	}
+	if fileInfo.Error != nil {
+		return nil, fileInfo.Error
+	}
+
+	expr, err := t.expressionAt(file, fileInfo.Language, line, col)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TaintStatus{
+		FilePath:   file,
+		Line:       line,
+		Column:     col,
+		Expression: expr,
+	}
+
+	// The expression is itself an input source
+	if sourceInfo := t.identifySource(expr, file, line); sourceInfo != nil {
+		status.Tainted = true
+		status.Sources = []types.SourceInfo{*sourceInfo}
+		status.ShortestPath = &types.BackwardPath{
+			Source: *sourceInfo,
+			Steps: []types.BackwardStep{{
+				Expression:  expr,
+				FilePath:    file,
+				Line:        line,
+				StepType:    "source",
+				Description: fmt.Sprintf("Input source: %s (%s)", expr, sourceInfo.Type),
+			}},
+		}
+		status.Confidence = 1.0
+		return status, nil
+	}
+
+	varName := strings.TrimPrefix(expr, "$")
+	if varName == "" || strings.ContainsAny(varName, " ()[]-:>'\"") {
+		return status, nil // Only plain variables are traced further
+	}
+
+	ctx := t.traceContext()
+	defer ctx.Close()
+
+	// Only assignments in the scope owning the variable at line can reach it
+	var inScope func(string) bool
+	if fileInfo.SymbolTable != nil {
+		scope := fileInfo.SymbolTable.Scopes.VariableScopeOf(line, varName)
+		inScope = func(s string) bool { return s == scope }
+	}
+
+	paths, _, _ := t.traceBackwardInScope(ctx, file, varName, inScope)
+	seen := make(map[string]bool)
+	for i := range paths {
+		path := &paths[i]
+		if len(path.Steps) == 0 {
+			continue
+		}
+		// Only assignments that precede the queried position can reach it
+		if last := path.Steps[len(path.Steps)-1]; last.FilePath == file && last.Line > line {
+			continue
+		}
+
+		status.Tainted = true
+		key := fmt.Sprintf("%s:%d:%s", path.Source.FilePath, path.Source.Line, path.Source.Expression)
+		if !seen[key] {
+			seen[key] = true
+			status.Sources = append(status.Sources, path.Source)
+		}
+		if status.ShortestPath == nil || len(path.Steps) < len(status.ShortestPath.Steps) {
+			status.ShortestPath = path
+		}
+		if c := pathConfidence(path); c > status.Confidence {
+			status.Confidence = c
+		}
+	}
+
+	return status, nil
+}
+
+// pathConfidence scores a backward path: every step past the direct assignment
+// and every file boundary adds uncertainty
+func pathConfidence(path *types.BackwardPath) float64 {
+	confidence := 1.0 - 0.1*float64(len(path.Steps)-2)
+	if confidence > 1.0 {
+		confidence = 1.0
+	}
+	if path.CrossFile {
+		confidence *= 0.8
+	}
+	if confidence < 0.3 {
+		confidence = 0.3
+	}
+	return confidence
+}
+
+// expressionAt returns the text of the smallest meaningful expression at a position
+func (t *Tracer) expressionAt(file, language string, line, col int) (string, error) {
+	content, err := t.readFile(file)
+	if err != nil {
+		return "", &types.ParseError{FilePath: file, Err: err}
+	}
+
+	parser := createParser(language)
+	if parser == nil {
+		return "", fmt.Errorf("%w: no parser for language %q", types.ErrUnsupportedExpression, language)
+	}
+	defer parser.Close()
+
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return "", &types.ParseError{FilePath: file, Err: err}
+	}
+	defer tree.Close()
+
+	point := sitter.Point{Row: uint32(line - 1), Column: uint32(col)}
+	node := tree.RootNode().NamedDescendantForPointRange(point, point)
+	if node == nil {
+		return "", fmt.Errorf("%w: nothing at %s:%d:%d", types.ErrUnsupportedExpression, file, line, col)
+	}
+
+	// Widen a bare name to its variable ($x), a property name to its member
+	// access ($obj->x) and an array base to its subscript ($_GET['id'])
+	for parent := node.Parent(); parent != nil; parent = node.Parent() {
+		widen := false
+		switch parent.Type() {
+		case "variable_name", "member_access_expression":
+			widen = node.Type() == "name"
+		case "subscript_expression":
+			widen = parent.NamedChild(0) == node
+		}
+		if !widen {
+			break
+		}
+		node = parent
+	}
+
+	return strings.TrimSpace(string(content[node.StartByte():node.EndByte()])), nil
+}
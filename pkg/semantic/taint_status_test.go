@@ -0,0 +1,78 @@
+package semantic
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestTaintStatusAt(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+$id = $_GET['id'];
+echo $id;
+function render() {
+    $id = 'static';
+    echo $id;
+}
+function session() {
+    global $id;
+    echo $id;
+}
+$copy = $id;
+echo $copy;
+echo $later;
+$later = $_POST['later'];
+`)
+	file := filepath.Join(dir, "index.php")
+
+	tracer := New(nil)
+	if _, err := tracer.TraceDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		line, col  int
+		expression string
+		source     string // "" = not tainted
+		confidence float64
+	}{
+		{"source itself", 2, 6, "$_GET['id']", "$_GET['id']", 1.0},
+		{"file-level read", 3, 5, "$id", "$_GET['id']", 1.0},
+		{"function local with the same name", 6, 9, "$id", "", 0},
+		{"declared global", 10, 9, "$id", "$_GET['id']", 1.0},
+		{"through a copy", 13, 5, "$copy", "$_GET['id']", 0.9},
+		{"read before its assignment", 14, 5, "$later", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := tracer.TaintStatusAt(file, tt.line, tt.col)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Expression != tt.expression {
+				t.Errorf("expression = %q, want %q", status.Expression, tt.expression)
+			}
+			if tt.source == "" {
+				if status.Tainted || status.ShortestPath != nil {
+					t.Errorf("tainted by %+v, want untainted", status.Sources)
+				}
+				return
+			}
+			if !status.Tainted || len(status.Sources) != 1 || status.Sources[0].Expression != tt.source {
+				t.Fatalf("sources = %+v, want %s", status.Sources, tt.source)
+			}
+			if status.ShortestPath == nil || len(status.ShortestPath.Steps) == 0 {
+				t.Errorf("shortest path = %+v, want steps", status.ShortestPath)
+			}
+			if got := fmt.Sprintf("%.2f", status.Confidence); got != fmt.Sprintf("%.2f", tt.confidence) {
+				t.Errorf("confidence = %s, want %.2f", got, tt.confidence)
+			}
+		})
+	}
+
+	if _, err := tracer.TaintStatusAt(filepath.Join(dir, "missing.php"), 1, 0); err == nil {
+		t.Error("TaintStatusAt on an unparsed file: want an error")
+	}
+}
@@ -0,0 +1,179 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/patterns"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// templateBindingExtractor is implemented by analyzers that model the
+// `extract($vars); include $template;` idiom (currently PHP)
+type templateBindingExtractor interface {
+	ExtractTemplateBindings(root *sitter.Node, source []byte, filePath string) []*types.TemplateBinding
+}
+
+// templateBindingsFor returns the extract/include bindings whose include
+// resolves to filePath. The bindings of all files are indexed by included
+// path once per trace context.
+func (t *Tracer) templateBindingsFor(ctx *TraceContext, filePath string) []*types.TemplateBinding {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	if ctx.templateBindings == nil {
+		ctx.templateBindings = make(map[string][]*types.TemplateBinding)
+		t.mu.RLock()
+		for _, fileInfo := range t.files {
+			for _, b := range fileInfo.TemplateBindings {
+				ctx.templateBindings[b.IncludePath] = append(ctx.templateBindings[b.IncludePath], b)
+			}
+		}
+		t.mu.RUnlock()
+	}
+	return ctx.templateBindings[filePath]
+}
+
+// templateVariableLine returns the first line where $varName is read in a
+// template (0 if never). A template is scanned once per trace context, for
+// the first read of every variable.
+func (t *Tracer) templateVariableLine(ctx *TraceContext, filePath string, varName string) int {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+	reads, ok := ctx.templateReads[filePath]
+	if !ok {
+		reads = t.firstVariableReads(filePath)
+		if ctx.templateReads == nil {
+			ctx.templateReads = make(map[string]map[string]int)
+		}
+		ctx.templateReads[filePath] = reads
+	}
+	return reads[varName]
+}
+
+// firstVariableReads maps the variables (without $) mentioned in a PHP file
+// to the line they first appear on
+func (t *Tracer) firstVariableReads(filePath string) map[string]int {
+	reads := make(map[string]int)
+	content, err := t.readFile(filePath)
+	if err != nil {
+		return reads
+	}
+	variable := patterns.GetVariablePatterns("php")[0]
+	for i, line := range strings.Split(string(content), "\n") {
+		for _, name := range variable.FindAllString(line, -1) {
+			if _, seen := reads[name[1:]]; !seen {
+				reads[name[1:]] = i + 1
+			}
+		}
+	}
+	return reads
+}
+
+// traceTemplateBinding resolves $varName through one binding: the matching key of the
+// extracted array is either a known value expression or an element of the array itself
+func (t *Tracer) traceTemplateBinding(ctx *TraceContext, b *types.TemplateBinding, varName string, visited map[string]bool, depth int) []types.SourceInfo {
+	keyExpr := fmt.Sprintf("%s['%s']", b.ExtractedExpr, varName)
+	if b.Values != nil {
+		value, ok := b.Values[varName]
+		if !ok {
+			return nil // Key is not extracted - the template variable is not bound here
+		}
+		keyExpr = value
+	}
+
+	if sourceInfo := t.identifySource(keyExpr, b.FilePath, b.ExtractLine); sourceInfo != nil {
+		return []types.SourceInfo{*sourceInfo}
+	}
+
+	// Trace the extracted array (or the bound value) within the including scope
+	tracedVar := b.ExtractedExpr
+	if b.Values != nil {
+		tracedVar = keyExpr
+	}
+	if !strings.HasPrefix(tracedVar, "$") {
+		return nil
+	}
+	return t.traceBackwardRecursiveWithContext(ctx, tracedVar, b.Scope, b.FilePath, visited, depth+1)
+}
+
+// traceTemplateVariable resolves a free variable of an included template back to the
+// keys of the arrays extracted by its includers
+func (t *Tracer) traceTemplateVariable(ctx *TraceContext, filePath string, varName string, visited map[string]bool, depth int) []types.SourceInfo {
+	var sources []types.SourceInfo
+	for _, b := range t.templateBindingsFor(ctx, filePath) {
+		sources = append(sources, t.traceTemplateBinding(ctx, b, varName, visited, depth)...)
+	}
+	return sources
+}
+
+// templatePaths builds backward paths for a template variable that has no
+// assignment of its own but is bound by extract() in an including file
+func (t *Tracer) templatePaths(ctx *TraceContext, filePath string, varName string) []types.BackwardPath {
+	bindings := t.templateBindingsFor(ctx, filePath)
+	if len(bindings) == 0 {
+		return nil
+	}
+
+	// A variable the template assigns itself is not a free variable
+	t.mu.RLock()
+	fileInfo := t.files[filePath]
+	t.mu.RUnlock()
+	if fileInfo != nil {
+		for _, assign := range ctx.getAssignmentsDirectly(filePath, fileInfo.Language) {
+			if assign.Scope == "" && strings.TrimPrefix(assign.Target, "$") == varName {
+				return nil
+			}
+		}
+	}
+
+	useLine := t.templateVariableLine(ctx, filePath, varName)
+	if useLine == 0 {
+		return nil
+	}
+
+	var paths []types.BackwardPath
+	for _, b := range bindings {
+		for _, src := range t.traceTemplateBinding(ctx, b, varName, make(map[string]bool), 0) {
+			paths = append(paths, types.BackwardPath{
+				Source:    src,
+				CrossFile: true,
+				Steps: []types.BackwardStep{
+					{
+						StepNumber:  0,
+						Expression:  src.Expression,
+						FilePath:    src.FilePath,
+						Line:        src.Line,
+						StepType:    "source",
+						Description: fmt.Sprintf("Input source: %s (%s)", src.Expression, src.Type),
+					},
+					{
+						StepNumber:  1,
+						Expression:  fmt.Sprintf("extract(%s)", b.ExtractedExpr),
+						FilePath:    b.FilePath,
+						Line:        b.ExtractLine,
+						StepType:    "template_extract",
+						Description: fmt.Sprintf("Key '%s' of %s extracted into template scope", varName, b.ExtractedExpr),
+					},
+					{
+						StepNumber:  2,
+						Expression:  fmt.Sprintf("include %s", b.IncludeExpr),
+						FilePath:    b.FilePath,
+						Line:        b.IncludeLine,
+						StepType:    "template_include",
+						Description: fmt.Sprintf("Template %s included with extracted variables", filePath),
+					},
+					{
+						StepNumber:  3,
+						Expression:  "$" + varName,
+						FilePath:    filePath,
+						Line:        useLine,
+						StepType:    "template_variable",
+						Description: fmt.Sprintf("$%s read in template", varName),
+					},
+				},
+			})
+		}
+	}
+	return paths
+}
@@ -0,0 +1,77 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplatePaths(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "page.php", `<?php
+extract(['title' => $_GET['title'], 'footer' => 'static']);
+include 'view.php';
+`)
+	writeFile(t, dir, "form.php", `<?php
+$name = $_POST['name'];
+extract(compact('name'));
+include 'view.php';
+`)
+	writeFile(t, dir, "view.php", `<html>
+<h1><?php echo $title; ?></h1>
+<p><?php echo $name; ?></p>
+<footer><?php echo $footer; ?></footer>
+<?php $own = 'x'; echo $own; ?>
+`)
+	view := filepath.Join(dir, "view.php")
+
+	tracer := New(nil)
+	if _, err := tracer.ParseOnly(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		variable string
+		source   string // "" = no template path
+		useLine  int
+	}{
+		{"title", "$_GET['title']", 2},
+		{"name", "$_POST['name']", 3},
+		{"footer", "", 0},
+		{"own", "", 0}, // Assigned by the template itself
+		{"missing", "", 0},
+	}
+	ctx := tracer.traceContext()
+	defer ctx.Close()
+	for _, tt := range tests {
+		t.Run(tt.variable, func(t *testing.T) {
+			paths := tracer.templatePaths(ctx, view, tt.variable)
+			if tt.source == "" {
+				if len(paths) != 0 {
+					t.Errorf("paths = %+v, want none", paths)
+				}
+				return
+			}
+			if len(paths) != 1 || paths[0].Source.Expression != tt.source {
+				t.Fatalf("paths = %+v, want one from %s", paths, tt.source)
+			}
+			last := paths[0].Steps[len(paths[0].Steps)-1]
+			if last.StepType != "template_variable" || last.FilePath != view || last.Line != tt.useLine {
+				t.Errorf("last step = %+v, want template_variable at view.php:%d", last, tt.useLine)
+			}
+		})
+	}
+
+	// Bindings and template reads are computed once per trace context
+	if err := os.WriteFile(view, []byte("<?php\n\n\n\necho $title;\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := tracer.templateVariableLine(ctx, view, "title"); got != 2 {
+		t.Errorf("cached use line = %d, want 2", got)
+	}
+	next := tracer.traceContext()
+	defer next.Close()
+	if got := tracer.templateVariableLine(next, view, "title"); got != 5 {
+		t.Errorf("use line in a new trace = %d, want 5", got)
+	}
+}
@@ -0,0 +1,91 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	phpPatterns "github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+// stopAt records a wall hit by the current backward search, keeping the most
+// severe one
+func (ctx *TraceContext) stopAt(reason types.TerminationReason) {
+	ctx.stop = types.CombineTermination(ctx.stop, reason)
+}
+
+// stopReason classifies an assigned value that is neither input nor a plain
+// variable, which the backward trace does not follow
+func (t *Tracer) stopReason(ctx *TraceContext, value string) types.TerminationReason {
+	value = strings.TrimSpace(value)
+	ctx.budget.CountRegex(1)
+	if phpPatterns.LiteralValuePattern.MatchString(value) {
+		return types.TerminationClean
+	}
+	ctx.budget.CountRegex(1)
+	if phpPatterns.InstanceMethodCallPattern.MatchString(value) {
+		return types.TerminationDynamicDispatch
+	}
+	ctx.budget.CountRegex(1)
+	if m := phpPatterns.ClassReferencePattern.FindStringSubmatch(value); m != nil {
+		class := m[1] + m[2]
+		switch strings.ToLower(class) {
+		case "self", "static", "parent":
+			return types.TerminationUnresolved
+		}
+		if i := strings.LastIndex(class, "\\"); i >= 0 {
+			class = class[i+1:]
+		}
+		t.mu.RLock()
+		defined := t.symbolTable.Classes[class] != nil
+		t.mu.RUnlock()
+		if !defined {
+			return types.TerminationClassNotFound
+		}
+	}
+	return types.TerminationUnresolved
+}
+
+// deadEnd returns the path of an assignment to targetVar whose trace reached
+// no source, with the reason it stopped: for a plain variable the walls its
+// search hit (ctx.stop), otherwise the kind of value assigned
+func (t *Tracer) deadEnd(ctx *TraceContext, targetVar string, assign *types.Assignment, filePath string) types.BackwardPath {
+	reason := types.TerminationClean
+	ctx.budget.CountRegex(1)
+	if phpPatterns.PlainVariablePattern.MatchString(strings.TrimSpace(assign.Source)) {
+		reason = types.CombineTermination(reason, ctx.stop)
+	} else {
+		reason = t.stopReason(ctx, assign.Source)
+	}
+	return types.BackwardPath{
+		Steps: []types.BackwardStep{{
+			StepNumber:  1,
+			Expression:  fmt.Sprintf("$%s = %s", targetVar, assign.Source),
+			FilePath:    filePath,
+			Line:        assign.Line,
+			StepType:    "assignment",
+			Description: fmt.Sprintf("$%s assigned from %s (%s)", targetVar, assign.Source, reason),
+		}},
+		Termination: reason,
+	}
+}
+
+// terminate records why the trace of result stopped. Its paths reached input;
+// without any, the most severe reason among the dead ends applies, and a
+// target never assigned is unresolved rather than clean.
+func terminate(result *types.BackwardTraceResult) {
+	for i := range result.Paths {
+		result.Paths[i].Termination = types.TerminationSource
+	}
+	switch {
+	case len(result.Paths) > 0:
+		result.Termination = types.TerminationSource
+	case len(result.DeadEnds) == 0:
+		result.Termination = types.TerminationUnresolved
+	default:
+		result.Termination = types.TerminationClean
+		for _, path := range result.DeadEnds {
+			result.Termination = types.CombineTermination(result.Termination, path.Termination)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package semantic
+
+import (
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestBackwardTermination(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+class Known {}
+$input = $_GET['q'];
+$clean = 'fixed';
+$copy = $clean;
+$fetched = $repo->find(1);
+$built = new Missing();
+$made = Known::create();
+$chain = $a1;
+$a1 = $a2;
+$a2 = $a3;
+$a3 = $a4;
+$a4 = $a5;
+$a5 = $a6;
+$mixed = 'x';
+$mixed = $repo->load();
+$fromParam = $param;
+`)
+
+	config := DefaultConfig()
+	config.MaxDepth = 3
+	tracer := New(config)
+	if _, err := tracer.ParseOnly(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target string
+		want   types.TerminationReason
+	}{
+		{"$input", types.TerminationSource},
+		{"$clean", types.TerminationClean},
+		{"$copy", types.TerminationClean},
+		{"$fetched", types.TerminationDynamicDispatch},
+		{"$built", types.TerminationClassNotFound},
+		{"$made", types.TerminationUnresolved},
+		{"$chain", types.TerminationDepthLimit},
+		{"$mixed", types.TerminationDynamicDispatch},
+		{"$fromParam", types.TerminationUnresolved},
+		{"$neverAssigned", types.TerminationUnresolved},
+	}
+	for _, tt := range tests {
+		result, err := tracer.TraceBackward(tt.target, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Termination != tt.want {
+			t.Errorf("%s: termination = %s, want %s", tt.target, result.Termination, tt.want)
+		}
+		for _, path := range result.Paths {
+			if path.Termination != types.TerminationSource {
+				t.Errorf("%s: path termination = %s, want source", tt.target, path.Termination)
+			}
+		}
+		if tt.want.Incomplete() != (len(result.Sources) == 0 && result.Termination != types.TerminationClean) {
+			t.Errorf("%s: Incomplete() = %v with %d sources", tt.target, tt.want.Incomplete(), len(result.Sources))
+		}
+	}
+}
+
+func TestTerminationFromWarnings(t *testing.T) {
+	tests := []struct {
+		categories []types.WarningCategory
+		want       types.TerminationReason
+	}{
+		{nil, types.TerminationClean},
+		{[]types.WarningCategory{types.WarningUnresolvedVariable}, types.TerminationUnresolved},
+		{[]types.WarningCategory{types.WarningReturnTypeUnknown, types.WarningClassNotFound}, types.TerminationClassNotFound},
+		{[]types.WarningCategory{types.WarningChainTruncated, types.WarningMethodNotFound}, types.TerminationDepthLimit},
+		{[]types.WarningCategory{types.WarningDepthCutoff, types.WarningTimeLimit}, types.TerminationResourceLimit},
+	}
+	for _, tt := range tests {
+		var warnings []types.AnalysisWarning
+		for _, c := range tt.categories {
+			warnings = append(warnings, types.AnalysisWarning{Category: c})
+		}
+		if got := types.TerminationFromWarnings(warnings); got != tt.want {
+			t.Errorf("TerminationFromWarnings(%v) = %s, want %s", tt.categories, got, tt.want)
+		}
+	}
+}
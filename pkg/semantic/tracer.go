@@ -11,6 +11,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hatlesswizard/inputtracer/pkg/parser"
@@ -28,10 +29,12 @@ import (
 	"github.com/smacker/go-tree-sitter/golang"
 	"github.com/smacker/go-tree-sitter/java"
 	"github.com/smacker/go-tree-sitter/javascript"
+	"github.com/smacker/go-tree-sitter/kotlin"
 	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
 	"github.com/smacker/go-tree-sitter/ruby"
 	"github.com/smacker/go-tree-sitter/rust"
+	"github.com/smacker/go-tree-sitter/swift"
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
 
 	// Import language analyzers to register them
@@ -41,10 +44,12 @@ import (
 	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/golang"
 	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/java"
 	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/javascript"
+	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/kotlin"
 	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/php"
 	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/python"
 	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/ruby"
 	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/rust"
+	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/swift"
 	_ "github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer/typescript"
 )
 
@@ -53,9 +58,18 @@ type Config struct {
 	// Languages to analyze (empty = auto-detect all)
 	Languages []string
 
-	// MaxDepth for inter-procedural analysis
+	// Profile presets the limits below left at zero: quick (sources only, no
+	// flow tracing), standard (default) or deep (see the Profile constants);
+	// tracing fails for any other value
+	Profile Profile
+
+	// MaxDepth for inter-procedural analysis (0 = profile default, 10 standard)
 	MaxDepth int
 
+	// TraceStrategy orders flow exploration: depth_first (default) or
+	// breadth_first, which finds the shortest source-to-target paths first
+	TraceStrategy TraceStrategy
+
 	// Workers for parallel analysis
 	Workers int
 
@@ -71,10 +85,17 @@ type Config struct {
 	// ExcludePatterns for file filtering (glob patterns)
 	ExcludePatterns []string
 
-	// MaxMemoryMB is the maximum memory usage in MB (0 = use default 100MB)
-	// Applied to all modes to prevent OOM on large codebases
+	// MaxMemoryMB is the maximum memory usage in MB (0 = profile default, 120MB standard)
+	// Applied to all modes to prevent OOM on large codebases: heap usage is
+	// checked while parsing and tracing, and analysis stops when it is exceeded
 	MaxMemoryMB int
 
+	// SetGCMemoryLimit also sets MaxMemoryMB as the runtime's soft memory
+	// limit (debug.SetMemoryLimit) while tracing, so the GC works harder near
+	// it. The limit is process-wide: it affects the whole host application
+	// until the last trace using it ends.
+	SetGCMemoryLimit bool
+
 	// MaxFileSizeBytes is the maximum file size to parse (0 = unlimited)
 	MaxFileSizeBytes int64
 
@@ -86,27 +107,97 @@ type Config struct {
 
 	// MaxFlowEdges is the maximum number of edges in the flow graph (0 = default 20000)
 	MaxFlowEdges int
-}
 
-// getMemoryUsageMB returns current memory usage in MB (allocated heap memory)
-func getMemoryUsageMB() uint64 {
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	// Use Alloc (currently allocated heap) not Sys (total memory from OS)
-	// Sys includes memory the Go runtime reserves but hasn't released to OS,
-	// which causes false memory limit triggers when running inside larger applications
-	return m.Alloc / 1024 / 1024
+	// GeneratedFiles controls minified/generated files: flag (default), skip, light or full
+	GeneratedFiles GeneratedFileMode
+
+	// RulesFile is a JSON rules file with user declarations such as entry points
+	RulesFile string
+
+	// Rules are used instead of RulesFile when set
+	Rules *Rules
+
+	// RulePackFiles are rule packs (see sources.RulePack) applied in order
+	// on top of the built-in detection rules, after RulePacks
+	RulePackFiles []string
+
+	// RulePacks are rule packs applied before RulePackFiles
+	RulePacks []*sources.RulePack
+
+	// FrameworkVersions sets framework versions (framework name -> version,
+	// e.g. "laravel": "10.48") over the ones read from composer.lock and
+	// package-lock.json; patterns of other versions are not matched
+	FrameworkVersions map[string]string
+
+	// RuntimeHintsFile is a JSONL runtime log (observed request parameters,
+	// resolved DI services, executed includes) used to resolve dynamic
+	// constructs; flows relying on it are marked runtime-assisted
+	RuntimeHintsFile string
+
+	// RuntimeHints are used instead of RuntimeHintsFile when set
+	RuntimeHints *RuntimeHints
+
+	// IndexFile is an index written by BuildIndex; when set, backward queries
+	// (TraceBackward, TraceBackwardBatch) load it instead of parsing the codebase
+	IndexFile string
+
+	// SuppressWarnings lists analysis warning categories that are not recorded
+	SuppressWarnings []types.WarningCategory
+
+	// GrammarVersion pins the expected version of the bundled tree-sitter
+	// grammars; a different bundled version is reported as a warning
+	GrammarVersion string
+
+	// RedactSnippets replaces string literals and comments in all emitted
+	// snippets so reports can be shared without exposing embedded secrets
+	RedactSnippets bool
+
+	// MaxTracedSources is the maximum number of sources whose flows are
+	// traced (0 = default 200, negative = unlimited). Past the cap the
+	// highest-priority sources are traced and the rest reported as skipped.
+	MaxTracedSources int
+
+	// SandboxRoots restricts all file resolution to these directories, like
+	// PHP's open_basedir. Files and includes resolving outside them (through
+	// ../ or symlinks) are never read and are reported as sandbox_escape
+	// warnings. Empty means no restriction.
+	SandboxRoots []string
+
+	// TrustedServerKeys overrides the trust of PHP $_SERVER keys: true marks
+	// a key as server configuration, which is not reported as input; false as
+	// attacker-controlled. By default HTTP_*, REQUEST_URI, QUERY_STRING and
+	// unknown keys are attacker-controlled, SERVER_ADDR, SCRIPT_FILENAME and
+	// the other configuration keys are trusted.
+	TrustedServerKeys map[string]bool
+
+	// MaxWarnings is the maximum number of warnings kept (0 = default 1000);
+	// warnings past the limit are still counted
+	MaxWarnings int
+
+	// MaxDuration stops flow tracing once a trace has run this long; the
+	// result is marked incomplete (0 = profile default, unlimited standard)
+	MaxDuration time.Duration
+
+	// YieldEvery yields every this many parsed files, flow tracing steps or
+	// symbolic execution steps, so that an embedding application stays
+	// responsive during long traces (0 = never yield)
+	YieldEvery int
+
+	// YieldHook is called at each yield point instead of runtime.Gosched,
+	// e.g. to wait for a host scheduler's turn; it is called concurrently
+	// while files are parsed
+	YieldHook func()
 }
 
 // DefaultConfig returns sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
 		Languages:        []string{}, // Auto-detect
-		MaxDepth:         10,
 		Workers:          runtime.NumCPU(),
 		FollowImports:    true,
 		Verbose:          false,
 		MaxFileSizeBytes: 5 * 1024 * 1024, // 5MB - skip very large files (ASTs are ~10x source size)
+		GeneratedFiles:   GeneratedFlag,
 		IncludePatterns:  languages.BuildIncludePatterns(),
 		ExcludePatterns: []string{
 			"**/node_modules/**", "**/vendor/**", "**/.git/**",
@@ -133,6 +224,85 @@ type Tracer struct {
 
 	// Statistics
 	stats *TraceStats
+
+	// Reason analysis stopped early (e.g. *types.MemoryLimitError), nil if complete
+	incomplete error
+
+	// Start of the running TraceDirectory, for Config.MaxDuration
+	traceStart time.Time
+
+	// Budget consumed by the running TraceDirectory or ParseOnly
+	budget *types.BudgetMeter
+
+	// Yield points of the heavy loops (see Config.YieldEvery)
+	yielder *types.Yielder
+
+	// Analysis gaps hit while tracing
+	warnings *types.WarningCollector
+
+	// Static include graph of the parsed files and its reverse (see buildIncludeGraph)
+	includes   map[string][]string
+	includedBy map[string][]string
+
+	// Shared copies of strings repeated across cached and result structures,
+	// kept for one parse or trace (see stringInterner.release)
+	interner *stringInterner
+
+	// Directories file resolution is restricted to (see Config.SandboxRoots)
+	sandbox *types.Sandbox
+
+	// Pending forward tracing steps while tracing breadth-first (see Config.TraceStrategy)
+	frontier *frontier
+
+	// Entry points of the parsed files, used to prioritize capped sources
+	entryPoints []*EntryPoint
+
+	// Sources not traced because of the source cap (see Config.MaxTracedSources)
+	skippedSources []*types.FlowNode
+
+	// Called after each source's flows are traced; false stops tracing (see StreamDirectory)
+	onSourceTraced func(source *types.FlowNode, flowMap *types.FlowMap) bool
+
+	// Functions promoted to derived sources, keyed by lowercase name
+	sourceWrappers map[string]*types.SourceWrapper
+
+	// Functions returning arrays with tainted keys, keyed by lowercase name
+	returnSummaries map[string]*types.ReturnSummary
+
+	// Validator functions by lowercase name (see promoteValidators)
+	validators map[string][]*types.Validator
+
+	// PSR-7 request attributes set from tainted data, keyed by attribute name
+	requestAttributes map[string]*types.RequestAttribute
+
+	// User declarations loaded from Config.Rules/RulesFile (nil if none)
+	rules *Rules
+
+	// Rules of each of rules.Subtrees (see loadSubtreePacks)
+	subtrees []*subtreeRules
+
+	// Detection rules of the run (see loadRulePacks) and the analyzers
+	// matching their framework patterns
+	ruleSet   *sources.RuleSet
+	analyzers *analyzer.Registry
+
+	// Flows of the last TraceDirectory, for SourcesReaching
+	flowMap *types.FlowMap
+
+	// Framework versions patterns are restricted to (see applyFrameworkVersions)
+	frameworkVersions map[string]string
+
+	// Runtime log and the include edges only it provides (see Config.RuntimeHintsFile)
+	runtimeHints        *RuntimeHints
+	runtimeOnlyIncludes map[string]map[string]bool
+
+	// Assignments loaded from an index file, by file (see LoadIndex)
+	indexedAssignments map[string][]*types.Assignment
+
+	// Codebase queries run on, and the files invalidated since they were
+	// parsed (see InvalidateFiles)
+	queryRoot  string
+	staleFiles map[string]bool
 }
 
 // FileInfo holds information about a parsed file
@@ -144,8 +314,37 @@ type FileInfo struct {
 	Sources     []*types.FlowNode
 	Assignments []*types.Assignment // Cached assignments for flow tracing (avoids re-parsing)
 	Calls       []*types.CallSite   // Cached calls for flow tracing (avoids re-parsing)
-	Root        *sitter.Node        // Only populated during parsing, released after
-	Content     []byte              // Only populated if NeedsReparse is false
+	// TemplateBindings records extract($vars) + include pairs (template variable binding)
+	TemplateBindings []*types.TemplateBinding
+	// SourceWrappers are functions defined here that directly return a superglobal
+	SourceWrappers []*types.SourceWrapper
+	// ReturnSummaries are functions defined here returning arrays with tainted keys
+	ReturnSummaries []*types.ReturnSummary
+	// Validators are functions defined here that throw or exit on invalid input
+	Validators []*types.Validator
+	// RequestAttributes are PSR-7 withAttribute() calls made here
+	RequestAttributes []*types.RequestAttribute
+	// TopLevelCode marks files that execute code when requested directly
+	TopLevelCode bool
+	// RealtimeHandlers are the WebSocket/SSE message handlers declared here
+	RealtimeHandlers []*types.RealtimeHandler
+	// RegisterLoops are the file-scope loops copying a superglobal into variables
+	RegisterLoops []*types.RegisterLoop
+	// RouteDispatches are the custom routers (switch or action map) here
+	RouteDispatches []*types.RouteDispatch
+	// AttributeRoutes are the routes declared by attributes on methods here
+	AttributeRoutes []*types.AttributeRoute
+	// Includes are the static include/require paths of this file, as written
+	Includes []string
+	// DeadRanges are the statements after an exit, return or throw in their block
+	DeadRanges []types.DeadRange
+	// Generated marks minified or generated files (see ClassifyGeneratedFile)
+	Generated GeneratedKind
+	// CalledNames are the lowercase functions and methods called here; derived
+	// sources are only searched for in files calling a promoted name
+	CalledNames map[string]bool
+	Root        *sitter.Node // Only populated during parsing, released after
+	Content     []byte       // Only populated if NeedsReparse is false
 	ParseTime   time.Duration
 	Error       error
 	// NeedsReparse indicates the file needs re-parsing for deeper analysis
@@ -159,13 +358,22 @@ type TraceStats struct {
 	FilesParsed      int
 	FilesSkipped     int
 	ParseErrors      int
+	MinifiedFiles    int // Minified files found (skipped or analyzed per Config.GeneratedFiles)
+	GeneratedFiles   int // Generated files found (skipped or analyzed per Config.GeneratedFiles)
 	SourcesFound     int
+	SourcesSkipped   int // Sources not traced because of Config.MaxTracedSources
+	SourcesDead      int // Sources in unreachable code (Metadata["unreachable"])
 	FlowsTraced      int
 	CrossFileFlows   int
 	TotalDuration    time.Duration
 	ParseDuration    time.Duration
 	AnalysisDuration time.Duration
+	CacheHits        int64  // Assignment lookups answered from the trace cache or index
+	CacheMisses      int64  // Assignment lookups that parsed the file
+	PeakHeapMB       uint64 // Highest heap in use sampled during the scan
 	ByLanguage       map[string]*LanguageStats
+	ByLayer          map[string]*LayerStats                    // Sources and flow nodes per architectural layer
+	Grammars         map[string]*languages.GrammarCapabilities // Grammar version and unparsable modern constructs per language
 }
 
 // LanguageStats holds per-language statistics
@@ -195,8 +403,40 @@ type TraceResult struct {
 	// Per-file symbol tables (for symbolic execution)
 	SymbolTable map[string]*types.SymbolTable
 
+	// Codebase-level framework detection report
+	Frameworks *FrameworkReport
+
+	// Auto-discovered and declared entry points
+	EntryPoints []*EntryPoint
+
+	// Sources found but not traced because of Config.MaxTracedSources
+	SkippedSources []*types.FlowNode
+
+	// Runtime log used to resolve dynamic constructs (Config.RuntimeHintsFile)
+	RuntimeHints *RuntimeHints
+
+	// Container image or tarball scanned (TraceArtifact); paths are in-artifact
+	Artifact *Artifact
+
+	// Files, parses, cache hits, depth and wall time the scan consumed, and
+	// the limits it ran into
+	Budget *types.BudgetReport
+
+	// Incomplete is non-nil when analysis stopped early and results are partial;
+	// errors.Is(Incomplete, ErrMemoryLimit) reports a memory-limit cutoff
+	Incomplete error
+
+	// Analysis gaps (unresolved includes, depth cutoffs, parse errors, ...)
+	// and their number per category, including warnings past Config.MaxWarnings
+	Warnings      []types.AnalysisWarning
+	WarningCounts map[types.WarningCategory]int
+
 	// Statistics
 	Stats *TraceStats
+
+	parserService *parser.Service // Reads code context for exports (see WriteOptions)
+	redacted      bool            // Snippets were redacted; exports embed no code context
+	tracer        *Tracer         // Tracer whose caches DeepenPath re-traces on
 }
 
 // TraceContext provides per-trace-invocation isolation for thread safety
@@ -208,7 +448,16 @@ type TraceResult struct {
 type TraceContext struct {
 	phpParser        *sitter.Parser
 	jsParser         *sitter.Parser
-	assignmentsCache map[string][]*types.Assignment // ONLY cache assignments, NOT ASTs
+	assignmentsCache map[string][]*types.Assignment                        // ONLY cache assignments, NOT ASTs
+	indexed          map[string][]*types.Assignment                        // Read-only assignments from an index file
+	stop             types.TerminationReason                               // Most severe wall hit by the current backward search
+	assignmentsSeen  int                                                   // Assignments matched by backward searches so far
+	stats            *TraceStats                                           // Receives cache hits and misses (nil for none)
+	budget           *types.BudgetMeter                                    // Budget of the trace using the context (nil for none)
+	readFile         func(string) ([]byte, error)                          // Reads files on a cache miss (sandbox-checked)
+	analyzerFor      func(path, language string) analyzer.LanguageAnalyzer // Analyzer of a file's language (see Tracer.languageAnalyzer)
+	templateBindings map[string][]*types.TemplateBinding                   // Template bindings by included path (see templateBindingsFor)
+	templateReads    map[string]map[string]int                             // First read line of each template variable (see templateVariableLine)
 	mu               sync.RWMutex
 }
 
@@ -223,6 +472,40 @@ func newTraceContext() *TraceContext {
 		phpParser:        phpParser,
 		jsParser:         jsParser,
 		assignmentsCache: make(map[string][]*types.Assignment, 64), // Only cache assignments, NOT ASTs
+		readFile:         os.ReadFile,
+		analyzerFor:      defaultAnalyzer,
+	}
+}
+
+// defaultAnalyzer returns the built-in analyzer of a language, for trace
+// contexts not created by a Tracer
+func defaultAnalyzer(_, language string) analyzer.LanguageAnalyzer {
+	return analyzer.DefaultRegistry.Get(language)
+}
+
+// traceContext creates a trace context that reads assignments from the loaded
+// index, if any, before parsing
+func (t *Tracer) traceContext() *TraceContext {
+	ctx := newTraceContext()
+	ctx.indexed = t.indexedAssignments
+	ctx.stats = t.stats
+	ctx.budget = t.budget
+	ctx.readFile = t.readFile
+	ctx.analyzerFor = t.languageAnalyzer
+	return ctx
+}
+
+// countLookup counts an assignment lookup in the tracer's cache statistics
+func (ctx *TraceContext) countLookup(hit bool) {
+	if hit {
+		ctx.budget.CountCacheHit()
+	}
+	switch {
+	case ctx.stats == nil:
+	case hit:
+		atomic.AddInt64(&ctx.stats.CacheHits, 1)
+	default:
+		atomic.AddInt64(&ctx.stats.CacheMisses, 1)
 	}
 }
 
@@ -232,6 +515,8 @@ func (ctx *TraceContext) Close() {
 	defer ctx.mu.Unlock()
 	// No AST cache to clean up - we only cache assignments (tiny)
 	ctx.assignmentsCache = nil
+	ctx.templateBindings = nil
+	ctx.templateReads = nil
 }
 
 // getParser returns the parser for a language
@@ -249,16 +534,24 @@ func (ctx *TraceContext) getParser(language string) *sitter.Parser {
 // getAssignmentsDirectly parses a file, extracts assignments, and IMMEDIATELY discards the AST
 // This is memory-efficient: ASTs are huge (5-10x source size), assignments are tiny
 func (ctx *TraceContext) getAssignmentsDirectly(filePath string, language string) []*types.Assignment {
+	ctx.budget.TouchFile(filePath)
+
 	// Check cache first (fast path)
 	ctx.mu.RLock()
 	if cached, ok := ctx.assignmentsCache[filePath]; ok {
 		ctx.mu.RUnlock()
+		ctx.countLookup(true)
 		return cached
 	}
 	ctx.mu.RUnlock()
+	if indexed, ok := ctx.indexed[filePath]; ok {
+		ctx.countLookup(true)
+		return indexed
+	}
 
 	// Cache miss: parse → extract → discard AST
-	content, err := os.ReadFile(filePath)
+	ctx.countLookup(false)
+	content, err := ctx.readFile(filePath)
 	if err != nil {
 		return nil
 	}
@@ -272,16 +565,18 @@ func (ctx *TraceContext) getAssignmentsDirectly(filePath string, language string
 	if err != nil || tree == nil {
 		return nil
 	}
+	ctx.budget.CountParse()
 
 	root := tree.RootNode()
 
 	// Extract assignments
-	langAnalyzer := analyzer.DefaultRegistry.Get(language)
+	langAnalyzer := ctx.analyzerFor(filePath, language)
 	if langAnalyzer == nil {
 		tree.Close() // Don't leak memory
 		return nil
 	}
 	assignments, _ := langAnalyzer.ExtractAssignments(root, content, "")
+	assignments = pruneDeadAssignments(findDeadRanges(langAnalyzer, root, content), assignments)
 
 	// CRITICAL: Close the tree immediately to release AST memory
 	// Assignments are copied strings, safe to use after tree.Close()
@@ -309,15 +604,9 @@ func New(config *Config) *Tracer {
 	// MEMORY FIX: Set strict memory limit for all modes to prevent OOM
 	// Target: stay under 200MB for any analysis (library requirement)
 	// Note: runtime.MemStats.Sys underreports actual memory usage by ~30-50%
-	// so we use a lower internal limit to stay under 200MB external
-	if config.MaxMemoryMB == 0 {
-		config.MaxMemoryMB = 120 // 120MB internal -> ~180MB external
-	}
-
-	// Keep MaxDepth at default 10 for thorough analysis
-	if config.MaxDepth == 0 {
-		config.MaxDepth = 10
-	}
+	// so profiles other than deep use a lower internal limit to stay under
+	// 200MB external
+	applyProfile(config)
 
 	// Create parser service with LRU cache for on-demand AST access
 	// Small cache to limit memory usage
@@ -334,10 +623,18 @@ func New(config *Config) *Tracer {
 		symbolTable: &types.SymbolTable{
 			Classes:   make(map[string]*types.ClassDef),
 			Functions: make(map[string]*types.FunctionDef),
+			Constants: make(map[string]*types.ConstantDef),
 		},
 		stats: &TraceStats{
 			ByLanguage: make(map[string]*LanguageStats),
+			Grammars:   make(map[string]*languages.GrammarCapabilities),
 		},
+		warnings:  newWarningCollector(config),
+		interner:  newStringInterner(),
+		sandbox:   types.NewSandbox(config.SandboxRoots),
+		yielder:   types.NewYielder(config.YieldEvery, config.YieldHook),
+		ruleSet:   sources.NewRuleSet(),
+		analyzers: analyzer.DefaultRegistry,
 	}
 
 	// Initialize parsers for all languages
@@ -348,7 +645,26 @@ func New(config *Config) *Tracer {
 
 // initParsers initializes tree-sitter parsers for available languages
 func (t *Tracer) initParsers() {
-	languages := map[string]*sitter.Language{
+	grammars := supportedGrammars()
+
+	for name, lang := range grammars {
+		parser := sitter.NewParser()
+		parser.SetLanguage(lang)
+		t.parsers[name] = parser
+		t.stats.Grammars[name] = languages.CheckCapabilities(name, lang)
+	}
+
+	// Also register with parser service for on-demand parsing
+	if t.parserService != nil {
+		for name, lang := range grammars {
+			t.parserService.RegisterLanguage(name, lang)
+		}
+	}
+}
+
+// supportedGrammars returns the tree-sitter grammars by language name
+func supportedGrammars() map[string]*sitter.Language {
+	return map[string]*sitter.Language{
 		// PHP
 		"php": php.GetLanguage(),
 		// JavaScript/TypeScript
@@ -369,19 +685,10 @@ func (t *Tracer) initParsers() {
 		"ruby": ruby.GetLanguage(),
 		// Rust
 		"rust": rust.GetLanguage(),
-	}
-
-	for name, lang := range languages {
-		parser := sitter.NewParser()
-		parser.SetLanguage(lang)
-		t.parsers[name] = parser
-	}
-
-	// Also register with parser service for on-demand parsing
-	if t.parserService != nil {
-		for name, lang := range languages {
-			t.parserService.RegisterLanguage(name, lang)
-		}
+		// Kotlin
+		"kotlin": kotlin.GetLanguage(),
+		// Swift
+		"swift": swift.GetLanguage(),
 	}
 }
 
@@ -403,12 +710,29 @@ func (t *Tracer) Close() {
 	t.symbolTable = &types.SymbolTable{
 		Classes:   make(map[string]*types.ClassDef),
 		Functions: make(map[string]*types.FunctionDef),
+		Constants: make(map[string]*types.ConstantDef),
 	}
+
+	t.warnings = newWarningCollector(t.config)
 }
 
 // ParseOnly parses files and builds symbol tables without flow analysis (fast mode for symbolic tracing)
 func (t *Tracer) ParseOnly(path string) (*TraceResult, error) {
 	startTime := time.Now()
+	t.budget = types.NewBudgetMeter()
+	defer t.applyMemoryLimit()()
+	defer t.interner.release()
+
+	if err := t.config.checkProfile(); err != nil {
+		return nil, err
+	}
+	if err := t.loadRules(); err != nil {
+		return nil, err
+	}
+	t.applyFrameworkVersions(path)
+	if err := t.loadRuntimeHints(); err != nil {
+		return nil, err
+	}
 
 	// Phase 1: Discover files
 	if t.config.Verbose {
@@ -436,7 +760,14 @@ func (t *Tracer) ParseOnly(path string) (*TraceResult, error) {
 		fmt.Printf("[Phase 2] Parsing files (workers: %d)\n", t.config.Workers)
 	}
 	parseStart := time.Now()
+	t.queryRoot = path
 	t.parseFiles(files)
+	t.reportGrammarGaps()
+	t.buildIncludeGraph(path)
+	t.promoteSourceWrappers()
+	t.promoteReturnSummaries()
+	t.promoteRequestAttributes()
+	t.promoteValidators()
 	t.stats.ParseDuration = time.Since(parseStart)
 
 	if t.config.Verbose {
@@ -449,6 +780,7 @@ func (t *Tracer) ParseOnly(path string) (*TraceResult, error) {
 		fmt.Printf("[Phase 3] Building global symbol table\n")
 	}
 	t.buildGlobalSymbolTable()
+	t.resolveConstantKeys()
 
 	if t.config.Verbose {
 		fmt.Printf("  Classes: %d, Functions: %d\n",
@@ -480,6 +812,12 @@ func (t *Tracer) ParseOnly(path string) (*TraceResult, error) {
 		Files:             t.files,
 		GlobalSymbolTable: t.symbolTable,
 		SymbolTable:       perFileSymbolTables,
+		Frameworks:        t.buildFrameworkReport(path),
+		EntryPoints:       t.buildEntryPoints(path),
+		Budget:            t.budgetReport(nil),
+		Incomplete:        t.incomplete,
+		Warnings:          t.warnings.Warnings(),
+		WarningCounts:     t.warnings.Counts(),
 		Stats:             t.stats,
 	}, nil
 }
@@ -487,6 +825,21 @@ func (t *Tracer) ParseOnly(path string) (*TraceResult, error) {
 // TraceDirectory performs semantic tracing on a directory
 func (t *Tracer) TraceDirectory(path string) (*TraceResult, error) {
 	startTime := time.Now()
+	t.traceStart = startTime
+	t.budget = types.NewBudgetMeter()
+	defer t.applyMemoryLimit()()
+	defer t.interner.release()
+
+	if err := t.config.checkProfile(); err != nil {
+		return nil, err
+	}
+	if err := t.loadRules(); err != nil {
+		return nil, err
+	}
+	t.applyFrameworkVersions(path)
+	if err := t.loadRuntimeHints(); err != nil {
+		return nil, err
+	}
 
 	// Phase 1: Discover and filter files
 	if t.config.Verbose {
@@ -514,8 +867,17 @@ func (t *Tracer) TraceDirectory(path string) (*TraceResult, error) {
 		fmt.Printf("[Phase 2] Parsing files (workers: %d)\n", t.config.Workers)
 	}
 	parseStart := time.Now()
+	t.queryRoot = path
 	t.parseFiles(files)
+	t.reportGrammarGaps()
+	t.buildIncludeGraph(path)
+	t.promoteSourceWrappers()
+	t.promoteReturnSummaries()
+	t.promoteRequestAttributes()
+	t.promoteValidators()
+	t.promoteRouteDispatches()
 	t.stats.ParseDuration = time.Since(parseStart)
+	t.recordHeap()
 
 	if t.config.Verbose {
 		fmt.Printf("  Parsed %d files (%d errors) in %v\n",
@@ -527,6 +889,7 @@ func (t *Tracer) TraceDirectory(path string) (*TraceResult, error) {
 		fmt.Printf("[Phase 3] Building global symbol table\n")
 	}
 	t.buildGlobalSymbolTable()
+	t.resolveConstantKeys()
 
 	if t.config.Verbose {
 		fmt.Printf("  Classes: %d, Functions: %d\n",
@@ -543,19 +906,30 @@ func (t *Tracer) TraceDirectory(path string) (*TraceResult, error) {
 		fmt.Printf("[Phase 4] Collecting input sources\n")
 	}
 	sources := t.collectSources()
+	t.applyValidators(sources)
+	t.labelSources(sources, path)
+	t.applyRuntimeKeys(sources, path)
 	t.stats.SourcesFound = len(sources)
+	t.recordHeap()
 
 	if t.config.Verbose {
 		fmt.Printf("  Found %d input sources\n", len(sources))
 	}
 
 	// Phase 5: Cross-file flow analysis
+	t.entryPoints = t.buildEntryPoints(path)
 	if t.config.Verbose {
 		fmt.Printf("[Phase 5] Cross-file flow analysis\n")
 	}
 	analysisStart := time.Now()
-	flowMap := t.traceAllFlows(sources, path)
+	var flowMap *types.FlowMap
+	if t.config.tracesFlows() {
+		flowMap = t.traceAllFlows(sources, path)
+	} else {
+		flowMap = t.sourcesOnlyFlowMap(sources)
+	}
 	t.stats.AnalysisDuration = time.Since(analysisStart)
+	t.recordHeap()
 
 	if t.config.Verbose {
 		fmt.Printf("  Traced %d flows (%d cross-file) in %v\n",
@@ -566,6 +940,19 @@ func (t *Tracer) TraceDirectory(path string) (*TraceResult, error) {
 	// This frees large strings that are no longer needed
 	t.releaseBodySources()
 
+	if t.config.tracesFlows() {
+		t.linkRouteHandlers(flowMap)
+	}
+	t.mu.Lock()
+	t.flowMap = flowMap
+	t.mu.Unlock()
+	t.noteDecoding(flowMap)
+	propagateLabels(flowMap)
+	t.tagLayers(sources, flowMap, path)
+
+	// Share repeated node strings and snippets across the flow map
+	t.interner.internFlowMap(flowMap)
+
 	t.stats.TotalDuration = time.Since(startTime)
 
 	if t.config.Verbose {
@@ -581,14 +968,28 @@ func (t *Tracer) TraceDirectory(path string) (*TraceResult, error) {
 		}
 	}
 
-	return &TraceResult{
+	result := &TraceResult{
 		Sources:           sources,
 		FlowMap:           flowMap,
 		Files:             t.files,
 		GlobalSymbolTable: t.symbolTable,
 		SymbolTable:       perFileSymbolTables,
+		Frameworks:        t.buildFrameworkReport(path),
+		EntryPoints:       t.entryPoints,
+		SkippedSources:    t.skippedSources,
+		Budget:            t.budgetReport(flowMap),
+		Incomplete:        t.incomplete,
+		Warnings:          t.warnings.Warnings(),
+		WarningCounts:     t.warnings.Counts(),
 		Stats:             t.stats,
-	}, nil
+		RuntimeHints:      t.runtimeHints,
+		parserService:     t.parserService,
+		tracer:            t,
+	}
+	if t.config.RedactSnippets {
+		result.RedactSnippets()
+	}
+	return result, nil
 }
 
 // TraceFile performs semantic tracing on a single file
@@ -613,12 +1014,9 @@ func (t *Tracer) TraceBackwardBatch(targets []string, codebasePath string) (*typ
 		}, nil
 	}
 
-	// First parse the codebase if not already done
-	if len(t.files) == 0 {
-		_, err := t.ParseOnly(codebasePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse codebase: %w", err)
-		}
+	// First parse the codebase (or load its index) if not already done
+	if err := t.prepareQueries(codebasePath); err != nil {
+		return nil, err
 	}
 
 	result := &types.BatchTraceResult{
@@ -652,14 +1050,18 @@ func (t *Tracer) TraceBackwardBatch(targets []string, codebasePath string) (*typ
 	// Collect all file paths for processing
 	t.mu.RLock()
 	filePaths := make([]string, 0, len(t.files))
-	for filePath := range t.files {
+	for filePath, fileInfo := range t.files {
+		if fileInfo.Generated != GeneratedNone && t.generatedFileMode() == GeneratedSkip {
+			continue // Minified/generated files are excluded from backward traces too
+		}
 		filePaths = append(filePaths, filePath)
 	}
 	t.mu.RUnlock()
 
 	// CRITICAL: Create ONE shared TraceContext for ALL variables
 	// This is the key optimization - the assignment cache is shared!
-	ctx := newTraceContext()
+	ctx := t.traceContext()
+	ctx.budget = types.NewBudgetMeter()
 	defer ctx.Close()
 
 	// Global dedup map for sources
@@ -697,6 +1099,8 @@ func (t *Tracer) TraceBackwardBatch(targets []string, codebasePath string) (*typ
 
 			// Found an assignment to one of our targets
 			varResult := result.PerVariable[originalTarget]
+			ctx.stop = ""
+			found := len(varResult.Paths)
 
 			path := types.BackwardPath{
 				Steps:     make([]types.BackwardStep, 0),
@@ -713,6 +1117,7 @@ func (t *Tracer) TraceBackwardBatch(targets []string, codebasePath string) (*typ
 			})
 
 			// Check if the source is a superglobal
+			ctx.budget.CountRegex(1) // Source patterns
 			sourceInfo := t.identifySource(assign.Source, filePath, assign.Line)
 			if sourceInfo != nil {
 				path.Source = *sourceInfo
@@ -737,7 +1142,7 @@ func (t *Tracer) TraceBackwardBatch(targets []string, codebasePath string) (*typ
 				result.VariablesFound++
 			} else if strings.HasPrefix(assign.Source, "$") {
 				// The source is another variable - trace recursively WITH SHARED CONTEXT
-				innerSources := t.traceBackwardRecursiveWithContext(ctx, assign.Source, filePath, make(map[string]bool), 0)
+				innerSources := t.traceBackwardRecursiveWithContext(ctx, assign.Source, assign.Scope, filePath, make(map[string]bool), 0)
 				for _, innerSource := range innerSources {
 					innerPath := types.BackwardPath{
 						Source:    innerSource,
@@ -781,13 +1186,37 @@ func (t *Tracer) TraceBackwardBatch(targets []string, codebasePath string) (*typ
 					result.VariablesFound++
 				}
 			}
+			if len(varResult.Paths) == found {
+				varResult.DeadEnds = append(varResult.DeadEnds, t.deadEnd(ctx, assignTarget, assign, filePath))
+			}
 		}
 	}
 
-	// Set durations for all per-variable results
+	// Template variables bound by extract() in an including file
+	for _, filePath := range filePaths {
+		for targetVar, originalTarget := range targetVars {
+			varResult := result.PerVariable[originalTarget]
+			for _, path := range t.templatePaths(ctx, filePath, targetVar) {
+				varResult.Paths = append(varResult.Paths, path)
+				sourceKey := fmt.Sprintf("%s:%s", path.Source.Type, path.Source.Expression)
+				if !seenSources[originalTarget][sourceKey] {
+					seenSources[originalTarget][sourceKey] = true
+					varResult.Sources = append(varResult.Sources, path.Source)
+				}
+				result.HasUserInput = true
+				result.VariablesFound++
+			}
+		}
+	}
+
+	// Set durations and the shared budget for all per-variable results
 	totalDuration := time.Since(startTime)
+	report := ctx.budget.Report()
 	for _, varResult := range result.PerVariable {
+		t.annotateIncludeChains(varResult.Paths)
+		terminate(varResult)
 		varResult.Duration = totalDuration
+		varResult.Budget = report
 	}
 	result.TotalDuration = totalDuration
 
@@ -799,12 +1228,9 @@ func (t *Tracer) TraceBackwardBatch(targets []string, codebasePath string) (*typ
 func (t *Tracer) TraceBackward(target string, codebasePath string) (*types.BackwardTraceResult, error) {
 	startTime := time.Now()
 
-	// First parse the codebase if not already done
-	if len(t.files) == 0 {
-		_, err := t.ParseOnly(codebasePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse codebase: %w", err)
-		}
+	// First parse the codebase (or load its index) if not already done
+	if err := t.prepareQueries(codebasePath); err != nil {
+		return nil, err
 	}
 
 	result := &types.BackwardTraceResult{
@@ -813,6 +1239,7 @@ func (t *Tracer) TraceBackward(target string, codebasePath string) (*types.Backw
 		Sources:          make([]types.SourceInfo, 0),
 		AnalyzedFiles:    len(t.files),
 	}
+	budget := types.NewBudgetMeter()
 
 	// Clean target variable name
 	targetVar := strings.TrimSpace(target)
@@ -823,20 +1250,25 @@ func (t *Tracer) TraceBackward(target string, codebasePath string) (*types.Backw
 	// Collect all file paths for parallel processing (with lock)
 	t.mu.RLock()
 	filePaths := make([]string, 0, len(t.files))
-	for filePath := range t.files {
+	for filePath, fileInfo := range t.files {
+		if fileInfo.Generated != GeneratedNone && t.generatedFileMode() == GeneratedSkip {
+			continue // Minified/generated files are excluded from backward traces too
+		}
 		filePaths = append(filePaths, filePath)
 	}
 	t.mu.RUnlock()
 
 	// If few files, process sequentially with single context
 	if len(filePaths) <= 4 {
-		ctx := newTraceContext()
+		ctx := t.traceContext()
+		ctx.budget = budget
 		defer ctx.Close()
 
 		seenSources := make(map[string]bool)
 		for _, filePath := range filePaths {
-			paths, sources := t.traceBackwardInFileWithContext(ctx, filePath, targetVar)
+			paths, sources, deadEnds := t.traceBackwardInFileWithContext(ctx, filePath, targetVar)
 			result.Paths = append(result.Paths, paths...)
+			result.DeadEnds = append(result.DeadEnds, deadEnds...)
 			for _, src := range sources {
 				sourceKey := fmt.Sprintf("%s:%s", src.Type, src.Expression)
 				if !seenSources[sourceKey] {
@@ -845,7 +1277,10 @@ func (t *Tracer) TraceBackward(target string, codebasePath string) (*types.Backw
 				}
 			}
 		}
+		t.annotateIncludeChains(result.Paths)
+		terminate(result)
 		result.Duration = time.Since(startTime)
+		result.Budget = budget.Report()
 		return result, nil
 	}
 
@@ -871,8 +1306,9 @@ func (t *Tracer) TraceBackward(target string, codebasePath string) (*types.Backw
 
 	// Worker results
 	type workerResult struct {
-		paths   []types.BackwardPath
-		sources []types.SourceInfo
+		paths    []types.BackwardPath
+		sources  []types.SourceInfo
+		deadEnds []types.BackwardPath
 	}
 	results := make(chan workerResult, numWorkers)
 
@@ -884,19 +1320,22 @@ func (t *Tracer) TraceBackward(target string, codebasePath string) (*types.Backw
 			defer wg.Done()
 
 			// Each worker gets its own context (thread-safe, caches AST within worker)
-			ctx := newTraceContext()
+			ctx := t.traceContext()
+			ctx.budget = budget
 			defer ctx.Close()
 
 			localPaths := make([]types.BackwardPath, 0, 16)
 			localSources := make([]types.SourceInfo, 0, 8)
+			var localDeadEnds []types.BackwardPath
 
 			for filePath := range pathChan {
-				paths, sources := t.traceBackwardInFileWithContext(ctx, filePath, targetVar)
+				paths, sources, deadEnds := t.traceBackwardInFileWithContext(ctx, filePath, targetVar)
 				localPaths = append(localPaths, paths...)
 				localSources = append(localSources, sources...)
+				localDeadEnds = append(localDeadEnds, deadEnds...)
 			}
 
-			results <- workerResult{localPaths, localSources}
+			results <- workerResult{localPaths, localSources, localDeadEnds}
 		}()
 	}
 
@@ -910,6 +1349,7 @@ func (t *Tracer) TraceBackward(target string, codebasePath string) (*types.Backw
 	seenSources := make(map[string]bool)
 	for wr := range results {
 		result.Paths = append(result.Paths, wr.paths...)
+		result.DeadEnds = append(result.DeadEnds, wr.deadEnds...)
 		for _, src := range wr.sources {
 			sourceKey := fmt.Sprintf("%s:%s", src.Type, src.Expression)
 			if !seenSources[sourceKey] {
@@ -919,14 +1359,25 @@ func (t *Tracer) TraceBackward(target string, codebasePath string) (*types.Backw
 		}
 	}
 
+	t.annotateIncludeChains(result.Paths)
+	terminate(result)
 	result.Duration = time.Since(startTime)
+	result.Budget = budget.Report()
 	return result, nil
 }
 
 // traceBackwardInFileWithContext processes a single file for backward tracing using a TraceContext
-func (t *Tracer) traceBackwardInFileWithContext(ctx *TraceContext, filePath string, targetVar string) ([]types.BackwardPath, []types.SourceInfo) {
+// It also returns the dead ends: assignments to targetVar whose trace reached no source
+func (t *Tracer) traceBackwardInFileWithContext(ctx *TraceContext, filePath string, targetVar string) ([]types.BackwardPath, []types.SourceInfo, []types.BackwardPath) {
+	return t.traceBackwardInScope(ctx, filePath, targetVar, nil)
+}
+
+// traceBackwardInScope is traceBackwardInFileWithContext limited to the
+// assignments whose (resolved) scope inScope accepts; nil accepts every scope
+func (t *Tracer) traceBackwardInScope(ctx *TraceContext, filePath string, targetVar string, inScope func(scope string) bool) ([]types.BackwardPath, []types.SourceInfo, []types.BackwardPath) {
 	paths := make([]types.BackwardPath, 0)
 	sources := make([]types.SourceInfo, 0)
+	var deadEnds []types.BackwardPath
 
 	// Lock for reading t.files to get file metadata
 	t.mu.RLock()
@@ -934,15 +1385,12 @@ func (t *Tracer) traceBackwardInFileWithContext(ctx *TraceContext, filePath stri
 	t.mu.RUnlock()
 
 	if fileInfo == nil {
-		return paths, sources
+		return paths, sources, deadEnds
 	}
 
 	// Get cached assignments (parses → extracts → immediately discards AST)
 	// This is memory-efficient: only assignments are cached, not ASTs
 	assignments := ctx.getAssignmentsDirectly(filePath, fileInfo.Language)
-	if assignments == nil {
-		return paths, sources
-	}
 
 	for _, assign := range assignments {
 		// Check if this assignment is to our target variable
@@ -950,12 +1398,22 @@ func (t *Tracer) traceBackwardInFileWithContext(ctx *TraceContext, filePath stri
 		if assignTarget != targetVar {
 			continue
 		}
+		if inScope != nil && !inScope(t.variableScope(filePath, assign.Scope, targetVar)) {
+			continue
+		}
 
 		// Found an assignment to target - trace backward from source
 		path := types.BackwardPath{
 			Steps:     make([]types.BackwardStep, 0),
 			CrossFile: false,
 		}
+		ctx.stop = ""
+		found := len(paths)
+
+		// $t[0] and substr($t, 0, 8) still carry $t's input, truncated
+		operand, truncation := phpPatterns.PartialRead(assign.Source)
+		path.Truncated = len(truncation) > 0
+		ctx.budget.CountRegex(2) // Partial read and source patterns
 
 		// Add the assignment as a step
 		path.Steps = append(path.Steps, types.BackwardStep{
@@ -983,14 +1441,16 @@ func (t *Tracer) traceBackwardInFileWithContext(ctx *TraceContext, filePath stri
 			paths = append(paths, path)
 			sources = append(sources, *sourceInfo)
 		} else {
-			// The source might be another variable - trace recursively
-			if strings.HasPrefix(assign.Source, "$") {
-				innerSources := t.traceBackwardRecursiveWithContext(ctx, assign.Source, filePath, make(map[string]bool), 0)
+			// The source might be another variable - trace recursively within
+			// the scope of this assignment
+			if strings.HasPrefix(operand, "$") {
+				innerSources := t.traceBackwardRecursiveWithContext(ctx, operand, assign.Scope, filePath, make(map[string]bool), 0)
 				for _, innerSource := range innerSources {
 					innerPath := types.BackwardPath{
 						Source:    innerSource,
 						Steps:     make([]types.BackwardStep, 0),
 						CrossFile: innerSource.FilePath != filePath,
+						Truncated: path.Truncated,
 					}
 					innerPath.Steps = append(innerPath.Steps, types.BackwardStep{
 						StepNumber:  0,
@@ -1021,57 +1481,56 @@ func (t *Tracer) traceBackwardInFileWithContext(ctx *TraceContext, filePath stri
 					sources = append(sources, innerSource)
 				}
 			}
+			if len(paths) == found {
+				deadEnds = append(deadEnds, t.deadEnd(ctx, targetVar, assign, filePath))
+			}
 		}
 	}
 
-	return paths, sources
+	// A template variable without its own assignment may be bound by extract()
+	// in a file that includes this one, at its file level
+	if inScope != nil && !inScope("") {
+		return paths, sources, deadEnds
+	}
+	for _, path := range t.templatePaths(ctx, filePath, targetVar) {
+		paths = append(paths, path)
+		sources = append(sources, path.Source)
+	}
+
+	return paths, sources, deadEnds
 }
 
 // traceBackwardRecursiveWithContext recursively traces backward with caching and early termination
-func (t *Tracer) traceBackwardRecursiveWithContext(ctx *TraceContext, varExpr string, startFile string, visited map[string]bool, depth int) []types.SourceInfo {
-	if depth > t.config.MaxDepth {
-		return nil
-	}
-
-	// Prevent infinite loops
-	visitKey := fmt.Sprintf("%s:%s", startFile, varExpr)
-	if visited[visitKey] {
-		return nil
+// scope is the scope-qualified owner of varExpr ("" for file-level code); function
+// locals only resolve within that function, file-level variables across files
+func (t *Tracer) traceBackwardRecursiveWithContext(ctx *TraceContext, varExpr string, scope string, startFile string, visited map[string]bool, depth int) []types.SourceInfo {
+	if t.traceStrategy() == BreadthFirst {
+		return t.traceBackwardBreadthFirst(ctx, varExpr, scope, startFile, visited, depth)
 	}
-	visited[visitKey] = true
-
 	var sources []types.SourceInfo
-	varName := strings.TrimPrefix(strings.TrimSpace(varExpr), "$")
+	t.searchVar(ctx, backwardTarget{expr: varExpr, scope: scope, file: startFile, depth: depth}, visited, &sources, nil)
+	return sources
+}
 
-	// OPTIMIZATION 1: Search current file FIRST (most common case)
-	if found := t.searchFileForVar(ctx, startFile, varName, visited, depth, &sources); found {
-		return sources // Early termination - found source!
+// variableScope returns the scope owning a variable used in a scope of a
+// file: the file scope ("") when that function declares it global
+func (t *Tracer) variableScope(filePath, scope, varName string) string {
+	if scope == "" {
+		return ""
 	}
-
-	// OPTIMIZATION 2: Only search other files if not found in current
-	// Use read lock, NO map copying
 	t.mu.RLock()
-	filePaths := make([]string, 0, len(t.files))
-	for fp := range t.files {
-		if fp != startFile { // Skip already-searched file
-			filePaths = append(filePaths, fp)
-		}
-	}
+	fileInfo := t.files[filePath]
 	t.mu.RUnlock()
-
-	// OPTIMIZATION 3: Search other files with early termination
-	for _, filePath := range filePaths {
-		if found := t.searchFileForVar(ctx, filePath, varName, visited, depth, &sources); found {
-			return sources // Early termination - found source!
-		}
+	if fileInfo == nil || fileInfo.SymbolTable == nil {
+		return scope
 	}
-
-	return sources
+	return fileInfo.SymbolTable.Scopes.ResolveVariable(scope, varName)
 }
 
-// searchFileForVar searches a single file for variable assignments
-// Returns true if a source was found (for early termination)
-func (t *Tracer) searchFileForVar(ctx *TraceContext, filePath string, varName string, visited map[string]bool, depth int, sources *[]types.SourceInfo) bool {
+// searchFileForVar searches a single file for variable assignments in the given scope
+// Returns true if a source was found (for early termination). Variables assigned
+// from other variables are queued on next when it is non-nil (breadth-first).
+func (t *Tracer) searchFileForVar(ctx *TraceContext, filePath string, varName string, scope string, sameFile bool, visited map[string]bool, depth int, sources *[]types.SourceInfo, next *[]backwardTarget) bool {
 	// Get file language
 	t.mu.RLock()
 	fileInfo := t.files[filePath]
@@ -1088,6 +1547,12 @@ func (t *Tracer) searchFileForVar(ctx *TraceContext, filePath string, varName st
 		if strings.TrimPrefix(assign.Target, "$") != varName {
 			continue
 		}
+		if !types.SameVariableScope(t.variableScope(filePath, assign.Scope, varName), scope, sameFile) {
+			continue
+		}
+		ctx.assignmentsSeen++
+		ctx.budget.CountRegex(1) // Source patterns
+		t.yielder.Tick()
 
 		// Check if source is user input
 		if sourceInfo := t.identifySource(assign.Source, filePath, assign.Line); sourceInfo != nil {
@@ -1095,14 +1560,35 @@ func (t *Tracer) searchFileForVar(ctx *TraceContext, filePath string, varName st
 			return true // FOUND! Early termination
 		}
 
-		// Recurse if source is another variable
-		if strings.HasPrefix(assign.Source, "$") {
-			innerSources := t.traceBackwardRecursiveWithContext(ctx, assign.Source, filePath, visited, depth+1)
+		// Recurse if source is another variable, or a string offset or
+		// substring of one
+		ctx.budget.CountRegex(2) // Partial read and plain variable patterns
+		operand, _ := phpPatterns.PartialRead(assign.Source)
+		if strings.HasPrefix(operand, "$") && next != nil {
+			*next = append(*next, backwardTarget{expr: operand, scope: assign.Scope, file: filePath, depth: depth + 1})
+		} else if strings.HasPrefix(operand, "$") {
+			innerSources := t.traceBackwardRecursiveWithContext(ctx, operand, assign.Scope, filePath, visited, depth+1)
 			if len(innerSources) > 0 {
 				*sources = append(*sources, innerSources...)
 				return true // FOUND! Early termination
 			}
 		}
+		if !phpPatterns.PlainVariablePattern.MatchString(operand) {
+			ctx.stopAt(t.stopReason(ctx, assign.Source))
+		}
+	}
+
+	// File-level variables of a template may be bound by an includer's extract()
+	// or by a register_globals loop of the file
+	if scope == "" && sameFile {
+		if sourceInfo := registeredVariableSource(fileInfo, varName); sourceInfo != nil {
+			*sources = append(*sources, *sourceInfo)
+			return true
+		}
+		if templateSources := t.traceTemplateVariable(ctx, filePath, varName, visited, depth); len(templateSources) > 0 {
+			*sources = append(*sources, templateSources...)
+			return true
+		}
 	}
 
 	return false
@@ -1112,15 +1598,28 @@ func (t *Tracer) searchFileForVar(ctx *TraceContext, filePath string, varName st
 func (t *Tracer) identifySource(expr string, filePath string, line int) *types.SourceInfo {
 	expr = strings.TrimSpace(expr)
 
+	// Check command line arguments first: $_SERVER['argv'] is not a header, and
+	// $argv is only input where parsing found it to be the script's global
+	if phpPatterns.IsCLIArgExpression(expr) ||
+		(phpPatterns.IsCLIArgVariableExpression(expr) && t.hasSourceOnLine(filePath, line, types.SourceCLIArg)) {
+		return &types.SourceInfo{
+			Type:       types.SourceCLIArg,
+			Expression: expr,
+			FilePath:   filePath,
+			Line:       line,
+		}
+	}
+
 	// Check PHP superglobals (using centralized definitions from pkg/sources)
-	for sg, sourceType := range sources.SuperglobalToSourceType {
-		if strings.Contains(expr, sg) {
-			return &types.SourceInfo{
-				Type:       types.SourceType(sourceType), // Convert sources.SourceType to types.SourceType
-				Expression: expr,
-				FilePath:   filePath,
-				Line:       line,
-			}
+	sg := sources.FindSuperglobal(expr, func(name string) bool {
+		return name != "$_SERVER" || !t.onlyTrustedServerKeys(expr)
+	})
+	if sg != "" {
+		return &types.SourceInfo{
+			Type:       types.SourceType(sources.SuperglobalToSourceType[sg]), // Convert sources.SourceType to types.SourceType
+			Expression: expr,
+			FilePath:   filePath,
+			Line:       line,
 		}
 	}
 
@@ -1136,7 +1635,7 @@ func (t *Tracer) identifySource(expr string, filePath string, line int) *types.S
 	}
 
 	// Check property array access and method call patterns using centralized patterns
-	if phpPatterns.IsInputPropertyAccess(expr) || phpPatterns.IsInputMethodCall(expr) {
+	if rules := t.rulesFor(filePath); rules.IsInputPropertyAccess(expr) || rules.IsInputMethodCall(expr) {
 		return &types.SourceInfo{
 			Type:       types.SourceUserInput,
 			Expression: expr,
@@ -1145,11 +1644,43 @@ func (t *Tracer) identifySource(expr string, filePath string, line int) *types.S
 		}
 	}
 
+	// Check calls to user-defined functions that wrap a superglobal
+	if sourceInfo := t.identifyWrapperSource(expr, filePath, line); sourceInfo != nil {
+		return sourceInfo
+	}
+
+	// Check tainted keys read from the array a summarized function returns
+	if sourceInfo := t.identifyReturnSummarySource(expr, filePath, line); sourceInfo != nil {
+		return sourceInfo
+	}
+
+	// Check reads of PSR-7 request attributes set from tainted data
+	if sourceInfo := t.identifyRequestAttributeSource(expr, filePath, line); sourceInfo != nil {
+		return sourceInfo
+	}
+
 	return nil
 }
 
+// hasSourceOnLine reports whether parsing found a source of sourceType on a line of a file
+func (t *Tracer) hasSourceOnLine(filePath string, line int, sourceType types.SourceType) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if fileInfo, ok := t.files[filePath]; ok {
+		for _, src := range fileInfo.Sources {
+			if src.Line == line && src.SourceType == sourceType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // discoverFiles finds all relevant source files
 func (t *Tracer) discoverFiles(root string) ([]string, error) {
+	if err := t.checkSandboxRoot(root); err != nil {
+		return nil, err
+	}
 	var files []string
 
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -1168,32 +1699,37 @@ func (t *Tracer) discoverFiles(root string) ([]string, error) {
 			return nil
 		}
 
-		// Check exclude patterns
-		rel, _ := filepath.Rel(root, path)
-		for _, pattern := range t.config.ExcludePatterns {
-			if matched, _ := doubleStarMatch(pattern, rel); matched {
-				return nil
-			}
+		if t.discoverable(root, path) {
+			files = append(files, path)
 		}
-
-		// Check include patterns
-		for _, pattern := range t.config.IncludePatterns {
-			if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
-				// Check language filter
-				lang := detectLanguage(path)
-				if len(t.config.Languages) == 0 || contains(t.config.Languages, lang) {
-					files = append(files, path)
-				}
-				return nil
-			}
-		}
-
 		return nil
 	})
 
 	return files, err
 }
 
+// discoverable reports whether the file at path under root is analyzed: it is
+// not excluded, matches an include pattern and is in an analyzed language
+func (t *Tracer) discoverable(root, path string) bool {
+	// Check exclude patterns
+	rel, _ := filepath.Rel(root, path)
+	for _, pattern := range t.config.ExcludePatterns {
+		if matched, _ := doubleStarMatch(pattern, rel); matched {
+			return false
+		}
+	}
+
+	// Check include patterns
+	for _, pattern := range t.config.IncludePatterns {
+		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
+			// Check language filter
+			lang := detectLanguage(path)
+			return (len(t.config.Languages) == 0 || contains(t.config.Languages, lang)) && t.inSandbox(path, "", 0)
+		}
+	}
+	return false
+}
+
 // parseFiles parses all files using an optimized worker pool pattern
 // This reuses parsers across files within each worker to reduce allocations
 func (t *Tracer) parseFiles(files []string) {
@@ -1212,7 +1748,7 @@ func (t *Tracer) parseFiles(files []string) {
 	var memoryExceeded bool
 	var memCheckMu sync.Mutex
 	filesProcessed := 0
-	gcInterval := 25 // GC every 25 files for tighter memory control
+	pacer := newMemoryPacer(t.config.MaxMemoryMB)
 
 	// Start fixed number of workers - each reuses its parser
 	var wg sync.WaitGroup
@@ -1252,16 +1788,25 @@ func (t *Tracer) parseFiles(files []string) {
 				}
 
 				t.parseFileWithParser(path, lang, parser)
+				t.yielder.Tick()
 
-				// Periodic memory check and GC (enabled for all modes when memory limit is set)
-				if t.config.MaxMemoryMB > 0 && localCount%gcInterval == 0 {
-					runtime.GC()
-					memMB := getMemoryUsageMB()
+				// Adaptive memory check (enabled for all modes when memory limit is set)
+				memCheckMu.Lock()
+				checkDue := pacer.due(localCount)
+				var memMB uint64
+				var exceeded bool
+				if checkDue {
+					memMB, exceeded = pacer.check(localCount)
+					memoryExceeded = memoryExceeded || exceeded
+					if memMB > t.stats.PeakHeapMB {
+						t.stats.PeakHeapMB = memMB
+					}
+				}
+				memCheckMu.Unlock()
+				if checkDue {
 					maxMB := uint64(t.config.MaxMemoryMB)
-					if memMB > maxMB {
-						memCheckMu.Lock()
-						memoryExceeded = true
-						memCheckMu.Unlock()
+					if exceeded {
+						t.recordIncomplete(&types.MemoryLimitError{LimitMB: maxMB, UsedMB: memMB, FilesProcessed: localCount})
 						if t.config.Verbose {
 							fmt.Printf("  [Memory] Limit exceeded (%d MB > %d MB) after %d files - stopping\n",
 								memMB, maxMB, localCount)
@@ -1303,6 +1848,10 @@ func createParser(lang string) *sitter.Parser {
 		parser.SetLanguage(ruby.GetLanguage())
 	case "rust":
 		parser.SetLanguage(rust.GetLanguage())
+	case "kotlin":
+		parser.SetLanguage(kotlin.GetLanguage())
+	case "swift":
+		parser.SetLanguage(swift.GetLanguage())
 	default:
 		return nil
 	}
@@ -1315,7 +1864,7 @@ func (t *Tracer) parseFileWithParser(path string, lang string, parser *sitter.Pa
 	startTime := time.Now()
 
 	// Get analyzer
-	langAnalyzer := analyzer.DefaultRegistry.Get(lang)
+	langAnalyzer := t.languageAnalyzer(path, lang)
 	if langAnalyzer == nil {
 		return
 	}
@@ -1333,6 +1882,7 @@ func (t *Tracer) parseFileWithParser(path string, lang string, parser *sitter.Pa
 			}
 			t.stats.FilesSkipped++
 			t.mu.Unlock()
+			t.warn(types.WarningFileSkipped, path, 0, "", fmt.Sprintf("file too large: %d bytes (limit: %d)", fileInfo.Size(), maxFileSize))
 			if t.config.Verbose {
 				fmt.Printf("  Skipping large file: %s (%d MB)\n", path, fileInfo.Size()/1024/1024)
 			}
@@ -1341,51 +1891,170 @@ func (t *Tracer) parseFileWithParser(path string, lang string, parser *sitter.Pa
 	}
 
 	// Read file content
-	content, err := os.ReadFile(path)
+	t.budget.TouchFile(path)
+	content, err := t.readFile(path)
 	if err != nil {
 		t.mu.Lock()
 		t.files[path] = &FileInfo{
 			Path:     path,
 			Language: lang,
-			Error:    err,
+			Error:    &types.ParseError{FilePath: path, Err: err},
 		}
 		t.stats.ParseErrors++
 		t.mu.Unlock()
+		t.warnings.Add(types.WarningFromError(&types.ParseError{FilePath: path, Err: err}))
 		return
 	}
 
+	// Minified bundles and generated code explode node counts and add noise
+	generated := ClassifyGeneratedFile(path, content)
+	mode := t.generatedFileMode()
+	if generated != GeneratedNone {
+		t.mu.Lock()
+		if generated == GeneratedMinified {
+			t.stats.MinifiedFiles++
+		} else {
+			t.stats.GeneratedFiles++
+		}
+		if mode == GeneratedSkip {
+			t.files[path] = &FileInfo{
+				Path:      path,
+				Language:  lang,
+				Generated: generated,
+			}
+			t.stats.FilesSkipped++
+		}
+		t.mu.Unlock()
+		if mode == GeneratedSkip {
+			t.warn(types.WarningFileSkipped, path, 0, "", fmt.Sprintf("%s file skipped", generated))
+			if t.config.Verbose {
+				fmt.Printf("  Skipping %s file: %s\n", generated, path)
+			}
+			return
+		}
+		if mode == GeneratedFlag {
+			t.warn(types.WarningGeneratedFile, path, 0, "", fmt.Sprintf("%s file analyzed; its sources are marked", generated))
+		}
+	}
+	lightweight := generated != GeneratedNone && mode == GeneratedLight
+
 	// Parse with tree-sitter
+	t.budget.CountParse()
 	tree, err := parser.ParseCtx(context.Background(), nil, content)
 	if err != nil {
 		t.mu.Lock()
 		t.files[path] = &FileInfo{
 			Path:     path,
 			Language: lang,
-			Error:    err,
+			Error:    &types.ParseError{FilePath: path, Err: err},
 		}
 		t.stats.ParseErrors++
 		t.mu.Unlock()
+		t.warnings.Add(types.WarningFromError(&types.ParseError{FilePath: path, Err: err}))
 		return
 	}
 
 	// Get root node before we close the tree
 	root := tree.RootNode()
 
-	// Build symbol table (extract all needed info while AST is available)
-	symbolTable, err := langAnalyzer.BuildSymbolTable(path, content, root)
-	if err != nil {
-		// On error, still release the tree
-		tree.Close()
-		t.mu.Lock()
-		t.files[path] = &FileInfo{
-			Path:         path,
-			Language:     lang,
-			Error:        err,
-			NeedsReparse: true,
+	// Lightweight mode (minified/generated files) only collects input sources
+	var symbolTable *types.SymbolTable
+	var templateBindings []*types.TemplateBinding
+	var sourceWrappers []*types.SourceWrapper
+	var returnSummaries []*types.ReturnSummary
+	var validators []*types.Validator
+	var requestAttributes []*types.RequestAttribute
+	var topLevelCode bool
+	var realtimeHandlers []*types.RealtimeHandler
+	var registerLoops []*types.RegisterLoop
+	var routeDispatches []*types.RouteDispatch
+	var attributeRoutes []*types.AttributeRoute
+	var includes []string
+	var deadRanges []types.DeadRange
+	var calledNames map[string]bool
+	if !lightweight {
+		// Build symbol table (extract all needed info while AST is available)
+		symbolTable, err = langAnalyzer.BuildSymbolTable(path, content, root)
+		if err != nil {
+			// On error, still release the tree
+			tree.Close()
+			t.mu.Lock()
+			t.files[path] = &FileInfo{
+				Path:         path,
+				Language:     lang,
+				Error:        &types.ParseError{FilePath: path, Err: err},
+				NeedsReparse: true,
+			}
+			t.stats.ParseErrors++
+			t.mu.Unlock()
+			t.warnings.Add(types.WarningFromError(&types.ParseError{FilePath: path, Err: err}))
+			return
+		}
+
+		// Build the scope tree so locals resolve against their own function
+		symbolTable.Scopes = analyzer.BuildScopeTree(root, content, path, lang)
+
+		// Record extract() + include pairs so template variables link back to their includer
+		if extractor, ok := langAnalyzer.(templateBindingExtractor); ok {
+			templateBindings = extractor.ExtractTemplateBindings(root, content, path)
+		}
+
+		// Record thin superglobal wrappers; their call sites are promoted once all files are parsed
+		if extractor, ok := langAnalyzer.(sourceWrapperExtractor); ok {
+			sourceWrappers = extractor.ExtractSourceWrappers(root, content, path)
+		}
+
+		// Record array-returning functions with tainted keys; reads of those keys are promoted likewise
+		if extractor, ok := langAnalyzer.(returnSummaryExtractor); ok {
+			returnSummaries = extractor.ExtractReturnSummaries(root, content, path)
+		}
+
+		// Record throw-on-invalid validators; their call sites constrain sources once all files are parsed
+		if extractor, ok := langAnalyzer.(validatorExtractor); ok {
+			validators = extractor.ExtractValidators(root, content, path)
+		}
+
+		// Record unreachable code; nothing flows through it
+		deadRanges = findDeadRanges(langAnalyzer, root, content)
+
+		// Record what entry-point discovery needs; imports are released after parsing
+		if detector, ok := langAnalyzer.(scriptEntryDetector); ok {
+			topLevelCode = detector.HasTopLevelCode(root, content)
+		}
+		if finder, ok := langAnalyzer.(realtimeHandlerFinder); ok {
+			realtimeHandlers = finder.FindRealtimeHandlers(root, content)
+			for _, h := range realtimeHandlers {
+				h.FilePath = path
+			}
+		}
+		if finder, ok := langAnalyzer.(registerLoopFinder); ok {
+			registerLoops = finder.FindRegisterLoops(root, content)
+			for _, loop := range registerLoops {
+				loop.FilePath = path
+			}
+		}
+		if finder, ok := langAnalyzer.(routeDispatchFinder); ok {
+			routeDispatches = finder.FindRouteDispatches(root, content)
+			for _, d := range routeDispatches {
+				d.FilePath = path
+			}
+		}
+		if finder, ok := langAnalyzer.(attributeRouteFinder); ok {
+			attributeRoutes = finder.FindAttributeRoutes(root, content)
+			for _, r := range attributeRoutes {
+				r.FilePath = path
+			}
+		}
+		for _, imp := range symbolTable.Imports {
+			if strings.HasPrefix(imp.Type, "include") || strings.HasPrefix(imp.Type, "require") {
+				includes = append(includes, imp.Path)
+			}
 		}
-		t.stats.ParseErrors++
-		t.mu.Unlock()
-		return
+	}
+
+	// Record called names so promotions after parsing re-parse only their callers
+	if finder, ok := langAnalyzer.(calledNameFinder); ok {
+		calledNames = finder.FindCalledNames(root, content)
 	}
 
 	// Find input sources (extract while AST is available)
@@ -1393,22 +2062,49 @@ func (t *Tracer) parseFileWithParser(path string, lang string, parser *sitter.Pa
 	if err != nil {
 		sources = []*types.FlowNode{} // Continue with empty sources on error
 	}
+	// Message payloads of realtime handlers are network input
+	sources = append(sources, realtimeSources(realtimeHandlers, lang)...)
+	// Variables a register_globals loop may define are request input
+	sources = append(sources, registerLoopSources(registerLoops, lang)...)
+	// Server configuration keys of $_SERVER are not input
+	sources = t.dropTrustedServerKeys(sources)
+	deadSources := flagDeadSources(deadRanges, sources)
+
+	// Record PSR-7 withAttribute() calls fed by these sources; tainted attributes are resolved once all files are parsed
+	if extractor, ok := langAnalyzer.(requestAttributeExtractor); ok && !lightweight {
+		requestAttributes = extractor.ExtractRequestAttributes(root, content, path, sources)
+	}
+
+	// Sources of flagged minified/generated files say so
+	if generated != GeneratedNone && mode == GeneratedFlag {
+		flagGeneratedSources(generated, sources)
+	}
+
+	// Infer each parameter's effective type (int-cast, enum, free string) from its uses
+	if inferrer, ok := langAnalyzer.(sourceConstraintInferrer); ok && len(sources) > 0 {
+		inferrer.InferSourceConstraints(root, content, sources)
+	}
 
 	// Update file paths in sources
 	for _, src := range sources {
 		src.FilePath = path
-		if src.ID == "" || !strings.Contains(src.ID, path) {
-			src.ID = fmt.Sprintf("%s:%d:%d", path, src.Line, src.Column)
-		}
+		src.ID = types.NodeID(path, src.Line, src.Column, string(src.Type), src.Snippet)
+		t.interner.internNode(src)
 	}
 
 	// MEMORY FIX: Extract assignments and calls NOW to avoid re-parsing during flow tracing
 	// This caches lightweight data structures instead of re-creating heavy ASTs later
 	var assignments []*types.Assignment
 	var calls []*types.CallSite
-	if len(sources) > 0 { // Only extract if we found sources (optimization)
+	if len(sources) > 0 && !lightweight { // Only extract if we found sources (optimization)
 		assignments, _ = langAnalyzer.ExtractAssignments(root, content, "")
 		calls, _ = langAnalyzer.ExtractCalls(root, content, "")
+		assignments = pruneDeadAssignments(deadRanges, assignments)
+		calls = pruneDeadCalls(deadRanges, calls)
+		markRealtimeTaint(realtimeHandlers, assignments, calls)
+		markRegisterTaint(registerLoops, assignments, calls)
+		t.interner.internAssignments(assignments)
+		t.interner.internCalls(calls)
 	}
 
 	// MEMORY OPTIMIZATION: Close the tree to release AST memory
@@ -1419,18 +2115,33 @@ func (t *Tracer) parseFileWithParser(path string, lang string, parser *sitter.Pa
 
 	t.mu.Lock()
 	t.files[path] = &FileInfo{
-		Path:         path,
-		Language:     lang,
-		SymbolTable:  symbolTable,
-		Sources:      sources,
-		Assignments:  assignments, // Cached for flow tracing
-		Calls:        calls,       // Cached for flow tracing
-		Root:         nil,         // Don't retain AST - saves ~10x file size in memory
-		Content:      nil,         // Don't retain content - can re-read if needed
-		ParseTime:    parseTime,
-		NeedsReparse: true, // Mark that AST was released
+		Path:              path,
+		Language:          lang,
+		SymbolTable:       symbolTable,
+		Sources:           sources,
+		Assignments:       assignments, // Cached for flow tracing
+		Calls:             calls,       // Cached for flow tracing
+		TemplateBindings:  templateBindings,
+		SourceWrappers:    sourceWrappers,
+		ReturnSummaries:   returnSummaries,
+		Validators:        validators,
+		RequestAttributes: requestAttributes,
+		TopLevelCode:      topLevelCode,
+		RealtimeHandlers:  realtimeHandlers,
+		RegisterLoops:     registerLoops,
+		RouteDispatches:   routeDispatches,
+		AttributeRoutes:   attributeRoutes,
+		Includes:          includes,
+		DeadRanges:        deadRanges,
+		Generated:         generated,
+		CalledNames:       calledNames,
+		Root:              nil, // Don't retain AST - saves ~10x file size in memory
+		Content:           nil, // Don't retain content - can re-read if needed
+		ParseTime:         parseTime,
+		NeedsReparse:      true, // Mark that AST was released
 	}
 	t.stats.FilesParsed++
+	t.stats.SourcesDead += deadSources
 
 	// Update language stats
 	if t.stats.ByLanguage[lang] == nil {
@@ -1448,29 +2159,45 @@ func (t *Tracer) buildGlobalSymbolTable() {
 	defer t.mu.Unlock()
 
 	for filePath, fileInfo := range t.files {
-		if fileInfo.SymbolTable == nil {
-			continue
-		}
+		t.mergeFileSymbols(filePath, fileInfo)
+	}
+}
 
-		st := fileInfo.SymbolTable
+// mergeFileSymbols adds the symbols of one file to the global symbol table.
+// Callers must hold t.mu.
+func (t *Tracer) mergeFileSymbols(filePath string, fileInfo *FileInfo) {
+	if fileInfo.SymbolTable == nil {
+		return
+	}
 
-		// Merge classes
-		for name, class := range st.Classes {
-			key := filePath + "::" + name
-			t.symbolTable.Classes[key] = class
-			// Also add short name for lookup
-			if t.symbolTable.Classes[name] == nil {
-				t.symbolTable.Classes[name] = class
-			}
+	st := fileInfo.SymbolTable
+
+	// Merge classes
+	for name, class := range st.Classes {
+		key := filePath + "::" + name
+		t.symbolTable.Classes[key] = class
+		// Also add short name for lookup
+		if t.symbolTable.Classes[name] == nil {
+			t.symbolTable.Classes[name] = class
 		}
+	}
 
-		// Merge functions
-		for name, fn := range st.Functions {
-			key := filePath + "::" + name
-			t.symbolTable.Functions[key] = fn
-			if t.symbolTable.Functions[name] == nil {
-				t.symbolTable.Functions[name] = fn
-			}
+	// Merge functions
+	for name, fn := range st.Functions {
+		if fn.FilePath == "" {
+			fn.FilePath = filePath // Needed to tell cross-file calls apart
+		}
+		key := filePath + "::" + name
+		t.symbolTable.Functions[key] = fn
+		if t.symbolTable.Functions[name] == nil {
+			t.symbolTable.Functions[name] = fn
+		}
+	}
+
+	// Merge constants (first definition wins, as in PHP)
+	for name, c := range st.Constants {
+		if t.symbolTable.Constants[name] == nil {
+			t.symbolTable.Constants[name] = c
 		}
 	}
 }
@@ -1519,16 +2246,20 @@ func (t *Tracer) releasePerFileSymbolTables() {
 	}
 }
 
-// collectSources collects all input sources from all files
+// collectSources collects all input sources from all files and sets their
+// canonical names (keys are final once constant keys are resolved)
 func (t *Tracer) collectSources() []*types.FlowNode {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	var sources []*types.FlowNode
+	var collected []*types.FlowNode
 	for _, fileInfo := range t.files {
-		sources = append(sources, fileInfo.Sources...)
+		for _, src := range fileInfo.Sources {
+			src.Canonical = sources.CanonicalSourceName(src.SourceType, src.SourceKey)
+		}
+		collected = append(collected, fileInfo.Sources...)
 	}
-	return sources
+	return collected
 }
 
 // traceAllFlows traces flows from all sources using parallel workers
@@ -1543,21 +2274,29 @@ func (t *Tracer) traceAllFlows(sources []*types.FlowNode, rootPath string) *type
 
 	// MEMORY FIX: Limit number of sources to trace for memory safety
 	// Each source traced requires file re-parsing which consumes memory
-	maxSources := 200
-	if len(sources) > maxSources {
+	// The highest-priority sources are traced; the rest are reported as skipped
+	t.skippedSources = nil
+	if maxSources := t.maxTracedSources(); maxSources > 0 && len(sources) > maxSources {
 		if t.config.Verbose {
 			fmt.Printf("  Limiting flow analysis to %d sources (of %d) for memory safety\n", maxSources, len(sources))
 		}
+		t.warn(types.WarningSourceLimit, "", 0, "", fmt.Sprintf("flow analysis limited to %d of %d sources", maxSources, len(sources)))
+		sources = t.prioritizeSources(sources)
+		t.skippedSources = sources[maxSources:]
+		t.stats.SourcesSkipped = len(t.skippedSources)
 		sources = sources[:maxSources]
 	}
 
-	// Run GC before flow tracing to start with clean slate
-	runtime.GC()
-
 	// If few sources, trace sequentially to avoid overhead
 	if len(sources) <= 2 {
-		for _, source := range sources {
-			t.traceSource(source, flowMap, rootPath)
+		for i, source := range sources {
+			if t.timeExceeded(i) {
+				break
+			}
+			t.traceLevelOrder(func() { t.traceSource(source, flowMap, rootPath) })
+			if !t.emitFinding(source, flowMap) {
+				break
+			}
 		}
 		return flowMap
 	}
@@ -1584,10 +2323,10 @@ func (t *Tracer) traceAllFlows(sources []*types.FlowNode, rootPath string) *type
 	var flowMu sync.Mutex
 
 	// Memory tracking for flow tracing
-	var memoryExceeded bool
+	var memoryExceeded, streamStopped, timeExceeded bool
 	var memCheckMu sync.Mutex
 	sourcesProcessed := 0
-	memCheckInterval := 20 // Check memory every 20 sources
+	pacer := newMemoryPacer(t.config.MaxMemoryMB)
 
 	// Start workers
 	var wg sync.WaitGroup
@@ -1604,9 +2343,12 @@ func (t *Tracer) traceAllFlows(sources []*types.FlowNode, rootPath string) *type
 			localFlowMap := types.NewFlowMapWithLimits(t.config.MaxFlowNodes, t.config.MaxFlowEdges)
 
 			for source := range sourceChan {
-				// Check if memory limit exceeded
+				// Check if memory limit exceeded or the stream consumer stopped
 				memCheckMu.Lock()
-				if memoryExceeded {
+				if !memoryExceeded && !streamStopped && !timeExceeded {
+					timeExceeded = t.timeExceeded(sourcesProcessed)
+				}
+				if memoryExceeded || streamStopped || timeExceeded {
 					memCheckMu.Unlock()
 					continue // Skip remaining sources
 				}
@@ -1615,17 +2357,30 @@ func (t *Tracer) traceAllFlows(sources []*types.FlowNode, rootPath string) *type
 				memCheckMu.Unlock()
 
 				// Trace into local flowMap
-				t.traceSourceParallel(source, localFlowMap, rootPath, &flowMu)
+				t.traceLevelOrder(func() { t.traceSourceParallel(source, localFlowMap, rootPath, &flowMu) })
+				if !t.emitFinding(source, localFlowMap) {
+					memCheckMu.Lock()
+					streamStopped = true
+					memCheckMu.Unlock()
+				}
 
-				// Periodic memory check
-				if t.config.MaxMemoryMB > 0 && localCount%memCheckInterval == 0 {
-					runtime.GC()
-					memMB := getMemoryUsageMB()
+				// Adaptive memory check
+				memCheckMu.Lock()
+				checkDue := pacer.due(localCount)
+				var memMB uint64
+				var exceeded bool
+				if checkDue {
+					memMB, exceeded = pacer.check(localCount)
+					memoryExceeded = memoryExceeded || exceeded
+					if memMB > t.stats.PeakHeapMB {
+						t.stats.PeakHeapMB = memMB
+					}
+				}
+				memCheckMu.Unlock()
+				if checkDue {
 					maxMB := uint64(t.config.MaxMemoryMB)
-					if memMB > maxMB {
-						memCheckMu.Lock()
-						memoryExceeded = true
-						memCheckMu.Unlock()
+					if exceeded {
+						t.recordIncomplete(&types.MemoryLimitError{LimitMB: maxMB, UsedMB: memMB, FilesProcessed: len(t.files)})
 						if t.config.Verbose {
 							fmt.Printf("  [Memory] Flow tracing stopped at %d MB (limit: %d MB)\n", memMB, maxMB)
 						}
@@ -1673,7 +2428,7 @@ func (t *Tracer) traceSourceParallel(source *types.FlowNode, flowMap *types.Flow
 	}
 
 	// Get analyzer (read-only, safe)
-	langAnalyzer := analyzer.DefaultRegistry.Get(fileInfo.Language)
+	langAnalyzer := t.languageAnalyzer(fileInfo.Path, fileInfo.Language)
 	if langAnalyzer == nil {
 		return
 	}
@@ -1696,8 +2451,17 @@ func (t *Tracer) traceSourceParallel(source *types.FlowNode, flowMap *types.Flow
 	// Find assignments that use this source
 	for _, assign := range assignments {
 		if assign.IsTainted && containsSourceName(assign.Source, source.Name) {
+			if rule := t.returnBarrier(assign, fileInfo.Calls); rule != nil {
+				t.addBarrier(source, rule, source.FilePath, assign.Line, assign.Column, flowMap)
+				continue
+			}
+			description, flows := arrayBuiltinEdge(assign, fileInfo.Calls, source.Name)
+			if !flows {
+				continue
+			}
+
 			varNode := types.FlowNode{
-				ID:         fmt.Sprintf("%s:%d:%d", source.FilePath, assign.Line, assign.Column),
+				ID:         types.NodeID(source.FilePath, assign.Line, assign.Column, string(types.NodeVariable), assign.Target+" = "+assign.Source),
 				Type:       types.NodeVariable,
 				Language:   fileInfo.Language,
 				FilePath:   source.FilePath,
@@ -1713,7 +2477,7 @@ func (t *Tracer) traceSourceParallel(source *types.FlowNode, flowMap *types.Flow
 				From:        source.ID,
 				To:          varNode.ID,
 				Type:        types.EdgeAssignment,
-				Description: "assigned to",
+				Description: description,
 			}
 			flowMap.AddEdge(edge)
 
@@ -1728,7 +2492,9 @@ func (t *Tracer) traceSourceParallel(source *types.FlowNode, flowMap *types.Flow
 				fmt.Sprintf("%s assigned from %s", assign.Target, source.Name))
 
 			// Recursively trace this variable with taint chain
-			t.traceVariableWithChain(&varNode, varChain, flowMap, rootPath, fileInfo, langAnalyzer, 1)
+			t.descend(func() {
+				t.traceVariableWithChain(&varNode, varChain, flowMap, rootPath, fileInfo, langAnalyzer, 1)
+			})
 		}
 	}
 
@@ -1739,12 +2505,21 @@ func (t *Tracer) traceSourceParallel(source *types.FlowNode, flowMap *types.Flow
 	}
 
 	for _, call := range calls {
+		if call.Line >= source.Line {
+			t.traceArrayBuiltin(source, call, flowMap, func(node *types.FlowNode) {
+				nodeChain := initialChain.Clone()
+				nodeChain.AddStep("assignment", node.Name, source.FilePath, node.Line,
+					fmt.Sprintf("%s written from %s", node.Name, source.Name))
+				t.traceVariableWithChain(node, nodeChain, flowMap, rootPath, fileInfo, langAnalyzer, 1)
+			})
+		}
 		if call.HasTaintedArgs {
 			for _, argIdx := range call.TaintedArgIndices {
 				if argIdx < len(call.Arguments) {
 					arg := call.Arguments[argIdx]
 					if containsSourceName(arg.Value, source.Name) {
-						t.traceCall(source, call, flowMap, rootPath, 1)
+						call := call
+						t.descend(func() { t.traceCall(source, call, flowMap, rootPath, 1) })
 					}
 				}
 			}
@@ -1765,7 +2540,7 @@ func (t *Tracer) traceSource(source *types.FlowNode, flowMap *types.FlowMap, roo
 	}
 
 	// Get analyzer
-	langAnalyzer := analyzer.DefaultRegistry.Get(fileInfo.Language)
+	langAnalyzer := t.languageAnalyzer(fileInfo.Path, fileInfo.Language)
 	if langAnalyzer == nil {
 		return
 	}
@@ -1787,9 +2562,18 @@ func (t *Tracer) traceSource(source *types.FlowNode, flowMap *types.FlowMap, roo
 	// Find assignments that use this source
 	for _, assign := range assignments {
 		if assign.IsTainted && containsSourceName(assign.Source, source.Name) {
+			if rule := t.returnBarrier(assign, fileInfo.Calls); rule != nil {
+				t.addBarrier(source, rule, source.FilePath, assign.Line, assign.Column, flowMap)
+				continue
+			}
+			description, flows := arrayBuiltinEdge(assign, fileInfo.Calls, source.Name)
+			if !flows {
+				continue
+			}
+
 			// Create node for the assigned variable
 			varNode := types.FlowNode{
-				ID:         fmt.Sprintf("%s:%d:%d", source.FilePath, assign.Line, assign.Column),
+				ID:         types.NodeID(source.FilePath, assign.Line, assign.Column, string(types.NodeVariable), assign.Target+" = "+assign.Source),
 				Type:       types.NodeVariable,
 				Language:   fileInfo.Language,
 				FilePath:   source.FilePath,
@@ -1806,7 +2590,7 @@ func (t *Tracer) traceSource(source *types.FlowNode, flowMap *types.FlowMap, roo
 				From:        source.ID,
 				To:          varNode.ID,
 				Type:        types.EdgeAssignment,
-				Description: "assigned to",
+				Description: description,
 			}
 			flowMap.AddEdge(edge)
 			t.stats.FlowsTraced++
@@ -1817,7 +2601,9 @@ func (t *Tracer) traceSource(source *types.FlowNode, flowMap *types.FlowMap, roo
 				fmt.Sprintf("%s assigned from %s", assign.Target, source.Name))
 
 			// Recursively trace this variable with taint chain
-			t.traceVariableWithChain(&varNode, varChain, flowMap, rootPath, fileInfo, langAnalyzer, 1)
+			t.descend(func() {
+				t.traceVariableWithChain(&varNode, varChain, flowMap, rootPath, fileInfo, langAnalyzer, 1)
+			})
 		}
 	}
 
@@ -1828,12 +2614,21 @@ func (t *Tracer) traceSource(source *types.FlowNode, flowMap *types.FlowMap, roo
 	}
 
 	for _, call := range calls {
+		if call.Line >= source.Line {
+			t.traceArrayBuiltin(source, call, flowMap, func(node *types.FlowNode) {
+				nodeChain := initialChain.Clone()
+				nodeChain.AddStep("assignment", node.Name, source.FilePath, node.Line,
+					fmt.Sprintf("%s written from %s", node.Name, source.Name))
+				t.traceVariableWithChain(node, nodeChain, flowMap, rootPath, fileInfo, langAnalyzer, 1)
+			})
+		}
 		if call.HasTaintedArgs {
 			for _, argIdx := range call.TaintedArgIndices {
 				if argIdx < len(call.Arguments) {
 					arg := call.Arguments[argIdx]
 					if containsSourceName(arg.Value, source.Name) {
-						t.traceCall(source, call, flowMap, rootPath, 1)
+						call := call
+						t.descend(func() { t.traceCall(source, call, flowMap, rootPath, 1) })
 					}
 				}
 			}
@@ -1844,7 +2639,9 @@ func (t *Tracer) traceSource(source *types.FlowNode, flowMap *types.FlowMap, roo
 // traceVariable traces flows from a tainted variable
 // MEMORY FIX: Uses cached assignments and calls to avoid re-parsing files
 func (t *Tracer) traceVariable(varNode *types.FlowNode, flowMap *types.FlowMap, rootPath string, fileInfo *FileInfo, langAnalyzer analyzer.LanguageAnalyzer, depth int) {
-	if depth > t.config.MaxDepth {
+	t.yielder.Tick()
+	if t.beyondMaxDepth(depth) {
+		t.warnDepthCutoff(varNode.FilePath, varNode.Line, varNode.Name)
 		return
 	}
 
@@ -1856,9 +2653,18 @@ func (t *Tracer) traceVariable(varNode *types.FlowNode, flowMap *types.FlowMap,
 
 	for _, assign := range assignments {
 		if assign.Line > varNode.Line && containsSourceName(assign.Source, varNode.Name) {
+			if rule := t.returnBarrier(assign, fileInfo.Calls); rule != nil {
+				t.addBarrier(varNode, rule, varNode.FilePath, assign.Line, assign.Column, flowMap)
+				continue
+			}
+			description, flows := arrayBuiltinEdge(assign, fileInfo.Calls, varNode.Name)
+			if !flows {
+				continue
+			}
+
 			// Create node for new variable
 			newVarNode := types.FlowNode{
-				ID:         fmt.Sprintf("%s:%d:%d", varNode.FilePath, assign.Line, assign.Column),
+				ID:         types.NodeID(varNode.FilePath, assign.Line, assign.Column, string(types.NodeVariable), assign.Target+" = "+assign.Source),
 				Type:       types.NodeVariable,
 				Language:   fileInfo.Language,
 				FilePath:   varNode.FilePath,
@@ -1875,13 +2681,15 @@ func (t *Tracer) traceVariable(varNode *types.FlowNode, flowMap *types.FlowMap,
 					From:        varNode.ID,
 					To:          newVarNode.ID,
 					Type:        types.EdgeAssignment,
-					Description: "assigned to",
+					Description: description,
 				}
 				flowMap.AddEdge(edge)
 				t.stats.FlowsTraced++
 
 				// Recursively trace
-				t.traceVariable(&newVarNode, flowMap, rootPath, fileInfo, langAnalyzer, depth+1)
+				t.descend(func() {
+					t.traceVariable(&newVarNode, flowMap, rootPath, fileInfo, langAnalyzer, depth+1)
+				})
 			}
 		}
 	}
@@ -1894,13 +2702,16 @@ func (t *Tracer) traceVariable(varNode *types.FlowNode, flowMap *types.FlowMap,
 
 	for _, call := range calls {
 		if call.Line > varNode.Line {
+			t.traceArrayBuiltin(varNode, call, flowMap, func(node *types.FlowNode) {
+				t.traceVariable(node, flowMap, rootPath, fileInfo, langAnalyzer, depth+1)
+			})
 			for i, arg := range call.Arguments {
 				if containsSourceName(arg.Value, varNode.Name) {
 					// Create copy with taint info for this specific call
 					callCopy := *call
 					callCopy.HasTaintedArgs = true
 					callCopy.TaintedArgIndices = []int{i}
-					t.traceCall(varNode, &callCopy, flowMap, rootPath, depth)
+					t.descend(func() { t.traceCall(varNode, &callCopy, flowMap, rootPath, depth) })
 				}
 			}
 		}
@@ -1910,7 +2721,9 @@ func (t *Tracer) traceVariable(varNode *types.FlowNode, flowMap *types.FlowMap,
 // traceVariableWithChain traces flows from a tainted variable with full taint chain tracking (GAP 5)
 // MEMORY FIX: Uses cached assignments and calls to avoid re-parsing files
 func (t *Tracer) traceVariableWithChain(varNode *types.FlowNode, chain *types.TaintChain, flowMap *types.FlowMap, rootPath string, fileInfo *FileInfo, langAnalyzer analyzer.LanguageAnalyzer, depth int) {
-	if depth > t.config.MaxDepth {
+	t.yielder.Tick()
+	if t.beyondMaxDepth(depth) {
+		t.warnDepthCutoff(varNode.FilePath, varNode.Line, varNode.Name)
 		return
 	}
 
@@ -1922,9 +2735,18 @@ func (t *Tracer) traceVariableWithChain(varNode *types.FlowNode, chain *types.Ta
 
 	for _, assign := range assignments {
 		if assign.Line > varNode.Line && containsSourceName(assign.Source, varNode.Name) {
+			if rule := t.returnBarrier(assign, fileInfo.Calls); rule != nil {
+				t.addBarrier(varNode, rule, varNode.FilePath, assign.Line, assign.Column, flowMap)
+				continue
+			}
+			description, flows := arrayBuiltinEdge(assign, fileInfo.Calls, varNode.Name)
+			if !flows {
+				continue
+			}
+
 			// Create node for new variable
 			newVarNode := types.FlowNode{
-				ID:         fmt.Sprintf("%s:%d:%d", varNode.FilePath, assign.Line, assign.Column),
+				ID:         types.NodeID(varNode.FilePath, assign.Line, assign.Column, string(types.NodeVariable), assign.Target+" = "+assign.Source),
 				Type:       types.NodeVariable,
 				Language:   fileInfo.Language,
 				FilePath:   varNode.FilePath,
@@ -1941,7 +2763,7 @@ func (t *Tracer) traceVariableWithChain(varNode *types.FlowNode, chain *types.Ta
 					From:        varNode.ID,
 					To:          newVarNode.ID,
 					Type:        types.EdgeAssignment,
-					Description: "assigned to",
+					Description: description,
 				}
 				flowMap.AddEdge(edge)
 				t.stats.FlowsTraced++
@@ -1952,7 +2774,9 @@ func (t *Tracer) traceVariableWithChain(varNode *types.FlowNode, chain *types.Ta
 					fmt.Sprintf("%s assigned from %s", assign.Target, varNode.Name))
 
 				// Recursively trace with chain
-				t.traceVariableWithChain(&newVarNode, newChain, flowMap, rootPath, fileInfo, langAnalyzer, depth+1)
+				t.descend(func() {
+					t.traceVariableWithChain(&newVarNode, newChain, flowMap, rootPath, fileInfo, langAnalyzer, depth+1)
+				})
 			}
 		}
 	}
@@ -1965,6 +2789,12 @@ func (t *Tracer) traceVariableWithChain(varNode *types.FlowNode, chain *types.Ta
 
 	for _, call := range calls {
 		if call.Line > varNode.Line {
+			t.traceArrayBuiltin(varNode, call, flowMap, func(node *types.FlowNode) {
+				nodeChain := chain.Clone()
+				nodeChain.AddStep("assignment", node.Name, varNode.FilePath, node.Line,
+					fmt.Sprintf("%s written from %s", node.Name, varNode.Name))
+				t.traceVariableWithChain(node, nodeChain, flowMap, rootPath, fileInfo, langAnalyzer, depth+1)
+			})
 			for i, arg := range call.Arguments {
 				if containsSourceName(arg.Value, varNode.Name) {
 					// Create copy with taint info and chain for this specific call
@@ -1981,7 +2811,7 @@ func (t *Tracer) traceVariableWithChain(varNode *types.FlowNode, chain *types.Ta
 						callCopy.Arguments[i].TaintChain = argChain
 					}
 
-					t.traceCallWithChain(varNode, &callCopy, chain, flowMap, rootPath, depth)
+					t.descend(func() { t.traceCallWithChain(varNode, &callCopy, chain, flowMap, rootPath, depth) })
 				}
 			}
 		}
@@ -1990,13 +2820,15 @@ func (t *Tracer) traceVariableWithChain(varNode *types.FlowNode, chain *types.Ta
 
 // traceCallWithChain traces a function call with tainted argument and chain (GAP 5)
 func (t *Tracer) traceCallWithChain(source *types.FlowNode, call *types.CallSite, chain *types.TaintChain, flowMap *types.FlowMap, rootPath string, depth int) {
-	if depth > t.config.MaxDepth {
+	t.yielder.Tick()
+	if t.beyondMaxDepth(depth) {
+		t.warnDepthCutoff(call.FilePath, call.Line, call.FunctionName)
 		return
 	}
 
 	// Create node for the function call
 	callNode := types.FlowNode{
-		ID:         fmt.Sprintf("%s:%d:%d:call", source.FilePath, call.Line, call.Column),
+		ID:         types.NodeID(source.FilePath, call.Line, call.Column, types.NodeKindCall, call.FunctionName),
 		Type:       types.NodeFunction,
 		Language:   source.Language,
 		FilePath:   source.FilePath,
@@ -2026,19 +2858,21 @@ func (t *Tracer) traceCallWithChain(source *types.FlowNode, call *types.CallSite
 
 	// If cross-file tracing is enabled, find the function definition and trace into it with chain
 	if t.config.FollowImports {
-		t.traceIntoFunctionWithChain(&callNode, call, chain, flowMap, rootPath, depth+1)
+		t.descend(func() { t.traceIntoFunctionWithChain(&callNode, call, chain, flowMap, rootPath, depth+1) })
 	}
 }
 
 // traceCall traces a function call with tainted argument
 func (t *Tracer) traceCall(source *types.FlowNode, call *types.CallSite, flowMap *types.FlowMap, rootPath string, depth int) {
-	if depth > t.config.MaxDepth {
+	t.yielder.Tick()
+	if t.beyondMaxDepth(depth) {
+		t.warnDepthCutoff(call.FilePath, call.Line, call.FunctionName)
 		return
 	}
 
 	// Create node for the function call
 	callNode := types.FlowNode{
-		ID:         fmt.Sprintf("%s:%d:%d:call", source.FilePath, call.Line, call.Column),
+		ID:         types.NodeID(source.FilePath, call.Line, call.Column, types.NodeKindCall, call.FunctionName),
 		Type:       types.NodeFunction,
 		Language:   source.Language,
 		FilePath:   source.FilePath,
@@ -2068,13 +2902,14 @@ func (t *Tracer) traceCall(source *types.FlowNode, call *types.CallSite, flowMap
 
 	// If cross-file tracing is enabled, find the function definition and trace into it
 	if t.config.FollowImports {
-		t.traceIntoFunction(&callNode, call, flowMap, rootPath, depth+1)
+		t.descend(func() { t.traceIntoFunction(&callNode, call, flowMap, rootPath, depth+1) })
 	}
 }
 
 // traceIntoFunction traces execution into a called function
 func (t *Tracer) traceIntoFunction(callNode *types.FlowNode, call *types.CallSite, flowMap *types.FlowMap, rootPath string, depth int) {
-	if depth > t.config.MaxDepth {
+	if t.beyondMaxDepth(depth) {
+		t.warnDepthCutoff(call.FilePath, call.Line, call.FunctionName)
 		return
 	}
 
@@ -2110,9 +2945,18 @@ func (t *Tracer) traceIntoFunction(callNode *types.FlowNode, call *types.CallSit
 		return
 	}
 
+	// Input stops at a trusted boundary and passes an untrusted one through
+	// its barrier node
+	if rule := t.callBarrier(call, callNode.FilePath, funcFile, rootPath); rule != nil {
+		callNode = t.addBarrier(callNode, rule, callNode.FilePath, call.Line, call.Column, flowMap)
+		if rule.Trust == BarrierTrusted {
+			return
+		}
+	}
+
 	// Create node for the function definition
 	funcNode := types.FlowNode{
-		ID:       fmt.Sprintf("%s:%d:func", funcFile, funcDef.Line),
+		ID:       types.NodeID(funcFile, funcDef.Line, 0, types.NodeKindFunc, funcDef.Name),
 		Type:     types.NodeFunction,
 		Language: callNode.Language,
 		FilePath: funcFile,
@@ -2129,6 +2973,10 @@ func (t *Tracer) traceIntoFunction(callNode *types.FlowNode, call *types.CallSit
 			Type:        types.EdgeCall,
 			Description: "calls",
 		}
+		if callNode.FilePath != funcFile {
+			chain := t.includeChain(callNode.FilePath, funcFile)
+			edge.IncludeChain = &chain
+		}
 		flowMap.AddEdge(edge)
 		t.stats.FlowsTraced++
 
@@ -2144,7 +2992,7 @@ func (t *Tracer) traceIntoFunction(callNode *types.FlowNode, call *types.CallSit
 				param := funcDef.Parameters[argIdx]
 
 				paramNode := types.FlowNode{
-					ID:       fmt.Sprintf("%s:%d:param:%s", funcFile, funcDef.Line, param.Name),
+					ID:       types.NodeID(funcFile, funcDef.Line, 0, types.NodeKindParam, funcDef.Name+"."+param.Name),
 					Type:     types.NodeVariable,
 					Language: callNode.Language,
 					FilePath: funcFile,
@@ -2170,9 +3018,9 @@ func (t *Tracer) traceIntoFunction(callNode *types.FlowNode, call *types.CallSit
 					t.mu.RUnlock()
 
 					if fileInfo != nil {
-						langAnalyzer := analyzer.DefaultRegistry.Get(fileInfo.Language)
+						langAnalyzer := t.languageAnalyzer(fileInfo.Path, fileInfo.Language)
 						if langAnalyzer != nil {
-							t.traceVariable(&paramNode, flowMap, rootPath, fileInfo, langAnalyzer, depth)
+							t.descend(func() { t.traceVariable(&paramNode, flowMap, rootPath, fileInfo, langAnalyzer, depth) })
 						}
 					}
 				}
@@ -2183,7 +3031,8 @@ func (t *Tracer) traceIntoFunction(callNode *types.FlowNode, call *types.CallSit
 
 // traceIntoFunctionWithChain traces execution into a called function with taint chain (GAP 5)
 func (t *Tracer) traceIntoFunctionWithChain(callNode *types.FlowNode, call *types.CallSite, chain *types.TaintChain, flowMap *types.FlowMap, rootPath string, depth int) {
-	if depth > t.config.MaxDepth {
+	if t.beyondMaxDepth(depth) {
+		t.warnDepthCutoff(call.FilePath, call.Line, call.FunctionName)
 		return
 	}
 
@@ -2219,9 +3068,18 @@ func (t *Tracer) traceIntoFunctionWithChain(callNode *types.FlowNode, call *type
 		return
 	}
 
+	// Input stops at a trusted boundary and passes an untrusted one through
+	// its barrier node
+	if rule := t.callBarrier(call, callNode.FilePath, funcFile, rootPath); rule != nil {
+		callNode = t.addBarrier(callNode, rule, callNode.FilePath, call.Line, call.Column, flowMap)
+		if rule.Trust == BarrierTrusted {
+			return
+		}
+	}
+
 	// Create node for the function definition
 	funcNode := types.FlowNode{
-		ID:       fmt.Sprintf("%s:%d:func", funcFile, funcDef.Line),
+		ID:       types.NodeID(funcFile, funcDef.Line, 0, types.NodeKindFunc, funcDef.Name),
 		Type:     types.NodeFunction,
 		Language: callNode.Language,
 		FilePath: funcFile,
@@ -2238,6 +3096,10 @@ func (t *Tracer) traceIntoFunctionWithChain(callNode *types.FlowNode, call *type
 			Type:        types.EdgeCall,
 			Description: "calls",
 		}
+		if callNode.FilePath != funcFile {
+			chain := t.includeChain(callNode.FilePath, funcFile)
+			edge.IncludeChain = &chain
+		}
 		flowMap.AddEdge(edge)
 		t.stats.FlowsTraced++
 
@@ -2253,7 +3115,7 @@ func (t *Tracer) traceIntoFunctionWithChain(callNode *types.FlowNode, call *type
 				param := funcDef.Parameters[argIdx]
 
 				paramNode := types.FlowNode{
-					ID:       fmt.Sprintf("%s:%d:param:%s", funcFile, funcDef.Line, param.Name),
+					ID:       types.NodeID(funcFile, funcDef.Line, 0, types.NodeKindParam, funcDef.Name+"."+param.Name),
 					Type:     types.NodeParam,
 					Language: callNode.Language,
 					FilePath: funcFile,
@@ -2289,9 +3151,11 @@ func (t *Tracer) traceIntoFunctionWithChain(callNode *types.FlowNode, call *type
 					t.mu.RUnlock()
 
 					if fileInfo != nil {
-						langAnalyzer := analyzer.DefaultRegistry.Get(fileInfo.Language)
+						langAnalyzer := t.languageAnalyzer(fileInfo.Path, fileInfo.Language)
 						if langAnalyzer != nil {
-							t.traceVariableWithChain(&paramNode, paramChain, flowMap, rootPath, fileInfo, langAnalyzer, depth)
+							t.descend(func() {
+								t.traceVariableWithChain(&paramNode, paramChain, flowMap, rootPath, fileInfo, langAnalyzer, depth)
+							})
 						}
 					}
 				}
@@ -2310,12 +3174,22 @@ func (t *Tracer) printSummary() {
 		fmt.Printf("Files parsed: %d (%d errors)\n", t.stats.FilesParsed, t.stats.ParseErrors)
 	}
 	fmt.Printf("Input sources found: %d\n", t.stats.SourcesFound)
+	if t.stats.SourcesDead > 0 {
+		fmt.Printf("Sources in unreachable code: %d\n", t.stats.SourcesDead)
+	}
 	fmt.Printf("Flows traced: %d (%d cross-file)\n", t.stats.FlowsTraced, t.stats.CrossFileFlows)
 	fmt.Printf("\nBy language:\n")
 
 	for lang, stats := range t.stats.ByLanguage {
 		fmt.Printf("  %s: %d files, %d sources\n", lang, stats.Files, stats.Sources)
 	}
+
+	if len(t.stats.ByLayer) > 0 {
+		fmt.Printf("\nBy layer:\n")
+		for layer, stats := range t.stats.ByLayer {
+			fmt.Printf("  %s: %d sources, %d nodes reached\n", layer, stats.Sources, stats.Nodes)
+		}
+	}
 }
 
 // Helper functions
@@ -2383,6 +3257,11 @@ func (r *TraceResult) ToJSON() (string, error) {
 	return ToJSON(r)
 }
 
+// ToJSONWithOptions outputs the result as JSON, embedding what opts asks for
+func (r *TraceResult) ToJSONWithOptions(opts WriteOptions) (string, error) {
+	return ToJSONWithOptions(r, opts)
+}
+
 // ToDOT outputs the result as GraphViz DOT
 func (r *TraceResult) ToDOT() string {
 	return ToDOT(r)
@@ -2398,6 +3277,16 @@ func (r *TraceResult) ToHTML() string {
 	return ToHTML(r)
 }
 
+// ToDefectDojo outputs the input flows as DefectDojo generic-findings JSON
+func (r *TraceResult) ToDefectDojo() (string, error) {
+	return ToDefectDojo(r)
+}
+
+// ToCheckmarxXML outputs the input flows as Checkmarx-like XML
+func (r *TraceResult) ToCheckmarxXML() (string, error) {
+	return ToCheckmarxXML(r)
+}
+
 // Query methods
 
 // GetSourcesByType returns sources filtered by type
@@ -2411,6 +3300,28 @@ func (r *TraceResult) GetSourcesByType(sourceType types.SourceType) []*types.Flo
 	return result
 }
 
+// GetSourcesByCanonical returns the sources reading one input, identified by
+// its canonical name (e.g. "http_get:id"), across all languages
+func (r *TraceResult) GetSourcesByCanonical(canonical string) []*types.FlowNode {
+	var result []*types.FlowNode
+	for _, source := range r.Sources {
+		if source.Canonical == canonical {
+			result = append(result, source)
+		}
+	}
+	return result
+}
+
+// GroupSourcesByCanonical groups sources by canonical name, so the same
+// logical input read from different languages is aggregated
+func (r *TraceResult) GroupSourcesByCanonical() map[string][]*types.FlowNode {
+	groups := make(map[string][]*types.FlowNode)
+	for _, source := range r.Sources {
+		groups[source.Canonical] = append(groups[source.Canonical], source)
+	}
+	return groups
+}
+
 // GetSourcesByFile returns sources in a specific file
 func (r *TraceResult) GetSourcesByFile(filePath string) []*types.FlowNode {
 	var result []*types.FlowNode
@@ -12,6 +12,7 @@ import (
 	"github.com/smacker/go-tree-sitter/php"
 
 	"github.com/hatlesswizard/inputtracer/pkg/semantic/discovery"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
 	pkgSources "github.com/hatlesswizard/inputtracer/pkg/sources"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 )
@@ -21,6 +22,7 @@ type VariableTracer struct {
 	parser     *sitter.Parser
 	carrierMap *discovery.CarrierMap
 	codebase   string
+	sandbox    *types.Sandbox
 }
 
 // VariableDefinition represents one definition of a variable
@@ -96,6 +98,13 @@ func NewVariableTracer(codebase string, carrierMap *discovery.CarrierMap) *Varia
 	}
 }
 
+// SetSandbox restricts the files the tracer reads to the given root
+// directories; files resolving outside them, e.g. through a symlink in the
+// codebase, are skipped. Without roots any file is read.
+func (t *VariableTracer) SetSandbox(roots []string) {
+	t.sandbox = types.NewSandbox(roots)
+}
+
 // TraceVariable traces a variable across the entire codebase
 func (t *VariableTracer) TraceVariable(varName string) (*TraceReport, error) {
 	report := &TraceReport{
@@ -156,7 +165,7 @@ func (t *VariableTracer) findAllDefinitions(varName string) ([]VariableDefinitio
 			return nil
 		}
 
-		content, err := os.ReadFile(path)
+		content, err := t.sandbox.ReadFile(path)
 		if err != nil {
 			return nil
 		}
@@ -277,7 +286,7 @@ func (t *VariableTracer) traceDefinition(varName string, def VariableDefinition)
 
 	// Read the full file
 	fullPath := filepath.Join(t.codebase, def.File)
-	content, err := os.ReadFile(fullPath)
+	content, err := t.sandbox.ReadFile(fullPath)
 	if err != nil {
 		return result
 	}
@@ -793,7 +802,7 @@ func (t *VariableTracer) findFunctionCallSites(funcName string) []CallSiteInfo {
 			return nil
 		}
 
-		content, err := os.ReadFile(path)
+		content, err := t.sandbox.ReadFile(path)
 		if err != nil {
 			return nil
 		}
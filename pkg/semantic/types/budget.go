@@ -0,0 +1,148 @@
+package types
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BudgetLimit is a limit of the analysis a trace can run into
+type BudgetLimit string
+
+const (
+	BudgetLimitDepth  BudgetLimit = "depth"  // Maximum call depth or chain length
+	BudgetLimitNodes  BudgetLimit = "nodes"  // Maximum flow graph nodes or edges
+	BudgetLimitMemory BudgetLimit = "memory" // Memory limit
+	BudgetLimitTime   BudgetLimit = "time"   // Duration limit
+)
+
+// BudgetReport is the cost of one trace, to correlate the quality of a
+// result (its termination, its warnings) with the work behind it
+type BudgetReport struct {
+	FilesTouched     int           `json:"files_touched"`     // Distinct files read, parsed or looked up
+	Parses           int64         `json:"parses"`            // Files parsed, including re-parses
+	CacheHits        int64         `json:"cache_hits"`        // Lookups answered without parsing
+	RegexEvaluations int64         `json:"regex_evaluations"` // Pattern matches run on code or expression text
+	DepthReached     int           `json:"depth_reached"`     // Deepest call or variable hop followed
+	WallTime         time.Duration `json:"wall_time"`
+	LimitsHit        []BudgetLimit `json:"limits_hit,omitempty"`
+}
+
+// LimitHit reports whether the trace ran into any limit, so that its result
+// may be missing flows
+func (b *BudgetReport) LimitHit() bool {
+	return b != nil && len(b.LimitsHit) > 0
+}
+
+// BudgetMeter accumulates the budget of a trace. It is safe for concurrent
+// use, and a nil meter counts nothing.
+type BudgetMeter struct {
+	start     time.Time
+	parses    int64
+	cacheHits int64
+	regex     int64
+	depth     int64
+
+	mu     sync.Mutex
+	files  map[string]bool
+	limits map[BudgetLimit]bool
+}
+
+// NewBudgetMeter returns a meter whose wall time starts now
+func NewBudgetMeter() *BudgetMeter {
+	return &BudgetMeter{
+		start:  time.Now(),
+		files:  make(map[string]bool),
+		limits: make(map[BudgetLimit]bool),
+	}
+}
+
+// TouchFile records that the trace read or looked up a file
+func (m *BudgetMeter) TouchFile(path string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.files[path] = true
+	m.mu.Unlock()
+}
+
+// CountParse records a parse of a file
+func (m *BudgetMeter) CountParse() {
+	if m != nil {
+		atomic.AddInt64(&m.parses, 1)
+	}
+}
+
+// CountCacheHit records a lookup answered from a cache
+func (m *BudgetMeter) CountCacheHit() {
+	if m != nil {
+		atomic.AddInt64(&m.cacheHits, 1)
+	}
+}
+
+// CountRegex records n pattern matches
+func (m *BudgetMeter) CountRegex(n int) {
+	if m != nil {
+		atomic.AddInt64(&m.regex, int64(n))
+	}
+}
+
+// ReachDepth records that the trace followed a hop at depth
+func (m *BudgetMeter) ReachDepth(depth int) {
+	if m == nil {
+		return
+	}
+	for {
+		cur := atomic.LoadInt64(&m.depth)
+		if int64(depth) <= cur || atomic.CompareAndSwapInt64(&m.depth, cur, int64(depth)) {
+			return
+		}
+	}
+}
+
+// HitLimit records that the trace ran into a limit
+func (m *BudgetMeter) HitLimit(limit BudgetLimit) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.limits[limit] = true
+	m.mu.Unlock()
+}
+
+// HitLimitOf records the limit behind an analysis warning, if any: depth
+// cutoffs and truncated chains, memory and time limits
+func (m *BudgetMeter) HitLimitOf(category WarningCategory) {
+	switch category {
+	case WarningDepthCutoff, WarningChainTruncated:
+		m.HitLimit(BudgetLimitDepth)
+	case WarningMemoryLimit:
+		m.HitLimit(BudgetLimitMemory)
+	case WarningTimeLimit:
+		m.HitLimit(BudgetLimitTime)
+	}
+}
+
+// Report returns the budget consumed so far
+func (m *BudgetMeter) Report() *BudgetReport {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	report := &BudgetReport{
+		FilesTouched:     len(m.files),
+		Parses:           atomic.LoadInt64(&m.parses),
+		CacheHits:        atomic.LoadInt64(&m.cacheHits),
+		RegexEvaluations: atomic.LoadInt64(&m.regex),
+		DepthReached:     int(atomic.LoadInt64(&m.depth)),
+		WallTime:         time.Since(m.start),
+	}
+	for limit := range m.limits {
+		report.LimitsHit = append(report.LimitsHit, limit)
+	}
+	sort.Slice(report.LimitsHit, func(i, j int) bool { return report.LimitsHit[i] < report.LimitsHit[j] })
+	return report
+}
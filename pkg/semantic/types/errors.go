@@ -0,0 +1,108 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sentinel errors returned (wrapped) by the semantic and symbolic packages.
+// Use errors.Is to classify a failure and errors.As to get its details.
+var (
+	// ErrClassNotFound means a class definition could not be located
+	ErrClassNotFound = errors.New("class not found")
+
+	// ErrInstantiationNotFound means no `new ClassName` was found for an object variable
+	ErrInstantiationNotFound = errors.New("instantiation not found")
+
+	// ErrMethodNotFound means a method does not exist on the resolved class
+	ErrMethodNotFound = errors.New("method not found")
+
+	// ErrPropertyNotFound means a property does not exist on the resolved class
+	ErrPropertyNotFound = errors.New("property not found")
+
+	// ErrParse means a source file could not be read or parsed
+	ErrParse = errors.New("parse error")
+
+	// ErrMemoryLimit means analysis stopped early because the memory limit was reached
+	ErrMemoryLimit = errors.New("memory limit exceeded")
+
+	// ErrTimeLimit means analysis stopped early because the time limit was reached
+	ErrTimeLimit = errors.New("time limit exceeded")
+
+	// ErrUnsupportedExpression means an expression could not be parsed or traced
+	ErrUnsupportedExpression = errors.New("unsupported expression")
+
+	// ErrSandboxEscape means a path resolves outside the configured sandbox roots
+	ErrSandboxEscape = errors.New("path outside sandbox")
+)
+
+// TraceError is a structured tracing failure. Kind is one of the sentinel
+// errors above, so errors.Is(err, ErrClassNotFound) works on a *TraceError.
+type TraceError struct {
+	Kind       error  // Sentinel classifying the failure
+	Expression string // Expression being traced, if any
+	ClassName  string // Class involved, if any
+	Member     string // Method or property involved, if any
+	Message    string // Human-readable message
+}
+
+// Error returns the human-readable message
+func (e *TraceError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the sentinel kind
+func (e *TraceError) Unwrap() error {
+	return e.Kind
+}
+
+// ParseError records a file that could not be read or parsed.
+// It matches ErrParse and also unwraps to the underlying cause.
+type ParseError struct {
+	FilePath string
+	Err      error
+}
+
+// Error returns the underlying cause prefixed with the file path
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s: %v", e.FilePath, e.Err)
+}
+
+// Unwrap returns both ErrParse and the underlying cause
+func (e *ParseError) Unwrap() []error {
+	return []error{ErrParse, e.Err}
+}
+
+// MemoryLimitError records where analysis stopped because of the memory limit
+type MemoryLimitError struct {
+	LimitMB        uint64
+	UsedMB         uint64
+	FilesProcessed int
+}
+
+// Error describes the exceeded limit
+func (e *MemoryLimitError) Error() string {
+	return fmt.Sprintf("memory limit exceeded (%d MB > %d MB) after %d files", e.UsedMB, e.LimitMB, e.FilesProcessed)
+}
+
+// Unwrap returns ErrMemoryLimit
+func (e *MemoryLimitError) Unwrap() error {
+	return ErrMemoryLimit
+}
+
+// TimeLimitError records where flow tracing stopped because of the time limit
+type TimeLimitError struct {
+	Limit         time.Duration
+	SourcesTraced int
+}
+
+// Error describes the exceeded limit
+func (e *TimeLimitError) Error() string {
+	return fmt.Sprintf("time limit of %v exceeded after tracing %d sources", e.Limit, e.SourcesTraced)
+}
+
+// Unwrap returns ErrTimeLimit
+func (e *TimeLimitError) Unwrap() error {
+	return ErrTimeLimit
+}
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Node ID kinds besides the flow node types: a function node is either a call
+// or a definition, and parameters are one kind whatever their node type
+const (
+	NodeKindCall  = "call"
+	NodeKindFunc  = "func"
+	NodeKindParam = "param"
+)
+
+// NodeID returns the ID of a flow node: its position, its kind and a short
+// hash of its content, e.g. "src/a.php:3:5:variable:9f1c2b7e". Two nodes
+// share an ID only when they are the same node: a call and its receiver at
+// one position differ in kind, and a node read from a file edited between
+// phases differs in content. IDs start with the "file:line:column" of the
+// previous format, so consumers reading that prefix keep working.
+func NodeID(filePath string, line, column int, kind, content string) string {
+	h := fnv.New32a()
+	h.Write([]byte(content))
+	return fmt.Sprintf("%s:%d:%d:%s:%08x", filePath, line, column, kind, h.Sum32())
+}
+
+// NodeIDParts are the fields of a node ID
+type NodeIDParts struct {
+	FilePath string
+	Line     int
+	Column   int
+	Kind     string // Empty for "file:line:column" IDs of the previous format
+	Hash     string // Empty for IDs of the previous format
+}
+
+// Position returns the "file:line:column" the node ID starts with, the whole
+// ID in the previous format
+func (p NodeIDParts) Position() string {
+	return fmt.Sprintf("%s:%d:%d", p.FilePath, p.Line, p.Column)
+}
+
+// ParseNodeID splits a node ID into its fields. It also accepts IDs of the
+// previous formats (file:line:column, file:line:column:call, file:line:func
+// and file:line:param:name), whose hash is empty.
+func ParseNodeID(id string) (NodeIDParts, bool) {
+	parts := strings.Split(id, ":")
+	n := len(parts)
+	number := func(i int) (int, bool) {
+		if i < 1 {
+			return 0, false // The file path must precede the number
+		}
+		v, err := strconv.Atoi(parts[i])
+		return v, err == nil && v >= 0
+	}
+	file := func(end int) string {
+		return strings.Join(parts[:end], ":")
+	}
+
+	// file:line:column:kind:hash
+	if n >= 5 && isNodeHash(parts[n-1]) {
+		line, okLine := number(n - 4)
+		col, okCol := number(n - 3)
+		if okLine && okCol && parts[n-2] != "" {
+			return NodeIDParts{FilePath: file(n - 4), Line: line, Column: col, Kind: parts[n-2], Hash: parts[n-1]}, true
+		}
+	}
+
+	// Previous formats
+	if line, ok := number(n - 2); ok {
+		if col, ok := number(n - 1); ok {
+			return NodeIDParts{FilePath: file(n - 2), Line: line, Column: col}, true
+		}
+		if parts[n-1] == NodeKindFunc {
+			return NodeIDParts{FilePath: file(n - 2), Line: line, Kind: NodeKindFunc}, true
+		}
+	}
+	if n >= 4 && parts[n-2] == NodeKindParam {
+		if line, ok := number(n - 3); ok {
+			return NodeIDParts{FilePath: file(n - 3), Line: line, Kind: NodeKindParam}, true
+		}
+	}
+	if n >= 4 {
+		line, okLine := number(n - 3)
+		col, okCol := number(n - 2)
+		if okLine && okCol {
+			kind := string(NodeVariable) // file:line:column:name of array builtin targets
+			if parts[n-1] == NodeKindCall {
+				kind = NodeKindCall
+			}
+			return NodeIDParts{FilePath: file(n - 3), Line: line, Column: col, Kind: kind}, true
+		}
+	}
+	return NodeIDParts{}, false
+}
+
+// isNodeHash reports whether s is the 8 hex digit content hash of a node ID
+func isNodeHash(s string) bool {
+	if len(s) != 8 {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package types
+
+import "testing"
+
+func TestNodeID(t *testing.T) {
+	call := NodeID("/app/a.php", 3, 5, NodeKindCall, "foo")
+	variable := NodeID("/app/a.php", 3, 5, string(NodeVariable), "$x = foo()")
+	edited := NodeID("/app/a.php", 3, 5, string(NodeVariable), "$x = bar()")
+	if call == variable || variable == edited {
+		t.Errorf("IDs collide: %s, %s, %s", call, variable, edited)
+	}
+	if again := NodeID("/app/a.php", 3, 5, string(NodeVariable), "$x = foo()"); again != variable {
+		t.Errorf("ID not stable: %s != %s", again, variable)
+	}
+}
+
+func TestParseNodeID(t *testing.T) {
+	id := NodeID("C:/app/a.php", 3, 5, string(NodeVariable), "$x = 1")
+	tests := []struct {
+		id   string
+		want NodeIDParts
+		ok   bool
+	}{
+		{id, NodeIDParts{FilePath: "C:/app/a.php", Line: 3, Column: 5, Kind: "variable", Hash: id[len(id)-8:]}, true},
+		{"/app/a.php:3:5", NodeIDParts{FilePath: "/app/a.php", Line: 3, Column: 5}, true},
+		{"/app/a.php:3:5:call", NodeIDParts{FilePath: "/app/a.php", Line: 3, Column: 5, Kind: NodeKindCall}, true},
+		{"/app/a.php:3:func", NodeIDParts{FilePath: "/app/a.php", Line: 3, Kind: NodeKindFunc}, true},
+		{"/app/a.php:3:param:id", NodeIDParts{FilePath: "/app/a.php", Line: 3, Kind: NodeKindParam}, true},
+		{"/app/a.php:3:5:$keys", NodeIDParts{FilePath: "/app/a.php", Line: 3, Column: 5, Kind: "variable"}, true},
+		{"source-$_GET", NodeIDParts{}, false},
+		{"3:5", NodeIDParts{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseNodeID(tt.id)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseNodeID(%q) = %+v, %v; want %+v, %v", tt.id, got, ok, tt.want, tt.ok)
+		}
+	}
+}
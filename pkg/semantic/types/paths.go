@@ -0,0 +1,186 @@
+package types
+
+import "fmt"
+
+// DefaultMaxAllPaths caps the number of paths AllPaths returns, since the
+// simple paths between two nodes grow exponentially with the branching
+const DefaultMaxAllPaths = 1000
+
+// flowGraph is the adjacency of a FlowMap built for one query. Parallel edges
+// of different types between two nodes are followed once, through the first.
+type flowGraph struct {
+	nodes map[string]*FlowNode
+	out   map[string][]*FlowEdge
+	in    map[string][]*FlowEdge
+}
+
+// graph builds the adjacency of the flow map
+func (fm *FlowMap) graph() *flowGraph {
+	g := &flowGraph{
+		nodes: make(map[string]*FlowNode, len(fm.AllNodes)),
+		out:   make(map[string][]*FlowEdge),
+		in:    make(map[string][]*FlowEdge),
+	}
+	for i := range fm.AllNodes {
+		g.nodes[fm.AllNodes[i].ID] = &fm.AllNodes[i]
+	}
+	seen := make(map[string]bool, len(fm.AllEdges))
+	for i := range fm.AllEdges {
+		edge := &fm.AllEdges[i]
+		if seen[edge.From+"->"+edge.To] {
+			continue
+		}
+		seen[edge.From+"->"+edge.To] = true
+		g.out[edge.From] = append(g.out[edge.From], edge)
+		g.in[edge.To] = append(g.in[edge.To], edge)
+	}
+	return g
+}
+
+// distancesTo returns the fewest edges from each node that reaches toID to
+// toID, found breadth-first over the reversed edges. Nodes that cannot reach
+// toID are absent.
+func (g *flowGraph) distancesTo(toID string) map[string]int {
+	dist := map[string]int{toID: 0}
+	queue := []string{toID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.in[id] {
+			if _, visited := dist[edge.From]; !visited {
+				dist[edge.From] = dist[id] + 1
+				queue = append(queue, edge.From)
+			}
+		}
+	}
+	return dist
+}
+
+// path converts a node sequence and the edges between consecutive nodes into
+// a FlowPath, or returns false if a node is missing from the map
+func (g *flowGraph) path(id string, nodeIDs []string, edges []*FlowEdge) (FlowPath, bool) {
+	p := FlowPath{ID: id, Steps: make([]FlowStep, 0, len(nodeIDs))}
+	for i, nodeID := range nodeIDs {
+		node := g.nodes[nodeID]
+		if node == nil {
+			return FlowPath{}, false
+		}
+		step := FlowStep{Node: *node, StepNumber: i + 1}
+		if i < len(edges) {
+			edge := *edges[i]
+			step.Edge = &edge
+			step.Description = edge.Description
+		}
+		p.Steps = append(p.Steps, step)
+	}
+	p.Source = &p.Steps[0].Node
+	p.Target = &p.Steps[len(p.Steps)-1].Node
+	p.Description = fmt.Sprintf("%s to %s in %d steps", p.Source.Name, p.Target.Name, len(edges))
+	return p, true
+}
+
+// ShortestPath returns a path from one node to another with the fewest edges,
+// found breadth-first, or false if the target is unreachable or either node
+// is not in the map. A node reaches itself through a path of one step.
+func (fm *FlowMap) ShortestPath(fromID, toID string) (*FlowPath, bool) {
+	g := fm.graph()
+	if g.nodes[fromID] == nil || g.nodes[toID] == nil {
+		return nil, false
+	}
+
+	// via records the edge each node was first reached through
+	via := map[string]*FlowEdge{fromID: nil}
+	queue := []string{fromID}
+	for len(queue) > 0 && via[toID] == nil && fromID != toID {
+		id := queue[0]
+		queue = queue[1:]
+		for _, edge := range g.out[id] {
+			if _, visited := via[edge.To]; !visited {
+				via[edge.To] = edge
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	if _, reached := via[toID]; !reached {
+		return nil, false
+	}
+
+	nodeIDs := []string{toID}
+	var edges []*FlowEdge
+	for id := toID; via[id] != nil; id = via[id].From {
+		nodeIDs = append([]string{via[id].From}, nodeIDs...)
+		edges = append([]*FlowEdge{via[id]}, edges...)
+	}
+	p, ok := g.path(fromID+"->"+toID, nodeIDs, edges)
+	if !ok {
+		return nil, false
+	}
+	return &p, true
+}
+
+// AllPaths returns the simple paths (no node visited twice, so cycles are
+// not followed) from one node to another with at most maxLen edges
+// (0 = unlimited), shortest first. At most DefaultMaxAllPaths are returned;
+// when the cap cuts the result short the longest paths are the ones dropped.
+//
+// Paths are enumerated one length at a time and only through nodes that
+// reach toID within the remaining edges, so branches that cannot end at
+// toID are never walked. Paths of equal length keep the edge order of the map.
+func (fm *FlowMap) AllPaths(fromID, toID string, maxLen int) []FlowPath {
+	g := fm.graph()
+	if g.nodes[fromID] == nil || g.nodes[toID] == nil {
+		return nil
+	}
+	dist := g.distancesTo(toID)
+	minLen, reachable := dist[fromID]
+	if !reachable {
+		return nil
+	}
+	// Every node of a simple path to toID reaches toID, so none is longer
+	// than the number of such nodes
+	if maxLen <= 0 || maxLen > len(dist)-1 {
+		maxLen = len(dist) - 1
+	}
+
+	var found [][]*FlowEdge
+	var edges []*FlowEdge
+	onPath := map[string]bool{fromID: true}
+	var walk func(id string, length int)
+	walk = func(id string, length int) {
+		if id == toID {
+			if len(edges) == length {
+				found = append(found, append([]*FlowEdge(nil), edges...))
+			}
+			return
+		}
+		for _, edge := range g.out[id] {
+			if len(found) >= DefaultMaxAllPaths {
+				return
+			}
+			d, reaches := dist[edge.To]
+			if !reaches || onPath[edge.To] || len(edges)+1+d > length {
+				continue
+			}
+			onPath[edge.To] = true
+			edges = append(edges, edge)
+			walk(edge.To, length)
+			edges = edges[:len(edges)-1]
+			onPath[edge.To] = false
+		}
+	}
+	for length := minLen; length <= maxLen && len(found) < DefaultMaxAllPaths; length++ {
+		walk(fromID, length)
+	}
+
+	paths := make([]FlowPath, 0, len(found))
+	for i, pathEdges := range found {
+		nodeIDs := []string{fromID}
+		for _, edge := range pathEdges {
+			nodeIDs = append(nodeIDs, edge.To)
+		}
+		if p, ok := g.path(fmt.Sprintf("%s->%s#%d", fromID, toID, i+1), nodeIDs, pathEdges); ok {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
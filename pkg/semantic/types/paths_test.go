@@ -0,0 +1,113 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// pathNodes returns the node IDs of a path
+func pathNodes(p FlowPath) []string {
+	var ids []string
+	for _, step := range p.Steps {
+		ids = append(ids, step.Node.ID)
+	}
+	return ids
+}
+
+func TestFlowMapPaths(t *testing.T) {
+	fm := NewFlowMap()
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		fm.AddNode(FlowNode{ID: id, Name: id})
+	}
+	for _, e := range [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}, {"b", "c"}, {"d", "a"}} {
+		fm.AddEdge(FlowEdge{From: e[0], To: e[1], Type: EdgeAssignment})
+	}
+	fm.AddEdge(FlowEdge{From: "a", To: "b", Type: EdgeCall}) // Parallel edge, followed once
+
+	shortest := []struct {
+		from, to string
+		want     []string
+	}{
+		{"a", "d", []string{"a", "b", "d"}},
+		{"d", "c", []string{"d", "a", "c"}},
+		{"a", "a", []string{"a"}},
+		{"a", "e", nil},
+		{"a", "missing", nil},
+	}
+	for _, tt := range shortest {
+		p, ok := fm.ShortestPath(tt.from, tt.to)
+		if ok != (tt.want != nil) {
+			t.Errorf("ShortestPath(%s, %s) found = %v, want %v", tt.from, tt.to, ok, tt.want != nil)
+			continue
+		}
+		if ok && !reflect.DeepEqual(pathNodes(*p), tt.want) {
+			t.Errorf("ShortestPath(%s, %s) = %v, want %v", tt.from, tt.to, pathNodes(*p), tt.want)
+		}
+	}
+
+	all := []struct {
+		maxLen int
+		want   [][]string
+	}{
+		{0, [][]string{{"a", "b", "d"}, {"a", "c", "d"}, {"a", "b", "c", "d"}}},
+		{2, [][]string{{"a", "b", "d"}, {"a", "c", "d"}}},
+		{1, nil},
+	}
+	for _, tt := range all {
+		var got [][]string
+		for _, p := range fm.AllPaths("a", "d", tt.maxLen) {
+			got = append(got, pathNodes(p))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AllPaths(a, d, %d) = %v, want %v", tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestAllPathsPrunesAndKeepsShortest(t *testing.T) {
+	// A dense region hanging off the source that never reaches the target
+	dense := NewFlowMap()
+	dense.AddNode(FlowNode{ID: "s"})
+	dense.AddNode(FlowNode{ID: "t"})
+	for i := 0; i < 30; i++ {
+		dense.AddNode(FlowNode{ID: fmt.Sprintf("x%d", i)})
+		for j := 0; j < i; j++ {
+			dense.AddEdge(FlowEdge{From: fmt.Sprintf("x%d", j), To: fmt.Sprintf("x%d", i)})
+		}
+	}
+	dense.AddEdge(FlowEdge{From: "s", To: "x0"})
+	dense.AddEdge(FlowEdge{From: "s", To: "t"})
+	if got := dense.AllPaths("s", "t", 0); len(got) != 1 || !reflect.DeepEqual(pathNodes(got[0]), []string{"s", "t"}) {
+		t.Errorf("AllPaths through dense region = %d paths, want only s->t", len(got))
+	}
+
+	// More long paths than the cap, with the direct edge last in map order
+	layered := NewFlowMap()
+	layered.AddNode(FlowNode{ID: "s"})
+	layered.AddNode(FlowNode{ID: "t"})
+	prev := []string{"s"}
+	for layer := 0; layer < 5; layer++ {
+		var cur []string
+		for k := 0; k < 4; k++ {
+			id := fmt.Sprintf("l%d_%d", layer, k)
+			layered.AddNode(FlowNode{ID: id})
+			for _, from := range prev {
+				layered.AddEdge(FlowEdge{From: from, To: id})
+			}
+			cur = append(cur, id)
+		}
+		prev = cur
+	}
+	for _, from := range prev {
+		layered.AddEdge(FlowEdge{From: from, To: "t"})
+	}
+	layered.AddEdge(FlowEdge{From: "s", To: "t"})
+	got := layered.AllPaths("s", "t", 0)
+	if len(got) != DefaultMaxAllPaths {
+		t.Fatalf("AllPaths returned %d paths, want the cap %d", len(got), DefaultMaxAllPaths)
+	}
+	if !reflect.DeepEqual(pathNodes(got[0]), []string{"s", "t"}) {
+		t.Errorf("first path = %v, want the direct s->t", pathNodes(got[0]))
+	}
+}
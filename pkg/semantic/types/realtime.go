@@ -0,0 +1,27 @@
+package types
+
+// Realtime protocols of message handlers
+const (
+	ProtocolWebSocket = "websocket"
+	ProtocolSSE       = "sse"
+)
+
+// RealtimeHandler is a WebSocket or Server-Sent Events message handler: an
+// entry point invoked for every message a peer sends
+type RealtimeHandler struct {
+	Protocol  string            `json:"protocol"`            // ProtocolWebSocket or ProtocolSSE
+	Framework string            `json:"framework,omitempty"` // e.g. "ratchet", "socket.io", "gorilla/websocket"
+	Event     string            `json:"event,omitempty"`     // Event name for event-dispatching APIs
+	Function  string            `json:"function,omitempty"`  // Handler method or enclosing function
+	FilePath  string            `json:"file_path"`
+	Line      int               `json:"line"`
+	EndLine   int               `json:"end_line"`
+	Payloads  []RealtimePayload `json:"payloads,omitempty"` // Parameters or variables receiving message data
+}
+
+// RealtimePayload is a parameter or variable receiving message data
+type RealtimePayload struct {
+	Name   string `json:"name"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
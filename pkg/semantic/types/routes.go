@@ -0,0 +1,38 @@
+package types
+
+// RouteDispatch is a custom router selecting a handler by a request value:
+// a switch on the value calling a handler per case, or an array mapping
+// actions to callables that is indexed by the value and called
+type RouteDispatch struct {
+	Key      string         `json:"key"` // Expression selecting the handler, e.g. "$_GET['action']"
+	FilePath string         `json:"file_path"`
+	Line     int            `json:"line"`
+	Handlers []RouteHandler `json:"handlers"`
+
+	// Position of the dynamic call running the handler (array maps only)
+	CallLine   int `json:"call_line,omitempty"`
+	CallColumn int `json:"call_column,omitempty"`
+	// Index of the call's first argument passed on to the handler, or -1
+	// when the handler's arguments are not known (call_user_func_array)
+	ArgOffset int `json:"arg_offset"`
+}
+
+// RouteHandler is the function a RouteDispatch runs for one action
+type RouteHandler struct {
+	Action   string `json:"action"`
+	Function string `json:"function"` // Function or Class::method
+	Line     int    `json:"line"`     // Line of the case or array entry
+}
+
+// AttributeRoute is a route a framework declares without a route file: a
+// controller method carrying a #[Route] attribute, or a page served by
+// filesystem convention
+type AttributeRoute struct {
+	Framework string   `json:"framework"` // e.g. "symfony", "folio"
+	Path      string   `json:"path"`      // Path pattern with its parameters, e.g. "/blog/{slug}"
+	Methods   []string `json:"methods,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Function  string   `json:"function,omitempty"` // Class::method handling the route
+	FilePath  string   `json:"file_path"`
+	Line      int      `json:"line"`
+}
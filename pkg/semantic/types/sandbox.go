@@ -0,0 +1,67 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sandbox restricts file reads to a set of root directories. Paths are
+// compared after resolving symlinks, so a link inside a root pointing outside
+// it escapes. A nil sandbox allows every path.
+type Sandbox struct {
+	roots []string // Absolute, symlink-resolved
+}
+
+// NewSandbox creates a sandbox for the given roots, or nil when there are none
+func NewSandbox(roots []string) *Sandbox {
+	if len(roots) == 0 {
+		return nil
+	}
+	s := &Sandbox{}
+	for _, root := range roots {
+		s.roots = append(s.roots, canonicalPath(root))
+	}
+	return s
+}
+
+// Allows reports whether path resolves inside one of the sandbox roots
+func (s *Sandbox) Allows(path string) bool {
+	if s == nil {
+		return true
+	}
+	resolved := canonicalPath(path)
+	for _, root := range s.roots {
+		if rel, err := filepath.Rel(root, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadFile reads a file inside the sandbox; a path outside it fails with
+// ErrSandboxEscape without being opened
+func (s *Sandbox) ReadFile(path string) ([]byte, error) {
+	if !s.Allows(path) {
+		return nil, fmt.Errorf("%w: %s", ErrSandboxEscape, path)
+	}
+	return os.ReadFile(path)
+}
+
+// canonicalPath returns the absolute, cleaned path with symlinks resolved.
+// For a path that does not exist yet the longest existing parent is resolved.
+func canonicalPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	parent := filepath.Dir(abs)
+	if parent == abs {
+		return abs
+	}
+	return filepath.Join(canonicalPath(parent), filepath.Base(abs))
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSandboxAllows(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "app")
+	outside := filepath.Join(dir, "secret.php")
+	if err := os.MkdirAll(filepath.Join(root, "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(outside, []byte("<?php"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link.php")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	s := NewSandbox([]string{root})
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"root itself", root, true},
+		{"file in root", filepath.Join(root, "lib", "db.php"), true},
+		{"dot-dot escape", filepath.Join(root, "lib", "..", "..", "secret.php"), false},
+		{"sibling with root prefix", root + "-old/index.php", false},
+		{"symlink pointing outside", filepath.Join(root, "link.php"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Allows(tt.path); got != tt.want {
+				t.Errorf("allows(%s) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	var none *Sandbox
+	if !none.Allows(outside) {
+		t.Error("nil sandbox must allow every path")
+	}
+
+	if _, err := s.ReadFile(filepath.Join(root, "link.php")); !errors.Is(err, ErrSandboxEscape) {
+		t.Errorf("ReadFile through an escaping symlink: err = %v, want ErrSandboxEscape", err)
+	}
+	if _, err := none.ReadFile(outside); err != nil {
+		t.Errorf("nil sandbox ReadFile: %v", err)
+	}
+}
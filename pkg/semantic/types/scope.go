@@ -0,0 +1,176 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources/constants"
+)
+
+// ScopeType represents the kind of a lexical scope
+// Re-exported from pkg/sources/constants for backward compatibility
+type ScopeType = constants.ScopeType
+
+// Re-export ScopeType constants
+const (
+	ScopeFile     = constants.ScopeFile
+	ScopeClass    = constants.ScopeClass
+	ScopeFunction = constants.ScopeFunction
+	ScopeBlock    = constants.ScopeBlock
+)
+
+// Scope is a node in a file's scope tree (file → class → method → block).
+// ID is the scope-qualified name used for variable resolution; the file
+// scope has an empty ID so file-level code keeps the historical "" scope.
+type Scope struct {
+	ID        string    `json:"id"`
+	Type      ScopeType `json:"type"`
+	Name      string    `json:"name"`
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Parent    *Scope    `json:"-"`
+	Children  []*Scope  `json:"children,omitempty"`
+
+	// Globals are the variables (without $) a function declares global, which
+	// are file-level variables rather than locals
+	Globals []string `json:"globals,omitempty"`
+}
+
+// NewFileScope creates the root scope of a file's scope tree
+func NewFileScope(filePath string, endLine int) *Scope {
+	return &Scope{
+		Type:      ScopeFile,
+		Name:      filePath,
+		StartLine: 1,
+		EndLine:   endLine,
+	}
+}
+
+// AddChild creates a nested scope and links it into the tree
+func (s *Scope) AddChild(scopeType ScopeType, name string, startLine, endLine int) *Scope {
+	child := &Scope{
+		ID:        QualifyScopeID(s, scopeType, name, startLine),
+		Type:      scopeType,
+		Name:      name,
+		StartLine: startLine,
+		EndLine:   endLine,
+		Parent:    s,
+	}
+	s.Children = append(s.Children, child)
+	return child
+}
+
+// QualifyScopeID builds the ID of a scope nested in parent.
+// Members of a class are joined with "::" (Foo::bar), other nesting with "/".
+// Anonymous scopes (closures, blocks) are named by their start line.
+func QualifyScopeID(parent *Scope, scopeType ScopeType, name string, startLine int) string {
+	if name == "" {
+		name = fmt.Sprintf("{%s@%d}", scopeType, startLine)
+	}
+	if parent == nil || parent.ID == "" {
+		return name
+	}
+	if parent.Type == ScopeClass {
+		return parent.ID + "::" + name
+	}
+	return parent.ID + "/" + name
+}
+
+// Innermost returns the deepest scope containing the given line
+func (s *Scope) Innermost(line int) *Scope {
+	if s == nil {
+		return nil
+	}
+	for _, child := range s.Children {
+		if line >= child.StartLine && line <= child.EndLine {
+			return child.Innermost(line)
+		}
+	}
+	return s
+}
+
+// VariableScope returns the nearest enclosing scope that owns local variables.
+// Blocks and class bodies do not isolate variables in PHP, so resolution walks
+// up to the enclosing function (or the file for top-level code).
+func (s *Scope) VariableScope() *Scope {
+	for cur := s; cur != nil; cur = cur.Parent {
+		if cur.Type == ScopeFunction || cur.Type == ScopeFile {
+			return cur
+		}
+	}
+	return s
+}
+
+// Find returns the scope with the given ID, or nil
+func (s *Scope) Find(id string) *Scope {
+	if s == nil {
+		return nil
+	}
+	if s.ID == id {
+		return s
+	}
+	for _, child := range s.Children {
+		if found := child.Find(id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// VariableScopeAt returns the variable-owning scope ID for a line ("" for file level)
+func (s *Scope) VariableScopeAt(line int) string {
+	if s == nil {
+		return ""
+	}
+	return s.Innermost(line).VariableScope().ID
+}
+
+// DeclaresGlobal reports whether the scope declares a variable (with or
+// without $) global
+func (s *Scope) DeclaresGlobal(name string) bool {
+	if s == nil {
+		return false
+	}
+	name = strings.TrimPrefix(name, "$")
+	for _, global := range s.Globals {
+		if global == name {
+			return true
+		}
+	}
+	return false
+}
+
+// VariableScopeOf returns the scope ID owning a variable read or assigned at
+// a line: the enclosing function, or the file ("") when the function declares
+// the variable global
+func (s *Scope) VariableScopeOf(line int, name string) string {
+	if s == nil {
+		return ""
+	}
+	scope := s.Innermost(line).VariableScope()
+	if scope.DeclaresGlobal(name) {
+		return ""
+	}
+	return scope.ID
+}
+
+// ResolveVariable returns the scope ID owning a variable used in the scope
+// with the given ID: "" when that function declares it global, else id
+func (s *Scope) ResolveVariable(id, name string) string {
+	if id == "" || !s.Find(id).DeclaresGlobal(name) {
+		return id
+	}
+	return ""
+}
+
+// SameVariableScope reports whether an assignment in scope a can be seen by
+// a read in scope b. Function locals only resolve within the same function;
+// file-level (global) code resolves across files since includes share it.
+// Variables a function declares global belong to the file scope: resolve
+// both scopes with ResolveVariable (or VariableScopeOf) first.
+func SameVariableScope(a, b string, sameFile bool) bool {
+	if a != b {
+		return false
+	}
+	return sameFile || a == ""
+}
@@ -0,0 +1,35 @@
+package types
+
+import "testing"
+
+func TestScopeGlobals(t *testing.T) {
+	file := NewFileScope("index.php", 20)
+	fn := file.AddChild(ScopeFunction, "session", 3, 8)
+	fn.Globals = []string{"token"}
+	fn.AddChild(ScopeBlock, "", 5, 7)
+
+	tests := []struct {
+		line int
+		name string
+		want string
+	}{
+		{2, "$token", ""},
+		{4, "$token", ""},     // Declared global
+		{6, "token", ""},      // Inside a block of the function
+		{4, "$id", "session"}, // A local
+	}
+	for _, tt := range tests {
+		if got := file.VariableScopeOf(tt.line, tt.name); got != tt.want {
+			t.Errorf("VariableScopeOf(%d, %s) = %q, want %q", tt.line, tt.name, got, tt.want)
+		}
+	}
+	if got := file.ResolveVariable("session", "$token"); got != "" {
+		t.Errorf("ResolveVariable(session, $token) = %q, want file scope", got)
+	}
+	if got := file.ResolveVariable("session", "$id"); got != "session" {
+		t.Errorf("ResolveVariable(session, $id) = %q, want session", got)
+	}
+	if got := file.ResolveVariable("missing", "$token"); got != "missing" {
+		t.Errorf("ResolveVariable(missing, $token) = %q, want the scope unchanged", got)
+	}
+}
@@ -0,0 +1,66 @@
+package types
+
+// TerminationReason tells why a trace stopped, so that a trace without
+// sources can be told apart as proven clean or as incomplete analysis
+type TerminationReason string
+
+const (
+	TerminationSource          TerminationReason = "source"           // Reached an input source
+	TerminationClean           TerminationReason = "clean"            // Every flow ended without input
+	TerminationResourceLimit   TerminationReason = "resource_limit"   // Memory, time or source limit reached
+	TerminationDepthLimit      TerminationReason = "depth_limit"      // Maximum depth or chain length reached
+	TerminationClassNotFound   TerminationReason = "class_not_found"  // Class or its instantiation not found
+	TerminationDynamicDispatch TerminationReason = "dynamic_dispatch" // Method target depends on an unresolved receiver
+	TerminationUnresolved      TerminationReason = "unresolved"       // Variable, call or expression not followed
+)
+
+// terminationRank orders reasons by precedence when combining them
+var terminationRank = map[TerminationReason]int{
+	TerminationClean:           1,
+	TerminationUnresolved:      2,
+	TerminationDynamicDispatch: 3,
+	TerminationClassNotFound:   4,
+	TerminationDepthLimit:      5,
+	TerminationResourceLimit:   6,
+	TerminationSource:          7,
+}
+
+// Incomplete reports whether the trace stopped at an analysis gap rather than
+// at input or a proven clean end
+func (r TerminationReason) Incomplete() bool {
+	return r != "" && r != TerminationSource && r != TerminationClean
+}
+
+// CombineTermination returns the reason of two traces merged: reaching input
+// wins, then the gaps from resource limits down to unresolved code, then clean
+func CombineTermination(a, b TerminationReason) TerminationReason {
+	if terminationRank[b] > terminationRank[a] {
+		return b
+	}
+	return a
+}
+
+// TerminationFromWarning returns the reason a trace stopped at the gap w
+func TerminationFromWarning(w AnalysisWarning) TerminationReason {
+	switch w.Category {
+	case WarningMemoryLimit, WarningTimeLimit, WarningSourceLimit:
+		return TerminationResourceLimit
+	case WarningDepthCutoff, WarningChainTruncated:
+		return TerminationDepthLimit
+	case WarningClassNotFound, WarningInstantiationNotFound:
+		return TerminationClassNotFound
+	case WarningReturnTypeUnknown:
+		return TerminationDynamicDispatch
+	}
+	return TerminationUnresolved
+}
+
+// TerminationFromWarnings returns the reason a trace that found no source
+// stopped: the most severe of its gaps, or clean without any
+func TerminationFromWarnings(warnings []AnalysisWarning) TerminationReason {
+	reason := TerminationClean
+	for _, w := range warnings {
+		reason = CombineTermination(reason, TerminationFromWarning(w))
+	}
+	return reason
+}
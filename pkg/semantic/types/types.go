@@ -29,6 +29,7 @@ const (
 	NodeProperty = constants.NodeProperty
 	NodeParam    = constants.NodeParam
 	NodeReturn   = constants.NodeReturn
+	NodeBarrier  = constants.NodeBarrier
 )
 
 // FlowEdgeType represents how data flows between nodes
@@ -105,6 +106,18 @@ type FlowNode struct {
 	// Source information (if this is a source node)
 	SourceType SourceType `json:"source_type,omitempty"`
 	SourceKey  string     `json:"source_key,omitempty"` // Parameter name
+	Canonical  string     `json:"canonical,omitempty"`  // Language-independent channel and key, e.g. "http_get:id"
+
+	// Taint labels (pii, auth_token, ...) attached by label rules to sources
+	// and carried to every node they flow to
+	Labels []string `json:"labels,omitempty"`
+
+	// Architectural layer (controller, model, view, cli, migration) of the
+	// code the node is in, from path and class-name conventions
+	Layer string `json:"layer,omitempty"`
+
+	// Effective type of the input parameter inferred from its uses (sources only)
+	Constraint *ParamConstraint `json:"constraint,omitempty"`
 
 	// Carrier information
 	CarrierType string `json:"carrier_type,omitempty"` // "array", "object_property", etc.
@@ -135,6 +148,59 @@ type FlowEdge struct {
 
 	// Additional context
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+
+	// For edges crossing files: how the target file is reachable
+	IncludeChain *IncludeChain `json:"include_chain,omitempty"`
+}
+
+// Reachability tells whether a cross-file hop is backed by includes
+type Reachability string
+
+const (
+	// ReachabilityIncluded means an include chain loads the target file
+	ReachabilityIncluded Reachability = "included"
+	// ReachabilityRuntime means the include chain uses includes only
+	// observed at runtime (see semantic.RuntimeHints)
+	ReachabilityRuntime Reachability = "runtime_observed"
+	// ReachabilityUnproven means the target is a global symbol no include
+	// chain is known for (autoloading, dynamic includes or dead code)
+	ReachabilityUnproven Reachability = "global_symbol_unproven"
+)
+
+// IncludeChain is the include chain making a file reachable in a cross-file
+// hop: Files runs from the entry file (the hop's origin file or a file that
+// includes it) through each included file to the hop's target file
+type IncludeChain struct {
+	From         string       `json:"from"`
+	To           string       `json:"to"`
+	Reachability Reachability `json:"reachability"`
+	Files        []string     `json:"files,omitempty"`
+}
+
+// ParamType is the effective type of an input parameter
+// Re-exported from pkg/sources/constants for backward compatibility
+type ParamType = constants.ParamType
+
+// Re-export ParamType constants for backward compatibility
+const (
+	ParamInt        = constants.ParamInt
+	ParamFloat      = constants.ParamFloat
+	ParamBool       = constants.ParamBool
+	ParamEnum       = constants.ParamEnum
+	ParamPattern    = constants.ParamPattern
+	ParamFreeString = constants.ParamFreeString
+)
+
+// ParamConstraint describes how an input parameter is constrained by its uses,
+// e.g. an immediate (int) cast or an in_array() check against literals, or by
+// the getter reading it, e.g. getInt() or a MyBB::INPUT_INT type flag
+type ParamConstraint struct {
+	Type     ParamType `json:"type"`
+	Values   []string  `json:"values,omitempty"`   // Allowed literals (enum)
+	Pattern  string    `json:"pattern,omitempty"`  // Regex the value is matched against (pattern)
+	Default  string    `json:"default,omitempty"`  // Default a getter returns when the input is absent
+	Evidence string    `json:"evidence,omitempty"` // Expression that established the constraint
+	Line     int       `json:"line,omitempty"`
 }
 
 // TypeInfo holds type information for a node
@@ -303,6 +369,19 @@ func (fm *FlowMap) AddUsage(usage FlowNode) bool {
 	return false
 }
 
+// Reindex rebuilds the deduplication indexes from AllNodes and AllEdges,
+// after node IDs or edge ends were changed in place
+func (fm *FlowMap) Reindex() {
+	fm.nodeIndex = make(map[string]bool, len(fm.AllNodes))
+	for _, node := range fm.AllNodes {
+		fm.nodeIndex[node.ID] = true
+	}
+	fm.edgeIndex = make(map[string]bool, len(fm.AllEdges))
+	for _, edge := range fm.AllEdges {
+		fm.edgeIndex[edge.From+"->"+edge.To+":"+string(edge.Type)] = true
+	}
+}
+
 // HasNode checks if a node ID exists in O(1)
 func (fm *FlowMap) HasNode(nodeID string) bool {
 	if fm.nodeIndex == nil {
@@ -320,6 +399,12 @@ func (fm *FlowMap) HasEdge(from, to string, edgeType FlowEdgeType) bool {
 	return fm.edgeIndex[edgeKey]
 }
 
+// Full reports whether the map reached its node or edge limit, so that
+// further nodes or edges were dropped
+func (fm *FlowMap) Full() bool {
+	return (fm.maxNodes > 0 && len(fm.AllNodes) >= fm.maxNodes) || (fm.maxEdges > 0 && len(fm.AllEdges) >= fm.maxEdges)
+}
+
 // FlowTarget specifies what expression to trace
 type FlowTarget struct {
 	FilePath   string `json:"file_path"`
@@ -380,6 +465,9 @@ type SymbolTable struct {
 	Constants  map[string]*ConstantDef `json:"constants"`
 	Namespace  string                  `json:"namespace,omitempty"`
 
+	// Scope tree (file → class → method → block) for scope-qualified resolution
+	Scopes     *Scope                  `json:"scopes,omitempty"`
+
 	// File-level metadata
 	Framework  string                 `json:"framework,omitempty"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
@@ -647,6 +735,100 @@ type CallSite struct {
 	TaintedArgIndices []int   `json:"tainted_arg_indices,omitempty"`
 }
 
+// TemplateBinding records an extract($vars) followed by an include/require in
+// the same scope: free variables of the included file are bound to the keys
+// of the extracted array
+type TemplateBinding struct {
+	ExtractedExpr string            `json:"extracted_expr"`           // Argument of extract(), e.g. "$vars"
+	Values        map[string]string `json:"values,omitempty"`         // Known key → value expressions (array literal / compact)
+	IncludeExpr   string            `json:"include_expr"`             // Raw include path expression
+	IncludePath   string            `json:"include_path,omitempty"`   // Resolved path of the included file ("" if dynamic)
+	FilePath      string            `json:"file_path"`
+	ExtractLine   int               `json:"extract_line"`
+	IncludeLine   int               `json:"include_line"`
+	Scope         string            `json:"scope"`
+}
+
+// RegisterLoop records file-scope code copying a superglobal into variables
+// named after its keys, the register_globals emulation of legacy PHP:
+// `foreach ($_REQUEST as $k => $v) $$k = $v;` or `extract($_GET)`. Variables
+// read after it without an assignment of their own hold request input.
+type RegisterLoop struct {
+	Superglobals []string             `json:"superglobals"` // Copied superglobals, e.g. ["$_GET", "$_POST"]
+	Code         string               `json:"code"`         // The loop or extract() call, first line
+	FilePath     string               `json:"file_path"`
+	Line         int                  `json:"line"`
+	EndLine      int                  `json:"end_line"`
+	Variables    []RegisteredVariable `json:"variables,omitempty"` // First reads of undeclared variables after it
+}
+
+// RegisteredVariable is the first read of a variable a RegisterLoop defines
+type RegisteredVariable struct {
+	Name   string `json:"name"` // e.g. "$id"
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// SourceWrapper is a user-defined function whose body directly returns a
+// superglobal indexed by one of its parameters, e.g.
+// `function get_param($k) { return $_REQUEST[$k] ?? null; }`.
+// Every call site is promoted to a source keyed by that argument.
+type SourceWrapper struct {
+	FunctionName string     `json:"function_name"`
+	ParamIndex   int        `json:"param_index"`  // Index of the parameter used as the key
+	ParamName    string     `json:"param_name"`
+	Superglobal  string     `json:"superglobal"`  // e.g. "$_REQUEST"
+	SourceType   SourceType `json:"source_type"`
+	FilePath     string     `json:"file_path"`
+	Line         int        `json:"line"`
+}
+
+// ReturnSummary records, key by key, which values of the array literals a
+// function returns carry input, e.g. only `id` for
+// `function get_user() { return ['id' => $_GET['id'], 'name' => 'static']; }`.
+// Reads of a tainted key from the call's result are promoted to sources.
+type ReturnSummary struct {
+	FunctionName string                `json:"function_name"`
+	TaintedKeys  map[string]SourceType `json:"tainted_keys"` // Key → source type of its value
+	FilePath     string                `json:"file_path"`
+	Line         int                   `json:"line"`
+}
+
+// DeadRange is a run of statements no execution reaches because they follow
+// a statement that always leaves their block (exit, die, return, throw), e.g.
+// the assignment in `if ($denied) { exit; $x = $_GET['x']; }`.
+type DeadRange struct {
+	StartLine      int    `json:"start_line"`
+	EndLine        int    `json:"end_line"`
+	Terminator     string `json:"terminator"` // exit, die(), return, throw, ...
+	TerminatorLine int    `json:"terminator_line"`
+}
+
+// Validator is a function that rejects invalid input by throwing or exiting,
+// e.g. `function require_int($x) { if (!ctype_digit($x)) throw ...; }`.
+// A value passed to it is constrained for the rest of its scope.
+type Validator struct {
+	FunctionName string          `json:"function_name"`
+	ParamIndex   int             `json:"param_index"` // Index of the validated parameter
+	Constraint   ParamConstraint `json:"constraint"`
+	FilePath     string          `json:"file_path,omitempty"`
+	Line         int             `json:"line,omitempty"`
+	Declared     bool            `json:"declared,omitempty"` // Declared in the rules file rather than detected
+}
+
+// RequestAttribute is a PSR-7 request attribute set via withAttribute().
+// The value is tainted when it contains an input source (SourceType is set)
+// or reads another attribute that is tainted (DependsOn).
+type RequestAttribute struct {
+	Name       string     `json:"name"`
+	Value      string     `json:"value"`
+	SourceType SourceType `json:"source_type,omitempty"`
+	DependsOn  []string   `json:"depends_on,omitempty"`  // Attributes read by the value
+	Derivation []string   `json:"derivation,omitempty"`  // withX() calls the request was derived through
+	FilePath   string     `json:"file_path"`
+	Line       int        `json:"line"`
+}
+
 // CallArg represents a function call argument
 type CallArg struct {
 	Index       int    `json:"index"`
@@ -747,9 +929,19 @@ type BackwardTraceResult struct {
 	// Summary of all sources found
 	Sources []SourceInfo `json:"sources"`
 
+	// Assignments to the target whose trace reached no source
+	DeadEnds []BackwardPath `json:"dead_ends,omitempty"`
+
+	// Why the trace stopped: source when any path reached input, else the most
+	// severe reason among the dead ends; clean only when every one ended clean
+	Termination TerminationReason `json:"termination"`
+
 	// Analysis metadata
 	AnalyzedFiles int           `json:"analyzed_files"`
 	Duration      time.Duration `json:"duration"`
+
+	// What the trace consumed; shared by the variables of a batch
+	Budget *BudgetReport `json:"budget,omitempty"`
 }
 
 // BackwardPath represents one path from a source to the target
@@ -762,6 +954,16 @@ type BackwardPath struct {
 
 	// Whether path crosses file boundaries
 	CrossFile bool `json:"cross_file"`
+
+	// Include chain of each file boundary crossed, in path order
+	IncludeChains []IncludeChain `json:"include_chains,omitempty"`
+
+	// Why the path stopped: source, or for a dead end clean or the gap hit
+	Termination TerminationReason `json:"termination"`
+
+	// Whether the target holds only part of the input: a string offset or a
+	// substring of it
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // BackwardStep represents one step in a backward trace path
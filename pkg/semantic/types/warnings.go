@@ -0,0 +1,164 @@
+package types
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// WarningCategory classifies an analysis gap: a place where tracing could not
+// follow the code and results may be incomplete
+type WarningCategory string
+
+const (
+	WarningClassNotFound         WarningCategory = "class_not_found"
+	WarningInstantiationNotFound WarningCategory = "instantiation_not_found"
+	WarningMethodNotFound        WarningCategory = "method_not_found"
+	WarningPropertyNotFound      WarningCategory = "property_not_found"
+	WarningUnresolvedVariable    WarningCategory = "unresolved_variable"
+	WarningReturnTypeUnknown     WarningCategory = "return_type_unknown"
+	WarningUnsupportedExpression WarningCategory = "unsupported_expression"
+	WarningChainTruncated        WarningCategory = "chain_truncated"
+	WarningDepthCutoff           WarningCategory = "depth_cutoff"
+	WarningUnresolvedInclude     WarningCategory = "unresolved_include"
+	WarningParseError            WarningCategory = "parse_error"
+	WarningFileSkipped           WarningCategory = "file_skipped"
+	WarningSourceLimit           WarningCategory = "source_limit"
+	WarningMemoryLimit           WarningCategory = "memory_limit"
+	WarningTimeLimit             WarningCategory = "time_limit"
+	WarningGrammarUnsupported    WarningCategory = "grammar_unsupported"
+	WarningGrammarMismatch       WarningCategory = "grammar_mismatch"
+	WarningSandboxEscape         WarningCategory = "sandbox_escape"
+
+	// WarningDeprecatedAPI marks a trace made through a deprecated API; it is
+	// not an analysis gap
+	WarningDeprecatedAPI WarningCategory = "deprecated_api"
+
+	// WarningGeneratedFile marks a minified or generated file that was
+	// analyzed; findings in it may come from code nobody edits by hand
+	WarningGeneratedFile WarningCategory = "generated_file"
+)
+
+// AnalysisWarning records one analysis gap
+type AnalysisWarning struct {
+	Category   WarningCategory `json:"category"`
+	Message    string          `json:"message"`
+	FilePath   string          `json:"file_path,omitempty"`
+	Line       int             `json:"line,omitempty"`
+	Expression string          `json:"expression,omitempty"`
+}
+
+// WarningFromError converts a tracing error into a warning, classifying it by
+// its sentinel kind (unknown errors become unsupported_expression)
+func WarningFromError(err error) AnalysisWarning {
+	w := AnalysisWarning{Category: WarningUnsupportedExpression, Message: err.Error()}
+	switch {
+	case errors.Is(err, ErrClassNotFound):
+		w.Category = WarningClassNotFound
+	case errors.Is(err, ErrInstantiationNotFound):
+		w.Category = WarningInstantiationNotFound
+	case errors.Is(err, ErrMethodNotFound):
+		w.Category = WarningMethodNotFound
+	case errors.Is(err, ErrPropertyNotFound):
+		w.Category = WarningPropertyNotFound
+	case errors.Is(err, ErrParse):
+		w.Category = WarningParseError
+	case errors.Is(err, ErrMemoryLimit):
+		w.Category = WarningMemoryLimit
+	case errors.Is(err, ErrTimeLimit):
+		w.Category = WarningTimeLimit
+	}
+
+	var traceErr *TraceError
+	var parseErr *ParseError
+	if errors.As(err, &traceErr) {
+		w.Expression = traceErr.Expression
+	} else if errors.As(err, &parseErr) {
+		w.FilePath = parseErr.FilePath
+	}
+	return w
+}
+
+// CountWarnings returns the number of warnings per category
+func CountWarnings(warnings []AnalysisWarning) map[WarningCategory]int {
+	counts := make(map[WarningCategory]int)
+	for _, w := range warnings {
+		counts[w.Category]++
+	}
+	return counts
+}
+
+// WarningCollector gathers warnings from concurrent workers. Suppressed
+// categories are dropped; past the record limit warnings are only counted.
+type WarningCollector struct {
+	mu         sync.Mutex
+	warnings   []AnalysisWarning
+	counts     map[WarningCategory]int
+	suppressed map[WarningCategory]bool
+	maxRecords int
+}
+
+// NewWarningCollector creates a collector keeping at most maxRecords warnings
+// (0 = unlimited) and ignoring the suppressed categories
+func NewWarningCollector(maxRecords int, suppressed []WarningCategory) *WarningCollector {
+	c := &WarningCollector{
+		counts:     make(map[WarningCategory]int),
+		suppressed: make(map[WarningCategory]bool, len(suppressed)),
+		maxRecords: maxRecords,
+	}
+	for _, category := range suppressed {
+		c.suppressed[category] = true
+	}
+	return c
+}
+
+// Add records a warning unless its category is suppressed
+func (c *WarningCollector) Add(w AnalysisWarning) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.suppressed[w.Category] {
+		return
+	}
+	c.counts[w.Category]++
+	if c.maxRecords == 0 || len(c.warnings) < c.maxRecords {
+		c.warnings = append(c.warnings, w)
+	}
+}
+
+// Warnings returns the recorded warnings sorted by category, file and line
+func (c *WarningCollector) Warnings() []AnalysisWarning {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	warnings := append([]AnalysisWarning(nil), c.warnings...)
+	sort.SliceStable(warnings, func(i, j int) bool {
+		if warnings[i].Category != warnings[j].Category {
+			return warnings[i].Category < warnings[j].Category
+		}
+		if warnings[i].FilePath != warnings[j].FilePath {
+			return warnings[i].FilePath < warnings[j].FilePath
+		}
+		return warnings[i].Line < warnings[j].Line
+	})
+	return warnings
+}
+
+// Counts returns the number of warnings per category, including warnings
+// past the record limit
+func (c *WarningCollector) Counts() map[WarningCategory]int {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[WarningCategory]int, len(c.counts))
+	for category, n := range c.counts {
+		counts[category] = n
+	}
+	return counts
+}
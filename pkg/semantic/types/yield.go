@@ -0,0 +1,34 @@
+package types
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// Yielder pauses a long analysis every so many operations, so that an
+// embedding application keeps its threads for other work. It is safe for
+// concurrent use, and a nil yielder never yields.
+type Yielder struct {
+	every int64
+	hook  func()
+	ops   int64
+}
+
+// NewYielder returns a yielder calling hook (runtime.Gosched when nil) every
+// n operations, or nil when n <= 0
+func NewYielder(n int, hook func()) *Yielder {
+	if n <= 0 {
+		return nil
+	}
+	if hook == nil {
+		hook = runtime.Gosched
+	}
+	return &Yielder{every: int64(n), hook: hook}
+}
+
+// Tick records an operation and yields if it is the n-th since the last yield
+func (y *Yielder) Tick() {
+	if y != nil && atomic.AddInt64(&y.ops, 1)%y.every == 0 {
+		y.hook()
+	}
+}
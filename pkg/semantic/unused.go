@@ -0,0 +1,86 @@
+package semantic
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
+// SourceUsage is the evidence for whether an input is read: the reads of its
+// key and the reads that may return it without naming it
+type SourceUsage struct {
+	Channel types.SourceType `json:"channel"`
+	Key     string           `json:"key,omitempty"`
+
+	// Reads name the key, e.g. $_GET['id'] or $_REQUEST['id'] for http_get:id
+	Reads []InventoryRead `json:"reads,omitempty"`
+	// DynamicReads read the channel with a computed key or as a whole
+	// ($_GET[$name], foreach ($_GET ...)), so they may return the input
+	DynamicReads []InventoryRead `json:"dynamic_reads,omitempty"`
+	// UnparsedFiles failed to parse, so their reads are unknown
+	UnparsedFiles []string `json:"unparsed_files,omitempty"`
+
+	FilesSearched int `json:"files_searched"`
+}
+
+// IsSourceUnused reports whether an input, named by its channel (a source
+// type or alias such as "get" or "env") and key, is provably never read in
+// the parsed codebase. It is not unused while any read names it, a dynamic
+// read may return it or a file could not be parsed; the usage lists them.
+// An empty key asks whether the channel is read at all. The codebase must
+// have been parsed first (ParseOnly or TraceDirectory) or its index loaded;
+// before that, and for an unknown channel, an error is returned.
+func (t *Tracer) IsSourceUnused(channel, key string) (bool, *SourceUsage, error) {
+	st, ok := common.ParseSourceType(channel)
+	if !ok {
+		return false, nil, fmt.Errorf("unknown input channel %q", channel)
+	}
+	t.reparseStale()
+	usage := &SourceUsage{Channel: st, Key: key}
+	key = common.NormalizeSourceKey(st, key)
+
+	t.mu.RLock()
+	if len(t.files) == 0 {
+		t.mu.RUnlock()
+		return false, nil, fmt.Errorf("no files have been parsed: call ParseOnly, TraceDirectory or LoadIndex first")
+	}
+	for path, fileInfo := range t.files {
+		usage.FilesSearched++
+		if fileInfo.Error != nil {
+			usage.UnparsedFiles = append(usage.UnparsedFiles, path)
+			continue
+		}
+		for _, src := range fileInfo.Sources {
+			if !common.SourceTypesOverlap(src.SourceType, st) {
+				continue
+			}
+			_, dead := src.Metadata["unreachable"]
+			read := InventoryRead{FilePath: src.FilePath, Line: src.Line, Snippet: src.Snippet, Unreachable: dead}
+			switch srcKey := common.NormalizeSourceKey(src.SourceType, src.SourceKey); {
+			case key == "" || srcKey == key:
+				usage.Reads = append(usage.Reads, read)
+			case srcKey == "":
+				usage.DynamicReads = append(usage.DynamicReads, read)
+			}
+		}
+	}
+	t.mu.RUnlock()
+
+	sortReads(usage.Reads)
+	sortReads(usage.DynamicReads)
+	sort.Strings(usage.UnparsedFiles)
+	unused := len(usage.Reads) == 0 && len(usage.DynamicReads) == 0 && len(usage.UnparsedFiles) == 0
+	return unused, usage, nil
+}
+
+// sortReads orders reads by file and line
+func sortReads(reads []InventoryRead) {
+	sort.Slice(reads, func(i, j int) bool {
+		if reads[i].FilePath != reads[j].FilePath {
+			return reads[i].FilePath < reads[j].FilePath
+		}
+		return reads[i].Line < reads[j].Line
+	})
+}
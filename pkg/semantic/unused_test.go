@@ -0,0 +1,58 @@
+package semantic
+
+import "testing"
+
+func TestIsSourceUnused(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "index.php", `<?php
+$id = $_GET['id'];
+$name = $_REQUEST['name'];
+$home = getenv('HOME');
+`)
+	writeFile(t, dir, "form.php", `<?php
+foreach ($_POST as $field => $value) {
+    echo $value;
+}
+`)
+
+	tracer := New(nil)
+	if _, _, err := tracer.IsSourceUnused("get", "id"); err == nil {
+		t.Error("expected an error before the codebase is parsed")
+	}
+	if _, err := tracer.ParseOnly(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		channel, key string
+		unused       bool
+		reads        int
+		dynamic      int
+	}{
+		{"http_get", "id", false, 1, 0},
+		{"get", "name", false, 1, 0}, // Read through $_REQUEST
+		{"get", "page", true, 0, 0},
+		{"post", "title", false, 0, 1}, // Every field is iterated
+		{"env", "HOME", false, 1, 0},
+		{"env", "PATH", true, 0, 0},
+		{"session", "", true, 0, 0},
+	}
+	for _, tt := range tests {
+		unused, usage, err := tracer.IsSourceUnused(tt.channel, tt.key)
+		if err != nil {
+			t.Errorf("IsSourceUnused(%q, %q): %v", tt.channel, tt.key, err)
+			continue
+		}
+		if unused != tt.unused || len(usage.Reads) != tt.reads || len(usage.DynamicReads) != tt.dynamic {
+			t.Errorf("IsSourceUnused(%q, %q) = %v with %d reads, %d dynamic reads; want %v, %d, %d",
+				tt.channel, tt.key, unused, len(usage.Reads), len(usage.DynamicReads), tt.unused, tt.reads, tt.dynamic)
+		}
+		if usage.FilesSearched != 2 {
+			t.Errorf("IsSourceUnused(%q, %q) searched %d files, want 2", tt.channel, tt.key, usage.FilesSearched)
+		}
+	}
+
+	if unused, _, err := tracer.IsSourceUnused("gett", "id"); err == nil || unused {
+		t.Errorf("IsSourceUnused of an unknown channel = %v, %v; want an error", unused, err)
+	}
+}
@@ -0,0 +1,256 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// validatorExtractor is implemented by analyzers that recognize functions
+// rejecting invalid input by throwing or exiting (currently PHP)
+type validatorExtractor interface {
+	ExtractValidators(root *sitter.Node, source []byte, filePath string) []*types.Validator
+}
+
+// ValidatorRule declares a validator function, or disables a detected one
+type ValidatorRule struct {
+	Function string   `json:"function"`
+	Param    int      `json:"param,omitempty"`    // Index of the validated parameter
+	Type     string   `json:"type,omitempty"`     // int, float, bool, enum or pattern
+	Values   []string `json:"values,omitempty"`   // Allowed literals (enum)
+	Pattern  string   `json:"pattern,omitempty"`  // Regex the value must match (pattern)
+	Disabled bool     `json:"disabled,omitempty"` // Do not treat the function as a validator
+}
+
+// validate checks that the rule names a function and a known constraint
+func (r ValidatorRule) validate() error {
+	if r.Function == "" {
+		return fmt.Errorf("function is required")
+	}
+	if r.Param < 0 {
+		return fmt.Errorf("param must not be negative")
+	}
+	if r.Disabled {
+		return nil
+	}
+	switch types.ParamType(r.Type) {
+	case types.ParamInt, types.ParamFloat, types.ParamBool:
+	case types.ParamEnum:
+		if len(r.Values) == 0 {
+			return fmt.Errorf("enum validator %s needs values", r.Function)
+		}
+	case types.ParamPattern:
+		if r.Pattern == "" {
+			return fmt.Errorf("pattern validator %s needs a pattern", r.Function)
+		}
+	default:
+		return fmt.Errorf("unknown validator type %q", r.Type)
+	}
+	return nil
+}
+
+// Validators returns the validator functions of the last scan, sorted by name
+func (t *Tracer) Validators() []*types.Validator {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var validators []*types.Validator
+	for _, vs := range t.validators {
+		validators = append(validators, vs...)
+	}
+	sort.Slice(validators, func(i, j int) bool {
+		if validators[i].FunctionName != validators[j].FunctionName {
+			return validators[i].FunctionName < validators[j].FunctionName
+		}
+		return validators[i].ParamIndex < validators[j].ParamIndex
+	})
+	return validators
+}
+
+// promoteValidators registers the validators found while parsing, then
+// applies the declarations of the rules file, which override detection
+func (t *Tracer) promoteValidators() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.validators = make(map[string][]*types.Validator)
+	for _, fileInfo := range t.files {
+		for _, v := range fileInfo.Validators {
+			name := strings.ToLower(v.FunctionName)
+			t.validators[name] = append(t.validators[name], v)
+		}
+	}
+	if t.rules == nil {
+		return
+	}
+	for _, rule := range t.rules.Validators {
+		name := strings.ToLower(rule.Function)
+		if rule.Disabled {
+			delete(t.validators, name)
+			continue
+		}
+		var kept []*types.Validator
+		for _, v := range t.validators[name] {
+			if v.ParamIndex != rule.Param {
+				kept = append(kept, v)
+			}
+		}
+		t.validators[name] = append(kept, &types.Validator{
+			FunctionName: rule.Function,
+			ParamIndex:   rule.Param,
+			Constraint:   types.ParamConstraint{Type: types.ParamType(rule.Type), Values: rule.Values, Pattern: rule.Pattern},
+			Declared:     true,
+		})
+	}
+}
+
+// callDominanceChecker is implemented by analyzers that tell whether a call
+// runs whenever an earlier position of the same function does (currently PHP)
+type callDominanceChecker interface {
+	CallDominates(root *sitter.Node, from, call sitter.Point) bool
+}
+
+// applyValidators constrains unconstrained sources passed to a validator,
+// directly or through the variable they are assigned to, later in the same
+// function, before that variable is reassigned and on every path from the
+// source (see callDominanceChecker)
+func (t *Tracer) applyValidators(sources []*types.FlowNode) {
+	t.mu.RLock()
+	validators := t.validators
+	byFile := make(map[*FileInfo][]*types.FlowNode)
+	var files []*FileInfo
+	if len(validators) > 0 {
+		for _, src := range sources {
+			if src.Constraint != nil && src.Constraint.Type != types.ParamFreeString {
+				continue
+			}
+			fileInfo := t.files[src.FilePath]
+			if fileInfo == nil {
+				continue
+			}
+			if _, ok := byFile[fileInfo]; !ok {
+				files = append(files, fileInfo)
+			}
+			byFile[fileInfo] = append(byFile[fileInfo], src)
+		}
+	}
+	t.mu.RUnlock()
+
+	parsers := make(map[string]*sitter.Parser)
+	defer func() {
+		for _, p := range parsers {
+			p.Close()
+		}
+	}()
+
+	for _, fileInfo := range files {
+		var tree *sitter.Tree
+		var checker callDominanceChecker
+		parsed := false
+		dominates := func(src *types.FlowNode, call *types.CallSite) bool {
+			if !parsed {
+				parsed = true
+				tree, checker = t.parseForDominance(fileInfo, parsers)
+			}
+			if tree == nil {
+				return false
+			}
+			from := sitter.Point{Row: uint32(src.Line - 1), Column: uint32(src.Column)}
+			at := sitter.Point{Row: uint32(call.Line - 1), Column: uint32(call.Column)}
+			return checker.CallDominates(tree.RootNode(), from, at)
+		}
+
+		for _, src := range byFile[fileInfo] {
+			var target, scope string
+			for _, assign := range fileInfo.Assignments {
+				if assign.Line == src.Line && assign.TargetType == "variable" && strings.Contains(assign.Source, src.Snippet) {
+					target, scope = assign.Target, assign.Scope
+					break
+				}
+			}
+			end := 0 // Line the target is reassigned on (0 = never)
+			for _, assign := range fileInfo.Assignments {
+				if target != "" && assign.Target == target && assign.Scope == scope && assign.Line > src.Line && (end == 0 || assign.Line < end) {
+					end = assign.Line
+				}
+			}
+
+			if c := validatingCall(validators, fileInfo.Calls, src, target, end, dominates); c != nil {
+				src.Constraint = c
+			}
+		}
+		if tree != nil {
+			tree.Close()
+		}
+	}
+}
+
+// parseForDominance parses a file again for its analyzer's
+// callDominanceChecker; the tree is nil when either is unavailable
+func (t *Tracer) parseForDominance(fileInfo *FileInfo, parsers map[string]*sitter.Parser) (*sitter.Tree, callDominanceChecker) {
+	checker, ok := t.languageAnalyzer(fileInfo.Path, fileInfo.Language).(callDominanceChecker)
+	if !ok {
+		return nil, nil
+	}
+	content, err := t.readFile(fileInfo.Path)
+	if err != nil {
+		return nil, nil
+	}
+	parser, ok := parsers[fileInfo.Language]
+	if !ok {
+		if parser = createParser(fileInfo.Language); parser == nil {
+			return nil, nil
+		}
+		parsers[fileInfo.Language] = parser
+	}
+	t.budget.CountParse()
+	tree, err := parser.ParseCtx(context.Background(), nil, content)
+	if err != nil {
+		return nil, nil
+	}
+	return tree, checker
+}
+
+// validatingCall returns the constraint of the first validator call checking
+// src itself or its target variable that dominates src, or nil
+func validatingCall(validators map[string][]*types.Validator, calls []*types.CallSite, src *types.FlowNode, target string, end int, dominates func(*types.FlowNode, *types.CallSite) bool) *types.ParamConstraint {
+	var candidates []*types.CallSite
+	constraints := make(map[*types.CallSite]types.ParamConstraint)
+	for _, call := range calls {
+		if call.Line < src.Line || (end > 0 && call.Line >= end) {
+			continue
+		}
+		for _, v := range validators[strings.ToLower(call.FunctionName)] {
+			if v.ParamIndex >= len(call.Arguments) {
+				continue
+			}
+			arg := strings.TrimSpace(call.Arguments[v.ParamIndex].Value)
+			if arg == src.Snippet || (target != "" && arg == target) {
+				candidates = append(candidates, call)
+				constraints[call] = v.Constraint
+				break
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Line != candidates[j].Line {
+			return candidates[i].Line < candidates[j].Line
+		}
+		return candidates[i].Column < candidates[j].Column
+	})
+
+	for _, call := range candidates {
+		if !dominates(src, call) {
+			continue
+		}
+		constraint := constraints[call]
+		constraint.Evidence = fmt.Sprintf("%s(%s)", call.FunctionName, joinArgs(call))
+		constraint.Line = call.Line
+		return &constraint
+	}
+	return nil
+}
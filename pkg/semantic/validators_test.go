@@ -0,0 +1,183 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+func TestValidators(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib.php": `<?php
+function require_int($x) {
+    if (!ctype_digit($x)) {
+        throw new InvalidArgumentException('not a number');
+    }
+}
+function require_sort($s) {
+    if (!in_array($s, ['asc', 'desc'])) die('bad sort');
+}
+function log_value($v) {
+    if (!is_numeric($v)) {
+        error_log($v);
+    }
+}
+`,
+		"index.php": `<?php
+$id = $_GET['id'];
+require_int($id);
+$sort = $_GET['sort'];
+$sort = trim($sort);
+require_sort($sort);
+$n = $_GET['n'];
+log_value($n);
+require_int($_POST['page']);
+$slug = $_GET['slug'];
+check_slug($slug);
+`,
+	}
+	for name, code := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := DefaultConfig()
+	config.Rules = &Rules{Validators: []ValidatorRule{
+		{Function: "check_slug", Type: "pattern", Pattern: "^[a-z-]+$"},
+	}}
+	tracer := New(config)
+	result, err := tracer.TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]types.ParamType)
+	for _, v := range tracer.Validators() {
+		names[v.FunctionName] = v.Constraint.Type
+	}
+	wantValidators := map[string]types.ParamType{"require_int": types.ParamInt, "require_sort": types.ParamEnum, "check_slug": types.ParamPattern}
+	if len(names) != len(wantValidators) {
+		t.Errorf("validators = %v, want %v", names, wantValidators)
+	}
+	for name, want := range wantValidators {
+		if names[name] != want {
+			t.Errorf("validator %s type = %q, want %q", name, names[name], want)
+		}
+	}
+
+	tests := []struct {
+		snippet  string
+		want     types.ParamType
+		evidence string
+	}{
+		{"$_GET['id']", types.ParamInt, "require_int($id)"},
+		{"$_GET['sort']", types.ParamFreeString, ""}, // Reassigned before validation
+		{"$_GET['n']", types.ParamFreeString, ""},    // log_value does not reject
+		{"$_POST['page']", types.ParamInt, "require_int($_POST['page'])"},
+		{"$_GET['slug']", types.ParamPattern, "check_slug($slug)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.snippet, func(t *testing.T) {
+			for _, src := range result.Sources {
+				if src.Snippet != tt.snippet {
+					continue
+				}
+				if src.Constraint == nil || src.Constraint.Type != tt.want || src.Constraint.Evidence != tt.evidence {
+					t.Errorf("constraint = %+v, want %s from %q", src.Constraint, tt.want, tt.evidence)
+				}
+				return
+			}
+			t.Fatalf("source %s not found", tt.snippet)
+		})
+	}
+
+	config.Rules = &Rules{Validators: []ValidatorRule{{Function: "require_int", Disabled: true}}}
+	tracer = New(config)
+	if _, err := tracer.TraceDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range tracer.Validators() {
+		if v.FunctionName == "require_int" {
+			t.Error("disabled validator require_int still registered")
+		}
+	}
+}
+
+func TestValidatorGuards(t *testing.T) {
+	tests := []struct {
+		name  string
+		guard string
+		want  bool
+	}{
+		{"negated check", "!ctype_digit($x)", true},
+		{"boolean check is false", "ctype_digit($x) === false", true},
+		{"boolean check equals false", "ctype_digit($x) == false", true},
+		{"false on the left", "false === ctype_digit($x)", true},
+		{"boolean check is 0", "ctype_digit($x) === 0", false},
+		{"negated preg_match", "!preg_match('/^[0-9]+$/', $x)", true},
+		{"preg_match is 0", "preg_match('/^[0-9]+$/', $x) === 0", true},
+		{"preg_match equals 0", "preg_match('/^[0-9]+$/', $x) == 0", true},
+		{"preg_match is false", "preg_match('/^[0-9]+$/', $x) === false", false},
+		{"passing check", "ctype_digit($x)", false},
+		{"comparison with another value", "ctype_digit($x) === true", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "lib.php", "<?php\nfunction check($x) {\n    if ("+tt.guard+") {\n        throw new Exception('invalid');\n    }\n}\n")
+			tracer := New(nil)
+			if _, err := tracer.TraceDirectory(dir); err != nil {
+				t.Fatal(err)
+			}
+			if got := len(tracer.Validators()) == 1; got != tt.want {
+				t.Errorf("validator found = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatorDominance(t *testing.T) {
+	// The source is always on line 3
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"same function", "function a() {\n    $q = $_GET['q'];\n    check_slug($q);\n}\n", true},
+		{"direct argument", "function a() {\n    check_slug($_GET['q']);\n}\n", true},
+		{"check in the condition", "function a() {\n    $q = $_GET['q'];\n    if (check_slug($q)) {\n        echo 1;\n    }\n}\n", true},
+		{"other function", "function a() {\n    $q = $_GET['q'];\n}\nfunction b() {\n    if (rand()) { check_slug($_GET['q']); }\n    check_slug($q);\n}\n", false},
+		{"conditional check", "function a() {\n    $q = $_GET['q'];\n    if (rand()) {\n        check_slug($q);\n    }\n}\n", false},
+		{"check in a loop", "function a() {\n    $q = $_GET['q'];\n    foreach ([1] as $i) {\n        check_slug($q);\n    }\n}\n", false},
+		{"short-circuit operand", "function a() {\n    $q = $_GET['q'];\n    rand() && check_slug($q);\n}\n", false},
+		{"check in a closure", "function a() {\n    $q = $_GET['q'];\n    $f = function () use ($q) { check_slug($q); };\n}\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "index.php", "<?php\n"+tt.code)
+			config := DefaultConfig()
+			config.Rules = &Rules{Validators: []ValidatorRule{
+				{Function: "check_slug", Type: "pattern", Pattern: "^[a-z-]+$"},
+			}}
+			result, err := New(config).TraceDirectory(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, src := range result.Sources {
+				if src.Line != 3 {
+					continue
+				}
+				if got := src.Constraint != nil && src.Constraint.Type == types.ParamPattern; got != tt.want {
+					t.Errorf("constrained = %v, want %v (%+v)", got, tt.want, src.Constraint)
+				}
+				return
+			}
+			t.Fatal("source at line 3 not found")
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package semantic
+
+import (
+	"fmt"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+)
+
+// Analysis warning types (see pkg/semantic/types)
+type (
+	AnalysisWarning = types.AnalysisWarning
+	WarningCategory = types.WarningCategory
+)
+
+// defaultMaxWarnings is the number of warnings kept when Config.MaxWarnings is 0
+const defaultMaxWarnings = 1000
+
+// newWarningCollector creates the warning collector for a configuration
+func newWarningCollector(config *Config) *types.WarningCollector {
+	maxWarnings := config.MaxWarnings
+	if maxWarnings == 0 {
+		maxWarnings = defaultMaxWarnings
+	}
+	return types.NewWarningCollector(maxWarnings, config.SuppressWarnings)
+}
+
+// warn records an analysis gap
+func (t *Tracer) warn(category types.WarningCategory, filePath string, line int, expression, message string) {
+	t.warnings.Add(types.AnalysisWarning{
+		Category:   category,
+		Message:    message,
+		FilePath:   filePath,
+		Line:       line,
+		Expression: expression,
+	})
+}
+
+// warnDepthCutoff records that tracing stopped at Config.MaxDepth
+func (t *Tracer) warnDepthCutoff(filePath string, line int, expression string) {
+	t.warn(types.WarningDepthCutoff, filePath, line, expression,
+		fmt.Sprintf("tracing stopped at max depth %d", t.config.MaxDepth))
+}
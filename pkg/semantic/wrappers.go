@@ -0,0 +1,250 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/analyzer"
+	"github.com/hatlesswizard/inputtracer/pkg/semantic/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// sourceWrapperExtractor is implemented by analyzers that can recognize thin
+// functions wrapping a superglobal access (currently PHP)
+type sourceWrapperExtractor interface {
+	ExtractSourceWrappers(root *sitter.Node, source []byte, filePath string) []*types.SourceWrapper
+	FindWrapperSources(root *sitter.Node, source []byte, wrappers map[string]*types.SourceWrapper) []*types.FlowNode
+}
+
+// calledNameFinder is implemented by analyzers that can list the functions
+// and methods a file calls (currently PHP)
+type calledNameFinder interface {
+	FindCalledNames(root *sitter.Node, source []byte) map[string]bool
+}
+
+// wrapperCallPattern matches the function name of a call expression
+var wrapperCallPattern = regexp.MustCompile(`^\\?([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+
+// SourceWrappers returns the functions promoted to derived sources, sorted by name
+func (t *Tracer) SourceWrappers() []*types.SourceWrapper {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	wrappers := make([]*types.SourceWrapper, 0, len(t.sourceWrappers))
+	for _, w := range t.sourceWrappers {
+		wrappers = append(wrappers, w)
+	}
+	sort.Slice(wrappers, func(i, j int) bool { return wrappers[i].FunctionName < wrappers[j].FunctionName })
+	return wrappers
+}
+
+// promoteSourceWrappers registers the wrapper functions found while parsing as
+// derived sources and adds a source node for each of their call sites.
+// Call sites can live in any file, so this runs after all files are parsed.
+func (t *Tracer) promoteSourceWrappers() {
+	t.mu.Lock()
+	t.sourceWrappers = make(map[string]*types.SourceWrapper)
+	for _, fileInfo := range t.files {
+		for _, w := range fileInfo.SourceWrappers {
+			if t.trustedFunction(w.FunctionName) {
+				continue
+			}
+			t.sourceWrappers[strings.ToLower(w.FunctionName)] = w
+		}
+	}
+	wrappers := t.sourceWrappers
+	t.mu.Unlock()
+
+	if len(wrappers) == 0 {
+		return
+	}
+	if t.config.Verbose {
+		fmt.Printf("  Promoted %d source wrapper function(s)\n", len(wrappers))
+	}
+
+	t.addDerivedSources(
+		func(fileInfo *FileInfo) bool { return callsWrapper(fileInfo, wrappers) },
+		func(langAnalyzer analyzer.LanguageAnalyzer, root *sitter.Node, content []byte) []*types.FlowNode {
+			if extractor, ok := langAnalyzer.(sourceWrapperExtractor); ok {
+				return extractor.FindWrapperSources(root, content, wrappers)
+			}
+			return nil
+		},
+		func(fileInfo *FileInfo) { markWrapperTaint(fileInfo, wrappers) },
+	)
+}
+
+// derivedSourceFinder returns the derived sources of a reparsed file
+type derivedSourceFinder func(langAnalyzer analyzer.LanguageAnalyzer, root *sitter.Node, content []byte) []*types.FlowNode
+
+// addDerivedSources reparses the files accepted by calls (from the names
+// recorded while parsing), adds the sources find returns to them and lets
+// markTaint update their cached assignments and calls. Generated files are
+// only considered when they are analyzed fully.
+func (t *Tracer) addDerivedSources(calls func(fileInfo *FileInfo) bool, find derivedSourceFinder, markTaint func(fileInfo *FileInfo)) {
+	t.mu.RLock()
+	var candidates []*FileInfo
+	for _, fileInfo := range t.files {
+		if fileInfo.Error != nil || !calls(fileInfo) {
+			continue
+		}
+		if fileInfo.Generated == GeneratedNone || t.analyzesGeneratedFully() {
+			candidates = append(candidates, fileInfo)
+		}
+	}
+	t.mu.RUnlock()
+
+	parsers := make(map[string]*sitter.Parser)
+	defer func() {
+		for _, p := range parsers {
+			p.Close()
+		}
+	}()
+
+	for _, fileInfo := range candidates {
+		langAnalyzer := t.languageAnalyzer(fileInfo.Path, fileInfo.Language)
+		if langAnalyzer == nil {
+			continue
+		}
+
+		content, err := t.readFile(fileInfo.Path)
+		if err != nil {
+			continue
+		}
+
+		parser, ok := parsers[fileInfo.Language]
+		if !ok {
+			if parser = createParser(fileInfo.Language); parser == nil {
+				continue
+			}
+			parsers[fileInfo.Language] = parser
+		}
+		t.budget.CountParse()
+		tree, err := parser.ParseCtx(context.Background(), nil, content)
+		if err != nil {
+			continue
+		}
+		root := tree.RootNode()
+
+		found := find(langAnalyzer, root, content)
+		if inferrer, ok := langAnalyzer.(sourceConstraintInferrer); ok && len(found) > 0 {
+			inferrer.InferSourceConstraints(root, content, found)
+		}
+		for _, src := range found {
+			src.FilePath = fileInfo.Path
+			src.ID = types.NodeID(fileInfo.Path, src.Line, src.Column, string(src.Type), src.Snippet)
+		}
+		if t.generatedFileMode() == GeneratedFlag && fileInfo.Generated != GeneratedNone {
+			flagGeneratedSources(fileInfo.Generated, found)
+		}
+		deadSources := flagDeadSources(fileInfo.DeadRanges, found)
+
+		// Files without direct sources skipped assignment/call extraction during parsing
+		var assignments []*types.Assignment
+		var calls []*types.CallSite
+		if len(found) > 0 && fileInfo.Assignments == nil {
+			assignments, _ = langAnalyzer.ExtractAssignments(root, content, "")
+			calls, _ = langAnalyzer.ExtractCalls(root, content, "")
+			assignments = pruneDeadAssignments(fileInfo.DeadRanges, assignments)
+			calls = pruneDeadCalls(fileInfo.DeadRanges, calls)
+		}
+		tree.Close()
+
+		if len(found) == 0 {
+			continue
+		}
+
+		t.mu.Lock()
+		fileInfo.Sources = append(fileInfo.Sources, found...)
+		if assignments != nil {
+			fileInfo.Assignments = assignments
+			fileInfo.Calls = calls
+		}
+		markTaint(fileInfo)
+		t.stats.SourcesDead += deadSources
+		if stats := t.stats.ByLanguage[fileInfo.Language]; stats != nil {
+			stats.Sources += len(found)
+		}
+		t.mu.Unlock()
+	}
+}
+
+// callsWrapper reports whether a file calls any wrapper
+func callsWrapper(fileInfo *FileInfo, wrappers map[string]*types.SourceWrapper) bool {
+	for name := range wrappers {
+		if fileInfo.CalledNames[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// markWrapperTaint marks cached assignments and call arguments that contain a
+// wrapper call as tainted, as the analyzer does for built-in input functions
+func markWrapperTaint(fileInfo *FileInfo, wrappers map[string]*types.SourceWrapper) {
+	for _, assign := range fileInfo.Assignments {
+		if assign.IsTainted {
+			continue
+		}
+		if w := findWrapperCall(assign.Source, wrappers); w != nil {
+			assign.IsTainted = true
+			assign.TaintSource = w.FunctionName + "()"
+		}
+	}
+	for _, call := range fileInfo.Calls {
+		for i := range call.Arguments {
+			arg := &call.Arguments[i]
+			if arg.IsTainted {
+				continue
+			}
+			if w := findWrapperCall(arg.Value, wrappers); w != nil {
+				arg.IsTainted = true
+				arg.TaintSource = w.FunctionName + "()"
+				call.HasTaintedArgs = true
+				call.TaintedArgIndices = append(call.TaintedArgIndices, i)
+			}
+		}
+	}
+}
+
+// findWrapperCall returns the first wrapper called anywhere in expr
+func findWrapperCall(expr string, wrappers map[string]*types.SourceWrapper) *types.SourceWrapper {
+	lower := strings.ToLower(expr)
+	for name, w := range wrappers {
+		if idx := strings.Index(lower, name+"("); idx >= 0 {
+			if idx == 0 || !isIdentByte(lower[idx-1]) {
+				return w
+			}
+		}
+	}
+	return nil
+}
+
+// isIdentByte reports whether c can be part of an identifier (or a PHP variable/member prefix)
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c == '>' || c == ':' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// identifyWrapperSource recognizes a direct call to a source wrapper in a backward trace
+func (t *Tracer) identifyWrapperSource(expr string, filePath string, line int) *types.SourceInfo {
+	m := wrapperCallPattern.FindStringSubmatch(expr)
+	if m == nil {
+		return nil
+	}
+	t.mu.RLock()
+	w, ok := t.sourceWrappers[strings.ToLower(m[1])]
+	t.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return &types.SourceInfo{
+		Type:       w.SourceType,
+		Expression: expr,
+		FilePath:   filePath,
+		Line:       line,
+	}
+}
@@ -0,0 +1,68 @@
+package semantic
+
+import (
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSourceWrappers(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "lib.php", `<?php
+function fetch_arg($key) {
+    return $_GET[$key];
+}
+`)
+	writeFile(t, dir, "page.php", `<?php
+$id = fetch_arg('id');
+echo \FETCH_ARG('name');
+`)
+	writeFile(t, dir, "notes.php", `<?php
+// fetch_arg('id') reads the query string
+$label = 'fetch_arg';
+`)
+	writeFile(t, dir, "form.php", `<?php
+$form->fetch_arg('x');
+`)
+
+	tracer := New(nil)
+	result, err := tracer.TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wrappers := tracer.SourceWrappers(); len(wrappers) != 1 || wrappers[0].FunctionName != "fetch_arg" {
+		t.Fatalf("wrappers = %+v, want fetch_arg()", wrappers)
+	}
+
+	derived := make(map[string][]string)
+	for _, src := range result.Sources {
+		if src.Name != "" && src.Name != "$_GET" {
+			derived[filepath.Base(src.FilePath)] = append(derived[filepath.Base(src.FilePath)], src.SourceKey)
+		}
+	}
+	tests := []struct {
+		file string
+		keys []string
+	}{
+		{"page.php", []string{"id", "name"}},
+		{"notes.php", nil}, // Mentioned only in a comment and a string
+		{"form.php", nil},  // A method of the same name
+		{"lib.php", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			keys := derived[tt.file]
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("wrapper sources = %v, want %v", keys, tt.keys)
+			}
+		})
+	}
+
+	// Only the files calling fetch_arg by name are parsed again: page.php, and
+	// form.php for its method of the same name
+	if got, want := result.Budget.Parses, int64(4+2); got != want {
+		t.Errorf("parses = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package semantic
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestYieldHook(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.php", `<?php
+$id = $_GET['id'];
+$sql = "SELECT " . $id;
+mysql_query($sql);
+`)
+	writeFile(t, dir, "b.php", `<?php
+echo $_POST['name'];
+`)
+
+	var yields int64
+	config := DefaultConfig()
+	config.YieldEvery = 1
+	config.YieldHook = func() { atomic.AddInt64(&yields, 1) }
+	result, err := New(config).TraceDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two parsed files plus at least one flow tracing step
+	if n := atomic.LoadInt64(&yields); n <= 2 {
+		t.Errorf("yields = %d, want more than 2", n)
+	}
+	if len(result.Sources) == 0 {
+		t.Error("expected sources with yielding enabled")
+	}
+
+	yields = 0
+	config.YieldEvery = 0
+	if _, err := New(config).TraceDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	if yields != 0 {
+		t.Errorf("yields = %d with YieldEvery 0, want none", yields)
+	}
+}
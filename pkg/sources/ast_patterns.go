@@ -380,6 +380,52 @@ var LanguageASTNodeTypes = map[string]ASTNodeTypes{
 			"identifier",
 		},
 	},
+	"kotlin": {
+		FunctionTypes: []string{
+			"function_declaration",
+			"anonymous_function",
+			"lambda_literal",
+		},
+		ScopeTypes: []string{
+			"function_declaration",
+			"class_declaration",
+			"object_declaration",
+			"source_file",
+		},
+		AssignmentTypes: []string{
+			"assignment",
+			"property_declaration",
+		},
+		CallTypes: []string{
+			"call_expression",
+		},
+		IdentifierTypes: []string{
+			"simple_identifier",
+		},
+	},
+	"swift": {
+		FunctionTypes: []string{
+			"function_declaration",
+			"init_declaration",
+			"lambda_literal",
+		},
+		ScopeTypes: []string{
+			"function_declaration",
+			"class_declaration",
+			"protocol_declaration",
+			"source_file",
+		},
+		AssignmentTypes: []string{
+			"assignment",
+			"property_declaration",
+		},
+		CallTypes: []string{
+			"call_expression",
+		},
+		IdentifierTypes: []string{
+			"simple_identifier",
+		},
+	},
 }
 
 // IsFunctionNode checks if a node type represents a function definition
@@ -29,6 +29,10 @@ type FrameworkPattern struct {
 
 	// Tags for categorization
 	Tags []string `json:"tags,omitempty"`
+
+	// Framework versions the pattern applies to, e.g. ">=6.3" or "<6" (see
+	// VersionSatisfies); empty for all versions
+	Versions string `json:"versions,omitempty"`
 }
 
 // FrameworkPatternRegistry manages framework patterns for a language
@@ -67,6 +71,11 @@ func (r *FrameworkPatternRegistry) Register(pattern *FrameworkPattern) {
 	}
 }
 
+// Language returns the language of the registry
+func (r *FrameworkPatternRegistry) Language() string {
+	return r.language
+}
+
 // RegisterAll adds multiple patterns to the registry
 func (r *FrameworkPatternRegistry) RegisterAll(patterns []*FrameworkPattern) {
 	for _, p := range patterns {
@@ -0,0 +1,155 @@
+// Package common - keyword_matcher.go provides a matcher finding which of a
+// fixed set of keywords occur in a string in a single pass
+package common
+
+// KeywordMatcher finds occurrences of a fixed set of keywords (Aho-Corasick).
+// It replaces loops of strings.Contains over a pattern list in hot paths: a
+// string is scanned once whatever the number of keywords. Matching is case
+// sensitive, like strings.Contains. A built matcher is safe for concurrent use.
+type KeywordMatcher struct {
+	keywords []string
+	classes  [256]int32 // Byte -> transition column; 0 for bytes in no keyword
+	width    int32      // Number of columns
+	next     []int32    // state*width + column -> state
+	out      [][]int32  // Keywords ending at each state, ascending
+}
+
+// NewKeywordMatcher builds a matcher for keywords; empty keywords are ignored.
+// Keyword indexes reported by the matcher are positions in keywords.
+func NewKeywordMatcher(keywords []string) *KeywordMatcher {
+	m := &KeywordMatcher{keywords: keywords, width: 1}
+	for _, kw := range keywords {
+		for i := 0; i < len(kw); i++ {
+			if m.classes[kw[i]] == 0 {
+				m.classes[kw[i]] = m.width
+				m.width++
+			}
+		}
+	}
+
+	// Trie, with -1 for missing transitions
+	m.addState()
+	for k, kw := range keywords {
+		if kw == "" {
+			continue
+		}
+		state := int32(0)
+		for i := 0; i < len(kw); i++ {
+			slot := state*m.width + m.classes[kw[i]]
+			if m.next[slot] < 0 {
+				m.next[slot] = m.addState()
+			}
+			state = m.next[slot]
+		}
+		m.out[state] = append(m.out[state], int32(k))
+	}
+
+	// Breadth-first: fill missing transitions from the failure state, which
+	// is shallower and so already complete, and inherit its outputs
+	fail := make([]int32, len(m.out))
+	queue := make([]int32, 0, len(m.out))
+	for c := int32(0); c < m.width; c++ {
+		if s := m.next[c]; s > 0 {
+			queue = append(queue, s)
+		} else {
+			m.next[c] = 0
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		m.out[state] = mergeKeywords(m.out[state], m.out[fail[state]])
+		for c := int32(0); c < m.width; c++ {
+			slot := state*m.width + c
+			fallback := m.next[fail[state]*m.width+c]
+			if s := m.next[slot]; s >= 0 {
+				fail[s] = fallback
+				queue = append(queue, s)
+			} else {
+				m.next[slot] = fallback
+			}
+		}
+	}
+	return m
+}
+
+// addState appends a state without transitions and returns it
+func (m *KeywordMatcher) addState() int32 {
+	for c := int32(0); c < m.width; c++ {
+		m.next = append(m.next, -1)
+	}
+	m.out = append(m.out, nil)
+	return int32(len(m.out) - 1)
+}
+
+// mergeKeywords merges two ascending keyword lists
+func mergeKeywords(a, b []int32) []int32 {
+	if len(b) == 0 {
+		return a
+	}
+	merged := make([]int32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i] < b[j]):
+			merged = append(merged, a[i])
+			i++
+		case i == len(a) || b[j] < a[i]:
+			merged = append(merged, b[j])
+			j++
+		default:
+			merged = append(merged, a[i])
+			i++
+			j++
+		}
+	}
+	return merged
+}
+
+// Keyword returns the keyword at an index reported by the matcher
+func (m *KeywordMatcher) Keyword(index int) string {
+	return m.keywords[index]
+}
+
+// Contains reports whether any keyword occurs in s
+func (m *KeywordMatcher) Contains(s string) bool {
+	state := int32(0)
+	for i := 0; i < len(s); i++ {
+		state = m.next[state*m.width+m.classes[s[i]]]
+		if len(m.out[state]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// First returns the lowest index of the keywords occurring in s, or -1, so
+// that keyword order can express priority
+func (m *KeywordMatcher) First(s string) int {
+	first := int32(-1)
+	state := int32(0)
+	for i := 0; i < len(s); i++ {
+		state = m.next[state*m.width+m.classes[s[i]]]
+		if out := m.out[state]; len(out) > 0 && (first < 0 || out[0] < first) {
+			first = out[0]
+			if first == 0 {
+				break
+			}
+		}
+	}
+	return int(first)
+}
+
+// Find calls fn with the index of each keyword occurrence in s, in order of
+// where the occurrences end, until fn returns false
+func (m *KeywordMatcher) Find(s string, fn func(index int) bool) {
+	state := int32(0)
+	for i := 0; i < len(s); i++ {
+		state = m.next[state*m.width+m.classes[s[i]]]
+		for _, k := range m.out[state] {
+			if !fn(int(k)) {
+				return
+			}
+		}
+	}
+}
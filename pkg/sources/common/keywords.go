@@ -100,6 +100,16 @@ var LanguageKeywords = map[string][]string{
 		"move", "ref", "box", "dyn", "where", "unsafe",
 		"extern", "mod", "pub", "priv", "loop", "match",
 	},
+	"kotlin": {
+		"true", "false", "null", "this", "super", "val", "var",
+		"fun", "object", "companion", "when", "is", "in", "as",
+		"override", "open", "internal", "lateinit", "suspend", "it",
+	},
+	"swift": {
+		"true", "false", "nil", "self", "Self", "super", "let", "var",
+		"func", "guard", "defer", "inout", "where", "is", "as",
+		"override", "fileprivate", "internal", "mutating", "throws", "try", "await",
+	},
 }
 
 // IsKeyword checks if a word is a universal keyword
@@ -2,6 +2,8 @@
 // These should be the ONLY source type definitions in the entire codebase
 package common
 
+import "strings"
+
 // SourceType represents the semantic type of an input source
 type SourceType string
 
@@ -43,3 +45,75 @@ func IsValidSourceType(s string) bool {
 	}
 	return false
 }
+
+// SourceTypeAliases maps short input names (as used in rules files) to source types
+var SourceTypeAliases = map[string]SourceType{
+	"argv":    SourceCLIArg,
+	"args":    SourceCLIArg,
+	"cli":     SourceCLIArg,
+	"env":     SourceEnvVar,
+	"stdin":   SourceStdin,
+	"get":     SourceHTTPGet,
+	"query":   SourceHTTPGet,
+	"post":    SourceHTTPPost,
+	"body":    SourceHTTPBody,
+	"json":    SourceHTTPJSON,
+	"header":  SourceHTTPHeader,
+	"headers": SourceHTTPHeader,
+	"cookie":  SourceHTTPCookie,
+	"cookies": SourceHTTPCookie,
+	"path":    SourceHTTPPath,
+	"upload":  SourceHTTPFile,
+	"files":   SourceHTTPFile,
+	"request": SourceHTTPRequest,
+	"db":      SourceDatabase,
+	"socket":  SourceNetwork,
+}
+
+// SourceTypesOverlap reports whether reads of two channels can return the
+// same input: identical channels, or the combined request ($_REQUEST) and
+// the query string, form data or cookies it merges
+func SourceTypesOverlap(a, b SourceType) bool {
+	if a == b {
+		return true
+	}
+	if b == SourceHTTPRequest {
+		a, b = b, a
+	}
+	return a == SourceHTTPRequest && (b == SourceHTTPGet || b == SourceHTTPPost || b == SourceHTTPCookie)
+}
+
+// CanonicalSourceName returns the language-independent name of an input:
+// its channel and normalized key, e.g. "http_get:id" for both $_GET['id'] and
+// req.query.id. Sources without a key are named by their channel alone.
+func CanonicalSourceName(st SourceType, key string) string {
+	key = NormalizeSourceKey(st, key)
+	if key == "" {
+		return string(st)
+	}
+	return string(st) + ":" + key
+}
+
+// NormalizeSourceKey normalizes a source key for cross-language comparison.
+// Header names are case-insensitive and CGI-style names (HTTP_USER_AGENT)
+// become their wire form (user-agent); other keys are kept as written.
+func NormalizeSourceKey(st SourceType, key string) string {
+	key = strings.TrimSpace(key)
+	if st != SourceHTTPHeader {
+		return key
+	}
+	if strings.HasPrefix(key, "HTTP_") {
+		key = strings.ReplaceAll(strings.TrimPrefix(key, "HTTP_"), "_", "-")
+	}
+	return strings.ToLower(key)
+}
+
+// ParseSourceType resolves a source type name or alias, case-insensitively
+func ParseSourceType(name string) (SourceType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if IsValidSourceType(name) {
+		return SourceType(name), true
+	}
+	st, ok := SourceTypeAliases[name]
+	return st, ok
+}
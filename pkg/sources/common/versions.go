@@ -0,0 +1,103 @@
+// Package common - versions.go compares framework versions against the
+// version constraints of framework patterns
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+// VersionSatisfies reports whether a version (e.g. "10.48.2" or "v6.3.0")
+// satisfies a constraint. A constraint is a list of comparisons that must
+// all hold, separated by spaces or commas (">=5.5 <9", ">=6.3"), with
+// alternatives separated by "||" ("<6 || >=8"). A bare version ("6" or
+// "6.x") matches that version line. An empty constraint matches every
+// version; a version that cannot be parsed matches every constraint, as
+// nothing is known about it.
+func VersionSatisfies(version, constraint string) bool {
+	constraint = strings.TrimSpace(constraint)
+	v, ok := parseVersion(version)
+	if constraint == "" || !ok {
+		return true
+	}
+	for _, alternative := range strings.Split(constraint, "||") {
+		if satisfiesAll(v, alternative) {
+			return true
+		}
+	}
+	return false
+}
+
+// satisfiesAll reports whether v satisfies every comparison of a constraint
+func satisfiesAll(v []int, constraint string) bool {
+	fields := strings.FieldsFunc(constraint, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(fields) == 0 {
+		return false
+	}
+	for _, field := range fields {
+		op := strings.TrimRight(field, "0123456789.xX*v")
+		bound, ok := parseVersion(field[len(op):])
+		if !ok {
+			return false
+		}
+		cmp := compareVersions(v, bound)
+		var holds bool
+		switch op {
+		case ">=":
+			holds = cmp >= 0
+		case ">":
+			holds = cmp > 0
+		case "<=":
+			holds = cmp <= 0
+		case "<":
+			holds = cmp < 0
+		case "", "=", "==":
+			holds = compareVersions(v[:min(len(v), len(bound))], bound) == 0
+		default:
+			return false
+		}
+		if !holds {
+			return false
+		}
+	}
+	return true
+}
+
+// parseVersion parses the numeric components of a version, stopping at the
+// first wildcard or suffix: "v6.3.0-RC1" is [6 3 0], "6.x" is [6]
+func parseVersion(s string) ([]int, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+ "); i >= 0 {
+		s = s[:i]
+	}
+	var parts []int
+	for _, part := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts, len(parts) > 0
+}
+
+// compareVersions compares two versions component by component, missing
+// components counting as 0
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
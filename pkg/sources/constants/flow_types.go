@@ -12,6 +12,7 @@ const (
 	NodeProperty FlowNodeType = "property" // Object property access
 	NodeParam    FlowNodeType = "param"    // Function parameter
 	NodeReturn   FlowNodeType = "return"   // Return value
+	NodeBarrier  FlowNodeType = "barrier"  // Declared trust boundary the flow crosses
 )
 
 // FlowEdgeType represents how data flows between nodes
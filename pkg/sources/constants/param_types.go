@@ -0,0 +1,13 @@
+package constants
+
+// ParamType is the effective type of an input parameter inferred from how it is used
+type ParamType string
+
+const (
+	ParamInt        ParamType = "int"         // Cast or validated as an integer
+	ParamFloat      ParamType = "float"       // Cast or validated as a float
+	ParamBool       ParamType = "bool"        // Cast or validated as a boolean
+	ParamEnum       ParamType = "enum"        // Compared against a closed set of literals
+	ParamPattern    ParamType = "pattern"     // Matched against a regular expression
+	ParamFreeString ParamType = "free_string" // No constraint observed
+)
@@ -28,6 +28,8 @@ var LanguageSkipDirs = map[string][]string{
 	"python":     {"__pycache__", ".venv", "venv", "env", ".tox", ".pytest_cache"},
 	"go":         {"vendor"},
 	"rust":       {"target"},
+	"kotlin":     {"build", ".gradle"},
+	"swift":      {".build", "Pods", "DerivedData"},
 	"java":       {"target", "build", "bin", "out"},
 	"c_sharp":    {"bin", "obj", "packages"},
 	"ruby":       {"vendor", ".bundle"},
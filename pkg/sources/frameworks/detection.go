@@ -3,8 +3,11 @@
 package frameworks
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 )
@@ -194,6 +197,26 @@ var RustFrameworkIndicators = []FrameworkIndicator{
 	},
 }
 
+// KotlinFrameworkIndicators contains file path indicators for Kotlin frameworks
+var KotlinFrameworkIndicators = []FrameworkIndicator{
+	{
+		Framework:   "ktor",
+		Language:    "kotlin",
+		Indicators:  []string{"src/main/resources/application.conf"},
+		Description: "Ktor framework",
+	},
+}
+
+// SwiftFrameworkIndicators contains file path indicators for Swift frameworks
+var SwiftFrameworkIndicators = []FrameworkIndicator{
+	{
+		Framework:   "vapor",
+		Language:    "swift",
+		Indicators:  []string{"Sources/App/configure.swift", "Sources/App/routes.swift"},
+		Description: "Vapor framework",
+	},
+}
+
 // CppFrameworkIndicators contains file path indicators for C++ frameworks
 var CppFrameworkIndicators = []FrameworkIndicator{
 	{
@@ -221,6 +244,8 @@ var AllFrameworkIndicators = func() []FrameworkIndicator {
 	all = append(all, GoFrameworkIndicators...)
 	all = append(all, CSharpFrameworkIndicators...)
 	all = append(all, RustFrameworkIndicators...)
+	all = append(all, KotlinFrameworkIndicators...)
+	all = append(all, SwiftFrameworkIndicators...)
 	all = append(all, CppFrameworkIndicators...)
 	return all
 }()
@@ -258,6 +283,10 @@ func getIndicatorsForLanguage(language string) []FrameworkIndicator {
 		return CSharpFrameworkIndicators
 	case "rust":
 		return RustFrameworkIndicators
+	case "kotlin":
+		return KotlinFrameworkIndicators
+	case "swift":
+		return SwiftFrameworkIndicators
 	case "cpp", "c++":
 		return CppFrameworkIndicators
 	default:
@@ -307,6 +336,181 @@ func GetFrameworksForLanguage(language string) []string {
 	return frameworks
 }
 
+// FrameworkManifestPackages maps framework identifiers to the package names that
+// declare them in composer.json (PHP) or package.json (JavaScript/TypeScript)
+var FrameworkManifestPackages = map[string][]string{
+	"laravel": {"laravel/framework", "laravel/lumen-framework"},
+	"symfony": {"symfony/framework-bundle", "symfony/http-kernel", "symfony/symfony"},
+	"express": {"express"},
+	"koa":     {"koa"},
+	"fastify": {"fastify"},
+	"hapi":    {"@hapi/hapi", "hapi"},
+	"nestjs":  {"@nestjs/core"},
+	"nextjs":  {"next"},
+	"nuxt":    {"nuxt"},
+}
+
+// ManifestLanguages maps manifest file names to the language they declare packages for
+var ManifestLanguages = map[string]string{
+	"composer.json": "php",
+	"package.json":  "javascript",
+}
+
+// DeclaredFramework is a framework declared as a dependency in a manifest file
+type DeclaredFramework struct {
+	Framework string // Framework identifier
+	Language  string // Language of the manifest
+	Package   string // Package name that matched
+	Version   string // Declared version constraint (e.g., "^10.0")
+	Manifest  string // Manifest file path relative to the codebase root
+}
+
+// DetectDeclaredFrameworks reads composer.json and package.json at the codebase root
+// and returns the known frameworks they declare, with their version constraints
+func DetectDeclaredFrameworks(codebasePath string) []DeclaredFramework {
+	var declared []DeclaredFramework
+
+	for _, manifest := range []string{"composer.json", "package.json"} {
+		data, err := os.ReadFile(filepath.Join(codebasePath, manifest))
+		if err != nil {
+			continue
+		}
+		var parsed struct {
+			Require         map[string]string `json:"require"`
+			RequireDev      map[string]string `json:"require-dev"`
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			continue
+		}
+
+		for _, framework := range sortedManifestFrameworks() {
+			for _, pkg := range FrameworkManifestPackages[framework] {
+				version := firstNonEmpty(parsed.Require[pkg], parsed.Dependencies[pkg],
+					parsed.RequireDev[pkg], parsed.DevDependencies[pkg])
+				if version == "" {
+					continue
+				}
+				declared = append(declared, DeclaredFramework{
+					Framework: framework,
+					Language:  ManifestLanguages[manifest],
+					Package:   pkg,
+					Version:   version,
+					Manifest:  manifest,
+				})
+				break
+			}
+		}
+	}
+
+	return declared
+}
+
+// InstalledFramework is a framework version pinned by a lock file
+type InstalledFramework struct {
+	Framework string // Framework identifier
+	Package   string // Package name that matched
+	Version   string // Installed version without a "v" prefix (e.g., "10.48.2")
+	LockFile  string // Lock file path relative to the codebase root
+}
+
+// DetectInstalledFrameworks reads composer.lock and package-lock.json at the
+// codebase root and returns the installed versions of the known frameworks.
+// Lock files list transitive dependencies too, so a Laravel application
+// reports the Symfony components its request class is built on.
+func DetectInstalledFrameworks(codebasePath string) []InstalledFramework {
+	var installed []InstalledFramework
+	add := func(lockFile string, versions map[string]string) {
+		for _, framework := range sortedManifestFrameworks() {
+			for _, pkg := range FrameworkManifestPackages[framework] {
+				if version := versions[pkg]; version != "" {
+					installed = append(installed, InstalledFramework{
+						Framework: framework,
+						Package:   pkg,
+						Version:   strings.TrimPrefix(version, "v"),
+						LockFile:  lockFile,
+					})
+					break
+				}
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(codebasePath, "composer.lock")); err == nil {
+		var lock struct {
+			Packages    []composerLockPackage `json:"packages"`
+			PackagesDev []composerLockPackage `json:"packages-dev"`
+		}
+		if json.Unmarshal(data, &lock) == nil {
+			versions := make(map[string]string)
+			for _, p := range append(lock.Packages, lock.PackagesDev...) {
+				versions[p.Name] = p.Version
+			}
+			add("composer.lock", versions)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(codebasePath, "package-lock.json")); err == nil {
+		// lockfileVersion 2 and 3 key packages by install path, 1 by name
+		var lock struct {
+			Packages     map[string]struct{ Version string } `json:"packages"`
+			Dependencies map[string]struct{ Version string } `json:"dependencies"`
+		}
+		if json.Unmarshal(data, &lock) == nil {
+			versions := make(map[string]string)
+			for name, p := range lock.Dependencies {
+				versions[name] = p.Version
+			}
+			for path, p := range lock.Packages {
+				if name, ok := strings.CutPrefix(path, "node_modules/"); ok && !strings.Contains(name, "/node_modules/") {
+					versions[name] = p.Version
+				}
+			}
+			add("package-lock.json", versions)
+		}
+	}
+
+	return installed
+}
+
+// composerLockPackage is a package entry of composer.lock
+type composerLockPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// FindFrameworkIndicators returns the indicator paths of a framework present in the codebase
+func FindFrameworkIndicators(codebasePath string, framework string) []string {
+	var found []string
+	for _, path := range GetFrameworkIndicators(framework) {
+		if _, err := os.Stat(filepath.Join(codebasePath, path)); err == nil {
+			found = append(found, path)
+		}
+	}
+	return found
+}
+
+// sortedManifestFrameworks returns the keys of FrameworkManifestPackages in stable order
+func sortedManifestFrameworks() []string {
+	names := make([]string, 0, len(FrameworkManifestPackages))
+	for name := range FrameworkManifestPackages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 // RegisterAllDetectors registers all framework detectors with the common package
 func RegisterAllDetectors() {
 	for _, indicator := range AllFrameworkIndicators {
@@ -0,0 +1,28 @@
+package golang
+
+// =============================================================================
+// WEBSOCKET CONNECTIONS
+// Reads from a WebSocket connection return client messages
+// =============================================================================
+
+// RealtimeReadMethods are WebSocket connection methods returning a client
+// message, mapped to their library
+var RealtimeReadMethods = map[string]string{
+	"ReadMessage": "gorilla/websocket",
+	"ReadJSON":    "gorilla/websocket",
+	"NextReader":  "gorilla/websocket",
+}
+
+// RealtimeReceiveCalls are functions storing a client message into the
+// variable passed by reference as PayloadArg, mapped to their library
+var RealtimeReceiveCalls = map[string]RealtimeReceive{
+	"websocket.Message.Receive": {Library: "x/net/websocket", PayloadArg: 1},
+	"websocket.JSON.Receive":    {Library: "x/net/websocket", PayloadArg: 1},
+	"wsjson.Read":               {Library: "nhooyr.io/websocket", PayloadArg: 2},
+}
+
+// RealtimeReceive describes a message-receiving function
+type RealtimeReceive struct {
+	Library    string
+	PayloadArg int // Index of the argument receiving the message
+}
@@ -13,6 +13,7 @@ const (
 	GraphNodeCarrier   GraphNodeType = "carrier"
 	GraphNodeProperty  GraphNodeType = "property"
 	GraphNodeReturn    GraphNodeType = "return"
+	GraphNodeBarrier   GraphNodeType = "barrier"
 )
 
 // GraphEdgeType represents edge types in flow graphs
@@ -59,6 +60,7 @@ var NodeStyles = map[GraphNodeType]NodeStyle{
 	GraphNodeCarrier:   {FillColor: "#4ecdc4", StrokeColor: "#333", TextColor: "white", Shape: "box"},
 	GraphNodeProperty:  {FillColor: "#f9f9f9", StrokeColor: "#333", TextColor: "black", Shape: "box"},
 	GraphNodeReturn:    {FillColor: "#f9f9f9", StrokeColor: "#333", TextColor: "black", Shape: "box"},
+	GraphNodeBarrier:   {FillColor: "#ffd166", StrokeColor: "#333", TextColor: "black", Shape: "octagon"},
 }
 
 // DefaultNodeStyle is returned when node type is unknown
@@ -0,0 +1,57 @@
+package javascript
+
+import "regexp"
+
+// =============================================================================
+// WEBSOCKET AND SERVER-SENT EVENTS
+// Message handlers of socket.io, ws and browser WebSocket/EventSource objects
+// receive peer-controlled data through their parameters
+// =============================================================================
+
+var (
+	// RealtimeReceiverPattern matches receivers of WebSocket message handlers
+	// (socket.on('chat', ...), ws.on('message', ...), ws.onmessage = ...)
+	RealtimeReceiverPattern = regexp.MustCompile(`(?i)(socket|^wss?$|^conn(ection)?$|^client$|^peer$)`)
+
+	// SSEReceiverPattern matches EventSource receivers (es.onmessage = ...)
+	SSEReceiverPattern = regexp.MustCompile(`(?i)(eventsource|^es$|^sse$|^events?$|^source$)`)
+)
+
+// RealtimeLifecycleEvents are connection events whose handlers receive no
+// peer message
+var RealtimeLifecycleEvents = map[string]bool{
+	"connection": true, "connect": true, "disconnect": true, "disconnecting": true,
+	"close": true, "error": true, "open": true, "upgrade": true, "headers": true,
+	"listening": true, "ping": true, "pong": true, "connect_error": true, "reconnect": true,
+}
+
+// RealtimeAckParams are conventional names of acknowledgement callbacks
+// passed after the payload (socket.on('event', (data, ack) => ...))
+var RealtimeAckParams = map[string]bool{
+	"ack": true, "callback": true, "cb": true, "done": true, "fn": true, "respond": true, "reply": true,
+}
+
+const (
+	// RealtimeMessageEvent is the event delivering messages in ws and EventSource
+	RealtimeMessageEvent = "message"
+	// RealtimeMessageProperty is the message callback property (ws.onmessage = ...)
+	RealtimeMessageProperty = "onmessage"
+	// RealtimeListenerMethod registers DOM-style event listeners
+	RealtimeListenerMethod = "addEventListener"
+)
+
+// RealtimeFramework returns the library and protocol of a handler registered
+// on receiver for event, or empty strings if the receiver does not look like a
+// realtime connection
+func RealtimeFramework(receiver, event string) (framework, protocol string) {
+	switch {
+	case SSEReceiverPattern.MatchString(receiver):
+		return "eventsource", "sse"
+	case !RealtimeReceiverPattern.MatchString(receiver):
+		return "", ""
+	case event == RealtimeMessageEvent:
+		return "ws", "websocket"
+	default:
+		return "socket.io", "websocket"
+	}
+}
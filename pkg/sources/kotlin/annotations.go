@@ -0,0 +1,16 @@
+package kotlin
+
+import "github.com/hatlesswizard/inputtracer/pkg/sources/common"
+
+// InputAnnotations maps Spring parameter annotations on Kotlin controller
+// parameters to the source type of the bound value
+var InputAnnotations = map[string]common.SourceType{
+	"RequestParam":   common.SourceHTTPGet,
+	"PathVariable":   common.SourceHTTPPath,
+	"MatrixVariable": common.SourceHTTPGet,
+	"RequestBody":    common.SourceHTTPBody,
+	"RequestPart":    common.SourceHTTPBody,
+	"ModelAttribute": common.SourceHTTPPost,
+	"RequestHeader":  common.SourceHTTPHeader,
+	"CookieValue":    common.SourceHTTPCookie,
+}
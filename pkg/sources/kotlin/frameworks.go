@@ -0,0 +1,167 @@
+// Package kotlin - frameworks.go provides Kotlin web framework patterns
+// Includes patterns for Ktor and Spring (Kotlin controllers)
+package kotlin
+
+import (
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
+// Registry is the global Kotlin framework pattern registry
+var Registry = common.NewFrameworkPatternRegistry("kotlin")
+
+// Ktor patterns (asynchronous Kotlin web framework)
+var ktorPatterns = []*common.FrameworkPattern{
+	{
+		ID:              "ktor_call_parameters",
+		Framework:       "ktor",
+		Language:        "kotlin",
+		Name:            "call.parameters",
+		Description:     "Ktor route path and query parameters",
+		CarrierClass:    "ApplicationCall",
+		PropertyPattern: "^parameters$",
+		SourceType:      common.SourceHTTPRequest,
+		Tags:            []string{"web", "async", "popular"},
+	},
+	{
+		ID:              "ktor_query_parameters",
+		Framework:       "ktor",
+		Language:        "kotlin",
+		Name:            "call.request.queryParameters",
+		Description:     "Ktor query string parameters",
+		CarrierClass:    "ApplicationRequest",
+		PropertyPattern: "^queryParameters$",
+		SourceType:      common.SourceHTTPGet,
+		Tags:            []string{"web", "async"},
+	},
+	{
+		ID:            "ktor_receive",
+		Framework:     "ktor",
+		Language:      "kotlin",
+		Name:          "call.receive<T>()",
+		Description:   "Ktor typed request body",
+		CarrierClass:  "ApplicationCall",
+		MethodPattern: "^receive(Nullable)?$",
+		SourceType:    common.SourceHTTPBody,
+		Tags:          []string{"web", "async", "popular"},
+	},
+	{
+		ID:            "ktor_receive_text",
+		Framework:     "ktor",
+		Language:      "kotlin",
+		Name:          "call.receiveText()",
+		Description:   "Ktor raw request body",
+		CarrierClass:  "ApplicationCall",
+		MethodPattern: "^receiveText$",
+		SourceType:    common.SourceHTTPBody,
+		Tags:          []string{"web", "async"},
+	},
+	{
+		ID:            "ktor_receive_parameters",
+		Framework:     "ktor",
+		Language:      "kotlin",
+		Name:          "call.receiveParameters()",
+		Description:   "Ktor form parameters",
+		CarrierClass:  "ApplicationCall",
+		MethodPattern: "^receiveParameters$",
+		SourceType:    common.SourceHTTPPost,
+		Tags:          []string{"web", "async"},
+	},
+	{
+		ID:            "ktor_receive_multipart",
+		Framework:     "ktor",
+		Language:      "kotlin",
+		Name:          "call.receiveMultipart()",
+		Description:   "Ktor multipart form data",
+		CarrierClass:  "ApplicationCall",
+		MethodPattern: "^receiveMultipart$",
+		SourceType:    common.SourceHTTPFile,
+		Tags:          []string{"web", "async", "upload"},
+	},
+	{
+		ID:              "ktor_headers",
+		Framework:       "ktor",
+		Language:        "kotlin",
+		Name:            "call.request.headers",
+		Description:     "Ktor request headers",
+		CarrierClass:    "ApplicationRequest",
+		PropertyPattern: "^headers$",
+		SourceType:      common.SourceHTTPHeader,
+		Tags:            []string{"web", "async"},
+	},
+	{
+		ID:              "ktor_cookies",
+		Framework:       "ktor",
+		Language:        "kotlin",
+		Name:            "call.request.cookies",
+		Description:     "Ktor request cookies",
+		CarrierClass:    "ApplicationRequest",
+		PropertyPattern: "^cookies$",
+		SourceType:      common.SourceHTTPCookie,
+		Tags:            []string{"web", "async"},
+	},
+}
+
+// Spring patterns (annotated controller parameters written in Kotlin)
+var springPatterns = []*common.FrameworkPattern{
+	{
+		ID:            "spring_kotlin_request_param",
+		Framework:     "spring",
+		Language:      "kotlin",
+		Name:          "@RequestParam",
+		Description:   "Spring request parameter bound to a Kotlin parameter",
+		MethodPattern: "^RequestParam$",
+		SourceType:    common.SourceHTTPGet,
+		Tags:          []string{"web", "mvc", "popular"},
+	},
+	{
+		ID:            "spring_kotlin_path_variable",
+		Framework:     "spring",
+		Language:      "kotlin",
+		Name:          "@PathVariable",
+		Description:   "Spring path variable bound to a Kotlin parameter",
+		MethodPattern: "^PathVariable$",
+		SourceType:    common.SourceHTTPPath,
+		Tags:          []string{"web", "mvc"},
+	},
+	{
+		ID:            "spring_kotlin_request_body",
+		Framework:     "spring",
+		Language:      "kotlin",
+		Name:          "@RequestBody",
+		Description:   "Spring request body bound to a Kotlin parameter",
+		MethodPattern: "^RequestBody$",
+		SourceType:    common.SourceHTTPBody,
+		Tags:          []string{"web", "mvc", "popular"},
+	},
+	{
+		ID:            "spring_kotlin_request_header",
+		Framework:     "spring",
+		Language:      "kotlin",
+		Name:          "@RequestHeader",
+		Description:   "Spring request header bound to a Kotlin parameter",
+		MethodPattern: "^RequestHeader$",
+		SourceType:    common.SourceHTTPHeader,
+		Tags:          []string{"web", "mvc"},
+	},
+	{
+		ID:            "spring_kotlin_cookie_value",
+		Framework:     "spring",
+		Language:      "kotlin",
+		Name:          "@CookieValue",
+		Description:   "Spring cookie bound to a Kotlin parameter",
+		MethodPattern: "^CookieValue$",
+		SourceType:    common.SourceHTTPCookie,
+		Tags:          []string{"web", "mvc"},
+	},
+}
+
+func init() {
+	Registry.RegisterAll(ktorPatterns)
+	Registry.RegisterAll(springPatterns)
+
+	// Register framework detectors
+	common.RegisterFrameworkDetector(&common.FrameworkDetector{
+		Framework:  "ktor",
+		Indicators: []string{"build.gradle.kts", "io.ktor"},
+	})
+}
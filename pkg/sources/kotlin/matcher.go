@@ -0,0 +1,128 @@
+package kotlin
+
+import "github.com/hatlesswizard/inputtracer/pkg/sources/common"
+
+// Matcher matches Kotlin user input sources
+type Matcher struct {
+	*common.BaseMatcher
+}
+
+// NewMatcher creates a new Kotlin source matcher
+func NewMatcher() *Matcher {
+	defs := []common.Definition{
+		// CLI arguments and standard input
+		{
+			Name:        "readLine()",
+			Pattern:     `^(readLine|readln|readlnOrNull)\s*\(`,
+			Language:    "kotlin",
+			Labels:      []common.InputLabel{common.LabelUserInput},
+			Description: "Read line from stdin",
+			NodeTypes:   []string{"call_expression"},
+		},
+
+		// Environment variables
+		{
+			Name:         "System.getenv()",
+			Pattern:      `System\.getenv\s*\(`,
+			Language:     "kotlin",
+			Labels:       []common.InputLabel{common.LabelEnvironment},
+			Description:  "Get environment variable",
+			NodeTypes:    []string{"call_expression"},
+			KeyExtractor: `getenv\s*\(\s*"([^"]+)"`,
+		},
+
+		// File operations
+		{
+			Name:        "File.readText()",
+			Pattern:     `File\s*\([^)]*\)\.(readText|readLines|readBytes)\s*\(`,
+			Language:    "kotlin",
+			Labels:      []common.InputLabel{common.LabelFile},
+			Description: "Read file contents",
+			NodeTypes:   []string{"call_expression"},
+		},
+
+		// Ktor
+		{
+			Name:         "call.parameters",
+			Pattern:      `call\.parameters\b`,
+			Language:     "kotlin",
+			Labels:       []common.InputLabel{common.LabelHTTPGet, common.LabelUserInput},
+			Description:  "Ktor route and query parameters",
+			NodeTypes:    []string{"navigation_expression"},
+			KeyExtractor: `parameters\s*\[\s*"([^"]+)"`,
+		},
+		{
+			Name:         "call.request.queryParameters",
+			Pattern:      `request\.queryParameters\b`,
+			Language:     "kotlin",
+			Labels:       []common.InputLabel{common.LabelHTTPGet, common.LabelUserInput},
+			Description:  "Ktor query string parameters",
+			NodeTypes:    []string{"navigation_expression"},
+			KeyExtractor: `queryParameters\s*\[\s*"([^"]+)"`,
+		},
+		{
+			Name:        "call.receive()",
+			Pattern:     `call\.receive(Nullable|Text|Parameters|Multipart|Channel|Stream)?\b`,
+			Language:    "kotlin",
+			Labels:      []common.InputLabel{common.LabelHTTPBody, common.LabelUserInput},
+			Description: "Ktor request body",
+			NodeTypes:   []string{"call_expression"},
+		},
+		{
+			Name:         "call.request.headers",
+			Pattern:      `request\.headers\b|request\.header\s*\(`,
+			Language:     "kotlin",
+			Labels:       []common.InputLabel{common.LabelHTTPHeader, common.LabelUserInput},
+			Description:  "Ktor request headers",
+			NodeTypes:    []string{"navigation_expression", "call_expression"},
+			KeyExtractor: `headers?\s*[\[(]\s*"([^"]+)"`,
+		},
+		{
+			Name:         "call.request.cookies",
+			Pattern:      `request\.cookies\b`,
+			Language:     "kotlin",
+			Labels:       []common.InputLabel{common.LabelHTTPCookie, common.LabelUserInput},
+			Description:  "Ktor request cookies",
+			NodeTypes:    []string{"navigation_expression"},
+			KeyExtractor: `cookies\s*\[\s*"([^"]+)"`,
+		},
+
+		// Spring (Kotlin controllers)
+		{
+			Name:        "@RequestParam",
+			Pattern:     `@(RequestParam|PathVariable|MatrixVariable)\b`,
+			Language:    "kotlin",
+			Labels:      []common.InputLabel{common.LabelHTTPGet, common.LabelUserInput},
+			Description: "Spring request or path parameter",
+			NodeTypes:   []string{"annotation"},
+		},
+		{
+			Name:        "@RequestBody",
+			Pattern:     `@(RequestBody|RequestPart|ModelAttribute)\b`,
+			Language:    "kotlin",
+			Labels:      []common.InputLabel{common.LabelHTTPBody, common.LabelUserInput},
+			Description: "Spring request body",
+			NodeTypes:   []string{"annotation"},
+		},
+		{
+			Name:        "@RequestHeader",
+			Pattern:     `@RequestHeader\b`,
+			Language:    "kotlin",
+			Labels:      []common.InputLabel{common.LabelHTTPHeader, common.LabelUserInput},
+			Description: "Spring request header",
+			NodeTypes:   []string{"annotation"},
+		},
+		{
+			Name:        "@CookieValue",
+			Pattern:     `@CookieValue\b`,
+			Language:    "kotlin",
+			Labels:      []common.InputLabel{common.LabelHTTPCookie, common.LabelUserInput},
+			Description: "Spring cookie value",
+			NodeTypes:   []string{"annotation"},
+		},
+	}
+
+	return &Matcher{
+		BaseMatcher: common.NewBaseMatcher("kotlin", defs),
+	}
+}
@@ -37,6 +37,12 @@ func IsValidSourceType(s string) bool {
 	return common.IsValidSourceType(s)
 }
 
+// CanonicalSourceName returns the language-independent name of an input,
+// e.g. "http_get:id"
+func CanonicalSourceName(st SourceType, key string) string {
+	return common.CanonicalSourceName(st, key)
+}
+
 // LabelToSourceType maps InputLabel to SourceType for conversion
 var LabelToSourceType = map[InputLabel]SourceType{
 	LabelHTTPGet:     SourceHTTPGet,
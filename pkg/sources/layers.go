@@ -0,0 +1,81 @@
+package sources
+
+import "strings"
+
+// Architectural layers a file or class can belong to
+const (
+	LayerController = "controller"
+	LayerModel      = "model"
+	LayerView       = "view"
+	LayerCLI        = "cli"
+	LayerMigration  = "migration"
+)
+
+// LayerConvention recognizes a layer from the directories and file suffixes
+// of a path or the suffix of a class name
+type LayerConvention struct {
+	Layer         string
+	PathMarkers   []string // Lower-case path fragments, matched against "/"+path
+	FileSuffixes  []string // Lower-case file name suffixes
+	ClassSuffixes []string // Class name suffixes
+}
+
+// DefaultLayerConventions are the directory and naming conventions shared by
+// MVC frameworks, checked in order: migrations and views live under
+// directories (database/, resources/) that would otherwise look like models
+var DefaultLayerConventions = []LayerConvention{
+	{
+		Layer:       LayerMigration,
+		PathMarkers: []string{"/migrations/", "/db/migrate/"},
+	},
+	{
+		Layer:        LayerView,
+		PathMarkers:  []string{"/views/", "/view/", "/templates/", "/layouts/"},
+		FileSuffixes: []string{".blade.php", ".twig", ".phtml", ".tpl", ".erb", ".jinja", ".jinja2"},
+	},
+	{
+		Layer:         LayerCLI,
+		PathMarkers:   []string{"/console/", "/commands/", "/command/", "/cli/", "/bin/", "/cron/"},
+		ClassSuffixes: []string{"Command"},
+	},
+	{
+		Layer:         LayerController,
+		PathMarkers:   []string{"/controllers/", "/controller/", "/handlers/"},
+		ClassSuffixes: []string{"Controller", "Handler"},
+	},
+	{
+		Layer:         LayerModel,
+		PathMarkers:   []string{"/models/", "/model/", "/entity/", "/entities/", "/repositories/"},
+		ClassSuffixes: []string{"Model", "Entity", "Repository"},
+	},
+}
+
+// ClassifyLayer returns the layer of the default conventions a class name or
+// path belongs to, or "" if none applies. The class name is checked first,
+// since a class says more about its role than the directory holding it.
+func ClassifyLayer(path, className string) string {
+	if className != "" {
+		for _, conv := range DefaultLayerConventions {
+			for _, suffix := range conv.ClassSuffixes {
+				if strings.HasSuffix(className, suffix) && className != suffix {
+					return conv.Layer
+				}
+			}
+		}
+	}
+
+	lower := "/" + strings.ToLower(strings.ReplaceAll(path, "\\", "/"))
+	for _, conv := range DefaultLayerConventions {
+		for _, suffix := range conv.FileSuffixes {
+			if strings.HasSuffix(lower, suffix) {
+				return conv.Layer
+			}
+		}
+		for _, marker := range conv.PathMarkers {
+			if strings.Contains(lower, marker) {
+				return conv.Layer
+			}
+		}
+	}
+	return ""
+}
@@ -122,6 +122,8 @@ func init() {
 	registerCSharpMappings()
 	registerRubyMappings()
 	registerRustMappings()
+	registerKotlinMappings()
+	registerSwiftMappings()
 }
 
 func registerGoMappings() {
@@ -157,6 +159,8 @@ func registerGoMappings() {
 			"bufio.NewScanner": SourceStdin, "ioutil.ReadFile": SourceFile,
 			"os.ReadFile": SourceFile, "os.Open": SourceFile,
 			"io.ReadAll": SourceUserInput,
+			"conn.ReadMessage": SourceNetwork, "conn.ReadJSON": SourceNetwork,
+			"conn.NextReader": SourceNetwork,
 		},
 	}
 }
@@ -427,6 +431,37 @@ func registerRustMappings() {
 	}
 }
 
+func registerKotlinMappings() {
+	mappingsRegistry["kotlin"] = &LanguageMappings{
+		Language: "kotlin",
+		InputSources: map[string]SourceType{
+			"call.parameters": SourceHTTPRequest, "call.request.queryParameters": SourceHTTPGet,
+			"call.receive": SourceHTTPBody, "call.receiveNullable": SourceHTTPBody,
+			"call.receiveText": SourceHTTPBody, "call.receiveParameters": SourceHTTPPost,
+			"call.receiveMultipart": SourceHTTPFile, "call.request.headers": SourceHTTPHeader,
+			"call.request.header": SourceHTTPHeader, "call.request.cookies": SourceHTTPCookie,
+			"System.getenv": SourceEnvVar, "readLine": SourceStdin,
+			"readln": SourceStdin, "readlnOrNull": SourceStdin,
+		},
+	}
+}
+
+func registerSwiftMappings() {
+	mappingsRegistry["swift"] = &LanguageMappings{
+		Language: "swift",
+		InputSources: map[string]SourceType{
+			"req.query": SourceHTTPGet, "request.query": SourceHTTPGet,
+			"req.content": SourceHTTPBody, "request.content": SourceHTTPBody,
+			"req.parameters": SourceHTTPPath, "request.parameters": SourceHTTPPath,
+			"req.headers": SourceHTTPHeader, "request.headers": SourceHTTPHeader,
+			"req.cookies": SourceHTTPCookie, "request.cookies": SourceHTTPCookie,
+			"req.body": SourceHTTPBody, "request.body": SourceHTTPBody,
+			"Environment.get": SourceEnvVar, "ProcessInfo.processInfo.environment": SourceEnvVar,
+			"CommandLine.arguments": SourceCLIArg, "readLine": SourceStdin,
+		},
+	}
+}
+
 // GetMappings returns the mappings for a specific language
 func GetMappings(language string) *LanguageMappings {
 	return mappingsRegistry[language]
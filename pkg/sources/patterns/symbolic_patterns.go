@@ -128,24 +128,59 @@ var (
 	FunctionCallPattern = regexp.MustCompile(`^(\w+)\(([^)]*)\)$`)
 )
 
+// =============================================================================
+// STATEMENT SPLITTING PATTERNS
+// Used for splitting PHP source into logical statements, including code
+// written in the alternative syntax (if: ... endif;) and brace-less bodies
+// =============================================================================
+
+var (
+	// ControlConditionPattern matches a control structure keyword followed by its condition
+	// e.g., if (, elseif(, else if (, foreach (, while (
+	ControlConditionPattern = regexp.MustCompile(`^(?i)(?:if|elseif|else\s+if|foreach|for|while|switch|declare|catch)\s*\(`)
+
+	// ControlBarePattern matches a control structure keyword without condition
+	// e.g., else, do, try, finally
+	ControlBarePattern = regexp.MustCompile(`^(?i)(?:else|do|try|finally)\b`)
+
+	// CaseLabelPattern matches a switch label without its colon
+	// e.g., case 'edit', default
+	CaseLabelPattern = regexp.MustCompile(`^(?i)(?:case\b.*|default)$`)
+
+	// AlternativeEndPattern matches the keyword closing an alternative-syntax block
+	// e.g., endif, endforeach, endwhile
+	AlternativeEndPattern = regexp.MustCompile(`^(?i)end(?:if|foreach|for|while|switch|declare)$`)
+
+	// DeclarationHeaderPattern matches the header of a function, class or namespace declaration
+	// e.g., public static function get(, final class Request, namespace App
+	DeclarationHeaderPattern = regexp.MustCompile(`^(?i)(?:(?:abstract|final|readonly|public|private|protected|static)\s+)*(?:function|class|interface|trait|enum|namespace)\b`)
+)
+
 // =============================================================================
 // DYNAMIC PATTERN BUILDERS
 // Functions that build patterns based on runtime values
 // =============================================================================
 
 // BuildVariableAssignPattern creates a pattern for $varname = something;
+// Comparisons ($varname == x) and array arrows ($varname => x) do not match.
 func BuildVariableAssignPattern(varName string) *regexp.Regexp {
-	return regexp.MustCompile(`\$` + regexp.QuoteMeta(varName) + `\s*=\s*([^;]+);`)
+	return regexp.MustCompile(`\$` + regexp.QuoteMeta(varName) + `\s*=\s*([^=>;\s][^;]*);`)
+}
+
+// BuildFirstArgCallPattern creates a pattern for name($varname, ...), capturing
+// the function name
+func BuildFirstArgCallPattern(varName string) *regexp.Regexp {
+	return regexp.MustCompile(`\b([A-Za-z_]\w*)\s*\(\s*\$` + regexp.QuoteMeta(varName) + `\s*,`)
 }
 
 // BuildPropertyExternalAssignPattern creates a pattern for $var->property = something;
 func BuildPropertyExternalAssignPattern(varName, propertyName string) *regexp.Regexp {
-	return regexp.MustCompile(`\$` + regexp.QuoteMeta(varName) + `->` + regexp.QuoteMeta(propertyName) + `\s*=\s*([^;]+);`)
+	return regexp.MustCompile(`\$` + regexp.QuoteMeta(varName) + `->` + regexp.QuoteMeta(propertyName) + `\s*=\s*([^=>;\s][^;]*);`)
 }
 
 // BuildPropertyArrayExternalAssignPattern creates a pattern for $var->property['key'] = something;
 func BuildPropertyArrayExternalAssignPattern(varName, propertyName string) *regexp.Regexp {
-	return regexp.MustCompile(`\$` + regexp.QuoteMeta(varName) + `->` + regexp.QuoteMeta(propertyName) + `\[['"]?\w+['"]?\]\s*=\s*([^;]+);`)
+	return regexp.MustCompile(`\$` + regexp.QuoteMeta(varName) + `->` + regexp.QuoteMeta(propertyName) + `\[['"]?\w+['"]?\]\s*=\s*([^=>;\s][^;]*);`)
 }
 
 // BuildPropertyAssignInLoopPattern creates a pattern for $this->property[$keyVar] = $valVar
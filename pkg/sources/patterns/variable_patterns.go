@@ -42,6 +42,12 @@ var LanguageVariablePatterns = map[string][]*regexp.Regexp{
 	"rust": {
 		regexp.MustCompile(`\b[a-zA-Z_][a-zA-Z0-9_]*\b`),
 	},
+	"kotlin": {
+		regexp.MustCompile(`\b[a-zA-Z_][a-zA-Z0-9_]*\b`),
+	},
+	"swift": {
+		regexp.MustCompile(`\b[a-zA-Z_][a-zA-Z0-9_]*\b`),
+	},
 }
 
 // DefaultVariablePattern is used when language is not recognized
@@ -0,0 +1,65 @@
+package php
+
+import (
+	"regexp"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
+// =============================================================================
+// CLI ARGUMENTS
+// $argv, $_SERVER['argv'] and the array functions commonly used to consume
+// them. Positional keys: "1" is $argv[1], "-1" the last element and "1:" the
+// elements from index 1 on.
+// =============================================================================
+
+// CLIArgVariables are the variables holding command line arguments. They are
+// globals of the script, not superglobals: inside a function they are locals
+// unless declared global.
+var CLIArgVariables = map[string]common.SourceType{
+	"$argv": common.SourceCLIArg,
+	"$argc": common.SourceCLIArg,
+}
+
+// ServerCLIKeys are $_SERVER keys holding command line arguments
+var ServerCLIKeys = map[string]bool{
+	"argv": true,
+	"argc": true,
+}
+
+// CLIArgPattern matches an expression reading command line arguments through $_SERVER
+var CLIArgPattern = regexp.MustCompile(`\$_SERVER\s*\[\s*['"]arg[vc]['"]\s*\]`)
+
+// CLIArgVariablePattern matches an expression mentioning $argv or $argc, which
+// are command line arguments only where they refer to the script's globals
+var CLIArgVariablePattern = regexp.MustCompile(`\$arg[vc]\b`)
+
+// IsCLIArgExpression reports whether an expression reads command line arguments through $_SERVER
+func IsCLIArgExpression(expr string) bool {
+	return CLIArgPattern.MatchString(expr)
+}
+
+// IsCLIArgVariableExpression reports whether an expression mentions $argv or $argc
+func IsCLIArgVariableExpression(expr string) bool {
+	return CLIArgVariablePattern.MatchString(expr)
+}
+
+// ArgvPosition tells which element of an argument array a function reads
+type ArgvPosition int
+
+const (
+	ArgvShift ArgvPosition = iota // Next element from the front (array_shift)
+	ArgvFirst                     // First element (reset, current)
+	ArgvLast                      // Last element (end, array_pop)
+	ArgvSlice                     // Elements from an offset on (array_slice)
+)
+
+// ArgvHelperFunctions are array functions reading positional arguments
+var ArgvHelperFunctions = map[string]ArgvPosition{
+	"array_shift": ArgvShift,
+	"reset":       ArgvFirst,
+	"current":     ArgvFirst,
+	"end":         ArgvLast,
+	"array_pop":   ArgvLast,
+	"array_slice": ArgvSlice,
+}
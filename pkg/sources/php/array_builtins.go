@@ -0,0 +1,64 @@
+package php
+
+import "strings"
+
+// =============================================================================
+// ARRAY BUILTINS
+// Array functions that reshape data, with the arguments whose elements end up
+// in the result's keys or values, in an argument passed by reference, or in
+// the parameters of a callback. Arguments outside these (column names,
+// offsets, lengths) select data but do not carry it.
+// =============================================================================
+
+// ArrayBuiltin describes how taint moves through an array function
+type ArrayBuiltin struct {
+	Keys         []int // Arguments whose elements become the result's keys
+	Values       []int // Arguments whose elements become the result's values
+	RefArg       int   // Argument modified in place (-1 if none)
+	RefFrom      []int // Arguments whose elements are written into RefArg
+	Callback     int   // Callback argument (-1 if none)
+	CallbackFrom int   // Argument whose elements the callback receives
+	CallbackKeys bool  // The callback receives keys rather than values
+}
+
+// ArrayBuiltins maps array functions to their taint semantics
+var ArrayBuiltins = map[string]ArrayBuiltin{
+	// array_column($rows, $column, $index): values and index keys come from the rows
+	"array_column": {Keys: []int{0}, Values: []int{0}, RefArg: -1, Callback: -1},
+	// array_combine($keys, $values)
+	"array_combine": {Keys: []int{0}, Values: []int{1}, RefArg: -1, Callback: -1},
+	// array_fill_keys($keys, $value)
+	"array_fill_keys": {Keys: []int{0}, Values: []int{1}, RefArg: -1, Callback: -1},
+	// array_splice(&$array, $offset, $length, $replacement) returns the removed elements
+	"array_splice": {Values: []int{0}, RefArg: 0, RefFrom: []int{3}, Callback: -1},
+	// Sorting with a comparison callback passes elements to the callback
+	"usort":  {RefArg: -1, Callback: 1, CallbackFrom: 0},
+	"uasort": {RefArg: -1, Callback: 1, CallbackFrom: 0},
+	"uksort": {RefArg: -1, Callback: 1, CallbackFrom: 0, CallbackKeys: true},
+}
+
+// LookupArrayBuiltin returns the taint semantics of an array function
+func LookupArrayBuiltin(funcName string) (ArrayBuiltin, bool) {
+	b, ok := ArrayBuiltins[strings.ToLower(strings.TrimPrefix(funcName, "\\"))]
+	return b, ok
+}
+
+// ResultParts returns which parts of the result an argument flows to
+func (b ArrayBuiltin) ResultParts(arg int) (keys, values bool) {
+	return containsIndex(b.Keys, arg), containsIndex(b.Values, arg)
+}
+
+// WritesRef reports whether an argument is written into the by-reference argument
+func (b ArrayBuiltin) WritesRef(arg int) bool {
+	return b.RefArg >= 0 && containsIndex(b.RefFrom, arg)
+}
+
+// containsIndex reports whether indices contains i
+func containsIndex(indices []int, i int) bool {
+	for _, idx := range indices {
+		if idx == i {
+			return true
+		}
+	}
+	return false
+}
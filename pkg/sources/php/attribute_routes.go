@@ -0,0 +1,98 @@
+package php
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// =============================================================================
+// ATTRIBUTE AND CONVENTION ROUTING
+// Modern frameworks route without route files: Symfony controllers declare
+// their routes with PHP 8 #[Route] attributes, and Laravel Folio serves the
+// Blade templates under its pages directory at their path
+// =============================================================================
+
+// RouteAttributes are the attribute classes (lowercase, without the leading
+// backslash) declaring a route, mapped to their framework
+var RouteAttributes = map[string]string{
+	"route": "symfony",
+	"symfony\\component\\routing\\annotation\\route": "symfony",
+	"symfony\\component\\routing\\attribute\\route":  "symfony",
+}
+
+// RouteAttributeFramework returns the framework of a route attribute name as
+// written, or "" if the attribute does not declare a route
+func RouteAttributeFramework(name string) string {
+	return RouteAttributes[strings.ToLower(strings.TrimPrefix(name, "\\"))]
+}
+
+// JoinRoutePaths joins a class-level route prefix and a method route path
+func JoinRoutePaths(prefix, route string) string {
+	joined := strings.TrimSuffix(prefix, "/")
+	if route != "" {
+		joined += "/" + strings.TrimPrefix(route, "/")
+	}
+	if !strings.HasPrefix(joined, "/") {
+		joined = "/" + joined
+	}
+	return joined
+}
+
+// FolioPagesDirs are the directories (relative to the application root) whose
+// Blade templates Laravel Folio serves as pages
+var FolioPagesDirs = []string{"resources/views/pages"}
+
+// FolioMethods are the HTTP methods a Folio page answers
+var FolioMethods = []string{"GET", "HEAD"}
+
+// FolioRoute returns the route Laravel Folio serves a template at, given its
+// slash-separated path relative to the application root, and whether it is
+// a page: pages/users/[id].blade.php is /users/{id}, pages/users/index.blade.php
+// /users, [User] binds a model as {user} and [...ids] captures the rest.
+func FolioRoute(rel string) (string, bool) {
+	var page string
+	for _, dir := range FolioPagesDirs {
+		if rest, ok := strings.CutPrefix(rel, dir+"/"); ok {
+			page = rest
+			break
+		}
+	}
+	page, ok := strings.CutSuffix(page, ".blade.php")
+	if !ok || page == "" {
+		return "", false
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(page, "/") {
+		if inner, ok := strings.CutPrefix(segment, "["); ok && strings.HasSuffix(inner, "]") {
+			segment = "{" + folioParam(strings.TrimSuffix(inner, "]")) + "}"
+		}
+		segments = append(segments, segment)
+	}
+	if segments[len(segments)-1] == "index" {
+		segments = segments[:len(segments)-1]
+	}
+	return path.Join(append([]string{"/"}, segments...)...), true
+}
+
+// folioParam names the route parameter of a bracketed page segment: id for
+// [id], user for a model [User] or [.App.Models.User], user:slug for [User:slug]
+func folioParam(inner string) string {
+	rest, spread := strings.CutPrefix(inner, "...")
+	name, field, _ := strings.Cut(rest, ":")
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	if r, size := utf8.DecodeRuneInString(name); unicode.IsUpper(r) {
+		name = string(unicode.ToLower(r)) + name[size:]
+	}
+	if field != "" {
+		name += ":" + field
+	}
+	if spread {
+		name = "..." + name
+	}
+	return name
+}
@@ -0,0 +1,141 @@
+package php
+
+import (
+	"regexp"
+	"strings"
+)
+
+// =============================================================================
+// CONSTANT FOLDING
+// Statically evaluates key expressions built from literals and constants,
+// e.g. $_GET[PARAM_NAME], $mybb->input[MyBB::INPUT_KEY], $_POST['pre_' . 'fix']
+// =============================================================================
+
+var (
+	// ConstantNamePattern matches a global constant name (PARAM_NAME, \NS\NAME)
+	ConstantNamePattern = regexp.MustCompile(`^\\?[A-Za-z_][A-Za-z0-9_\\]*$`)
+
+	// ClassConstantPattern matches Class::CONSTANT, self::CONSTANT or static::CONSTANT
+	ClassConstantPattern = regexp.MustCompile(`^\\?([A-Za-z_][A-Za-z0-9_\\]*)::([A-Za-z_][A-Za-z0-9_]*)$`)
+
+	// IntegerLiteralPattern matches a decimal integer literal
+	IntegerLiteralPattern = regexp.MustCompile(`^-?\d+$`)
+)
+
+// SplitConcatenation splits a PHP concatenation expression on "." outside
+// string literals and parentheses
+func SplitConcatenation(expr string) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == quote && expr[i-1] != '\\' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == '.' && depth == 0:
+			parts = append(parts, strings.TrimSpace(expr[start:i]))
+			start = i + 1
+		}
+	}
+	return append(parts, strings.TrimSpace(expr[start:]))
+}
+
+// FoldConstantExpression evaluates a key expression made of string/integer
+// literals, constants and class constants joined by ".". lookup resolves a
+// constant name ("NAME" or "Class::NAME") to its value expression, which is
+// folded in turn. Returns false if any part is not statically determinable.
+func FoldConstantExpression(expr string, lookup func(name string) (string, bool)) (string, bool) {
+	return foldConstantExpression(expr, lookup, 0)
+}
+
+// maxFoldDepth bounds constants defined in terms of other constants
+const maxFoldDepth = 8
+
+func foldConstantExpression(expr string, lookup func(name string) (string, bool), depth int) (string, bool) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" || depth > maxFoldDepth {
+		return "", false
+	}
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+
+	var folded strings.Builder
+	for _, part := range SplitConcatenation(expr) {
+		switch {
+		case len(part) >= 2 && part[0] == '\'' && part[len(part)-1] == '\'':
+			folded.WriteString(part[1 : len(part)-1])
+		case len(part) >= 2 && part[0] == '"' && part[len(part)-1] == '"':
+			if strings.Contains(part, "$") {
+				return "", false // Interpolated string
+			}
+			folded.WriteString(part[1 : len(part)-1])
+		case IntegerLiteralPattern.MatchString(part):
+			folded.WriteString(part)
+		case ClassConstantPattern.MatchString(part) || ConstantNamePattern.MatchString(part):
+			if lookup == nil {
+				return "", false
+			}
+			valueExpr, ok := lookup(strings.TrimPrefix(part, "\\"))
+			if !ok {
+				return "", false
+			}
+			value, ok := foldConstantExpression(valueExpr, lookup, depth+1)
+			if !ok {
+				return "", false
+			}
+			folded.WriteString(value)
+		default:
+			return "", false
+		}
+	}
+	return folded.String(), true
+}
+
+// IsLiteralKey reports whether a subscript key expression is already a plain
+// literal or a variable, i.e. needs no constant folding
+func IsLiteralKey(expr string) bool {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "$") || IntegerLiteralPattern.MatchString(expr) {
+		return true
+	}
+	parts := SplitConcatenation(expr)
+	if len(parts) != 1 || len(expr) < 2 {
+		return false
+	}
+	return (expr[0] == '\'' || expr[0] == '"') && expr[len(expr)-1] == expr[0]
+}
+
+// IncludeDirExpressions evaluate to the directory of the current file, so an
+// include path appended to one is relative to the including file
+var IncludeDirExpressions = []string{"__DIR__", "dirname(__FILE__)"}
+
+// FileRelativeIncludePath returns the path of an include expression such as
+// __DIR__ . '/lib/db.php' relative to the including file's directory
+func FileRelativeIncludePath(expr string) (string, bool) {
+	expr = strings.TrimSpace(expr)
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	parts := SplitConcatenation(expr)
+	if len(parts) != 2 || len(parts[1]) < 2 || !strings.ContainsRune(`'"`, rune(parts[1][0])) ||
+		parts[1][len(parts[1])-1] != parts[1][0] {
+		return "", false
+	}
+	dir := strings.ReplaceAll(parts[0], " ", "")
+	for _, dirExpr := range IncludeDirExpressions {
+		if strings.EqualFold(dir, dirExpr) {
+			return strings.TrimPrefix(parts[1][1:len(parts[1])-1], "/"), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,93 @@
+package php
+
+import (
+	"regexp"
+	"strings"
+)
+
+// =============================================================================
+// DECODING AND NORMALIZATION
+// Functions that change how input is encoded, recorded on the flows calling
+// them, and functions that escape or check it.
+// =============================================================================
+
+// DecodingFunctions undo an encoding or normalize input, producing characters
+// that were not present in the value before
+var DecodingFunctions = map[string]bool{
+	"urldecode":               true,
+	"rawurldecode":            true,
+	"base64_decode":           true,
+	"stripslashes":            true,
+	"stripcslashes":           true,
+	"html_entity_decode":      true,
+	"htmlspecialchars_decode": true,
+	"hex2bin":                 true,
+	"quoted_printable_decode": true,
+	"convert_uudecode":        true,
+	"utf8_decode":             true,
+	"mb_convert_encoding":     true,
+	"iconv":                   true,
+	"normalizer_normalize":    true,
+	"normalizer::normalize":   true,
+}
+
+// SanitizingFunctions escape or strip input, returning the restricted value
+var SanitizingFunctions = map[string]bool{
+	"htmlspecialchars": true,
+	"htmlentities":     true,
+	"strip_tags":       true,
+	"addslashes":       true,
+	"addcslashes":      true,
+	"quotemeta":        true,
+	"preg_quote":       true,
+	"filter_var":       true,
+}
+
+// ValidatingFunctions check input and report whether it is acceptable,
+// typically in a condition guarding the rest of the code. Validator functions
+// detected in the scanned code or declared in rules are recognized as well.
+var ValidatingFunctions = map[string]bool{
+	"ctype_digit":  true,
+	"ctype_alnum":  true,
+	"ctype_alpha":  true,
+	"ctype_xdigit": true,
+	"is_numeric":   true,
+	"preg_match":   true,
+	"filter_var":   true,
+	"in_array":     true,
+}
+
+// CalledFunctionPattern matches the name of each function or static method
+// called in an expression
+var CalledFunctionPattern = regexp.MustCompile(`([A-Za-z_\\][\w\\]*(?:::\w+)?)\s*\(`)
+
+// CalledFunction returns the first function called in expr that is in set
+func CalledFunction(expr string, set map[string]bool) string {
+	for _, m := range CalledFunctionPattern.FindAllStringSubmatch(expr, -1) {
+		name := strings.ToLower(strings.TrimPrefix(m[1], "\\"))
+		if set[name] {
+			return name
+		}
+	}
+	return ""
+}
+
+// DecodingCall returns the decoding function called in expr, or ""
+func DecodingCall(expr string) string {
+	return CalledFunction(expr, DecodingFunctions)
+}
+
+// SanitizingCall returns the sanitizing function called in expr, or ""
+func SanitizingCall(expr string) string {
+	return CalledFunction(expr, SanitizingFunctions)
+}
+
+// IsDecodingFunction reports whether name is a decoding function
+func IsDecodingFunction(name string) bool {
+	return DecodingFunctions[strings.ToLower(strings.TrimPrefix(name, "\\"))]
+}
+
+// IsValidatingFunction reports whether name is a validating function
+func IsValidatingFunction(name string) bool {
+	return ValidatingFunctions[strings.ToLower(strings.TrimPrefix(name, "\\"))]
+}
@@ -12,19 +12,22 @@ import (
 // Registry is the global PHP framework pattern registry
 var Registry = common.NewFrameworkPatternRegistry("php")
 
-// Pattern caches - built lazily from registered framework patterns
-var (
-	inputMethodPatterns   []string
-	inputPropertyPatterns []string
-	patternsOnce          sync.Once
-)
+// InputPatterns are the method calls and property reads of input derived
+// from framework patterns, e.g. "->input(" from the method pattern "^input$"
+type InputPatterns struct {
+	methods         []string
+	properties      []string
+	methodMatcher   *common.KeywordMatcher
+	propertyMatcher *common.KeywordMatcher
+}
 
-// buildPatternsFromRegistry extracts method and property patterns from registered framework patterns
-func buildPatternsFromRegistry() {
+// NewInputPatterns derives the input method and property patterns of
+// framework patterns
+func NewInputPatterns(patterns []*common.FrameworkPattern) *InputPatterns {
 	methodSet := make(map[string]bool)
 	propertySet := make(map[string]bool)
 
-	for _, pattern := range Registry.GetAll() {
+	for _, pattern := range patterns {
 		// Extract method names from MethodPattern (e.g., "^input$" -> "input")
 		if pattern.MethodPattern != "" {
 			methodName := stripRegexAnchors(pattern.MethodPattern)
@@ -48,15 +51,46 @@ func buildPatternsFromRegistry() {
 	}
 
 	// Convert sets to slices
-	inputMethodPatterns = make([]string, 0, len(methodSet))
+	p := &InputPatterns{
+		methods:    make([]string, 0, len(methodSet)),
+		properties: make([]string, 0, len(propertySet)),
+	}
 	for pattern := range methodSet {
-		inputMethodPatterns = append(inputMethodPatterns, pattern)
+		p.methods = append(p.methods, pattern)
 	}
-
-	inputPropertyPatterns = make([]string, 0, len(propertySet))
 	for pattern := range propertySet {
-		inputPropertyPatterns = append(inputPropertyPatterns, pattern)
+		p.properties = append(p.properties, pattern)
 	}
+
+	p.methodMatcher = common.NewKeywordMatcher(p.methods)
+	p.propertyMatcher = common.NewKeywordMatcher(p.properties)
+	return p
+}
+
+// IsInputPropertyAccess checks if an expression matches an input property pattern
+func (p *InputPatterns) IsInputPropertyAccess(expr string) bool {
+	return p.propertyMatcher.Contains(expr)
+}
+
+// IsInputMethodCall checks if an expression matches an input method pattern
+func (p *InputPatterns) IsInputMethodCall(expr string) bool {
+	return p.methodMatcher.Contains(expr)
+}
+
+// Input patterns of the registered framework patterns - built lazily
+var (
+	registryInputPatterns *InputPatterns
+	patternsOnce          sync.Once
+)
+
+// RegistryInputPatterns returns the input patterns of all registered
+// framework patterns, built on first access to ensure all framework patterns
+// are registered
+func RegistryInputPatterns() *InputPatterns {
+	patternsOnce.Do(func() {
+		registryInputPatterns = NewInputPatterns(Registry.GetAll())
+	})
+	return registryInputPatterns
 }
 
 // stripRegexAnchors removes ^ and $ anchors from a regex pattern
@@ -74,35 +108,23 @@ func stripRegexAnchors(pattern string) string {
 // GetInputMethodPatterns returns method patterns derived from registered framework patterns
 // Built lazily on first access to ensure all framework patterns are registered
 func GetInputMethodPatterns() []string {
-	patternsOnce.Do(buildPatternsFromRegistry)
-	return inputMethodPatterns
+	return RegistryInputPatterns().methods
 }
 
 // GetInputPropertyPatterns returns property patterns derived from registered framework patterns
 // Built lazily on first access to ensure all framework patterns are registered
 func GetInputPropertyPatterns() []string {
-	patternsOnce.Do(buildPatternsFromRegistry)
-	return inputPropertyPatterns
+	return RegistryInputPatterns().properties
 }
 
 // IsInputPropertyAccess checks if an expression matches an input property pattern
 func IsInputPropertyAccess(expr string) bool {
-	for _, pattern := range GetInputPropertyPatterns() {
-		if strings.Contains(expr, pattern) {
-			return true
-		}
-	}
-	return false
+	return RegistryInputPatterns().IsInputPropertyAccess(expr)
 }
 
 // IsInputMethodCall checks if an expression matches an input method pattern
 func IsInputMethodCall(expr string) bool {
-	for _, pattern := range GetInputMethodPatterns() {
-		if strings.Contains(expr, pattern) {
-			return true
-		}
-	}
-	return false
+	return RegistryInputPatterns().IsInputMethodCall(expr)
 }
 
 // Note: IsContextDependentMethod, IsInputMethod, IsInputProperty, IsInputObject
@@ -189,28 +189,44 @@ func IsExternalDataFunction(funcName string) bool {
 		IsNetworkFunction(funcName)
 }
 
-// IdentifyExternalDataSource identifies the source type from an expression
-// Returns the source type and confidence level
-func IdentifyExternalDataSource(expr string) (common.SourceType, float64) {
-	// Check network functions first (more specific)
-	if ContainsCurlFunction(expr) {
-		return common.SourceNetwork, 0.8
-	}
-	if ContainsNetworkFunction(expr) {
-		return common.SourceNetwork, 0.75
-	}
-
-	// Check deserialization
-	if ContainsDeserializationFunction(expr) {
-		return common.SourceUserInput, 0.85
-	}
+// externalDataCall is what a call to an external data function identifies
+type externalDataCall struct {
+	sourceType common.SourceType
+	confidence float64
+}
 
-	// Check input functions
-	for _, fn := range InputFunctions {
-		if strings.Contains(expr, fn+"(") {
-			return GetInputFunctionSourceType(fn), 0.9
+// externalDataCalls and externalDataMatcher hold the calls "fn(" checked by
+// IdentifyExternalDataSource, most specific first: keyword order is priority
+var externalDataCalls, externalDataMatcher = func() ([]externalDataCall, *common.KeywordMatcher) {
+	var calls []externalDataCall
+	var keywords []string
+	add := func(fns []string, call func(fn string) externalDataCall) {
+		for _, fn := range fns {
+			keywords = append(keywords, fn+"(")
+			calls = append(calls, call(fn))
 		}
 	}
+	add([]string{"curl_exec", "curl_multi_getcontent", "curl_multi_exec"}, func(string) externalDataCall {
+		return externalDataCall{common.SourceNetwork, 0.8}
+	})
+	add(NetworkFunctions, func(string) externalDataCall {
+		return externalDataCall{common.SourceNetwork, 0.75}
+	})
+	add(DeserializationFunctions, func(string) externalDataCall {
+		return externalDataCall{common.SourceUserInput, 0.85}
+	})
+	add(InputFunctions, func(fn string) externalDataCall {
+		return externalDataCall{GetInputFunctionSourceType(fn), 0.9}
+	})
+	return calls, common.NewKeywordMatcher(keywords)
+}()
 
+// IdentifyExternalDataSource identifies the source type from an expression
+// Returns the source type and confidence level
+func IdentifyExternalDataSource(expr string) (common.SourceType, float64) {
+	// Network functions first (more specific), then deserialization, then input
+	if i := externalDataMatcher.First(expr); i >= 0 {
+		return externalDataCalls[i].sourceType, externalDataCalls[i].confidence
+	}
 	return common.SourceUnknown, 0.0
 }
@@ -183,12 +183,13 @@ func NewMatcher() *Matcher {
 
 		// CLI
 		{
-			Name:        "$argv",
-			Pattern:     `\$argv`,
-			Language:    "php",
-			Labels:      []common.InputLabel{common.LabelCLI},
-			Description: "Command line arguments",
-			NodeTypes:   []string{"variable_name"},
+			Name:         "$argv",
+			Pattern:      `\$argv`,
+			Language:     "php",
+			Labels:       []common.InputLabel{common.LabelCLI},
+			Description:  "Command line arguments",
+			NodeTypes:    []string{"variable_name"},
+			KeyExtractor: `\$argv\s*\[\s*(\d+)\s*\]`,
 		},
 
 		// Stream input
@@ -0,0 +1,154 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources/constants"
+)
+
+// =============================================================================
+// PARAMETER TYPE INFERENCE
+// How a use of an input value constrains its effective type
+// =============================================================================
+
+// CastParamTypes maps PHP cast types to the parameter type they enforce
+var CastParamTypes = map[string]constants.ParamType{
+	"int":     constants.ParamInt,
+	"integer": constants.ParamInt,
+	"float":   constants.ParamFloat,
+	"double":  constants.ParamFloat,
+	"real":    constants.ParamFloat,
+	"bool":    constants.ParamBool,
+	"boolean": constants.ParamBool,
+}
+
+// TypingFunctions maps functions that convert or validate a value to the
+// parameter type they enforce on their first argument
+var TypingFunctions = map[string]constants.ParamType{
+	"intval":      constants.ParamInt,
+	"absint":      constants.ParamInt,
+	"is_numeric":  constants.ParamInt,
+	"is_int":      constants.ParamInt,
+	"ctype_digit": constants.ParamInt,
+	"floatval":    constants.ParamFloat,
+	"doubleval":   constants.ParamFloat,
+	"is_float":    constants.ParamFloat,
+	"boolval":     constants.ParamBool,
+	"is_bool":     constants.ParamBool,
+}
+
+// EnumCheckFunctions take the checked value as first argument and the
+// allowed literal set as second argument
+var EnumCheckFunctions = map[string]bool{
+	"in_array": true,
+}
+
+// PatternCheckFunctions take the regex as first argument and the checked value as second
+var PatternCheckFunctions = map[string]bool{
+	"preg_match": true,
+}
+
+// CountingCheckFunctions return the number of matches, 0 when the value does
+// not match and false only on an error, so `=== false` does not test for a
+// failed check
+var CountingCheckFunctions = map[string]bool{
+	"preg_match":     true,
+	"preg_match_all": true,
+}
+
+// RejectFunctions end the request when called; a validator calls them (or
+// throws, or exits) when its argument is invalid, and code after an
+// unconditional call is unreachable. Redirects that only send a header
+// (header('Location: ...'), wp_redirect) are not listed: execution continues
+// after them unless an exit follows.
+var RejectFunctions = map[string]bool{
+	"die":  true,
+	"exit": true,
+	// WordPress
+	"wp_die":               true,
+	"wp_send_json":         true,
+	"wp_send_json_success": true,
+	"wp_send_json_error":   true,
+	// CodeIgniter
+	"show_error": true,
+	"show_404":   true,
+	// Laravel (throws an HttpException)
+	"abort": true,
+}
+
+// ParamTypeForCast returns the parameter type enforced by a cast like "(int)"
+func ParamTypeForCast(castType string) (constants.ParamType, bool) {
+	t, ok := CastParamTypes[strings.ToLower(strings.Trim(castType, "() \t"))]
+	return t, ok
+}
+
+// ParamTypeForFunction returns the parameter type enforced by a typing function
+func ParamTypeForFunction(funcName string) (constants.ParamType, bool) {
+	t, ok := TypingFunctions[strings.ToLower(strings.TrimPrefix(funcName, "\\"))]
+	return t, ok
+}
+
+// TypedGetter describes how a framework input getter's arguments constrain
+// the value it returns
+type TypedGetter struct {
+	ParamType  constants.ParamType // Type the getter itself enforces ("" = decided by TypeArg)
+	Pattern    string              // Regex the returned value matches (ParamPattern)
+	TypeArg    int                 // Index of a type-flag argument (-1 = none)
+	DefaultArg int                 // Index of the default-value argument (-1 = none)
+}
+
+// TypedGetters maps input getter methods (lowercase) to their argument
+// semantics: Symfony ParameterBag typed getters (see the generated symfony
+// patterns), Laravel typed retrieval and MyBB get_input() with a type flag
+var TypedGetters = map[string]TypedGetter{
+	// Symfony ParameterBag / InputBag
+	"getint":     {ParamType: constants.ParamInt, TypeArg: -1, DefaultArg: 1},
+	"getboolean": {ParamType: constants.ParamBool, TypeArg: -1, DefaultArg: 1},
+	"getdigits":  {ParamType: constants.ParamPattern, Pattern: `^[0-9]*$`, TypeArg: -1, DefaultArg: 1},
+	"getalpha":   {ParamType: constants.ParamPattern, Pattern: `^[a-zA-Z]*$`, TypeArg: -1, DefaultArg: 1},
+	"getalnum":   {ParamType: constants.ParamPattern, Pattern: `^[a-zA-Z0-9]*$`, TypeArg: -1, DefaultArg: 1},
+	"getstring":  {TypeArg: -1, DefaultArg: 1},
+	"get":        {TypeArg: -1, DefaultArg: 1},
+
+	// Laravel Request
+	"integer": {ParamType: constants.ParamInt, TypeArg: -1, DefaultArg: 1},
+	"float":   {ParamType: constants.ParamFloat, TypeArg: -1, DefaultArg: 1},
+	"boolean": {ParamType: constants.ParamBool, TypeArg: -1, DefaultArg: 1},
+	"input":   {TypeArg: -1, DefaultArg: 1},
+	"query":   {TypeArg: -1, DefaultArg: 1},
+	"post":    {TypeArg: -1, DefaultArg: 1},
+	"cookie":  {TypeArg: -1, DefaultArg: 1},
+	"header":  {TypeArg: -1, DefaultArg: 1},
+
+	// MyBB: $mybb->get_input('page', MyBB::INPUT_INT)
+	"get_input": {TypeArg: 1, DefaultArg: -1},
+}
+
+// GetterTypeFlags maps type-flag constants (and their integer values) passed to
+// a TypedGetter's TypeArg to the type they coerce the value to
+var GetterTypeFlags = map[string]constants.ParamType{
+	"INPUT_STRING": constants.ParamFreeString,
+	"INPUT_INT":    constants.ParamInt,
+	"INPUT_FLOAT":  constants.ParamFloat,
+	"INPUT_BOOL":   constants.ParamBool,
+	"0":            constants.ParamFreeString,
+	"1":            constants.ParamInt,
+	"3":            constants.ParamFloat,
+	"4":            constants.ParamBool,
+}
+
+// LookupTypedGetter returns the argument semantics of an input getter method
+func LookupTypedGetter(methodName string) (TypedGetter, bool) {
+	g, ok := TypedGetters[strings.ToLower(methodName)]
+	return g, ok
+}
+
+// ParamTypeForGetterFlag returns the type a getter type flag such as
+// "MyBB::INPUT_INT" coerces to
+func ParamTypeForGetterFlag(flag string) (constants.ParamType, bool) {
+	if i := strings.LastIndex(flag, "::"); i >= 0 {
+		flag = flag[i+2:]
+	}
+	t, ok := GetterTypeFlags[strings.TrimSpace(flag)]
+	return t, ok
+}
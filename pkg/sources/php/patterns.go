@@ -186,6 +186,20 @@ var (
 
 	// MethodCallSuffix is the suffix pattern for method calls
 	MethodCallSuffix = `\(`
+
+	// PlainVariablePattern matches a bare variable such as $name
+	PlainVariablePattern = regexp.MustCompile(`^\$\w+$`)
+
+	// LiteralValuePattern matches a value that carries no data: a number,
+	// boolean, null, uninterpolated string, constant or empty array
+	LiteralValuePattern = regexp.MustCompile(`^(?i:true|false|null|-?\d[\d._]*|'[^']*'|"[^"$]*"|\[\s*\]|array\(\s*\))$|^[A-Z_][A-Z0-9_]*$`)
+
+	// InstanceMethodCallPattern matches a method call on an object variable,
+	// e.g. $repo->find( or $this->db->get(, whose target depends on the receiver
+	InstanceMethodCallPattern = regexp.MustCompile(`^\$\w+(?:->\w+)*->\w+\s*\(`)
+
+	// ClassReferencePattern matches new Class or Class:: and captures the class
+	ClassReferencePattern = regexp.MustCompile(`^(?:new\s+\\?([\w\\]+)|\\?([\w\\]+)::)`)
 )
 
 // BuildThisPropertyAssignPattern creates a pattern for $this->property = ... paramName
@@ -0,0 +1,71 @@
+package php
+
+import "strings"
+
+// =============================================================================
+// PSR-7 REQUEST DERIVATION
+// PSR-7 requests are immutable: middleware derives new request instances via
+// withX() methods and later handlers read attributes back with getAttribute()
+// =============================================================================
+
+const (
+	// PSR7AttributeSetter stores a request attribute on a derived request
+	PSR7AttributeSetter = "withattribute"
+	// PSR7AttributeGetter reads a request attribute
+	PSR7AttributeGetter = "getattribute"
+)
+
+// PSR7DerivationMethods are the ServerRequestInterface/MessageInterface methods
+// that return a modified copy of the request (lowercase; PHP methods are
+// case-insensitive)
+var PSR7DerivationMethods = map[string]bool{
+	"withattribute":       true,
+	"withoutattribute":    true,
+	"withqueryparams":     true,
+	"withparsedbody":      true,
+	"withcookieparams":    true,
+	"withuploadedfiles":   true,
+	"withheader":          true,
+	"withaddedheader":     true,
+	"withoutheader":       true,
+	"withbody":            true,
+	"withuri":             true,
+	"withmethod":          true,
+	"withrequesttarget":   true,
+	"withprotocolversion": true,
+}
+
+// IsPSR7DerivationMethod reports whether a method derives a new PSR-7 request
+func IsPSR7DerivationMethod(methodName string) bool {
+	return PSR7DerivationMethods[strings.ToLower(methodName)]
+}
+
+// IsPSR7AttributeSetter reports whether a method is withAttribute()
+func IsPSR7AttributeSetter(methodName string) bool {
+	return strings.ToLower(methodName) == PSR7AttributeSetter
+}
+
+// IsPSR7AttributeGetter reports whether a method is getAttribute()
+func IsPSR7AttributeGetter(methodName string) bool {
+	return strings.ToLower(methodName) == PSR7AttributeGetter
+}
+
+// PSR7RequestTypes are the PSR-7 request interfaces and common implementations
+// (lowercase, fully qualified without the leading backslash). Only receivers
+// of these types carry request attributes; getAttribute() on anything else
+// (DOMElement, PDO, ...) is unrelated.
+var PSR7RequestTypes = map[string]bool{
+	"psr\\http\\message\\serverrequestinterface": true,
+	"psr\\http\\message\\requestinterface":       true,
+	"slim\\psr7\\request":                        true,
+	"guzzlehttp\\psr7\\serverrequest":            true,
+	"guzzlehttp\\psr7\\request":                  true,
+	"laminas\\diactoros\\serverrequest":          true,
+	"zend\\diactoros\\serverrequest":             true,
+	"nyholm\\psr7\\serverrequest":                true,
+}
+
+// IsPSR7RequestType reports whether a fully qualified class name is a PSR-7 request
+func IsPSR7RequestType(className string) bool {
+	return PSR7RequestTypes[strings.ToLower(strings.TrimPrefix(className, "\\"))]
+}
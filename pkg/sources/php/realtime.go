@@ -0,0 +1,53 @@
+package php
+
+import "strings"
+
+// =============================================================================
+// WEBSOCKET SERVERS
+// Long-running WebSocket servers receive client messages through callbacks
+// instead of superglobals; the callback parameter holding the message is input
+// =============================================================================
+
+// RealtimeCallback describes a message callback of a WebSocket server library
+type RealtimeCallback struct {
+	Framework    string
+	PayloadParam int // Index of the parameter receiving the message
+}
+
+// RealtimeInterfaces are the interfaces (lowercase) whose implementations
+// receive client messages, mapped to their framework
+var RealtimeInterfaces = map[string]string{
+	"messagecomponentinterface": "ratchet",
+	"messageinterface":          "ratchet",
+	"wampserverinterface":       "ratchet",
+}
+
+// RealtimeHandlerMethods are the message methods (lowercase) of classes
+// implementing RealtimeInterfaces
+var RealtimeHandlerMethods = map[string]RealtimeCallback{
+	"onmessage": {Framework: "ratchet", PayloadParam: 1}, // onMessage(ConnectionInterface $from, $msg)
+	"oncall":    {Framework: "ratchet", PayloadParam: 3}, // onCall($conn, $id, $topic, array $params)
+	"onpublish": {Framework: "ratchet", PayloadParam: 2}, // onPublish($conn, $topic, $event, ...)
+}
+
+// RealtimeCallbackProperties are callback properties (lowercase) assigned a
+// closure receiving messages, e.g. $worker->onMessage = function($conn, $data)
+var RealtimeCallbackProperties = map[string]RealtimeCallback{
+	"onmessage":          {Framework: "workerman", PayloadParam: 1},
+	"onwebsocketconnect": {Framework: "workerman", PayloadParam: 1}, // Raw handshake request
+}
+
+// RealtimeEvents are events (lowercase) registered with ->on() whose closure
+// receives messages, e.g. $server->on('message', function($server, $frame))
+var RealtimeEvents = map[string]RealtimeCallback{
+	"message": {Framework: "swoole", PayloadParam: 1},
+}
+
+// RealtimeInterfaceFramework returns the framework of a message-receiving
+// interface, or "" if the interface is not one
+func RealtimeInterfaceFramework(iface string) string {
+	if i := strings.LastIndex(iface, "\\"); i >= 0 {
+		iface = iface[i+1:]
+	}
+	return RealtimeInterfaces[strings.ToLower(iface)]
+}
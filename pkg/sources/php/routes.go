@@ -0,0 +1,27 @@
+package php
+
+import "strings"
+
+// =============================================================================
+// CUSTOM ROUTERS
+// Legacy applications dispatch on a request value themselves: a switch on
+// $_GET['action'] calling one function per case, or an array mapping actions
+// to function names that is indexed by the value and called
+// =============================================================================
+
+// CallableInvokers are functions (lowercase) calling their first argument,
+// mapped to the index of the first argument passed on to it, or -1 when the
+// arguments are passed as an array (call_user_func_array)
+var CallableInvokers = map[string]int{
+	"call_user_func":            1,
+	"call_user_func_array":      -1,
+	"forward_static_call":       1,
+	"forward_static_call_array": -1,
+}
+
+// CallableInvoker returns the argument offset of a callable invoker and
+// whether name is one
+func CallableInvoker(name string) (int, bool) {
+	offset, ok := CallableInvokers[strings.ToLower(strings.TrimPrefix(name, "\\"))]
+	return offset, ok
+}
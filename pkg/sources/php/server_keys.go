@@ -0,0 +1,25 @@
+package php
+
+import "regexp"
+
+// =============================================================================
+// $_SERVER KEYS
+// $_SERVER mixes request data (HTTP_*, REQUEST_URI) with server configuration
+// (SERVER_ADDR, SCRIPT_FILENAME); the trust of each key is classified in
+// pkg/sources (PHPServerUserKeys, PHPServerConfigKeys).
+// =============================================================================
+
+// ServerAccessPattern matches any $_SERVER access
+var ServerAccessPattern = regexp.MustCompile(`\$_SERVER\b`)
+
+// ServerKeyPattern matches a $_SERVER access with a literal key
+var ServerKeyPattern = regexp.MustCompile(`\$_SERVER\s*\[\s*['"]([^'"]+)['"]\s*\]`)
+
+// ServerKeys returns the literal keys of the $_SERVER accesses in an
+// expression and whether every access uses one (no dynamic or bare access)
+func ServerKeys(expr string) (keys []string, allLiteral bool) {
+	for _, m := range ServerKeyPattern.FindAllStringSubmatch(expr, -1) {
+		keys = append(keys, m[1])
+	}
+	return keys, len(keys) == len(ServerAccessPattern.FindAllStringIndex(expr, -1))
+}
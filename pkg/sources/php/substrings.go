@@ -0,0 +1,177 @@
+package php
+
+import (
+	"regexp"
+	"strings"
+)
+
+// =============================================================================
+// PARTIAL READS
+// String offsets ($s[0], $s{0}), nested subscripts and substring functions
+// read part of a value. Input stays tainted through them, truncated.
+// =============================================================================
+
+// SubstringFunctions return part of the string passed as their first argument
+var SubstringFunctions = map[string]bool{
+	"substr":        true,
+	"mb_substr":     true,
+	"iconv_substr":  true,
+	"mb_strcut":     true,
+	"mb_strimwidth": true,
+	"strstr":        true,
+	"stristr":       true,
+	"strrchr":       true,
+	"mb_strstr":     true,
+	"mb_stristr":    true,
+	"mb_strrchr":    true,
+	"str_split":     true,
+	"mb_str_split":  true,
+	"strtok":        true,
+}
+
+// substringCallPattern matches the function name at the start of a call
+var substringCallPattern = regexp.MustCompile(`^\\?([A-Za-z_]\w*)\s*\(`)
+
+// SubstringCall returns the substring function expr consists of (lowercase)
+// and the expression of the string it reads, e.g. "substr" and "$s" for
+// substr($s, 0, 8)
+func SubstringCall(expr string) (fn, arg string, ok bool) {
+	expr = strings.TrimSpace(expr)
+	m := substringCallPattern.FindStringSubmatch(expr)
+	if m == nil || !SubstringFunctions[strings.ToLower(m[1])] {
+		return "", "", false
+	}
+	open := len(m[0]) - 1
+	if closing := matchingClose(expr, open); closing != len(expr)-1 {
+		return "", "", false // The call is only part of expr
+	}
+	arg = strings.TrimSpace(firstArgument(expr[open+1 : len(expr)-1]))
+	if arg == "" {
+		return "", "", false
+	}
+	return strings.ToLower(m[1]), arg, true
+}
+
+// TrailingSubscripts splits the subscripts ending expr from what they
+// subscript, leaving the first keep of them on the base: "$s" and ["[0]"]
+// for $s[0] with keep 0, "$_GET['a']" and ["[0]"] for $_GET['a'][0] with
+// keep 1.
+func TrailingSubscripts(expr string, keep int) (base string, subscripts []string) {
+	expr = strings.TrimSpace(expr)
+	var starts []int
+	var quote byte
+	depth := 0
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == quote && expr[i-1] != '\\' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[' || c == '{':
+			if depth == 0 {
+				starts = append(starts, i)
+			}
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		default:
+			if depth == 0 && c != ' ' && c != '\t' {
+				starts = starts[:0]
+			}
+		}
+	}
+	// starts now holds the groups after the last other token
+	if depth != 0 || keep >= len(starts) {
+		return expr, nil
+	}
+	for i := keep; i < len(starts); i++ {
+		stop := len(expr)
+		if i+1 < len(starts) {
+			stop = starts[i+1]
+		}
+		subscripts = append(subscripts, strings.TrimSpace(expr[starts[i]:stop]))
+	}
+	return strings.TrimSpace(expr[:starts[keep]]), subscripts
+}
+
+// PartialRead unwraps the substring calls and trailing string offsets
+// around the value expr reads from: "$s" for substr($s[0], 1). how lists
+// what was unwrapped, outermost first ("substr()", "[0]"); it is empty when
+// expr reads a whole value. Element keys are not offsets: $row['id'] is kept.
+func PartialRead(expr string) (base string, how []string) {
+	base = strings.TrimSpace(expr)
+	for {
+		if fn, arg, ok := SubstringCall(base); ok {
+			how = append(how, fn+"()")
+			base = arg
+			continue
+		}
+		_, subscripts := TrailingSubscripts(base, 0)
+		if len(subscripts) == 0 || !IsStringOffset(subscripts[len(subscripts)-1]) {
+			return base, how
+		}
+		base, _ = TrailingSubscripts(base, len(subscripts)-1)
+		how = append(how, subscripts[len(subscripts)-1])
+	}
+}
+
+// stringOffsetPattern matches a subscript reading a character: [0], [-1], {3}
+var stringOffsetPattern = regexp.MustCompile(`^(\[\s*-?\d+\s*\]|\{[^{}]+\})$`)
+
+// IsStringOffset reports whether a subscript is a string offset rather than
+// an element key
+func IsStringOffset(subscript string) bool {
+	return stringOffsetPattern.MatchString(strings.TrimSpace(subscript))
+}
+
+// matchingClose returns the index of the ")" closing the "(" at open, or -1
+func matchingClose(expr string, open int) int {
+	depth := 0
+	var quote byte
+	for i := open; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == quote && expr[i-1] != '\\' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// firstArgument returns the first argument of an argument list
+func firstArgument(args string) string {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(args); i++ {
+		c := args[i]
+		switch {
+		case quote != 0:
+			if c == quote && args[i-1] != '\\' {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			return args[:i]
+		}
+	}
+	return args
+}
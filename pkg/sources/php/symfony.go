@@ -276,6 +276,33 @@ var symfonyPatterns = []*common.FrameworkPattern{
 	},
 }
 
+// SymfonyBagProperties maps the Request properties holding a parameter bag
+// to the input the bag carries
+var SymfonyBagProperties = map[string]common.SourceType{
+	"attributes": common.SourceHTTPPath,
+	"request":    common.SourceHTTPPost,
+	"query":      common.SourceHTTPGet,
+	"server":     common.SourceHTTPHeader,
+	"files":      common.SourceHTTPFile,
+	"cookies":    common.SourceHTTPCookie,
+	"headers":    common.SourceHTTPHeader,
+}
+
+// SymfonyBagMethods are the ParameterBag and InputBag methods returning
+// input (lowercase; PHP methods are case-insensitive)
+var SymfonyBagMethods = map[string]bool{
+	"all":        true,
+	"get":        true,
+	"getalpha":   true,
+	"getalnum":   true,
+	"getdigits":  true,
+	"getstring":  true,
+	"getint":     true,
+	"getboolean": true,
+	"getenum":    true,
+	"filter":     true,
+}
+
 func init() {
 	Registry.RegisterAll(symfonyPatterns)
 
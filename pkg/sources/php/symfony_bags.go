@@ -0,0 +1,25 @@
+package php
+
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
+// =============================================================================
+// SYMFONY PARAMETER BAGS
+// Request input is read through bag properties ($request->query) and bag
+// methods ($request->query->get('id')); the bag decides the input's source
+// type. The bag tables are generated into symfony.go.
+// =============================================================================
+
+// LookupSymfonyBag returns the source type of a Request bag property
+func LookupSymfonyBag(property string) (common.SourceType, bool) {
+	st, ok := SymfonyBagProperties[property]
+	return st, ok
+}
+
+// IsSymfonyBagMethod reports whether a bag method returns input
+func IsSymfonyBagMethod(methodName string) bool {
+	return SymfonyBagMethods[strings.ToLower(methodName)]
+}
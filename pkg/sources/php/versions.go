@@ -0,0 +1,118 @@
+package php
+
+import (
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
+// =============================================================================
+// VERSION-CONDITIONAL PATTERNS
+// Request APIs added or removed in a framework release. They apply only to
+// codebases on those versions (see sources.RuleSet.SetFrameworkVersions),
+// or to every codebase whose version is unknown.
+// =============================================================================
+
+// patternVersions restricts generated patterns, by ID, to the framework
+// versions that have their API
+var patternVersions = map[string]string{
+	"laravel_request_fluent":         ">=11",
+	"symfony_parameterbag_getString": ">=6.3",
+	"symfony_parameterbag_getEnum":   ">=6.3",
+}
+
+// versionedPatterns are request APIs of a range of framework versions that
+// the generated patterns, taken from the current release, do not cover
+var versionedPatterns = []*common.FrameworkPattern{
+	{
+		ID:            "laravel_request_intersect",
+		Framework:     "laravel",
+		Language:      "php",
+		Name:          "Laravel $request->intersect()",
+		Description:   "Laravel $request->intersect() returns user input (removed in 5.5)",
+		ClassPattern:  "^(Illuminate\\\\\\\\Http\\\\\\\\)?Request$",
+		MethodPattern: "^intersect$",
+		SourceType:    common.SourceUserInput,
+		CarrierClass:  "Illuminate\\Http\\Request",
+		PopulatedFrom: []string{"$_GET", "$_POST"},
+		Tags:          []string{"framework", "modern", "legacy"},
+		Versions:      "<5.5",
+	},
+	{
+		ID:            "laravel_request_string",
+		Framework:     "laravel",
+		Language:      "php",
+		Name:          "Laravel $request->string()",
+		Description:   "Laravel $request->string() returns user input",
+		ClassPattern:  "^(Illuminate\\\\\\\\Http\\\\\\\\)?Request$",
+		MethodPattern: "^string$",
+		SourceType:    common.SourceUserInput,
+		CarrierClass:  "Illuminate\\Http\\Request",
+		PopulatedFrom: []string{"$_GET", "$_POST"},
+		Tags:          []string{"framework", "modern"},
+		Versions:      ">=9",
+	},
+	{
+		ID:            "laravel_request_integer",
+		Framework:     "laravel",
+		Language:      "php",
+		Name:          "Laravel $request->integer()",
+		Description:   "Laravel $request->integer() returns user input",
+		ClassPattern:  "^(Illuminate\\\\\\\\Http\\\\\\\\)?Request$",
+		MethodPattern: "^integer$",
+		SourceType:    common.SourceUserInput,
+		CarrierClass:  "Illuminate\\Http\\Request",
+		PopulatedFrom: []string{"$_GET", "$_POST"},
+		Tags:          []string{"framework", "modern"},
+		Versions:      ">=9",
+	},
+	{
+		ID:            "laravel_request_date",
+		Framework:     "laravel",
+		Language:      "php",
+		Name:          "Laravel $request->date()",
+		Description:   "Laravel $request->date() returns user input",
+		ClassPattern:  "^(Illuminate\\\\\\\\Http\\\\\\\\)?Request$",
+		MethodPattern: "^date$",
+		SourceType:    common.SourceUserInput,
+		CarrierClass:  "Illuminate\\Http\\Request",
+		PopulatedFrom: []string{"$_GET", "$_POST"},
+		Tags:          []string{"framework", "modern"},
+		Versions:      ">=9",
+	},
+	{
+		ID:            "symfony_request_getContentType",
+		Framework:     "symfony",
+		Language:      "php",
+		Name:          "Symfony $request->getContentType()",
+		Description:   "Symfony $request->getContentType() returns the Content-Type header format (removed in 7.0)",
+		ClassPattern:  "^(Symfony\\\\\\\\Component\\\\\\\\HttpFoundation\\\\\\\\)?Request$",
+		MethodPattern: "^getContentType$",
+		SourceType:    common.SourceHTTPHeader,
+		CarrierClass:  "Symfony\\Component\\HttpFoundation\\Request",
+		Tags:          []string{"framework", "enterprise", "legacy"},
+		Versions:      "<7",
+	},
+	{
+		ID:            "symfony_request_getContentTypeFormat",
+		Framework:     "symfony",
+		Language:      "php",
+		Name:          "Symfony $request->getContentTypeFormat()",
+		Description:   "Symfony $request->getContentTypeFormat() returns the Content-Type header format",
+		ClassPattern:  "^(Symfony\\\\\\\\Component\\\\\\\\HttpFoundation\\\\\\\\)?Request$",
+		MethodPattern: "^getContentTypeFormat$",
+		SourceType:    common.SourceHTTPHeader,
+		CarrierClass:  "Symfony\\Component\\HttpFoundation\\Request",
+		Tags:          []string{"framework", "enterprise"},
+		Versions:      ">=6.2",
+	},
+}
+
+func init() {
+	// Runs after the generated files registered their patterns (files
+	// initialize in name order)
+	for _, p := range Registry.GetAll() {
+		if versions, ok := patternVersions[p.ID]; ok {
+			p.Versions = versions
+		}
+	}
+	Registry.RegisterAll(versionedPatterns)
+}
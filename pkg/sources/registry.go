@@ -4,15 +4,18 @@ import (
 	"sync"
 
 	"github.com/hatlesswizard/inputtracer/pkg/sources/c"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/cpp"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/csharp"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/golang"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/java"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/javascript"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/kotlin"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/php"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/python"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/ruby"
 	"github.com/hatlesswizard/inputtracer/pkg/sources/rust"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/swift"
 )
 
 // Registry manages all source matchers
@@ -92,4 +95,27 @@ func RegisterAll(r *Registry) {
 
 	// Register Rust
 	r.RegisterMatcher(rust.NewMatcher())
+
+	// Register Kotlin
+	r.RegisterMatcher(kotlin.NewMatcher())
+
+	// Register Swift
+	r.RegisterMatcher(swift.NewMatcher())
+}
+
+// frameworkPatternRegistries lists the per-language framework pattern registries
+var frameworkPatternRegistries = []*common.FrameworkPatternRegistry{
+	php.Registry, javascript.Registry, python.Registry, golang.Registry, java.Registry,
+	c.Registry, cpp.Registry, csharp.Registry, ruby.Registry, rust.Registry,
+	kotlin.Registry, swift.Registry,
+}
+
+// FrameworkPatternCount returns how many patterns are registered for a framework
+// across all languages (0 means no pattern pack exists for it)
+func FrameworkPatternCount(framework string) int {
+	count := 0
+	for _, registry := range frameworkPatternRegistries {
+		count += len(registry.GetByFramework(framework))
+	}
+	return count
 }
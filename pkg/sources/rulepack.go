@@ -0,0 +1,384 @@
+package sources
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+	"github.com/hatlesswizard/inputtracer/pkg/sources/php"
+)
+
+// RulePackFormat is the version of the rule pack format written by
+// ExportRulePack; packs of another format are refused
+const RulePackFormat = 1
+
+// RulePack is a detection rule set as data, so rules can be updated without
+// a new release: the framework input patterns of every language (built-in and
+// generated by genpatterns) and the functions that decode, sanitize or
+// validate input. An exported pack also lists the built-in input sources of
+// every language and the framework methods genpatterns generated patterns
+// for; both are informational and ignored when a pack is applied. Sinks are
+// not part of it: the library traces input sources only.
+//
+//	{
+//	  "format": 1,
+//	  "name": "acme-rules",
+//	  "version": "2026.10.1",
+//	  "framework_patterns": [
+//	    {"id": "acme_request_param", "framework": "acme", "language": "php",
+//	     "name": "Acme $req->param()", "class_pattern": "^AcmeRequest$",
+//	     "method_pattern": "^param$", "source_type": "http_get"}
+//	  ],
+//	  "functions": {
+//	    "php": {"decoding": ["acme_unescape"], "sanitizing": [], "validating": ["acme_is_id"]}
+//	  }
+//	}
+type RulePack struct {
+	Format            int                              `json:"format"`
+	Name              string                           `json:"name,omitempty"`
+	Version           string                           `json:"version,omitempty"`
+	FrameworkPatterns []*common.FrameworkPattern       `json:"framework_patterns,omitempty"`
+	Functions         map[string]*FunctionRuleSet      `json:"functions,omitempty"`         // Language -> function lists
+	Sources           map[string]map[string]SourceType `json:"sources,omitempty"`           // Language -> input source -> type (export only)
+	GeneratedMethods  map[string][]string              `json:"generated_methods,omitempty"` // Framework -> generated request methods (export only)
+}
+
+// FunctionRuleSet lists the functions of a language (lowercase) that decode,
+// sanitize or validate input. An imported list replaces the current one;
+// a missing (null) list keeps it.
+type FunctionRuleSet struct {
+	Decoding   []string `json:"decoding"`
+	Sanitizing []string `json:"sanitizing"`
+	Validating []string `json:"validating"`
+}
+
+// RuleSet is the detection rules of one scan: the built-in framework patterns
+// and function lists with rule packs applied on top, and the framework
+// versions of the scanned codebase. Applying a pack or setting versions
+// changes only the rule set, never the built-in rules, so scans with
+// different packs and versions can run side by side.
+type RuleSet struct {
+	patterns   map[string][]*common.FrameworkPattern // Registry language -> patterns
+	versions   map[string]string                     // Framework -> version of the scanned codebase
+	decoding   map[string]bool
+	sanitizing map[string]bool
+	validating map[string]bool
+	phpInput   *php.InputPatterns // Derived from the PHP patterns that apply
+}
+
+// NewRuleSet returns the built-in rules
+func NewRuleSet() *RuleSet {
+	s := &RuleSet{
+		patterns:   make(map[string][]*common.FrameworkPattern, len(frameworkPatternRegistries)),
+		decoding:   php.DecodingFunctions,
+		sanitizing: php.SanitizingFunctions,
+		validating: php.ValidatingFunctions,
+	}
+	for _, registry := range frameworkPatternRegistries {
+		s.patterns[registry.Language()] = registry.GetAll()
+	}
+	s.phpInput = php.RegistryInputPatterns()
+	return s
+}
+
+// Clone returns a copy of the rule set that packs can be applied to
+// without changing s
+func (s *RuleSet) Clone() *RuleSet {
+	c := *s
+	c.patterns = make(map[string][]*common.FrameworkPattern, len(s.patterns))
+	for language, patterns := range s.patterns {
+		c.patterns[language] = patterns
+	}
+	c.versions = make(map[string]string, len(s.versions))
+	for fw, v := range s.versions {
+		c.versions[fw] = v
+	}
+	return &c
+}
+
+// SetFrameworkVersions sets the framework versions of the scanned codebase
+// (framework name -> version), replacing the previous ones. Patterns limited
+// to versions of a framework apply only when its version satisfies them;
+// frameworks without a version get all their patterns.
+func (s *RuleSet) SetFrameworkVersions(versions map[string]string) {
+	s.versions = make(map[string]string, len(versions))
+	for fw, v := range versions {
+		s.versions[fw] = v
+	}
+	s.phpInput = php.NewInputPatterns(s.Patterns("php"))
+}
+
+// FrameworkVersion returns the version set for a framework, or ""
+func (s *RuleSet) FrameworkVersion(framework string) string {
+	return s.versions[framework]
+}
+
+// Apply validates a pack and applies it: its framework patterns replace the
+// patterns with the same ID or are added, and its function lists replace the
+// current ones
+func (s *RuleSet) Apply(p *RulePack) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	for _, pattern := range p.FrameworkPatterns {
+		language := registryLanguage(pattern.Language)
+		s.patterns[language] = overridePattern(s.patterns[language], pattern, language)
+	}
+	if len(p.FrameworkPatterns) > 0 {
+		s.phpInput = php.NewInputPatterns(s.Patterns("php"))
+	}
+	if rules := p.Functions["php"]; rules != nil {
+		if rules.Decoding != nil {
+			s.decoding = functionSet(rules.Decoding)
+		}
+		if rules.Sanitizing != nil {
+			s.sanitizing = functionSet(rules.Sanitizing)
+		}
+		if rules.Validating != nil {
+			s.validating = functionSet(rules.Validating)
+		}
+	}
+	return nil
+}
+
+// overridePattern returns patterns with pattern in place of the one with the
+// same ID, or added. The slice is copied, as other rule sets may share it.
+func overridePattern(patterns []*common.FrameworkPattern, pattern *common.FrameworkPattern, language string) []*common.FrameworkPattern {
+	p := *pattern
+	if p.Language == "" {
+		p.Language = language
+	}
+	result := make([]*common.FrameworkPattern, 0, len(patterns)+1)
+	replaced := false
+	for _, existing := range patterns {
+		if p.ID != "" && existing.ID == p.ID {
+			if !replaced {
+				result = append(result, &p)
+				replaced = true
+			}
+			continue
+		}
+		result = append(result, existing)
+	}
+	if !replaced {
+		result = append(result, &p)
+	}
+	return result
+}
+
+// Patterns returns the framework patterns of a language (TypeScript shares
+// JavaScript's) that apply to the framework versions of the scanned codebase
+func (s *RuleSet) Patterns(language string) []*common.FrameworkPattern {
+	language = registryLanguage(language)
+	if len(s.versions) == 0 {
+		return s.patterns[language]
+	}
+	var active []*common.FrameworkPattern
+	for _, p := range s.patterns[language] {
+		if p.Versions == "" || common.VersionSatisfies(s.versions[p.Framework], p.Versions) {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// IsInputMethodCall reports whether a PHP expression calls a framework input
+// method, e.g. "$request->input('id')"
+func (s *RuleSet) IsInputMethodCall(expr string) bool {
+	return s.phpInput.IsInputMethodCall(expr)
+}
+
+// IsInputPropertyAccess reports whether a PHP expression reads a framework
+// input property, e.g. "$request->query['id']"
+func (s *RuleSet) IsInputPropertyAccess(expr string) bool {
+	return s.phpInput.IsInputPropertyAccess(expr)
+}
+
+// IsDecodingFunction reports whether name is a PHP decoding function
+func (s *RuleSet) IsDecodingFunction(name string) bool {
+	return s.decoding[strings.ToLower(strings.TrimPrefix(name, "\\"))]
+}
+
+// IsValidatingFunction reports whether name is a PHP validating function
+func (s *RuleSet) IsValidatingFunction(name string) bool {
+	return s.validating[strings.ToLower(strings.TrimPrefix(name, "\\"))]
+}
+
+// DecodingCall returns the PHP decoding function called in expr, or ""
+func (s *RuleSet) DecodingCall(expr string) string {
+	return php.CalledFunction(expr, s.decoding)
+}
+
+// SanitizingCall returns the PHP sanitizing function called in expr, or ""
+func (s *RuleSet) SanitizingCall(expr string) string {
+	return php.CalledFunction(expr, s.sanitizing)
+}
+
+// Export returns the rule set as a pack named name and version, listing the
+// built-in input sources and generated framework methods as well
+func (s *RuleSet) Export(name, version string) *RulePack {
+	pack := &RulePack{Format: RulePackFormat, Name: name, Version: version}
+	for _, registry := range frameworkPatternRegistries {
+		pack.FrameworkPatterns = append(pack.FrameworkPatterns, s.patterns[registry.Language()]...)
+	}
+	pack.Functions = map[string]*FunctionRuleSet{
+		"php": {
+			Decoding:   functionList(s.decoding),
+			Sanitizing: functionList(s.sanitizing),
+			Validating: functionList(s.validating),
+		},
+	}
+
+	pack.Sources = make(map[string]map[string]SourceType)
+	for language, m := range mappingsRegistry {
+		merged := MergeMaps(m.InputFunctions, m.InputSources, m.Superglobals, m.GlobalSources, m.DOMSources,
+			m.NodeSources, m.CGIEnvVars, m.QtInputMethods, m.MethodInputs, m.Annotations, m.InputMethods)
+		if len(merged) > 0 {
+			pack.Sources[language] = merged
+		}
+	}
+
+	pack.GeneratedMethods = make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, p := range pack.FrameworkPatterns {
+		method := strings.TrimSuffix(strings.TrimPrefix(p.MethodPattern, "^"), "$")
+		key := p.Framework + "\x00" + method
+		if method == "" || seen[key] || !hasTag(p, "generated") {
+			continue
+		}
+		seen[key] = true
+		pack.GeneratedMethods[p.Framework] = append(pack.GeneratedMethods[p.Framework], method)
+	}
+	for _, methods := range pack.GeneratedMethods {
+		sort.Strings(methods)
+	}
+	return pack
+}
+
+// ExportRulePack returns the built-in rules as a pack named name and version
+func ExportRulePack(name, version string) *RulePack {
+	return NewRuleSet().Export(name, version)
+}
+
+// hasTag reports whether a pattern has a tag
+func hasTag(p *common.FrameworkPattern, tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// functionList returns the sorted names of a function set
+func functionList(set map[string]bool) []string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// functionSet returns a function list as a lowercase set
+func functionSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToLower(strings.TrimPrefix(name, "\\"))] = true
+	}
+	return set
+}
+
+// LoadRulePack reads and validates a rule pack file
+func LoadRulePack(path string) (*RulePack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rule pack: %w", err)
+	}
+	var pack RulePack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("parsing rule pack %s: %w", path, err)
+	}
+	if err := pack.Validate(); err != nil {
+		return nil, fmt.Errorf("rule pack %s: %w", path, err)
+	}
+	return &pack, nil
+}
+
+// MarshalIndent returns the pack as indented JSON, leaving the < and > of
+// names like $request->get() unescaped so packs stay editable by hand
+func (p *RulePack) MarshalIndent() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteFile writes the pack as indented JSON
+func (p *RulePack) WriteFile(path string) error {
+	data, err := p.MarshalIndent()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Validate checks the format version and that every pattern names a known
+// language and source type and has valid regexes
+func (p *RulePack) Validate() error {
+	if p.Format != RulePackFormat {
+		return fmt.Errorf("unsupported format %d (supported: %d)", p.Format, RulePackFormat)
+	}
+	for i, pattern := range p.FrameworkPatterns {
+		if pattern == nil || pattern.ID == "" {
+			return fmt.Errorf("framework_patterns[%d]: id is required", i)
+		}
+		if patternRegistry(pattern.Language) == nil {
+			return fmt.Errorf("framework_patterns[%d] (%s): unknown language %q", i, pattern.ID, pattern.Language)
+		}
+		if !common.IsValidSourceType(string(pattern.SourceType)) {
+			return fmt.Errorf("framework_patterns[%d] (%s): unknown source type %q", i, pattern.ID, pattern.SourceType)
+		}
+		for _, re := range []string{pattern.ClassPattern, pattern.MethodPattern, pattern.PropertyPattern} {
+			if _, err := regexp.Compile(re); err != nil {
+				return fmt.Errorf("framework_patterns[%d] (%s): %w", i, pattern.ID, err)
+			}
+		}
+	}
+	for language := range p.Functions {
+		if language != "php" {
+			return fmt.Errorf("functions: no function rules for language %q", language)
+		}
+	}
+	return nil
+}
+
+// registryLanguage returns the language of the framework pattern registry
+// holding a language's patterns (TypeScript shares JavaScript's)
+func registryLanguage(language string) string {
+	if language == "typescript" {
+		return "javascript"
+	}
+	return language
+}
+
+// patternRegistry returns the framework pattern registry of a language
+// (TypeScript shares JavaScript's), or nil
+func patternRegistry(language string) *common.FrameworkPatternRegistry {
+	language = registryLanguage(language)
+	for _, registry := range frameworkPatternRegistries {
+		if registry.Language() == language {
+			return registry
+		}
+	}
+	return nil
+}
@@ -2,7 +2,10 @@
 // All special filename patterns should be defined here
 package sources
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
 
 // UnsupportedFilenames contains filenames that should not be parsed
 // Replaces hardcoded switch in parser/service.go
@@ -141,3 +144,63 @@ func ShouldSkipDir(dirName string) bool {
 	}
 	return false
 }
+
+// GeneratedPathMarkers are path fragments of compiled or generated code
+// (compiled templates, framework caches, ORM proxies, bundler output). A
+// plain "generated" directory is not one: applications keep hand-written
+// code under such names.
+var GeneratedPathMarkers = []string{
+	"storage/framework/views/", // Compiled Blade views
+	"bootstrap/cache/",
+	"var/cache/", // Symfony cache (compiled container, Twig)
+	"__cg__",     // Doctrine proxies
+	".min.js",
+	".min.mjs",
+	".bundle.js",
+	".chunk.js",
+	"_pb.js",
+	".pb.go",
+	"_pb2.py",
+}
+
+// GeneratedCommentPattern matches a header comment (lowercase, comment
+// delimiters stripped) that marks a file as generated: `@generated`, Go's
+// "Code generated by ... DO NOT EDIT", "This file was auto-generated", or a
+// "do not edit" notice naming generated code. A bare "do not edit" is not
+// enough; config templates carry such notices too.
+var GeneratedCommentPattern = regexp.MustCompile(
+	`@generated\b|^code generated by\b|\b(file|class|code) (is|was|has been) (auto-?|automatically )?generated\b|\bgenerated\b.*\bdo not (edit|modify)\b|\bdo not (edit|modify)\b.*\bgenerated\b|^auto-?generated\b`)
+
+// generatedCommentPrefixes open a comment line in the supported languages
+var generatedCommentPrefixes = []string{"//", "/*", "*", "#", "<!--", "{{--", "{#", "--"}
+
+// IsGeneratedPath checks if a path matches a generated-code location
+func IsGeneratedPath(path string) bool {
+	lower := strings.ToLower(strings.ReplaceAll(path, "\\", "/"))
+	for _, marker := range GeneratedPathMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGeneratedMarker checks if a comment line of a file header marks the
+// file as generated; string literals and code mentioning the words do not
+func HasGeneratedMarker(header string) bool {
+	for _, line := range strings.Split(strings.ToLower(header), "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "<?php"))
+		comment := false
+		for _, prefix := range generatedCommentPrefixes {
+			if strings.HasPrefix(line, prefix) {
+				line = strings.TrimSpace(strings.TrimLeft(line, "/*#!<-{"))
+				comment = true
+				break
+			}
+		}
+		if comment && GeneratedCommentPattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,12 @@
 // This is the ONLY place PHP superglobal definitions should exist
 package sources
 
+import (
+	"strings"
+
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
 // PHPSuperglobal represents a PHP superglobal variable with all its metadata
 type PHPSuperglobal struct {
 	Name        string       // "$_GET", "$_POST", etc.
@@ -55,6 +61,30 @@ var SuperglobalToSourceType = map[string]SourceType{
 	"$_SESSION": SourceSession,
 }
 
+// superglobalMatcher finds the names of PHPSuperglobals in expressions
+var superglobalMatcher = func() *common.KeywordMatcher {
+	names := make([]string, len(PHPSuperglobals))
+	for i, sg := range PHPSuperglobals {
+		names[i] = sg.Name
+	}
+	return common.NewKeywordMatcher(names)
+}()
+
+// FindSuperglobal returns the leftmost superglobal named in expr that accept
+// accepts (nil accepts any), or ""; expr is scanned once
+func FindSuperglobal(expr string, accept func(name string) bool) string {
+	found := ""
+	superglobalMatcher.Find(expr, func(index int) bool {
+		name := superglobalMatcher.Keyword(index)
+		if accept == nil || accept(name) {
+			found = name
+			return false
+		}
+		return true
+	})
+	return found
+}
+
 // SourceTypeToSuperglobal maps SourceType back to superglobal name (reverse lookup)
 var SourceTypeToSuperglobal = map[SourceType]string{
 	SourceHTTPGet:     "$_GET",
@@ -226,3 +256,19 @@ func GetServerKeySourceType(key string) SourceType {
 	}
 	return SourceUnknown
 }
+
+// serverConfigKeySet indexes PHPServerConfigKeys
+var serverConfigKeySet = func() map[string]bool {
+	set := make(map[string]bool, len(PHPServerConfigKeys))
+	for _, key := range PHPServerConfigKeys {
+		set[key] = true
+	}
+	return set
+}()
+
+// IsServerKeyTrusted returns true if the $_SERVER key holds server configuration
+// the client cannot influence. Request keys (HTTP_*, REQUEST_URI, ...) and keys
+// in neither list are untrusted, so unknown keys keep being reported as input.
+func IsServerKeyTrusted(key string) bool {
+	return serverConfigKeySet[strings.ToUpper(key)]
+}
@@ -0,0 +1,90 @@
+// Package swift - frameworks.go provides Swift server framework patterns
+// Includes patterns for Vapor
+package swift
+
+import (
+	"github.com/hatlesswizard/inputtracer/pkg/sources/common"
+)
+
+// Registry is the global Swift framework pattern registry
+var Registry = common.NewFrameworkPatternRegistry("swift")
+
+// Vapor patterns (server-side Swift web framework)
+var vaporPatterns = []*common.FrameworkPattern{
+	{
+		ID:              "vapor_query",
+		Framework:       "vapor",
+		Language:        "swift",
+		Name:            "req.query",
+		Description:     "Vapor query string parameters",
+		CarrierClass:    "Request",
+		PropertyPattern: "^query$",
+		SourceType:      common.SourceHTTPGet,
+		Tags:            []string{"web", "async", "popular"},
+	},
+	{
+		ID:              "vapor_content",
+		Framework:       "vapor",
+		Language:        "swift",
+		Name:            "req.content",
+		Description:     "Vapor decoded request body",
+		CarrierClass:    "Request",
+		PropertyPattern: "^content$",
+		SourceType:      common.SourceHTTPBody,
+		Tags:            []string{"web", "async", "popular"},
+	},
+	{
+		ID:              "vapor_parameters",
+		Framework:       "vapor",
+		Language:        "swift",
+		Name:            "req.parameters",
+		Description:     "Vapor route path parameters",
+		CarrierClass:    "Request",
+		PropertyPattern: "^parameters$",
+		SourceType:      common.SourceHTTPPath,
+		Tags:            []string{"web", "async"},
+	},
+	{
+		ID:              "vapor_headers",
+		Framework:       "vapor",
+		Language:        "swift",
+		Name:            "req.headers",
+		Description:     "Vapor request headers",
+		CarrierClass:    "Request",
+		PropertyPattern: "^headers$",
+		SourceType:      common.SourceHTTPHeader,
+		Tags:            []string{"web", "async"},
+	},
+	{
+		ID:              "vapor_cookies",
+		Framework:       "vapor",
+		Language:        "swift",
+		Name:            "req.cookies",
+		Description:     "Vapor request cookies",
+		CarrierClass:    "Request",
+		PropertyPattern: "^cookies$",
+		SourceType:      common.SourceHTTPCookie,
+		Tags:            []string{"web", "async"},
+	},
+	{
+		ID:              "vapor_body",
+		Framework:       "vapor",
+		Language:        "swift",
+		Name:            "req.body",
+		Description:     "Vapor raw request body",
+		CarrierClass:    "Request",
+		PropertyPattern: "^body$",
+		SourceType:      common.SourceHTTPBody,
+		Tags:            []string{"web", "async"},
+	},
+}
+
+func init() {
+	Registry.RegisterAll(vaporPatterns)
+
+	// Register framework detectors
+	common.RegisterFrameworkDetector(&common.FrameworkDetector{
+		Framework:  "vapor",
+		Indicators: []string{"Package.swift", "vapor"},
+	})
+}
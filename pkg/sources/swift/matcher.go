@@ -0,0 +1,117 @@
+package swift
+
+import "github.com/hatlesswizard/inputtracer/pkg/sources/common"
+
+// Matcher matches Swift user input sources
+type Matcher struct {
+	*common.BaseMatcher
+}
+
+// NewMatcher creates a new Swift source matcher
+func NewMatcher() *Matcher {
+	defs := []common.Definition{
+		// CLI arguments and standard input
+		{
+			Name:        "CommandLine.arguments",
+			Pattern:     `CommandLine\.arguments\b`,
+			Language:    "swift",
+			Labels:      []common.InputLabel{common.LabelCLI},
+			Description: "Command line arguments",
+			NodeTypes:   []string{"navigation_expression"},
+		},
+		{
+			Name:        "readLine()",
+			Pattern:     `^readLine\s*\(`,
+			Language:    "swift",
+			Labels:      []common.InputLabel{common.LabelUserInput},
+			Description: "Read line from stdin",
+			NodeTypes:   []string{"call_expression"},
+		},
+
+		// Environment variables
+		{
+			Name:         "ProcessInfo.processInfo.environment",
+			Pattern:      `ProcessInfo\.processInfo\.environment\b`,
+			Language:     "swift",
+			Labels:       []common.InputLabel{common.LabelEnvironment},
+			Description:  "Process environment variables",
+			NodeTypes:    []string{"navigation_expression"},
+			KeyExtractor: `environment\s*\[\s*"([^"]+)"`,
+		},
+		{
+			Name:         "Environment.get()",
+			Pattern:      `Environment\.get\s*\(`,
+			Language:     "swift",
+			Labels:       []common.InputLabel{common.LabelEnvironment},
+			Description:  "Vapor environment variable",
+			NodeTypes:    []string{"call_expression"},
+			KeyExtractor: `get\s*\(\s*"([^"]+)"`,
+		},
+
+		// File operations
+		{
+			Name:        "String(contentsOfFile:)",
+			Pattern:     `(String|Data)\s*\(\s*contentsOf(File)?:`,
+			Language:    "swift",
+			Labels:      []common.InputLabel{common.LabelFile},
+			Description: "Read file contents",
+			NodeTypes:   []string{"call_expression"},
+		},
+
+		// Vapor
+		{
+			Name:         "req.query",
+			Pattern:      `\b(req|request)\.query\b`,
+			Language:     "swift",
+			Labels:       []common.InputLabel{common.LabelHTTPGet, common.LabelUserInput},
+			Description:  "Vapor query string parameters",
+			NodeTypes:    []string{"navigation_expression"},
+			KeyExtractor: `query\s*\[\s*"([^"]+)"`,
+		},
+		{
+			Name:        "req.content",
+			Pattern:     `\b(req|request)\.content\b`,
+			Language:    "swift",
+			Labels:      []common.InputLabel{common.LabelHTTPBody, common.LabelUserInput},
+			Description: "Vapor decoded request body",
+			NodeTypes:   []string{"navigation_expression"},
+		},
+		{
+			Name:         "req.parameters",
+			Pattern:      `\b(req|request)\.parameters\b`,
+			Language:     "swift",
+			Labels:       []common.InputLabel{common.LabelHTTPGet, common.LabelUserInput},
+			Description:  "Vapor route path parameters",
+			NodeTypes:    []string{"navigation_expression"},
+			KeyExtractor: `parameters\.get\s*\(\s*"([^"]+)"`,
+		},
+		{
+			Name:        "req.headers",
+			Pattern:     `\b(req|request)\.headers\b`,
+			Language:    "swift",
+			Labels:      []common.InputLabel{common.LabelHTTPHeader, common.LabelUserInput},
+			Description: "Vapor request headers",
+			NodeTypes:   []string{"navigation_expression"},
+		},
+		{
+			Name:        "req.cookies",
+			Pattern:     `\b(req|request)\.cookies\b`,
+			Language:    "swift",
+			Labels:      []common.InputLabel{common.LabelHTTPCookie, common.LabelUserInput},
+			Description: "Vapor request cookies",
+			NodeTypes:   []string{"navigation_expression"},
+		},
+		{
+			Name:        "req.body",
+			Pattern:     `\b(req|request)\.body\b`,
+			Language:    "swift",
+			Labels:      []common.InputLabel{common.LabelHTTPBody, common.LabelUserInput},
+			Description: "Vapor raw request body",
+			NodeTypes:   []string{"navigation_expression"},
+		},
+	}
+
+	return &Matcher{
+		BaseMatcher: common.NewBaseMatcher("swift", defs),
+	}
+}
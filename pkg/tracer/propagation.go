@@ -220,6 +220,8 @@ func (tp *TaintPropagator) extractAssignmentParts(node *sitter.Node, src []byte)
 		return tp.extractRubyAssignment(node, src)
 	case "rust":
 		return tp.extractRustAssignment(node, src)
+	case "kotlin", "swift":
+		return tp.extractBindingAssignment(node, src)
 	}
 
 	// Generic fallback - look for = operator
@@ -376,6 +378,23 @@ func (tp *TaintPropagator) extractRustAssignment(node *sitter.Node, src []byte)
 	return "", ""
 }
 
+func (tp *TaintPropagator) extractBindingAssignment(node *sitter.Node, src []byte) (string, string) {
+	// Kotlin: val name: Type = value; Swift: let name = value (or var in both)
+	text := string(src[node.StartByte():node.EndByte()])
+	parts := strings.SplitN(text, "=", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	target := strings.TrimSpace(parts[0])
+	for _, keyword := range []string{"val ", "var ", "let "} {
+		target = strings.TrimSpace(strings.TrimPrefix(target, keyword))
+	}
+	if colonIdx := strings.Index(target, ":"); colonIdx > 0 {
+		target = strings.TrimSpace(target[:colonIdx])
+	}
+	return target, strings.TrimSpace(parts[1])
+}
+
 // extractFunctionName extracts the function name from a call expression
 func (tp *TaintPropagator) extractFunctionName(node *sitter.Node, src []byte) string {
 	// Look for identifier or member expression in first child